@@ -0,0 +1,342 @@
+package stages
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/mitchellh/mapstructure"
+	"github.com/pkg/errors"
+)
+
+// Config Errors
+const (
+	ErrEmptyCEFStageConfig = "empty cef stage configuration"
+	ErrEmptyCEFStageSource = "empty source"
+	ErrMalformedCEF        = "malformed cef or leef event"
+)
+
+var (
+	cefHeaderFields  = []string{"version", "device_vendor", "device_product", "device_version", "signature_id", "name", "severity"}
+	leefHeaderFields = []string{"version", "device_vendor", "device_product", "device_version", "event_id"}
+)
+
+// CEFConfig represents a CEF Stage configuration
+type CEFConfig struct {
+	Mapping            map[string]string `mapstructure:"mapping"`
+	Source             *string           `mapstructure:"source"`
+	DropMalformed      bool              `mapstructure:"drop_malformed"`
+	StructuredMetadata LabelsConfig      `mapstructure:"structured_metadata"`
+}
+
+// validateCEFConfig validates a cef stage config and returns an inverse mapping of the configured mapping,
+// from the name of the event field to the keys in the extracted data. Several keys can be set from the same
+// event field. A nil mapping extracts all fields.
+func validateCEFConfig(c *CEFConfig) (map[string][]string, error) {
+	if c == nil {
+		return nil, errors.New(ErrEmptyCEFStageConfig)
+	}
+
+	if c.Source != nil && *c.Source == "" {
+		return nil, errors.New(ErrEmptyCEFStageSource)
+	}
+
+	if c.StructuredMetadata != nil {
+		if err := validateLabelsConfig(c.StructuredMetadata); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(c.Mapping) == 0 {
+		return nil, nil
+	}
+	inverseMapping := make(map[string][]string, len(c.Mapping))
+	for k, v := range c.Mapping {
+		// if value is not set, use the key for setting data in extracted map.
+		if v == "" {
+			v = k
+		}
+		inverseMapping[v] = append(inverseMapping[v], k)
+	}
+	return inverseMapping, nil
+}
+
+// cefStage sets extracted data by parsing events in the ArcSight Common Event Format (CEF)
+// or the IBM QRadar Log Event Extended Format (LEEF).
+type cefStage struct {
+	cfg            *CEFConfig
+	inverseMapping map[string][]string
+	logger         log.Logger
+}
+
+// newCEFStage creates a new cef pipeline stage from a config.
+func newCEFStage(logger log.Logger, config interface{}) (Stage, error) {
+	cfg, err := parseCEFConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	inverseMapping, err := validateCEFConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &cefStage{
+		cfg:            cfg,
+		inverseMapping: inverseMapping,
+		logger:         log.With(logger, "component", "stage", "type", "cef"),
+	}, nil
+}
+
+func parseCEFConfig(config interface{}) (*CEFConfig, error) {
+	cfg := &CEFConfig{}
+	err := mapstructure.Decode(config, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func (c *cefStage) Run(in chan Entry) chan Entry {
+	out := make(chan Entry)
+	go func() {
+		defer close(out)
+		for e := range in {
+			err := c.processEntry(e.Extracted, &e.Line)
+			if err != nil && c.cfg.DropMalformed {
+				continue
+			}
+			if c.cfg.StructuredMetadata != nil {
+				addStructuredMetadata(c.logger, &e, c.cfg.StructuredMetadata)
+			}
+			out <- e
+		}
+	}()
+	return out
+}
+
+func (c *cefStage) processEntry(extracted map[string]interface{}, entry *string) error {
+	// If a source key is provided, the cef stage should process it
+	// from the extracted map, otherwise should fallback to the entry
+	input := entry
+
+	if c.cfg.Source != nil {
+		if _, ok := extracted[*c.cfg.Source]; !ok {
+			if Debug {
+				level.Debug(c.logger).Log("msg", "source does not exist in the set of extracted values", "source", *c.cfg.Source)
+			}
+			return nil
+		}
+
+		value, err := getString(extracted[*c.cfg.Source])
+		if err != nil {
+			if Debug {
+				level.Debug(c.logger).Log("msg", "failed to convert source value to string", "source", *c.cfg.Source, "err", err, "type", reflect.TypeOf(extracted[*c.cfg.Source]))
+			}
+			return nil
+		}
+
+		input = &value
+	}
+
+	if input == nil {
+		if Debug {
+			level.Debug(c.logger).Log("msg", "cannot parse a nil entry")
+		}
+		return nil
+	}
+
+	fields, err := parseSecurityEvent(*input)
+	if err != nil {
+		if Debug {
+			level.Debug(c.logger).Log("msg", "failed to parse log line", "err", err)
+		}
+		return errors.New(ErrMalformedCEF)
+	}
+
+	for name, value := range fields {
+		if c.inverseMapping == nil {
+			extracted[name] = value
+			continue
+		}
+		for _, key := range c.inverseMapping[name] {
+			extracted[key] = value
+		}
+	}
+	if Debug {
+		level.Debug(c.logger).Log("msg", "extracted data debug in cef stage", "extracted data", fmt.Sprintf("%v", extracted))
+	}
+	return nil
+}
+
+// Name implements Stage
+func (c *cefStage) Name() string {
+	return StageTypeCEF
+}
+
+// parseSecurityEvent parses a CEF or LEEF event into its header and extension fields.
+// The event may be preceded by a prefix, such as a syslog header.
+func parseSecurityEvent(s string) (map[string]string, error) {
+	cefIdx, leefIdx := strings.Index(s, "CEF:"), strings.Index(s, "LEEF:")
+	switch {
+	case cefIdx >= 0 && (leefIdx < 0 || cefIdx < leefIdx):
+		return parseCEF(s[cefIdx+len("CEF:"):])
+	case leefIdx >= 0:
+		return parseLEEF(s[leefIdx+len("LEEF:"):])
+	default:
+		return nil, errors.New("no CEF or LEEF header found")
+	}
+}
+
+// parseCEF parses a CEF event, without its CEF: prefix, in the format
+// Version|Device Vendor|Device Product|Device Version|Signature ID|Name|Severity|Extension
+func parseCEF(s string) (map[string]string, error) {
+	header, extension, ok := splitEventHeader(s, len(cefHeaderFields))
+	if !ok {
+		return nil, fmt.Errorf("expected %d header fields", len(cefHeaderFields))
+	}
+	fields := make(map[string]string, len(header))
+	for i, name := range cefHeaderFields {
+		fields[name] = header[i]
+	}
+	parseCEFExtension(extension, fields)
+	return fields, nil
+}
+
+// parseLEEF parses a LEEF event, without its LEEF: prefix, in the format
+// Version|Vendor|Product|Version|EventID|Attributes for LEEF 1.0, where attributes are separated by tabs,
+// and Version|Vendor|Product|Version|EventID|Delimiter|Attributes for LEEF 2.0.
+func parseLEEF(s string) (map[string]string, error) {
+	header, attributes, ok := splitEventHeader(s, len(leefHeaderFields))
+	if !ok {
+		return nil, fmt.Errorf("expected %d header fields", len(leefHeaderFields))
+	}
+	fields := make(map[string]string, len(header))
+	for i, name := range leefHeaderFields {
+		fields[name] = header[i]
+	}
+
+	delimiter := "\t"
+	if strings.HasPrefix(fields["version"], "2") {
+		// The delimiter field is optional, it is only present if the next field holds no attribute.
+		if d, rest, ok := strings.Cut(attributes, "|"); ok && !strings.Contains(d, "=") {
+			attributes = rest
+			if d != "" {
+				delim, err := parseLEEFDelimiter(d)
+				if err != nil {
+					return nil, err
+				}
+				delimiter = delim
+			}
+		}
+	}
+
+	for _, attr := range strings.Split(attributes, delimiter) {
+		key, value, ok := strings.Cut(attr, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			continue
+		}
+		fields[key] = value
+	}
+	return fields, nil
+}
+
+// parseLEEFDelimiter parses the delimiter of a LEEF 2.0 event, which is either a single character
+// or its hexadecimal code, e.g. x09 or 0x09.
+func parseLEEFDelimiter(d string) (string, error) {
+	if len(d) == 1 {
+		return d, nil
+	}
+	hex := strings.TrimPrefix(strings.TrimPrefix(strings.ToLower(d), "0"), "x")
+	code, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return "", fmt.Errorf("invalid delimiter %s", d)
+	}
+	return string(rune(code)), nil
+}
+
+// splitEventHeader splits the first n fields of the pipe separated header, unescaping \| and \\,
+// and returns them along with the rest of the event.
+func splitEventHeader(s string, n int) ([]string, string, bool) {
+	fields := make([]string, 0, n)
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch ch := s[i]; {
+		case ch == '\\' && i+1 < len(s) && (s[i+1] == '|' || s[i+1] == '\\'):
+			sb.WriteByte(s[i+1])
+			i++
+		case ch == '|':
+			fields = append(fields, sb.String())
+			sb.Reset()
+			if len(fields) == n {
+				return fields, s[i+1:], true
+			}
+		default:
+			sb.WriteByte(ch)
+		}
+	}
+	return nil, "", false
+}
+
+// parseCEFExtension parses the space separated key=value pairs of a CEF extension. Values may contain spaces,
+// so a value ends where the next key starts.
+func parseCEFExtension(s string, into map[string]string) {
+	type pair struct{ keyStart, eq int }
+	var pairs []pair
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '=':
+			start := i
+			for start > 0 && s[start-1] != ' ' {
+				start--
+			}
+			if start < i && isCEFKey(s[start:i]) {
+				pairs = append(pairs, pair{keyStart: start, eq: i})
+			}
+		}
+	}
+	for i, p := range pairs {
+		end := len(s)
+		if i+1 < len(pairs) {
+			end = pairs[i+1].keyStart
+		}
+		into[s[p.keyStart:p.eq]] = unescapeCEFValue(strings.TrimSpace(s[p.eq+1 : end]))
+	}
+}
+
+func isCEFKey(s string) bool {
+	for _, r := range s {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '.' || r == '-' || r == '[' || r == ']') {
+			return false
+		}
+	}
+	return true
+}
+
+func unescapeCEFValue(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var sb strings.Builder
+	sb.Grow(len(s))
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			sb.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n':
+			sb.WriteByte('\n')
+		case 'r':
+			sb.WriteByte('\r')
+		default:
+			sb.WriteByte(s[i])
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,239 @@
+package stages
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/pkg/push"
+
+	util_log "github.com/grafana/loki/v3/pkg/util/log"
+)
+
+var testCEFYamlAllFields = `
+pipeline_stages:
+- cef:
+`
+
+var testCEFYamlMapping = `
+pipeline_stages:
+- cef:
+    mapping:
+      source_ip: src
+      severity:
+    structured_metadata:
+      source_ip:
+`
+
+var testCEFYamlSharedSource = `
+pipeline_stages:
+- cef:
+    mapping:
+      source_ip: src
+      client_ip: src
+      src:
+`
+
+func TestPipeline_CEF(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		config          string
+		entry           string
+		expectedExtract map[string]interface{}
+	}{
+		"cef with syslog prefix": {
+			testCEFYamlAllFields,
+			`Sep 19 08:26:10 host CEF:0|Security|threat\|manager|1.0|100|worm successfully stopped|10|src=10.0.0.1 dst=2.1.2.2 spt=1232 msg=Detected a threat.\nNo action \= needed cs1Label=path cs1=C:\\Windows`,
+			map[string]interface{}{
+				"version":        "0",
+				"device_vendor":  "Security",
+				"device_product": "threat|manager",
+				"device_version": "1.0",
+				"signature_id":   "100",
+				"name":           "worm successfully stopped",
+				"severity":       "10",
+				"src":            "10.0.0.1",
+				"dst":            "2.1.2.2",
+				"spt":            "1232",
+				"msg":            "Detected a threat.\nNo action = needed",
+				"cs1Label":       "path",
+				"cs1":            `C:\Windows`,
+			},
+		},
+		"cef with mapping": {
+			testCEFYamlMapping,
+			`CEF:0|Security|threatmanager|1.0|100|worm successfully stopped|10|src=10.0.0.1 dst=2.1.2.2`,
+			map[string]interface{}{
+				"source_ip": "10.0.0.1",
+				"severity":  "10",
+			},
+		},
+		"cef with keys mapped to the same field": {
+			testCEFYamlSharedSource,
+			`CEF:0|Security|threatmanager|1.0|100|worm successfully stopped|10|src=10.0.0.1 dst=2.1.2.2`,
+			map[string]interface{}{
+				"source_ip": "10.0.0.1",
+				"client_ip": "10.0.0.1",
+				"src":       "10.0.0.1",
+			},
+		},
+		"leef 1.0": {
+			testCEFYamlAllFields,
+			"LEEF:1.0|Microsoft|MSExchange|4.0 SP1|15345|src=192.0.2.0\tdst=172.50.123.1\tsev=5\tmsg=this is a message",
+			map[string]interface{}{
+				"version":        "1.0",
+				"device_vendor":  "Microsoft",
+				"device_product": "MSExchange",
+				"device_version": "4.0 SP1",
+				"event_id":       "15345",
+				"src":            "192.0.2.0",
+				"dst":            "172.50.123.1",
+				"sev":            "5",
+				"msg":            "this is a message",
+			},
+		},
+		"leef 2.0 with delimiter": {
+			testCEFYamlAllFields,
+			"LEEF:2.0|Lancope|StealthWatch|1.0|41|^|src=10.0.1.8^dst=10.0.0.5^sev=5",
+			map[string]interface{}{
+				"version":        "2.0",
+				"device_vendor":  "Lancope",
+				"device_product": "StealthWatch",
+				"device_version": "1.0",
+				"event_id":       "41",
+				"src":            "10.0.1.8",
+				"dst":            "10.0.0.5",
+				"sev":            "5",
+			},
+		},
+		"leef 2.0 with hex delimiter": {
+			testCEFYamlAllFields,
+			"LEEF:2.0|Lancope|StealthWatch|1.0|41|0x7c|src=10.0.1.8|dst=10.0.0.5",
+			map[string]interface{}{
+				"version":        "2.0",
+				"device_vendor":  "Lancope",
+				"device_product": "StealthWatch",
+				"device_version": "1.0",
+				"event_id":       "41",
+				"src":            "10.0.1.8",
+				"dst":            "10.0.0.5",
+			},
+		},
+		"not an event": {
+			testCEFYamlAllFields,
+			"level=info msg=hello",
+			map[string]interface{}{},
+		},
+	}
+
+	for testName, testData := range tests {
+		testData := testData
+
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			pl, err := NewPipeline(util_log.Logger, loadConfig(testData.config), nil, prometheus.DefaultRegisterer)
+			require.NoError(t, err)
+			out := processEntries(pl, newEntry(nil, nil, testData.entry, time.Now()))[0]
+			assert.Equal(t, testData.expectedExtract, out.Extracted)
+		})
+	}
+}
+
+func TestCEFConfig_Validate(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		config      interface{}
+		wantMapping map[string][]string
+		err         error
+	}{
+		"empty source": {
+			map[string]interface{}{
+				"source": "",
+			},
+			nil,
+			errors.New(ErrEmptyCEFStageSource),
+		},
+		"no mapping": {
+			map[string]interface{}{},
+			nil,
+			nil,
+		},
+		"mapping": {
+			map[string]interface{}{
+				"mapping": map[string]string{
+					"source_ip": "src",
+					"severity":  "",
+				},
+			},
+			map[string][]string{
+				"src":      {"source_ip"},
+				"severity": {"severity"},
+			},
+			nil,
+		},
+		"keys mapped to the same field": {
+			map[string]interface{}{
+				"mapping": map[string]string{
+					"source_ip": "src",
+					"client_ip": "src",
+					"src":       "",
+				},
+			},
+			map[string][]string{
+				"src": {"client_ip", "source_ip", "src"},
+			},
+			nil,
+		},
+	}
+	for tName, tt := range tests {
+		tt := tt
+		t.Run(tName, func(t *testing.T) {
+			t.Parallel()
+
+			c, err := parseCEFConfig(tt.config)
+			require.NoError(t, err)
+			got, err := validateCEFConfig(c)
+			if tt.err != nil {
+				require.EqualError(t, err, tt.err.Error())
+				return
+			}
+			require.NoError(t, err)
+			for _, keys := range got {
+				sort.Strings(keys)
+			}
+			require.Equal(t, tt.wantMapping, got)
+		})
+	}
+}
+
+func TestPipeline_CEFStructuredMetadata(t *testing.T) {
+	pl, err := NewPipeline(util_log.Logger, loadConfig(testCEFYamlMapping), nil, prometheus.DefaultRegisterer)
+	require.NoError(t, err)
+
+	out := processEntries(pl, newEntry(nil, nil, "CEF:0|Security|threatmanager|1.0|100|worm successfully stopped|10|src=10.0.0.1", time.Now()))[0]
+	require.Equal(t, push.LabelsAdapter{{Name: "source_ip", Value: "10.0.0.1"}}, out.StructuredMetadata)
+}
+
+func TestCEFDropMalformed(t *testing.T) {
+	pl, err := NewPipeline(util_log.Logger, loadConfig(`
+pipeline_stages:
+- cef:
+    drop_malformed: true
+`), nil, prometheus.DefaultRegisterer)
+	require.NoError(t, err)
+
+	out := processEntries(pl,
+		newEntry(nil, nil, "CEF:0|Security|threatmanager", time.Now()),
+		newEntry(nil, nil, "CEF:0|Security|threatmanager|1.0|100|worm successfully stopped|10|", time.Now()),
+	)
+	require.Len(t, out, 1)
+	require.Equal(t, "100", out[0].Extracted["signature_id"])
+}
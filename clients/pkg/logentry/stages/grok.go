@@ -0,0 +1,324 @@
+package stages
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/mitchellh/mapstructure"
+	"github.com/pkg/errors"
+)
+
+// Config Errors
+const (
+	ErrEmptyGrokStageConfig     = "empty grok stage configuration"
+	ErrGrokPatternRequired      = "grok pattern is required"
+	ErrEmptyGrokStageSource     = "empty source"
+	ErrGrokUnknownPattern       = "unknown grok pattern: %s"
+	ErrGrokRecursivePattern     = "recursive grok pattern: %s"
+	ErrGrokInvalidDefinition    = "invalid grok pattern definition: %s"
+	ErrCouldNotCompileGrok      = "could not compile grok pattern"
+	ErrCouldNotLoadGrokPatterns = "could not load grok patterns"
+)
+
+const grokGroupPrefix = "__grok"
+
+// grokReference matches a pattern reference in the form %{PATTERN}, %{PATTERN:field} or %{PATTERN:field:type}.
+var grokReference = regexp.MustCompile(`%\{(\w+)(?::([\w.@\[\]-]+))?(?::(int|float))?\}`)
+
+// GrokConfig contains a grokStage configuration
+type GrokConfig struct {
+	Pattern            string            `mapstructure:"pattern"`
+	PatternDefinitions map[string]string `mapstructure:"pattern_definitions"`
+	PatternsDir        string            `mapstructure:"patterns_dir"`
+	Source             *string           `mapstructure:"source"`
+	StructuredMetadata LabelsConfig      `mapstructure:"structured_metadata"`
+}
+
+// grokField is a field captured by a %{PATTERN:field:type} reference.
+type grokField struct {
+	group int
+	name  string
+	typ   string
+}
+
+// grokStage sets extracted data using grok patterns
+type grokStage struct {
+	cfg        *GrokConfig
+	expression *regexp.Regexp
+	fields     []grokField
+	logger     log.Logger
+}
+
+// newGrokStage creates a new grok pipeline stage from a config.
+func newGrokStage(logger log.Logger, config interface{}) (Stage, error) {
+	cfg, err := parseGrokConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	expression, fields, err := validateGrokConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &grokStage{
+		cfg:        cfg,
+		expression: expression,
+		fields:     fields,
+		logger:     log.With(logger, "component", "stage", "type", "grok"),
+	}, nil
+}
+
+// parseGrokConfig processes an incoming configuration into a GrokConfig
+func parseGrokConfig(config interface{}) (*GrokConfig, error) {
+	cfg := &GrokConfig{}
+	err := mapstructure.Decode(config, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// validateGrokConfig validates the config and compiles the grok pattern into a regular expression
+// along with the fields captured by it.
+func validateGrokConfig(c *GrokConfig) (*regexp.Regexp, []grokField, error) {
+	if c == nil {
+		return nil, nil, errors.New(ErrEmptyGrokStageConfig)
+	}
+	if c.Pattern == "" {
+		return nil, nil, errors.New(ErrGrokPatternRequired)
+	}
+	if c.Source != nil && *c.Source == "" {
+		return nil, nil, errors.New(ErrEmptyGrokStageSource)
+	}
+	if c.StructuredMetadata != nil {
+		if err := validateLabelsConfig(c.StructuredMetadata); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// Definitions from the patterns directory override the bundled ones,
+	// and inline definitions override both.
+	patterns := map[string]string{}
+	if err := parseGrokPatterns(defaultGrokPatterns, patterns); err != nil {
+		return nil, nil, err
+	}
+	if c.PatternsDir != "" {
+		if err := loadGrokPatternsDir(c.PatternsDir, patterns); err != nil {
+			return nil, nil, errors.Wrap(err, ErrCouldNotLoadGrokPatterns)
+		}
+	}
+	for name, def := range c.PatternDefinitions {
+		patterns[name] = def
+	}
+
+	compiler := &grokCompiler{patterns: patterns, visiting: map[string]bool{}}
+	expanded, err := compiler.expand(c.Pattern)
+	if err != nil {
+		return nil, nil, err
+	}
+	expr, err := regexp.Compile(expanded)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, ErrCouldNotCompileGrok)
+	}
+
+	fields := make([]grokField, 0, len(compiler.fields))
+	for i, name := range expr.SubexpNames() {
+		if !strings.HasPrefix(name, grokGroupPrefix) {
+			continue
+		}
+		idx, err := strconv.Atoi(strings.TrimPrefix(name, grokGroupPrefix))
+		if err != nil || idx >= len(compiler.fields) {
+			continue
+		}
+		field := compiler.fields[idx]
+		field.group = i
+		fields = append(fields, field)
+	}
+	return expr, fields, nil
+}
+
+// parseGrokPatterns parses pattern definitions in the `NAME PATTERN` format, one per line.
+// Empty lines and lines starting with # are ignored.
+func parseGrokPatterns(definitions string, into map[string]string) error {
+	scanner := bufio.NewScanner(strings.NewReader(definitions))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		name, def, ok := strings.Cut(line, " ")
+		if !ok || strings.TrimSpace(def) == "" {
+			return fmt.Errorf(ErrGrokInvalidDefinition, line)
+		}
+		into[name] = strings.TrimSpace(def)
+	}
+	return scanner.Err()
+}
+
+// loadGrokPatternsDir loads the pattern definitions of every file in dir, in lexical order.
+func loadGrokPatternsDir(dir string, into map[string]string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		if !e.IsDir() {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		b, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return err
+		}
+		if err := parseGrokPatterns(string(b), into); err != nil {
+			return errors.Wrapf(err, "file %s", name)
+		}
+	}
+	return nil
+}
+
+// grokCompiler expands pattern references into a regular expression, replacing every
+// %{PATTERN:field} reference with a capture group named after the index of the field.
+type grokCompiler struct {
+	patterns map[string]string
+	visiting map[string]bool
+	fields   []grokField
+}
+
+func (c *grokCompiler) expand(pattern string) (string, error) {
+	var err error
+	expanded := grokReference.ReplaceAllStringFunc(pattern, func(ref string) string {
+		if err != nil {
+			return ""
+		}
+		m := grokReference.FindStringSubmatch(ref)
+		name, field, typ := m[1], m[2], m[3]
+
+		def, ok := c.patterns[name]
+		if !ok {
+			err = fmt.Errorf(ErrGrokUnknownPattern, name)
+			return ""
+		}
+		if c.visiting[name] {
+			err = fmt.Errorf(ErrGrokRecursivePattern, name)
+			return ""
+		}
+		c.visiting[name] = true
+		inner, expandErr := c.expand(def)
+		delete(c.visiting, name)
+		if expandErr != nil {
+			err = expandErr
+			return ""
+		}
+
+		if field == "" {
+			return "(?:" + inner + ")"
+		}
+		c.fields = append(c.fields, grokField{name: field, typ: typ})
+		return fmt.Sprintf("(?P<%s%d>%s)", grokGroupPrefix, len(c.fields)-1, inner)
+	})
+	return expanded, err
+}
+
+// Run implements Stage
+func (g *grokStage) Run(in chan Entry) chan Entry {
+	return RunWith(in, func(e Entry) Entry {
+		g.process(e.Extracted, e.Line)
+		if g.cfg.StructuredMetadata != nil {
+			addStructuredMetadata(g.logger, &e, g.cfg.StructuredMetadata)
+		}
+		return e
+	})
+}
+
+func (g *grokStage) process(extracted map[string]interface{}, line string) {
+	// If a source key is provided, the grok stage should process it
+	// from the extracted map, otherwise should fallback to the entry
+	input := line
+
+	if g.cfg.Source != nil {
+		if _, ok := extracted[*g.cfg.Source]; !ok {
+			if Debug {
+				level.Debug(g.logger).Log("msg", "source does not exist in the set of extracted values", "source", *g.cfg.Source)
+			}
+			return
+		}
+
+		value, err := getString(extracted[*g.cfg.Source])
+		if err != nil {
+			if Debug {
+				level.Debug(g.logger).Log("msg", "failed to convert source value to string", "source", *g.cfg.Source, "err", err, "type", reflect.TypeOf(extracted[*g.cfg.Source]))
+			}
+			return
+		}
+
+		input = value
+	}
+
+	match := g.expression.FindStringSubmatchIndex(input)
+	if match == nil {
+		if Debug {
+			level.Debug(g.logger).Log("msg", "grok pattern did not match", "input", input, "pattern", g.cfg.Pattern)
+		}
+		return
+	}
+
+	// A field referenced more than once, e.g. on both sides of an alternation, keeps the first value captured.
+	captured := make(map[string]struct{}, len(g.fields))
+	for _, f := range g.fields {
+		start, end := match[2*f.group], match[2*f.group+1]
+		if start < 0 {
+			continue
+		}
+		if _, ok := captured[f.name]; ok {
+			continue
+		}
+		captured[f.name] = struct{}{}
+		extracted[f.name] = convertGrokValue(input[start:end], f.typ)
+	}
+
+	// Plain named groups in the pattern are extracted as is.
+	for i, name := range g.expression.SubexpNames() {
+		if i == 0 || name == "" || strings.HasPrefix(name, grokGroupPrefix) || match[2*i] < 0 {
+			continue
+		}
+		extracted[name] = input[match[2*i]:match[2*i+1]]
+	}
+
+	if Debug {
+		level.Debug(g.logger).Log("msg", "extracted data debug in grok stage", "extracted data", fmt.Sprintf("%v", extracted))
+	}
+}
+
+// convertGrokValue converts a captured value to the type requested in the pattern reference.
+// Values which can't be converted are kept as strings.
+func convertGrokValue(value, typ string) interface{} {
+	switch typ {
+	case "int":
+		if i, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return i
+		}
+	case "float":
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			return f
+		}
+	}
+	return value
+}
+
+// Name implements Stage
+func (g *grokStage) Name() string {
+	return StageTypeGrok
+}
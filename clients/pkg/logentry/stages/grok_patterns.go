@@ -0,0 +1,89 @@
+package stages
+
+// defaultGrokPatterns is the pattern library bundled with the grok stage. It follows the format of the
+// Logstash pattern files, one `NAME PATTERN` definition per line, with the patterns adjusted to the RE2
+// syntax supported by Go, which has no lookaround or atomic groups.
+const defaultGrokPatterns = `
+# Basic types
+USERNAME [a-zA-Z0-9._-]+
+USER %{USERNAME}
+EMAILLOCALPART [a-zA-Z0-9!#$%&'*+\-/=?^_{|}~]+(?:\.[a-zA-Z0-9!#$%&'*+\-/=?^_{|}~]+)*
+EMAILADDRESS %{EMAILLOCALPART}@%{HOSTNAME}
+INT [+-]?[0-9]+
+BASE10NUM [+-]?(?:[0-9]+(?:\.[0-9]*)?|\.[0-9]+)
+NUMBER %{BASE10NUM}
+BASE16NUM [+-]?(?:0x)?[0-9A-Fa-f]+
+POSINT \b[1-9][0-9]*\b
+NONNEGINT \b[0-9]+\b
+WORD \b\w+\b
+NOTSPACE \S+
+SPACE \s*
+DATA .*?
+GREEDYDATA .*
+QUOTEDSTRING "(?:[^"\\]|\\.)*"|'(?:[^'\\]|\\.)*'
+QS %{QUOTEDSTRING}
+UUID [A-Fa-f0-9]{8}-(?:[A-Fa-f0-9]{4}-){3}[A-Fa-f0-9]{12}
+
+# Networking
+CISCOMAC (?:[A-Fa-f0-9]{4}\.){2}[A-Fa-f0-9]{4}
+WINDOWSMAC (?:[A-Fa-f0-9]{2}-){5}[A-Fa-f0-9]{2}
+COMMONMAC (?:[A-Fa-f0-9]{2}:){5}[A-Fa-f0-9]{2}
+MAC %{CISCOMAC}|%{WINDOWSMAC}|%{COMMONMAC}
+IPV4OCTET 25[0-5]|2[0-4][0-9]|[01]?[0-9][0-9]?
+IPV4 (?:%{IPV4OCTET}\.){3}%{IPV4OCTET}
+IPV6HEXTET [0-9A-Fa-f]{1,4}
+IPV6 (?:(?:%{IPV6HEXTET}:){7}%{IPV6HEXTET}|(?:%{IPV6HEXTET}:){1,7}:|(?:%{IPV6HEXTET}:){1,6}:%{IPV6HEXTET}|(?:%{IPV6HEXTET}:){1,5}(?::%{IPV6HEXTET}){1,2}|(?:%{IPV6HEXTET}:){1,4}(?::%{IPV6HEXTET}){1,3}|(?:%{IPV6HEXTET}:){1,3}(?::%{IPV6HEXTET}){1,4}|(?:%{IPV6HEXTET}:){1,2}(?::%{IPV6HEXTET}){1,5}|%{IPV6HEXTET}:(?::%{IPV6HEXTET}){1,6}|:(?:(?::%{IPV6HEXTET}){1,7}|:)|(?:%{IPV6HEXTET}:){6}%{IPV4}|::(?:ffff(?::0{1,4})?:)?%{IPV4})(?:%[0-9A-Za-z]+)?
+IP %{IPV6}|%{IPV4}
+HOSTNAME \b[0-9A-Za-z][0-9A-Za-z-]{0,62}(?:\.[0-9A-Za-z][0-9A-Za-z-]{0,62})*(?:\.?|\b)
+IPORHOST %{IP}|%{HOSTNAME}
+HOSTPORT %{IPORHOST}:%{POSINT}
+
+# Paths and URIs
+UNIXPATH (?:/[\w%!$@:.,+~-]*)+
+WINPATH (?:[A-Za-z]+:|\\)(?:\\[^\\?*]*)+
+PATH %{UNIXPATH}|%{WINPATH}
+URIPROTO [A-Za-z][A-Za-z0-9+.-]+
+URIHOST %{IPORHOST}(?::%{POSINT})?
+URIPATH (?:/[A-Za-z0-9$.+!*'(){},~:;=@#%&_\-]*)+
+URIQUERY [A-Za-z0-9$.+!*'|(){},~@#%&/=:;_?\-\[\]<>]*
+URIPARAM \?%{URIQUERY}
+URIPATHPARAM %{URIPATH}(?:%{URIPARAM})?
+URI %{URIPROTO}://(?:%{USER}(?::[^@]*)?@)?(?:%{URIHOST})?(?:%{URIPATHPARAM})?
+
+# Dates and times
+MONTH \b(?:Jan(?:uary)?|Feb(?:ruary)?|Mar(?:ch)?|Apr(?:il)?|May|June?|July?|Aug(?:ust)?|Sep(?:tember)?|Oct(?:ober)?|Nov(?:ember)?|Dec(?:ember)?)\b
+MONTHNUM 1[0-2]|0?[1-9]
+MONTHDAY 3[01]|[12][0-9]|0?[1-9]
+DAY \b(?:Mon(?:day)?|Tue(?:sday)?|Wed(?:nesday)?|Thu(?:rsday)?|Fri(?:day)?|Sat(?:urday)?|Sun(?:day)?)\b
+YEAR (?:\d\d){1,2}
+HOUR 2[0123]|[01]?[0-9]
+MINUTE [0-5][0-9]
+SECOND (?:60|[0-5]?[0-9])(?:[:.,][0-9]+)?
+TIME %{HOUR}:%{MINUTE}(?::%{SECOND})?
+DATE_US %{MONTHNUM}[/-]%{MONTHDAY}[/-]%{YEAR}
+DATE_EU %{MONTHDAY}[./-]%{MONTHNUM}[./-]%{YEAR}
+DATE %{DATE_US}|%{DATE_EU}
+DATESTAMP %{DATE}[- ]%{TIME}
+TZ [APMCE][SD]T|UTC
+ISO8601_TIMEZONE Z|[+-]%{HOUR}(?::?%{MINUTE})
+TIMESTAMP_ISO8601 %{YEAR}-%{MONTHNUM}-%{MONTHDAY}[T ]%{HOUR}:?%{MINUTE}(?::?%{SECOND})?%{ISO8601_TIMEZONE}?
+DATESTAMP_RFC822 %{DAY} %{MONTH} %{MONTHDAY} %{YEAR} %{TIME} %{TZ}
+HTTPDATE %{MONTHDAY}/%{MONTH}/%{YEAR}:%{TIME} %{INT}
+
+# Syslog
+SYSLOGTIMESTAMP %{MONTH} +%{MONTHDAY} %{TIME}
+PROG [\x21-\x5a\x5c\x5e-\x7e]+
+SYSLOGPROG %{PROG:program}(?:\[%{POSINT:pid}\])?
+SYSLOGHOST %{IPORHOST}
+SYSLOGFACILITY <%{NONNEGINT:facility}.%{NONNEGINT:priority}>
+SYSLOGBASE %{SYSLOGTIMESTAMP:timestamp} (?:%{SYSLOGFACILITY} )?%{SYSLOGHOST:logsource} %{SYSLOGPROG}:
+SYSLOGLINE %{SYSLOGBASE} %{GREEDYDATA:message}
+
+# Log levels
+LOGLEVEL [Aa]lert|ALERT|[Tt]race|TRACE|[Dd]ebug|DEBUG|[Nn]otice|NOTICE|[Ii]nfo(?:rmation)?|INFO(?:RMATION)?|[Ww]arn(?:ing)?|WARN(?:ING)?|[Ee]rr(?:or)?|ERR(?:OR)?|[Cc]rit(?:ical)?|CRIT(?:ICAL)?|[Ff]atal|FATAL|[Ss]evere|SEVERE|[Ee]merg(?:ency)?|EMERG(?:ENCY)?
+
+# Web servers
+HTTPDUSER %{EMAILADDRESS}|%{USER}
+COMMONAPACHELOG %{IPORHOST:clientip} %{HTTPDUSER:ident} %{HTTPDUSER:auth} \[%{HTTPDATE:timestamp}\] "(?:%{WORD:verb} %{NOTSPACE:request}(?: HTTP/%{NUMBER:httpversion})?|%{DATA:rawrequest})" %{NUMBER:response} (?:%{NUMBER:bytes}|-)
+COMBINEDAPACHELOG %{COMMONAPACHELOG} %{QS:referrer} %{QS:agent}
+`
@@ -0,0 +1,239 @@
+package stages
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/pkg/push"
+
+	util_log "github.com/grafana/loki/v3/pkg/util/log"
+)
+
+var testGrokYamlCombinedApacheLog = `
+pipeline_stages:
+- grok:
+    pattern: "%{COMBINEDAPACHELOG}"
+`
+
+var testGrokYamlCustomPatterns = `
+pipeline_stages:
+- grok:
+    pattern: "%{TIMESTAMP_ISO8601:time} %{LOGLEVEL:level} \\[%{COMPONENT:component}\\] took %{NUMBER:duration:float}ms status=%{INT:status:int}"
+    pattern_definitions:
+      COMPONENT: "[a-z-]+"
+`
+
+var testGrokYamlWithSource = `
+pipeline_stages:
+- regex:
+    expression: "^(?P<prefix>\\S+) (?P<rest>.*)$"
+- grok:
+    pattern: "%{IP:client}:%{POSINT:port}"
+    source: prefix
+`
+
+var testGrokYamlStructuredMetadata = `
+pipeline_stages:
+- grok:
+    pattern: "%{WORD:verb} %{URIPATHPARAM:path} trace=%{UUID:trace_id}"
+    structured_metadata:
+      trace_id:
+`
+
+func TestPipeline_Grok(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		config          string
+		entry           string
+		expectedExtract map[string]interface{}
+	}{
+		"bundled patterns": {
+			testGrokYamlCombinedApacheLog,
+			`127.0.0.1 - frank [10/Oct/2000:13:55:36 -0700] "GET /apache_pb.gif HTTP/1.0" 200 2326 "http://www.example.com/start.html" "Mozilla/4.08"`,
+			map[string]interface{}{
+				"clientip":    "127.0.0.1",
+				"ident":       "-",
+				"auth":        "frank",
+				"timestamp":   "10/Oct/2000:13:55:36 -0700",
+				"verb":        "GET",
+				"request":     "/apache_pb.gif",
+				"httpversion": "1.0",
+				"response":    "200",
+				"bytes":       "2326",
+				"referrer":    `"http://www.example.com/start.html"`,
+				"agent":       `"Mozilla/4.08"`,
+			},
+		},
+		"custom patterns and type conversion": {
+			testGrokYamlCustomPatterns,
+			`2024-05-01T10:00:00Z WARN [query-frontend] took 12.5ms status=200`,
+			map[string]interface{}{
+				"time":      "2024-05-01T10:00:00Z",
+				"level":     "WARN",
+				"component": "query-frontend",
+				"duration":  12.5,
+				"status":    int64(200),
+			},
+		},
+		"source": {
+			testGrokYamlWithSource,
+			`10.0.0.1:8080 GET /`,
+			map[string]interface{}{
+				"prefix": "10.0.0.1:8080",
+				"rest":   "GET /",
+				"client": "10.0.0.1",
+				"port":   "8080",
+			},
+		},
+		"no match": {
+			testGrokYamlCustomPatterns,
+			`this does not match`,
+			map[string]interface{}{},
+		},
+	}
+
+	for testName, testData := range tests {
+		testData := testData
+
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			pl, err := NewPipeline(util_log.Logger, loadConfig(testData.config), nil, prometheus.DefaultRegisterer)
+			require.NoError(t, err)
+			out := processEntries(pl, newEntry(nil, nil, testData.entry, time.Now()))[0]
+			assert.Equal(t, testData.expectedExtract, out.Extracted)
+		})
+	}
+}
+
+func TestPipeline_GrokStructuredMetadata(t *testing.T) {
+	pl, err := NewPipeline(util_log.Logger, loadConfig(testGrokYamlStructuredMetadata), nil, prometheus.DefaultRegisterer)
+	require.NoError(t, err)
+
+	out := processEntries(pl, newEntry(nil, nil, "GET /api/v1/query?limit=10 trace=3f2504e0-4f89-11d3-9a0c-0305e82c3301", time.Now()))[0]
+	require.Equal(t, "/api/v1/query?limit=10", out.Extracted["path"])
+	require.Equal(t, push.LabelsAdapter{{Name: "trace_id", Value: "3f2504e0-4f89-11d3-9a0c-0305e82c3301"}}, out.StructuredMetadata)
+}
+
+func TestGrokConfig_validate(t *testing.T) {
+	t.Parallel()
+
+	patternsDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(patternsDir, "custom"), []byte("# custom patterns\nTENANT [a-z0-9]+\n\nREQUEST tenant=%{TENANT:tenant}\n"), 0o644))
+
+	tests := map[string]struct {
+		config     interface{}
+		wantFields []string
+		err        error
+	}{
+		"empty config": {
+			nil,
+			nil,
+			errors.New(ErrGrokPatternRequired),
+		},
+		"empty source": {
+			map[string]interface{}{
+				"pattern": "%{WORD:word}",
+				"source":  "",
+			},
+			nil,
+			errors.New(ErrEmptyGrokStageSource),
+		},
+		"unknown pattern": {
+			map[string]interface{}{
+				"pattern": "%{NOPE:word}",
+			},
+			nil,
+			errors.Errorf(ErrGrokUnknownPattern, "NOPE"),
+		},
+		"recursive pattern": {
+			map[string]interface{}{
+				"pattern": "%{A}",
+				"pattern_definitions": map[string]string{
+					"A": "a%{B}",
+					"B": "b%{A}",
+				},
+			},
+			nil,
+			errors.Errorf(ErrGrokRecursivePattern, "A"),
+		},
+		"nested fields": {
+			map[string]interface{}{
+				"pattern": "%{SYSLOGBASE} %{GREEDYDATA:message}",
+			},
+			[]string{"timestamp", "facility", "priority", "logsource", "program", "pid", "message"},
+			nil,
+		},
+		"patterns directory": {
+			map[string]interface{}{
+				"pattern":      "%{REQUEST} %{WORD:verb}",
+				"patterns_dir": patternsDir,
+			},
+			[]string{"tenant", "verb"},
+			nil,
+		},
+	}
+	for tName, tt := range tests {
+		tt := tt
+		t.Run(tName, func(t *testing.T) {
+			t.Parallel()
+			c, err := parseGrokConfig(tt.config)
+			require.NoError(t, err)
+			_, fields, err := validateGrokConfig(c)
+			if tt.err != nil {
+				require.EqualError(t, err, tt.err.Error())
+				return
+			}
+			require.NoError(t, err)
+			names := make([]string, 0, len(fields))
+			for _, f := range fields {
+				names = append(names, f.name)
+			}
+			require.Equal(t, tt.wantFields, names)
+		})
+	}
+}
+
+func TestDefaultGrokPatterns_Compile(t *testing.T) {
+	patterns := map[string]string{}
+	require.NoError(t, parseGrokPatterns(defaultGrokPatterns, patterns))
+
+	for name := range patterns {
+		_, _, err := validateGrokConfig(&GrokConfig{Pattern: "%{" + name + "}"})
+		require.NoError(t, err, name)
+	}
+}
+
+func TestGrokDefaultPatterns_Match(t *testing.T) {
+	tests := []struct {
+		pattern string
+		input   string
+		match   string
+	}{
+		{"IPV4", "192.168.0.1", "192.168.0.1"},
+		{"IPV6", "2001:db8::ff00:42:8329", "2001:db8::ff00:42:8329"},
+		{"IPORHOST", "loki-0.loki.svc", "loki-0.loki.svc"},
+		{"MAC", "00:1A:2b:3c:4D:5e", "00:1A:2b:3c:4D:5e"},
+		{"SYSLOGTIMESTAMP", "Jan  2 15:04:05", "Jan  2 15:04:05"},
+		{"TIMESTAMP_ISO8601", "2024-05-01T10:00:00.123+02:00", "2024-05-01T10:00:00.123+02:00"},
+		{"URI", "https://user@example.com:8443/path/to?x=1", "https://user@example.com:8443/path/to?x=1"},
+		{"QUOTEDSTRING", `"say \"hi\""`, `"say \"hi\""`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			expr, fields, err := validateGrokConfig(&GrokConfig{Pattern: "^%{" + tt.pattern + ":value}$"})
+			require.NoError(t, err)
+			match := expr.FindStringSubmatch(tt.input)
+			require.NotNil(t, match)
+			require.Equal(t, tt.match, match[fields[0].group])
+		})
+	}
+}
@@ -41,6 +41,9 @@ const (
 	StageTypeDecolorize      = "decolorize"
 	StageTypeEventLogMessage = "eventlogmessage"
 	StageTypeGeoIP           = "geoip"
+	StageTypeGrok            = "grok"
+	StageTypeXML             = "xml"
+	StageTypeCEF             = "cef"
 	// Deprecated. Renamed to `structured_metadata`. Will be removed after the migration.
 	StageTypeNonIndexedLabels   = "non_indexed_labels"
 	StageTypeStructuredMetadata = "structured_metadata"
@@ -207,6 +210,15 @@ func initCreators() {
 		StageTypeGeoIP: func(params StageCreationParams) (Stage, error) {
 			return newGeoIPStage(params.logger, params.config)
 		},
+		StageTypeGrok: func(params StageCreationParams) (Stage, error) {
+			return newGrokStage(params.logger, params.config)
+		},
+		StageTypeXML: func(params StageCreationParams) (Stage, error) {
+			return newXMLStage(params.logger, params.config)
+		},
+		StageTypeCEF: func(params StageCreationParams) (Stage, error) {
+			return newCEFStage(params.logger, params.config)
+		},
 		StageTypeNonIndexedLabels:   newStructuredMetadataStage,
 		StageTypeStructuredMetadata: newStructuredMetadataStage,
 	}
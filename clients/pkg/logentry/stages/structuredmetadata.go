@@ -35,13 +35,19 @@ func (s *structuredMetadataStage) Name() string {
 
 func (s *structuredMetadataStage) Run(in chan Entry) chan Entry {
 	return RunWith(in, func(e Entry) Entry {
-		processLabelsConfigs(s.logger, e.Extracted, s.cfgs, func(labelName model.LabelName, labelValue model.LabelValue) {
-			e.StructuredMetadata = append(e.StructuredMetadata, logproto.LabelAdapter{Name: string(labelName), Value: string(labelValue)})
-		})
+		addStructuredMetadata(s.logger, &e, s.cfgs)
 		return s.extractFromLabels(e)
 	})
 }
 
+// addStructuredMetadata appends the extracted values selected by cfgs to the structured metadata of the entry.
+// Parsing stages use it to send parsed fields as structured metadata without an additional stage.
+func addStructuredMetadata(logger log.Logger, e *Entry, cfgs LabelsConfig) {
+	processLabelsConfigs(logger, e.Extracted, cfgs, func(labelName model.LabelName, labelValue model.LabelValue) {
+		e.StructuredMetadata = append(e.StructuredMetadata, logproto.LabelAdapter{Name: string(labelName), Value: string(labelValue)})
+	})
+}
+
 func (s *structuredMetadataStage) extractFromLabels(e Entry) Entry {
 	labels := e.Labels
 	foundLabels := []model.LabelName{}
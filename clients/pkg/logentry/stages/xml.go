@@ -0,0 +1,445 @@
+package stages
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/mitchellh/mapstructure"
+	"github.com/pkg/errors"
+)
+
+// Config Errors
+const (
+	ErrXMLExpressionsRequired = "XML path expression is required"
+	ErrCouldNotCompileXMLPath = "could not compile XML path expression"
+	ErrEmptyXMLStageConfig    = "empty xml stage configuration"
+	ErrEmptyXMLStageSource    = "empty source"
+	ErrMalformedXML           = "malformed xml"
+)
+
+// XMLConfig represents a XML Stage configuration
+type XMLConfig struct {
+	Expressions        map[string]string `mapstructure:"expressions"`
+	Source             *string           `mapstructure:"source"`
+	DropMalformed      bool              `mapstructure:"drop_malformed"`
+	StructuredMetadata LabelsConfig      `mapstructure:"structured_metadata"`
+}
+
+// validateXMLConfig validates a xml config and returns a map of compiled path expressions.
+func validateXMLConfig(c *XMLConfig) (map[string]*xmlPath, error) {
+	if c == nil {
+		return nil, errors.New(ErrEmptyXMLStageConfig)
+	}
+
+	if len(c.Expressions) == 0 {
+		return nil, errors.New(ErrXMLExpressionsRequired)
+	}
+
+	if c.Source != nil && *c.Source == "" {
+		return nil, errors.New(ErrEmptyXMLStageSource)
+	}
+
+	if c.StructuredMetadata != nil {
+		if err := validateLabelsConfig(c.StructuredMetadata); err != nil {
+			return nil, err
+		}
+	}
+
+	expressions := map[string]*xmlPath{}
+	for n, e := range c.Expressions {
+		// If there is no expression, use the name as the expression.
+		if e == "" {
+			e = n
+		}
+		path, err := compileXMLPath(e)
+		if err != nil {
+			return nil, errors.Wrap(err, ErrCouldNotCompileXMLPath)
+		}
+		expressions[n] = path
+	}
+	return expressions, nil
+}
+
+// xmlStage sets extracted data using XML path expressions
+type xmlStage struct {
+	cfg         *XMLConfig
+	expressions map[string]*xmlPath
+	logger      log.Logger
+}
+
+// newXMLStage creates a new xml pipeline stage from a config.
+func newXMLStage(logger log.Logger, config interface{}) (Stage, error) {
+	cfg, err := parseXMLConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	expressions, err := validateXMLConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &xmlStage{
+		cfg:         cfg,
+		expressions: expressions,
+		logger:      log.With(logger, "component", "stage", "type", "xml"),
+	}, nil
+}
+
+func parseXMLConfig(config interface{}) (*XMLConfig, error) {
+	cfg := &XMLConfig{}
+	err := mapstructure.Decode(config, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func (x *xmlStage) Run(in chan Entry) chan Entry {
+	out := make(chan Entry)
+	go func() {
+		defer close(out)
+		for e := range in {
+			err := x.processEntry(e.Extracted, &e.Line)
+			if err != nil && x.cfg.DropMalformed {
+				continue
+			}
+			if x.cfg.StructuredMetadata != nil {
+				addStructuredMetadata(x.logger, &e, x.cfg.StructuredMetadata)
+			}
+			out <- e
+		}
+	}()
+	return out
+}
+
+func (x *xmlStage) processEntry(extracted map[string]interface{}, entry *string) error {
+	// If a source key is provided, the xml stage should process it
+	// from the extracted map, otherwise should fallback to the entry
+	input := entry
+
+	if x.cfg.Source != nil {
+		if _, ok := extracted[*x.cfg.Source]; !ok {
+			if Debug {
+				level.Debug(x.logger).Log("msg", "source does not exist in the set of extracted values", "source", *x.cfg.Source)
+			}
+			return nil
+		}
+
+		value, err := getString(extracted[*x.cfg.Source])
+		if err != nil {
+			if Debug {
+				level.Debug(x.logger).Log("msg", "failed to convert source value to string", "source", *x.cfg.Source, "err", err, "type", reflect.TypeOf(extracted[*x.cfg.Source]))
+			}
+			return nil
+		}
+
+		input = &value
+	}
+
+	if input == nil {
+		if Debug {
+			level.Debug(x.logger).Log("msg", "cannot parse a nil entry")
+		}
+		return nil
+	}
+
+	doc, err := parseXMLDocument(*input)
+	if err != nil {
+		if Debug {
+			level.Debug(x.logger).Log("msg", "failed to unmarshal log line", "err", err)
+		}
+		return errors.New(ErrMalformedXML)
+	}
+
+	for n, path := range x.expressions {
+		if value, ok := path.evaluate(doc); ok {
+			extracted[n] = value
+		}
+	}
+	if Debug {
+		level.Debug(x.logger).Log("msg", "extracted data debug in xml stage", "extracted data", fmt.Sprintf("%v", extracted))
+	}
+	return nil
+}
+
+// Name implements Stage
+func (x *xmlStage) Name() string {
+	return StageTypeXML
+}
+
+// xmlNode is an element of a parsed XML document. Namespaces are ignored and
+// elements are matched by their local name only.
+type xmlNode struct {
+	name     string
+	attrs    []xml.Attr
+	text     strings.Builder
+	children []*xmlNode
+}
+
+func (n *xmlNode) attr(name string) (string, bool) {
+	for _, a := range n.attrs {
+		if a.Name.Local == name {
+			return a.Value, true
+		}
+	}
+	return "", false
+}
+
+// textContent returns the trimmed text of the node and all of its descendants.
+func (n *xmlNode) textContent() string {
+	if len(n.children) == 0 {
+		return strings.TrimSpace(n.text.String())
+	}
+	var sb strings.Builder
+	var walk func(*xmlNode)
+	walk = func(node *xmlNode) {
+		sb.WriteString(node.text.String())
+		for _, c := range node.children {
+			walk(c)
+		}
+	}
+	walk(n)
+	return strings.TrimSpace(sb.String())
+}
+
+// parseXMLDocument parses s into a tree of nodes. The returned node is the document itself,
+// with the root element as its only child.
+func parseXMLDocument(s string) (*xmlNode, error) {
+	d := xml.NewDecoder(strings.NewReader(s))
+	// The log line is already decoded, whatever encoding the XML declaration states.
+	d.CharsetReader = func(_ string, input io.Reader) (io.Reader, error) {
+		return input, nil
+	}
+
+	doc := &xmlNode{}
+	stack := []*xmlNode{doc}
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			node := &xmlNode{name: t.Name.Local, attrs: t.Attr}
+			parent := stack[len(stack)-1]
+			parent.children = append(parent.children, node)
+			stack = append(stack, node)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			if len(stack) > 1 {
+				stack[len(stack)-1].text.Write(t)
+			}
+		}
+	}
+	if len(doc.children) == 0 {
+		return nil, errors.New("no root element")
+	}
+	return doc, nil
+}
+
+// xmlPath is a compiled path expression, a subset of XPath which supports:
+//
+//   - absolute paths of element names, e.g. Event/System/EventID, and descendant paths starting with //
+//   - the * wildcard in place of an element name
+//   - predicates on attributes, e.g. Data[@Name='TargetUserName'] or Data[@Name], and on positions, e.g. Data[2]
+//   - selecting an attribute of the matched element as the last step, e.g. Event/System/Provider/@Name
+type xmlPath struct {
+	descendant bool
+	steps      []xmlStep
+	attr       string
+}
+
+type xmlStep struct {
+	name       string
+	attrs      []xmlAttrPredicate
+	position   int
+	matchesAny bool
+}
+
+type xmlAttrPredicate struct {
+	name     string
+	value    string
+	hasValue bool
+}
+
+func compileXMLPath(expr string) (*xmlPath, error) {
+	p := &xmlPath{}
+	switch {
+	case strings.HasPrefix(expr, "//"):
+		p.descendant = true
+		expr = expr[2:]
+	case strings.HasPrefix(expr, "/"):
+		expr = expr[1:]
+	}
+
+	parts, err := splitXMLPath(expr)
+	if err != nil {
+		return nil, err
+	}
+	for i, part := range parts {
+		if strings.HasPrefix(part, "@") {
+			if i != len(parts)-1 {
+				return nil, fmt.Errorf("attribute %s must be the last step of the path", part)
+			}
+			p.attr = part[1:]
+			if p.attr == "" {
+				return nil, errors.New("empty attribute name")
+			}
+			continue
+		}
+		step, err := parseXMLStep(part)
+		if err != nil {
+			return nil, err
+		}
+		p.steps = append(p.steps, step)
+	}
+	if len(p.steps) == 0 {
+		return nil, errors.New("path must select at least one element")
+	}
+	return p, nil
+}
+
+// splitXMLPath splits a path on the / separators which are not part of a predicate.
+func splitXMLPath(expr string) ([]string, error) {
+	var (
+		parts []string
+		depth int
+		start int
+	)
+	for i := 0; i < len(expr); i++ {
+		switch expr[i] {
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth < 0 {
+				return nil, fmt.Errorf("unexpected ] in %s", expr)
+			}
+		case '/':
+			if depth == 0 {
+				parts = append(parts, expr[start:i])
+				start = i + 1
+			}
+		}
+	}
+	if depth != 0 {
+		return nil, fmt.Errorf("unterminated predicate in %s", expr)
+	}
+	parts = append(parts, expr[start:])
+	for _, part := range parts {
+		if part == "" {
+			return nil, fmt.Errorf("empty step in %s", expr)
+		}
+	}
+	return parts, nil
+}
+
+func parseXMLStep(s string) (xmlStep, error) {
+	name, rest, _ := strings.Cut(s, "[")
+	step := xmlStep{name: name, matchesAny: name == "*"}
+	if name == "" {
+		return step, fmt.Errorf("missing element name in %s", s)
+	}
+	for rest != "" {
+		pred, remaining, ok := strings.Cut(rest, "]")
+		if !ok {
+			return step, fmt.Errorf("unterminated predicate in %s", s)
+		}
+		rest = strings.TrimPrefix(remaining, "[")
+
+		if !strings.HasPrefix(pred, "@") {
+			pos, err := strconv.Atoi(pred)
+			if err != nil || pos < 1 {
+				return step, fmt.Errorf("invalid predicate [%s]", pred)
+			}
+			step.position = pos
+			continue
+		}
+		attrName, value, hasValue := strings.Cut(pred[1:], "=")
+		if hasValue {
+			unquoted, err := strconv.Unquote(strings.ReplaceAll(value, "'", `"`))
+			if err != nil {
+				return step, fmt.Errorf("invalid attribute value in predicate [%s]", pred)
+			}
+			value = unquoted
+		}
+		step.attrs = append(step.attrs, xmlAttrPredicate{name: attrName, value: value, hasValue: hasValue})
+	}
+	return step, nil
+}
+
+func (s xmlStep) matches(n *xmlNode) bool {
+	if !s.matchesAny && n.name != s.name {
+		return false
+	}
+	for _, p := range s.attrs {
+		v, ok := n.attr(p.name)
+		if !ok || (p.hasValue && v != p.value) {
+			return false
+		}
+	}
+	return true
+}
+
+// selectChildren returns the children of parent matched by the step.
+func (s xmlStep) selectChildren(parent *xmlNode) []*xmlNode {
+	var selected []*xmlNode
+	var pos int
+	for _, c := range parent.children {
+		if !s.matches(c) {
+			continue
+		}
+		pos++
+		if s.position == 0 || s.position == pos {
+			selected = append(selected, c)
+		}
+	}
+	return selected
+}
+
+// evaluate returns the value selected by the path in doc. If the path matches several nodes, the first one
+// in document order is used.
+func (p *xmlPath) evaluate(doc *xmlNode) (string, bool) {
+	parents := []*xmlNode{doc}
+	if p.descendant {
+		parents = parents[:0]
+		var walk func(*xmlNode)
+		walk = func(n *xmlNode) {
+			parents = append(parents, n)
+			for _, c := range n.children {
+				walk(c)
+			}
+		}
+		walk(doc)
+	}
+
+	for _, step := range p.steps {
+		var next []*xmlNode
+		for _, parent := range parents {
+			next = append(next, step.selectChildren(parent)...)
+		}
+		if len(next) == 0 {
+			return "", false
+		}
+		parents = next
+	}
+
+	if p.attr == "" {
+		return parents[0].textContent(), true
+	}
+	for _, n := range parents {
+		if v, ok := n.attr(p.attr); ok {
+			return v, true
+		}
+	}
+	return "", false
+}
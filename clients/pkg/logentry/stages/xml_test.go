@@ -0,0 +1,165 @@
+package stages
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/pkg/push"
+
+	util_log "github.com/grafana/loki/v3/pkg/util/log"
+)
+
+var testXMLWindowsEvent = `<?xml version="1.0" encoding="UTF-16"?>
+<Event xmlns="http://schemas.microsoft.com/win/2004/08/events/event">
+  <System>
+    <Provider Name="Microsoft-Windows-Security-Auditing" Guid="{54849625-5478-4994-a5ba-3e3b0328c30d}"/>
+    <EventID>4624</EventID>
+    <Level>0</Level>
+    <Computer>dc01.example.com</Computer>
+  </System>
+  <EventData>
+    <Data Name="SubjectUserName">DC01$</Data>
+    <Data Name="TargetUserName">alice</Data>
+    <Data Name="LogonType">3</Data>
+  </EventData>
+</Event>`
+
+var testXMLYamlWindowsEvent = `
+pipeline_stages:
+- xml:
+    expressions:
+      event_id: Event/System/EventID
+      provider: Event/System/Provider/@Name
+      user: Event/EventData/Data[@Name='TargetUserName']
+      logon_type: //Data[@Name="LogonType"]
+      first_data: Event/EventData/Data[1]
+      computer: //Computer
+      missing: Event/System/Task
+    structured_metadata:
+      user:
+`
+
+var testXMLYamlWithSource = `
+pipeline_stages:
+- regex:
+    expression: "^(?P<level>\\S+) (?P<payload>.*)$"
+- xml:
+    expressions:
+      id: order/@id
+      item: order/item
+    source: payload
+`
+
+func TestPipeline_XML(t *testing.T) {
+	t.Parallel()
+
+	pl, err := NewPipeline(util_log.Logger, loadConfig(testXMLYamlWindowsEvent), nil, prometheus.DefaultRegisterer)
+	require.NoError(t, err)
+	out := processEntries(pl, newEntry(nil, nil, testXMLWindowsEvent, time.Now()))[0]
+	assert.Equal(t, map[string]interface{}{
+		"event_id":   "4624",
+		"provider":   "Microsoft-Windows-Security-Auditing",
+		"user":       "alice",
+		"logon_type": "3",
+		"first_data": "DC01$",
+		"computer":   "dc01.example.com",
+	}, out.Extracted)
+	assert.Equal(t, push.LabelsAdapter{{Name: "user", Value: "alice"}}, out.StructuredMetadata)
+
+	pl, err = NewPipeline(util_log.Logger, loadConfig(testXMLYamlWithSource), nil, prometheus.DefaultRegisterer)
+	require.NoError(t, err)
+	out = processEntries(pl, newEntry(nil, nil, `info <order id="42"><item> widget </item></order>`, time.Now()))[0]
+	assert.Equal(t, map[string]interface{}{
+		"level":   "info",
+		"payload": `<order id="42"><item> widget </item></order>`,
+		"id":      "42",
+		"item":    "widget",
+	}, out.Extracted)
+}
+
+func TestXMLDropMalformed(t *testing.T) {
+	t.Parallel()
+
+	pl, err := NewPipeline(util_log.Logger, loadConfig(`
+pipeline_stages:
+- xml:
+    drop_malformed: true
+    expressions:
+      id: order/@id
+`), nil, prometheus.DefaultRegisterer)
+	require.NoError(t, err)
+	out := processEntries(pl,
+		newEntry(nil, nil, `<order id="1">`, time.Now()),
+		newEntry(nil, nil, `<order id="2"/>`, time.Now()),
+	)
+	require.Len(t, out, 1)
+	require.Equal(t, "2", out[0].Extracted["id"])
+}
+
+func TestXMLConfig_validate(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		config interface{}
+		err    error
+	}{
+		"empty config": {
+			nil,
+			errors.New(ErrXMLExpressionsRequired),
+		},
+		"empty source": {
+			map[string]interface{}{
+				"expressions": map[string]string{"id": "order/@id"},
+				"source":      "",
+			},
+			errors.New(ErrEmptyXMLStageSource),
+		},
+		"attribute before the last step": {
+			map[string]interface{}{
+				"expressions": map[string]string{"id": "order/@id/item"},
+			},
+			errors.Wrap(errors.New("attribute @id must be the last step of the path"), ErrCouldNotCompileXMLPath),
+		},
+		"unterminated predicate": {
+			map[string]interface{}{
+				"expressions": map[string]string{"data": "Data[@Name='x'"},
+			},
+			errors.Wrap(errors.New("unterminated predicate in Data[@Name='x'"), ErrCouldNotCompileXMLPath),
+		},
+		"invalid position": {
+			map[string]interface{}{
+				"expressions": map[string]string{"data": "Data[0]"},
+			},
+			errors.Wrap(errors.New("invalid predicate [0]"), ErrCouldNotCompileXMLPath),
+		},
+		"valid": {
+			map[string]interface{}{
+				"expressions": map[string]string{
+					"id":       "",
+					"provider": "/Event/*/Provider[@Name]/@Name",
+					"data":     "//Data[@Name='a/b'][2]",
+				},
+			},
+			nil,
+		},
+	}
+	for tName, tt := range tests {
+		tt := tt
+		t.Run(tName, func(t *testing.T) {
+			t.Parallel()
+			c, err := parseXMLConfig(tt.config)
+			require.NoError(t, err)
+			_, err = validateXMLConfig(c)
+			if tt.err != nil {
+				require.EqualError(t, err, tt.err.Error())
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
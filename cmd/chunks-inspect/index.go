@@ -0,0 +1,293 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"strings"
+)
+
+const (
+	tsdbMagic     = 0xBAAAD700
+	tsdbFormatV2  = 2
+	tsdbFormatV3  = 3
+	tsdbHeaderLen = 5
+	tsdbTOCLen    = 8*9 + 4
+
+	// tenantLabel is added to the series of multi-tenant TSDB indexes built by ingesters.
+	tenantLabel = "__loki_tenant__"
+)
+
+// TSDBIndex is a TSDB index file as written by Loki.
+type TSDBIndex struct {
+	data    []byte
+	version int
+	symbols []string
+
+	seriesStart, seriesEnd uint64
+
+	// time range of all chunks in the index, unix milliseconds
+	from, through int64
+}
+
+// TSDBSeries is a series of a TSDB index together with the metadata of its chunks.
+type TSDBSeries struct {
+	Fingerprint uint64
+	Labels      Labels
+	Chunks      []TSDBChunkMeta
+}
+
+// TSDBChunkMeta is the metadata of a chunk, as stored in the TSDB index.
+type TSDBChunkMeta struct {
+	MinTime, MaxTime int64 // unix milliseconds
+	KB, Entries      uint32
+	Checksum         uint32
+}
+
+func openTSDBIndex(filename string) (*TSDBIndex, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	// Indexes are uploaded to the object store compressed.
+	if strings.HasSuffix(filename, ".gz") {
+		r, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress index: %w", err)
+		}
+		if data, err = io.ReadAll(r); err != nil {
+			return nil, fmt.Errorf("failed to decompress index: %w", err)
+		}
+	}
+
+	return parseTSDBIndex(data)
+}
+
+func parseTSDBIndex(data []byte) (*TSDBIndex, error) {
+
+	/* Loki TSDB Index Format
+
+	4B magic number
+	1B version
+	Symbols <----------------------------------- A
+	Series (16B aligned) <---------------------- B
+	...
+	TOC:
+	8B Symbols offset -------------------------> A
+	8B Series offset --------------------------> B
+	8B Label indices offset
+	8B Label indices table offset
+	8B Postings offset
+	8B Postings table offset
+	8B Fingerprint offsets offset
+	8B From
+	8B Through
+	4B TOC Checksum
+	*/
+
+	if len(data) < tsdbHeaderLen+tsdbTOCLen {
+		return nil, fmt.Errorf("index too short: %d bytes", len(data))
+	}
+	if num := binary.BigEndian.Uint32(data[0:4]); num != tsdbMagic {
+		return nil, fmt.Errorf("invalid magic number: %0x", num)
+	}
+
+	idx := &TSDBIndex{data: data, version: int(data[4])}
+	if idx.version != tsdbFormatV2 && idx.version != tsdbFormatV3 {
+		return nil, fmt.Errorf("unsupported index version: %d", idx.version)
+	}
+
+	toc := data[len(data)-tsdbTOCLen:]
+	if stored, computed := binary.BigEndian.Uint32(toc[len(toc)-4:]), crc32.Checksum(toc[:len(toc)-4], castagnoliTable); stored != computed {
+		return nil, fmt.Errorf("bad TOC checksum %08x, computed %08x", stored, computed)
+	}
+
+	offsets := make([]uint64, 7)
+	for i := range offsets {
+		offsets[i] = binary.BigEndian.Uint64(toc[i*8:])
+	}
+	idx.from = int64(binary.BigEndian.Uint64(toc[7*8:]))
+	idx.through = int64(binary.BigEndian.Uint64(toc[8*8:]))
+
+	// Series are followed by the first non-empty section after them.
+	idx.seriesStart = offsets[1]
+	idx.seriesEnd = uint64(len(data) - tsdbTOCLen)
+	for _, off := range offsets[2:] {
+		if off > idx.seriesStart && off < idx.seriesEnd {
+			idx.seriesEnd = off
+		}
+	}
+
+	var err error
+	idx.symbols, err = readTSDBSymbols(data, offsets[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to read symbols: %w", err)
+	}
+
+	return idx, nil
+}
+
+// readTSDBSymbols reads the symbols section: 4B length, 4B number of symbols,
+// the symbols prefixed by their length and a 4B checksum.
+func readTSDBSymbols(data []byte, off uint64) ([]string, error) {
+	if off+4 > uint64(len(data)) {
+		return nil, fmt.Errorf("invalid symbols offset %d", off)
+	}
+	length := uint64(binary.BigEndian.Uint32(data[off:]))
+	start := off + 4
+	if start+length+4 > uint64(len(data)) {
+		return nil, fmt.Errorf("invalid symbols length %d", length)
+	}
+	section := data[start : start+length]
+	if stored, computed := binary.BigEndian.Uint32(data[start+length:]), crc32.Checksum(section, castagnoliTable); stored != computed {
+		return nil, fmt.Errorf("bad checksum %08x, computed %08x", stored, computed)
+	}
+
+	count := binary.BigEndian.Uint32(section)
+	buf := section[4:]
+	symbols := make([]string, 0, count)
+
+	var err error
+	for i := uint32(0); i < count; i++ {
+		var l uint64
+		l, buf, err = readUvarint(err, buf)
+		if err != nil {
+			return nil, err
+		}
+		if uint64(len(buf)) < l {
+			return nil, fmt.Errorf("not enough symbol data, need %d, got %d", l, len(buf))
+		}
+		symbols = append(symbols, string(buf[:l]))
+		buf = buf[l:]
+	}
+	return symbols, nil
+}
+
+// Series calls fn for every series of the index, in the order they are stored.
+func (idx *TSDBIndex) Series(fn func(s TSDBSeries) error) error {
+	pos := idx.seriesStart
+	for {
+		// Every series is 16 byte aligned.
+		if rem := pos % 16; rem != 0 {
+			pos += 16 - rem
+		}
+		if pos >= idx.seriesEnd {
+			return nil
+		}
+
+		length, n := binary.Uvarint(idx.data[pos:idx.seriesEnd])
+		if n <= 0 {
+			return fmt.Errorf("failed to read series length at %d", pos)
+		}
+		start := pos + uint64(n)
+		end := start + length
+		if end+4 > idx.seriesEnd {
+			return fmt.Errorf("series at %d exceeds series section", pos)
+		}
+
+		b := idx.data[start:end]
+		if stored, computed := binary.BigEndian.Uint32(idx.data[end:]), crc32.Checksum(b, castagnoliTable); stored != computed {
+			return fmt.Errorf("series at %d: bad checksum %08x, computed %08x", pos, stored, computed)
+		}
+
+		s, err := idx.decodeSeries(b)
+		if err != nil {
+			return fmt.Errorf("series at %d: %w", pos, err)
+		}
+		if err := fn(s); err != nil {
+			return err
+		}
+
+		pos = end + 4
+	}
+}
+
+func (idx *TSDBIndex) decodeSeries(b []byte) (TSDBSeries, error) {
+	if len(b) < 8 {
+		return TSDBSeries{}, fmt.Errorf("series too short")
+	}
+	s := TSDBSeries{Fingerprint: binary.BigEndian.Uint64(b)}
+	buf := b[8:]
+
+	var err error
+	var nLabels uint64
+	nLabels, buf, err = readUvarint(err, buf)
+	for i := uint64(0); i < nLabels && err == nil; i++ {
+		var name, value uint64
+		name, buf, err = readUvarint(err, buf)
+		value, buf, err = readUvarint(err, buf)
+		if err != nil {
+			break
+		}
+		if name >= uint64(len(idx.symbols)) || value >= uint64(len(idx.symbols)) {
+			return s, fmt.Errorf("symbol out of range, got %d and %d, have %d symbols", name, value, len(idx.symbols))
+		}
+		s.Labels = append(s.Labels, Label{Name: idx.symbols[name], Value: idx.symbols[value]})
+	}
+	if err != nil {
+		return s, fmt.Errorf("failed to read labels: %w", err)
+	}
+
+	s.Chunks, err = idx.decodeChunkMetas(buf)
+	if err != nil {
+		return s, fmt.Errorf("failed to read chunks: %w", err)
+	}
+	return s, nil
+}
+
+func (idx *TSDBIndex) decodeChunkMetas(buf []byte) ([]TSDBChunkMeta, error) {
+	var err error
+	var nChunks uint64
+	nChunks, buf, err = readUvarint(err, buf)
+	if err != nil {
+		return nil, err
+	}
+
+	// V3 indexes store chunk page markers before the chunks, which are not needed when reading all chunks.
+	if idx.version >= tsdbFormatV3 {
+		if len(buf) < 4 {
+			return nil, fmt.Errorf("failed to read length of chunk page markers")
+		}
+		markersLen := binary.BigEndian.Uint32(buf)
+		if uint64(len(buf)) < 4+uint64(markersLen) {
+			return nil, fmt.Errorf("chunk page markers exceed series")
+		}
+		buf = buf[4+markersLen:]
+	}
+
+	chunks := make([]TSDBChunkMeta, 0, nChunks)
+	var prevMaxT int64
+	for i := uint64(0); i < nChunks; i++ {
+		var delta int64
+		var span, kb, entries uint64
+		c := TSDBChunkMeta{}
+
+		// The min time is delta encoded against the max time of the previous chunk.
+		delta, buf, err = readVarint(err, buf)
+		span, buf, err = readUvarint(err, buf)
+		kb, buf, err = readUvarint(err, buf)
+		entries, buf, err = readUvarint(err, buf)
+		if err != nil {
+			return nil, err
+		}
+		if len(buf) < 4 {
+			return nil, fmt.Errorf("failed to read checksum of chunk %d", i)
+		}
+
+		c.MinTime = prevMaxT + delta
+		c.MaxTime = c.MinTime + int64(span)
+		c.KB = uint32(kb)
+		c.Entries = uint32(entries)
+		c.Checksum = binary.BigEndian.Uint32(buf)
+		buf = buf[4:]
+
+		prevMaxT = c.MaxTime
+		chunks = append(chunks, c)
+	}
+	return chunks, nil
+}
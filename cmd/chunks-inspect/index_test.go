@@ -0,0 +1,224 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// The indexes in testdata are written by the TSDB index builder of Loki and hold the series
+// {app="api", env="prod"} with the chunk in testdata/chunk-v4, and {app="db", env="prod"} with two chunks.
+var (
+	testAPILabels = Labels{{Name: "app", Value: "api"}, {Name: "env", Value: "prod"}}
+	testDBLabels  = Labels{{Name: "app", Value: "db"}, {Name: "env", Value: "prod"}}
+
+	testAPIChunks = []TSDBChunkMeta{
+		{MinTime: 1704103200000, MaxTime: 1704103205000, KB: 1, Entries: 6, Checksum: 0x9d2599dc},
+	}
+	testDBChunks = []TSDBChunkMeta{
+		{MinTime: 1704099600000, MaxTime: 1704103199000, KB: 2, Entries: 10, Checksum: 0xaa},
+		{MinTime: 1704103200000, MaxTime: 1704106799000, KB: 3, Entries: 20, Checksum: 0xbb},
+	}
+)
+
+func TestOpenTSDBIndex(t *testing.T) {
+	for _, tc := range []struct {
+		file    string
+		version int
+	}{
+		{file: "index-v2.tsdb", version: 2},
+		{file: "index-v3.tsdb.gz", version: 3},
+	} {
+		t.Run(tc.file, func(t *testing.T) {
+			idx, err := openTSDBIndex(filepath.Join("testdata", tc.file))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if idx.version != tc.version {
+				t.Errorf("expected version %d, got %d", tc.version, idx.version)
+			}
+			if idx.from != 1704099600000 || idx.through != 1704106799000 {
+				t.Errorf("unexpected index time range %d-%d", idx.from, idx.through)
+			}
+
+			var series []TSDBSeries
+			if err := idx.Series(func(s TSDBSeries) error {
+				series = append(series, s)
+				return nil
+			}); err != nil {
+				t.Fatal(err)
+			}
+
+			if len(series) != 2 {
+				t.Fatalf("expected 2 series, got %d", len(series))
+			}
+			if !reflect.DeepEqual(series[0].Labels, testAPILabels) {
+				t.Errorf("unexpected labels %v", series[0].Labels)
+			}
+			if series[0].Fingerprint != 0x5fd68e2544215f6c {
+				t.Errorf("unexpected fingerprint %x", series[0].Fingerprint)
+			}
+			if !reflect.DeepEqual(series[0].Chunks, testAPIChunks) {
+				t.Errorf("unexpected chunks %+v", series[0].Chunks)
+			}
+			if !reflect.DeepEqual(series[1].Labels, testDBLabels) {
+				t.Errorf("unexpected labels %v", series[1].Labels)
+			}
+			if !reflect.DeepEqual(series[1].Chunks, testDBChunks) {
+				t.Errorf("unexpected chunks %+v", series[1].Chunks)
+			}
+		})
+	}
+}
+
+func TestParseTSDBIndex_Invalid(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "index-v2.tsdb"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, data := range map[string][]byte{
+		"empty":     nil,
+		"bad magic": append([]byte{0, 0, 0, 0}, data[4:]...),
+		"truncated": data[:len(data)/2],
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := parseTSDBIndex(data); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestFindChunks(t *testing.T) {
+	idx, err := openTSDBIndex(filepath.Join("testdata", "index-v2.tsdb"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name          string
+		selector      string
+		tenant        string
+		from, through int64
+		expected      []TSDBChunkMeta
+		expectedErr   bool
+	}{
+		{
+			name:     "all chunks of a series",
+			selector: `{app="db"}`,
+			tenant:   "fake",
+			from:     idx.from,
+			through:  idx.through + 1,
+			expected: testDBChunks,
+		},
+		{
+			name:     "chunks overlapping the time range",
+			selector: `{env="prod"}`,
+			tenant:   "fake",
+			from:     1704103199000,
+			through:  1704103200000,
+			expected: testDBChunks[:1],
+		},
+		{
+			name:     "regex selector",
+			selector: `{app=~"a.*"}`,
+			tenant:   "fake",
+			from:     idx.from,
+			through:  idx.through + 1,
+			expected: testAPIChunks,
+		},
+		{
+			name:     "no match",
+			selector: `{app="web"}`,
+			tenant:   "fake",
+			from:     idx.from,
+			through:  idx.through + 1,
+		},
+		{
+			name:        "no tenant",
+			selector:    `{app="db"}`,
+			from:        idx.from,
+			through:     idx.through + 1,
+			expectedErr: true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			sel, err := parseSelector(tc.selector)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			refs, err := findChunks(idx, sel, tc.tenant, tc.from, tc.through)
+			if tc.expectedErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var metas []TSDBChunkMeta
+			for _, ref := range refs {
+				if ref.UserID != tc.tenant {
+					t.Errorf("expected tenant %s, got %s", tc.tenant, ref.UserID)
+				}
+				metas = append(metas, ref.Meta)
+			}
+			if !reflect.DeepEqual(metas, tc.expected) {
+				t.Errorf("expected chunks %+v, got %+v", tc.expected, metas)
+			}
+		})
+	}
+}
+
+func TestChunkPath(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "chunk-v4"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ref := ChunkRef{UserID: "fake", Fingerprint: 0x5fd68e2544215f6c, Meta: testAPIChunks[0], Labels: testAPILabels}
+	if key := ref.ExternalKey(); key != "fake/5fd68e2544215f6c/18cc4774500:18cc4775888:9d2599dc" {
+		t.Fatalf("unexpected key %s", key)
+	}
+
+	for _, tc := range []struct {
+		name string
+		path string
+	}{
+		// As written by the filesystem object store of Loki since schema v12.
+		{name: "filesystem store", path: "fake/5fd68e2544215f6c/MThjYzQ3NzQ1MDA6MThjYzQ3NzU4ODg6OWQyNTk5ZGM="},
+		{name: "plain key", path: "fake/5fd68e2544215f6c/18cc4774500:18cc4775888:9d2599dc"},
+		{name: "legacy filesystem store", path: "ZmFrZS81ZmQ2OGUyNTQ0MjE1ZjZjOjE4Y2M0Nzc0NTAwOjE4Y2M0Nzc1ODg4OjlkMjU5OWRj"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if _, err := chunkPath(dir, ref); err == nil {
+				t.Fatal("expected an error for a missing chunk")
+			}
+
+			p := filepath.Join(dir, filepath.FromSlash(tc.path))
+			if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(p, data, 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := chunkPath(dir, ref)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != p {
+				t.Errorf("expected path %s, got %s", p, got)
+			}
+			if _, _, _, err := loadFile(got); err != nil {
+				t.Errorf("failed to load chunk: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"time"
+)
+
+type jsonChunk struct {
+	File           string        `json:"file"`
+	UserID         string        `json:"user_id"`
+	From           time.Time     `json:"from"`
+	Through        time.Time     `json:"through"`
+	Labels         Labels        `json:"labels"`
+	Format         byte          `json:"format"`
+	Encoding       string        `json:"encoding"`
+	FileSize       int64         `json:"file_size"`
+	OriginalSize   int           `json:"original_size"`
+	Checksum       jsonChecksum  `json:"metadata_checksum"`
+	Blocks         []jsonBlock   `json:"blocks"`
+	BadBlocks      int           `json:"bad_blocks"`
+	Symbols        []string      `json:"structured_metadata_symbols,omitempty"`
+	SymbolChecksum *jsonChecksum `json:"structured_metadata_checksum,omitempty"`
+}
+
+type jsonChecksum struct {
+	Stored   string `json:"stored"`
+	Computed string `json:"computed"`
+	OK       bool   `json:"ok"`
+}
+
+type jsonBlock struct {
+	Position     uint64       `json:"position"`
+	Entries      uint64       `json:"entries"`
+	MinTime      time.Time    `json:"min_time"`
+	MaxTime      time.Time    `json:"max_time"`
+	StoredSize   int          `json:"stored_size"`
+	OriginalSize int          `json:"original_size"`
+	Checksum     jsonChecksum `json:"checksum"`
+	Lines        []jsonEntry  `json:"lines,omitempty"`
+}
+
+type jsonEntry struct {
+	Timestamp          time.Time `json:"timestamp"`
+	Line               string    `json:"line"`
+	StructuredMetadata Labels    `json:"structured_metadata,omitempty"`
+}
+
+func newJSONChecksum(stored, computed uint32) jsonChecksum {
+	return jsonChecksum{
+		Stored:   fmt.Sprintf("%08x", stored),
+		Computed: fmt.Sprintf("%08x", computed),
+		OK:       stored == computed,
+	}
+}
+
+// printFileJSON prints the details of the chunk file as a single line JSON object.
+func printFileJSON(filename string, printLines bool) {
+	h, lokiChunk, size, err := loadFile(filename)
+	if err != nil {
+		log.Printf("%s: %v", filename, err)
+		return
+	}
+
+	out := jsonChunk{
+		File:      filename,
+		UserID:    h.UserID,
+		From:      h.From.Time().In(timezone),
+		Through:   h.Through.Time().In(timezone),
+		Labels:    h.Metric,
+		Format:    lokiChunk.format,
+		Encoding:  lokiChunk.encoding.String(),
+		FileSize:  size,
+		Checksum:  newJSONChecksum(lokiChunk.metadataChecksum, lokiChunk.computedMetadataChecksum),
+		Blocks:    make([]jsonBlock, 0, len(lokiChunk.blocks)),
+		BadBlocks: lokiChunk.badBlocks(),
+	}
+	if lokiChunk.format >= chunkFormatV4 {
+		out.Symbols = lokiChunk.symbols
+		checksum := newJSONChecksum(lokiChunk.structuredMetadataChecksum, lokiChunk.computedStructuredMetadataChecksum)
+		out.SymbolChecksum = &checksum
+	}
+
+	for _, b := range lokiChunk.blocks {
+		block := jsonBlock{
+			Position:     b.dataOffset,
+			Entries:      b.numEntries,
+			MinTime:      time.Unix(0, b.minT).In(timezone),
+			MaxTime:      time.Unix(0, b.maxT).In(timezone),
+			StoredSize:   len(b.rawData),
+			OriginalSize: len(b.originalData),
+			Checksum:     newJSONChecksum(b.storedChecksum, b.computedChecksum),
+		}
+		if printLines {
+			for _, e := range b.entries {
+				block.Lines = append(block.Lines, jsonEntry{
+					Timestamp:          time.Unix(0, e.timestamp).In(timezone),
+					Line:               e.line,
+					StructuredMetadata: e.structuredMetadata,
+				})
+			}
+		}
+		out.OriginalSize += len(b.originalData)
+		out.Blocks = append(out.Blocks, block)
+	}
+
+	if err := json.NewEncoder(os.Stdout).Encode(out); err != nil {
+		log.Printf("%s: %v", filename, err)
+	}
+}
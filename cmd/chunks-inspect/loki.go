@@ -59,6 +59,14 @@ const (
 	chunkFormatV1
 	chunkFormatV2
 	chunkFormatV3
+	chunkFormatV4
+)
+
+// Starting with V4 chunks, the length and offset of each section are stored at the end of the chunk,
+// indexed from the end: 1 refers to the last pair, 2 to the pair before it.
+const (
+	chunkMetasSectionIdx              = 1
+	chunkStructuredMetadataSectionIdx = 2
 )
 
 type LokiChunk struct {
@@ -69,6 +77,11 @@ type LokiChunk struct {
 
 	metadataChecksum         uint32
 	computedMetadataChecksum uint32
+
+	// structured metadata symbols, V4 chunks only
+	symbols                            []string
+	structuredMetadataChecksum         uint32
+	computedStructuredMetadataChecksum uint32
 }
 
 type LokiBlock struct {
@@ -90,8 +103,20 @@ type LokiBlock struct {
 }
 
 type LokiEntry struct {
-	timestamp int64
-	line      string
+	timestamp          int64
+	line               string
+	structuredMetadata Labels
+}
+
+// badBlocks returns the number of blocks whose stored checksum does not match their data.
+func (c *LokiChunk) badBlocks() int {
+	n := 0
+	for _, b := range c.blocks {
+		if b.storedChecksum != b.computedChecksum {
+			n++
+		}
+	}
+	return n
 }
 
 func parseLokiChunk(chunkHeader *ChunkHeader, r io.Reader) (*LokiChunk, error) {
@@ -114,6 +139,16 @@ func parseLokiChunk(chunkHeader *ChunkHeader, r io.Reader) (*LokiChunk, error) {
 	Block1 Meta Checksum
 	...
 	4B Meta offset ----------------------------> A
+
+	V4 chunks additionally store the structured metadata symbols and replace the meta offset
+	with the length and offset of each section:
+
+	...
+	Uvarint # symbols <------------------------- C
+	Symbols, compressed with the chunk encoding
+	Symbols Checksum
+	8B Symbols length, 8B Symbols offset ------> C
+	8B Meta length, 8B Meta offset ------------> A
 	*/
 
 	// Loki chunks need to be loaded into memory, because some offsets are actually stored at the end.
@@ -136,11 +171,17 @@ func parseLokiChunk(chunkHeader *ChunkHeader, r io.Reader) (*LokiChunk, error) {
 
 	// return &LokiChunk{encoding: compression}, nil
 
-	metasOffset := binary.BigEndian.Uint64(data[len(data)-8:])
+	var metasOffset, metasLen uint64
+	if f >= chunkFormatV4 {
+		metasLen, metasOffset = readSectionLenAndOffset(data, chunkMetasSectionIdx)
+	} else {
+		metasOffset = binary.BigEndian.Uint64(data[len(data)-8:])
+		metasLen = uint64(len(data)-(8+4)) - metasOffset
+	}
 
-	metadata := data[metasOffset : len(data)-(8+4)]
+	metadata := data[metasOffset : metasOffset+metasLen]
 
-	metaChecksum := binary.BigEndian.Uint32(data[len(data)-12 : len(data)-8])
+	metaChecksum := binary.BigEndian.Uint32(data[metasOffset+metasLen:])
 	computedMetaChecksum := crc32.Checksum(metadata, castagnoliTable)
 
 	blocks, n := binary.Uvarint(metadata)
@@ -156,6 +197,18 @@ func parseLokiChunk(chunkHeader *ChunkHeader, r io.Reader) (*LokiChunk, error) {
 		computedMetadataChecksum: computedMetaChecksum,
 	}
 
+	if f >= chunkFormatV4 {
+		smLen, smOffset := readSectionLenAndOffset(data, chunkStructuredMetadataSectionIdx)
+		sm := data[smOffset : smOffset+smLen]
+
+		lokiChunk.structuredMetadataChecksum = binary.BigEndian.Uint32(data[smOffset+smLen:])
+		lokiChunk.computedStructuredMetadataChecksum = crc32.Checksum(sm, castagnoliTable)
+		lokiChunk.symbols, err = parseSymbols(compression, sm)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read structured metadata symbols: %w", err)
+		}
+	}
+
 	for ix := 0; ix < int(blocks); ix++ {
 		block := LokiBlock{}
 		block.numEntries, metadata, err = readUvarint(err, metadata)
@@ -175,14 +228,14 @@ func parseLokiChunk(chunkHeader *ChunkHeader, r io.Reader) (*LokiChunk, error) {
 		block.rawData = data[block.dataOffset : block.dataOffset+dataLength]
 		block.storedChecksum = binary.BigEndian.Uint32(data[block.dataOffset+dataLength : block.dataOffset+dataLength+4])
 		block.computedChecksum = crc32.Checksum(block.rawData, castagnoliTable)
-		block.originalData, block.entries, err = parseLokiBlock(compression, block.rawData)
+		block.originalData, block.entries, err = parseLokiBlock(f, compression, lokiChunk.symbols, block.rawData)
 		lokiChunk.blocks = append(lokiChunk.blocks, block)
 	}
 
 	return lokiChunk, nil
 }
 
-func parseLokiBlock(compression Encoding, data []byte) ([]byte, []LokiEntry, error) {
+func parseLokiBlock(format byte, compression Encoding, symbols []string, data []byte) ([]byte, []LokiEntry, error) {
 	r, err := compression.readerFn(bytes.NewReader(data))
 	if err != nil {
 		return nil, nil, err
@@ -209,17 +262,91 @@ func parseLokiBlock(compression Encoding, data []byte) ([]byte, []LokiEntry, err
 			return origDecompressed, nil, fmt.Errorf("not enough line data, need %d, got %d", lineLength, len(decompressed))
 		}
 
-		entries = append(entries, LokiEntry{
+		entry := LokiEntry{
 			timestamp: timestamp,
 			line:      string(decompressed[0:lineLength]),
-		})
-
+		}
 		decompressed = decompressed[lineLength:]
+
+		if format >= chunkFormatV4 {
+			entry.structuredMetadata, decompressed, err = readStructuredMetadata(symbols, decompressed)
+			if err != nil {
+				return origDecompressed, nil, err
+			}
+		}
+
+		entries = append(entries, entry)
 	}
 
 	return origDecompressed, entries, nil
 }
 
+// readStructuredMetadata reads the structured metadata stored after each line in V4 chunks:
+// the length of the section, the number of labels and a name and value symbol reference per label.
+func readStructuredMetadata(symbols []string, buf []byte) (Labels, []byte, error) {
+	var err error
+	var nLabels uint64
+
+	_, buf, err = readUvarint(err, buf)
+	nLabels, buf, err = readUvarint(err, buf)
+	if err != nil {
+		return nil, buf, fmt.Errorf("failed to read structured metadata: %w", err)
+	}
+
+	var lbls Labels
+	for i := uint64(0); i < nLabels; i++ {
+		var name, value uint64
+		name, buf, err = readUvarint(err, buf)
+		value, buf, err = readUvarint(err, buf)
+		if err != nil {
+			return nil, buf, fmt.Errorf("failed to read structured metadata: %w", err)
+		}
+		if name >= uint64(len(symbols)) || value >= uint64(len(symbols)) {
+			return nil, buf, fmt.Errorf("structured metadata symbol out of range, got %d and %d, have %d symbols", name, value, len(symbols))
+		}
+		lbls = append(lbls, Label{Name: symbols[name], Value: symbols[value]})
+	}
+	return lbls, buf, nil
+}
+
+// parseSymbols decodes the structured metadata symbols section of V4 chunks: the number of symbols,
+// followed by the symbols compressed with the chunk encoding, each prefixed by its length.
+func parseSymbols(compression Encoding, data []byte) ([]string, error) {
+	count, data, err := readUvarint(nil, data)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := compression.readerFn(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	decompressed, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	symbols := make([]string, 0, count)
+	for i := uint64(0); i < count; i++ {
+		var length uint64
+		length, decompressed, err = readUvarint(err, decompressed)
+		if err != nil {
+			return nil, err
+		}
+		if len(decompressed) < int(length) {
+			return nil, fmt.Errorf("not enough symbol data, need %d, got %d", length, len(decompressed))
+		}
+		symbols = append(symbols, string(decompressed[:length]))
+		decompressed = decompressed[length:]
+	}
+	return symbols, nil
+}
+
+func readSectionLenAndOffset(data []byte, idx int) (uint64, uint64) {
+	pos := len(data) - (idx * 16)
+	return binary.BigEndian.Uint64(data[pos : pos+8]), binary.BigEndian.Uint64(data[pos+8 : pos+16])
+}
+
 func readVarint(prevErr error, buf []byte) (int64, []byte, error) {
 	if prevErr != nil {
 		return 0, buf, prevErr
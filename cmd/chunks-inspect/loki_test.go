@@ -0,0 +1,135 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLoadFile_V4Chunk(t *testing.T) {
+	h, c, _, err := loadFile(filepath.Join("testdata", "chunk-v4"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if h.UserID != "fake" || h.Fingerprint != 0x5fd68e2544215f6c {
+		t.Errorf("unexpected chunk header %+v", h)
+	}
+	if !reflect.DeepEqual(h.Metric, testAPILabels) {
+		t.Errorf("unexpected chunk labels %v", h.Metric)
+	}
+
+	if c.format != chunkFormatV4 {
+		t.Errorf("expected format %d, got %d", chunkFormatV4, c.format)
+	}
+	if c.encoding.code != encSnappy.code {
+		t.Errorf("expected encoding %s, got %s", encSnappy.name, c.encoding.name)
+	}
+	if c.metadataChecksum != c.computedMetadataChecksum {
+		t.Errorf("metadata checksum mismatch: %08x != %08x", c.metadataChecksum, c.computedMetadataChecksum)
+	}
+	if c.structuredMetadataChecksum != c.computedStructuredMetadataChecksum {
+		t.Errorf("structured metadata checksum mismatch: %08x != %08x", c.structuredMetadataChecksum, c.computedStructuredMetadataChecksum)
+	}
+	if n := c.badBlocks(); n != 0 {
+		t.Errorf("expected no bad blocks, got %d", n)
+	}
+
+	var entries []LokiEntry
+	for _, b := range c.blocks {
+		if int(b.numEntries) != len(b.entries) {
+			t.Errorf("block holds %d entries, parsed %d", b.numEntries, len(b.entries))
+		}
+		entries = append(entries, b.entries...)
+	}
+	if len(entries) != 6 {
+		t.Fatalf("expected 6 entries, got %d", len(entries))
+	}
+
+	start := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	for i, e := range entries {
+		if ts := start.Add(time.Duration(i) * time.Second).UnixNano(); e.timestamp != ts {
+			t.Errorf("entry %d: expected timestamp %d, got %d", i, ts, e.timestamp)
+		}
+		if line := fmt.Sprintf(`level=info msg="request %d"`, i); e.line != line {
+			t.Errorf("entry %d: expected line %q, got %q", i, line, e.line)
+		}
+
+		var metadata Labels
+		if i%2 == 0 {
+			metadata = Labels{{Name: "trace_id", Value: fmt.Sprintf("t%d", i)}}
+		}
+		if !reflect.DeepEqual(e.structuredMetadata, metadata) {
+			t.Errorf("entry %d: expected structured metadata %v, got %v", i, metadata, e.structuredMetadata)
+		}
+	}
+}
+
+func TestReadStructuredMetadata(t *testing.T) {
+	symbols := []string{"trace_id", "abc", "pod"}
+
+	for _, tc := range []struct {
+		name        string
+		buf         []byte
+		expected    Labels
+		expectedErr bool
+	}{
+		{
+			name: "no labels",
+			buf:  []byte{1, 0},
+		},
+		{
+			name:     "labels",
+			buf:      []byte{5, 2, 0, 1, 2, 1, 0xff},
+			expected: Labels{{Name: "trace_id", Value: "abc"}, {Name: "pod", Value: "abc"}},
+		},
+		{
+			name:        "symbol out of range",
+			buf:         []byte{3, 1, 0, 3},
+			expectedErr: true,
+		},
+		{
+			name:        "truncated",
+			buf:         []byte{3, 1, 0},
+			expectedErr: true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			lbls, rest, err := readStructuredMetadata(symbols, tc.buf)
+			if tc.expectedErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(lbls, tc.expected) {
+				t.Errorf("expected %v, got %v", tc.expected, lbls)
+			}
+			if tc.name == "labels" && !reflect.DeepEqual(rest, []byte{0xff}) {
+				t.Errorf("unexpected remaining bytes %v", rest)
+			}
+		})
+	}
+}
+
+func TestParseSymbols(t *testing.T) {
+	// Symbols are only compressed with the chunk encoding, none keeps them as they are.
+	data := []byte{2, 8, 't', 'r', 'a', 'c', 'e', '_', 'i', 'd', 2, 't', '0'}
+
+	symbols, err := parseSymbols(encNone, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"trace_id", "t0"}; !reflect.DeepEqual(symbols, expected) {
+		t.Errorf("expected %v, got %v", expected, symbols)
+	}
+
+	if _, err := parseSymbols(encNone, data[:len(data)-1]); err == nil {
+		t.Error("expected an error for truncated symbols")
+	}
+}
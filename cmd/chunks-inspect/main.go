@@ -18,29 +18,110 @@ func main() {
 	blocks := flag.Bool("b", false, "print block details")
 	lines := flag.Bool("l", false, "print log lines")
 	storeBlocks := flag.Bool("s", false, "store blocks, using input filename, and appending block index to it")
+	jsonOutput := flag.Bool("json", false, "print chunk details as JSON, one object per chunk")
+	indexFile := flag.String("index", "", "TSDB index file to look up chunks matching -selector in, optionally gzip compressed")
+	selector := flag.String("selector", "", "stream selector of the chunks to look up in the index, e.g. '{app=\"foo\"}'")
+	from := flag.String("from", "", "start of the time range to look up chunks for, in RFC3339 format (default start of the index)")
+	to := flag.String("to", "", "end of the time range to look up chunks for, in RFC3339 format (default end of the index)")
+	tenant := flag.String("tenant", "", "tenant of the chunks, required if the index does not contain it")
+	chunksDir := flag.String("chunks-dir", "", "directory of the filesystem object store to fetch chunks found in the index from")
 	flag.Parse()
 
-	for _, f := range flag.Args() {
-		printFile(f, *blocks, *lines, *storeBlocks)
+	files := flag.Args()
+	if *indexFile != "" {
+		found, err := lookupChunks(*indexFile, *selector, *from, *to, *tenant, *chunksDir)
+		if err != nil {
+			log.Fatalf("%s: %v", *indexFile, err)
+		}
+		files = append(files, found...)
+	}
+
+	for _, f := range files {
+		if *jsonOutput {
+			printFileJSON(f, *lines)
+		} else {
+			printFile(f, *blocks, *lines, *storeBlocks)
+		}
 	}
 }
 
-func printFile(filename string, blockDetails, printLines, storeBlocks bool) {
+// lookupChunks returns the files of the chunks of the index which match the selector and time range.
+func lookupChunks(indexFile, selector, from, to, tenant, chunksDir string) ([]string, error) {
+	if selector == "" || chunksDir == "" {
+		return nil, fmt.Errorf("-index requires -selector and -chunks-dir")
+	}
+	sel, err := parseSelector(selector)
+	if err != nil {
+		return nil, err
+	}
+
+	idx, err := openTSDBIndex(indexFile)
+	if err != nil {
+		return nil, err
+	}
+
+	start, end := idx.from, idx.through+1
+	if from != "" {
+		t, err := time.Parse(time.RFC3339Nano, from)
+		if err != nil {
+			return nil, fmt.Errorf("invalid -from: %w", err)
+		}
+		start = t.UnixNano() / int64(time.Millisecond)
+	}
+	if to != "" {
+		t, err := time.Parse(time.RFC3339Nano, to)
+		if err != nil {
+			return nil, fmt.Errorf("invalid -to: %w", err)
+		}
+		end = t.UnixNano() / int64(time.Millisecond)
+	}
+
+	refs, err := findChunks(idx, sel, tenant, start, end)
+	if err != nil {
+		return nil, err
+	}
+	log.Println("Found", len(refs), "chunk(s) in index", indexFile, "for", selector)
+
+	files := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		p, err := chunkPath(chunksDir, ref)
+		if err != nil {
+			log.Println(err)
+			continue
+		}
+		files = append(files, p)
+	}
+	return files, nil
+}
+
+// loadFile reads and parses the chunk file, returning its size.
+func loadFile(filename string) (*ChunkHeader, *LokiChunk, int64, error) {
 	f, err := os.Open(filename)
 	if err != nil {
-		log.Printf("%s: %v", filename, err)
-		return
+		return nil, nil, 0, err
 	}
 	defer f.Close()
 
 	si, err := f.Stat()
 	if err != nil {
-		log.Println("failed to stat file", err)
-		return
+		return nil, nil, 0, fmt.Errorf("failed to stat file: %w", err)
 	}
 
 	h, err := DecodeHeader(f)
 	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	lokiChunk, err := parseLokiChunk(h, f)
+	if err != nil {
+		return h, nil, 0, err
+	}
+	return h, lokiChunk, si.Size(), nil
+}
+
+func printFile(filename string, blockDetails, printLines, storeBlocks bool) {
+	h, lokiChunk, size, err := loadFile(filename)
+	if h == nil {
 		log.Printf("%s: %v", filename, err)
 		return
 	}
@@ -59,7 +140,6 @@ func printFile(filename string, blockDetails, printLines, storeBlocks bool) {
 		fmt.Println("\t", l.Name, "=", l.Value)
 	}
 
-	lokiChunk, err := parseLokiChunk(h, f)
 	if err != nil {
 		log.Printf("%s: %v", filename, err)
 		return
@@ -73,11 +153,23 @@ func printFile(filename string, blockDetails, printLines, storeBlocks bool) {
 	} else {
 		fmt.Println(" BAD, computed checksum:", fmt.Sprintf("%08x", lokiChunk.computedMetadataChecksum))
 	}
+	if lokiChunk.format >= chunkFormatV4 {
+		fmt.Print("Structured Metadata Checksum: ", fmt.Sprintf("%08x", lokiChunk.structuredMetadataChecksum))
+		if lokiChunk.structuredMetadataChecksum == lokiChunk.computedStructuredMetadataChecksum {
+			fmt.Println(" OK")
+		} else {
+			fmt.Println(" BAD, computed checksum:", fmt.Sprintf("%08x", lokiChunk.computedStructuredMetadataChecksum))
+		}
+		fmt.Println("Structured Metadata Symbols:", len(lokiChunk.symbols))
+	}
 	if blockDetails {
 		fmt.Println("Found", len(lokiChunk.blocks), "block(s)")
 	} else {
 		fmt.Println("Found", len(lokiChunk.blocks), "block(s), use -b to show block details")
 	}
+	if bad := lokiChunk.badBlocks(); bad > 0 {
+		fmt.Println("Blocks with BAD checksum:", bad)
+	}
 	if len(lokiChunk.blocks) > 0 {
 		fmt.Println("Minimum time (from first block):", time.Unix(0, lokiChunk.blocks[0].minT).In(timezone).Format(format))
 		fmt.Println("Maximum time (from last block):", time.Unix(0, lokiChunk.blocks[len(lokiChunk.blocks)-1].maxT).In(timezone).Format(format))
@@ -108,7 +200,11 @@ func printFile(filename string, blockDetails, printLines, storeBlocks bool) {
 
 		if printLines {
 			for _, l := range b.entries {
-				fmt.Printf("%v\t%s\n", time.Unix(0, l.timestamp).In(timezone).Format(format), strings.TrimSpace(l.line))
+				if len(l.structuredMetadata) > 0 {
+					fmt.Printf("%v\t%s\t%s\n", time.Unix(0, l.timestamp).In(timezone).Format(format), strings.TrimSpace(l.line), l.structuredMetadata)
+				} else {
+					fmt.Printf("%v\t%s\n", time.Unix(0, l.timestamp).In(timezone).Format(format), strings.TrimSpace(l.line))
+				}
 			}
 		}
 
@@ -118,7 +214,7 @@ func printFile(filename string, blockDetails, printLines, storeBlocks bool) {
 		}
 	}
 
-	fmt.Println("Total size of original data:", totalSize, "file size:", si.Size(), "ratio:", fmt.Sprintf("%0.3g", float64(totalSize)/float64(si.Size())))
+	fmt.Println("Total size of original data:", totalSize, "file size:", size, "ratio:", fmt.Sprintf("%0.3g", float64(totalSize)/float64(size)))
 }
 
 func writeBlockToFile(data []byte, blockIndex int, filename string) {
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type matchType string
+
+const (
+	matchEqual     matchType = "="
+	matchNotEqual  matchType = "!="
+	matchRegexp    matchType = "=~"
+	matchNotRegexp matchType = "!~"
+)
+
+// Matcher matches the value of a single label, as in a LogQL stream selector.
+type Matcher struct {
+	Name  string
+	Type  matchType
+	Value string
+
+	re *regexp.Regexp
+}
+
+func (m Matcher) Matches(v string) bool {
+	switch m.Type {
+	case matchEqual:
+		return v == m.Value
+	case matchNotEqual:
+		return v != m.Value
+	case matchRegexp:
+		return m.re.MatchString(v)
+	case matchNotRegexp:
+		return !m.re.MatchString(v)
+	}
+	return false
+}
+
+// Selector is a parsed stream selector, e.g. {app="foo", env=~"prod|dev"}.
+type Selector []Matcher
+
+// Matches returns true if all matchers of the selector match the labels.
+// Missing labels are matched as empty values.
+func (s Selector) Matches(ls Labels) bool {
+	m := ls.Map()
+	for _, matcher := range s {
+		if !matcher.Matches(m[matcher.Name]) {
+			return false
+		}
+	}
+	return true
+}
+
+func parseSelector(s string) (Selector, error) {
+	s = strings.TrimSpace(s)
+	if !strings.HasPrefix(s, "{") || !strings.HasSuffix(s, "}") {
+		return nil, fmt.Errorf("stream selector must be enclosed in braces: %s", s)
+	}
+	rest := strings.TrimSpace(s[1 : len(s)-1])
+
+	var sel Selector
+	for rest != "" {
+		i := strings.IndexAny(rest, "=!")
+		if i <= 0 {
+			return nil, fmt.Errorf("expected label matcher at: %s", rest)
+		}
+		name := strings.TrimSpace(rest[:i])
+		rest = rest[i:]
+
+		var typ matchType
+		for _, t := range []matchType{matchNotEqual, matchRegexp, matchNotRegexp, matchEqual} {
+			if strings.HasPrefix(rest, string(t)) {
+				typ = t
+				break
+			}
+		}
+		if typ == "" {
+			return nil, fmt.Errorf("unknown match operator at: %s", rest)
+		}
+		rest = strings.TrimSpace(rest[len(typ):])
+
+		quoted, err := strconv.QuotedPrefix(rest)
+		if err != nil {
+			return nil, fmt.Errorf("expected quoted value for label %s at: %s", name, rest)
+		}
+		value, err := strconv.Unquote(quoted)
+		if err != nil {
+			return nil, err
+		}
+		rest = strings.TrimSpace(rest[len(quoted):])
+
+		m := Matcher{Name: name, Type: typ, Value: value}
+		if typ == matchRegexp || typ == matchNotRegexp {
+			if m.re, err = regexp.Compile("^(?:" + value + ")$"); err != nil {
+				return nil, fmt.Errorf("invalid regexp for label %s: %w", name, err)
+			}
+		}
+		sel = append(sel, m)
+
+		if strings.HasPrefix(rest, ",") {
+			rest = strings.TrimSpace(rest[1:])
+		} else if rest != "" {
+			return nil, fmt.Errorf("expected comma at: %s", rest)
+		}
+	}
+
+	if len(sel) == 0 {
+		return nil, fmt.Errorf("stream selector must contain at least one matcher")
+	}
+	return sel, nil
+}
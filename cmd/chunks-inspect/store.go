@@ -0,0 +1,81 @@
+package main
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ChunkRef identifies a chunk in the object store.
+type ChunkRef struct {
+	UserID      string
+	Fingerprint uint64
+	Meta        TSDBChunkMeta
+	Labels      Labels
+}
+
+// ExternalKey returns the object store key of the chunk, using the layout of schema v12 and newer.
+func (r ChunkRef) ExternalKey() string {
+	return fmt.Sprintf("%s/%x/%x:%x:%x", r.UserID, r.Fingerprint, r.Meta.MinTime, r.Meta.MaxTime, r.Meta.Checksum)
+}
+
+// legacyExternalKey returns the object store key of the chunk used before schema v12.
+func (r ChunkRef) legacyExternalKey() string {
+	return fmt.Sprintf("%s/%x:%x:%x:%x", r.UserID, r.Fingerprint, r.Meta.MinTime, r.Meta.MaxTime, r.Meta.Checksum)
+}
+
+// findChunks returns the chunks of all series of the index that match the selector and overlap
+// the time range, given in unix milliseconds. The tenant is only used when the series of the index
+// do not carry the tenant themselves.
+func findChunks(idx *TSDBIndex, sel Selector, tenant string, from, through int64) ([]ChunkRef, error) {
+	var refs []ChunkRef
+	err := idx.Series(func(s TSDBSeries) error {
+		userID := tenant
+		lbls := make(Labels, 0, len(s.Labels))
+		for _, l := range s.Labels {
+			if l.Name == tenantLabel {
+				userID = l.Value
+				continue
+			}
+			lbls = append(lbls, l)
+		}
+
+		if (tenant != "" && userID != tenant) || !sel.Matches(lbls) {
+			return nil
+		}
+		if userID == "" {
+			return fmt.Errorf("series %s has no tenant, use -tenant to set it", lbls)
+		}
+
+		for _, c := range s.Chunks {
+			// The max time of a chunk is inclusive, through is exclusive.
+			if from <= c.MaxTime && through > c.MinTime {
+				refs = append(refs, ChunkRef{UserID: userID, Fingerprint: s.Fingerprint, Meta: c, Labels: lbls})
+			}
+		}
+		return nil
+	})
+	return refs, err
+}
+
+// chunkPath returns the path of the chunk in a filesystem object store rooted at dir.
+// Since schema v12 the filesystem store keeps the chunks of a series in a directory, with the last part of the key
+// base64 encoded. Before, it kept all chunks in a single directory, with base64 encoded keys. Chunks downloaded from
+// other object stores keep their plain key.
+func chunkPath(dir string, ref ChunkRef) (string, error) {
+	key := ref.ExternalKey()
+	split := strings.LastIndexByte(key, '/')
+	candidates := []string{
+		filepath.Join(dir, filepath.FromSlash(key[:split]), base64.StdEncoding.EncodeToString([]byte(key[split+1:]))),
+		filepath.Join(dir, filepath.FromSlash(key)),
+		filepath.Join(dir, base64.StdEncoding.EncodeToString([]byte(ref.legacyExternalKey()))),
+	}
+	for _, p := range candidates {
+		if _, err := os.Stat(p); err == nil {
+			return p, nil
+		}
+	}
+	return "", fmt.Errorf("chunk %s not found in %s", key, dir)
+}
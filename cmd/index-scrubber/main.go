@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/loki/v3/pkg/compactor"
+	"github.com/grafana/loki/v3/pkg/loki"
+	"github.com/grafana/loki/v3/pkg/storage"
+	"github.com/grafana/loki/v3/pkg/storage/config"
+	"github.com/grafana/loki/v3/pkg/storage/stores/shipper/indexshipper/tsdb"
+	"github.com/grafana/loki/v3/pkg/storage/types"
+	"github.com/grafana/loki/v3/pkg/util/cfg"
+	util_log "github.com/grafana/loki/v3/pkg/util/log"
+)
+
+// exitProblemsFound is the exit code when missing, corrupted or orphaned chunks were found.
+const exitProblemsFound = 2
+
+func main() {
+	now := time.Now().UTC()
+
+	configFile := flag.String("config.file", "", "Loki config file of the cluster to scrub")
+	tenants := flag.String("tenant", "", "Comma separated list of tenants to scrub, all the tenants having an index in the scrubbed tables are scrubbed when empty")
+	from := flag.String("from", now.Add(-8*24*time.Hour).Format(time.DateOnly), "Start of the period to scrub, as a date or RFC3339 time")
+	to := flag.String("to", now.Add(-24*time.Hour).Format(time.DateOnly), "End of the period to scrub, as a date or RFC3339 time")
+	workingDir := flag.String("working-dir", filepath.Join(os.TempDir(), "index-scrubber"), "Directory where the index files are downloaded")
+	reportFile := flag.String("report", "", "File to write the JSON report to, the report is written to stdout when empty")
+
+	var scrubberCfg compactor.ScrubberConfig
+	flag.BoolVar(&scrubberCfg.Repair, "repair", false, "Remove the index entries of chunks missing from the object store. Make sure no compactor is running while repairing")
+	flag.BoolVar(&scrubberCfg.VerifyChecksums, "verify-checksums", false, "Download the referenced chunks to verify their checksums, only their existence is checked otherwise")
+	flag.DurationVar(&scrubberCfg.OrphanGracePeriod, "orphan-grace-period", 24*time.Hour, "Chunks written more recently than this are not reported as orphaned")
+	flag.IntVar(&scrubberCfg.Concurrency, "concurrency", 10, "Number of chunks to check in parallel")
+	flag.Parse()
+
+	if *configFile == "" {
+		exit("-config.file must be specified")
+	}
+	start, err := parseTime(*from)
+	if err != nil {
+		exit("invalid -from: %v", err)
+	}
+	end, err := parseTime(*to)
+	if err != nil {
+		exit("invalid -to: %v", err)
+	}
+	if end.Before(start) {
+		exit("-to must not be before -from")
+	}
+
+	var c loki.ConfigWrapper
+	if err := cfg.DynamicUnmarshal(&c, []string{"-config.file=" + *configFile}, flag.NewFlagSet("config-file-loader", flag.ContinueOnError)); err != nil {
+		exit("failed parsing config: %v", err)
+	}
+
+	var userIDs []string
+	if *tenants != "" {
+		userIDs = strings.Split(*tenants, ",")
+	}
+
+	ctx := context.Background()
+	clientMetrics := storage.NewClientMetrics()
+	metrics := compactor.NewScrubberMetrics(prometheus.NewRegistry())
+	report := &compactor.ScrubReport{StartedAt: time.Now(), Repair: scrubberCfg.Repair}
+
+	for i, period := range c.SchemaConfig.Configs {
+		if period.IndexType != types.TSDBType {
+			continue
+		}
+
+		periodStart, periodEnd := start, end
+		if periodStart.Before(period.From.Time) {
+			periodStart = period.From.Time
+		}
+		if i+1 < len(c.SchemaConfig.Configs) && !periodEnd.Before(c.SchemaConfig.Configs[i+1].From.Time) {
+			periodEnd = c.SchemaConfig.Configs[i+1].From.Time - 1
+		}
+		tables := tablesInRange(period, periodStart, periodEnd)
+		if len(tables) == 0 {
+			continue
+		}
+
+		objectClient, err := storage.NewObjectClient(period.ObjectType, c.StorageConfig, clientMetrics)
+		if err != nil {
+			exit("failed to create object client for period %s: %v", period.From, err)
+		}
+
+		scrubber := compactor.NewScrubber(scrubberCfg, *workingDir, c.SchemaConfig, period, objectClient, tsdb.NewIndexCompactor(), metrics, util_log.Logger)
+		reports, err := scrubber.Scrub(ctx, tables, userIDs)
+		if err != nil {
+			exit("failed to scrub period %s: %v", period.From, err)
+		}
+		report.Tenants = append(report.Tenants, reports...)
+		objectClient.Stop()
+	}
+	report.FinishedAt = time.Now()
+
+	out := os.Stdout
+	if *reportFile != "" {
+		out, err = os.Create(*reportFile)
+		if err != nil {
+			exit("failed to create report file: %v", err)
+		}
+		defer out.Close()
+	}
+	if err := compactor.WriteScrubReport(out, report); err != nil {
+		exit("failed to write report: %v", err)
+	}
+
+	if hasProblems(report) {
+		out.Close()
+		os.Exit(exitProblemsFound)
+	}
+}
+
+func tablesInRange(period config.PeriodConfig, start, end model.Time) []string {
+	var tables []string
+	for t := start; !t.After(end); t = t.Add(period.IndexTables.Period) {
+		tables = append(tables, period.IndexTables.TableFor(t))
+	}
+	if last := period.IndexTables.TableFor(end); len(tables) == 0 || tables[len(tables)-1] != last {
+		tables = append(tables, last)
+	}
+	return tables
+}
+
+func hasProblems(report *compactor.ScrubReport) bool {
+	for _, tenant := range report.Tenants {
+		if len(tenant.OrphanedChunks) > 0 {
+			return true
+		}
+		for _, table := range tenant.Tables {
+			if len(table.MissingChunks) > 0 || len(table.CorruptedChunks) > 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func parseTime(s string) (model.Time, error) {
+	if t, err := time.Parse(time.DateOnly, s); err == nil {
+		return model.TimeFromUnixNano(t.UnixNano()), nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return 0, err
+	}
+	return model.TimeFromUnixNano(t.UnixNano()), nil
+}
+
+func exit(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+	os.Exit(1)
+}
@@ -7,6 +7,7 @@ import (
 	"math"
 	"net/url"
 	"os"
+	"path/filepath"
 	"runtime/pprof"
 	"strings"
 	"time"
@@ -19,8 +20,10 @@ import (
 	"github.com/grafana/loki/v3/pkg/logcli/index"
 	"github.com/grafana/loki/v3/pkg/logcli/labelquery"
 	"github.com/grafana/loki/v3/pkg/logcli/output"
+	"github.com/grafana/loki/v3/pkg/logcli/push"
 	"github.com/grafana/loki/v3/pkg/logcli/query"
 	"github.com/grafana/loki/v3/pkg/logcli/seriesquery"
+	"github.com/grafana/loki/v3/pkg/logcli/shell"
 	"github.com/grafana/loki/v3/pkg/logcli/volume"
 	"github.com/grafana/loki/v3/pkg/logql/syntax"
 	_ "github.com/grafana/loki/v3/pkg/util/build"
@@ -30,12 +33,20 @@ var (
 	app        = kingpin.New("logcli", "A command-line for loki.").Version(version.Print("logcli"))
 	quiet      = app.Flag("quiet", "Suppress query metadata").Default("false").Short('q').Bool()
 	statistics = app.Flag("stats", "Show query statistics").Default("false").Bool()
-	outputMode = app.Flag("output", "Specify output mode [default, raw, jsonl]. raw suppresses log labels and timestamp.").Default("default").Short('o').Enum("default", "raw", "jsonl")
+	outputMode = app.Flag("output", "Specify output mode [default, raw, jsonl, table, csv, tsv]. raw suppresses log labels and timestamp. table, csv and tsv print log lines and metric results as rows with a header.").Default("default").Short('o').Enum("default", "raw", "jsonl", "table", "csv", "tsv")
+	columns    = app.Flag("columns", "Labels printed as columns by the table, csv and tsv output modes. Can be repeated. By default, log lines have a single labels column and metric results a column per label.").Strings()
+	wide       = app.Flag("wide", "Print metric results with a row per timestamp and a column per series in the table, csv and tsv output modes.").Default("false").Bool()
 	timezone   = app.Flag("timezone", "Specify the timezone to use when formatting output timestamps [Local, UTC]").Default("Local").Short('z').Enum("Local", "UTC")
 	cpuProfile = app.Flag("cpuprofile", "Specify the location for writing a CPU profile.").Default("").String()
 	memProfile = app.Flag("memprofile", "Specify the location for writing a memory profile.").Default("").String()
 	stdin      = app.Flag("stdin", "Take input logs from stdin").Bool()
 
+	local                = app.Flag("local", "Take input logs from the given files, directories or glob patterns instead of Loki. Can be repeated.").Strings()
+	localPathTemplate    = app.Flag("local-path-template", "Derive labels from the path of the local files, e.g. /var/log/{app}/{pod}.log. Used to find the files when --local is not set.").Default("").String()
+	localTimestampFormat = app.Flag("local-timestamp-format", "Format of the timestamps of the local lines: a Go time layout, a layout name like RFC3339, or one of Unix, UnixMs, UnixUs, UnixNs. Without format, lines are timestamped with the modification time of their file.").Default("").String()
+	localTimestampRegexp = app.Flag("local-timestamp-regexp", "Regular expression finding the timestamp in the local lines, in its first capture group if any.").Default(`^\S+`).String()
+	localMaxOpenFiles    = app.Flag("local-max-open-files", "Maximum number of local files a query reads. The files selected by a query are open until it ends.").Default("1000").Int()
+
 	queryClient = newQueryClient(app)
 
 	queryCmd = app.Command("query", `Run a LogQL query.
@@ -253,6 +264,57 @@ Example:
 	   'my-query'
   `)
 	volumeRangeQuery = newVolumeQuery(true, volumeRangeCmd)
+
+	pushCmd = app.Command("push", `Push log lines to Loki.
+
+The "push" command reads the lines of the given files, or of stdin when
+there's none or the file is "-", and pushes them to Loki in batches, with
+the labels given by --labels. The lines of a file also have a "filename"
+label with its path.
+
+Lines are timestamped with --timestamp-format, in the same way as with
+--local-timestamp-format. Lines without timestamp get the timestamp of the
+line before. Without format, lines are timestamped with the time they are
+read at.
+
+Structured metadata can be extracted from the fields of json or logfmt
+lines with --structured-metadata.
+
+Failed requests are retried according to the --retries, --min-backoff and
+--max-backoff flags. The progress is printed to stderr unless --quiet.
+
+Example:
+
+	logcli push
+	   --labels='{job="import", host="laptop-42"}'
+	   --timestamp-format=RFC3339Nano
+	   --format=json
+	   --structured-metadata=trace_id=traceID
+	   --retries=5 --min-backoff=1 --max-backoff=30
+	   /var/log/app/*.log
+`)
+	pushQuery = newPush(pushCmd)
+
+	shellCmd = app.Command("shell", `Run LogQL queries interactively.
+
+The "shell" command starts a prompt running the LogQL queries typed in,
+keeping the connection, tenant, time range and output settings between
+them. Queries with unclosed brackets or strings, or lines ending with a
+backslash, continue on the next line.
+
+Label names and values, pipeline stages and functions are completed with
+tab, and the history is saved to --history-file.
+
+Commands starting with a colon change the settings of the shell, e.g.:
+
+	logql> :since 24h
+	logql> :org tenant-2
+	logql> :output raw
+	logql> {job="api"} |= "error"
+
+Type :help for the list of commands.
+`)
+	shellQuery = newShell(shellCmd)
 )
 
 func main() {
@@ -309,6 +371,33 @@ func main() {
 
 		// `--limit` doesn't make sense when using `--stdin` flag.
 		rangeQuery.Limit = 0
+	} else if len(*local) > 0 || *localPathTemplate != "" {
+		location, err := time.LoadLocation(*timezone)
+		if err != nil {
+			log.Fatalf("Unable to load timezone '%s': %s", *timezone, err)
+		}
+		queryClient, err = client.NewLocalClient(client.LocalConfig{
+			Paths:           *local,
+			PathTemplate:    *localPathTemplate,
+			TimestampFormat: *localTimestampFormat,
+			TimestampRegexp: *localTimestampRegexp,
+			Location:        location,
+			MaxOpenFiles:    *localMaxOpenFiles,
+		})
+		if err != nil {
+			log.Fatalf("Unable to read local files: %s", err)
+		}
+		if rangeQuery.Step.Seconds() == 0 {
+			rangeQuery.Step = defaultQueryRangeStep(rangeQuery.Start, rangeQuery.End)
+		}
+
+		// Like with `--stdin`, the stream selector is optional: every local file has a filename label.
+		for _, q := range []*query.Query{rangeQuery, instantQuery} {
+			qs := strings.TrimSpace(q.QueryString)
+			if strings.HasPrefix(qs, "|") || strings.HasPrefix(qs, "!") {
+				q.QueryString = `{` + client.FilenameLabel + `=~".+"}` + q.QueryString
+			}
+		}
 	}
 
 	switch cmd {
@@ -322,6 +411,8 @@ func main() {
 			Timezone:      location,
 			NoLabels:      rangeQuery.NoLabels,
 			ColoredOutput: rangeQuery.ColoredOutput,
+			Columns:       *columns,
+			Wide:          *wide,
 		}
 
 		out, err := output.NewLogOutput(os.Stdout, *outputMode, outputOptions)
@@ -331,6 +422,8 @@ func main() {
 
 		if *tail || *follow {
 			rangeQuery.TailQuery(time.Duration(*delayFor)*time.Second, queryClient, out)
+		} else if rangeQuery.Export {
+			rangeQuery.DoExport(queryClient, out)
 		} else if rangeQuery.ParallelMaxWorkers == 1 {
 			rangeQuery.DoQuery(queryClient, out, *statistics)
 		} else {
@@ -348,6 +441,8 @@ func main() {
 			Timezone:      location,
 			NoLabels:      instantQuery.NoLabels,
 			ColoredOutput: instantQuery.ColoredOutput,
+			Columns:       *columns,
+			Wide:          *wide,
 		}
 
 		out, err := output.NewLogOutput(os.Stdout, *outputMode, outputOptions)
@@ -376,6 +471,8 @@ func main() {
 			Timezone:      location,
 			NoLabels:      rangeQuery.NoLabels,
 			ColoredOutput: rangeQuery.ColoredOutput,
+			Columns:       *columns,
+			Wide:          *wide,
 		}
 
 		out, err := output.NewLogOutput(os.Stdout, *outputMode, outputOptions)
@@ -388,6 +485,37 @@ func main() {
 		} else {
 			index.GetVolume(volumeQuery, queryClient, out, *statistics)
 		}
+	case pushCmd.FullCommand():
+		if *stdin || len(*local) > 0 || *localPathTemplate != "" {
+			log.Fatal("--stdin and --local can't be used to push lines, give the files to push as arguments instead")
+		}
+		location, err := time.LoadLocation(*timezone)
+		if err != nil {
+			log.Fatalf("Unable to load timezone '%s': %s", *timezone, err)
+		}
+		pushQuery.Location = location
+		pushQuery.DoPush(queryClient)
+	case shellCmd.FullCommand():
+		if *stdin {
+			log.Fatal("--stdin can't be used with the shell, which reads the queries from stdin")
+		}
+		location, err := time.LoadLocation(*timezone)
+		if err != nil {
+			log.Fatalf("Unable to load timezone '%s': %s", *timezone, err)
+		}
+		shellQuery.Client = queryClient
+		shellQuery.OutputMode = *outputMode
+		shellQuery.OutputOptions = output.LogOutputOptions{
+			Timezone:      location,
+			NoLabels:      shellQuery.Query.NoLabels,
+			ColoredOutput: shellQuery.Query.ColoredOutput,
+			Columns:       *columns,
+			Wide:          *wide,
+		}
+		shellQuery.Statistics = *statistics
+		if err := shellQuery.Run(os.Stdin, os.Stdout); err != nil {
+			log.Fatalf("Shell failed: %s", err)
+		}
 	}
 }
 
@@ -450,6 +578,67 @@ func newQueryClient(app *kingpin.Application) client.Client {
 	return client
 }
 
+func newPush(cmd *kingpin.CmdClause) *push.Push {
+	p := &push.Push{}
+
+	// executed after all command flags are parsed
+	cmd.Action(func(_ *kingpin.ParseContext) error {
+		p.Quiet = *quiet
+		return nil
+	})
+
+	cmd.Arg("file", "Files to push, - for stdin. Stdin is read when no file is given.").StringsVar(&p.Paths)
+	cmd.Flag("labels", `Labels of the pushed streams, e.g. '{job="import"}'.`).Required().StringVar(&p.Labels)
+	cmd.Flag("batch-size", "Maximum size in bytes of the lines of each push request.").Default("1048576").IntVar(&p.BatchSize)
+	cmd.Flag("timestamp-format", "Format of the timestamps of the lines: a Go time layout, a layout name like RFC3339, or one of Unix, UnixMs, UnixUs, UnixNs. Without format, lines are timestamped with the time they are read at.").Default("").StringVar(&p.TimestampFormat)
+	cmd.Flag("timestamp-regexp", "Regular expression finding the timestamp in the lines, in its first capture group if any.").Default(`^\S+`).StringVar(&p.TimestampRegexp)
+	cmd.Flag("format", "Format of the lines to extract the structured metadata from [json, logfmt].").Default("logfmt").EnumVar(&p.Format, "json", "logfmt")
+	cmd.Flag("structured-metadata", "Structured metadata to attach to the lines, as name=field where field is the field of the line holding the value. Nested json fields are separated by dots. Can be repeated.").StringMapVar(&p.StructuredMetadata)
+
+	return p
+}
+
+func newShell(cmd *kingpin.CmdClause) *shell.Shell {
+	var from, to string
+
+	s := &shell.Shell{}
+
+	// executed after all command flags are parsed
+	cmd.Action(func(_ *kingpin.ParseContext) error {
+		// An absolute time range replaces the lookback window.
+		if from != "" || to != "" {
+			defaultEnd := time.Now()
+			s.Query.Start = mustParse(from, defaultEnd.Add(-s.Since))
+			s.Query.End = mustParse(to, defaultEnd)
+			s.Since = 0
+		}
+		s.Query.Quiet = *quiet
+		s.Query.ParallelMaxWorkers = 1
+		return nil
+	})
+
+	cmd.Flag("since", "Lookback window of the queries.").Default("1h").DurationVar(&s.Since)
+	cmd.Flag("from", "Start looking for logs at this absolute time (inclusive)").StringVar(&from)
+	cmd.Flag("to", "Stop looking for logs at this absolute time (exclusive)").StringVar(&to)
+	cmd.Flag("limit", "Limit on number of entries to print. Setting it to 0 will fetch all entries.").Default("30").IntVar(&s.Query.Limit)
+	cmd.Flag("batch", "Query batch size to use until 'limit' is reached").Default("1000").IntVar(&s.Query.BatchSize)
+	cmd.Flag("step", "Query resolution step width, for metric queries.").DurationVar(&s.Query.Step)
+	cmd.Flag("forward", "Scan forwards through logs.").Default("false").BoolVar(&s.Query.Forward)
+	cmd.Flag("no-labels", "Do not print any labels").Default("false").BoolVar(&s.Query.NoLabels)
+	cmd.Flag("colored-output", "Show output with colored labels").Default("false").BoolVar(&s.Query.ColoredOutput)
+	cmd.Flag("history-file", "File the history of the shell is saved to, none if empty.").Default(defaultHistoryFile()).StringVar(&s.HistoryFile)
+
+	return s
+}
+
+func defaultHistoryFile() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".logcli_history")
+}
+
 func newLabelQuery(cmd *kingpin.CmdClause) *labelquery.LabelQuery {
 	var labelName, from, to string
 	var since time.Duration
@@ -552,6 +741,7 @@ func newQuery(instant bool, cmd *kingpin.CmdClause) *query.Query {
 		cmd.Flag("overwrite-completed-parts", "Overwrites completed part files. This will download the range again, and replace the original completed part file. Default will skip a range if it's part file is already downloaded.").Default("false").BoolVar(&q.OverwriteCompleted)
 		cmd.Flag("merge-parts", "Reads the part files in order and writes the output to stdout. Original part files will be deleted with this option.").Default("false").BoolVar(&q.MergeParts)
 		cmd.Flag("keep-parts", "Overrides the default behaviour of --merge-parts which will delete the part files once all the files have been read. This option will keep the part files.").Default("false").BoolVar(&q.KeepParts)
+		cmd.Flag("export", "Stream the entries with the export API instead of querying them in batches. The batch size is used as the page size of the export, and an interrupted export is resumed automatically.").Default("false").BoolVar(&q.Export)
 	}
 
 	cmd.Flag("forward", "Scan forwards through logs.").Default("false").BoolVar(&q.Forward)
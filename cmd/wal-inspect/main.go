@@ -0,0 +1,186 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/snappy"
+
+	"github.com/grafana/loki/v3/pkg/ingester"
+	"github.com/grafana/loki/v3/pkg/logproto"
+)
+
+// exitProblemsFound is the exit code when records of the WAL can not be replayed.
+const exitProblemsFound = 2
+
+const usage = `Usage: wal-inspect <command> [flags] <wal dir>
+
+Commands:
+  inspect  report the tenants, streams and entries of the checkpoint and of every segment, and the records which can not be replayed
+  repair   copy the WAL to a new directory, dropping the records which can not be replayed
+  export   push the entries of the WAL to a Loki instance
+
+Run wal-inspect <command> -help for the flags of a command.
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		exit(usage)
+	}
+
+	switch cmd, args := os.Args[1], os.Args[2:]; cmd {
+	case "inspect":
+		inspect(args)
+	case "repair":
+		repair(args)
+	case "export":
+		export(args)
+	default:
+		exit("unknown command %q\n\n%s", cmd, usage)
+	}
+}
+
+func inspect(args []string) {
+	fs := flag.NewFlagSet("inspect", flag.ExitOnError)
+	reportFile := fs.String("report", "", "File to write the JSON report to, the report is written to stdout when empty")
+	dir := parseArgs(fs, args)
+
+	report, err := ingester.InspectWAL(dir)
+	if err != nil {
+		exit("failed to inspect WAL: %v", err)
+	}
+	writeReport(*reportFile, report)
+
+	if report.HasProblems() {
+		os.Exit(exitProblemsFound)
+	}
+}
+
+func repair(args []string) {
+	fs := flag.NewFlagSet("repair", flag.ExitOnError)
+	output := fs.String("output", "", "Directory to write the repaired WAL to, it must not exist or be empty")
+	mode := fs.String("mode", ingester.WALRepairTruncate, fmt.Sprintf("How to handle records which can not be replayed: %s drops everything after the first of them, %s only drops them", ingester.WALRepairTruncate, ingester.WALRepairSkip))
+	reportFile := fs.String("report", "", "File to write the JSON report to, the report is written to stdout when empty")
+	dir := parseArgs(fs, args)
+
+	if *output == "" {
+		exit("-output must be specified")
+	}
+
+	report, err := ingester.RepairWAL(dir, *output, *mode, log.NewLogfmtLogger(os.Stderr))
+	if err != nil {
+		exit("failed to repair WAL: %v", err)
+	}
+	writeReport(*reportFile, report)
+}
+
+func export(args []string) {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	url := fs.String("url", "", "URL of the Loki instance to push the entries to, e.g. http://localhost:3100")
+	tenant := fs.String("tenant", "", "Tenant to push all the entries to, the entries are pushed to their original tenant when empty")
+	username := fs.String("username", "", "Username for basic authentication")
+	password := fs.String("password", "", "Password for basic authentication")
+	batchSize := fs.Int("batch-size", 1000, "Maximum number of entries per push request")
+	timeout := fs.Duration("timeout", 30*time.Second, "Timeout of a push request")
+	dir := parseArgs(fs, args)
+
+	if *url == "" {
+		exit("-url must be specified")
+	}
+
+	client := &pushClient{
+		url:      strings.TrimSuffix(*url, "/") + "/loki/api/v1/push",
+		tenant:   *tenant,
+		username: *username,
+		password: *password,
+		client:   &http.Client{Timeout: *timeout},
+	}
+	if err := ingester.ExportWAL(context.Background(), dir, *batchSize, client.push); err != nil {
+		exit("failed to export WAL: %v", err)
+	}
+	fmt.Fprintf(os.Stderr, "pushed %d entries in %d requests\n", client.entries, client.requests)
+}
+
+type pushClient struct {
+	url                string
+	tenant             string
+	username, password string
+	client             *http.Client
+
+	requests, entries int
+}
+
+// push sends the request to Loki as snappy compressed protobuf.
+func (c *pushClient) push(ctx context.Context, userID string, req *logproto.PushRequest) error {
+	buf, err := proto.Marshal(req)
+	if err != nil {
+		return err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(snappy.Encode(nil, buf)))
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Content-Type", "application/x-protobuf")
+	if c.tenant != "" {
+		userID = c.tenant
+	}
+	httpReq.Header.Set("X-Scope-OrgID", userID)
+	if c.username != "" {
+		httpReq.SetBasicAuth(c.username, c.password)
+	}
+
+	resp, err := c.client.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("server returned HTTP status %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	c.requests++
+	for _, s := range req.Streams {
+		c.entries += len(s.Entries)
+	}
+	return nil
+}
+
+// parseArgs parses the flags of a command and returns the WAL directory.
+func parseArgs(fs *flag.FlagSet, args []string) string {
+	_ = fs.Parse(args)
+	if fs.NArg() != 1 {
+		exit("expected the WAL directory as the only argument\n\n%s", usage)
+	}
+	return fs.Arg(0)
+}
+
+func writeReport(reportFile string, report interface{}) {
+	out := os.Stdout
+	if reportFile != "" {
+		var err error
+		out, err = os.Create(reportFile)
+		if err != nil {
+			exit("failed to create report file: %v", err)
+		}
+		defer out.Close()
+	}
+	if err := ingester.WriteWALReport(out, report); err != nil {
+		exit("failed to write report: %v", err)
+	}
+}
+
+func exit(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, strings.TrimSuffix(format, "\n")+"\n", args...)
+	os.Exit(1)
+}
@@ -0,0 +1,138 @@
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// LokiDeleteRequestSpec defines the log entries to delete from a LokiStack.
+//
+// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="spec is immutable"
+// +kubebuilder:validation:XValidation:rule="!has(self.end) || timestamp(self.start) <= timestamp(self.end)",message="start must not be after end"
+type LokiDeleteRequestSpec struct {
+	// LokiStack is the name of the LokiStack in the same namespace to delete the log entries from.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="LokiStack"
+	LokiStack string `json:"lokiStack"`
+
+	// TenantID of the tenant owning the log entries.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Tenant ID"
+	TenantID string `json:"tenantID"`
+
+	// Selector is the LogQL log stream selector of the log entries to delete,
+	// optionally followed by line filters.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Selector"
+	Selector string `json:"selector"`
+
+	// Start of the time range of the log entries to delete.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Start"
+	Start metav1.Time `json:"start"`
+
+	// End of the time range of the log entries to delete. Defaults to the
+	// creation time of the delete request.
+	//
+	// +optional
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="End"
+	End *metav1.Time `json:"end,omitempty"`
+}
+
+// LokiDeleteRequestConditionType defines the type of conditions of a LokiDeleteRequest.
+type LokiDeleteRequestConditionType string
+
+const (
+	// ConditionDeleteRequestSubmitted defines the condition that the delete request was accepted by the compactor.
+	ConditionDeleteRequestSubmitted LokiDeleteRequestConditionType = "Submitted"
+	// ConditionDeleteRequestProcessed defines the condition that the compactor deleted all matching log entries.
+	ConditionDeleteRequestProcessed LokiDeleteRequestConditionType = "Processed"
+)
+
+// LokiDeleteRequestConditionReason defines the type for valid reasons of LokiDeleteRequest conditions.
+type LokiDeleteRequestConditionReason string
+
+const (
+	// ReasonDeleteRequestAccepted when the compactor accepted the delete request.
+	ReasonDeleteRequestAccepted LokiDeleteRequestConditionReason = "Accepted"
+	// ReasonDeleteRequestPending when the delete request was submitted to the compactor, but is not
+	// listed by it yet. It is not submitted again.
+	ReasonDeleteRequestPending LokiDeleteRequestConditionReason = "Pending"
+	// ReasonDeleteRequestLokiStackNotFound when the LokiStack of the delete request does not exist.
+	ReasonDeleteRequestLokiStackNotFound LokiDeleteRequestConditionReason = "LokiStackNotFound"
+	// ReasonDeleteRequestRetentionDisabled when the LokiStack does not enable retention, which is
+	// required for the compactor to serve delete requests.
+	ReasonDeleteRequestRetentionDisabled LokiDeleteRequestConditionReason = "RetentionDisabled"
+	// ReasonDeleteRequestRejected when the compactor rejected the delete request, e.g. for an invalid selector.
+	ReasonDeleteRequestRejected LokiDeleteRequestConditionReason = "Rejected"
+	// ReasonDeleteRequestNotFound when the compactor no longer knows the delete request, e.g. when it was
+	// cancelled directly on the compactor.
+	ReasonDeleteRequestNotFound LokiDeleteRequestConditionReason = "NotFound"
+	// ReasonDeleteRequestInProgress when the compactor did not yet delete all matching log entries.
+	ReasonDeleteRequestInProgress LokiDeleteRequestConditionReason = "InProgress"
+	// ReasonDeleteRequestCompleted when the compactor deleted all matching log entries.
+	ReasonDeleteRequestCompleted LokiDeleteRequestConditionReason = "Completed"
+)
+
+// LokiDeleteRequestStatus defines the observed state of LokiDeleteRequest
+type LokiDeleteRequestStatus struct {
+	// RequestID is the identifier of the delete request in the compactor.
+	//
+	// +optional
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=status,displayName="Request ID"
+	RequestID string `json:"requestID,omitempty"`
+
+	// Progress of the delete request as reported by the compactor, i.e. received,
+	// the percentage of completion or processed.
+	//
+	// +optional
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=status,displayName="Progress"
+	Progress string `json:"progress,omitempty"`
+
+	// Conditions of the LokiDeleteRequest.
+	//
+	// +optional
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=status,xDescriptors="urn:alm:descriptor:io.kubernetes.conditions"
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+
+// LokiDeleteRequest is the Schema for the lokideleterequests API
+//
+// +operator-sdk:csv:customresourcedefinitions:displayName="LokiDeleteRequest",resources={{LokiStack,v1}}
+type LokiDeleteRequest struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   LokiDeleteRequestSpec   `json:"spec,omitempty"`
+	Status LokiDeleteRequestStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// LokiDeleteRequestList contains a list of LokiDeleteRequest
+type LokiDeleteRequestList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []LokiDeleteRequest `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&LokiDeleteRequest{}, &LokiDeleteRequestList{})
+}
@@ -382,6 +382,30 @@ type LokiTemplateSpec struct {
 	// +kubebuilder:validation:Optional
 	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Ruler pods"
 	Ruler *LokiComponentSpec `json:"ruler,omitempty"`
+
+	// BloomCompactor defines the bloom compactor component spec. The bloom compactor is only
+	// deployed when this spec is set and requires the bloom gateway to be deployed too.
+	//
+	// +optional
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Bloom Compactor pods"
+	BloomCompactor *LokiComponentSpec `json:"bloomCompactor,omitempty"`
+
+	// BloomGateway defines the bloom gateway component spec. The bloom gateway is only
+	// deployed when this spec is set and requires the bloom compactor to be deployed too.
+	//
+	// +optional
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Bloom Gateway pods"
+	BloomGateway *LokiComponentSpec `json:"bloomGateway,omitempty"`
+
+	// PatternIngester defines the pattern ingester component spec. The pattern ingester is only
+	// deployed when this spec is set.
+	//
+	// +optional
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Pattern Ingester pods"
+	PatternIngester *LokiComponentSpec `json:"patternIngester,omitempty"`
 }
 
 // ClusterProxy is the Proxy configuration when the cluster is behind a Proxy.
@@ -821,6 +845,84 @@ type RetentionLimitSpec struct {
 	Streams []*RetentionStreamSpec `json:"streams,omitempty"`
 }
 
+// OTLPAttributeAction defines the action to take on the attributes of logs received with OTLP.
+//
+// +kubebuilder:validation:Enum=index_label;structured_metadata;drop
+type OTLPAttributeAction string
+
+const (
+	// OTLPAttributeActionIndexLabel stores the attributes as index labels of the stream.
+	// It is only allowed for resource attributes.
+	OTLPAttributeActionIndexLabel OTLPAttributeAction = "index_label"
+	// OTLPAttributeActionStructuredMetadata stores the attributes as structured metadata of the log entries.
+	OTLPAttributeActionStructuredMetadata OTLPAttributeAction = "structured_metadata"
+	// OTLPAttributeActionDrop drops the attributes.
+	OTLPAttributeActionDrop OTLPAttributeAction = "drop"
+)
+
+// OTLPAttributesSpec selects a set of attributes, by name or by regular expression,
+// and the action to take on them.
+type OTLPAttributesSpec struct {
+	// Action defines what to do with the selected attributes.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	Action OTLPAttributeAction `json:"action"`
+
+	// Attributes contains the names of the selected attributes. It cannot be used
+	// together with Regex.
+	//
+	// +optional
+	// +kubebuilder:validation:Optional
+	Attributes []string `json:"attributes,omitempty"`
+
+	// Regex selects the attributes with a name matching the regular expression. It cannot
+	// be used together with Attributes.
+	//
+	// +optional
+	// +kubebuilder:validation:Optional
+	Regex string `json:"regex,omitempty"`
+}
+
+// OTLPResourceAttributesSpec defines the configuration of the resource attributes of logs received with OTLP.
+type OTLPResourceAttributesSpec struct {
+	// IgnoreDefaults disables the default list of resource attributes stored as index labels,
+	// only keeping the ones selected by Attributes.
+	//
+	// +optional
+	// +kubebuilder:validation:Optional
+	IgnoreDefaults bool `json:"ignoreDefaults,omitempty"`
+
+	// Attributes defines the actions to take on the resource attributes.
+	//
+	// +optional
+	// +kubebuilder:validation:Optional
+	Attributes []OTLPAttributesSpec `json:"attributes,omitempty"`
+}
+
+// OTLPSpec defines how the attributes of logs received with OTLP are stored.
+type OTLPSpec struct {
+	// ResourceAttributes defines the configuration of the resource attributes.
+	//
+	// +optional
+	// +kubebuilder:validation:Optional
+	ResourceAttributes *OTLPResourceAttributesSpec `json:"resourceAttributes,omitempty"`
+
+	// ScopeAttributes defines the actions to take on the scope attributes. The index_label
+	// action is not allowed.
+	//
+	// +optional
+	// +kubebuilder:validation:Optional
+	ScopeAttributes []OTLPAttributesSpec `json:"scopeAttributes,omitempty"`
+
+	// LogAttributes defines the actions to take on the log attributes. The index_label
+	// action is not allowed.
+	//
+	// +optional
+	// +kubebuilder:validation:Optional
+	LogAttributes []OTLPAttributesSpec `json:"logAttributes,omitempty"`
+}
+
 // LimitsTemplateSpec defines the limits  applied at ingestion or query path.
 type LimitsTemplateSpec struct {
 	// IngestionLimits defines the limits applied on ingested log streams.
@@ -840,6 +942,12 @@ type LimitsTemplateSpec struct {
 	// +optional
 	// +kubebuilder:validation:Optional
 	Retention *RetentionLimitSpec `json:"retention,omitempty"`
+
+	// OTLP defines how the attributes of logs received with OTLP are stored.
+	//
+	// +optional
+	// +kubebuilder:validation:Optional
+	OTLP *OTLPSpec `json:"otlp,omitempty"`
 }
 
 // LimitsTemplateSpec defines the limits  applied at ingestion or query path.
@@ -861,6 +969,12 @@ type PerTenantLimitsTemplateSpec struct {
 	// +optional
 	// +kubebuilder:validation:Optional
 	Retention *RetentionLimitSpec `json:"retention,omitempty"`
+
+	// OTLP defines how the attributes of logs received with OTLP are stored.
+	//
+	// +optional
+	// +kubebuilder:validation:Optional
+	OTLP *OTLPSpec `json:"otlp,omitempty"`
 }
 
 // LimitsSpec defines the spec for limits applied at ingestion or query
@@ -996,6 +1110,14 @@ type LokiStackSpec struct {
 	// +kubebuilder:validation:Optional
 	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Tenants Configuration"
 	Tenants *TenantsSpec `json:"tenants,omitempty"`
+
+	// Autoscaling defines the spec for scaling the queriers and ingesters automatically with
+	// the load instead of using the replicas of the selected size.
+	//
+	// +optional
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors="urn:alm:descriptor:com.tectonic.ui:advanced",displayName="Autoscaling"
+	Autoscaling *AutoscalingSpec `json:"autoscaling,omitempty"`
 }
 
 type ReplicationSpec struct {
@@ -1033,6 +1155,96 @@ type ZoneSpec struct {
 	TopologyKey string `json:"topologyKey"`
 }
 
+// AutoscalingSpec defines the components scaled automatically with the load.
+type AutoscalingSpec struct {
+	// Querier defines the autoscaling of the queriers, driven by the length of the query queue.
+	//
+	// +optional
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Querier Autoscaling"
+	Querier *QuerierAutoscalingSpec `json:"querier,omitempty"`
+
+	// Ingester defines the autoscaling of the ingesters, driven by their CPU utilization.
+	// Ingesters are scaled up by a HorizontalPodAutoscaler and scaled down by the operator
+	// one at a time. The ingester leaving the ring flushes its data while shutting down.
+	//
+	// +optional
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,displayName="Ingester Autoscaling"
+	Ingester *IngesterAutoscalingSpec `json:"ingester,omitempty"`
+}
+
+// QuerierAutoscalingSpec defines the autoscaling of the queriers.
+type QuerierAutoscalingSpec struct {
+	// MinReplicas defines the lower limit for the number of querier replicas.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Minimum:=1
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors="urn:alm:descriptor:com.tectonic.ui:number",displayName="Minimum Replicas"
+	MinReplicas int32 `json:"minReplicas"`
+
+	// MaxReplicas defines the upper limit for the number of querier replicas.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Minimum:=1
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors="urn:alm:descriptor:com.tectonic.ui:number",displayName="Maximum Replicas"
+	MaxReplicas int32 `json:"maxReplicas"`
+
+	// TargetQueueLength defines the average number of queries waiting in the query-frontend
+	// queue per querier replica. The queriers are scaled out when the queue grows beyond it.
+	// The queue length metric needs to be exposed to the HorizontalPodAutoscaler by an
+	// external metrics adapter, e.g. KEDA or the Prometheus adapter.
+	//
+	// +optional
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum:=1
+	// +kubebuilder:default:=4
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors="urn:alm:descriptor:com.tectonic.ui:number",displayName="Target Queue Length"
+	TargetQueueLength int32 `json:"targetQueueLength,omitempty"`
+}
+
+// IngesterAutoscalingSpec defines the autoscaling of the ingesters.
+type IngesterAutoscalingSpec struct {
+	// MinReplicas defines the lower limit for the number of ingester replicas. It needs to
+	// be at least the replication factor.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Minimum:=1
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors="urn:alm:descriptor:com.tectonic.ui:number",displayName="Minimum Replicas"
+	MinReplicas int32 `json:"minReplicas"`
+
+	// MaxReplicas defines the upper limit for the number of ingester replicas.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Minimum:=1
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors="urn:alm:descriptor:com.tectonic.ui:number",displayName="Maximum Replicas"
+	MaxReplicas int32 `json:"maxReplicas"`
+
+	// TargetCPUUtilization defines the average CPU utilization of the ingesters, in percent
+	// of their CPU requests, the ingesters are scaled to.
+	//
+	// +optional
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum:=1
+	// +kubebuilder:validation:Maximum:=100
+	// +kubebuilder:default:=80
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors="urn:alm:descriptor:com.tectonic.ui:number",displayName="Target CPU Utilization"
+	TargetCPUUtilization int32 `json:"targetCPUUtilization,omitempty"`
+
+	// ScaleDownDelay defines the minimum time between removing two ingesters, giving the
+	// remaining ingesters the time to take over the streams of the removed one.
+	//
+	// +optional
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default:="10m"
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,xDescriptors="urn:alm:descriptor:com.tectonic.ui:text",displayName="Scale Down Delay"
+	ScaleDownDelay PrometheusDuration `json:"scaleDownDelay,omitempty"`
+}
+
 // LokiStackConditionType deifnes the type of condition types of a Loki deployment.
 type LokiStackConditionType string
 
@@ -1198,6 +1410,27 @@ type LokiStackComponentStatus struct {
 	// +kubebuilder:validation:Optional
 	// +operator-sdk:csv:customresourcedefinitions:type=status,xDescriptors="urn:alm:descriptor:com.tectonic.ui:podStatuses",displayName="Ruler",order=6
 	Ruler PodStatusMap `json:"ruler,omitempty"`
+
+	// BloomCompactor is a map to the per pod status of the bloom compactor statefulset.
+	//
+	// +optional
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=status,xDescriptors="urn:alm:descriptor:com.tectonic.ui:podStatuses",displayName="Bloom Compactor",order=7
+	BloomCompactor PodStatusMap `json:"bloomCompactor,omitempty"`
+
+	// BloomGateway is a map to the per pod status of the bloom gateway statefulset.
+	//
+	// +optional
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=status,xDescriptors="urn:alm:descriptor:com.tectonic.ui:podStatuses",displayName="Bloom Gateway",order=8
+	BloomGateway PodStatusMap `json:"bloomGateway,omitempty"`
+
+	// PatternIngester is a map to the per pod status of the pattern ingester statefulset.
+	//
+	// +optional
+	// +kubebuilder:validation:Optional
+	// +operator-sdk:csv:customresourcedefinitions:type=status,xDescriptors="urn:alm:descriptor:com.tectonic.ui:podStatuses",displayName="Pattern Ingester",order=9
+	PatternIngester PodStatusMap `json:"patternIngester,omitempty"`
 }
 
 // CredentialMode represents the type of authentication used for accessing the object storage.
@@ -1270,7 +1503,7 @@ type LokiStackStatus struct {
 
 // LokiStack is the Schema for the lokistacks API
 //
-// +operator-sdk:csv:customresourcedefinitions:displayName="LokiStack",resources={{Deployment,v1},{StatefulSet,v1},{ConfigMap,v1},{Ingress,v1},{Service,v1},{ServiceAccount,v1},{PersistentVolumeClaims,v1},{Route,v1},{ServiceMonitor,v1}}
+// +operator-sdk:csv:customresourcedefinitions:displayName="LokiStack",resources={{Deployment,v1},{StatefulSet,v1},{HorizontalPodAutoscaler,v2},{ConfigMap,v1},{Ingress,v1},{Service,v1},{ServiceAccount,v1},{PersistentVolumeClaims,v1},{Route,v1},{ServiceMonitor,v1}}
 type LokiStack struct {
 	// LokiStack CR spec field.
 	Spec LokiStackSpec `json:"spec,omitempty"`
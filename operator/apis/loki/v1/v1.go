@@ -83,6 +83,22 @@ var (
 	ErrReplicationSpecConflict = errors.New("replicationSpec and replicationFactor (deprecated) cannot be used at the same time")
 	// ErrIPv6InstanceAddrTypeNotAllowed when the default InstanceAddrType is used with enableIPv6.
 	ErrIPv6InstanceAddrTypeNotAllowed = errors.New(`instanceAddrType "default" cannot be used with enableIPv6 at the same time`)
+	// ErrBloomComponentsNotPaired when only one of the bloom compactor and bloom gateway templates is set.
+	ErrBloomComponentsNotPaired = errors.New("bloomCompactor and bloomGateway need to be enabled together")
+	// ErrBloomRequiresSchemaV13 when the bloom components are enabled while the latest schema is older than v13.
+	ErrBloomRequiresSchemaV13 = errors.New("bloom components require the latest storage schema to be v13")
+	// ErrOTLPIndexLabelNotAllowed when the index_label action is used for scope or log attributes.
+	ErrOTLPIndexLabelNotAllowed = errors.New("index_label action is only allowed for resource attributes")
+	// ErrOTLPAttributesRegexConflict when both attributes and regex are set for an OTLP attributes action.
+	ErrOTLPAttributesRegexConflict = errors.New("attributes and regex cannot be used at the same time")
+	// ErrOTLPAttributesMissing when neither attributes nor regex are set for an OTLP attributes action.
+	ErrOTLPAttributesMissing = errors.New("either attributes or regex needs to be set")
+	// ErrOTLPInvalidRegex when the regex of an OTLP attributes action cannot be compiled.
+	ErrOTLPInvalidRegex = errors.New("Failed to parse regex")
+	// ErrAutoscalingReplicasInvalid when the minimum replicas of an autoscaled component exceed the maximum replicas.
+	ErrAutoscalingReplicasInvalid = errors.New("minReplicas cannot be greater than maxReplicas")
+	// ErrAutoscalingIngesterReplicasTooLow when the minimum ingester replicas are lower than the replication factor.
+	ErrAutoscalingIngesterReplicasTooLow = errors.New("minReplicas of the ingesters cannot be lower than the replication factor")
 
 	// ErrRuleMustMatchNamespace indicates that an expression used in an alerting or recording rule is missing
 	// matchers for a namespace.
@@ -430,6 +430,31 @@ func (in *AuthorizationSpec) DeepCopy() *AuthorizationSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AutoscalingSpec) DeepCopyInto(out *AutoscalingSpec) {
+	*out = *in
+	if in.Querier != nil {
+		in, out := &in.Querier, &out.Querier
+		*out = new(QuerierAutoscalingSpec)
+		**out = **in
+	}
+	if in.Ingester != nil {
+		in, out := &in.Ingester, &out.Ingester
+		*out = new(IngesterAutoscalingSpec)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AutoscalingSpec.
+func (in *AutoscalingSpec) DeepCopy() *AutoscalingSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AutoscalingSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BlockedQuerySpec) DeepCopyInto(out *BlockedQuerySpec) {
 	*out = *in
@@ -519,6 +544,21 @@ func (in *HashRingSpec) DeepCopy() *HashRingSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IngesterAutoscalingSpec) DeepCopyInto(out *IngesterAutoscalingSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IngesterAutoscalingSpec.
+func (in *IngesterAutoscalingSpec) DeepCopy() *IngesterAutoscalingSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(IngesterAutoscalingSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *IngestionLimitSpec) DeepCopyInto(out *IngestionLimitSpec) {
 	*out = *in
@@ -579,6 +619,11 @@ func (in *LimitsTemplateSpec) DeepCopyInto(out *LimitsTemplateSpec) {
 		*out = new(RetentionLimitSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.OTLP != nil {
+		in, out := &in.OTLP, &out.OTLP
+		*out = new(OTLPSpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LimitsTemplateSpec.
@@ -625,6 +670,107 @@ func (in *LokiComponentSpec) DeepCopy() *LokiComponentSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LokiDeleteRequest) DeepCopyInto(out *LokiDeleteRequest) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LokiDeleteRequest.
+func (in *LokiDeleteRequest) DeepCopy() *LokiDeleteRequest {
+	if in == nil {
+		return nil
+	}
+	out := new(LokiDeleteRequest)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *LokiDeleteRequest) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LokiDeleteRequestList) DeepCopyInto(out *LokiDeleteRequestList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]LokiDeleteRequest, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LokiDeleteRequestList.
+func (in *LokiDeleteRequestList) DeepCopy() *LokiDeleteRequestList {
+	if in == nil {
+		return nil
+	}
+	out := new(LokiDeleteRequestList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *LokiDeleteRequestList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LokiDeleteRequestSpec) DeepCopyInto(out *LokiDeleteRequestSpec) {
+	*out = *in
+	in.Start.DeepCopyInto(&out.Start)
+	if in.End != nil {
+		in, out := &in.End, &out.End
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LokiDeleteRequestSpec.
+func (in *LokiDeleteRequestSpec) DeepCopy() *LokiDeleteRequestSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(LokiDeleteRequestSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LokiDeleteRequestStatus) DeepCopyInto(out *LokiDeleteRequestStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LokiDeleteRequestStatus.
+func (in *LokiDeleteRequestStatus) DeepCopy() *LokiDeleteRequestStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(LokiDeleteRequestStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *LokiStack) DeepCopyInto(out *LokiStack) {
 	*out = *in
@@ -783,6 +929,54 @@ func (in *LokiStackComponentStatus) DeepCopyInto(out *LokiStackComponentStatus)
 			(*out)[key] = outVal
 		}
 	}
+	if in.BloomCompactor != nil {
+		in, out := &in.BloomCompactor, &out.BloomCompactor
+		*out = make(PodStatusMap, len(*in))
+		for key, val := range *in {
+			var outVal []string
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				inVal := (*in)[key]
+				in, out := &inVal, &outVal
+				*out = make([]string, len(*in))
+				copy(*out, *in)
+			}
+			(*out)[key] = outVal
+		}
+	}
+	if in.BloomGateway != nil {
+		in, out := &in.BloomGateway, &out.BloomGateway
+		*out = make(PodStatusMap, len(*in))
+		for key, val := range *in {
+			var outVal []string
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				inVal := (*in)[key]
+				in, out := &inVal, &outVal
+				*out = make([]string, len(*in))
+				copy(*out, *in)
+			}
+			(*out)[key] = outVal
+		}
+	}
+	if in.PatternIngester != nil {
+		in, out := &in.PatternIngester, &out.PatternIngester
+		*out = make(PodStatusMap, len(*in))
+		for key, val := range *in {
+			var outVal []string
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				inVal := (*in)[key]
+				in, out := &inVal, &outVal
+				*out = make([]string, len(*in))
+				copy(*out, *in)
+			}
+			(*out)[key] = outVal
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LokiStackComponentStatus.
@@ -866,6 +1060,11 @@ func (in *LokiStackSpec) DeepCopyInto(out *LokiStackSpec) {
 		*out = new(TenantsSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Autoscaling != nil {
+		in, out := &in.Autoscaling, &out.Autoscaling
+		*out = new(AutoscalingSpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LokiStackSpec.
@@ -965,6 +1164,21 @@ func (in *LokiTemplateSpec) DeepCopyInto(out *LokiTemplateSpec) {
 		*out = new(LokiComponentSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.BloomCompactor != nil {
+		in, out := &in.BloomCompactor, &out.BloomCompactor
+		*out = new(LokiComponentSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BloomGateway != nil {
+		in, out := &in.BloomGateway, &out.BloomGateway
+		*out = new(LokiComponentSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PatternIngester != nil {
+		in, out := &in.PatternIngester, &out.PatternIngester
+		*out = new(LokiComponentSpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LokiTemplateSpec.
@@ -1052,6 +1266,82 @@ func (in *OPASpec) DeepCopy() *OPASpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OTLPAttributesSpec) DeepCopyInto(out *OTLPAttributesSpec) {
+	*out = *in
+	if in.Attributes != nil {
+		in, out := &in.Attributes, &out.Attributes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OTLPAttributesSpec.
+func (in *OTLPAttributesSpec) DeepCopy() *OTLPAttributesSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(OTLPAttributesSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OTLPResourceAttributesSpec) DeepCopyInto(out *OTLPResourceAttributesSpec) {
+	*out = *in
+	if in.Attributes != nil {
+		in, out := &in.Attributes, &out.Attributes
+		*out = make([]OTLPAttributesSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OTLPResourceAttributesSpec.
+func (in *OTLPResourceAttributesSpec) DeepCopy() *OTLPResourceAttributesSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(OTLPResourceAttributesSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OTLPSpec) DeepCopyInto(out *OTLPSpec) {
+	*out = *in
+	if in.ResourceAttributes != nil {
+		in, out := &in.ResourceAttributes, &out.ResourceAttributes
+		*out = new(OTLPResourceAttributesSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ScopeAttributes != nil {
+		in, out := &in.ScopeAttributes, &out.ScopeAttributes
+		*out = make([]OTLPAttributesSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LogAttributes != nil {
+		in, out := &in.LogAttributes, &out.LogAttributes
+		*out = make([]OTLPAttributesSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OTLPSpec.
+func (in *OTLPSpec) DeepCopy() *OTLPSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(OTLPSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ObjectStorageSchema) DeepCopyInto(out *ObjectStorageSchema) {
 	*out = *in
@@ -1162,6 +1452,11 @@ func (in *PerTenantLimitsTemplateSpec) DeepCopyInto(out *PerTenantLimitsTemplate
 		*out = new(RetentionLimitSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.OTLP != nil {
+		in, out := &in.OTLP, &out.OTLP
+		*out = new(OTLPSpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PerTenantLimitsTemplateSpec.
@@ -1227,6 +1522,21 @@ func (in PodStatusMap) DeepCopy() PodStatusMap {
 	return *out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QuerierAutoscalingSpec) DeepCopyInto(out *QuerierAutoscalingSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuerierAutoscalingSpec.
+func (in *QuerierAutoscalingSpec) DeepCopy() *QuerierAutoscalingSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(QuerierAutoscalingSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *QueryLimitSpec) DeepCopyInto(out *QueryLimitSpec) {
 	*out = *in
@@ -0,0 +1,79 @@
+package controllers
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	configv1 "github.com/grafana/loki/operator/apis/config/v1"
+	lokiv1 "github.com/grafana/loki/operator/apis/loki/v1"
+	"github.com/grafana/loki/operator/internal/handlers"
+)
+
+// LokiDeleteRequestReconciler reconciles a LokiDeleteRequest object
+type LokiDeleteRequestReconciler struct {
+	client.Client
+	Log          logr.Logger
+	Scheme       *runtime.Scheme
+	FeatureGates configv1.FeatureGates
+}
+
+//+kubebuilder:rbac:groups=loki.grafana.com,resources=lokideleterequests,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=loki.grafana.com,resources=lokideleterequests/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=loki.grafana.com,resources=lokideleterequests/finalizers,verbs=update
+
+// Reconcile submits the LokiDeleteRequest to the compactor of its LokiStack, mirrors the
+// progress of the deletion into its status and cancels the deletion when it is removed.
+//
+// For more details, check Reconcile and its Result here:
+// - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.11.0/pkg/reconcile
+func (r *LokiDeleteRequestReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	requeueAfter, err := handlers.SyncDeleteRequest(ctx, r.Log, req, r.Client, r.FeatureGates)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{RequeueAfter: requeueAfter}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *LokiDeleteRequestReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&lokiv1.LokiDeleteRequest{}).
+		Watches(&lokiv1.LokiStack{}, r.enqueueForLokiStack()).
+		Complete(r)
+}
+
+// enqueueForLokiStack enqueues the delete requests of a LokiStack, which cannot be submitted
+// before the LokiStack exists and enables retention.
+func (r *LokiDeleteRequestReconciler) enqueueForLokiStack() handler.EventHandler {
+	return handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, obj client.Object) []reconcile.Request {
+		deleteRequests := &lokiv1.LokiDeleteRequestList{}
+		if err := r.Client.List(ctx, deleteRequests, client.InNamespace(obj.GetNamespace())); err != nil {
+			r.Log.Error(err, "Error getting LokiDeleteRequest resources in event handler")
+			return nil
+		}
+
+		var requests []reconcile.Request
+		for _, dr := range deleteRequests.Items {
+			if dr.Spec.LokiStack != obj.GetName() {
+				continue
+			}
+
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Namespace: dr.Namespace,
+					Name:      dr.Name,
+				},
+			})
+		}
+
+		return requests
+	})
+}
@@ -13,6 +13,7 @@ import (
 	cloudcredentialv1 "github.com/openshift/cloud-credential-operator/pkg/apis/cloudcredential/v1"
 	"github.com/stretchr/testify/require"
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
@@ -87,61 +88,67 @@ func TestLokiStackController_RegisterOwnedResourcesForUpdateOrDeleteOnly(t *test
 		{
 			obj:           &corev1.ConfigMap{},
 			index:         0,
-			ownCallsCount: 11,
+			ownCallsCount: 12,
 			pred:          updateOrDeleteOnlyPred,
 		},
 		{
 			obj:           &corev1.Secret{},
 			index:         1,
-			ownCallsCount: 11,
+			ownCallsCount: 12,
 			pred:          updateOrDeleteOnlyPred,
 		},
 		{
 			obj:           &corev1.ServiceAccount{},
 			index:         2,
-			ownCallsCount: 11,
+			ownCallsCount: 12,
 			pred:          updateOrDeleteOnlyPred,
 		},
 		{
 			obj:           &corev1.Service{},
 			index:         3,
-			ownCallsCount: 11,
+			ownCallsCount: 12,
 			pred:          updateOrDeleteOnlyPred,
 		},
 		{
 			obj:           &appsv1.Deployment{},
 			index:         4,
-			ownCallsCount: 11,
+			ownCallsCount: 12,
 			pred:          updateOrDeleteWithStatusPred,
 		},
 		{
 			obj:           &appsv1.StatefulSet{},
 			index:         5,
-			ownCallsCount: 11,
+			ownCallsCount: 12,
 			pred:          updateOrDeleteWithStatusPred,
 		},
 		{
 			obj:           &rbacv1.ClusterRole{},
 			index:         6,
-			ownCallsCount: 11,
+			ownCallsCount: 12,
 			pred:          updateOrDeleteOnlyPred,
 		},
 		{
 			obj:           &rbacv1.ClusterRoleBinding{},
 			index:         7,
-			ownCallsCount: 11,
+			ownCallsCount: 12,
 			pred:          updateOrDeleteOnlyPred,
 		},
 		{
 			obj:           &rbacv1.Role{},
 			index:         8,
-			ownCallsCount: 11,
+			ownCallsCount: 12,
 			pred:          updateOrDeleteOnlyPred,
 		},
 		{
 			obj:           &rbacv1.RoleBinding{},
 			index:         9,
-			ownCallsCount: 11,
+			ownCallsCount: 12,
+			pred:          updateOrDeleteOnlyPred,
+		},
+		{
+			obj:           &autoscalingv2.HorizontalPodAutoscaler{},
+			index:         10,
+			ownCallsCount: 12,
 			pred:          updateOrDeleteOnlyPred,
 		},
 		// The next two share the same index, because the
@@ -149,8 +156,8 @@ func TestLokiStackController_RegisterOwnedResourcesForUpdateOrDeleteOnly(t *test
 		// or a Route (i.e. OpenShift).
 		{
 			obj:           &networkingv1.Ingress{},
-			index:         10,
-			ownCallsCount: 11,
+			index:         11,
+			ownCallsCount: 12,
 			featureGates: configv1.FeatureGates{
 				OpenShift: configv1.OpenShiftFeatureGates{
 					Enabled: false,
@@ -160,8 +167,8 @@ func TestLokiStackController_RegisterOwnedResourcesForUpdateOrDeleteOnly(t *test
 		},
 		{
 			obj:           &routev1.Route{},
-			index:         10,
-			ownCallsCount: 12,
+			index:         11,
+			ownCallsCount: 13,
 			featureGates: configv1.FeatureGates{
 				OpenShift: configv1.OpenShiftFeatureGates{
 					Enabled: true,
@@ -171,8 +178,8 @@ func TestLokiStackController_RegisterOwnedResourcesForUpdateOrDeleteOnly(t *test
 		},
 		{
 			obj:           &cloudcredentialv1.CredentialsRequest{},
-			index:         11,
-			ownCallsCount: 12,
+			index:         12,
+			ownCallsCount: 13,
 			featureGates: configv1.FeatureGates{
 				OpenShift: configv1.OpenShiftFeatureGates{
 					Enabled: true,
@@ -0,0 +1,64 @@
+package autoscaling
+
+import (
+	"context"
+
+	"github.com/ViaQ/logerr/v2/kverrors"
+	"github.com/go-logr/logr"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	lokiv1 "github.com/grafana/loki/operator/apis/loki/v1"
+	"github.com/grafana/loki/operator/internal/external/k8s"
+	"github.com/grafana/loki/operator/internal/manifests"
+)
+
+// Cleanup removes the HorizontalPodAutoscalers of the components whose autoscaling is disabled,
+// otherwise they keep overriding the replicas set by the operator. It returns an error to retry
+// the reconciliation loop.
+func Cleanup(ctx context.Context, log logr.Logger, k k8s.Client, stack *lokiv1.LokiStack) error {
+	if !manifests.QuerierAutoscalingEnabled(stack.Spec) {
+		if err := removeAutoscaler(ctx, k, stack, manifests.QuerierName(stack.Name)); err != nil {
+			log.Error(err, "failed to remove querier HorizontalPodAutoscaler")
+			return err
+		}
+	}
+
+	if !manifests.IngesterAutoscalingEnabled(stack.Spec) {
+		if err := removeAutoscaler(ctx, k, stack, manifests.IngesterName(stack.Name)); err != nil {
+			log.Error(err, "failed to remove ingester HorizontalPodAutoscaler")
+			return err
+		}
+	}
+
+	return nil
+}
+
+func removeAutoscaler(ctx context.Context, k k8s.Client, stack *lokiv1.LokiStack, name string) error {
+	key := client.ObjectKey{Name: name, Namespace: stack.Namespace}
+
+	var hpa autoscalingv2.HorizontalPodAutoscaler
+	if err := k.Get(ctx, key, &hpa); err != nil {
+		if apierrors.IsNotFound(err) {
+			// resource doesnt exist, so nothing to do.
+			return nil
+		}
+		return kverrors.Wrap(err, "failed to lookup HorizontalPodAutoscaler", "name", key)
+	}
+
+	// Autoscalers not created by the operator are left alone.
+	if !metav1.IsControlledBy(&hpa, stack) {
+		return nil
+	}
+
+	if err := k.Delete(ctx, &hpa, &client.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+		return kverrors.Wrap(err, "failed to delete HorizontalPodAutoscaler",
+			"name", hpa.Name,
+			"namespace", hpa.Namespace,
+		)
+	}
+
+	return nil
+}
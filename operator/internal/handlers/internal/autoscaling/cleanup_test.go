@@ -0,0 +1,118 @@
+package autoscaling
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/ViaQ/logerr/v2/log"
+	"github.com/stretchr/testify/require"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	lokiv1 "github.com/grafana/loki/operator/apis/loki/v1"
+	"github.com/grafana/loki/operator/internal/external/k8s/k8sfakes"
+)
+
+var logger = log.NewLogger("testing", log.WithOutput(io.Discard))
+
+func newStack(autoscaling *lokiv1.AutoscalingSpec) *lokiv1.LokiStack {
+	return &lokiv1.LokiStack{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: lokiv1.GroupVersion.String(),
+			Kind:       "LokiStack",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "my-stack",
+			Namespace: "some-ns",
+			UID:       "b23f9a38-9672-499f-8c29-15ede74d3ece",
+		},
+		Spec: lokiv1.LokiStackSpec{
+			Autoscaling: autoscaling,
+		},
+	}
+}
+
+func newFakeClient(stack *lokiv1.LokiStack, controlled bool) *k8sfakes.FakeClient {
+	k := &k8sfakes.FakeClient{}
+	k.GetStub = func(_ context.Context, key client.ObjectKey, obj client.Object, _ ...client.GetOption) error {
+		hpa, ok := obj.(*autoscalingv2.HorizontalPodAutoscaler)
+		if !ok {
+			return apierrors.NewNotFound(schema.GroupResource{}, key.Name)
+		}
+		hpa.Name = key.Name
+		hpa.Namespace = key.Namespace
+		if controlled {
+			hpa.OwnerReferences = []metav1.OwnerReference{{
+				APIVersion: stack.APIVersion,
+				Kind:       stack.Kind,
+				Name:       stack.Name,
+				UID:        stack.UID,
+				Controller: ptr.To(true),
+			}}
+		}
+		return nil
+	}
+	return k
+}
+
+func deletedNames(k *k8sfakes.FakeClient) []string {
+	var names []string
+	for i := 0; i < k.DeleteCallCount(); i++ {
+		_, obj, _ := k.DeleteArgsForCall(i)
+		names = append(names, obj.GetName())
+	}
+	return names
+}
+
+func TestCleanup_RemovesAutoscalersWhenDisabled(t *testing.T) {
+	stack := newStack(nil)
+	k := newFakeClient(stack, true)
+
+	require.NoError(t, Cleanup(context.TODO(), logger, k, stack))
+	require.Equal(t, []string{"my-stack-querier", "my-stack-ingester"}, deletedNames(k))
+}
+
+func TestCleanup_KeepsAutoscalersWhenEnabled(t *testing.T) {
+	stack := newStack(&lokiv1.AutoscalingSpec{
+		Querier:  &lokiv1.QuerierAutoscalingSpec{MinReplicas: 2, MaxReplicas: 4},
+		Ingester: &lokiv1.IngesterAutoscalingSpec{MinReplicas: 2, MaxReplicas: 4},
+	})
+	k := newFakeClient(stack, true)
+
+	require.NoError(t, Cleanup(context.TODO(), logger, k, stack))
+	require.Zero(t, k.DeleteCallCount())
+}
+
+func TestCleanup_RemovesOnlyDisabledAutoscaler(t *testing.T) {
+	stack := newStack(&lokiv1.AutoscalingSpec{
+		Ingester: &lokiv1.IngesterAutoscalingSpec{MinReplicas: 2, MaxReplicas: 4},
+	})
+	k := newFakeClient(stack, true)
+
+	require.NoError(t, Cleanup(context.TODO(), logger, k, stack))
+	require.Equal(t, []string{"my-stack-querier"}, deletedNames(k))
+}
+
+func TestCleanup_KeepsAutoscalersNotControlledByStack(t *testing.T) {
+	stack := newStack(nil)
+	k := newFakeClient(stack, false)
+
+	require.NoError(t, Cleanup(context.TODO(), logger, k, stack))
+	require.Zero(t, k.DeleteCallCount())
+}
+
+func TestCleanup_WhenAutoscalerNotFound_IsNoop(t *testing.T) {
+	stack := newStack(nil)
+	k := &k8sfakes.FakeClient{}
+	k.GetStub = func(_ context.Context, key client.ObjectKey, _ client.Object, _ ...client.GetOption) error {
+		return apierrors.NewNotFound(schema.GroupResource{}, key.Name)
+	}
+
+	require.NoError(t, Cleanup(context.TODO(), logger, k, stack))
+	require.Zero(t, k.DeleteCallCount())
+}
@@ -0,0 +1,49 @@
+package clienttls
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+
+	"github.com/ViaQ/logerr/v2/kverrors"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/grafana/loki/operator/internal/certrotation"
+	"github.com/grafana/loki/operator/internal/external/k8s"
+	"github.com/grafana/loki/operator/internal/manifests"
+)
+
+// BuildConfig returns the TLS configuration to call the HTTP endpoints of the components
+// of a LokiStack, authenticating with the gateway client certificate and verifying the
+// serving certificate issued for serverName with the CA bundle of the stack.
+func BuildConfig(ctx context.Context, k k8s.Client, stackName, namespace, serverName string) (*tls.Config, error) {
+	var secret corev1.Secret
+	key := client.ObjectKey{Name: manifests.GatewayClientSecretName(stackName), Namespace: namespace}
+	if err := k.Get(ctx, key, &secret); err != nil {
+		return nil, kverrors.Wrap(err, "failed to lookup client certificate secret", "name", key)
+	}
+
+	cert, err := tls.X509KeyPair(secret.Data[corev1.TLSCertKey], secret.Data[corev1.TLSPrivateKeyKey])
+	if err != nil {
+		return nil, kverrors.Wrap(err, "failed to load client certificate", "name", key)
+	}
+
+	var cm corev1.ConfigMap
+	key = client.ObjectKey{Name: certrotation.CABundleName(stackName), Namespace: namespace}
+	if err := k.Get(ctx, key, &cm); err != nil {
+		return nil, kverrors.Wrap(err, "failed to lookup CA bundle", "name", key)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM([]byte(cm.Data[certrotation.CAFile])) {
+		return nil, kverrors.New("failed to load CA bundle", "name", key)
+	}
+
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		RootCAs:      pool,
+		ServerName:   serverName,
+		MinVersion:   tls.VersionTLS12,
+	}, nil
+}
@@ -0,0 +1,142 @@
+package compactor
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/ViaQ/logerr/v2/kverrors"
+	"github.com/prometheus/common/model"
+)
+
+const (
+	deletePath = "/loki/api/v1/delete"
+
+	// orgIDHeader is the header used by Loki to select the tenant of a request.
+	orgIDHeader = "X-Scope-OrgID"
+
+	requestTimeout = 30 * time.Second
+)
+
+// DeleteRequest is a delete request as reported by the compactor.
+type DeleteRequest struct {
+	RequestID string     `json:"request_id"`
+	StartTime model.Time `json:"start_time"`
+	EndTime   model.Time `json:"end_time"`
+	Query     string     `json:"query"`
+	Status    string     `json:"status"`
+	CreatedAt model.Time `json:"created_at"`
+}
+
+// ResponseError is returned when the compactor responds with a non-successful status code.
+type ResponseError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *ResponseError) Error() string {
+	return fmt.Sprintf("unexpected compactor response: status %d: %s", e.StatusCode, e.Body)
+}
+
+// Client calls the log deletion API of a compactor.
+type Client interface {
+	// AddDeleteRequest requests the deletion of the log entries of a tenant matching the
+	// query in the given time range. The compactor does not return the identifier of the
+	// created request, it needs to be looked up with ListDeleteRequests.
+	AddDeleteRequest(ctx context.Context, tenantID, query string, start, end time.Time) error
+	// ListDeleteRequests returns all delete requests of a tenant.
+	ListDeleteRequests(ctx context.Context, tenantID string) ([]DeleteRequest, error)
+	// CancelDeleteRequest cancels the not yet processed parts of a delete request.
+	CancelDeleteRequest(ctx context.Context, tenantID, requestID string) error
+}
+
+type httpClient struct {
+	client *http.Client
+	url    string
+}
+
+// NewClient returns a Client calling the compactor at addr over HTTPS when a TLS
+// configuration is given, otherwise over plain HTTP.
+func NewClient(addr string, tlsConfig *tls.Config) Client {
+	c := &httpClient{
+		client: &http.Client{Timeout: requestTimeout},
+		url:    fmt.Sprintf("http://%s%s", addr, deletePath),
+	}
+
+	if tlsConfig != nil {
+		c.client.Transport = &http.Transport{TLSClientConfig: tlsConfig}
+		c.url = fmt.Sprintf("https://%s%s", addr, deletePath)
+	}
+
+	return c
+}
+
+func (c *httpClient) AddDeleteRequest(ctx context.Context, tenantID, query string, start, end time.Time) error {
+	params := url.Values{}
+	params.Set("query", query)
+	params.Set("start", strconv.FormatInt(start.Unix(), 10))
+	params.Set("end", strconv.FormatInt(end.Unix(), 10))
+
+	_, err := c.do(ctx, http.MethodPost, tenantID, params)
+	return err
+}
+
+func (c *httpClient) ListDeleteRequests(ctx context.Context, tenantID string) ([]DeleteRequest, error) {
+	body, err := c.do(ctx, http.MethodGet, tenantID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var requests []DeleteRequest
+	if err := json.Unmarshal(body, &requests); err != nil {
+		return nil, kverrors.Wrap(err, "failed to decode delete requests", "tenant", tenantID)
+	}
+
+	return requests, nil
+}
+
+func (c *httpClient) CancelDeleteRequest(ctx context.Context, tenantID, requestID string) error {
+	params := url.Values{}
+	params.Set("request_id", requestID)
+	// Cancel the remaining parts of a partially processed request too.
+	params.Set("force", "true")
+
+	_, err := c.do(ctx, http.MethodDelete, tenantID, params)
+	return err
+}
+
+func (c *httpClient) do(ctx context.Context, method, tenantID string, params url.Values) ([]byte, error) {
+	u := c.url
+	if len(params) > 0 {
+		u = fmt.Sprintf("%s?%s", u, params.Encode())
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u, nil)
+	if err != nil {
+		return nil, kverrors.Wrap(err, "failed to create compactor request", "url", c.url)
+	}
+	req.Header.Set(orgIDHeader, tenantID)
+
+	res, err := c.client.Do(req)
+	if err != nil {
+		return nil, kverrors.Wrap(err, "failed to call compactor", "url", c.url)
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(res.Body, 10<<20))
+	if err != nil {
+		return nil, kverrors.Wrap(err, "failed to read compactor response", "url", c.url)
+	}
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return nil, &ResponseError{StatusCode: res.StatusCode, Body: string(body)}
+	}
+
+	return body, nil
+}
@@ -0,0 +1,66 @@
+package compactor
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_CallsDeleteEndpoint(t *testing.T) {
+	var calls []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "application", r.Header.Get("X-Scope-OrgID"))
+		calls = append(calls, r.Method+" "+r.URL.Path+"?"+r.URL.RawQuery)
+
+		if r.Method == http.MethodGet {
+			_, _ = w.Write([]byte(`[{"request_id":"abc","start_time":1704067200,"end_time":1704153600,"query":"{app=\"foo\"}","status":"50% Complete","created_at":1704200000.5}]`))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	c := NewClient(strings.TrimPrefix(srv.URL, "http://"), nil)
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	require.NoError(t, c.AddDeleteRequest(context.TODO(), "application", `{app="foo"}`, start, end))
+
+	requests, err := c.ListDeleteRequests(context.TODO(), "application")
+	require.NoError(t, err)
+	require.Len(t, requests, 1)
+	require.Equal(t, "abc", requests[0].RequestID)
+	require.Equal(t, `{app="foo"}`, requests[0].Query)
+	require.Equal(t, "50% Complete", requests[0].Status)
+	require.True(t, requests[0].StartTime.Time().Equal(start))
+	require.True(t, requests[0].EndTime.Time().Equal(end))
+
+	require.NoError(t, c.CancelDeleteRequest(context.TODO(), "application", "abc"))
+
+	require.Equal(t, []string{
+		"POST /loki/api/v1/delete?end=1704153600&query=%7Bapp%3D%22foo%22%7D&start=1704067200",
+		"GET /loki/api/v1/delete?",
+		"DELETE /loki/api/v1/delete?force=true&request_id=abc",
+	}, calls)
+}
+
+func TestClient_ReturnsResponseError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, "invalid query expression", http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	c := NewClient(strings.TrimPrefix(srv.URL, "http://"), nil)
+	err := c.AddDeleteRequest(context.TODO(), "application", "{", time.Now(), time.Now())
+
+	var resErr *ResponseError
+	require.True(t, errors.As(err, &resErr))
+	require.Equal(t, http.StatusBadRequest, resErr.StatusCode)
+	require.Contains(t, resErr.Body, "invalid query expression")
+}
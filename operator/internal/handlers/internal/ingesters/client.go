@@ -0,0 +1,79 @@
+package ingesters
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/ViaQ/logerr/v2/kverrors"
+)
+
+const (
+	prepareShutdownPath = "/ingester/prepare_shutdown"
+
+	// requestTimeout bounds the calls to the ingesters, which run within the LokiStack reconciliation.
+	requestTimeout = 10 * time.Second
+)
+
+// Client calls the HTTP endpoints of an ingester used to remove it safely from the ring.
+type Client interface {
+	// PrepareShutdown configures the ingester to flush its data and leave the ring on shutdown.
+	// The call returns immediately, the data is flushed once the ingester is stopped.
+	PrepareShutdown(ctx context.Context, addr string) error
+	// CancelPrepareShutdown reverts PrepareShutdown, when the ingester is not removed after all.
+	CancelPrepareShutdown(ctx context.Context, addr string) error
+}
+
+type httpClient struct {
+	client *http.Client
+	scheme string
+}
+
+// NewClient returns a Client calling the ingesters over HTTPS when a TLS
+// configuration is given, otherwise over plain HTTP.
+func NewClient(tlsConfig *tls.Config) Client {
+	c := &httpClient{
+		client: &http.Client{Timeout: requestTimeout},
+		scheme: "http",
+	}
+
+	if tlsConfig != nil {
+		c.client.Transport = &http.Transport{TLSClientConfig: tlsConfig}
+		c.scheme = "https"
+	}
+
+	return c
+}
+
+func (c *httpClient) PrepareShutdown(ctx context.Context, addr string) error {
+	return c.do(ctx, http.MethodPost, addr, prepareShutdownPath)
+}
+
+func (c *httpClient) CancelPrepareShutdown(ctx context.Context, addr string) error {
+	return c.do(ctx, http.MethodDelete, addr, prepareShutdownPath)
+}
+
+func (c *httpClient) do(ctx context.Context, method, addr, path string) error {
+	url := fmt.Sprintf("%s://%s%s", c.scheme, addr, path)
+
+	req, err := http.NewRequestWithContext(ctx, method, url, nil)
+	if err != nil {
+		return kverrors.Wrap(err, "failed to create ingester request", "url", url)
+	}
+
+	res, err := c.client.Do(req)
+	if err != nil {
+		return kverrors.Wrap(err, "failed to call ingester", "url", url)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return kverrors.New("unexpected ingester response", "url", url, "status", res.StatusCode, "body", string(body))
+	}
+
+	return nil
+}
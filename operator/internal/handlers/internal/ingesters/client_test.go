@@ -0,0 +1,41 @@
+package ingesters
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_CallsIngesterEndpoints(t *testing.T) {
+	var calls []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls = append(calls, r.Method+" "+r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	addr := strings.TrimPrefix(srv.URL, "http://")
+	c := NewClient(nil)
+
+	require.NoError(t, c.PrepareShutdown(context.TODO(), addr))
+	require.NoError(t, c.CancelPrepareShutdown(context.TODO(), addr))
+
+	require.Equal(t, []string{
+		"POST /ingester/prepare_shutdown",
+		"DELETE /ingester/prepare_shutdown",
+	}, calls)
+}
+
+func TestClient_ReturnsErrorOnFailedResponse(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, "shutdown marker path not configured", http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	err := NewClient(nil).PrepareShutdown(context.TODO(), strings.TrimPrefix(srv.URL, "http://"))
+	require.ErrorContains(t, err, "unexpected ingester response")
+}
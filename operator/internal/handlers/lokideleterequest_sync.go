@@ -0,0 +1,337 @@
+package handlers
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/ViaQ/logerr/v2/kverrors"
+	"github.com/go-logr/logr"
+	"github.com/prometheus/common/model"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	configv1 "github.com/grafana/loki/operator/apis/config/v1"
+	lokiv1 "github.com/grafana/loki/operator/apis/loki/v1"
+	"github.com/grafana/loki/operator/internal/external/k8s"
+	"github.com/grafana/loki/operator/internal/handlers/internal/clienttls"
+	"github.com/grafana/loki/operator/internal/handlers/internal/compactor"
+	"github.com/grafana/loki/operator/internal/manifests"
+)
+
+const (
+	// deleteRequestFinalizer is the finalizer keeping a LokiDeleteRequest until its delete
+	// request in the compactor is cancelled.
+	deleteRequestFinalizer = "loki.grafana.com/delete-request"
+
+	// deleteRequestSyncPeriod is the interval for mirroring the progress of a delete request.
+	// The compactor processes delete requests only once per compaction interval.
+	deleteRequestSyncPeriod = 5 * time.Minute
+
+	// deleteRequestClockSkewTolerance is how much earlier than the LokiDeleteRequest a delete request
+	// submitted for it can be created according to the clock of the compactor.
+	deleteRequestClockSkewTolerance = 5 * time.Minute
+
+	deleteRequestStatusProcessed = "processed"
+)
+
+// SyncDeleteRequest submits a LokiDeleteRequest to the compactor of its LokiStack and mirrors
+// the progress reported by the compactor into its status. When the LokiDeleteRequest is
+// deleted, the not yet processed parts of the compactor delete request are cancelled. Returns
+// the time after which the progress needs to be checked again, zero if there is nothing left
+// to check.
+func SyncDeleteRequest(ctx context.Context, log logr.Logger, req ctrl.Request, k k8s.Client, fg configv1.FeatureGates) (time.Duration, error) {
+	return syncDeleteRequest(ctx, log, req, k, fg, compactor.NewClient)
+}
+
+func syncDeleteRequest(
+	ctx context.Context,
+	log logr.Logger,
+	req ctrl.Request,
+	k k8s.Client,
+	fg configv1.FeatureGates,
+	newClient func(string, *tls.Config) compactor.Client,
+) (time.Duration, error) {
+	ll := log.WithValues("lokideleterequest", req.String(), "event", "syncDeleteRequest")
+
+	var dr lokiv1.LokiDeleteRequest
+	if err := k.Get(ctx, req.NamespacedName, &dr); err != nil {
+		if apierrors.IsNotFound(err) {
+			return 0, nil
+		}
+		return 0, kverrors.Wrap(err, "failed to lookup lokideleterequest", "name", req.NamespacedName)
+	}
+
+	if !dr.DeletionTimestamp.IsZero() {
+		return 0, cancelDeleteRequest(ctx, ll, k, fg, &dr, newClient)
+	}
+
+	if controllerutil.AddFinalizer(&dr, deleteRequestFinalizer) {
+		if err := k.Update(ctx, &dr); err != nil {
+			return 0, kverrors.Wrap(err, "failed to add finalizer", "name", req.NamespacedName)
+		}
+	}
+
+	if deleteRequestProcessed(&dr) {
+		return 0, nil
+	}
+
+	var stack lokiv1.LokiStack
+	key := client.ObjectKey{Name: dr.Spec.LokiStack, Namespace: dr.Namespace}
+	if err := k.Get(ctx, key, &stack); err != nil {
+		if apierrors.IsNotFound(err) {
+			setDeleteRequestCondition(&dr, lokiv1.ConditionDeleteRequestSubmitted, metav1.ConditionFalse,
+				lokiv1.ReasonDeleteRequestLokiStackNotFound, fmt.Sprintf("LokiStack %q not found", dr.Spec.LokiStack))
+			return 0, updateDeleteRequestStatus(ctx, k, &dr)
+		}
+		return 0, kverrors.Wrap(err, "failed to lookup lokistack", "name", key)
+	}
+
+	if !manifests.RetentionEnabled(&stack.Spec) {
+		setDeleteRequestCondition(&dr, lokiv1.ConditionDeleteRequestSubmitted, metav1.ConditionFalse,
+			lokiv1.ReasonDeleteRequestRetentionDisabled, "The compactor serves delete requests only when the LokiStack enables retention")
+		return 0, updateDeleteRequestStatus(ctx, k, &dr)
+	}
+
+	c, err := newCompactorClient(ctx, k, fg, &stack, newClient)
+	if err != nil {
+		return 0, err
+	}
+
+	if dr.Status.RequestID == "" {
+		id, err := submitDeleteRequest(ctx, c, &dr)
+		if err != nil {
+			var resErr *compactor.ResponseError
+			if errors.As(err, &resErr) && resErr.StatusCode == http.StatusBadRequest {
+				setDeleteRequestCondition(&dr, lokiv1.ConditionDeleteRequestSubmitted, metav1.ConditionFalse,
+					lokiv1.ReasonDeleteRequestRejected, resErr.Body)
+				return 0, updateDeleteRequestStatus(ctx, k, &dr)
+			}
+			return 0, kverrors.Wrap(err, "failed to submit delete request", "name", req.NamespacedName)
+		}
+		if id == "" {
+			// Record the submission, so that the request is not submitted again while the compactor does not list it.
+			ll.Info("submitted delete request not listed by the compactor yet")
+			setDeleteRequestCondition(&dr, lokiv1.ConditionDeleteRequestSubmitted, metav1.ConditionUnknown,
+				lokiv1.ReasonDeleteRequestPending, "The delete request was submitted, but the compactor does not list it yet")
+			return deleteRequestSyncPeriod, updateDeleteRequestStatus(ctx, k, &dr)
+		}
+
+		ll.Info("submitted delete request", "request_id", id)
+		dr.Status.RequestID = id
+	}
+
+	requests, err := c.ListDeleteRequests(ctx, dr.Spec.TenantID)
+	if err != nil {
+		return 0, kverrors.Wrap(err, "failed to list delete requests", "name", req.NamespacedName)
+	}
+
+	r, ok := findDeleteRequestByID(requests, dr.Status.RequestID)
+	if !ok {
+		setDeleteRequestCondition(&dr, lokiv1.ConditionDeleteRequestSubmitted, metav1.ConditionFalse,
+			lokiv1.ReasonDeleteRequestNotFound, fmt.Sprintf("Delete request %q not found in the compactor", dr.Status.RequestID))
+		return 0, updateDeleteRequestStatus(ctx, k, &dr)
+	}
+
+	dr.Status.Progress = r.Status
+	setDeleteRequestCondition(&dr, lokiv1.ConditionDeleteRequestSubmitted, metav1.ConditionTrue,
+		lokiv1.ReasonDeleteRequestAccepted, "The compactor accepted the delete request")
+
+	requeueAfter := deleteRequestSyncPeriod
+	if r.Status == deleteRequestStatusProcessed {
+		setDeleteRequestCondition(&dr, lokiv1.ConditionDeleteRequestProcessed, metav1.ConditionTrue,
+			lokiv1.ReasonDeleteRequestCompleted, "The compactor deleted all matching log entries")
+		requeueAfter = 0
+	} else {
+		setDeleteRequestCondition(&dr, lokiv1.ConditionDeleteRequestProcessed, metav1.ConditionFalse,
+			lokiv1.ReasonDeleteRequestInProgress, "The compactor did not yet delete all matching log entries")
+	}
+
+	if err := updateDeleteRequestStatus(ctx, k, &dr); err != nil {
+		return 0, err
+	}
+
+	return requeueAfter, nil
+}
+
+// submitDeleteRequest adds the delete request to the compactor and returns its identifier.
+// The compactor does not return the identifier of a new request, thus it is looked up by
+// query and time range. A request already submitted by an earlier reconciliation that failed
+// to record its identifier is reused instead of being submitted twice. The identifier is empty
+// if the compactor does not list the submitted request yet.
+func submitDeleteRequest(ctx context.Context, c compactor.Client, dr *lokiv1.LokiDeleteRequest) (string, error) {
+	start := dr.Spec.Start.Time
+	end := dr.CreationTimestamp.Time
+	if dr.Spec.End != nil {
+		end = dr.Spec.End.Time
+	}
+
+	requests, err := c.ListDeleteRequests(ctx, dr.Spec.TenantID)
+	if err != nil {
+		return "", err
+	}
+
+	if r, ok := findDeleteRequest(requests, dr, start, end); ok {
+		return r.RequestID, nil
+	}
+	if deleteRequestPending(dr) {
+		return "", nil
+	}
+
+	if err := c.AddDeleteRequest(ctx, dr.Spec.TenantID, dr.Spec.Selector, start, end); err != nil {
+		return "", err
+	}
+
+	requests, err = c.ListDeleteRequests(ctx, dr.Spec.TenantID)
+	if err != nil {
+		return "", err
+	}
+
+	r, _ := findDeleteRequest(requests, dr, start, end)
+	return r.RequestID, nil
+}
+
+// findDeleteRequest returns the oldest delete request matching the query and time range of
+// the LokiDeleteRequest created after it, give or take the clock skew of the compactor.
+func findDeleteRequest(requests []compactor.DeleteRequest, dr *lokiv1.LokiDeleteRequest, start, end time.Time) (compactor.DeleteRequest, bool) {
+	var (
+		found   compactor.DeleteRequest
+		ok      bool
+		created = model.TimeFromUnix(dr.CreationTimestamp.Add(-deleteRequestClockSkewTolerance).Unix())
+	)
+
+	for _, r := range requests {
+		if r.Query != dr.Spec.Selector ||
+			r.StartTime != model.TimeFromUnix(start.Unix()) ||
+			r.EndTime != model.TimeFromUnix(end.Unix()) ||
+			r.CreatedAt.Before(created) {
+			continue
+		}
+
+		if !ok || r.CreatedAt.Before(found.CreatedAt) {
+			found, ok = r, true
+		}
+	}
+
+	return found, ok
+}
+
+func findDeleteRequestByID(requests []compactor.DeleteRequest, id string) (compactor.DeleteRequest, bool) {
+	for _, r := range requests {
+		if r.RequestID == id {
+			return r, true
+		}
+	}
+	return compactor.DeleteRequest{}, false
+}
+
+// cancelDeleteRequest cancels the compactor delete request of a deleted LokiDeleteRequest
+// and removes the finalizer.
+func cancelDeleteRequest(
+	ctx context.Context,
+	log logr.Logger,
+	k k8s.Client,
+	fg configv1.FeatureGates,
+	dr *lokiv1.LokiDeleteRequest,
+	newClient func(string, *tls.Config) compactor.Client,
+) error {
+	if !controllerutil.ContainsFinalizer(dr, deleteRequestFinalizer) {
+		return nil
+	}
+
+	if dr.Status.RequestID != "" && !deleteRequestProcessed(dr) {
+		var stack lokiv1.LokiStack
+		key := client.ObjectKey{Name: dr.Spec.LokiStack, Namespace: dr.Namespace}
+		err := k.Get(ctx, key, &stack)
+		switch {
+		case apierrors.IsNotFound(err):
+			// Without a LokiStack there is no compactor left to cancel the request in.
+		case err != nil:
+			return kverrors.Wrap(err, "failed to lookup lokistack", "name", key)
+		default:
+			c, err := newCompactorClient(ctx, k, fg, &stack, newClient)
+			if err != nil {
+				return err
+			}
+
+			err = c.CancelDeleteRequest(ctx, dr.Spec.TenantID, dr.Status.RequestID)
+			var resErr *compactor.ResponseError
+			switch {
+			case err == nil:
+				log.Info("cancelled delete request", "request_id", dr.Status.RequestID)
+			case errors.As(err, &resErr) && (resErr.StatusCode == http.StatusBadRequest || resErr.StatusCode == http.StatusNotFound):
+				// The request is already processed or no longer exists.
+				log.Info("delete request not cancelled", "request_id", dr.Status.RequestID, "reason", resErr.Body)
+			default:
+				return kverrors.Wrap(err, "failed to cancel delete request", "request_id", dr.Status.RequestID)
+			}
+		}
+	}
+
+	controllerutil.RemoveFinalizer(dr, deleteRequestFinalizer)
+	if err := k.Update(ctx, dr); err != nil {
+		return kverrors.Wrap(err, "failed to remove finalizer", "name", client.ObjectKeyFromObject(dr))
+	}
+
+	return nil
+}
+
+func newCompactorClient(
+	ctx context.Context,
+	k k8s.Client,
+	fg configv1.FeatureGates,
+	stack *lokiv1.LokiStack,
+	newClient func(string, *tls.Config) compactor.Client,
+) (compactor.Client, error) {
+	var tlsConfig *tls.Config
+	if fg.HTTPEncryption {
+		var err error
+		serverName := manifests.CompactorHTTPServiceFQDN(stack.Name, stack.Namespace)
+		tlsConfig, err = clienttls.BuildConfig(ctx, k, stack.Name, stack.Namespace, serverName)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return newClient(manifests.CompactorHTTPServiceAddress(stack.Name, stack.Namespace), tlsConfig), nil
+}
+
+// deleteRequestPending returns true if the delete request was submitted to the compactor, which did not list it yet.
+func deleteRequestPending(dr *lokiv1.LokiDeleteRequest) bool {
+	c := meta.FindStatusCondition(dr.Status.Conditions, string(lokiv1.ConditionDeleteRequestSubmitted))
+	return c != nil && c.Reason == string(lokiv1.ReasonDeleteRequestPending)
+}
+
+func deleteRequestProcessed(dr *lokiv1.LokiDeleteRequest) bool {
+	return meta.IsStatusConditionTrue(dr.Status.Conditions, string(lokiv1.ConditionDeleteRequestProcessed))
+}
+
+func setDeleteRequestCondition(
+	dr *lokiv1.LokiDeleteRequest,
+	condType lokiv1.LokiDeleteRequestConditionType,
+	status metav1.ConditionStatus,
+	reason lokiv1.LokiDeleteRequestConditionReason,
+	message string,
+) {
+	meta.SetStatusCondition(&dr.Status.Conditions, metav1.Condition{
+		Type:               string(condType),
+		Status:             status,
+		Reason:             string(reason),
+		Message:            message,
+		ObservedGeneration: dr.Generation,
+	})
+}
+
+func updateDeleteRequestStatus(ctx context.Context, k k8s.Client, dr *lokiv1.LokiDeleteRequest) error {
+	if err := k.Status().Update(ctx, dr); err != nil {
+		return kverrors.Wrap(err, "failed to update lokideleterequest status", "name", client.ObjectKeyFromObject(dr))
+	}
+	return nil
+}
@@ -0,0 +1,357 @@
+package handlers
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	configv1 "github.com/grafana/loki/operator/apis/config/v1"
+	lokiv1 "github.com/grafana/loki/operator/apis/loki/v1"
+	"github.com/grafana/loki/operator/internal/external/k8s/k8sfakes"
+	"github.com/grafana/loki/operator/internal/handlers/internal/compactor"
+)
+
+type fakeCompactorClient struct {
+	addr      string
+	requests  []compactor.DeleteRequest
+	createdAt time.Time
+	added     int
+	addErr    error
+	cancelled []string
+	cancelErr error
+}
+
+func (f *fakeCompactorClient) AddDeleteRequest(_ context.Context, _, query string, start, end time.Time) error {
+	if f.addErr != nil {
+		return f.addErr
+	}
+	createdAt := f.createdAt
+	if createdAt.IsZero() {
+		createdAt = deleteRequestCreated.Add(time.Minute)
+	}
+	f.added++
+	f.requests = append(f.requests, compactor.DeleteRequest{
+		RequestID: "new-id",
+		Query:     query,
+		StartTime: model.TimeFromUnix(start.Unix()),
+		EndTime:   model.TimeFromUnix(end.Unix()),
+		Status:    "received",
+		CreatedAt: model.TimeFromUnix(createdAt.Unix()),
+	})
+	return nil
+}
+
+func (f *fakeCompactorClient) ListDeleteRequests(context.Context, string) ([]compactor.DeleteRequest, error) {
+	return f.requests, nil
+}
+
+func (f *fakeCompactorClient) CancelDeleteRequest(_ context.Context, _, requestID string) error {
+	f.cancelled = append(f.cancelled, requestID)
+	return f.cancelErr
+}
+
+var (
+	deleteRequestCreated = time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	deleteRequestReq = ctrl.Request{
+		NamespacedName: types.NamespacedName{
+			Name:      "my-delete",
+			Namespace: "some-ns",
+		},
+	}
+)
+
+type deleteRequestFixture struct {
+	dr    lokiv1.LokiDeleteRequest
+	stack *lokiv1.LokiStack
+	sw    *k8sfakes.FakeStatusWriter
+	cc    *fakeCompactorClient
+}
+
+func newDeleteRequestFixture() *deleteRequestFixture {
+	return &deleteRequestFixture{
+		dr: lokiv1.LokiDeleteRequest{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "my-delete",
+				Namespace:         "some-ns",
+				CreationTimestamp: metav1.NewTime(deleteRequestCreated),
+			},
+			Spec: lokiv1.LokiDeleteRequestSpec{
+				LokiStack: "my-stack",
+				TenantID:  "application",
+				Selector:  `{app="foo"} |= "password"`,
+				Start:     metav1.NewTime(time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)),
+			},
+		},
+		stack: &lokiv1.LokiStack{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "my-stack",
+				Namespace: "some-ns",
+			},
+			Spec: lokiv1.LokiStackSpec{
+				Limits: &lokiv1.LimitsSpec{
+					Global: &lokiv1.LimitsTemplateSpec{
+						Retention: &lokiv1.RetentionLimitSpec{Days: 30},
+					},
+				},
+			},
+		},
+		sw: &k8sfakes.FakeStatusWriter{},
+		cc: &fakeCompactorClient{},
+	}
+}
+
+func (f *deleteRequestFixture) sync(t *testing.T) (*k8sfakes.FakeClient, time.Duration, error) {
+	k := &k8sfakes.FakeClient{}
+	k.GetStub = func(_ context.Context, name types.NamespacedName, object client.Object, _ ...client.GetOption) error {
+		switch object.(type) {
+		case *lokiv1.LokiDeleteRequest:
+			if name.Name == f.dr.Name {
+				k.SetClientObject(object, &f.dr)
+				return nil
+			}
+		case *lokiv1.LokiStack:
+			if f.stack != nil && name.Name == f.stack.Name {
+				k.SetClientObject(object, f.stack)
+				return nil
+			}
+		}
+		return apierrors.NewNotFound(schema.GroupResource{}, name.Name)
+	}
+	k.StatusStub = func() client.StatusWriter { return f.sw }
+
+	requeueAfter, err := syncDeleteRequest(context.TODO(), logger, deleteRequestReq, k, configv1.FeatureGates{}, func(addr string, tlsConfig *tls.Config) compactor.Client {
+		require.Nil(t, tlsConfig)
+		f.cc.addr = addr
+		return f.cc
+	})
+	return k, requeueAfter, err
+}
+
+func (f *deleteRequestFixture) status(t *testing.T) lokiv1.LokiDeleteRequestStatus {
+	require.Equal(t, 1, f.sw.UpdateCallCount())
+	_, obj, _ := f.sw.UpdateArgsForCall(0)
+	return obj.(*lokiv1.LokiDeleteRequest).Status
+}
+
+func requireDeleteRequestCondition(t *testing.T, status lokiv1.LokiDeleteRequestStatus, condType lokiv1.LokiDeleteRequestConditionType, s metav1.ConditionStatus, reason lokiv1.LokiDeleteRequestConditionReason) {
+	c := meta.FindStatusCondition(status.Conditions, string(condType))
+	require.NotNil(t, c, "missing condition %s", condType)
+	require.Equal(t, s, c.Status)
+	require.Equal(t, string(reason), c.Reason)
+}
+
+func TestSyncDeleteRequest_SubmitsDeleteRequest(t *testing.T) {
+	f := newDeleteRequestFixture()
+
+	k, requeueAfter, err := f.sync(t)
+	require.NoError(t, err)
+	require.Equal(t, deleteRequestSyncPeriod, requeueAfter)
+
+	require.Equal(t, "my-stack-compactor-http.some-ns.svc.cluster.local:3100", f.cc.addr)
+	require.Equal(t, 1, f.cc.added)
+	require.Equal(t, model.TimeFromUnix(deleteRequestCreated.Unix()), f.cc.requests[0].EndTime)
+
+	require.Equal(t, 1, k.UpdateCallCount())
+	_, obj, _ := k.UpdateArgsForCall(0)
+	require.Contains(t, obj.GetFinalizers(), deleteRequestFinalizer)
+
+	status := f.status(t)
+	require.Equal(t, "new-id", status.RequestID)
+	require.Equal(t, "received", status.Progress)
+	requireDeleteRequestCondition(t, status, lokiv1.ConditionDeleteRequestSubmitted, metav1.ConditionTrue, lokiv1.ReasonDeleteRequestAccepted)
+	requireDeleteRequestCondition(t, status, lokiv1.ConditionDeleteRequestProcessed, metav1.ConditionFalse, lokiv1.ReasonDeleteRequestInProgress)
+}
+
+func TestSyncDeleteRequest_ReusesSubmittedDeleteRequest(t *testing.T) {
+	f := newDeleteRequestFixture()
+	f.dr.Spec.End = &metav1.Time{Time: time.Date(2024, 2, 2, 0, 0, 0, 0, time.UTC)}
+	f.cc.requests = []compactor.DeleteRequest{
+		{
+			// Same request submitted before the LokiDeleteRequest was created.
+			RequestID: "older-id",
+			Query:     f.dr.Spec.Selector,
+			StartTime: model.TimeFromUnix(f.dr.Spec.Start.Unix()),
+			EndTime:   model.TimeFromUnix(f.dr.Spec.End.Unix()),
+			Status:    "processed",
+			CreatedAt: model.TimeFromUnix(deleteRequestCreated.Add(-time.Hour).Unix()),
+		},
+		{
+			RequestID: "existing-id",
+			Query:     f.dr.Spec.Selector,
+			StartTime: model.TimeFromUnix(f.dr.Spec.Start.Unix()),
+			EndTime:   model.TimeFromUnix(f.dr.Spec.End.Unix()),
+			Status:    "50% Complete",
+			CreatedAt: model.TimeFromUnix(deleteRequestCreated.Add(time.Second).Unix()),
+		},
+	}
+
+	_, _, err := f.sync(t)
+	require.NoError(t, err)
+	require.Zero(t, f.cc.added)
+
+	status := f.status(t)
+	require.Equal(t, "existing-id", status.RequestID)
+	require.Equal(t, "50% Complete", status.Progress)
+}
+
+func TestSyncDeleteRequest_ToleratesCompactorClockSkew(t *testing.T) {
+	f := newDeleteRequestFixture()
+	// The clock of the compactor is behind the one of the API server.
+	f.cc.createdAt = deleteRequestCreated.Add(-time.Minute)
+
+	_, _, err := f.sync(t)
+	require.NoError(t, err)
+	require.Equal(t, 1, f.cc.added)
+	require.Equal(t, "new-id", f.status(t).RequestID)
+}
+
+func TestSyncDeleteRequest_WhenSubmittedRequestNotListed_DoesNotSubmitAgain(t *testing.T) {
+	f := newDeleteRequestFixture()
+	f.cc.createdAt = deleteRequestCreated.Add(-time.Hour)
+
+	_, requeueAfter, err := f.sync(t)
+	require.NoError(t, err)
+	require.Equal(t, deleteRequestSyncPeriod, requeueAfter)
+	require.Equal(t, 1, f.cc.added)
+
+	status := f.status(t)
+	require.Empty(t, status.RequestID)
+	requireDeleteRequestCondition(t, status, lokiv1.ConditionDeleteRequestSubmitted, metav1.ConditionUnknown, lokiv1.ReasonDeleteRequestPending)
+
+	// The next reconciliation only looks the request up.
+	f.dr.Status = status
+	f.sw = &k8sfakes.FakeStatusWriter{}
+	_, requeueAfter, err = f.sync(t)
+	require.NoError(t, err)
+	require.Equal(t, deleteRequestSyncPeriod, requeueAfter)
+	require.Equal(t, 1, f.cc.added)
+	requireDeleteRequestCondition(t, f.status(t), lokiv1.ConditionDeleteRequestSubmitted, metav1.ConditionUnknown, lokiv1.ReasonDeleteRequestPending)
+}
+
+func TestSyncDeleteRequest_WhenProcessed_StopsRequeue(t *testing.T) {
+	f := newDeleteRequestFixture()
+	f.dr.Finalizers = []string{deleteRequestFinalizer}
+	f.dr.Status.RequestID = "existing-id"
+	f.cc.requests = []compactor.DeleteRequest{
+		{RequestID: "existing-id", Status: "processed"},
+	}
+
+	k, requeueAfter, err := f.sync(t)
+	require.NoError(t, err)
+	require.Zero(t, requeueAfter)
+	require.Zero(t, k.UpdateCallCount())
+	require.Zero(t, f.cc.added)
+
+	status := f.status(t)
+	require.Equal(t, "processed", status.Progress)
+	requireDeleteRequestCondition(t, status, lokiv1.ConditionDeleteRequestProcessed, metav1.ConditionTrue, lokiv1.ReasonDeleteRequestCompleted)
+}
+
+func TestSyncDeleteRequest_NotSubmitted(t *testing.T) {
+	table := []struct {
+		desc   string
+		mutate func(f *deleteRequestFixture)
+		reason lokiv1.LokiDeleteRequestConditionReason
+	}{
+		{
+			desc:   "lokistack not found",
+			mutate: func(f *deleteRequestFixture) { f.stack = nil },
+			reason: lokiv1.ReasonDeleteRequestLokiStackNotFound,
+		},
+		{
+			desc:   "retention disabled",
+			mutate: func(f *deleteRequestFixture) { f.stack.Spec.Limits = nil },
+			reason: lokiv1.ReasonDeleteRequestRetentionDisabled,
+		},
+		{
+			desc: "rejected by compactor",
+			mutate: func(f *deleteRequestFixture) {
+				f.cc.addErr = &compactor.ResponseError{StatusCode: http.StatusBadRequest, Body: "invalid query expression"}
+			},
+			reason: lokiv1.ReasonDeleteRequestRejected,
+		},
+		{
+			desc: "request cancelled in compactor",
+			mutate: func(f *deleteRequestFixture) {
+				f.dr.Status.RequestID = "cancelled-id"
+			},
+			reason: lokiv1.ReasonDeleteRequestNotFound,
+		},
+	}
+	for _, tst := range table {
+		tst := tst
+		t.Run(tst.desc, func(t *testing.T) {
+			t.Parallel()
+
+			f := newDeleteRequestFixture()
+			tst.mutate(f)
+
+			_, requeueAfter, err := f.sync(t)
+			require.NoError(t, err)
+			require.Zero(t, requeueAfter)
+			require.Zero(t, f.cc.added)
+
+			requireDeleteRequestCondition(t, f.status(t), lokiv1.ConditionDeleteRequestSubmitted, metav1.ConditionFalse, tst.reason)
+		})
+	}
+}
+
+func TestSyncDeleteRequest_WhenDeleted_CancelsDeleteRequest(t *testing.T) {
+	table := []struct {
+		desc      string
+		cancelErr error
+	}{
+		{
+			desc: "cancelled",
+		},
+		{
+			desc:      "already processed",
+			cancelErr: &compactor.ResponseError{StatusCode: http.StatusBadRequest, Body: "deletion of request which is in process or already processed is not allowed"},
+		},
+	}
+	for _, tst := range table {
+		tst := tst
+		t.Run(tst.desc, func(t *testing.T) {
+			t.Parallel()
+
+			f := newDeleteRequestFixture()
+			f.dr.Finalizers = []string{deleteRequestFinalizer}
+			f.dr.DeletionTimestamp = &metav1.Time{Time: deleteRequestCreated.Add(time.Hour)}
+			f.dr.Status.RequestID = "existing-id"
+			f.cc.cancelErr = tst.cancelErr
+
+			k, _, err := f.sync(t)
+			require.NoError(t, err)
+			require.Equal(t, []string{"existing-id"}, f.cc.cancelled)
+
+			require.Equal(t, 1, k.UpdateCallCount())
+			_, obj, _ := k.UpdateArgsForCall(0)
+			require.NotContains(t, obj.GetFinalizers(), deleteRequestFinalizer)
+		})
+	}
+}
+
+func TestSyncDeleteRequest_WhenDeletedAndCancelFails_KeepsFinalizer(t *testing.T) {
+	f := newDeleteRequestFixture()
+	f.dr.Finalizers = []string{deleteRequestFinalizer}
+	f.dr.DeletionTimestamp = &metav1.Time{Time: deleteRequestCreated.Add(time.Hour)}
+	f.dr.Status.RequestID = "existing-id"
+	f.cc.cancelErr = &compactor.ResponseError{StatusCode: http.StatusInternalServerError, Body: "store unavailable"}
+
+	k, _, err := f.sync(t)
+	require.Error(t, err)
+	require.Zero(t, k.UpdateCallCount())
+}
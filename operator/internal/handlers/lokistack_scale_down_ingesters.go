@@ -0,0 +1,231 @@
+package handlers
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/ViaQ/logerr/v2/kverrors"
+	"github.com/go-logr/logr"
+	"github.com/prometheus/common/model"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	configv1 "github.com/grafana/loki/operator/apis/config/v1"
+	lokiv1 "github.com/grafana/loki/operator/apis/loki/v1"
+	"github.com/grafana/loki/operator/internal/external/k8s"
+	"github.com/grafana/loki/operator/internal/handlers/internal/clienttls"
+	"github.com/grafana/loki/operator/internal/handlers/internal/ingesters"
+	"github.com/grafana/loki/operator/internal/manifests"
+)
+
+const (
+	defaultIngesterScaleDownDelay       = 10 * time.Minute
+	defaultIngesterTargetCPUUtilization = 80
+	// ingesterScaleDownTolerance is the fraction below the target utilization ignored to
+	// avoid removing ingesters on small load drops, the same as the HorizontalPodAutoscaler.
+	ingesterScaleDownTolerance = 0.1
+	// ingesterHTTPPortName is the name of the container port serving the ingester HTTP endpoints.
+	ingesterHTTPPortName = "metrics"
+)
+
+// ScaleDownIngesters removes one ingester of a LokiStack with autoscaled ingesters when their CPU
+// utilization reported by the ingester HorizontalPodAutoscaler allows for fewer replicas. The
+// ingester with the highest ordinal is prepared for shutdown before the statefulset is scaled
+// down: once stopped, it flushes its chunks and leaves the ring within the termination grace
+// period of its pod. Data not flushed within the grace period remains in the WAL of the removed
+// ingester. Returns the time after which the ingesters need to be checked again, zero if the
+// ingesters are not autoscaled.
+func ScaleDownIngesters(ctx context.Context, log logr.Logger, req ctrl.Request, k k8s.Client, fg configv1.FeatureGates) (time.Duration, error) {
+	return scaleDownIngesters(ctx, log, req, k, fg, time.Now(), ingesters.NewClient)
+}
+
+func scaleDownIngesters(
+	ctx context.Context,
+	log logr.Logger,
+	req ctrl.Request,
+	k k8s.Client,
+	fg configv1.FeatureGates,
+	now time.Time,
+	newClient func(*tls.Config) ingesters.Client,
+) (time.Duration, error) {
+	ll := log.WithValues("lokistack", req.String(), "event", "scaleDownIngesters")
+
+	var stack lokiv1.LokiStack
+	if err := k.Get(ctx, req.NamespacedName, &stack); err != nil {
+		if apierrors.IsNotFound(err) {
+			// maybe the user deleted it before we could react? Either way this isn't an issue
+			ll.Error(err, "could not find the requested loki stack", "name", req.String())
+			return 0, nil
+		}
+		return 0, kverrors.Wrap(err, "failed to lookup lokistack", "name", req.String())
+	}
+
+	if !manifests.IngesterAutoscalingEnabled(stack.Spec) {
+		return 0, nil
+	}
+	spec := stack.Spec.Autoscaling.Ingester
+
+	delay := defaultIngesterScaleDownDelay
+	if spec.ScaleDownDelay != "" {
+		d, err := model.ParseDuration(string(spec.ScaleDownDelay))
+		if err != nil {
+			return 0, kverrors.Wrap(err, "failed to parse ingester scale down delay", "delay", spec.ScaleDownDelay)
+		}
+		delay = time.Duration(d)
+	}
+
+	key := client.ObjectKey{Name: manifests.IngesterName(stack.Name), Namespace: stack.Namespace}
+
+	var hpa autoscalingv2.HorizontalPodAutoscaler
+	if err := k.Get(ctx, key, &hpa); err != nil {
+		if apierrors.IsNotFound(err) {
+			return delay, nil
+		}
+		return 0, kverrors.Wrap(err, "failed to lookup ingester autoscaler", "name", key)
+	}
+
+	var sts appsv1.StatefulSet
+	if err := k.Get(ctx, key, &sts); err != nil {
+		if apierrors.IsNotFound(err) {
+			return delay, nil
+		}
+		return 0, kverrors.Wrap(err, "failed to lookup ingester statefulset", "name", key)
+	}
+
+	replicas := ptr.Deref(sts.Spec.Replicas, 1)
+	if replicas <= spec.MinReplicas {
+		return delay, nil
+	}
+
+	// Only remove an ingester from a ring in which all ingesters are up to date and ready.
+	if sts.Status.ReadyReplicas != replicas || sts.Status.UpdatedReplicas != replicas {
+		return delay, nil
+	}
+
+	if last, ok := sts.Annotations[manifests.AnnotationIngesterScaledDownAt]; ok {
+		lastScaleDown, err := time.Parse(time.RFC3339, last)
+		if err == nil && now.Before(lastScaleDown.Add(delay)) {
+			return lastScaleDown.Add(delay).Sub(now), nil
+		}
+	}
+
+	if desiredIngesterReplicas(&hpa, spec, replicas) >= replicas {
+		return delay, nil
+	}
+
+	var pod corev1.Pod
+	podKey := client.ObjectKey{Name: fmt.Sprintf("%s-%d", sts.Name, replicas-1), Namespace: sts.Namespace}
+	if err := k.Get(ctx, podKey, &pod); err != nil {
+		if apierrors.IsNotFound(err) {
+			return delay, nil
+		}
+		return 0, kverrors.Wrap(err, "failed to lookup ingester pod", "name", podKey)
+	}
+
+	port, ok := ingesterHTTPPort(&pod)
+	if pod.Status.PodIP == "" || !ok {
+		return delay, nil
+	}
+	addr := net.JoinHostPort(pod.Status.PodIP, strconv.Itoa(int(port)))
+
+	var tlsConfig *tls.Config
+	if fg.HTTPEncryption {
+		var err error
+		// The ingesters are called by pod IP, their serving certificates are issued for the service name.
+		serverName := manifests.IngesterHTTPServiceFQDN(stack.Name, stack.Namespace)
+		tlsConfig, err = clienttls.BuildConfig(ctx, k, stack.Name, stack.Namespace, serverName)
+		if err != nil {
+			return 0, err
+		}
+	}
+	c := newClient(tlsConfig)
+
+	if err := c.PrepareShutdown(ctx, addr); err != nil {
+		return 0, kverrors.Wrap(err, "failed to prepare ingester shutdown", "pod", podKey)
+	}
+
+	// The resource version makes the patch fail if the autoscaler changed the replicas in the meantime.
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"resourceVersion": sts.ResourceVersion,
+			"annotations": map[string]string{
+				manifests.AnnotationIngesterScaledDownAt: now.UTC().Format(time.RFC3339),
+			},
+		},
+		"spec": map[string]interface{}{
+			"replicas": replicas - 1,
+		},
+	})
+	if err != nil {
+		return 0, kverrors.Wrap(err, "could not format the statefulset patch", "name", key)
+	}
+
+	if err := k.Patch(ctx, &sts, client.RawPatch(types.MergePatchType, patch)); err != nil {
+		return 0, cancelIngesterShutdown(ctx, ll, c, addr, kverrors.Wrap(err, "failed to scale down ingesters", "name", key))
+	}
+
+	ll.Info("scaled down ingesters", "replicas", replicas-1, "pod", podKey.Name)
+	return delay, nil
+}
+
+// desiredIngesterReplicas returns the number of ingesters needed for the CPU utilization reported
+// by the autoscaler, never less than the minimum replicas and never more than the current ones.
+func desiredIngesterReplicas(hpa *autoscalingv2.HorizontalPodAutoscaler, spec *lokiv1.IngesterAutoscalingSpec, replicas int32) int32 {
+	target := spec.TargetCPUUtilization
+	if target == 0 {
+		target = defaultIngesterTargetCPUUtilization
+	}
+
+	for _, m := range hpa.Status.CurrentMetrics {
+		if m.Type != autoscalingv2.ResourceMetricSourceType || m.Resource == nil || m.Resource.Name != corev1.ResourceCPU {
+			continue
+		}
+		if m.Resource.Current.AverageUtilization == nil {
+			break
+		}
+
+		ratio := float64(*m.Resource.Current.AverageUtilization) / float64(target)
+		if ratio >= 1-ingesterScaleDownTolerance {
+			return replicas
+		}
+
+		desired := int32(math.Ceil(ratio * float64(replicas)))
+		if desired < spec.MinReplicas {
+			return spec.MinReplicas
+		}
+		return desired
+	}
+
+	return replicas
+}
+
+func ingesterHTTPPort(pod *corev1.Pod) (int32, bool) {
+	for _, c := range pod.Spec.Containers {
+		for _, p := range c.Ports {
+			if p.Name == ingesterHTTPPortName {
+				return p.ContainerPort, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// cancelIngesterShutdown reverts the shutdown preparation of an ingester which is not removed.
+func cancelIngesterShutdown(ctx context.Context, log logr.Logger, c ingesters.Client, addr string, err error) error {
+	if cancelErr := c.CancelPrepareShutdown(ctx, addr); cancelErr != nil {
+		log.Error(cancelErr, "failed to cancel ingester shutdown preparation", "addr", addr)
+	}
+	return err
+}
@@ -0,0 +1,301 @@
+package handlers
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/ViaQ/logerr/v2/kverrors"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	configv1 "github.com/grafana/loki/operator/apis/config/v1"
+	lokiv1 "github.com/grafana/loki/operator/apis/loki/v1"
+	"github.com/grafana/loki/operator/internal/external/k8s/k8sfakes"
+	"github.com/grafana/loki/operator/internal/handlers/internal/ingesters"
+	"github.com/grafana/loki/operator/internal/manifests"
+)
+
+type fakeIngesterClient struct {
+	calls []string
+}
+
+func (f *fakeIngesterClient) PrepareShutdown(_ context.Context, addr string) error {
+	f.calls = append(f.calls, "prepare_shutdown "+addr)
+	return nil
+}
+
+func (f *fakeIngesterClient) CancelPrepareShutdown(_ context.Context, addr string) error {
+	f.calls = append(f.calls, "cancel_prepare_shutdown "+addr)
+	return nil
+}
+
+type scaleDownFixture struct {
+	stack       lokiv1.LokiStack
+	sts         appsv1.StatefulSet
+	hpa         autoscalingv2.HorizontalPodAutoscaler
+	pod         corev1.Pod
+	utilization int32
+}
+
+func newScaleDownFixture() *scaleDownFixture {
+	return &scaleDownFixture{
+		stack: lokiv1.LokiStack{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "my-stack",
+				Namespace: "some-ns",
+			},
+			Spec: lokiv1.LokiStackSpec{
+				Autoscaling: &lokiv1.AutoscalingSpec{
+					Ingester: &lokiv1.IngesterAutoscalingSpec{
+						MinReplicas:          2,
+						MaxReplicas:          6,
+						TargetCPUUtilization: 80,
+						ScaleDownDelay:       "10m",
+					},
+				},
+			},
+		},
+		sts: appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            "my-stack-ingester",
+				Namespace:       "some-ns",
+				ResourceVersion: "42",
+			},
+			Spec: appsv1.StatefulSetSpec{
+				Replicas: ptr.To[int32](4),
+			},
+			Status: appsv1.StatefulSetStatus{
+				ReadyReplicas:   4,
+				UpdatedReplicas: 4,
+			},
+		},
+		pod: corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "my-stack-ingester-3",
+				Namespace: "some-ns",
+			},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{
+					{
+						Ports: []corev1.ContainerPort{
+							{Name: "metrics", ContainerPort: 3100},
+							{Name: "grpclb", ContainerPort: 9095},
+						},
+					},
+				},
+			},
+			Status: corev1.PodStatus{
+				PodIP: "10.0.0.4",
+			},
+		},
+		utilization: 30,
+	}
+}
+
+func (f *scaleDownFixture) client() *k8sfakes.FakeClient {
+	k := &k8sfakes.FakeClient{}
+	k.GetStub = func(_ context.Context, name types.NamespacedName, object client.Object, _ ...client.GetOption) error {
+		switch object.(type) {
+		case *lokiv1.LokiStack:
+			if name.Name == f.stack.Name {
+				k.SetClientObject(object, &f.stack)
+				return nil
+			}
+		case *appsv1.StatefulSet:
+			if name.Name == f.sts.Name {
+				k.SetClientObject(object, &f.sts)
+				return nil
+			}
+		case *autoscalingv2.HorizontalPodAutoscaler:
+			hpa := autoscalingv2.HorizontalPodAutoscaler{
+				ObjectMeta: metav1.ObjectMeta{Name: f.sts.Name, Namespace: f.sts.Namespace},
+				Status: autoscalingv2.HorizontalPodAutoscalerStatus{
+					CurrentMetrics: []autoscalingv2.MetricStatus{
+						{
+							Type: autoscalingv2.ResourceMetricSourceType,
+							Resource: &autoscalingv2.ResourceMetricStatus{
+								Name:    corev1.ResourceCPU,
+								Current: autoscalingv2.MetricValueStatus{AverageUtilization: ptr.To(f.utilization)},
+							},
+						},
+					},
+				},
+			}
+			if name.Name == hpa.Name {
+				k.SetClientObject(object, &hpa)
+				return nil
+			}
+		case *corev1.Pod:
+			if name.Name == f.pod.Name {
+				k.SetClientObject(object, &f.pod)
+				return nil
+			}
+		}
+		return apierrors.NewNotFound(schema.GroupResource{}, name.Name)
+	}
+	return k
+}
+
+var scaleDownRequest = ctrl.Request{
+	NamespacedName: types.NamespacedName{
+		Name:      "my-stack",
+		Namespace: "some-ns",
+	},
+}
+
+func TestScaleDownIngesters_WhenNotAutoscaled_DoesNothing(t *testing.T) {
+	f := newScaleDownFixture()
+	f.stack.Spec.Autoscaling = nil
+	k := f.client()
+	ic := &fakeIngesterClient{}
+
+	requeueAfter, err := scaleDownIngesters(context.TODO(), logger, scaleDownRequest, k, configv1.FeatureGates{}, time.Now(), func(*tls.Config) ingesters.Client { return ic })
+	require.NoError(t, err)
+	require.Zero(t, requeueAfter)
+	require.Empty(t, ic.calls)
+	require.Zero(t, k.PatchCallCount())
+}
+
+func TestScaleDownIngesters_RemovesIngesterAfterPrepareShutdown(t *testing.T) {
+	f := newScaleDownFixture()
+	k := f.client()
+	ic := &fakeIngesterClient{}
+	now := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	requeueAfter, err := scaleDownIngesters(context.TODO(), logger, scaleDownRequest, k, configv1.FeatureGates{}, now, func(*tls.Config) ingesters.Client { return ic })
+	require.NoError(t, err)
+	require.Equal(t, 10*time.Minute, requeueAfter)
+	require.Equal(t, []string{"prepare_shutdown 10.0.0.4:3100"}, ic.calls)
+
+	require.Equal(t, 1, k.PatchCallCount())
+	_, obj, patch, _ := k.PatchArgsForCall(0)
+	require.Equal(t, "my-stack-ingester", obj.GetName())
+
+	data, err := patch.Data(obj)
+	require.NoError(t, err)
+	expected, _ := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"resourceVersion": "42",
+			"annotations": map[string]string{
+				manifests.AnnotationIngesterScaledDownAt: "2024-03-01T10:00:00Z",
+			},
+		},
+		"spec": map[string]interface{}{
+			"replicas": 3,
+		},
+	})
+	require.JSONEq(t, string(expected), string(data))
+}
+
+func TestScaleDownIngesters_WaitsForScaleDownDelay(t *testing.T) {
+	f := newScaleDownFixture()
+	f.sts.Annotations = map[string]string{
+		manifests.AnnotationIngesterScaledDownAt: "2024-03-01T09:56:00Z",
+	}
+	k := f.client()
+	ic := &fakeIngesterClient{}
+	now := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	requeueAfter, err := scaleDownIngesters(context.TODO(), logger, scaleDownRequest, k, configv1.FeatureGates{}, now, func(*tls.Config) ingesters.Client { return ic })
+	require.NoError(t, err)
+	require.Equal(t, 6*time.Minute, requeueAfter)
+	require.Empty(t, ic.calls)
+	require.Zero(t, k.PatchCallCount())
+}
+
+func TestScaleDownIngesters_KeepsIngesters(t *testing.T) {
+	table := []struct {
+		desc   string
+		mutate func(f *scaleDownFixture)
+	}{
+		{
+			desc:   "utilization within tolerance of target",
+			mutate: func(f *scaleDownFixture) { f.utilization = 75 },
+		},
+		{
+			desc: "already at min replicas",
+			mutate: func(f *scaleDownFixture) {
+				f.sts.Spec.Replicas = ptr.To[int32](2)
+				f.sts.Status.ReadyReplicas = 2
+				f.sts.Status.UpdatedReplicas = 2
+			},
+		},
+		{
+			desc:   "ingesters not ready",
+			mutate: func(f *scaleDownFixture) { f.sts.Status.ReadyReplicas = 3 },
+		},
+		{
+			desc:   "ingester pod without IP",
+			mutate: func(f *scaleDownFixture) { f.pod.Status.PodIP = "" },
+		},
+	}
+	for _, tst := range table {
+		tst := tst
+		t.Run(tst.desc, func(t *testing.T) {
+			t.Parallel()
+
+			f := newScaleDownFixture()
+			tst.mutate(f)
+			k := f.client()
+			ic := &fakeIngesterClient{}
+
+			requeueAfter, err := scaleDownIngesters(context.TODO(), logger, scaleDownRequest, k, configv1.FeatureGates{}, time.Now(), func(*tls.Config) ingesters.Client { return ic })
+			require.NoError(t, err)
+			require.Equal(t, 10*time.Minute, requeueAfter)
+			require.Empty(t, ic.calls)
+			require.Zero(t, k.PatchCallCount())
+		})
+	}
+}
+
+func TestScaleDownIngesters_WhenScaleDownFails_CancelsShutdown(t *testing.T) {
+	f := newScaleDownFixture()
+	k := f.client()
+	k.PatchStub = func(_ context.Context, _ client.Object, _ client.Patch, _ ...client.PatchOption) error {
+		return kverrors.New("conflict")
+	}
+	ic := &fakeIngesterClient{}
+
+	_, err := scaleDownIngesters(context.TODO(), logger, scaleDownRequest, k, configv1.FeatureGates{}, time.Now(), func(*tls.Config) ingesters.Client { return ic })
+	require.Error(t, err)
+	require.Equal(t, []string{
+		"prepare_shutdown 10.0.0.4:3100",
+		"cancel_prepare_shutdown 10.0.0.4:3100",
+	}, ic.calls)
+}
+
+func TestDesiredIngesterReplicas(t *testing.T) {
+	spec := &lokiv1.IngesterAutoscalingSpec{MinReplicas: 2, TargetCPUUtilization: 80}
+	hpa := func(utilization int32) *autoscalingv2.HorizontalPodAutoscaler {
+		return &autoscalingv2.HorizontalPodAutoscaler{
+			Status: autoscalingv2.HorizontalPodAutoscalerStatus{
+				CurrentMetrics: []autoscalingv2.MetricStatus{
+					{
+						Type: autoscalingv2.ResourceMetricSourceType,
+						Resource: &autoscalingv2.ResourceMetricStatus{
+							Name:    corev1.ResourceCPU,
+							Current: autoscalingv2.MetricValueStatus{AverageUtilization: ptr.To(utilization)},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	require.Equal(t, int32(5), desiredIngesterReplicas(hpa(60), spec, 6))
+	require.Equal(t, int32(2), desiredIngesterReplicas(hpa(5), spec, 6))
+	require.Equal(t, int32(6), desiredIngesterReplicas(hpa(90), spec, 6))
+	require.Equal(t, int32(6), desiredIngesterReplicas(&autoscalingv2.HorizontalPodAutoscaler{}, spec, 6))
+}
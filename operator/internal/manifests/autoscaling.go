@@ -0,0 +1,150 @@
+package manifests
+
+import (
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	lokiv1 "github.com/grafana/loki/operator/apis/loki/v1"
+)
+
+const (
+	// queryFrontendQueueLengthMetric is the metric of the query-frontend the queriers are scaled on.
+	queryFrontendQueueLengthMetric = "loki_query_frontend_queue_length"
+	// ingesterShutdownGracePeriodSeconds is the time autoscaled ingesters get to flush their
+	// data when stopped, it matches the flush_op_timeout of the ingesters.
+	ingesterShutdownGracePeriodSeconds int64 = 600
+)
+
+// BuildAutoscalers returns a list of k8s objects scaling the autoscaled components.
+func BuildAutoscalers(opts Options) []client.Object {
+	var res []client.Object
+
+	if QuerierAutoscalingEnabled(opts.Stack) {
+		res = append(res, NewQuerierHorizontalPodAutoscaler(opts))
+	}
+
+	if IngesterAutoscalingEnabled(opts.Stack) {
+		res = append(res, NewIngesterHorizontalPodAutoscaler(opts))
+	}
+
+	return res
+}
+
+// NewQuerierHorizontalPodAutoscaler creates a HorizontalPodAutoscaler scaling the queriers
+// on the length of the query-frontend queue. The metric is an external metric, it needs to be
+// served by an external metrics adapter collecting the metrics of the query-frontend.
+func NewQuerierHorizontalPodAutoscaler(opts Options) *autoscalingv2.HorizontalPodAutoscaler {
+	spec := opts.Stack.Autoscaling.Querier
+
+	return &autoscalingv2.HorizontalPodAutoscaler{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "HorizontalPodAutoscaler",
+			APIVersion: autoscalingv2.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   QuerierName(opts.Name),
+			Labels: ComponentLabels(LabelQuerierComponent, opts.Name),
+		},
+		Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{
+				APIVersion: appsv1.SchemeGroupVersion.String(),
+				Kind:       "Deployment",
+				Name:       QuerierName(opts.Name),
+			},
+			MinReplicas: ptr.To(spec.MinReplicas),
+			MaxReplicas: spec.MaxReplicas,
+			Metrics: []autoscalingv2.MetricSpec{
+				{
+					Type: autoscalingv2.ExternalMetricSourceType,
+					External: &autoscalingv2.ExternalMetricSource{
+						Metric: autoscalingv2.MetricIdentifier{
+							Name: queryFrontendQueueLengthMetric,
+							Selector: &metav1.LabelSelector{
+								MatchLabels: map[string]string{
+									"namespace": opts.Namespace,
+									"service":   serviceNameQueryFrontendHTTP(opts.Name),
+								},
+							},
+						},
+						Target: autoscalingv2.MetricTarget{
+							Type:         autoscalingv2.AverageValueMetricType,
+							AverageValue: resource.NewQuantity(int64(spec.TargetQueueLength), resource.DecimalSI),
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// NewIngesterHorizontalPodAutoscaler creates a HorizontalPodAutoscaler scaling the ingesters
+// up on their CPU utilization. Scaling down is disabled, the operator removes the ingesters
+// one at a time, flushing their data and leaving the ring on shutdown.
+func NewIngesterHorizontalPodAutoscaler(opts Options) *autoscalingv2.HorizontalPodAutoscaler {
+	spec := opts.Stack.Autoscaling.Ingester
+
+	return &autoscalingv2.HorizontalPodAutoscaler{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "HorizontalPodAutoscaler",
+			APIVersion: autoscalingv2.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   IngesterName(opts.Name),
+			Labels: ComponentLabels(LabelIngesterComponent, opts.Name),
+		},
+		Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{
+				APIVersion: appsv1.SchemeGroupVersion.String(),
+				Kind:       "StatefulSet",
+				Name:       IngesterName(opts.Name),
+			},
+			MinReplicas: ptr.To(spec.MinReplicas),
+			MaxReplicas: spec.MaxReplicas,
+			Metrics: []autoscalingv2.MetricSpec{
+				{
+					Type: autoscalingv2.ResourceMetricSourceType,
+					Resource: &autoscalingv2.ResourceMetricSource{
+						Name: corev1.ResourceCPU,
+						Target: autoscalingv2.MetricTarget{
+							Type:               autoscalingv2.UtilizationMetricType,
+							AverageUtilization: ptr.To(spec.TargetCPUUtilization),
+						},
+					},
+				},
+			},
+			Behavior: &autoscalingv2.HorizontalPodAutoscalerBehavior{
+				ScaleDown: &autoscalingv2.HPAScalingRules{
+					SelectPolicy: ptr.To(autoscalingv2.DisabledPolicySelect),
+				},
+			},
+		},
+	}
+}
+
+// QuerierAutoscalingEnabled returns true if the queriers of the stack are autoscaled.
+func QuerierAutoscalingEnabled(s lokiv1.LokiStackSpec) bool {
+	return s.Autoscaling != nil && s.Autoscaling.Querier != nil
+}
+
+// IngesterAutoscalingEnabled returns true if the ingesters of the stack are autoscaled.
+func IngesterAutoscalingEnabled(s lokiv1.LokiStackSpec) bool {
+	return s.Autoscaling != nil && s.Autoscaling.Ingester != nil
+}
+
+// configureAutoscaled marks the workload as autoscaled, its replicas are only set on creation.
+func configureAutoscaled(meta *metav1.ObjectMeta) {
+	if meta.Annotations == nil {
+		meta.Annotations = map[string]string{}
+	}
+	meta.Annotations[AnnotationAutoscaled] = "true"
+}
+
+func isAutoscaled(obj client.Object) bool {
+	_, ok := obj.GetAnnotations()[AnnotationAutoscaled]
+	return ok
+}
@@ -0,0 +1,163 @@
+package manifests
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	lokiv1 "github.com/grafana/loki/operator/apis/loki/v1"
+	"github.com/grafana/loki/operator/internal/manifests/internal/config"
+)
+
+func TestBuildAutoscalers_Disabled(t *testing.T) {
+	opts := Options{
+		Name:      "abcd",
+		Namespace: "efgh",
+		Stack: lokiv1.LokiStackSpec{
+			Autoscaling: &lokiv1.AutoscalingSpec{},
+		},
+	}
+
+	require.Empty(t, BuildAutoscalers(opts))
+}
+
+func TestNewQuerierHorizontalPodAutoscaler(t *testing.T) {
+	opts := Options{
+		Name:      "abcd",
+		Namespace: "efgh",
+		Stack: lokiv1.LokiStackSpec{
+			Autoscaling: &lokiv1.AutoscalingSpec{
+				Querier: &lokiv1.QuerierAutoscalingSpec{
+					MinReplicas:       2,
+					MaxReplicas:       8,
+					TargetQueueLength: 4,
+				},
+			},
+		},
+	}
+
+	objs := BuildAutoscalers(opts)
+	require.Len(t, objs, 1)
+
+	hpa := objs[0].(*autoscalingv2.HorizontalPodAutoscaler)
+	require.Equal(t, "abcd-querier", hpa.Name)
+	require.Equal(t, "Deployment", hpa.Spec.ScaleTargetRef.Kind)
+	require.Equal(t, "abcd-querier", hpa.Spec.ScaleTargetRef.Name)
+	require.Equal(t, int32(2), *hpa.Spec.MinReplicas)
+	require.Equal(t, int32(8), hpa.Spec.MaxReplicas)
+
+	require.Len(t, hpa.Spec.Metrics, 1)
+	metric := hpa.Spec.Metrics[0].External
+	require.Equal(t, "loki_query_frontend_queue_length", metric.Metric.Name)
+	require.Equal(t, map[string]string{
+		"namespace": "efgh",
+		"service":   "abcd-query-frontend-http",
+	}, metric.Metric.Selector.MatchLabels)
+	require.Equal(t, autoscalingv2.AverageValueMetricType, metric.Target.Type)
+	require.True(t, resource.MustParse("4").Equal(*metric.Target.AverageValue))
+}
+
+func TestNewIngesterHorizontalPodAutoscaler_DisablesScaleDown(t *testing.T) {
+	opts := Options{
+		Name:      "abcd",
+		Namespace: "efgh",
+		Stack: lokiv1.LokiStackSpec{
+			Autoscaling: &lokiv1.AutoscalingSpec{
+				Ingester: &lokiv1.IngesterAutoscalingSpec{
+					MinReplicas:          3,
+					MaxReplicas:          6,
+					TargetCPUUtilization: 75,
+				},
+			},
+		},
+	}
+
+	objs := BuildAutoscalers(opts)
+	require.Len(t, objs, 1)
+
+	hpa := objs[0].(*autoscalingv2.HorizontalPodAutoscaler)
+	require.Equal(t, "abcd-ingester", hpa.Name)
+	require.Equal(t, "StatefulSet", hpa.Spec.ScaleTargetRef.Kind)
+	require.Equal(t, "abcd-ingester", hpa.Spec.ScaleTargetRef.Name)
+	require.Equal(t, int32(3), *hpa.Spec.MinReplicas)
+	require.Equal(t, int32(6), hpa.Spec.MaxReplicas)
+	require.Equal(t, int32(75), *hpa.Spec.Metrics[0].Resource.Target.AverageUtilization)
+	require.Nil(t, hpa.Spec.Behavior.ScaleUp)
+	require.Equal(t, autoscalingv2.DisabledPolicySelect, *hpa.Spec.Behavior.ScaleDown.SelectPolicy)
+}
+
+func TestBuildAll_AutoscaledWorkloads(t *testing.T) {
+	opts := Options{
+		Name:      "abcd",
+		Namespace: "efgh",
+		Stack: lokiv1.LokiStackSpec{
+			Size: lokiv1.SizeOneXSmall,
+			Autoscaling: &lokiv1.AutoscalingSpec{
+				Querier: &lokiv1.QuerierAutoscalingSpec{
+					MinReplicas: 3,
+					MaxReplicas: 8,
+				},
+				Ingester: &lokiv1.IngesterAutoscalingSpec{
+					MinReplicas: 4,
+					MaxReplicas: 6,
+				},
+			},
+		},
+		Timeouts: defaultTimeoutConfig,
+	}
+	require.NoError(t, ApplyDefaultSettings(&opts))
+
+	objs, err := BuildAll(opts)
+	require.NoError(t, err)
+
+	var hpas int
+	for _, obj := range objs {
+		switch o := obj.(type) {
+		case *autoscalingv2.HorizontalPodAutoscaler:
+			hpas++
+		case *appsv1.Deployment:
+			if o.Name == QuerierName(opts.Name) {
+				require.Contains(t, o.Annotations, AnnotationAutoscaled)
+				require.Equal(t, int32(3), *o.Spec.Replicas)
+			} else {
+				require.NotContains(t, o.Annotations, AnnotationAutoscaled)
+			}
+		case *appsv1.StatefulSet:
+			if o.Name == IngesterName(opts.Name) {
+				require.Contains(t, o.Annotations, AnnotationAutoscaled)
+				require.Equal(t, int32(4), *o.Spec.Replicas)
+				require.Equal(t, ingesterShutdownGracePeriodSeconds, *o.Spec.Template.Spec.TerminationGracePeriodSeconds)
+			} else {
+				require.NotContains(t, o.Annotations, AnnotationAutoscaled)
+			}
+		}
+	}
+	require.Equal(t, 2, hpas)
+}
+
+func TestLokiConfigMap_IngesterAutoscalingSetsShutdownMarkerPath(t *testing.T) {
+	opts := Options{
+		Name:      "abcd",
+		Namespace: "efgh",
+		Stack: lokiv1.LokiStackSpec{
+			Size: lokiv1.SizeOneXSmall,
+		},
+		Timeouts: defaultTimeoutConfig,
+	}
+	require.NoError(t, ApplyDefaultSettings(&opts))
+
+	cm, _, err := LokiConfigMap(opts)
+	require.NoError(t, err)
+	require.NotContains(t, cm.Data[config.LokiConfigFileName], "shutdown_marker_path")
+
+	opts.Stack.Autoscaling = &lokiv1.AutoscalingSpec{
+		Ingester: &lokiv1.IngesterAutoscalingSpec{MinReplicas: 2, MaxReplicas: 4},
+	}
+
+	cm, _, err = LokiConfigMap(opts)
+	require.NoError(t, err)
+	require.Contains(t, cm.Data[config.LokiConfigFileName], "  shutdown_marker_path: /tmp/loki\n")
+}
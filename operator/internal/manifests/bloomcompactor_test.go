@@ -0,0 +1,76 @@
+package manifests
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	lokiv1 "github.com/grafana/loki/operator/apis/loki/v1"
+)
+
+func TestNewBloomCompactorStatefulSet_HasTemplateConfigHashAnnotation(t *testing.T) {
+	ss := NewBloomCompactorStatefulSet(Options{
+		Name:       "abcd",
+		Namespace:  "efgh",
+		ConfigSHA1: "deadbeef",
+		Stack: lokiv1.LokiStackSpec{
+			StorageClassName: "standard",
+			Template: &lokiv1.LokiTemplateSpec{
+				BloomCompactor: &lokiv1.LokiComponentSpec{
+					Replicas: 1,
+				},
+			},
+		},
+	})
+
+	annotations := ss.Spec.Template.Annotations
+	require.Contains(t, annotations, AnnotationLokiConfigHash)
+	require.Equal(t, annotations[AnnotationLokiConfigHash], "deadbeef")
+}
+
+func TestNewBloomCompactorStatefulSet_SelectorMatchesLabels(t *testing.T) {
+	// You must set the .spec.selector field of a StatefulSet to match the labels of
+	// its .spec.template.metadata.labels. Prior to Kubernetes 1.8, the
+	// .spec.selector field was defaulted when omitted. In 1.8 and later versions,
+	// failing to specify a matching Pod Selector will result in a validation error
+	// during StatefulSet creation.
+	// See https://kubernetes.io/docs/concepts/workloads/controllers/statefulset/#pod-selector
+	ss := NewBloomCompactorStatefulSet(Options{
+		Name:      "abcd",
+		Namespace: "efgh",
+		Stack: lokiv1.LokiStackSpec{
+			StorageClassName: "standard",
+			Template: &lokiv1.LokiTemplateSpec{
+				BloomCompactor: &lokiv1.LokiComponentSpec{
+					Replicas: 1,
+				},
+			},
+		},
+	})
+
+	l := ss.Spec.Template.GetObjectMeta().GetLabels()
+	for key, value := range ss.Spec.Selector.MatchLabels {
+		require.Contains(t, l, key)
+		require.Equal(t, l[key], value)
+	}
+}
+
+func TestBuildBloomCompactor_HasNoPodDisruptionBudget(t *testing.T) {
+	objs, err := BuildBloomCompactor(Options{
+		Name:      "abcd",
+		Namespace: "efgh",
+		Stack: lokiv1.LokiStackSpec{
+			Template: &lokiv1.LokiTemplateSpec{
+				BloomCompactor: &lokiv1.LokiComponentSpec{
+					Replicas: 1,
+				},
+			},
+		},
+	})
+
+	require.NoError(t, err)
+	require.Len(t, objs, 3)
+	require.Equal(t, "abcd-bloom-compactor", objs[0].GetName())
+	require.Equal(t, "abcd-bloom-compactor-grpc", objs[1].GetName())
+	require.Equal(t, "abcd-bloom-compactor-http", objs[2].GetName())
+}
@@ -0,0 +1,283 @@
+package manifests
+
+import (
+	"fmt"
+	"path"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/grafana/loki/operator/internal/manifests/internal/config"
+	"github.com/grafana/loki/operator/internal/manifests/storage"
+)
+
+// BuildBloomGateway returns a list of k8s objects for Loki BloomGateway
+func BuildBloomGateway(opts Options) ([]client.Object, error) {
+	statefulSet := NewBloomGatewayStatefulSet(opts)
+	if opts.Gates.HTTPEncryption {
+		if err := configureBloomGatewayHTTPServicePKI(statefulSet, opts); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := storage.ConfigureStatefulSet(statefulSet, opts.ObjectStorage); err != nil {
+		return nil, err
+	}
+
+	if opts.Gates.GRPCEncryption {
+		if err := configureBloomGatewayGRPCServicePKI(statefulSet, opts); err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.Gates.HTTPEncryption || opts.Gates.GRPCEncryption {
+		caBundleName := signingCABundleName(opts.Name)
+		if err := configureServiceCA(&statefulSet.Spec.Template.Spec, caBundleName); err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.Gates.RestrictedPodSecurityStandard {
+		if err := configurePodSpecForRestrictedStandard(&statefulSet.Spec.Template.Spec); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := configureHashRingEnv(&statefulSet.Spec.Template.Spec, opts); err != nil {
+		return nil, err
+	}
+
+	if err := configureProxyEnv(&statefulSet.Spec.Template.Spec, opts); err != nil {
+		return nil, err
+	}
+
+	if err := configureReplication(&statefulSet.Spec.Template, opts.Stack.Replication, LabelBloomGatewayComponent, opts.Name); err != nil {
+		return nil, err
+	}
+
+	return []client.Object{
+		statefulSet,
+		NewBloomGatewayGRPCService(opts),
+		NewBloomGatewayHTTPService(opts),
+		NewBloomGatewayPodDisruptionBudget(opts),
+	}, nil
+}
+
+// NewBloomGatewayStatefulSet creates a statefulset object for a bloom-gateway
+func NewBloomGatewayStatefulSet(opts Options) *appsv1.StatefulSet {
+	l := ComponentLabels(LabelBloomGatewayComponent, opts.Name)
+	a := commonAnnotations(opts)
+	podSpec := corev1.PodSpec{
+		ServiceAccountName: opts.Name,
+		Affinity:           configureAffinity(LabelBloomGatewayComponent, opts.Name, opts.Gates.DefaultNodeAffinity, opts.Stack.Template.BloomGateway),
+		Volumes: []corev1.Volume{
+			{
+				Name: configVolumeName,
+				VolumeSource: corev1.VolumeSource{
+					ConfigMap: &corev1.ConfigMapVolumeSource{
+						DefaultMode: &defaultConfigMapMode,
+						LocalObjectReference: corev1.LocalObjectReference{
+							Name: lokiConfigMapName(opts.Name),
+						},
+					},
+				},
+			},
+		},
+		Containers: []corev1.Container{
+			{
+				Image: opts.Image,
+				Name:  "loki-bloom-gateway",
+				Resources: corev1.ResourceRequirements{
+					Limits:   opts.ResourceRequirements.BloomGateway.Limits,
+					Requests: opts.ResourceRequirements.BloomGateway.Requests,
+				},
+				Args: []string{
+					"-target=bloom-gateway",
+					fmt.Sprintf("-config.file=%s", path.Join(config.LokiConfigMountDir, config.LokiConfigFileName)),
+					fmt.Sprintf("-runtime-config.file=%s", path.Join(config.LokiConfigMountDir, config.LokiRuntimeConfigFileName)),
+					"-config.expand-env=true",
+				},
+				ReadinessProbe: lokiReadinessProbe(),
+				LivenessProbe:  lokiLivenessProbe(),
+				Ports: []corev1.ContainerPort{
+					{
+						Name:          lokiHTTPPortName,
+						ContainerPort: httpPort,
+						Protocol:      protocolTCP,
+					},
+					{
+						Name:          lokiGRPCPortName,
+						ContainerPort: grpcPort,
+						Protocol:      protocolTCP,
+					},
+				},
+				VolumeMounts: []corev1.VolumeMount{
+					{
+						Name:      configVolumeName,
+						ReadOnly:  false,
+						MountPath: config.LokiConfigMountDir,
+					},
+					{
+						Name:      storageVolumeName,
+						ReadOnly:  false,
+						MountPath: dataDirectory,
+					},
+				},
+				TerminationMessagePath:   "/dev/termination-log",
+				TerminationMessagePolicy: "File",
+				ImagePullPolicy:          "IfNotPresent",
+			},
+		},
+	}
+
+	if opts.Stack.Template != nil && opts.Stack.Template.BloomGateway != nil {
+		podSpec.Tolerations = opts.Stack.Template.BloomGateway.Tolerations
+		podSpec.NodeSelector = opts.Stack.Template.BloomGateway.NodeSelector
+	}
+
+	return &appsv1.StatefulSet{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "StatefulSet",
+			APIVersion: appsv1.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   BloomGatewayName(opts.Name),
+			Labels: l,
+		},
+		Spec: appsv1.StatefulSetSpec{
+			PodManagementPolicy:  appsv1.OrderedReadyPodManagement,
+			RevisionHistoryLimit: ptr.To(defaultRevHistoryLimit),
+			Replicas:             ptr.To(opts.Stack.Template.BloomGateway.Replicas),
+			Selector: &metav1.LabelSelector{
+				MatchLabels: labels.Merge(l, GossipLabels()),
+			},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        fmt.Sprintf("loki-bloom-gateway-%s", opts.Name),
+					Labels:      labels.Merge(l, GossipLabels()),
+					Annotations: a,
+				},
+				Spec: podSpec,
+			},
+			VolumeClaimTemplates: []corev1.PersistentVolumeClaim{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Labels: l,
+						Name:   storageVolumeName,
+					},
+					Spec: corev1.PersistentVolumeClaimSpec{
+						AccessModes: []corev1.PersistentVolumeAccessMode{
+							// TODO: should we verify that this is possible with the given storage class first?
+							corev1.ReadWriteOnce,
+						},
+						Resources: corev1.ResourceRequirements{
+							Requests: map[corev1.ResourceName]resource.Quantity{
+								corev1.ResourceStorage: opts.ResourceRequirements.BloomGateway.PVCSize,
+							},
+						},
+						StorageClassName: ptr.To(opts.Stack.StorageClassName),
+						VolumeMode:       &volumeFileSystemMode,
+					},
+				},
+			},
+		},
+	}
+}
+
+// NewBloomGatewayGRPCService creates a k8s service for the bloom-gateway GRPC endpoint
+func NewBloomGatewayGRPCService(opts Options) *corev1.Service {
+	serviceName := serviceNameBloomGatewayGRPC(opts.Name)
+	labels := ComponentLabels(LabelBloomGatewayComponent, opts.Name)
+
+	return &corev1.Service{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Service",
+			APIVersion: corev1.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   serviceName,
+			Labels: labels,
+		},
+		Spec: corev1.ServiceSpec{
+			ClusterIP: "None",
+			Ports: []corev1.ServicePort{
+				{
+					Name:       lokiGRPCPortName,
+					Port:       grpcPort,
+					Protocol:   protocolTCP,
+					TargetPort: intstr.IntOrString{IntVal: grpcPort},
+				},
+			},
+			Selector: labels,
+		},
+	}
+}
+
+// NewBloomGatewayHTTPService creates a k8s service for the bloom-gateway HTTP endpoint
+func NewBloomGatewayHTTPService(opts Options) *corev1.Service {
+	serviceName := serviceNameBloomGatewayHTTP(opts.Name)
+	labels := ComponentLabels(LabelBloomGatewayComponent, opts.Name)
+
+	return &corev1.Service{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Service",
+			APIVersion: corev1.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   serviceName,
+			Labels: labels,
+		},
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{
+				{
+					Name:       lokiHTTPPortName,
+					Port:       httpPort,
+					Protocol:   protocolTCP,
+					TargetPort: intstr.IntOrString{IntVal: httpPort},
+				},
+			},
+			Selector: labels,
+		},
+	}
+}
+
+// NewBloomGatewayPodDisruptionBudget returns a PodDisruptionBudget for the LokiStack
+// bloom-gateway pods.
+func NewBloomGatewayPodDisruptionBudget(opts Options) *policyv1.PodDisruptionBudget {
+	l := ComponentLabels(LabelBloomGatewayComponent, opts.Name)
+	ma := intstr.FromInt(1)
+	return &policyv1.PodDisruptionBudget{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "PodDisruptionBudget",
+			APIVersion: policyv1.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Labels:    l,
+			Name:      BloomGatewayName(opts.Name),
+			Namespace: opts.Namespace,
+		},
+		Spec: policyv1.PodDisruptionBudgetSpec{
+			Selector: &metav1.LabelSelector{
+				MatchLabels: l,
+			},
+			MinAvailable: &ma,
+		},
+	}
+}
+
+func configureBloomGatewayHTTPServicePKI(statefulSet *appsv1.StatefulSet, opts Options) error {
+	serviceName := serviceNameBloomGatewayHTTP(opts.Name)
+	return configureHTTPServicePKI(&statefulSet.Spec.Template.Spec, serviceName)
+}
+
+func configureBloomGatewayGRPCServicePKI(sts *appsv1.StatefulSet, opts Options) error {
+	serviceName := serviceNameBloomGatewayGRPC(opts.Name)
+	return configureGRPCServicePKI(&sts.Spec.Template.Spec, serviceName)
+}
@@ -0,0 +1,112 @@
+package manifests
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	lokiv1 "github.com/grafana/loki/operator/apis/loki/v1"
+	"github.com/grafana/loki/operator/internal/manifests/internal"
+)
+
+func TestNewBloomGatewayStatefulSet_HasTemplateConfigHashAnnotation(t *testing.T) {
+	ss := NewBloomGatewayStatefulSet(Options{
+		Name:       "abcd",
+		Namespace:  "efgh",
+		ConfigSHA1: "deadbeef",
+		Stack: lokiv1.LokiStackSpec{
+			StorageClassName: "standard",
+			Template: &lokiv1.LokiTemplateSpec{
+				BloomGateway: &lokiv1.LokiComponentSpec{
+					Replicas: 1,
+				},
+			},
+		},
+	})
+
+	annotations := ss.Spec.Template.Annotations
+	require.Contains(t, annotations, AnnotationLokiConfigHash)
+	require.Equal(t, annotations[AnnotationLokiConfigHash], "deadbeef")
+}
+
+func TestNewBloomGatewayStatefulSet_SelectorMatchesLabels(t *testing.T) {
+	// You must set the .spec.selector field of a StatefulSet to match the labels of
+	// its .spec.template.metadata.labels. Prior to Kubernetes 1.8, the
+	// .spec.selector field was defaulted when omitted. In 1.8 and later versions,
+	// failing to specify a matching Pod Selector will result in a validation error
+	// during StatefulSet creation.
+	// See https://kubernetes.io/docs/concepts/workloads/controllers/statefulset/#pod-selector
+	ss := NewBloomGatewayStatefulSet(Options{
+		Name:      "abcd",
+		Namespace: "efgh",
+		Stack: lokiv1.LokiStackSpec{
+			StorageClassName: "standard",
+			Template: &lokiv1.LokiTemplateSpec{
+				BloomGateway: &lokiv1.LokiComponentSpec{
+					Replicas: 1,
+				},
+			},
+		},
+	})
+
+	l := ss.Spec.Template.GetObjectMeta().GetLabels()
+	for key, value := range ss.Spec.Selector.MatchLabels {
+		require.Contains(t, l, key)
+		require.Equal(t, l[key], value)
+	}
+}
+
+func TestBuildBloomGateway_PodDisruptionBudget(t *testing.T) {
+	opts := Options{
+		Name:      "abcd",
+		Namespace: "efgh",
+		Stack: lokiv1.LokiStackSpec{
+			Template: &lokiv1.LokiTemplateSpec{
+				BloomGateway: &lokiv1.LokiComponentSpec{
+					Replicas: 1,
+				},
+			},
+		},
+	}
+	objs, err := BuildBloomGateway(opts)
+
+	require.NoError(t, err)
+	require.Len(t, objs, 4)
+
+	pdb := objs[3].(*policyv1.PodDisruptionBudget)
+	require.NotNil(t, pdb)
+	require.Equal(t, "abcd-bloom-gateway", pdb.Name)
+	require.Equal(t, "efgh", pdb.Namespace)
+	require.NotNil(t, pdb.Spec.MinAvailable.IntVal)
+	require.Equal(t, int32(1), pdb.Spec.MinAvailable.IntVal)
+	require.EqualValues(t, ComponentLabels(LabelBloomGatewayComponent, opts.Name),
+		pdb.Spec.Selector.MatchLabels)
+}
+
+func TestNewBloomGatewayStatefulSet_HasBloomsVolumeClaim(t *testing.T) {
+	ss := NewBloomGatewayStatefulSet(Options{
+		Name:      "abcd",
+		Namespace: "efgh",
+		ResourceRequirements: internal.ComponentResources{
+			BloomGateway: internal.ResourceRequirements{
+				PVCSize: resource.MustParse("50Gi"),
+			},
+		},
+		Stack: lokiv1.LokiStackSpec{
+			StorageClassName: "standard",
+			Template: &lokiv1.LokiTemplateSpec{
+				BloomGateway: &lokiv1.LokiComponentSpec{
+					Replicas: 1,
+				},
+			},
+		},
+	})
+
+	require.Len(t, ss.Spec.VolumeClaimTemplates, 1)
+	pvc := ss.Spec.VolumeClaimTemplates[0]
+	require.Equal(t, storageVolumeName, pvc.Name)
+	require.Equal(t, resource.MustParse("50Gi"), pvc.Spec.Resources.Requests[corev1.ResourceStorage])
+}
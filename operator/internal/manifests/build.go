@@ -62,6 +62,34 @@ func BuildAll(opts Options) ([]client.Object, error) {
 	res = append(res, queryFrontendObjs...)
 	res = append(res, indexGatewayObjs...)
 	res = append(res, BuildLokiGossipRingService(opts.Name))
+	res = append(res, BuildAutoscalers(opts)...)
+
+	if bloomCompactorEnabled(opts.Stack) {
+		bloomCompactorObjs, err := BuildBloomCompactor(opts)
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, bloomCompactorObjs...)
+	}
+
+	if bloomGatewayEnabled(opts.Stack) {
+		bloomGatewayObjs, err := BuildBloomGateway(opts)
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, bloomGatewayObjs...)
+	}
+
+	if patternIngesterEnabled(opts.Stack) {
+		patternIngesterObjs, err := BuildPatternIngester(opts)
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, patternIngesterObjs...)
+	}
 
 	if opts.Stack.Rules != nil && opts.Stack.Rules.Enabled {
 		rulesCMShards, err := RulesConfigMapShards(&opts)
@@ -136,6 +164,27 @@ func ApplyDefaultSettings(opts *Options) error {
 		return kverrors.Wrap(err, "failed to merge strict defaults")
 	}
 
+	// The bloom and pattern ingester components are only deployed when their
+	// template is set, thus they have no replicas in the size defaults.
+	for _, c := range []*lokiv1.LokiComponentSpec{
+		spec.Template.BloomCompactor,
+		spec.Template.BloomGateway,
+		spec.Template.PatternIngester,
+	} {
+		if c != nil && c.Replicas == 0 {
+			c.Replicas = 1
+		}
+	}
+
+	// The autoscaled components start with their minimum replicas, the
+	// autoscalers take over their replicas afterwards.
+	if QuerierAutoscalingEnabled(*spec) {
+		spec.Template.Querier.Replicas = spec.Autoscaling.Querier.MinReplicas
+	}
+	if IngesterAutoscalingEnabled(*spec) {
+		spec.Template.Ingester.Replicas = spec.Autoscaling.Ingester.MinReplicas
+	}
+
 	opts.ResourceRequirements = internal.ResourceRequirementsTable[opts.Stack.Size]
 	opts.Stack = *spec
 
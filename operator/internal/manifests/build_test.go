@@ -90,6 +90,28 @@ func TestApplyUserOptions_AlwaysSetCompactorReplicasToOne(t *testing.T) {
 	}
 }
 
+func TestApplyUserOptions_OptInComponentsDefaultToOneReplica(t *testing.T) {
+	opt := Options{
+		Name:      "abcd",
+		Namespace: "efgh",
+		Stack: lokiv1.LokiStackSpec{
+			Size: lokiv1.SizeOneXSmall,
+			Template: &lokiv1.LokiTemplateSpec{
+				BloomCompactor:  &lokiv1.LokiComponentSpec{},
+				BloomGateway:    &lokiv1.LokiComponentSpec{Replicas: 3},
+				PatternIngester: &lokiv1.LokiComponentSpec{},
+			},
+		},
+		Timeouts: defaultTimeoutConfig,
+	}
+	err := ApplyDefaultSettings(&opt)
+
+	require.NoError(t, err)
+	require.Equal(t, int32(1), opt.Stack.Template.BloomCompactor.Replicas)
+	require.Equal(t, int32(3), opt.Stack.Template.BloomGateway.Replicas)
+	require.Equal(t, int32(1), opt.Stack.Template.PatternIngester.Replicas)
+}
+
 func TestApplyTLSSettings_OverrideDefaults(t *testing.T) {
 	type tt struct {
 		desc     string
@@ -937,6 +959,63 @@ func TestBuildAll_WithFeatureGates_LokiStackAlerts(t *testing.T) {
 	}
 }
 
+func TestBuildAll_OptInComponents(t *testing.T) {
+	table := []struct {
+		desc     string
+		template *lokiv1.LokiTemplateSpec
+		want     []string
+	}{
+		{
+			desc: "none enabled",
+		},
+		{
+			desc: "bloom components enabled",
+			template: &lokiv1.LokiTemplateSpec{
+				BloomCompactor: &lokiv1.LokiComponentSpec{Replicas: 1},
+				BloomGateway:   &lokiv1.LokiComponentSpec{Replicas: 1},
+			},
+			want: []string{BloomCompactorName("test"), BloomGatewayName("test")},
+		},
+		{
+			desc: "pattern ingester enabled",
+			template: &lokiv1.LokiTemplateSpec{
+				PatternIngester: &lokiv1.LokiComponentSpec{Replicas: 1},
+			},
+			want: []string{PatternIngesterName("test")},
+		},
+	}
+	for _, tst := range table {
+		tst := tst
+		t.Run(tst.desc, func(t *testing.T) {
+			t.Parallel()
+			opts := Options{
+				Name:      "test",
+				Namespace: "test",
+				Stack: lokiv1.LokiStackSpec{
+					Size:     lokiv1.SizeOneXSmall,
+					Template: tst.template,
+				},
+				Timeouts: defaultTimeoutConfig,
+			}
+			err := ApplyDefaultSettings(&opts)
+			require.NoError(t, err)
+			objects, buildErr := BuildAll(opts)
+			require.NoError(t, buildErr)
+
+			var got []string
+			for _, obj := range objects {
+				switch obj.GetName() {
+				case BloomCompactorName("test"), BloomGatewayName("test"), PatternIngesterName("test"):
+					if _, ok := obj.(*appsv1.StatefulSet); ok {
+						got = append(got, obj.GetName())
+					}
+				}
+			}
+			require.Equal(t, tst.want, got)
+		})
+	}
+}
+
 func serviceMonitorCount(objects []client.Object) int {
 	monitors := 0
 	for _, obj := range objects {
@@ -151,11 +151,13 @@ func ConfigOptions(opt Options) config.Options {
 			},
 			ServerNames: config.TLSServerNames{
 				GRPC: config.GRPCServerNames{
-					Compactor:     fqdn(serviceNameCompactorGRPC(opt.Name), opt.Namespace),
-					IndexGateway:  fqdn(serviceNameIndexGatewayGRPC(opt.Name), opt.Namespace),
-					Ingester:      fqdn(serviceNameIngesterGRPC(opt.Name), opt.Namespace),
-					QueryFrontend: fqdn(serviceNameQueryFrontendGRPC(opt.Name), opt.Namespace),
-					Ruler:         fqdn(serviceNameRulerGRPC(opt.Name), opt.Namespace),
+					BloomGateway:    fqdn(serviceNameBloomGatewayGRPC(opt.Name), opt.Namespace),
+					Compactor:       fqdn(serviceNameCompactorGRPC(opt.Name), opt.Namespace),
+					IndexGateway:    fqdn(serviceNameIndexGatewayGRPC(opt.Name), opt.Namespace),
+					Ingester:        fqdn(serviceNameIngesterGRPC(opt.Name), opt.Namespace),
+					PatternIngester: fqdn(serviceNamePatternIngesterGRPC(opt.Name), opt.Namespace),
+					QueryFrontend:   fqdn(serviceNameQueryFrontendGRPC(opt.Name), opt.Namespace),
+					Ruler:           fqdn(serviceNameRulerGRPC(opt.Name), opt.Namespace),
 				},
 				HTTP: config.HTTPServerNames{
 					Querier: fqdn(serviceNameQuerierHTTP(opt.Name), opt.Namespace),
@@ -182,6 +184,16 @@ func ConfigOptions(opt Options) config.Options {
 			FQDN: fqdn(NewIndexGatewayGRPCService(opt).GetName(), opt.Namespace),
 			Port: grpcPort,
 		},
+		BloomCompactor: config.BloomCompactor{
+			Enabled: bloomCompactorEnabled(opt.Stack),
+		},
+		BloomGateway: config.BloomGateway{
+			Enabled:   bloomGatewayEnabled(opt.Stack),
+			Addresses: bloomGatewayAddresses(opt.Name, opt.Namespace),
+		},
+		PatternIngester: config.PatternIngester{
+			Enabled: patternIngesterEnabled(opt.Stack),
+		},
 		StorageDirectory: dataDirectory,
 		MaxConcurrent: config.MaxConcurrent{
 			AvailableQuerierCPUCores: int32(opt.ResourceRequirements.Querier.Requests.Cpu().Value()),
@@ -308,6 +320,12 @@ func gossipRingConfig(stackName, stackNs string, spec *lokiv1.HashRingSpec, repl
 	}
 }
 
+// bloomGatewayAddresses returns the DNS service discovery address of the bloom gateway
+// instances, resolving the SRV records of the headless GRPC service.
+func bloomGatewayAddresses(stackName, stackNs string) string {
+	return fmt.Sprintf("dnssrvnoa+_%s._tcp.%s", lokiGRPCPortName, fqdn(serviceNameBloomGatewayGRPC(stackName), stackNs))
+}
+
 func remoteWriteConfig(s *lokiv1.RemoteWriteSpec, rs *RulerSecret) *config.RemoteWriteConfig {
 	if s == nil || rs == nil {
 		return nil
@@ -371,6 +389,12 @@ var deleteWorkerCountMap = map[lokiv1.LokiStackSizeType]uint{
 	lokiv1.SizeOneXMedium:     150,
 }
 
+// RetentionEnabled returns true if the compactor of the stack applies retention, which
+// is required for the compactor to serve the log deletion API.
+func RetentionEnabled(ls *lokiv1.LokiStackSpec) bool {
+	return retentionConfig(ls).Enabled
+}
+
 func retentionConfig(ls *lokiv1.LokiStackSpec) config.RetentionOptions {
 	if ls.Limits == nil {
 		return config.RetentionOptions{}
@@ -75,7 +75,7 @@ func BuildGateway(opts Options) ([]client.Object, error) {
 		serviceName := serviceNameGatewayHTTP(opts.Name)
 		serverCAName := gatewaySigningCABundleName(GatewayName(opts.Name))
 		upstreamCAName := signingCABundleName(opts.Name)
-		upstreamClientName := gatewayClientSecretName(opts.Name)
+		upstreamClientName := GatewayClientSecretName(opts.Name)
 		if err := configureGatewayServerPKI(&dpl.Spec.Template.Spec, opts.Namespace, serviceName, serverCAName, upstreamCAName, upstreamClientName, minTLSVersion, ciphers); err != nil {
 			return nil, err
 		}
@@ -62,6 +62,12 @@ func BuildIngester(opts Options) ([]client.Object, error) {
 		return nil, err
 	}
 
+	if IngesterAutoscalingEnabled(opts.Stack) {
+		configureAutoscaled(&statefulSet.ObjectMeta)
+		// Ingesters removed by the operator flush their data on shutdown.
+		statefulSet.Spec.Template.Spec.TerminationGracePeriodSeconds = ptr.To(ingesterShutdownGracePeriodSeconds)
+	}
+
 	return []client.Object{
 		statefulSet,
 		NewIngesterGRPCService(opts),
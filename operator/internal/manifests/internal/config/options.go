@@ -24,6 +24,9 @@ type Options struct {
 	GossipRing            GossipRing
 	Querier               Address
 	IndexGateway          Address
+	BloomCompactor        BloomCompactor
+	BloomGateway          BloomGateway
+	PatternIngester       PatternIngester
 	Ruler                 Ruler
 	StorageDirectory      string
 	MaxConcurrent         MaxConcurrent
@@ -74,6 +77,23 @@ type GossipRing struct {
 	EnableInstanceAvailabilityZone bool
 }
 
+// BloomCompactor configures the bloom compactor.
+type BloomCompactor struct {
+	Enabled bool
+}
+
+// BloomGateway configures the bloom gateway and its clients.
+type BloomGateway struct {
+	Enabled bool
+	// Addresses of the bloom gateway instances in DNS service discovery format.
+	Addresses string
+}
+
+// PatternIngester configures the pattern ingester.
+type PatternIngester struct {
+	Enabled bool
+}
+
 // HTTPTimeoutConfig defines the HTTP server config options.
 type HTTPTimeoutConfig struct {
 	IdleTimeout  time.Duration
@@ -264,11 +284,13 @@ type TLSServerNames struct {
 }
 
 type GRPCServerNames struct {
-	Compactor     string
-	IndexGateway  string
-	Ingester      string
-	QueryFrontend string
-	Ruler         string
+	BloomGateway    string
+	Compactor       string
+	IndexGateway    string
+	Ingester        string
+	PatternIngester string
+	QueryFrontend   string
+	Ruler           string
 }
 
 type HTTPServerNames struct {
@@ -9,16 +9,19 @@ import (
 
 // ComponentResources is a map of component->requests/limits
 type ComponentResources struct {
-	IndexGateway ResourceRequirements
-	Ingester     ResourceRequirements
-	Compactor    ResourceRequirements
-	Ruler        ResourceRequirements
-	WALStorage   ResourceRequirements
-	// these two don't need a PVCSize
-	Querier       corev1.ResourceRequirements
-	Distributor   corev1.ResourceRequirements
-	QueryFrontend corev1.ResourceRequirements
-	Gateway       corev1.ResourceRequirements
+	IndexGateway   ResourceRequirements
+	Ingester       ResourceRequirements
+	Compactor      ResourceRequirements
+	Ruler          ResourceRequirements
+	WALStorage     ResourceRequirements
+	BloomCompactor ResourceRequirements
+	BloomGateway   ResourceRequirements
+	// these don't need a PVCSize
+	Querier         corev1.ResourceRequirements
+	Distributor     corev1.ResourceRequirements
+	QueryFrontend   corev1.ResourceRequirements
+	Gateway         corev1.ResourceRequirements
+	PatternIngester corev1.ResourceRequirements
 }
 
 // ResourceRequirements sets CPU, Memory, and PVC requirements for a component
@@ -44,6 +47,12 @@ var ResourceRequirementsTable = map[lokiv1.LokiStackSizeType]ComponentResources{
 		IndexGateway: ResourceRequirements{
 			PVCSize: resource.MustParse("10Gi"),
 		},
+		BloomCompactor: ResourceRequirements{
+			PVCSize: resource.MustParse("10Gi"),
+		},
+		BloomGateway: ResourceRequirements{
+			PVCSize: resource.MustParse("10Gi"),
+		},
 		WALStorage: ResourceRequirements{
 			PVCSize: resource.MustParse("10Gi"),
 		},
@@ -102,6 +111,26 @@ var ResourceRequirementsTable = map[lokiv1.LokiStackSizeType]ComponentResources{
 				corev1.ResourceMemory: resource.MustParse("1Gi"),
 			},
 		},
+		BloomCompactor: ResourceRequirements{
+			PVCSize: resource.MustParse("50Gi"),
+			Requests: map[corev1.ResourceName]resource.Quantity{
+				corev1.ResourceCPU:    resource.MustParse("1"),
+				corev1.ResourceMemory: resource.MustParse("2Gi"),
+			},
+		},
+		BloomGateway: ResourceRequirements{
+			PVCSize: resource.MustParse("50Gi"),
+			Requests: map[corev1.ResourceName]resource.Quantity{
+				corev1.ResourceCPU:    resource.MustParse("500m"),
+				corev1.ResourceMemory: resource.MustParse("1Gi"),
+			},
+		},
+		PatternIngester: corev1.ResourceRequirements{
+			Requests: map[corev1.ResourceName]resource.Quantity{
+				corev1.ResourceCPU:    resource.MustParse("500m"),
+				corev1.ResourceMemory: resource.MustParse("1Gi"),
+			},
+		},
 		WALStorage: ResourceRequirements{
 			PVCSize: resource.MustParse("150Gi"),
 		},
@@ -160,6 +189,26 @@ var ResourceRequirementsTable = map[lokiv1.LokiStackSizeType]ComponentResources{
 				corev1.ResourceMemory: resource.MustParse("2Gi"),
 			},
 		},
+		BloomCompactor: ResourceRequirements{
+			PVCSize: resource.MustParse("150Gi"),
+			Requests: map[corev1.ResourceName]resource.Quantity{
+				corev1.ResourceCPU:    resource.MustParse("2"),
+				corev1.ResourceMemory: resource.MustParse("4Gi"),
+			},
+		},
+		BloomGateway: ResourceRequirements{
+			PVCSize: resource.MustParse("150Gi"),
+			Requests: map[corev1.ResourceName]resource.Quantity{
+				corev1.ResourceCPU:    resource.MustParse("1"),
+				corev1.ResourceMemory: resource.MustParse("4Gi"),
+			},
+		},
+		PatternIngester: corev1.ResourceRequirements{
+			Requests: map[corev1.ResourceName]resource.Quantity{
+				corev1.ResourceCPU:    resource.MustParse("1"),
+				corev1.ResourceMemory: resource.MustParse("2Gi"),
+			},
+		},
 		WALStorage: ResourceRequirements{
 			PVCSize: resource.MustParse("150Gi"),
 		},
@@ -218,6 +267,26 @@ var ResourceRequirementsTable = map[lokiv1.LokiStackSizeType]ComponentResources{
 				corev1.ResourceMemory: resource.MustParse("2Gi"),
 			},
 		},
+		BloomCompactor: ResourceRequirements{
+			PVCSize: resource.MustParse("150Gi"),
+			Requests: map[corev1.ResourceName]resource.Quantity{
+				corev1.ResourceCPU:    resource.MustParse("2"),
+				corev1.ResourceMemory: resource.MustParse("8Gi"),
+			},
+		},
+		BloomGateway: ResourceRequirements{
+			PVCSize: resource.MustParse("150Gi"),
+			Requests: map[corev1.ResourceName]resource.Quantity{
+				corev1.ResourceCPU:    resource.MustParse("2"),
+				corev1.ResourceMemory: resource.MustParse("8Gi"),
+			},
+		},
+		PatternIngester: corev1.ResourceRequirements{
+			Requests: map[corev1.ResourceName]resource.Quantity{
+				corev1.ResourceCPU:    resource.MustParse("2"),
+				corev1.ResourceMemory: resource.MustParse("4Gi"),
+			},
+		},
 		WALStorage: ResourceRequirements{
 			PVCSize: resource.MustParse("150Gi"),
 		},
@@ -9,6 +9,7 @@ import (
 	cloudcredentialv1 "github.com/openshift/cloud-credential-operator/pkg/apis/cloudcredential/v1"
 	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
 	policyv1 "k8s.io/api/policy/v1"
@@ -35,6 +36,7 @@ import (
 //   - Route
 //   - PrometheusRule
 //   - PodDisruptionBudget
+//   - HorizontalPodAutoscaler
 func MutateFuncFor(existing, desired client.Object, depAnnotations map[string]string) controllerutil.MutateFn {
 	return func() error {
 		existingAnnotations := existing.GetAnnotations()
@@ -138,6 +140,11 @@ func MutateFuncFor(existing, desired client.Object, depAnnotations map[string]st
 			wantPdb := desired.(*policyv1.PodDisruptionBudget)
 			mutatePodDisruptionBudget(pdb, wantPdb)
 
+		case *autoscalingv2.HorizontalPodAutoscaler:
+			hpa := existing.(*autoscalingv2.HorizontalPodAutoscaler)
+			wantHpa := desired.(*autoscalingv2.HorizontalPodAutoscaler)
+			mutateHorizontalPodAutoscaler(hpa, wantHpa)
+
 		default:
 			t := reflect.TypeOf(existing).String()
 			return kverrors.New("missing mutate implementation for resource type", "type", t)
@@ -240,7 +247,10 @@ func mutateDeployment(existing, desired *appsv1.Deployment) {
 	if existing.CreationTimestamp.IsZero() {
 		existing.Spec.Selector = desired.Spec.Selector
 	}
-	existing.Spec.Replicas = desired.Spec.Replicas
+	// The replicas of autoscaled deployments are managed by the autoscaler once created.
+	if existing.CreationTimestamp.IsZero() || !isAutoscaled(desired) {
+		existing.Spec.Replicas = desired.Spec.Replicas
+	}
 	existing.Spec.Strategy = desired.Spec.Strategy
 	mutatePodTemplate(&existing.Spec.Template, &desired.Spec.Template)
 }
@@ -251,7 +261,10 @@ func mutateStatefulSet(existing, desired *appsv1.StatefulSet) {
 	if existing.CreationTimestamp.IsZero() {
 		existing.Spec.Selector = desired.Spec.Selector
 	}
-	existing.Spec.Replicas = desired.Spec.Replicas
+	// The replicas of autoscaled statefulsets are managed by the autoscaler once created.
+	if existing.CreationTimestamp.IsZero() || !isAutoscaled(desired) {
+		existing.Spec.Replicas = desired.Spec.Replicas
+	}
 	mutatePodTemplate(&existing.Spec.Template, &desired.Spec.Template)
 }
 
@@ -261,6 +274,12 @@ func mutatePodDisruptionBudget(existing, desired *policyv1.PodDisruptionBudget)
 	existing.Spec = desired.Spec
 }
 
+func mutateHorizontalPodAutoscaler(existing, desired *autoscalingv2.HorizontalPodAutoscaler) {
+	existing.Annotations = desired.Annotations
+	existing.Labels = desired.Labels
+	existing.Spec = desired.Spec
+}
+
 func mutatePodTemplate(existing, desired *corev1.PodTemplateSpec) {
 	existing.Annotations = desired.Annotations
 	existing.Labels = desired.Labels
@@ -7,6 +7,7 @@ import (
 	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
 	"github.com/stretchr/testify/require"
 	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
 	policyv1 "k8s.io/api/policy/v1"
@@ -1167,3 +1168,100 @@ func TestGetMutateFunc_MutatePodDisruptionBudget(t *testing.T) {
 	require.Exactly(t, got.Labels, want.Labels)
 	require.Exactly(t, got.Spec, want.Spec)
 }
+
+func TestGetMutateFunc_MutateHorizontalPodAutoscaler(t *testing.T) {
+	got := &autoscalingv2.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels: map[string]string{
+				"test":  "test",
+				"other": "label",
+			},
+		},
+		Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+			MinReplicas: ptr.To[int32](1),
+			MaxReplicas: 2,
+		},
+	}
+
+	want := &autoscalingv2.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels: map[string]string{
+				"other": "label",
+				"new":   "label",
+			},
+		},
+		Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{
+				APIVersion: "apps/v1",
+				Kind:       "Deployment",
+				Name:       "test",
+			},
+			MinReplicas: ptr.To[int32](2),
+			MaxReplicas: 5,
+		},
+	}
+
+	f := MutateFuncFor(got, want, nil)
+	err := f()
+
+	require.NoError(t, err)
+	require.Exactly(t, got.Labels, want.Labels)
+	require.Exactly(t, got.Spec, want.Spec)
+}
+
+func TestMutateFuncFor_KeepsReplicasOfAutoscaledWorkloads(t *testing.T) {
+	existingDpl := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			CreationTimestamp: metav1.Now(),
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: ptr.To[int32](5),
+		},
+	}
+	desiredDpl := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{AnnotationAutoscaled: "true"},
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: ptr.To[int32](2),
+		},
+	}
+
+	existingSts := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			CreationTimestamp: metav1.Now(),
+		},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas: ptr.To[int32](4),
+		},
+	}
+	desiredSts := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{AnnotationAutoscaled: "true"},
+		},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas: ptr.To[int32](3),
+		},
+	}
+
+	require.NoError(t, MutateFuncFor(existingDpl, desiredDpl, nil)())
+	require.NoError(t, MutateFuncFor(existingSts, desiredSts, nil)())
+
+	require.Equal(t, int32(5), *existingDpl.Spec.Replicas)
+	require.Equal(t, int32(4), *existingSts.Spec.Replicas)
+}
+
+func TestMutateFuncFor_SetsReplicasOfNewAutoscaledWorkloads(t *testing.T) {
+	existing := &appsv1.StatefulSet{}
+	desired := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{AnnotationAutoscaled: "true"},
+		},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas: ptr.To[int32](3),
+		},
+	}
+
+	require.NoError(t, MutateFuncFor(existing, desired, nil)())
+	require.Equal(t, int32(3), *existing.Spec.Replicas)
+}
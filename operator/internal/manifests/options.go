@@ -132,6 +132,18 @@ func (o Options) TLSCipherSuites() string {
 	return strings.Join(o.TLSProfile.Ciphers, ",")
 }
 
+func bloomCompactorEnabled(s lokiv1.LokiStackSpec) bool {
+	return s.Template != nil && s.Template.BloomCompactor != nil
+}
+
+func bloomGatewayEnabled(s lokiv1.LokiStackSpec) bool {
+	return s.Template != nil && s.Template.BloomGateway != nil
+}
+
+func patternIngesterEnabled(s lokiv1.LokiStackSpec) bool {
+	return s.Template != nil && s.Template.PatternIngester != nil
+}
+
 // NewTimeoutConfig creates a TimeoutConfig from the QueryTimeout values in the spec's limits.
 func NewTimeoutConfig(s *lokiv1.LimitsSpec) (TimeoutConfig, error) {
 	if s == nil {
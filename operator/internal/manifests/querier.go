@@ -62,6 +62,10 @@ func BuildQuerier(opts Options) ([]client.Object, error) {
 		return nil, err
 	}
 
+	if QuerierAutoscalingEnabled(opts.Stack) {
+		configureAutoscaled(&deployment.ObjectMeta)
+	}
+
 	return []client.Object{
 		deployment,
 		NewQuerierGRPCService(opts),
@@ -9,7 +9,7 @@ import (
 
 // BuildServiceMonitors builds the service monitors
 func BuildServiceMonitors(opts Options) []client.Object {
-	return []client.Object{
+	objs := []client.Object{
 		NewDistributorServiceMonitor(opts),
 		NewIngesterServiceMonitor(opts),
 		NewQuerierServiceMonitor(opts),
@@ -19,6 +19,20 @@ func BuildServiceMonitors(opts Options) []client.Object {
 		NewRulerServiceMonitor(opts),
 		NewGatewayServiceMonitor(opts),
 	}
+
+	if bloomCompactorEnabled(opts.Stack) {
+		objs = append(objs, NewBloomCompactorServiceMonitor(opts))
+	}
+
+	if bloomGatewayEnabled(opts.Stack) {
+		objs = append(objs, NewBloomGatewayServiceMonitor(opts))
+	}
+
+	if patternIngesterEnabled(opts.Stack) {
+		objs = append(objs, NewPatternIngesterServiceMonitor(opts))
+	}
+
+	return objs
 }
 
 // NewDistributorServiceMonitor creates a k8s service monitor for the distributor component
@@ -98,6 +112,39 @@ func NewRulerServiceMonitor(opts Options) *monitoringv1.ServiceMonitor {
 	return newServiceMonitor(opts.Namespace, serviceMonitorName, l, lokiEndpoint)
 }
 
+// NewBloomCompactorServiceMonitor creates a k8s service monitor for the bloom-compactor component
+func NewBloomCompactorServiceMonitor(opts Options) *monitoringv1.ServiceMonitor {
+	l := ComponentLabels(LabelBloomCompactorComponent, opts.Name)
+
+	serviceMonitorName := serviceMonitorName(BloomCompactorName(opts.Name))
+	serviceName := serviceNameBloomCompactorHTTP(opts.Name)
+	lokiEndpoint := lokiServiceMonitorEndpoint(opts.Name, lokiHTTPPortName, serviceName, opts.Namespace, opts.Gates.ServiceMonitorTLSEndpoints)
+
+	return newServiceMonitor(opts.Namespace, serviceMonitorName, l, lokiEndpoint)
+}
+
+// NewBloomGatewayServiceMonitor creates a k8s service monitor for the bloom-gateway component
+func NewBloomGatewayServiceMonitor(opts Options) *monitoringv1.ServiceMonitor {
+	l := ComponentLabels(LabelBloomGatewayComponent, opts.Name)
+
+	serviceMonitorName := serviceMonitorName(BloomGatewayName(opts.Name))
+	serviceName := serviceNameBloomGatewayHTTP(opts.Name)
+	lokiEndpoint := lokiServiceMonitorEndpoint(opts.Name, lokiHTTPPortName, serviceName, opts.Namespace, opts.Gates.ServiceMonitorTLSEndpoints)
+
+	return newServiceMonitor(opts.Namespace, serviceMonitorName, l, lokiEndpoint)
+}
+
+// NewPatternIngesterServiceMonitor creates a k8s service monitor for the pattern-ingester component
+func NewPatternIngesterServiceMonitor(opts Options) *monitoringv1.ServiceMonitor {
+	l := ComponentLabels(LabelPatternIngesterComponent, opts.Name)
+
+	serviceMonitorName := serviceMonitorName(PatternIngesterName(opts.Name))
+	serviceName := serviceNamePatternIngesterHTTP(opts.Name)
+	lokiEndpoint := lokiServiceMonitorEndpoint(opts.Name, lokiHTTPPortName, serviceName, opts.Namespace, opts.Gates.ServiceMonitorTLSEndpoints)
+
+	return newServiceMonitor(opts.Namespace, serviceMonitorName, l, lokiEndpoint)
+}
+
 // NewGatewayServiceMonitor creates a k8s service monitor for the lokistack-gateway component
 func NewGatewayServiceMonitor(opts Options) *monitoringv1.ServiceMonitor {
 	l := ComponentLabels(LabelGatewayComponent, opts.Name)
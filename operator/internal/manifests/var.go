@@ -80,6 +80,10 @@ const (
 	AnnotationLokiObjectStoreHash string = "loki.grafana.com/object-store-hash"
 	// AnnotationLokiTokenCCOAuthHash stores the SHA1 hash of the secret generated by the Cloud Credential Operator.
 	AnnotationLokiTokenCCOAuthHash string = "loki.grafana.com/token-cco-auth-hash"
+	// AnnotationAutoscaled marks the workloads having their replicas managed by an autoscaler.
+	AnnotationAutoscaled string = "loki.grafana.com/autoscaled"
+	// AnnotationIngesterScaledDownAt stores the point in time the last ingester was removed by the operator.
+	AnnotationIngesterScaledDownAt string = "loki.grafana.com/ingester-scaled-down-at"
 
 	// LabelCompactorComponent is the label value for the compactor component
 	LabelCompactorComponent string = "compactor"
@@ -97,6 +101,12 @@ const (
 	LabelRulerComponent string = "ruler"
 	// LabelGatewayComponent is the label value for the lokiStack-gateway component
 	LabelGatewayComponent string = "lokistack-gateway"
+	// LabelBloomCompactorComponent is the label value for the bloom-compactor component
+	LabelBloomCompactorComponent string = "bloom-compactor"
+	// LabelBloomGatewayComponent is the label value for the bloom-gateway component
+	LabelBloomGatewayComponent string = "bloom-gateway"
+	// LabelPatternIngesterComponent is the label value for the pattern-ingester component
+	LabelPatternIngesterComponent string = "pattern-ingester"
 
 	// httpTLSDir is the path that is mounted from the secret for TLS
 	httpTLSDir = "/var/run/tls/http"
@@ -231,6 +241,21 @@ func RulerName(stackName string) string {
 	return fmt.Sprintf("%s-ruler", stackName)
 }
 
+// BloomCompactorName is the name of the bloom-compactor statefulset
+func BloomCompactorName(stackName string) string {
+	return fmt.Sprintf("%s-bloom-compactor", stackName)
+}
+
+// BloomGatewayName is the name of the bloom-gateway statefulset
+func BloomGatewayName(stackName string) string {
+	return fmt.Sprintf("%s-bloom-gateway", stackName)
+}
+
+// PatternIngesterName is the name of the pattern-ingester statefulset
+func PatternIngesterName(stackName string) string {
+	return fmt.Sprintf("%s-pattern-ingester", stackName)
+}
+
 // RulesConfigMapName is the name of the alerting/recording rules configmap
 func RulesConfigMapName(stackName string) string {
 	return fmt.Sprintf("%s-rules", stackName)
@@ -315,7 +340,9 @@ func TenantCAPath(tennantName, key string) string {
 	return path.Join(tenantCAsDir, tennantName, key)
 }
 
-func gatewayClientSecretName(stackName string) string {
+// GatewayClientSecretName returns the name of the secret holding the client certificate
+// used to call the HTTP endpoints of the Loki components.
+func GatewayClientSecretName(stackName string) string {
 	return fmt.Sprintf("%s-gateway-client-http", stackName)
 }
 
@@ -335,6 +362,12 @@ func serviceNameIngesterHTTP(stackName string) string {
 	return fmt.Sprintf("%s-ingester-http", stackName)
 }
 
+// IngesterHTTPServiceFQDN returns the fully qualified name of the ingester HTTP service,
+// which the serving certificates of the ingester pods are issued for.
+func IngesterHTTPServiceFQDN(stackName, namespace string) string {
+	return fqdn(serviceNameIngesterHTTP(stackName), namespace)
+}
+
 func serviceNameDistributorGRPC(stackName string) string {
 	return fmt.Sprintf("%s-distributor-grpc", stackName)
 }
@@ -351,6 +384,16 @@ func serviceNameCompactorHTTP(stackName string) string {
 	return fmt.Sprintf("%s-compactor-http", stackName)
 }
 
+// CompactorHTTPServiceFQDN returns the fully qualified name of the compactor HTTP service.
+func CompactorHTTPServiceFQDN(stackName, namespace string) string {
+	return fqdn(serviceNameCompactorHTTP(stackName), namespace)
+}
+
+// CompactorHTTPServiceAddress returns the host and port of the compactor HTTP service.
+func CompactorHTTPServiceAddress(stackName, namespace string) string {
+	return fmt.Sprintf("%s:%d", CompactorHTTPServiceFQDN(stackName, namespace), httpPort)
+}
+
 func serviceNameQueryFrontendGRPC(stackName string) string {
 	return fmt.Sprintf("%s-query-frontend-grpc", stackName)
 }
@@ -375,6 +418,30 @@ func serviceNameRulerGRPC(stackName string) string {
 	return fmt.Sprintf("%s-ruler-grpc", stackName)
 }
 
+func serviceNameBloomCompactorHTTP(stackName string) string {
+	return fmt.Sprintf("%s-bloom-compactor-http", stackName)
+}
+
+func serviceNameBloomCompactorGRPC(stackName string) string {
+	return fmt.Sprintf("%s-bloom-compactor-grpc", stackName)
+}
+
+func serviceNameBloomGatewayHTTP(stackName string) string {
+	return fmt.Sprintf("%s-bloom-gateway-http", stackName)
+}
+
+func serviceNameBloomGatewayGRPC(stackName string) string {
+	return fmt.Sprintf("%s-bloom-gateway-grpc", stackName)
+}
+
+func serviceNamePatternIngesterHTTP(stackName string) string {
+	return fmt.Sprintf("%s-pattern-ingester-http", stackName)
+}
+
+func serviceNamePatternIngesterGRPC(stackName string) string {
+	return fmt.Sprintf("%s-pattern-ingester-grpc", stackName)
+}
+
 func serviceNameGatewayHTTP(stackName string) string {
 	return fmt.Sprintf("%s-gateway-http", stackName)
 }
@@ -73,47 +73,59 @@ func TestGenerateComponentStatus(t *testing.T) {
 		{
 			desc: "no pods",
 			componentPods: map[string]*corev1.PodList{
-				manifests.LabelCompactorComponent:     {},
-				manifests.LabelDistributorComponent:   {},
-				manifests.LabelIngesterComponent:      {},
-				manifests.LabelQuerierComponent:       {},
-				manifests.LabelQueryFrontendComponent: {},
-				manifests.LabelIndexGatewayComponent:  {},
-				manifests.LabelRulerComponent:         {},
-				manifests.LabelGatewayComponent:       {},
+				manifests.LabelCompactorComponent:       {},
+				manifests.LabelDistributorComponent:     {},
+				manifests.LabelIngesterComponent:        {},
+				manifests.LabelQuerierComponent:         {},
+				manifests.LabelQueryFrontendComponent:   {},
+				manifests.LabelIndexGatewayComponent:    {},
+				manifests.LabelRulerComponent:           {},
+				manifests.LabelGatewayComponent:         {},
+				manifests.LabelBloomCompactorComponent:  {},
+				manifests.LabelBloomGatewayComponent:    {},
+				manifests.LabelPatternIngesterComponent: {},
 			},
 			wantComponentStatus: &lokiv1.LokiStackComponentStatus{
-				Compactor:     lokiv1.PodStatusMap{},
-				Distributor:   lokiv1.PodStatusMap{},
-				IndexGateway:  lokiv1.PodStatusMap{},
-				Ingester:      lokiv1.PodStatusMap{},
-				Querier:       lokiv1.PodStatusMap{},
-				QueryFrontend: lokiv1.PodStatusMap{},
-				Gateway:       lokiv1.PodStatusMap{},
-				Ruler:         lokiv1.PodStatusMap{},
+				Compactor:       lokiv1.PodStatusMap{},
+				Distributor:     lokiv1.PodStatusMap{},
+				IndexGateway:    lokiv1.PodStatusMap{},
+				Ingester:        lokiv1.PodStatusMap{},
+				Querier:         lokiv1.PodStatusMap{},
+				QueryFrontend:   lokiv1.PodStatusMap{},
+				Gateway:         lokiv1.PodStatusMap{},
+				Ruler:           lokiv1.PodStatusMap{},
+				BloomCompactor:  lokiv1.PodStatusMap{},
+				BloomGateway:    lokiv1.PodStatusMap{},
+				PatternIngester: lokiv1.PodStatusMap{},
 			},
 		},
 		{
 			desc: "all one pod running",
 			componentPods: map[string]*corev1.PodList{
-				manifests.LabelCompactorComponent:     createPodList(manifests.LabelCompactorComponent, false, corev1.PodRunning),
-				manifests.LabelDistributorComponent:   createPodList(manifests.LabelDistributorComponent, false, corev1.PodRunning),
-				manifests.LabelIngesterComponent:      createPodList(manifests.LabelIngesterComponent, false, corev1.PodRunning),
-				manifests.LabelQuerierComponent:       createPodList(manifests.LabelQuerierComponent, false, corev1.PodRunning),
-				manifests.LabelQueryFrontendComponent: createPodList(manifests.LabelQueryFrontendComponent, false, corev1.PodRunning),
-				manifests.LabelIndexGatewayComponent:  createPodList(manifests.LabelIndexGatewayComponent, false, corev1.PodRunning),
-				manifests.LabelRulerComponent:         createPodList(manifests.LabelRulerComponent, false, corev1.PodRunning),
-				manifests.LabelGatewayComponent:       createPodList(manifests.LabelGatewayComponent, false, corev1.PodRunning),
+				manifests.LabelCompactorComponent:       createPodList(manifests.LabelCompactorComponent, false, corev1.PodRunning),
+				manifests.LabelDistributorComponent:     createPodList(manifests.LabelDistributorComponent, false, corev1.PodRunning),
+				manifests.LabelIngesterComponent:        createPodList(manifests.LabelIngesterComponent, false, corev1.PodRunning),
+				manifests.LabelQuerierComponent:         createPodList(manifests.LabelQuerierComponent, false, corev1.PodRunning),
+				manifests.LabelQueryFrontendComponent:   createPodList(manifests.LabelQueryFrontendComponent, false, corev1.PodRunning),
+				manifests.LabelIndexGatewayComponent:    createPodList(manifests.LabelIndexGatewayComponent, false, corev1.PodRunning),
+				manifests.LabelRulerComponent:           createPodList(manifests.LabelRulerComponent, false, corev1.PodRunning),
+				manifests.LabelGatewayComponent:         createPodList(manifests.LabelGatewayComponent, false, corev1.PodRunning),
+				manifests.LabelBloomCompactorComponent:  createPodList(manifests.LabelBloomCompactorComponent, false, corev1.PodRunning),
+				manifests.LabelBloomGatewayComponent:    createPodList(manifests.LabelBloomGatewayComponent, false, corev1.PodRunning),
+				manifests.LabelPatternIngesterComponent: createPodList(manifests.LabelPatternIngesterComponent, false, corev1.PodRunning),
 			},
 			wantComponentStatus: &lokiv1.LokiStackComponentStatus{
-				Compactor:     lokiv1.PodStatusMap{lokiv1.PodRunning: {"compactor-pod-0"}},
-				Distributor:   lokiv1.PodStatusMap{lokiv1.PodRunning: {"distributor-pod-0"}},
-				IndexGateway:  lokiv1.PodStatusMap{lokiv1.PodRunning: {"index-gateway-pod-0"}},
-				Ingester:      lokiv1.PodStatusMap{lokiv1.PodRunning: {"ingester-pod-0"}},
-				Querier:       lokiv1.PodStatusMap{lokiv1.PodRunning: {"querier-pod-0"}},
-				QueryFrontend: lokiv1.PodStatusMap{lokiv1.PodRunning: {"query-frontend-pod-0"}},
-				Gateway:       lokiv1.PodStatusMap{lokiv1.PodRunning: {"lokistack-gateway-pod-0"}},
-				Ruler:         lokiv1.PodStatusMap{lokiv1.PodRunning: {"ruler-pod-0"}},
+				Compactor:       lokiv1.PodStatusMap{lokiv1.PodRunning: {"compactor-pod-0"}},
+				Distributor:     lokiv1.PodStatusMap{lokiv1.PodRunning: {"distributor-pod-0"}},
+				IndexGateway:    lokiv1.PodStatusMap{lokiv1.PodRunning: {"index-gateway-pod-0"}},
+				Ingester:        lokiv1.PodStatusMap{lokiv1.PodRunning: {"ingester-pod-0"}},
+				Querier:         lokiv1.PodStatusMap{lokiv1.PodRunning: {"querier-pod-0"}},
+				QueryFrontend:   lokiv1.PodStatusMap{lokiv1.PodRunning: {"query-frontend-pod-0"}},
+				Gateway:         lokiv1.PodStatusMap{lokiv1.PodRunning: {"lokistack-gateway-pod-0"}},
+				Ruler:           lokiv1.PodStatusMap{lokiv1.PodRunning: {"ruler-pod-0"}},
+				BloomCompactor:  lokiv1.PodStatusMap{lokiv1.PodRunning: {"bloom-compactor-pod-0"}},
+				BloomGateway:    lokiv1.PodStatusMap{lokiv1.PodRunning: {"bloom-gateway-pod-0"}},
+				PatternIngester: lokiv1.PodStatusMap{lokiv1.PodRunning: {"pattern-ingester-pod-0"}},
 			},
 		},
 	}
@@ -137,7 +149,7 @@ func TestGenerateComponentStatus(t *testing.T) {
 			require.Equal(t, tc.wantComponentStatus, componentStatus)
 
 			// one list call for each component
-			require.Equal(t, 8, k.ListCallCount())
+			require.Equal(t, 11, k.ListCallCount())
 		})
 	}
 }
@@ -3,6 +3,7 @@ package validation
 import (
 	"context"
 	"fmt"
+	"regexp"
 	"time"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -78,6 +79,21 @@ func (v *LokiStackValidator) validate(ctx context.Context, obj runtime.Object) (
 		allErrs = append(allErrs, errors...)
 	}
 
+	errors = v.validateBloomSpec(stack.Spec)
+	if len(errors) != 0 {
+		allErrs = append(allErrs, errors...)
+	}
+
+	errors = v.validateOTLPSpec(stack.Spec)
+	if len(errors) != 0 {
+		allErrs = append(allErrs, errors...)
+	}
+
+	errors = v.validateAutoscalingSpec(stack.Spec)
+	if len(errors) != 0 {
+		allErrs = append(allErrs, errors...)
+	}
+
 	if v.ExtendedValidator != nil {
 		allErrs = append(allErrs, v.ExtendedValidator(ctx, stack)...)
 	}
@@ -136,6 +152,162 @@ func (v *LokiStackValidator) validateReplicationSpec(stack lokiv1.LokiStackSpec)
 	return nil
 }
 
+func (v *LokiStackValidator) validateBloomSpec(s lokiv1.LokiStackSpec) field.ErrorList {
+	if s.Template == nil {
+		return nil
+	}
+
+	compactor, gateway := s.Template.BloomCompactor != nil, s.Template.BloomGateway != nil
+	if !compactor && !gateway {
+		return nil
+	}
+
+	if compactor != gateway {
+		path, value := field.NewPath("spec", "template", "bloomCompactor"), s.Template.BloomCompactor
+		if gateway {
+			path, value = field.NewPath("spec", "template", "bloomGateway"), s.Template.BloomGateway
+		}
+
+		return field.ErrorList{
+			field.Invalid(path, value, lokiv1.ErrBloomComponentsNotPaired.Error()),
+		}
+	}
+
+	var latest *lokiv1.ObjectStorageSchema
+	for i, sc := range s.Storage.Schemas {
+		if latest == nil || sc.EffectiveDate > latest.EffectiveDate {
+			latest = &s.Storage.Schemas[i]
+		}
+	}
+
+	if latest != nil && latest.Version != lokiv1.ObjectStorageSchemaV13 {
+		return field.ErrorList{
+			field.Invalid(
+				field.NewPath("spec", "storage", "schemas"),
+				s.Storage.Schemas,
+				lokiv1.ErrBloomRequiresSchemaV13.Error(),
+			),
+		}
+	}
+
+	return nil
+}
+
+func (v *LokiStackValidator) validateAutoscalingSpec(s lokiv1.LokiStackSpec) field.ErrorList {
+	if s.Autoscaling == nil {
+		return nil
+	}
+
+	var allErrs field.ErrorList
+
+	if q := s.Autoscaling.Querier; q != nil && q.MinReplicas > q.MaxReplicas {
+		allErrs = append(allErrs, field.Invalid(
+			field.NewPath("spec", "autoscaling", "querier", "minReplicas"),
+			q.MinReplicas,
+			lokiv1.ErrAutoscalingReplicasInvalid.Error(),
+		))
+	}
+
+	if i := s.Autoscaling.Ingester; i != nil {
+		if i.MinReplicas > i.MaxReplicas {
+			allErrs = append(allErrs, field.Invalid(
+				field.NewPath("spec", "autoscaling", "ingester", "minReplicas"),
+				i.MinReplicas,
+				lokiv1.ErrAutoscalingReplicasInvalid.Error(),
+			))
+		}
+
+		factor := s.ReplicationFactor
+		if s.Replication != nil && s.Replication.Factor > 0 {
+			factor = s.Replication.Factor
+		}
+
+		if i.MinReplicas < factor {
+			allErrs = append(allErrs, field.Invalid(
+				field.NewPath("spec", "autoscaling", "ingester", "minReplicas"),
+				i.MinReplicas,
+				lokiv1.ErrAutoscalingIngesterReplicasTooLow.Error(),
+			))
+		}
+	}
+
+	return allErrs
+}
+
+func (v *LokiStackValidator) validateOTLPSpec(s lokiv1.LokiStackSpec) field.ErrorList {
+	if s.Limits == nil {
+		return nil
+	}
+
+	var allErrs field.ErrorList
+
+	if s.Limits.Global != nil && s.Limits.Global.OTLP != nil {
+		allErrs = append(allErrs, validateOTLP(field.NewPath("spec", "limits", "global", "otlp"), s.Limits.Global.OTLP)...)
+	}
+
+	for tenant, limits := range s.Limits.Tenants {
+		if limits.OTLP == nil {
+			continue
+		}
+
+		allErrs = append(allErrs, validateOTLP(field.NewPath("spec", "limits", "tenants").Key(tenant).Child("otlp"), limits.OTLP)...)
+	}
+
+	return allErrs
+}
+
+func validateOTLP(path *field.Path, spec *lokiv1.OTLPSpec) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if spec.ResourceAttributes != nil {
+		allErrs = append(allErrs, validateOTLPAttributes(path.Child("resourceAttributes", "attributes"), spec.ResourceAttributes.Attributes, true)...)
+	}
+
+	allErrs = append(allErrs, validateOTLPAttributes(path.Child("scopeAttributes"), spec.ScopeAttributes, false)...)
+	allErrs = append(allErrs, validateOTLPAttributes(path.Child("logAttributes"), spec.LogAttributes, false)...)
+
+	return allErrs
+}
+
+func validateOTLPAttributes(path *field.Path, attrs []lokiv1.OTLPAttributesSpec, allowIndexLabel bool) field.ErrorList {
+	var allErrs field.ErrorList
+
+	for i, attr := range attrs {
+		if !allowIndexLabel && attr.Action == lokiv1.OTLPAttributeActionIndexLabel {
+			allErrs = append(allErrs, field.Invalid(
+				path.Index(i).Child("action"),
+				attr.Action,
+				lokiv1.ErrOTLPIndexLabelNotAllowed.Error(),
+			))
+		}
+
+		switch {
+		case len(attr.Attributes) > 0 && attr.Regex != "":
+			allErrs = append(allErrs, field.Invalid(
+				path.Index(i).Child("regex"),
+				attr.Regex,
+				lokiv1.ErrOTLPAttributesRegexConflict.Error(),
+			))
+		case len(attr.Attributes) == 0 && attr.Regex == "":
+			allErrs = append(allErrs, field.Invalid(
+				path.Index(i),
+				attr,
+				lokiv1.ErrOTLPAttributesMissing.Error(),
+			))
+		case attr.Regex != "":
+			if _, err := regexp.Compile(attr.Regex); err != nil {
+				allErrs = append(allErrs, field.Invalid(
+					path.Index(i).Child("regex"),
+					attr.Regex,
+					lokiv1.ErrOTLPInvalidRegex.Error(),
+				))
+			}
+		}
+	}
+
+	return allErrs
+}
+
 // ValidateSchemas ensures that the schemas are in a valid format
 func ValidateSchemas(v *lokiv1.ObjectStorageSpec, utcTime time.Time, status lokiv1.LokiStackStorageStatus) field.ErrorList {
 	var allErrs field.ErrorList
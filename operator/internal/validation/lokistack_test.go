@@ -389,6 +389,274 @@ var ltt = []struct {
 				),
 			},
 		),
+	}, {
+		desc: "valid bloom components with schema v13",
+		spec: lokiv1.LokiStack{
+			Spec: lokiv1.LokiStackSpec{
+				Storage: lokiv1.ObjectStorageSpec{
+					Schemas: []lokiv1.ObjectStorageSchema{
+						{
+							Version:       lokiv1.ObjectStorageSchemaV12,
+							EffectiveDate: "2020-10-11",
+						},
+						{
+							Version:       lokiv1.ObjectStorageSchemaV13,
+							EffectiveDate: "2024-01-01",
+						},
+					},
+				},
+				Template: &lokiv1.LokiTemplateSpec{
+					BloomCompactor: &lokiv1.LokiComponentSpec{Replicas: 1},
+					BloomGateway:   &lokiv1.LokiComponentSpec{Replicas: 1},
+				},
+			},
+		},
+	},
+	{
+		desc: "bloom compactor without bloom gateway",
+		spec: lokiv1.LokiStack{
+			Spec: lokiv1.LokiStackSpec{
+				Storage: lokiv1.ObjectStorageSpec{
+					Schemas: []lokiv1.ObjectStorageSchema{
+						{
+							Version:       lokiv1.ObjectStorageSchemaV12,
+							EffectiveDate: "2020-10-11",
+						},
+						{
+							Version:       lokiv1.ObjectStorageSchemaV13,
+							EffectiveDate: "2024-01-01",
+						},
+					},
+				},
+				Template: &lokiv1.LokiTemplateSpec{
+					BloomCompactor: &lokiv1.LokiComponentSpec{Replicas: 1},
+				},
+			},
+		},
+		err: apierrors.NewInvalid(
+			schema.GroupKind{Group: "loki.grafana.com", Kind: "LokiStack"},
+			"testing-stack",
+			field.ErrorList{
+				field.Invalid(
+					field.NewPath("spec", "template", "bloomCompactor"),
+					&lokiv1.LokiComponentSpec{Replicas: 1},
+					lokiv1.ErrBloomComponentsNotPaired.Error(),
+				),
+			},
+		),
+	},
+	{
+		desc: "bloom components with schema older than v13",
+		spec: lokiv1.LokiStack{
+			Spec: lokiv1.LokiStackSpec{
+				Storage: lokiv1.ObjectStorageSpec{
+					Schemas: []lokiv1.ObjectStorageSchema{
+						{
+							Version:       lokiv1.ObjectStorageSchemaV12,
+							EffectiveDate: "2020-10-11",
+						},
+					},
+				},
+				Template: &lokiv1.LokiTemplateSpec{
+					BloomCompactor: &lokiv1.LokiComponentSpec{Replicas: 1},
+					BloomGateway:   &lokiv1.LokiComponentSpec{Replicas: 1},
+				},
+			},
+		},
+		err: apierrors.NewInvalid(
+			schema.GroupKind{Group: "loki.grafana.com", Kind: "LokiStack"},
+			"testing-stack",
+			field.ErrorList{
+				field.Invalid(
+					field.NewPath("spec", "storage", "schemas"),
+					[]lokiv1.ObjectStorageSchema{
+						{
+							Version:       lokiv1.ObjectStorageSchemaV12,
+							EffectiveDate: "2020-10-11",
+						},
+					},
+					lokiv1.ErrBloomRequiresSchemaV13.Error(),
+				),
+			},
+		),
+	},
+	{
+		desc: "valid otlp config",
+		spec: lokiv1.LokiStack{
+			Spec: lokiv1.LokiStackSpec{
+				Storage: lokiv1.ObjectStorageSpec{
+					Schemas: []lokiv1.ObjectStorageSchema{
+						{
+							Version:       lokiv1.ObjectStorageSchemaV12,
+							EffectiveDate: "2020-10-11",
+						},
+					},
+				},
+				Limits: &lokiv1.LimitsSpec{
+					Global: &lokiv1.LimitsTemplateSpec{
+						OTLP: &lokiv1.OTLPSpec{
+							ResourceAttributes: &lokiv1.OTLPResourceAttributesSpec{
+								Attributes: []lokiv1.OTLPAttributesSpec{
+									{
+										Action:     lokiv1.OTLPAttributeActionIndexLabel,
+										Attributes: []string{"k8s.pod.name"},
+									},
+								},
+							},
+							LogAttributes: []lokiv1.OTLPAttributesSpec{
+								{
+									Action: lokiv1.OTLPAttributeActionDrop,
+									Regex:  "http\\..*",
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	},
+	{
+		desc: "invalid otlp config",
+		spec: lokiv1.LokiStack{
+			Spec: lokiv1.LokiStackSpec{
+				Storage: lokiv1.ObjectStorageSpec{
+					Schemas: []lokiv1.ObjectStorageSchema{
+						{
+							Version:       lokiv1.ObjectStorageSchemaV12,
+							EffectiveDate: "2020-10-11",
+						},
+					},
+				},
+				Limits: &lokiv1.LimitsSpec{
+					Global: &lokiv1.LimitsTemplateSpec{
+						OTLP: &lokiv1.OTLPSpec{
+							ScopeAttributes: []lokiv1.OTLPAttributesSpec{
+								{
+									Action:     lokiv1.OTLPAttributeActionIndexLabel,
+									Attributes: []string{"scope.name"},
+								},
+							},
+						},
+					},
+					Tenants: map[string]lokiv1.PerTenantLimitsTemplateSpec{
+						"application": {
+							OTLP: &lokiv1.OTLPSpec{
+								LogAttributes: []lokiv1.OTLPAttributesSpec{
+									{
+										Action:     lokiv1.OTLPAttributeActionStructuredMetadata,
+										Attributes: []string{"trace.id"},
+										Regex:      "trace\\..*",
+									},
+									{
+										Action: lokiv1.OTLPAttributeActionDrop,
+									},
+									{
+										Action: lokiv1.OTLPAttributeActionDrop,
+										Regex:  "(",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		err: apierrors.NewInvalid(
+			schema.GroupKind{Group: "loki.grafana.com", Kind: "LokiStack"},
+			"testing-stack",
+			field.ErrorList{
+				field.Invalid(
+					field.NewPath("spec", "limits", "global", "otlp", "scopeAttributes").Index(0).Child("action"),
+					lokiv1.OTLPAttributeActionIndexLabel,
+					lokiv1.ErrOTLPIndexLabelNotAllowed.Error(),
+				),
+				field.Invalid(
+					field.NewPath("spec", "limits", "tenants").Key("application").Child("otlp", "logAttributes").Index(0).Child("regex"),
+					"trace\\..*",
+					lokiv1.ErrOTLPAttributesRegexConflict.Error(),
+				),
+				field.Invalid(
+					field.NewPath("spec", "limits", "tenants").Key("application").Child("otlp", "logAttributes").Index(1),
+					lokiv1.OTLPAttributesSpec{Action: lokiv1.OTLPAttributeActionDrop},
+					lokiv1.ErrOTLPAttributesMissing.Error(),
+				),
+				field.Invalid(
+					field.NewPath("spec", "limits", "tenants").Key("application").Child("otlp", "logAttributes").Index(2).Child("regex"),
+					"(",
+					lokiv1.ErrOTLPInvalidRegex.Error(),
+				),
+			},
+		),
+	},
+	{
+		desc: "valid autoscaling",
+		spec: lokiv1.LokiStack{
+			Spec: lokiv1.LokiStackSpec{
+				Storage: lokiv1.ObjectStorageSpec{
+					Schemas: []lokiv1.ObjectStorageSchema{
+						{
+							Version:       lokiv1.ObjectStorageSchemaV12,
+							EffectiveDate: "2020-10-11",
+						},
+					},
+				},
+				Replication: &lokiv1.ReplicationSpec{
+					Factor: 2,
+				},
+				Autoscaling: &lokiv1.AutoscalingSpec{
+					Querier: &lokiv1.QuerierAutoscalingSpec{
+						MinReplicas: 2,
+						MaxReplicas: 2,
+					},
+					Ingester: &lokiv1.IngesterAutoscalingSpec{
+						MinReplicas: 2,
+						MaxReplicas: 5,
+					},
+				},
+			},
+		},
+	},
+	{
+		desc: "invalid autoscaling",
+		spec: lokiv1.LokiStack{
+			Spec: lokiv1.LokiStackSpec{
+				Storage: lokiv1.ObjectStorageSpec{
+					Schemas: []lokiv1.ObjectStorageSchema{
+						{
+							Version:       lokiv1.ObjectStorageSchemaV12,
+							EffectiveDate: "2020-10-11",
+						},
+					},
+				},
+				ReplicationFactor: 3,
+				Autoscaling: &lokiv1.AutoscalingSpec{
+					Querier: &lokiv1.QuerierAutoscalingSpec{
+						MinReplicas: 4,
+						MaxReplicas: 2,
+					},
+					Ingester: &lokiv1.IngesterAutoscalingSpec{
+						MinReplicas: 2,
+						MaxReplicas: 5,
+					},
+				},
+			},
+		},
+		err: apierrors.NewInvalid(
+			schema.GroupKind{Group: "loki.grafana.com", Kind: "LokiStack"},
+			"testing-stack",
+			field.ErrorList{
+				field.Invalid(
+					field.NewPath("spec", "autoscaling", "querier", "minReplicas"),
+					int32(4),
+					lokiv1.ErrAutoscalingReplicasInvalid.Error(),
+				),
+				field.Invalid(
+					field.NewPath("spec", "autoscaling", "ingester", "minReplicas"),
+					int32(2),
+					lokiv1.ErrAutoscalingIngesterReplicasTooLow.Error(),
+				),
+			},
+		),
 	},
 }
 
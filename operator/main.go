@@ -188,6 +188,15 @@ func main() {
 			os.Exit(1)
 		}
 	}
+	if err = (&lokictrl.LokiDeleteRequestReconciler{
+		Client:       mgr.GetClient(),
+		Log:          logger.WithName("controllers").WithName("lokideleterequest"),
+		Scheme:       mgr.GetScheme(),
+		FeatureGates: ctrlCfg.Gates,
+	}).SetupWithManager(mgr); err != nil {
+		logger.Error(err, "unable to create controller", "controller", "lokideleterequest")
+		os.Exit(1)
+	}
 	if ctrlCfg.Gates.BuiltInCertManagement.Enabled {
 		if err = (&lokictrl.CertRotationReconciler{
 			Client:       mgr.GetClient(),
@@ -227,11 +227,12 @@ func (g *Gateway) FilterChunkRefs(ctx context.Context, req *logproto.FilterChunk
 	}
 
 	filters := v1.ExtractTestableLineFilters(req.Plan.AST)
-	stats.NumFilters = len(filters)
-	g.metrics.receivedFilters.Observe(float64(len(filters)))
+	labelFilters := v1.ExtractTestableLabelFilters(req.Plan.AST)
+	stats.NumFilters = len(filters) + len(labelFilters)
+	g.metrics.receivedFilters.Observe(float64(stats.NumFilters))
 
 	// Shortcut if request does not contain filters
-	if len(filters) == 0 {
+	if stats.NumFilters == 0 {
 		stats.Status = labelSuccess
 		return &logproto.FilterChunkRefResponse{
 			ChunkRefs: req.Refs,
@@ -250,7 +251,7 @@ func (g *Gateway) FilterChunkRefs(ctx context.Context, req *logproto.FilterChunk
 	}
 
 	sp.LogKV(
-		"filters", len(filters),
+		"filters", stats.NumFilters,
 		"days", len(seriesByDay),
 		"series_requested", len(req.Refs),
 	)
@@ -258,7 +259,7 @@ func (g *Gateway) FilterChunkRefs(ctx context.Context, req *logproto.FilterChunk
 	tasks := make([]Task, 0, len(seriesByDay))
 	responses := make([][]v1.Output, 0, len(seriesByDay))
 	for _, seriesForDay := range seriesByDay {
-		task, err := NewTask(ctx, tenantID, seriesForDay, filters)
+		task, err := NewTask(ctx, tenantID, seriesForDay, filters, labelFilters)
 		if err != nil {
 			return nil, err
 		}
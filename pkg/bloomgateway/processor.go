@@ -178,7 +178,7 @@ func (p *processor) processBlock(_ context.Context, blockQuerier *v1.BlockQuerie
 			sp.LogKV("process block", blk.String(), "series", len(task.series))
 		}
 
-		it := v1.NewPeekingIter(task.RequestIter(tokenizer))
+		it := v1.NewPeekingIter(task.RequestIter(tokenizer, schema))
 		iters = append(iters, it)
 	}
 
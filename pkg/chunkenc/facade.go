@@ -4,6 +4,7 @@ import (
 	"io"
 	"time"
 
+	"github.com/pkg/errors"
 	"github.com/prometheus/common/model"
 
 	"github.com/grafana/loki/v3/pkg/storage/chunk"
@@ -128,3 +129,16 @@ func UncompressedSize(c chunk.Data) (int, bool) {
 
 	return f.c.UncompressedSize(), true
 }
+
+// VerifyChecksums returns ErrInvalidChecksum if blocks of the chunk were skipped while decoding it
+// because their checksum did not match. Decoding a chunk only fails when its metadata is corrupted.
+func VerifyChecksums(c chunk.Data) error {
+	f, ok := c.(*Facade)
+	if !ok || f.c == nil {
+		return nil
+	}
+	if mc, ok := f.c.(*MemChunk); ok && mc.corruptedBlocks > 0 {
+		return errors.Wrapf(ErrInvalidChecksum, "%d corrupted blocks", mc.corruptedBlocks)
+	}
+	return nil
+}
@@ -137,6 +137,8 @@ type MemChunk struct {
 
 	// compressed size of chunk. Set when chunk is cut or while decoding chunk from storage.
 	compressedSize int
+	// number of blocks skipped while decoding the chunk from storage because their checksum did not match.
+	corruptedBlocks int
 }
 
 type block struct {
@@ -482,6 +484,7 @@ func newByteChunk(b []byte, blockSize, targetSize int, fromCheckpoint bool) (*Me
 		expCRC := binary.BigEndian.Uint32(b[blk.offset+l:])
 		if expCRC != crc32.Checksum(blk.b, castagnoliTable) {
 			_ = level.Error(util_log.Logger).Log("msg", "Checksum does not match for a block in chunk, this block will be skipped", "err", ErrInvalidChecksum)
+			bc.corruptedBlocks++
 			continue
 		}
 
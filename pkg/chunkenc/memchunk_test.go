@@ -291,6 +291,30 @@ func TestCorruptChunk(t *testing.T) {
 	}
 }
 
+func TestVerifyChecksums(t *testing.T) {
+	t.Parallel()
+
+	c := NewMemChunk(ChunkFormatV4, EncSnappy, DefaultTestHeadBlockFmt, testBlockSize, testTargetSize)
+	fillChunk(c)
+	require.NoError(t, c.Close())
+	require.Greater(t, len(c.blocks), 1)
+
+	b, err := c.Bytes()
+	require.NoError(t, err)
+
+	facade := &Facade{}
+	require.NoError(t, facade.UnmarshalFromBuf(b))
+	require.NoError(t, VerifyChecksums(facade))
+
+	// flip a byte of the second block, decoding skips the block but the checksum verification fails.
+	corrupted := append([]byte(nil), b...)
+	corrupted[c.blocks[1].offset] ^= 0xff
+
+	facade = &Facade{}
+	require.NoError(t, facade.UnmarshalFromBuf(corrupted))
+	require.ErrorIs(t, VerifyChecksums(facade), ErrInvalidChecksum)
+}
+
 func TestReadFormatV1(t *testing.T) {
 	t.Parallel()
 
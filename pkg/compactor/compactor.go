@@ -88,6 +88,7 @@ type Config struct {
 	RunOnce                     bool                `yaml:"_" doc:"hidden"`
 	TablesToCompact             int                 `yaml:"tables_to_compact"`
 	SkipLatestNTables           int                 `yaml:"skip_latest_n_tables"`
+	Scrubber                    ScrubberConfig      `yaml:"scrubber"`
 }
 
 // RegisterFlags registers flags.
@@ -110,6 +111,8 @@ func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
 	f.IntVar(&cfg.TablesToCompact, "compactor.tables-to-compact", 0, "Number of tables that compactor will try to compact. Newer tables are chosen when this is less than the number of tables available.")
 	f.IntVar(&cfg.SkipLatestNTables, "compactor.skip-latest-n-tables", 0, "Do not compact N latest tables. Together with -compactor.run-once and -compactor.tables-to-compact, this is useful when clearing compactor backlogs.")
 
+	cfg.Scrubber.RegisterFlagsWithPrefix("compactor.scrubber.", f)
+
 	// Ring
 	skipFlags := []string{
 		"compactor.ring.num-tokens",
@@ -153,7 +156,7 @@ func (cfg *Config) Validate() error {
 		}
 	}
 
-	return nil
+	return cfg.Scrubber.Validate()
 }
 
 type Compactor struct {
@@ -174,6 +177,10 @@ type Compactor struct {
 	indexCompactors           map[string]IndexCompactor
 	schemaConfig              config.SchemaConfig
 	tableLocker               *tableLocker
+	scrubberMetrics           *ScrubberMetrics
+
+	lastScrubReportMtx sync.Mutex
+	lastScrubReport    *ScrubReport
 
 	// Ring used for running a single compactor
 	ringLifecycler *ring.BasicLifecycler
@@ -192,6 +199,7 @@ type storeContainer struct {
 	tableMarker        retention.TableMarker
 	sweeper            *retention.Sweeper
 	indexStorageClient storage.Client
+	objectClient       client.ObjectClient
 }
 
 type Limits interface {
@@ -290,11 +298,10 @@ func (c *Compactor) init(objectStoreClients map[config.DayTime]client.ObjectClie
 
 		var sc storeContainer
 		sc.indexStorageClient = storage.NewIndexStorageClient(objectClient, period.IndexTables.PathPrefix)
+		sc.objectClient = objectClient
 
 		if c.cfg.RetentionEnabled {
 			var (
-				raw              client.ObjectClient
-				encoder          client.KeyEncoder
 				name             = fmt.Sprintf("%s_%s", period.ObjectType, period.From.String())
 				retentionWorkDir = filepath.Join(c.cfg.WorkingDirectory, "retention", name)
 				r                = prometheus.WrapRegistererWith(prometheus.Labels{"from": name}, r)
@@ -313,15 +320,7 @@ func (c *Compactor) init(objectStoreClients map[config.DayTime]client.ObjectClie
 			// remove markers from the store dir after copying them to period specific dirs.
 			legacyMarkerDirs[period.ObjectType] = struct{}{}
 
-			if casted, ok := objectClient.(client.PrefixedObjectClient); ok {
-				raw = casted.GetDownstream()
-			} else {
-				raw = objectClient
-			}
-			if _, ok := raw.(*local.FSObjectClient); ok {
-				encoder = client.FSEncoder
-			}
-			chunkClient := client.NewClient(objectClient, encoder, schemaConfig)
+			chunkClient := client.NewClient(objectClient, chunkKeyEncoder(objectClient), schemaConfig)
 
 			sc.sweeper, err = retention.NewSweeper(retentionWorkDir, chunkClient, c.cfg.RetentionDeleteWorkCount, c.cfg.RetentionDeleteDelay, r)
 			if err != nil {
@@ -351,6 +350,25 @@ func (c *Compactor) init(objectStoreClients map[config.DayTime]client.ObjectClie
 	}
 
 	c.metrics = newMetrics(r)
+	if c.cfg.Scrubber.Enabled {
+		c.scrubberMetrics = NewScrubberMetrics(r)
+	}
+	return nil
+}
+
+// chunkKeyEncoder returns the encoder of the object keys of the chunks stored with the object client.
+func chunkKeyEncoder(objectClient client.ObjectClient) client.KeyEncoder {
+	raw := objectClient
+	for {
+		wrapper, ok := raw.(interface{ GetDownstream() client.ObjectClient })
+		if !ok {
+			break
+		}
+		raw = wrapper.GetDownstream()
+	}
+	if _, ok := raw.(*local.FSObjectClient); ok {
+		return client.FSEncoder
+	}
 	return nil
 }
 
@@ -585,6 +603,27 @@ func (c *Compactor) runCompactions(ctx context.Context) {
 			}(container)
 		}
 	}
+
+	if c.cfg.Scrubber.Enabled {
+		c.wg.Add(1)
+		go func() {
+			defer c.wg.Done()
+
+			ticker := time.NewTicker(c.cfg.Scrubber.Interval)
+			defer ticker.Stop()
+
+			for {
+				select {
+				case <-ticker.C:
+					if err := c.RunScrub(ctx); err != nil {
+						level.Error(util_log.Logger).Log("msg", "failed to scrub tables", "err", err)
+					}
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
 	level.Info(util_log.Logger).Log("msg", "compactor started")
 }
 
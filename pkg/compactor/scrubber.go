@@ -0,0 +1,699 @@
+package compactor
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/grafana/dskit/concurrency"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/prometheus/model/labels"
+
+	"github.com/grafana/loki/v3/pkg/chunkenc"
+	"github.com/grafana/loki/v3/pkg/compactor/deletion"
+	"github.com/grafana/loki/v3/pkg/compactor/retention"
+	"github.com/grafana/loki/v3/pkg/storage/chunk"
+	"github.com/grafana/loki/v3/pkg/storage/chunk/client"
+	chunk_util "github.com/grafana/loki/v3/pkg/storage/chunk/client/util"
+	"github.com/grafana/loki/v3/pkg/storage/config"
+	"github.com/grafana/loki/v3/pkg/storage/stores/shipper/indexshipper/storage"
+	util_log "github.com/grafana/loki/v3/pkg/util/log"
+)
+
+const (
+	chunkStatusOK        = "ok"
+	chunkStatusMissing   = "missing"
+	chunkStatusCorrupted = "corrupted"
+	chunkStatusOrphaned  = "orphaned"
+)
+
+var errChunkMissing = errors.New("chunk not found in the object store")
+
+type ScrubberConfig struct {
+	Enabled           bool          `yaml:"enabled"`
+	Interval          time.Duration `yaml:"interval"`
+	Repair            bool          `yaml:"repair"`
+	VerifyChecksums   bool          `yaml:"verify_checksums"`
+	OrphanGracePeriod time.Duration `yaml:"orphan_grace_period"`
+	Concurrency       int           `yaml:"concurrency"`
+	ReportDirectory   string        `yaml:"report_directory"`
+}
+
+// RegisterFlagsWithPrefix registers flags.
+func (cfg *ScrubberConfig) RegisterFlagsWithPrefix(prefix string, f *flag.FlagSet) {
+	f.BoolVar(&cfg.Enabled, prefix+"enabled", false, "Periodically verify the chunks referenced by the compacted TSDB index tables exist in the object store, and report the chunks not referenced by any index.")
+	f.DurationVar(&cfg.Interval, prefix+"interval", 24*time.Hour, "Interval at which to scrub the index tables.")
+	f.BoolVar(&cfg.Repair, prefix+"repair", false, "Remove the index entries of chunks missing from the object store. Orphaned and corrupted chunks are only reported.")
+	f.BoolVar(&cfg.VerifyChecksums, prefix+"verify-checksums", false, "Download the referenced chunks to verify their checksums. When disabled, only the existence of the chunks is checked.")
+	f.DurationVar(&cfg.OrphanGracePeriod, prefix+"orphan-grace-period", 24*time.Hour, "Chunks written more recently than this are not reported as orphaned, since their index may not be uploaded or compacted yet.")
+	f.IntVar(&cfg.Concurrency, prefix+"concurrency", 10, "Number of chunks to check in parallel.")
+	f.StringVar(&cfg.ReportDirectory, prefix+"report-directory", "", "Directory where the JSON report of every scrub is written. The report of the last scrub is also available at /compactor/scrub/report.")
+}
+
+// Validate verifies the config does not contain inappropriate values
+func (cfg *ScrubberConfig) Validate() error {
+	if !cfg.Enabled {
+		return nil
+	}
+	if cfg.Interval <= 0 {
+		return errors.New("scrubber interval must be > 0")
+	}
+	if cfg.Concurrency < 1 {
+		return errors.New("scrubber concurrency must be >= 1")
+	}
+	return nil
+}
+
+// ScrubReport is the JSON report of a scrub of the index tables.
+type ScrubReport struct {
+	StartedAt  time.Time            `json:"started_at"`
+	FinishedAt time.Time            `json:"finished_at"`
+	Repair     bool                 `json:"repair"`
+	Tenants    []*TenantScrubReport `json:"tenants"`
+}
+
+type TenantScrubReport struct {
+	UserID         string              `json:"user_id"`
+	Tables         []*TableScrubReport `json:"tables"`
+	OrphanedChunks []string            `json:"orphaned_chunks,omitempty"`
+}
+
+type TableScrubReport struct {
+	TableName string `json:"table_name"`
+	// Skipped is the reason the table was not scrubbed, if any.
+	Skipped             string           `json:"skipped,omitempty"`
+	CheckedChunks       int              `json:"checked_chunks"`
+	MissingChunks       []string         `json:"missing_chunks,omitempty"`
+	CorruptedChunks     []CorruptedChunk `json:"corrupted_chunks,omitempty"`
+	RemovedIndexEntries int              `json:"removed_index_entries"`
+}
+
+type CorruptedChunk struct {
+	ChunkID string `json:"chunk_id"`
+	Error   string `json:"error"`
+}
+
+// WriteScrubReport writes the report as JSON to w.
+func WriteScrubReport(w io.Writer, report *ScrubReport) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(report)
+}
+
+type ScrubberMetrics struct {
+	chunksTotal                   *prometheus.CounterVec
+	removedIndexEntriesTotal      prometheus.Counter
+	skippedTablesTotal            prometheus.Counter
+	scrubOperationTotal           *prometheus.CounterVec
+	scrubOperationLastSuccess     prometheus.Gauge
+	scrubOperationDurationSeconds prometheus.Gauge
+}
+
+func NewScrubberMetrics(r prometheus.Registerer) *ScrubberMetrics {
+	return &ScrubberMetrics{
+		chunksTotal: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: "loki_compactor",
+			Name:      "scrubber_chunks_total",
+			Help:      "Total number of chunks checked by the scrubber by status",
+		}, []string{"status"}),
+		removedIndexEntriesTotal: promauto.With(r).NewCounter(prometheus.CounterOpts{
+			Namespace: "loki_compactor",
+			Name:      "scrubber_removed_index_entries_total",
+			Help:      "Total number of index entries of missing chunks removed by the scrubber",
+		}),
+		skippedTablesTotal: promauto.With(r).NewCounter(prometheus.CounterOpts{
+			Namespace: "loki_compactor",
+			Name:      "scrubber_skipped_tables_total",
+			Help:      "Total number of tenant index tables skipped by the scrubber because they were not compacted",
+		}),
+		scrubOperationTotal: promauto.With(r).NewCounterVec(prometheus.CounterOpts{
+			Namespace: "loki_compactor",
+			Name:      "scrub_operation_total",
+			Help:      "Total number of scrubs done by status",
+		}, []string{"status"}),
+		scrubOperationLastSuccess: promauto.With(r).NewGauge(prometheus.GaugeOpts{
+			Namespace: "loki_compactor",
+			Name:      "scrub_last_successful_run_timestamp_seconds",
+			Help:      "Unix timestamp of the last successful scrub",
+		}),
+		scrubOperationDurationSeconds: promauto.With(r).NewGauge(prometheus.GaugeOpts{
+			Namespace: "loki_compactor",
+			Name:      "scrub_operation_duration_seconds",
+			Help:      "Time (in seconds) spent in scrubbing all the tables",
+		}),
+	}
+}
+
+// Scrubber verifies the integrity of the index tables of a period: the chunks referenced by the
+// compacted per tenant index must exist in the object store and decode with valid checksums, while
+// every chunk stored for a tenant must be referenced by its index.
+type Scrubber struct {
+	cfg                ScrubberConfig
+	workingDir         string
+	schemaConfig       config.SchemaConfig
+	periodConfig       config.PeriodConfig
+	objectClient       client.ObjectClient
+	indexStorageClient storage.Client
+	indexCompactor     IndexCompactor
+	keyEncoder         client.KeyEncoder
+	metrics            *ScrubberMetrics
+	logger             log.Logger
+
+	// tableLocker prevents scrubbing tables being compacted. It is nil when scrubbing offline.
+	tableLocker *tableLocker
+}
+
+func NewScrubber(cfg ScrubberConfig, workingDir string, schemaConfig config.SchemaConfig, periodConfig config.PeriodConfig,
+	objectClient client.ObjectClient, indexCompactor IndexCompactor, metrics *ScrubberMetrics, logger log.Logger,
+) *Scrubber {
+	return &Scrubber{
+		cfg:                cfg,
+		workingDir:         workingDir,
+		schemaConfig:       schemaConfig,
+		periodConfig:       periodConfig,
+		objectClient:       objectClient,
+		indexStorageClient: storage.NewIndexStorageClient(objectClient, periodConfig.IndexTables.PathPrefix),
+		indexCompactor:     indexCompactor,
+		keyEncoder:         chunkKeyEncoder(objectClient),
+		metrics:            metrics,
+		logger:             log.With(logger, "period", periodConfig.From.String()),
+	}
+}
+
+// Scrub scrubs the given tables of the period for the given tenants, or for all the tenants
+// having an index in the tables when userIDs is empty.
+func (s *Scrubber) Scrub(ctx context.Context, tables []string, userIDs []string) ([]*TenantScrubReport, error) {
+	// tables with common index files have not been compacted yet so they may not hold all the
+	// index of the tenants and can not be scrubbed.
+	uncompactedTables := map[string]bool{}
+	tenants := map[string]struct{}{}
+	for _, tableName := range tables {
+		commonFiles, users, err := s.indexStorageClient.ListFiles(ctx, tableName, true)
+		if err != nil {
+			return nil, err
+		}
+		uncompactedTables[tableName] = len(commonFiles) > 0
+		for _, userID := range users {
+			tenants[userID] = struct{}{}
+		}
+	}
+
+	if len(userIDs) == 0 {
+		for userID := range tenants {
+			userIDs = append(userIDs, userID)
+		}
+		sort.Strings(userIDs)
+	}
+
+	reports := make([]*TenantScrubReport, 0, len(userIDs))
+	for _, userID := range userIDs {
+		report, err := s.scrubTenant(ctx, userID, tables, uncompactedTables)
+		if err != nil {
+			return nil, errors.Wrapf(err, "scrubbing tenant %s", userID)
+		}
+		reports = append(reports, report)
+	}
+	return reports, nil
+}
+
+func (s *Scrubber) scrubTenant(ctx context.Context, userID string, tables []string, uncompactedTables map[string]bool) (*TenantScrubReport, error) {
+	report := &TenantScrubReport{UserID: userID}
+	referenced := map[string]struct{}{}
+	scrubbedTables := map[int64]struct{}{}
+
+	for _, tableName := range tables {
+		tableReport := &TableScrubReport{TableName: tableName}
+		if uncompactedTables[tableName] {
+			tableReport.Skipped = "table has uncompacted common index files"
+		} else if err := s.scrubTable(ctx, tableName, userID, tableReport, referenced); err != nil {
+			return nil, errors.Wrapf(err, "scrubbing table %s", tableName)
+		}
+		report.Tables = append(report.Tables, tableReport)
+
+		if tableReport.Skipped != "" {
+			s.metrics.skippedTablesTotal.Inc()
+			continue
+		}
+		if tableNumber, err := config.ExtractTableNumberFromName(tableName); err == nil {
+			scrubbedTables[tableNumber] = struct{}{}
+		}
+	}
+
+	orphans, err := s.findOrphanedChunks(ctx, userID, referenced, scrubbedTables)
+	if err != nil {
+		return nil, errors.Wrap(err, "finding orphaned chunks")
+	}
+	report.OrphanedChunks = orphans
+	s.metrics.chunksTotal.WithLabelValues(chunkStatusOrphaned).Add(float64(len(orphans)))
+
+	return report, nil
+}
+
+func (s *Scrubber) scrubTable(ctx context.Context, tableName, userID string, report *TableScrubReport, referenced map[string]struct{}) error {
+	chunks, err := s.listTableChunks(ctx, tableName, userID, report)
+	if err != nil || len(chunks) == 0 {
+		return err
+	}
+
+	// The table is not locked while checking the chunks, which can take long, so that it can still be compacted.
+	problems := make([]error, len(chunks))
+	err = concurrency.ForEachJob(ctx, len(chunks), s.cfg.Concurrency, func(ctx context.Context, idx int) error {
+		var err error
+		problems[idx], err = s.checkChunk(ctx, chunks[idx])
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	missing := map[string]struct{}{}
+	for i, c := range chunks {
+		referenced[s.chunkKey(c)] = struct{}{}
+		report.CheckedChunks++
+
+		chunkID := s.schemaConfig.ExternalKey(c.ChunkRef)
+		switch problem := problems[i]; {
+		case problem == nil:
+			s.metrics.chunksTotal.WithLabelValues(chunkStatusOK).Inc()
+		case errors.Is(problem, errChunkMissing):
+			s.metrics.chunksTotal.WithLabelValues(chunkStatusMissing).Inc()
+			report.MissingChunks = append(report.MissingChunks, chunkID)
+			missing[chunkID] = struct{}{}
+		default:
+			s.metrics.chunksTotal.WithLabelValues(chunkStatusCorrupted).Inc()
+			report.CorruptedChunks = append(report.CorruptedChunks, CorruptedChunk{ChunkID: chunkID, Error: problem.Error()})
+		}
+	}
+	sort.Strings(report.MissingChunks)
+
+	if !s.cfg.Repair || len(missing) == 0 {
+		return nil
+	}
+	return s.repairTable(ctx, tableName, userID, report, missing)
+}
+
+// listTableChunks returns the chunks referenced by the compacted index of the tenant in the table.
+// The table is only locked while its index is read.
+func (s *Scrubber) listTableChunks(ctx context.Context, tableName, userID string, report *TableScrubReport) ([]chunk.Chunk, error) {
+	if !s.lockTable(ctx, tableName) {
+		return nil, ctx.Err()
+	}
+	defer s.unlockTable(tableName)
+	defer s.removeWorkingDir(tableName)
+
+	is, compactedIndex, err := s.openTableIndex(ctx, tableName, userID, report)
+	if err != nil || compactedIndex == nil {
+		return nil, err
+	}
+	defer is.cleanup()
+
+	var chunks []chunk.Chunk
+	err = compactedIndex.ForEachChunk(ctx, func(entry retention.ChunkEntry) (bool, error) {
+		c, err := chunk.ParseExternalKey(userID, string(entry.ChunkID))
+		if err != nil {
+			return false, err
+		}
+		chunks = append(chunks, c)
+		return false, nil
+	})
+	return chunks, err
+}
+
+// repairTable removes the index entries of the missing chunks from the compacted index of the tenant in the table.
+// The index is read again, since it may have been compacted after its chunks were listed.
+func (s *Scrubber) repairTable(ctx context.Context, tableName, userID string, report *TableScrubReport, missing map[string]struct{}) error {
+	if !s.lockTable(ctx, tableName) {
+		return ctx.Err()
+	}
+	defer s.unlockTable(tableName)
+	defer s.removeWorkingDir(tableName)
+
+	is, compactedIndex, err := s.openTableIndex(ctx, tableName, userID, report)
+	if err != nil || compactedIndex == nil {
+		return err
+	}
+	defer is.cleanup()
+
+	removed, empty, err := removeMissingChunks(ctx, compactedIndex, missing)
+	if err != nil {
+		return err
+	}
+	// like when applying retention, an index left without any chunk is removed instead of being re-uploaded.
+	is.setCompactedIndex(compactedIndex, !empty, true)
+	if err := is.done(); err != nil {
+		return err
+	}
+
+	report.RemovedIndexEntries = removed
+	s.metrics.removedIndexEntriesTotal.Add(float64(removed))
+	level.Info(is.logger).Log("msg", "removed index entries of missing chunks", "user-id", userID, "count", removed)
+	return nil
+}
+
+// openTableIndex downloads and opens the compacted index of the tenant in the table. The returned index is nil if the
+// tenant has no index in the table, or if it has several index files waiting for compaction, in which case the table
+// is reported as skipped. The caller must hold the table lock.
+func (s *Scrubber) openTableIndex(ctx context.Context, tableName, userID string, report *TableScrubReport) (*indexSet, CompactedIndex, error) {
+	workingDir := filepath.Join(s.workingDir, tableName, userID)
+	if err := os.RemoveAll(workingDir); err != nil {
+		return nil, nil, err
+	}
+
+	is, err := newUserIndexSet(ctx, tableName, userID, storage.NewIndexSet(s.indexStorageClient, true), workingDir, log.With(s.logger, "table-name", tableName))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sourceFiles := is.ListSourceFiles()
+	if len(sourceFiles) == 0 {
+		return nil, nil, nil
+	}
+	if len(sourceFiles) != 1 {
+		report.Skipped = fmt.Sprintf("tenant has %d index files in the table, waiting for compaction", len(sourceFiles))
+		return nil, nil, nil
+	}
+
+	downloadedAt, err := is.GetSourceFile(sourceFiles[0])
+	if err != nil {
+		return nil, nil, err
+	}
+	compactedIndex, err := s.indexCompactor.OpenCompactedIndexFile(ctx, downloadedAt, tableName, userID, workingDir, s.periodConfig, is.logger)
+	if err != nil {
+		return nil, nil, err
+	}
+	is.setCompactedIndex(compactedIndex, false, false)
+	return is, compactedIndex, nil
+}
+
+// checkChunk returns errChunkMissing if the chunk does not exist in the object store, or the error
+// encountered while decoding it when it is corrupted. The returned error is set when the check failed.
+func (s *Scrubber) checkChunk(ctx context.Context, c chunk.Chunk) (problem error, err error) {
+	key := s.chunkKey(c)
+	if !s.cfg.VerifyChecksums {
+		exists, err := s.objectClient.ObjectExists(ctx, key)
+		if err != nil && !s.objectClient.IsObjectNotFoundErr(err) {
+			return nil, err
+		}
+		if !exists {
+			return errChunkMissing, nil
+		}
+		return nil, nil
+	}
+
+	reader, _, err := s.objectClient.GetObject(ctx, key)
+	if err != nil {
+		if s.objectClient.IsObjectNotFoundErr(err) {
+			return errChunkMissing, nil
+		}
+		return nil, err
+	}
+	defer reader.Close()
+
+	buf, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.Decode(chunk.NewDecodeContext(), buf); err != nil {
+		return err, nil
+	}
+	return chunkenc.VerifyChecksums(c.Data), nil
+}
+
+// findOrphanedChunks lists the chunks of the tenant which are not referenced by its index.
+// Only the chunks entirely covered by the scrubbed tables are considered, since the index
+// of the other tables was not read.
+func (s *Scrubber) findOrphanedChunks(ctx context.Context, userID string, referenced map[string]struct{}, scrubbedTables map[int64]struct{}) ([]string, error) {
+	if len(scrubbedTables) == 0 {
+		return nil, nil
+	}
+
+	tablePeriod := int64(s.periodConfig.IndexTables.Period / time.Second)
+	firstTable, lastTable := int64(math.MaxInt64), int64(math.MinInt64)
+	for tableNumber := range scrubbedTables {
+		firstTable = min(firstTable, tableNumber)
+		lastTable = max(lastTable, tableNumber)
+	}
+	gracePeriodStart := time.Now().Add(-s.cfg.OrphanGracePeriod)
+
+	var orphans []string
+	collect := func(objects []client.StorageObject) {
+		for _, object := range objects {
+			if _, ok := referenced[object.Key]; ok || object.ModifiedAt.After(gracePeriodStart) {
+				continue
+			}
+
+			c, ok := parseChunkKey(userID, object.Key)
+			if !ok {
+				continue
+			}
+			from, through := c.From.Unix()/tablePeriod, c.Through.Unix()/tablePeriod
+			if from < firstTable || through > lastTable {
+				continue
+			}
+			if p, err := s.schemaConfig.SchemaForTime(c.From); err != nil || p.From != s.periodConfig.From {
+				continue
+			}
+
+			covered := true
+			for tableNumber := from; tableNumber <= through; tableNumber++ {
+				if _, ok := scrubbedTables[tableNumber]; !ok {
+					covered = false
+					break
+				}
+			}
+			if covered {
+				orphans = append(orphans, s.schemaConfig.ExternalKey(c.ChunkRef))
+			}
+		}
+	}
+
+	// The chunks are listed one series at a time instead of holding the listing of all the chunks of the tenant.
+	// The time range of a chunk is not part of the prefix of its key, so the chunks outside of the scrubbed tables
+	// are only skipped once listed. Chunks of schemas before v12 are not stored in a directory per series.
+	objects, seriesPrefixes, err := s.objectClient.List(ctx, userID+"/", "/")
+	if err != nil {
+		return nil, err
+	}
+	collect(objects)
+
+	for _, prefix := range seriesPrefixes {
+		objects, _, err := s.objectClient.List(ctx, string(prefix), "")
+		if err != nil {
+			return nil, err
+		}
+		collect(objects)
+	}
+
+	sort.Strings(orphans)
+	return orphans, nil
+}
+
+func (s *Scrubber) removeWorkingDir(tableName string) {
+	if err := os.RemoveAll(filepath.Join(s.workingDir, tableName)); err != nil {
+		level.Error(s.logger).Log("msg", "failed to remove scrubber working directory", "table-name", tableName, "err", err)
+	}
+}
+
+func (s *Scrubber) chunkKey(c chunk.Chunk) string {
+	if s.keyEncoder != nil {
+		return s.keyEncoder(s.schemaConfig, c)
+	}
+	return s.schemaConfig.ExternalKey(c.ChunkRef)
+}
+
+func (s *Scrubber) lockTable(ctx context.Context, tableName string) bool {
+	if s.tableLocker == nil {
+		return true
+	}
+
+	for {
+		locked, lockWaiterChan := s.tableLocker.lockTable(tableName)
+		if locked {
+			return true
+		}
+
+		select {
+		case <-lockWaiterChan:
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
+
+func (s *Scrubber) unlockTable(tableName string) {
+	if s.tableLocker != nil {
+		s.tableLocker.unlockTable(tableName)
+	}
+}
+
+// removeMissingChunks removes the index entries of the missing chunks along with the series left
+// without chunks. It returns the number of removed entries and whether the index is left empty.
+func removeMissingChunks(ctx context.Context, indexProcessor retention.IndexProcessor, missing map[string]struct{}) (int, bool, error) {
+	type series struct {
+		userID    []byte
+		lbls      labels.Labels
+		remaining int
+	}
+
+	removed := 0
+	seriesMap := map[string]*series{}
+	err := indexProcessor.ForEachChunk(ctx, func(entry retention.ChunkEntry) (bool, error) {
+		// the entry is reused between calls so its slices must be copied.
+		s, ok := seriesMap[string(entry.SeriesID)]
+		if !ok {
+			s = &series{userID: slices.Clone(entry.UserID), lbls: entry.Labels.Copy()}
+			seriesMap[string(entry.SeriesID)] = s
+		}
+
+		if _, ok := missing[string(entry.ChunkID)]; ok {
+			removed++
+			return true, nil
+		}
+		s.remaining++
+		return false, nil
+	})
+	if err != nil {
+		return 0, false, err
+	}
+
+	empty := true
+	for _, s := range seriesMap {
+		if s.remaining > 0 {
+			empty = false
+			continue
+		}
+		if err := indexProcessor.CleanupSeries(s.userID, s.lbls); err != nil {
+			return 0, false, err
+		}
+	}
+
+	return removed, empty, nil
+}
+
+// parseChunkKey parses the object key of a chunk of the tenant, which may have been
+// encoded by the filesystem object client.
+func parseChunkKey(userID, key string) (chunk.Chunk, bool) {
+	if c, err := chunk.ParseExternalKey(userID, key); err == nil {
+		return c, true
+	}
+
+	split := strings.LastIndexByte(key, '/')
+	tail, err := base64.StdEncoding.DecodeString(key[split+1:])
+	if err != nil {
+		return chunk.Chunk{}, false
+	}
+	c, err := chunk.ParseExternalKey(userID, key[:split+1]+string(tail))
+	return c, err == nil
+}
+
+// RunScrub scrubs the index tables of every period, except the tables still being written to.
+func (c *Compactor) RunScrub(ctx context.Context) (err error) {
+	status := statusSuccess
+	report := &ScrubReport{StartedAt: time.Now(), Repair: c.cfg.Scrubber.Repair}
+
+	defer func() {
+		if err != nil {
+			status = statusFailure
+		}
+		c.scrubberMetrics.scrubOperationTotal.WithLabelValues(status).Inc()
+		if status == statusSuccess {
+			c.scrubberMetrics.scrubOperationDurationSeconds.Set(time.Since(report.StartedAt).Seconds())
+			c.scrubberMetrics.scrubOperationLastSuccess.SetToCurrentTime()
+		}
+	}()
+
+	now := time.Now()
+	for from, sc := range c.storeContainers {
+		period, err := c.schemaConfig.SchemaForTime(from.Time)
+		if err != nil {
+			return err
+		}
+		indexCompactor, ok := c.indexCompactors[period.IndexType]
+		if !ok {
+			continue
+		}
+
+		tables, err := sc.indexStorageClient.ListTables(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to list tables: %w", err)
+		}
+		tables = slices.DeleteFunc(tables, func(tableName string) bool {
+			if tableName == deletion.DeleteRequestsTableName {
+				return true
+			}
+			// several periods may share the same bucket and path prefix.
+			if p, ok := SchemaPeriodForTable(c.schemaConfig, tableName); !ok || p.From != from {
+				return true
+			}
+			return retention.ExtractIntervalFromTableName(tableName).End.Time().After(now)
+		})
+		SortTablesByRange(tables)
+
+		scrubber := NewScrubber(c.cfg.Scrubber, filepath.Join(c.cfg.WorkingDirectory, "scrubber"), c.schemaConfig, period, sc.objectClient, indexCompactor, c.scrubberMetrics, util_log.Logger)
+		scrubber.tableLocker = c.tableLocker
+
+		tenants, err := scrubber.Scrub(ctx, tables, nil)
+		if err != nil {
+			return err
+		}
+		report.Tenants = append(report.Tenants, tenants...)
+	}
+	report.FinishedAt = time.Now()
+
+	c.lastScrubReportMtx.Lock()
+	c.lastScrubReport = report
+	c.lastScrubReportMtx.Unlock()
+
+	if c.cfg.Scrubber.ReportDirectory == "" {
+		return nil
+	}
+	return writeScrubReportFile(filepath.Join(c.cfg.Scrubber.ReportDirectory, fmt.Sprintf("scrub-report-%d.json", report.StartedAt.Unix())), report)
+}
+
+func writeScrubReportFile(path string, report *ScrubReport) error {
+	if err := chunk_util.EnsureDirectory(filepath.Dir(path)); err != nil {
+		return err
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := WriteScrubReport(f, report); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// ScrubReportHandler serves the JSON report of the last scrub.
+func (c *Compactor) ScrubReportHandler(w http.ResponseWriter, _ *http.Request) {
+	c.lastScrubReportMtx.Lock()
+	report := c.lastScrubReport
+	c.lastScrubReportMtx.Unlock()
+
+	if report == nil {
+		http.Error(w, "no scrub has completed yet", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := WriteScrubReport(w, report); err != nil {
+		level.Error(util_log.Logger).Log("msg", "failed to write scrub report", "err", err)
+	}
+}
@@ -0,0 +1,356 @@
+package compactor
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/promql/parser"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/chunkenc"
+	"github.com/grafana/loki/v3/pkg/compactor/retention"
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/storage/chunk"
+	"github.com/grafana/loki/v3/pkg/storage/chunk/client"
+	"github.com/grafana/loki/v3/pkg/storage/chunk/client/local"
+	"github.com/grafana/loki/v3/pkg/storage/config"
+	"github.com/grafana/loki/v3/pkg/storage/stores/shipper/indexshipper/index"
+	util_log "github.com/grafana/loki/v3/pkg/util/log"
+)
+
+// scrubberTestIndex is an index file holding one "<series labels>\t<chunk id>" line per chunk.
+type scrubberTestIndex struct {
+	path          string
+	userID        string
+	lines         [][2]string
+	deletedChunks map[string]struct{}
+	deletedSeries map[string]struct{}
+}
+
+func (i *scrubberTestIndex) ForEachChunk(_ context.Context, callback retention.ChunkEntryCallback) error {
+	for _, line := range i.lines {
+		lbls, err := parser.ParseMetric(line[0])
+		if err != nil {
+			return err
+		}
+		deleteChunk, err := callback(retention.ChunkEntry{
+			ChunkRef: retention.ChunkRef{UserID: []byte(i.userID), SeriesID: []byte(line[0]), ChunkID: []byte(line[1])},
+			Labels:   lbls,
+		})
+		if err != nil {
+			return err
+		}
+		if deleteChunk {
+			i.deletedChunks[line[1]] = struct{}{}
+		}
+	}
+	return nil
+}
+
+func (i *scrubberTestIndex) IndexChunk(_ chunk.Chunk) (bool, error) {
+	return false, nil
+}
+
+func (i *scrubberTestIndex) CleanupSeries(_ []byte, lbls labels.Labels) error {
+	i.deletedSeries[lbls.String()] = struct{}{}
+	return nil
+}
+
+func (i *scrubberTestIndex) Cleanup() {}
+
+func (i *scrubberTestIndex) ToIndexFile() (index.Index, error) {
+	var sb strings.Builder
+	for _, line := range i.lines {
+		if _, ok := i.deletedChunks[line[1]]; ok {
+			continue
+		}
+		if _, ok := i.deletedSeries[line[0]]; ok {
+			continue
+		}
+		sb.WriteString(line[0] + "\t" + line[1] + "\n")
+	}
+
+	path := i.path + "-scrubbed"
+	if err := os.WriteFile(path, []byte(sb.String()), 0o640); err != nil {
+		return nil, err
+	}
+	return openCompactedIndex(path)
+}
+
+type scrubberTestIndexCompactor struct{}
+
+func (scrubberTestIndexCompactor) NewTableCompactor(_ context.Context, _ IndexSet, _ map[string]IndexSet, _ MakeEmptyUserIndexSetFunc, _ config.PeriodConfig) TableCompactor {
+	panic("not implemented")
+}
+
+func (scrubberTestIndexCompactor) OpenCompactedIndexFile(_ context.Context, path, _, userID, _ string, _ config.PeriodConfig, _ log.Logger) (CompactedIndex, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	idx := &scrubberTestIndex{path: path, userID: userID, deletedChunks: map[string]struct{}{}, deletedSeries: map[string]struct{}{}}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		series, chunkID, _ := strings.Cut(scanner.Text(), "\t")
+		idx.lines = append(idx.lines, [2]string{series, chunkID})
+	}
+	return idx, scanner.Err()
+}
+
+func createScrubberTestChunk(t *testing.T, userID string, lbls labels.Labels, from model.Time) chunk.Chunk {
+	t.Helper()
+
+	memChunk := chunkenc.NewMemChunk(chunkenc.ChunkFormatV4, chunkenc.EncSnappy, chunkenc.UnorderedWithStructuredMetadataHeadBlockFmt, 256*1024, 0)
+	require.NoError(t, memChunk.Append(&logproto.Entry{Timestamp: from.Time(), Line: "line"}))
+	require.NoError(t, memChunk.Close())
+
+	c := chunk.NewChunk(userID, model.Fingerprint(lbls.Hash()), lbls, chunkenc.NewFacade(memChunk, 0, 0), from, from.Add(time.Minute))
+	require.NoError(t, c.Encode())
+	return c
+}
+
+func TestScrubber(t *testing.T) {
+	tempDir := t.TempDir()
+	const userID = "user-1"
+
+	periodConfig := config.PeriodConfig{
+		From:       config.DayTime{Time: model.Time(0)},
+		IndexType:  "scrubber-test",
+		ObjectType: "fs",
+		Schema:     "v13",
+		IndexTables: config.IndexPeriodicTableConfig{
+			PathPrefix: "index/",
+			PeriodicTableConfig: config.PeriodicTableConfig{
+				Prefix: indexTablePrefix,
+				Period: config.ObjectStorageIndexRequiredPeriod,
+			}},
+	}
+	schemaConfig := config.SchemaConfig{Configs: []config.PeriodConfig{periodConfig}}
+
+	objectClient, err := local.NewFSObjectClient(local.FSConfig{Directory: tempDir})
+	require.NoError(t, err)
+	chunkClient := client.NewClient(objectClient, client.FSEncoder, schemaConfig)
+
+	tableStart := model.TimeFromUnix(time.Now().Add(-72*time.Hour).Unix() / 86400 * 86400)
+	tableName := periodConfig.IndexTables.TableFor(tableStart)
+
+	seriesA := labels.FromStrings("app", "a")
+	seriesB := labels.FromStrings("app", "b")
+	var (
+		stored    = createScrubberTestChunk(t, userID, seriesA, tableStart.Add(time.Hour))
+		missing   = createScrubberTestChunk(t, userID, seriesA, tableStart.Add(2*time.Hour))
+		corrupted = createScrubberTestChunk(t, userID, seriesA, tableStart.Add(3*time.Hour))
+		orphaned  = createScrubberTestChunk(t, userID, seriesA, tableStart.Add(4*time.Hour))
+		// the only chunk of series b is missing
+		missingSeries = createScrubberTestChunk(t, userID, seriesB, tableStart.Add(time.Hour))
+		// the chunk spans into the next table which is not scrubbed
+		notCovered = createScrubberTestChunk(t, userID, seriesB, tableStart.Add(24*time.Hour-time.Second))
+	)
+	require.NoError(t, chunkClient.PutChunks(context.Background(), []chunk.Chunk{stored, corrupted, orphaned, notCovered}))
+
+	// corrupt the data of the chunk after its header.
+	corruptedPath := filepath.Join(tempDir, client.FSEncoder(schemaConfig, corrupted))
+	data, err := os.ReadFile(corruptedPath)
+	require.NoError(t, err)
+	data[len(data)/2] ^= 0xff
+	require.NoError(t, os.WriteFile(corruptedPath, data, 0o640))
+
+	// make the chunks old enough to be reported as orphaned.
+	for _, c := range []chunk.Chunk{stored, corrupted, orphaned, notCovered} {
+		past := time.Now().Add(-48 * time.Hour)
+		require.NoError(t, os.Chtimes(filepath.Join(tempDir, client.FSEncoder(schemaConfig, c)), past, past))
+	}
+
+	indexDir := filepath.Join(tempDir, "index", tableName, userID)
+	require.NoError(t, os.MkdirAll(indexDir, 0o750))
+	var sb strings.Builder
+	for _, c := range []chunk.Chunk{stored, missing, corrupted, missingSeries} {
+		sb.WriteString(fmt.Sprintf("%s\t%s\n", c.Metric.String(), schemaConfig.ExternalKey(c.ChunkRef)))
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(indexDir, "index-1"), []byte(sb.String()), 0o640))
+
+	cfg := ScrubberConfig{VerifyChecksums: true, OrphanGracePeriod: time.Hour, Concurrency: 2}
+
+	for _, repair := range []bool{false, true} {
+		t.Run(fmt.Sprintf("repair=%v", repair), func(t *testing.T) {
+			cfg.Repair = repair
+			scrubber := NewScrubber(cfg, filepath.Join(tempDir, "scrubber"), schemaConfig, periodConfig, objectClient, scrubberTestIndexCompactor{}, NewScrubberMetrics(prometheus.NewPedanticRegistry()), util_log.Logger)
+
+			reports, err := scrubber.Scrub(context.Background(), []string{tableName}, nil)
+			require.NoError(t, err)
+			require.Len(t, reports, 1)
+
+			report := reports[0]
+			require.Equal(t, userID, report.UserID)
+			require.Equal(t, []string{schemaConfig.ExternalKey(orphaned.ChunkRef)}, report.OrphanedChunks)
+			require.Len(t, report.Tables, 1)
+
+			tableReport := report.Tables[0]
+			require.Empty(t, tableReport.Skipped)
+			require.Equal(t, 4, tableReport.CheckedChunks)
+			require.ElementsMatch(t, []string{schemaConfig.ExternalKey(missing.ChunkRef), schemaConfig.ExternalKey(missingSeries.ChunkRef)}, tableReport.MissingChunks)
+			require.Len(t, tableReport.CorruptedChunks, 1)
+			require.Equal(t, schemaConfig.ExternalKey(corrupted.ChunkRef), tableReport.CorruptedChunks[0].ChunkID)
+
+			if !repair {
+				require.Equal(t, 0, tableReport.RemovedIndexEntries)
+				return
+			}
+			require.Equal(t, 2, tableReport.RemovedIndexEntries)
+
+			// the index was replaced with one without the missing chunks.
+			files, err := os.ReadDir(indexDir)
+			require.NoError(t, err)
+			require.Len(t, files, 1)
+			require.True(t, strings.HasSuffix(files[0].Name(), ".gz"))
+		})
+	}
+
+	// the repaired index only references existing chunks.
+	cfg.Repair = false
+	scrubber := NewScrubber(cfg, filepath.Join(tempDir, "scrubber"), schemaConfig, periodConfig, objectClient, scrubberTestIndexCompactor{}, NewScrubberMetrics(prometheus.NewPedanticRegistry()), util_log.Logger)
+	reports, err := scrubber.Scrub(context.Background(), []string{tableName}, []string{userID})
+	require.NoError(t, err)
+	require.Equal(t, 2, reports[0].Tables[0].CheckedChunks)
+	require.Empty(t, reports[0].Tables[0].MissingChunks)
+}
+
+func TestScrubber_SkipsUncompactedTables(t *testing.T) {
+	tempDir := t.TempDir()
+	tableName := fmt.Sprintf("%s%d", indexTablePrefix, time.Now().Add(-72*time.Hour).Unix()/86400)
+	SetupTable(t, filepath.Join(tempDir, "index", tableName), IndexesConfig{NumUnCompactedFiles: 2}, PerUserIndexesConfig{NumUsers: 1, IndexesConfig: IndexesConfig{NumCompactedFiles: 1}})
+
+	periodConfig := config.PeriodConfig{
+		From:       config.DayTime{Time: model.Time(0)},
+		IndexType:  "scrubber-test",
+		ObjectType: "fs",
+		Schema:     "v13",
+		IndexTables: config.IndexPeriodicTableConfig{
+			PathPrefix: "index/",
+			PeriodicTableConfig: config.PeriodicTableConfig{
+				Prefix: indexTablePrefix,
+				Period: config.ObjectStorageIndexRequiredPeriod,
+			}},
+	}
+	objectClient, err := local.NewFSObjectClient(local.FSConfig{Directory: tempDir})
+	require.NoError(t, err)
+
+	cfg := ScrubberConfig{VerifyChecksums: true, OrphanGracePeriod: time.Hour, Concurrency: 2}
+	scrubber := NewScrubber(cfg, filepath.Join(tempDir, "scrubber"), config.SchemaConfig{Configs: []config.PeriodConfig{periodConfig}}, periodConfig, objectClient, scrubberTestIndexCompactor{}, NewScrubberMetrics(prometheus.NewPedanticRegistry()), util_log.Logger)
+
+	reports, err := scrubber.Scrub(context.Background(), []string{tableName}, nil)
+	require.NoError(t, err)
+	require.Len(t, reports, 1)
+	require.Equal(t, BuildUserID(0), reports[0].UserID)
+	require.NotEmpty(t, reports[0].Tables[0].Skipped)
+	require.Empty(t, reports[0].OrphanedChunks)
+}
+
+// scrubberTestObjectClient records the listings and calls onGetChunk before downloading a chunk.
+type scrubberTestObjectClient struct {
+	client.ObjectClient
+	userID     string
+	onGetChunk func()
+
+	mtx   sync.Mutex
+	lists [][2]string
+}
+
+func (c *scrubberTestObjectClient) GetDownstream() client.ObjectClient {
+	return c.ObjectClient
+}
+
+func (c *scrubberTestObjectClient) GetObject(ctx context.Context, objectKey string) (io.ReadCloser, int64, error) {
+	if strings.HasPrefix(objectKey, c.userID+"/") {
+		c.onGetChunk()
+	}
+	return c.ObjectClient.GetObject(ctx, objectKey)
+}
+
+func (c *scrubberTestObjectClient) List(ctx context.Context, prefix, delimiter string) ([]client.StorageObject, []client.StorageCommonPrefix, error) {
+	c.mtx.Lock()
+	c.lists = append(c.lists, [2]string{prefix, delimiter})
+	c.mtx.Unlock()
+	return c.ObjectClient.List(ctx, prefix, delimiter)
+}
+
+func TestScrubber_DoesNotLockTableWhileCheckingChunks(t *testing.T) {
+	tempDir := t.TempDir()
+	const userID = "user-1"
+
+	periodConfig := config.PeriodConfig{
+		From:       config.DayTime{Time: model.Time(0)},
+		IndexType:  "scrubber-test",
+		ObjectType: "fs",
+		Schema:     "v13",
+		IndexTables: config.IndexPeriodicTableConfig{
+			PathPrefix: "index/",
+			PeriodicTableConfig: config.PeriodicTableConfig{
+				Prefix: indexTablePrefix,
+				Period: config.ObjectStorageIndexRequiredPeriod,
+			}},
+	}
+	schemaConfig := config.SchemaConfig{Configs: []config.PeriodConfig{periodConfig}}
+
+	fsClient, err := local.NewFSObjectClient(local.FSConfig{Directory: tempDir})
+	require.NoError(t, err)
+
+	tableStart := model.TimeFromUnix(time.Now().Add(-72*time.Hour).Unix() / 86400 * 86400)
+	tableName := periodConfig.IndexTables.TableFor(tableStart)
+
+	series := labels.FromStrings("app", "a")
+	stored := createScrubberTestChunk(t, userID, series, tableStart.Add(time.Hour))
+	orphaned := createScrubberTestChunk(t, userID, series, tableStart.Add(2*time.Hour))
+	require.NoError(t, client.NewClient(fsClient, client.FSEncoder, schemaConfig).PutChunks(context.Background(), []chunk.Chunk{stored, orphaned}))
+	past := time.Now().Add(-48 * time.Hour)
+	require.NoError(t, os.Chtimes(filepath.Join(tempDir, client.FSEncoder(schemaConfig, orphaned)), past, past))
+
+	indexDir := filepath.Join(tempDir, "index", tableName, userID)
+	require.NoError(t, os.MkdirAll(indexDir, 0o750))
+	require.NoError(t, os.WriteFile(filepath.Join(indexDir, "index-1"), []byte(fmt.Sprintf("%s\t%s\n", series.String(), schemaConfig.ExternalKey(stored.ChunkRef))), 0o640))
+
+	locker := newTableLocker()
+	var lockedWhileChecking atomic.Bool
+	objectClient := &scrubberTestObjectClient{ObjectClient: fsClient, userID: userID, onGetChunk: func() {
+		// the compaction of the table can proceed while the chunks are downloaded.
+		if locked, _ := locker.lockTable(tableName); locked {
+			locker.unlockTable(tableName)
+			return
+		}
+		lockedWhileChecking.Store(true)
+	}}
+
+	cfg := ScrubberConfig{VerifyChecksums: true, OrphanGracePeriod: time.Hour, Concurrency: 1}
+	scrubber := NewScrubber(cfg, filepath.Join(tempDir, "scrubber"), schemaConfig, periodConfig, objectClient, scrubberTestIndexCompactor{}, NewScrubberMetrics(prometheus.NewPedanticRegistry()), util_log.Logger)
+	scrubber.tableLocker = locker
+
+	reports, err := scrubber.Scrub(context.Background(), []string{tableName}, nil)
+	require.NoError(t, err)
+	require.Equal(t, 1, reports[0].Tables[0].CheckedChunks)
+	require.Empty(t, reports[0].Tables[0].CorruptedChunks)
+	require.Equal(t, []string{schemaConfig.ExternalKey(orphaned.ChunkRef)}, reports[0].OrphanedChunks)
+	require.False(t, lockedWhileChecking.Load())
+
+	// the chunks of the tenant are listed one series at a time.
+	for _, list := range objectClient.lists {
+		if list[0] == userID+"/" {
+			require.Equal(t, "/", list[1])
+		}
+	}
+	require.Contains(t, objectClient.lists, [2]string{fmt.Sprintf("%s/%x/", userID, stored.Fingerprint), ""})
+}
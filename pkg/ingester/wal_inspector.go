@@ -0,0 +1,686 @@
+package ingester
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/pkg/errors"
+	"github.com/prometheus/prometheus/tsdb/chunks"
+	"github.com/prometheus/prometheus/tsdb/wlog"
+
+	"github.com/grafana/loki/v3/pkg/chunkenc"
+	"github.com/grafana/loki/v3/pkg/ingester/wal"
+	"github.com/grafana/loki/v3/pkg/logproto"
+	lokilog "github.com/grafana/loki/v3/pkg/logql/log"
+)
+
+const (
+	// WALProblemCorruption is reported when a segment can not be read any further, the rest of the segment is lost.
+	WALProblemCorruption = "corruption"
+	// WALProblemInvalidRecord is reported for records which can be read but not decoded.
+	WALProblemInvalidRecord = "invalid_record"
+	// WALProblemUnknownStream is reported for entries of streams not created by the checkpoint or by a previous
+	// series record. Those entries are dropped when the WAL is replayed.
+	WALProblemUnknownStream = "unknown_stream"
+
+	// WALRepairTruncate drops everything from the first corrupted or invalid record on, like Prometheus does.
+	WALRepairTruncate = "truncate"
+	// WALRepairSkip only drops the corrupted and invalid records. The rest of a corrupted segment is still lost.
+	WALRepairSkip = "skip"
+)
+
+// WALReport is the result of inspecting the WAL directory of an ingester.
+type WALReport struct {
+	Dir        string               `json:"dir"`
+	Checkpoint *WALCheckpointReport `json:"checkpoint,omitempty"`
+	Segments   []*WALSegmentReport  `json:"segments"`
+	Tenants    []*WALTenantReport   `json:"tenants"`
+}
+
+type WALCheckpointReport struct {
+	Name     string        `json:"name"`
+	Series   int           `json:"series"`
+	Chunks   int           `json:"chunks"`
+	Entries  int           `json:"entries"`
+	From     time.Time     `json:"from"`
+	Through  time.Time     `json:"through"`
+	Problems []*WALProblem `json:"problems,omitempty"`
+}
+
+type WALSegmentReport struct {
+	Segment        int                       `json:"segment"`
+	SeriesRecords  int                       `json:"series_records"`
+	EntriesRecords int                       `json:"entries_records"`
+	Entries        int                       `json:"entries"`
+	From           time.Time                 `json:"from"`
+	Through        time.Time                 `json:"through"`
+	Tenants        []*WALSegmentTenantReport `json:"tenants"`
+	Problems       []*WALProblem             `json:"problems,omitempty"`
+
+	tenants map[string]*WALSegmentTenantReport
+	streams map[string]map[chunks.HeadSeriesRef]struct{}
+}
+
+type WALSegmentTenantReport struct {
+	UserID  string    `json:"user_id"`
+	Streams int       `json:"streams"`
+	Entries int       `json:"entries"`
+	From    time.Time `json:"from"`
+	Through time.Time `json:"through"`
+}
+
+type WALTenantReport struct {
+	UserID  string             `json:"user_id"`
+	Streams []*WALStreamReport `json:"streams"`
+}
+
+type WALStreamReport struct {
+	Labels      string    `json:"labels"`
+	Fingerprint uint64    `json:"fingerprint"`
+	Entries     int       `json:"entries"`
+	From        time.Time `json:"from"`
+	Through     time.Time `json:"through"`
+}
+
+// WALProblem is a record of the checkpoint or of a segment which can not be replayed.
+type WALProblem struct {
+	Type       string `json:"type"`
+	Checkpoint bool   `json:"checkpoint,omitempty"`
+	Segment    int    `json:"segment"`
+	// Offset is the end of the record in the segment, or where reading failed for corruptions.
+	Offset int64  `json:"offset"`
+	UserID string `json:"user_id,omitempty"`
+	Error  string `json:"error"`
+}
+
+// WALRepairReport summarizes the records copied to the repaired WAL.
+type WALRepairReport struct {
+	Mode            string        `json:"mode"`
+	OutputDir       string        `json:"output_dir"`
+	KeptRecords     int           `json:"kept_records"`
+	DroppedRecords  int           `json:"dropped_records"`
+	DroppedSegments []int         `json:"dropped_segments,omitempty"`
+	Problems        []*WALProblem `json:"problems,omitempty"`
+}
+
+// HasProblems returns true if any record of the WAL can not be replayed.
+func (r *WALReport) HasProblems() bool {
+	if r.Checkpoint != nil && len(r.Checkpoint.Problems) > 0 {
+		return true
+	}
+	for _, s := range r.Segments {
+		if len(s.Problems) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func WriteWALReport(w io.Writer, report interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(report)
+}
+
+// InspectWAL reads the last checkpoint and all the segments of the WAL directory of an ingester,
+// and reports their content together with the records which can not be replayed. Entries are counted as
+// stored, including the ones skipped during replay because the checkpoint already contains them.
+func InspectWAL(dir string) (*WALReport, error) {
+	checkpointDir, _, err := lastCheckpoint(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &WALReport{Dir: dir, Segments: []*WALSegmentReport{}, Tenants: []*WALTenantReport{}}
+	if checkpointDir != "" {
+		report.Checkpoint = &WALCheckpointReport{Name: filepath.Base(checkpointDir)}
+	}
+
+	streams := map[string]map[chunks.HeadSeriesRef]*WALStreamReport{}
+	getStream := func(userID string, ref chunks.HeadSeriesRef) *WALStreamReport {
+		if streams[userID] == nil {
+			return nil
+		}
+		return streams[userID][ref]
+	}
+	setStream := func(userID string, ref chunks.HeadSeriesRef, lbls string) {
+		if streams[userID] == nil {
+			streams[userID] = map[chunks.HeadSeriesRef]*WALStreamReport{}
+		}
+		if _, ok := streams[userID][ref]; !ok {
+			streams[userID][ref] = &WALStreamReport{Labels: lbls, Fingerprint: uint64(ref)}
+		}
+	}
+
+	var segment *WALSegmentReport
+	err = walkWAL(dir, checkpointDir, walVisitor{
+		startSegment: func(checkpoint bool, i int) error {
+			if !checkpoint {
+				segment = &WALSegmentReport{
+					Segment: i,
+					Tenants: []*WALSegmentTenantReport{},
+					tenants: map[string]*WALSegmentTenantReport{},
+					streams: map[string]map[chunks.HeadSeriesRef]struct{}{},
+				}
+				report.Segments = append(report.Segments, segment)
+			}
+			return nil
+		},
+		record: func(rec walRecord, problem *WALProblem) error {
+			if rec.checkpoint {
+				cp := report.Checkpoint
+				if problem != nil {
+					cp.Problems = append(cp.Problems, problem)
+					return nil
+				}
+
+				s := rec.series
+				ref := chunks.HeadSeriesRef(s.Fingerprint)
+				setStream(s.UserID, ref, logproto.FromLabelAdaptersToLabels(s.Labels).String())
+				stream := getStream(s.UserID, ref)
+
+				err := forEachCheckpointEntry(context.Background(), s, func(e logproto.Entry) error {
+					stream.Entries++
+					cp.Entries++
+					extendTimeRange(&stream.From, &stream.Through, e.Timestamp)
+					extendTimeRange(&cp.From, &cp.Through, e.Timestamp)
+					return nil
+				})
+				if err != nil {
+					cp.Problems = append(cp.Problems, &WALProblem{
+						Type: WALProblemInvalidRecord, Checkpoint: true, Segment: rec.segment, Offset: rec.offset, UserID: s.UserID, Error: err.Error(),
+					})
+				}
+				cp.Series++
+				cp.Chunks += len(s.Chunks)
+				return nil
+			}
+
+			if problem != nil {
+				segment.Problems = append(segment.Problems, problem)
+				return nil
+			}
+
+			r := rec.record
+			if len(r.Series) > 0 {
+				segment.SeriesRecords++
+				for _, s := range r.Series {
+					setStream(r.UserID, s.Ref, s.Labels.String())
+				}
+			}
+			if len(r.RefEntries) > 0 {
+				segment.EntriesRecords++
+			}
+			for _, refEntries := range r.RefEntries {
+				stream := getStream(r.UserID, refEntries.Ref)
+				if stream == nil {
+					segment.Problems = append(segment.Problems, &WALProblem{
+						Type:    WALProblemUnknownStream,
+						Segment: rec.segment,
+						Offset:  rec.offset,
+						UserID:  r.UserID,
+						Error:   fmt.Sprintf("%d entries of unknown stream %d", len(refEntries.Entries), refEntries.Ref),
+					})
+					continue
+				}
+
+				tenant, ok := segment.tenants[r.UserID]
+				if !ok {
+					tenant = &WALSegmentTenantReport{UserID: r.UserID}
+					segment.tenants[r.UserID] = tenant
+					segment.streams[r.UserID] = map[chunks.HeadSeriesRef]struct{}{}
+					segment.Tenants = append(segment.Tenants, tenant)
+				}
+				segment.streams[r.UserID][refEntries.Ref] = struct{}{}
+				tenant.Streams = len(segment.streams[r.UserID])
+
+				for _, e := range refEntries.Entries {
+					stream.Entries++
+					tenant.Entries++
+					segment.Entries++
+					extendTimeRange(&stream.From, &stream.Through, e.Timestamp)
+					extendTimeRange(&tenant.From, &tenant.Through, e.Timestamp)
+					extendTimeRange(&segment.From, &segment.Through, e.Timestamp)
+				}
+			}
+			return nil
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, s := range report.Segments {
+		sort.Slice(s.Tenants, func(i, j int) bool { return s.Tenants[i].UserID < s.Tenants[j].UserID })
+	}
+	for userID, tenantStreams := range streams {
+		tenant := &WALTenantReport{UserID: userID, Streams: make([]*WALStreamReport, 0, len(tenantStreams))}
+		for _, s := range tenantStreams {
+			tenant.Streams = append(tenant.Streams, s)
+		}
+		sort.Slice(tenant.Streams, func(i, j int) bool { return tenant.Streams[i].Labels < tenant.Streams[j].Labels })
+		report.Tenants = append(report.Tenants, tenant)
+	}
+	sort.Slice(report.Tenants, func(i, j int) bool { return report.Tenants[i].UserID < report.Tenants[j].UserID })
+
+	return report, nil
+}
+
+// RepairWAL copies the last checkpoint and the segments of the WAL directory to outputDir, dropping the records
+// which can not be replayed according to mode. The WAL directory itself is not modified, the output directory
+// must not exist or be empty. Entries of unknown streams are not considered, they are dropped during replay anyway.
+func RepairWAL(dir, outputDir, mode string, logger log.Logger) (*WALRepairReport, error) {
+	if mode != WALRepairTruncate && mode != WALRepairSkip {
+		return nil, errors.Errorf("unknown repair mode %q, must be one of %s, %s", mode, WALRepairTruncate, WALRepairSkip)
+	}
+	if entries, err := os.ReadDir(outputDir); err == nil && len(entries) > 0 {
+		return nil, errors.Errorf("output directory %s is not empty", outputDir)
+	}
+	if err := os.MkdirAll(outputDir, 0o777); err != nil {
+		return nil, err
+	}
+
+	checkpointDir, _, err := lastCheckpoint(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &WALRepairReport{Mode: mode, OutputDir: outputDir}
+	var (
+		writer *segmentRewriter
+		// truncated is set once a problem was found in the checkpoint, respectively in the segments.
+		truncated bool
+	)
+	closeWriter := func() error {
+		if writer == nil {
+			return nil
+		}
+		err := writer.Close()
+		writer = nil
+		return err
+	}
+
+	err = walkWAL(dir, checkpointDir, walVisitor{
+		startSegment: func(checkpoint bool, i int) error {
+			if err := closeWriter(); err != nil {
+				return err
+			}
+
+			if truncated {
+				if !checkpoint {
+					report.DroppedSegments = append(report.DroppedSegments, i)
+				}
+				return nil
+			}
+
+			target := outputDir
+			if checkpoint {
+				target = filepath.Join(outputDir, filepath.Base(checkpointDir))
+			}
+
+			var err error
+			writer, err = newSegmentRewriter(target, i, logger)
+			return err
+		},
+		record: func(rec walRecord, problem *WALProblem) error {
+			if problem != nil {
+				problem.Checkpoint = rec.checkpoint
+				report.Problems = append(report.Problems, problem)
+				if mode == WALRepairTruncate {
+					truncated = true
+				}
+			}
+			if problem != nil || truncated || writer == nil {
+				if rec.raw != nil {
+					report.DroppedRecords++
+				}
+				return nil
+			}
+
+			report.KeptRecords++
+			return writer.Log(rec.raw)
+		},
+		endCheckpoint: func() error {
+			// Problems of the checkpoint do not truncate the segments.
+			truncated = false
+			return closeWriter()
+		},
+	})
+	if cerr := closeWriter(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// ExportWAL replays the last checkpoint and the segments of the WAL directory like an ingester would,
+// and passes the entries to push in requests of at most batchSize entries per tenant. Records which can
+// not be replayed are skipped.
+func ExportWAL(ctx context.Context, dir string, batchSize int, push func(ctx context.Context, userID string, req *logproto.PushRequest) error) error {
+	if batchSize <= 0 {
+		return errors.New("batch size must be positive")
+	}
+
+	checkpointDir, _, err := lastCheckpoint(dir)
+	if err != nil {
+		return err
+	}
+
+	type exportStream struct {
+		labels  string
+		counter int64
+	}
+	streams := map[string]map[chunks.HeadSeriesRef]*exportStream{}
+	setStream := func(userID string, ref chunks.HeadSeriesRef, lbls string, counter int64) {
+		if streams[userID] == nil {
+			streams[userID] = map[chunks.HeadSeriesRef]*exportStream{}
+		}
+		if _, ok := streams[userID][ref]; !ok {
+			streams[userID][ref] = &exportStream{labels: lbls, counter: counter}
+		}
+	}
+
+	batches := map[string]*exportBatch{}
+	add := func(userID, lbls string, entries ...logproto.Entry) error {
+		b, ok := batches[userID]
+		if !ok {
+			b = newExportBatch()
+			batches[userID] = b
+		}
+		for _, e := range entries {
+			b.add(lbls, e)
+			if b.size >= batchSize {
+				if err := push(ctx, userID, b.request()); err != nil {
+					return errors.Wrapf(err, "push entries of tenant %s", userID)
+				}
+				b.reset()
+			}
+		}
+		return nil
+	}
+
+	err = walkWAL(dir, checkpointDir, walVisitor{
+		record: func(rec walRecord, problem *WALProblem) error {
+			if problem != nil {
+				return nil
+			}
+
+			if rec.checkpoint {
+				s := rec.series
+				lbls := logproto.FromLabelAdaptersToLabels(s.Labels).String()
+				setStream(s.UserID, chunks.HeadSeriesRef(s.Fingerprint), lbls, s.EntryCt)
+				// Chunks which can not be decoded are skipped, like during recovery.
+				_ = forEachCheckpointEntry(ctx, s, func(e logproto.Entry) error {
+					return add(s.UserID, lbls, e)
+				})
+				return nil
+			}
+
+			r := rec.record
+			for _, s := range r.Series {
+				setStream(r.UserID, s.Ref, s.Labels.String(), 0)
+			}
+			for _, refEntries := range r.RefEntries {
+				stream, ok := streams[r.UserID][refEntries.Ref]
+				if !ok {
+					continue
+				}
+				// Entries already contained in the checkpoint are skipped, see stream.Push.
+				if refEntries.Counter > 0 && refEntries.Counter <= stream.counter {
+					continue
+				}
+				if refEntries.Counter > stream.counter {
+					stream.counter = refEntries.Counter
+				}
+				if err := add(r.UserID, stream.labels, refEntries.Entries...); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	userIDs := make([]string, 0, len(batches))
+	for userID := range batches {
+		userIDs = append(userIDs, userID)
+	}
+	sort.Strings(userIDs)
+	for _, userID := range userIDs {
+		if b := batches[userID]; b.size > 0 {
+			if err := push(ctx, userID, b.request()); err != nil {
+				return errors.Wrapf(err, "push entries of tenant %s", userID)
+			}
+		}
+	}
+	return nil
+}
+
+type exportBatch struct {
+	streams map[string]*logproto.Stream
+	order   []string
+	size    int
+}
+
+func newExportBatch() *exportBatch {
+	return &exportBatch{streams: map[string]*logproto.Stream{}}
+}
+
+func (b *exportBatch) add(lbls string, e logproto.Entry) {
+	s, ok := b.streams[lbls]
+	if !ok {
+		s = &logproto.Stream{Labels: lbls}
+		b.streams[lbls] = s
+		b.order = append(b.order, lbls)
+	}
+	s.Entries = append(s.Entries, e)
+	b.size++
+}
+
+func (b *exportBatch) request() *logproto.PushRequest {
+	req := &logproto.PushRequest{Streams: make([]logproto.Stream, 0, len(b.order))}
+	for _, lbls := range b.order {
+		req.Streams = append(req.Streams, *b.streams[lbls])
+	}
+	return req
+}
+
+func (b *exportBatch) reset() {
+	b.streams = map[string]*logproto.Stream{}
+	b.order = b.order[:0]
+	b.size = 0
+}
+
+// forEachCheckpointEntry calls fn for all the entries of the chunks of a checkpoint series.
+func forEachCheckpointEntry(ctx context.Context, s *Series, fn func(logproto.Entry) error) error {
+	pipeline := lokilog.NewNoopPipeline().ForStream(logproto.FromLabelAdaptersToLabels(s.Labels))
+	for i, c := range s.Chunks {
+		mc, err := chunkenc.MemchunkFromCheckpoint(c.Data, c.Head, chunkenc.OrderedHeadBlockFmt, 0, 0)
+		if err != nil {
+			return errors.Wrapf(err, "decode chunk %d", i)
+		}
+		it, err := mc.Iterator(ctx, time.Unix(0, 0), time.Unix(0, math.MaxInt64), logproto.FORWARD, pipeline)
+		if err != nil {
+			return errors.Wrapf(err, "iterate chunk %d", i)
+		}
+		for it.Next() {
+			if err := fn(it.Entry()); err != nil {
+				_ = it.Close()
+				return err
+			}
+		}
+		err = it.Error()
+		_ = it.Close()
+		if err != nil {
+			return errors.Wrapf(err, "iterate chunk %d", i)
+		}
+	}
+	return nil
+}
+
+func extendTimeRange(from, through *time.Time, ts time.Time) {
+	if from.IsZero() || ts.Before(*from) {
+		*from = ts
+	}
+	if through.IsZero() || ts.After(*through) {
+		*through = ts
+	}
+}
+
+// walRecord is a record of the checkpoint or of a WAL segment. Either series or record is set for records
+// which could be decoded, raw is nil for corruptions.
+type walRecord struct {
+	checkpoint bool
+	segment    int
+	offset     int64
+	raw        []byte // only valid until the visitor returns
+
+	series *Series
+	record *wal.Record
+}
+
+type walVisitor struct {
+	// startSegment is called before the records of every segment, it is optional.
+	startSegment func(checkpoint bool, segment int) error
+	// endCheckpoint is called after the last segment of the checkpoint, it is optional.
+	endCheckpoint func() error
+	// record is called for every record, with the problem if the record can not be replayed.
+	record func(rec walRecord, problem *WALProblem) error
+}
+
+// walkWAL visits the records of the checkpoint and of the WAL segments in dir, in the order they are replayed.
+func walkWAL(dir, checkpointDir string, v walVisitor) error {
+	if checkpointDir != "" {
+		if err := walkSegments(checkpointDir, true, v); err != nil {
+			return errors.Wrap(err, "read checkpoint")
+		}
+		if v.endCheckpoint != nil {
+			if err := v.endCheckpoint(); err != nil {
+				return err
+			}
+		}
+	}
+	if err := walkSegments(dir, false, v); err != nil {
+		return errors.Wrap(err, "read segments")
+	}
+	return nil
+}
+
+func walkSegments(dir string, checkpoint bool, v walVisitor) error {
+	first, last, err := wlog.Segments(dir)
+	if err != nil {
+		return err
+	}
+	if first < 0 {
+		return nil
+	}
+
+	for segment := first; segment <= last; segment++ {
+		if v.startSegment != nil {
+			if err := v.startSegment(checkpoint, segment); err != nil {
+				return err
+			}
+		}
+
+		sr, err := wlog.NewSegmentsRangeReader(wlog.SegmentRange{Dir: dir, First: segment, Last: segment})
+		if err != nil {
+			return err
+		}
+
+		r := wlog.NewReader(sr)
+		for r.Next() {
+			rec := walRecord{checkpoint: checkpoint, segment: segment, offset: r.Offset(), raw: r.Record()}
+			if err := v.record(rec, decodeWALRecord(&rec)); err != nil {
+				_ = sr.Close()
+				return err
+			}
+		}
+
+		if err := r.Err(); err != nil {
+			problem := &WALProblem{Type: WALProblemCorruption, Checkpoint: checkpoint, Segment: segment, Error: err.Error()}
+			var cerr *wlog.CorruptionErr
+			if errors.As(err, &cerr) {
+				problem.Offset = cerr.Offset
+				problem.Error = cerr.Err.Error()
+			}
+			if err := v.record(walRecord{checkpoint: checkpoint, segment: segment, offset: problem.Offset}, problem); err != nil {
+				_ = sr.Close()
+				return err
+			}
+		}
+		if err := sr.Close(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func decodeWALRecord(rec *walRecord) *WALProblem {
+	var err error
+	if len(rec.raw) == 0 {
+		err = errors.New("empty record")
+	} else if rec.checkpoint {
+		rec.series = &Series{}
+		err = decodeCheckpointRecord(rec.raw, rec.series)
+	} else {
+		rec.record = &wal.Record{}
+		err = wal.DecodeRecord(rec.raw, rec.record)
+	}
+	if err != nil {
+		rec.series, rec.record = nil, nil
+		return &WALProblem{Type: WALProblemInvalidRecord, Checkpoint: rec.checkpoint, Segment: rec.segment, Offset: rec.offset, Error: err.Error()}
+	}
+	return nil
+}
+
+// segmentRewriter writes records to a new segment with the given index. The records are written to a
+// temporary WAL first, since a WAL always starts with its first segment.
+type segmentRewriter struct {
+	dir     string
+	tmp     string
+	segment int
+	wal     *wlog.WL
+}
+
+func newSegmentRewriter(dir string, segment int, logger log.Logger) (*segmentRewriter, error) {
+	tmp := filepath.Join(dir, fmt.Sprintf("%08d.tmp", segment))
+	if err := os.MkdirAll(tmp, 0o777); err != nil {
+		return nil, err
+	}
+	w, err := wlog.NewSize(logger, nil, tmp, walSegmentSize, wlog.CompressionNone)
+	if err != nil {
+		return nil, errors.Wrapf(err, "create segment %d", segment)
+	}
+	return &segmentRewriter{dir: dir, tmp: tmp, segment: segment, wal: w}, nil
+}
+
+func (s *segmentRewriter) Log(rec []byte) error {
+	return s.wal.Log(rec)
+}
+
+func (s *segmentRewriter) Close() error {
+	if err := s.wal.Close(); err != nil {
+		return err
+	}
+	if _, last, err := wlog.Segments(s.tmp); err != nil {
+		return err
+	} else if last > 0 {
+		return errors.Errorf("rewritten segment %d exceeds the segment size", s.segment)
+	}
+	if err := os.Rename(wlog.SegmentName(s.tmp, 0), wlog.SegmentName(s.dir, s.segment)); err != nil {
+		return err
+	}
+	return os.RemoveAll(s.tmp)
+}
@@ -0,0 +1,195 @@
+package ingester
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/tsdb/chunks"
+	"github.com/prometheus/prometheus/tsdb/record"
+	"github.com/prometheus/prometheus/tsdb/wlog"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/chunkenc"
+	"github.com/grafana/loki/v3/pkg/ingester/wal"
+	"github.com/grafana/loki/v3/pkg/logproto"
+)
+
+// writeTestWAL writes a checkpoint with the stream {app="a"} and two segments. The first segment contains
+// entries of an unknown stream and an invalid record, the only record of the second segment is corrupted.
+func writeTestWAL(t *testing.T) string {
+	dir := t.TempDir()
+
+	c := chunkenc.NewMemChunk(chunkenc.ChunkFormatV4, chunkenc.EncSnappy, chunkenc.UnorderedWithStructuredMetadataHeadBlockFmt, 256*1024, 1500*1024)
+	for i := 1; i <= 3; i++ {
+		require.NoError(t, c.Append(&logproto.Entry{Timestamp: time.Unix(int64(i), 0), Line: "checkpointed"}))
+	}
+	var chk, head bytes.Buffer
+	require.NoError(t, c.SerializeForCheckpointTo(&chk, &head))
+	from, to := c.Bounds()
+
+	cp, err := wlog.NewSize(log.NewNopLogger(), nil, filepath.Join(dir, "checkpoint.000001"), walSegmentSize, wlog.CompressionNone)
+	require.NoError(t, err)
+	rec, err := encodeWithTypeHeader(&Series{
+		UserID:      "fake",
+		Fingerprint: 1,
+		Labels:      logproto.FromLabelsToLabelAdapters(labels.FromStrings("app", "a")),
+		Chunks:      []Chunk{{From: from, To: to, Data: chk.Bytes(), Head: head.Bytes()}},
+		EntryCt:     3,
+	}, wal.CheckpointRecord, nil)
+	require.NoError(t, err)
+	require.NoError(t, cp.Log(rec))
+	require.NoError(t, cp.Close())
+
+	entries := func(ref uint64, counter int64, ts ...int64) wal.RefEntries {
+		e := wal.RefEntries{Ref: chunks.HeadSeriesRef(ref), Counter: counter}
+		for _, s := range ts {
+			e.Entries = append(e.Entries, logproto.Entry{Timestamp: time.Unix(s, 0), Line: "segment"})
+		}
+		return e
+	}
+
+	w, err := wlog.NewSize(log.NewNopLogger(), nil, dir, walSegmentSize, wlog.CompressionNone)
+	require.NoError(t, err)
+	series := &wal.Record{UserID: "fake", Series: []record.RefSeries{{Ref: 2, Labels: labels.FromStrings("app", "b")}}}
+	require.NoError(t, w.Log(
+		series.EncodeSeries(nil),
+		// already contained in the checkpoint
+		(&wal.Record{UserID: "fake", RefEntries: []wal.RefEntries{entries(1, 3, 3)}}).EncodeEntries(wal.CurrentEntriesRec, nil),
+		(&wal.Record{UserID: "fake", RefEntries: []wal.RefEntries{entries(1, 4, 4), entries(2, 1, 5), entries(9, 1, 5)}}).EncodeEntries(wal.CurrentEntriesRec, nil),
+		[]byte{0xff, 1, 2},
+		(&wal.Record{UserID: "fake", RefEntries: []wal.RefEntries{entries(2, 2, 6)}}).EncodeEntries(wal.CurrentEntriesRec, nil),
+	))
+	_, err = w.NextSegment()
+	require.NoError(t, err)
+	require.NoError(t, w.Log((&wal.Record{UserID: "fake", RefEntries: []wal.RefEntries{entries(1, 5, 7)}}).EncodeEntries(wal.CurrentEntriesRec, nil)))
+	require.NoError(t, w.Close())
+
+	// Corrupt the data of the record of the second segment, after its 7 bytes header.
+	f, err := os.OpenFile(wlog.SegmentName(dir, 1), os.O_RDWR, 0o666)
+	require.NoError(t, err)
+	_, err = f.WriteAt([]byte{0xff}, 10)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	return dir
+}
+
+func problemTypes(problems []*WALProblem) []string {
+	types := []string{}
+	for _, p := range problems {
+		types = append(types, p.Type)
+	}
+	return types
+}
+
+func TestInspectWAL(t *testing.T) {
+	dir := writeTestWAL(t)
+
+	report, err := InspectWAL(dir)
+	require.NoError(t, err)
+	require.True(t, report.HasProblems())
+
+	require.Equal(t, "checkpoint.000001", report.Checkpoint.Name)
+	require.Equal(t, 1, report.Checkpoint.Series)
+	require.Equal(t, 1, report.Checkpoint.Chunks)
+	require.Equal(t, 3, report.Checkpoint.Entries)
+	require.Equal(t, time.Unix(1, 0), report.Checkpoint.From)
+	require.Equal(t, time.Unix(3, 0), report.Checkpoint.Through)
+	require.Empty(t, report.Checkpoint.Problems)
+
+	require.Len(t, report.Segments, 2)
+	s := report.Segments[0]
+	require.Equal(t, 1, s.SeriesRecords)
+	require.Equal(t, 3, s.EntriesRecords)
+	require.Equal(t, 4, s.Entries)
+	require.Equal(t, time.Unix(3, 0), s.From)
+	require.Equal(t, time.Unix(6, 0), s.Through)
+	require.Equal(t, []*WALSegmentTenantReport{{UserID: "fake", Streams: 2, Entries: 4, From: time.Unix(3, 0), Through: time.Unix(6, 0)}}, s.Tenants)
+	require.Equal(t, []string{WALProblemUnknownStream, WALProblemInvalidRecord}, problemTypes(s.Problems))
+
+	require.Equal(t, 0, report.Segments[1].Entries)
+	require.Equal(t, []string{WALProblemCorruption}, problemTypes(report.Segments[1].Problems))
+
+	require.Equal(t, []*WALTenantReport{{
+		UserID: "fake",
+		Streams: []*WALStreamReport{
+			{Labels: `{app="a"}`, Fingerprint: 1, Entries: 5, From: time.Unix(1, 0), Through: time.Unix(4, 0)},
+			{Labels: `{app="b"}`, Fingerprint: 2, Entries: 2, From: time.Unix(5, 0), Through: time.Unix(6, 0)},
+		},
+	}}, report.Tenants)
+}
+
+func TestRepairWAL(t *testing.T) {
+	for _, tc := range []struct {
+		mode            string
+		kept, dropped   int
+		droppedSegments []int
+		segments        int
+		entries         int
+	}{
+		{mode: WALRepairSkip, kept: 5, dropped: 1, segments: 2, entries: 4},
+		{mode: WALRepairTruncate, kept: 4, dropped: 2, droppedSegments: []int{1}, segments: 1, entries: 3},
+	} {
+		t.Run(tc.mode, func(t *testing.T) {
+			dir := writeTestWAL(t)
+			out := filepath.Join(t.TempDir(), "repaired")
+
+			report, err := RepairWAL(dir, out, tc.mode, log.NewNopLogger())
+			require.NoError(t, err)
+			require.Equal(t, tc.kept, report.KeptRecords)
+			require.Equal(t, tc.dropped, report.DroppedRecords)
+			require.Equal(t, tc.droppedSegments, report.DroppedSegments)
+			require.Equal(t, []string{WALProblemInvalidRecord, WALProblemCorruption}, problemTypes(report.Problems))
+
+			repaired, err := InspectWAL(out)
+			require.NoError(t, err)
+			require.Equal(t, 3, repaired.Checkpoint.Entries)
+			require.Len(t, repaired.Segments, tc.segments)
+			require.Equal(t, tc.entries, repaired.Segments[0].Entries)
+			// Entries of unknown streams are kept.
+			require.Equal(t, []string{WALProblemUnknownStream}, problemTypes(repaired.Segments[0].Problems))
+			for _, s := range repaired.Segments[1:] {
+				require.Empty(t, s.Problems)
+			}
+
+			// The source is not modified.
+			_, err = RepairWAL(dir, out, tc.mode, log.NewNopLogger())
+			require.Error(t, err)
+			source, err := InspectWAL(dir)
+			require.NoError(t, err)
+			require.Len(t, source.Segments, 2)
+		})
+	}
+}
+
+func TestExportWAL(t *testing.T) {
+	dir := writeTestWAL(t)
+
+	var (
+		pushes  int
+		entries = map[string][]int64{}
+	)
+	err := ExportWAL(context.Background(), dir, 2, func(_ context.Context, userID string, req *logproto.PushRequest) error {
+		require.Equal(t, "fake", userID)
+		pushes++
+		for _, s := range req.Streams {
+			for _, e := range s.Entries {
+				entries[s.Labels] = append(entries[s.Labels], e.Timestamp.Unix())
+			}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, 3, pushes)
+	require.Equal(t, map[string][]int64{
+		`{app="a"}`: {1, 2, 3, 4},
+		`{app="b"}`: {5, 6},
+	}, entries)
+}
@@ -1,6 +1,7 @@
 package client
 
 import (
+	"bytes"
 	"context"
 	"encoding/base64"
 	"fmt"
@@ -13,6 +14,8 @@ import (
 	"strings"
 	"time"
 
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/snappy"
 	"github.com/gorilla/websocket"
 	json "github.com/json-iterator/go"
 	"github.com/prometheus/common/config"
@@ -30,6 +33,7 @@ import (
 const (
 	queryPath         = "/loki/api/v1/query"
 	queryRangePath    = "/loki/api/v1/query_range"
+	exportPath        = "/loki/api/v1/export"
 	labelsPath        = "/loki/api/v1/labels"
 	labelValuesPath   = "/loki/api/v1/label/%s/values"
 	seriesPath        = "/loki/api/v1/series"
@@ -37,6 +41,7 @@ const (
 	statsPath         = "/loki/api/v1/index/stats"
 	volumePath        = "/loki/api/v1/index/volume"
 	volumeRangePath   = "/loki/api/v1/index/volume_range"
+	pushPath          = "/loki/api/v1/push"
 	defaultAuthHeader = "Authorization"
 )
 
@@ -45,7 +50,8 @@ var userAgent = fmt.Sprintf("loki-logcli/%s", build.Version)
 // Client contains all the methods to query a Loki instance, it's an interface to allow multiple implementations.
 type Client interface {
 	Query(queryStr string, limit int, time time.Time, direction logproto.Direction, quiet bool) (*loghttp.QueryResponse, error)
-	QueryRange(queryStr string, limit int, start, end time.Time, direction logproto.Direction, step, interval time.Duration, quiet bool) (*loghttp.QueryResponse, error)
+	QueryRange(queryStr string, limit int, start, end time.Time, direction logproto.Direction, step, interval time.Duration, cursor string, quiet bool) (*loghttp.QueryResponse, error)
+	Export(queryStr string, limit, pageSize int, start, end time.Time, direction logproto.Direction, cursor string, quiet bool) (io.ReadCloser, error)
 	ListLabelNames(quiet bool, start, end time.Time) (*loghttp.LabelResponse, error)
 	ListLabelValues(name string, quiet bool, start, end time.Time) (*loghttp.LabelResponse, error)
 	Series(matchers []string, start, end time.Time, quiet bool) (*loghttp.SeriesResponse, error)
@@ -54,6 +60,7 @@ type Client interface {
 	GetStats(queryStr string, start, end time.Time, quiet bool) (*logproto.IndexStatsResponse, error)
 	GetVolume(query *volume.Query) (*loghttp.QueryResponse, error)
 	GetVolumeRange(query *volume.Query) (*loghttp.QueryResponse, error)
+	Push(req *logproto.PushRequest, quiet bool) error
 }
 
 // Tripperware can wrap a roundtripper.
@@ -93,10 +100,11 @@ func (c *DefaultClient) Query(queryStr string, limit int, time time.Time, direct
 	return c.doQuery(queryPath, qsb.Encode(), quiet)
 }
 
-// QueryRange uses the /api/v1/query_range endpoint to execute a range query
+// QueryRange uses the /api/v1/query_range endpoint to execute a range query.
+// The cursor of the previous page of a log query, if not empty, returns the entries following it.
 // excluding interfacer b/c it suggests taking the interface promql.Node instead of logproto.Direction b/c it happens to have a String() method
 // nolint:interfacer
-func (c *DefaultClient) QueryRange(queryStr string, limit int, start, end time.Time, direction logproto.Direction, step, interval time.Duration, quiet bool) (*loghttp.QueryResponse, error) {
+func (c *DefaultClient) QueryRange(queryStr string, limit int, start, end time.Time, direction logproto.Direction, step, interval time.Duration, cursor string, quiet bool) (*loghttp.QueryResponse, error) {
 	params := util.NewQueryStringBuilder()
 	params.SetString("query", queryStr)
 	params.SetInt32("limit", limit)
@@ -114,9 +122,35 @@ func (c *DefaultClient) QueryRange(queryStr string, limit int, start, end time.T
 		params.SetFloat("interval", interval.Seconds())
 	}
 
+	if cursor != "" {
+		params.SetString("cursor", cursor)
+	}
+
 	return c.doQuery(queryRangePath, params.Encode(), quiet)
 }
 
+// Export uses the /api/v1/export endpoint to stream the entries of a log query. It returns the
+// newline delimited JSON response, which must be closed once read.
+// nolint:interfacer
+func (c *DefaultClient) Export(queryStr string, limit, pageSize int, start, end time.Time, direction logproto.Direction, cursor string, quiet bool) (io.ReadCloser, error) {
+	params := util.NewQueryStringBuilder()
+	params.SetString("query", queryStr)
+	params.SetInt32("limit", limit)
+	params.SetInt32("page_size", pageSize)
+	params.SetInt("start", start.UnixNano())
+	params.SetInt("end", end.UnixNano())
+	params.SetString("direction", direction.String())
+	if cursor != "" {
+		params.SetString("cursor", cursor)
+	}
+
+	resp, err := c.do(exportPath, params.Encode(), quiet)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
 // ListLabelNames uses the /api/v1/label endpoint to list label names
 func (c *DefaultClient) ListLabelNames(quiet bool, start, end time.Time) (*loghttp.LabelResponse, error) {
 	var labelResponse loghttp.LabelResponse
@@ -236,22 +270,59 @@ func (c *DefaultClient) doQuery(path string, query string, quiet bool) (*loghttp
 }
 
 func (c *DefaultClient) doRequest(path, query string, quiet bool, out interface{}) error {
-	us, err := buildURL(c.Address, path, query)
+	resp, err := c.do(path, query, quiet)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			log.Println("error closing body", err)
+		}
+	}()
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// Push uses the /loki/api/v1/push endpoint to push the streams of req.
+func (c *DefaultClient) Push(req *logproto.PushRequest, quiet bool) error {
+	buf, err := proto.Marshal(req)
 	if err != nil {
 		return err
 	}
+
+	resp, err := c.send(http.MethodPost, pushPath, "", "application/x-protobuf", snappy.Encode(nil, buf), quiet)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// do sends a request, retrying it until it succeeds or runs out of attempts.
+func (c *DefaultClient) do(path, query string, quiet bool) (*http.Response, error) {
+	return c.send(http.MethodGet, path, query, "", nil, quiet)
+}
+
+// send sends a request with the given body, retrying it until it succeeds or runs out of attempts.
+func (c *DefaultClient) send(method, path, query, contentType string, body []byte, quiet bool) (*http.Response, error) {
+	us, err := buildURL(c.Address, path, query)
+	if err != nil {
+		return nil, err
+	}
 	if !quiet {
 		log.Print(us)
 	}
 
-	req, err := http.NewRequest("GET", us, nil)
+	req, err := http.NewRequest(method, us, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	h, err := c.getHTTPRequestHeader()
 	if err != nil {
-		return err
+		return nil, err
+	}
+	if contentType != "" {
+		h.Set("Content-Type", contentType)
 	}
 	req.Header = h
 
@@ -263,14 +334,14 @@ func (c *DefaultClient) doRequest(path, query string, quiet bool, out interface{
 	if c.ProxyURL != "" {
 		prox, err := url.Parse(c.ProxyURL)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		clientConfig.ProxyURL = config.URL{URL: prox}
 	}
 
 	client, err := config.NewClientFromConfig(clientConfig, "promtail", config.WithHTTP2Disabled())
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if c.Tripperware != nil {
 		client.Transport = c.Tripperware(client.Transport)
@@ -292,6 +363,9 @@ func (c *DefaultClient) doRequest(path, query string, quiet bool, out interface{
 		if !backoff.Ongoing() {
 			break
 		}
+		if body != nil {
+			req.Body, req.ContentLength = io.NopCloser(bytes.NewReader(body)), int64(len(body))
+		}
 		resp, err = client.Do(req)
 		if err != nil {
 			log.Println("error sending request", err)
@@ -313,15 +387,9 @@ func (c *DefaultClient) doRequest(path, query string, quiet bool, out interface{
 
 	}
 	if !success {
-		return fmt.Errorf("run out of attempts while querying the server")
+		return nil, fmt.Errorf("run out of attempts while querying the server")
 	}
-
-	defer func() {
-		if err := resp.Body.Close(); err != nil {
-			log.Println("error closing body", err)
-		}
-	}()
-	return json.NewDecoder(resp.Body).Decode(out)
+	return resp, nil
 }
 
 // nolint:goconst
@@ -2,10 +2,18 @@ package client
 
 import (
 	"encoding/base64"
+	"io"
 	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/snappy"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/logproto"
 )
 
 func Test_buildURL(t *testing.T) {
@@ -78,3 +86,43 @@ func Test_getHTTPRequestHeader(t *testing.T) {
 		})
 	}
 }
+
+func TestDefaultClient_Push(t *testing.T) {
+	var attempts int
+	var got logproto.PushRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		// The first attempt fails, the retry must send the body again.
+		if attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, pushPath, r.URL.Path)
+		assert.Equal(t, "application/x-protobuf", r.Header.Get("Content-Type"))
+		assert.Equal(t, "tenant", r.Header.Get("X-Scope-OrgID"))
+
+		compressed, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		buf, err := snappy.Decode(nil, compressed)
+		require.NoError(t, err)
+		require.NoError(t, proto.Unmarshal(buf, &got))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	req := &logproto.PushRequest{Streams: []logproto.Stream{{
+		Labels:  `{job="import"}`,
+		Entries: []logproto.Entry{{Timestamp: time.Unix(1, 0).UTC(), Line: "hello"}},
+	}}}
+	c := &DefaultClient{Address: srv.URL, OrgID: "tenant", Retries: 1}
+	require.NoError(t, c.Push(req, true))
+	require.Equal(t, 2, attempts)
+	require.Equal(t, req.Streams[0].Labels, got.Streams[0].Labels)
+	require.Equal(t, req.Streams[0].Entries[0].Line, got.Streams[0].Entries[0].Line)
+	require.True(t, req.Streams[0].Entries[0].Timestamp.Equal(got.Streams[0].Entries[0].Timestamp))
+
+	attempts = 0
+	c.Retries = 0
+	require.Error(t, c.Push(req, true))
+}
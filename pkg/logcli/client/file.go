@@ -104,7 +104,7 @@ func (f *FileClient) Query(q string, limit int, t time.Time, direction logproto.
 	}, nil
 }
 
-func (f *FileClient) QueryRange(queryStr string, limit int, start, end time.Time, direction logproto.Direction, step, interval time.Duration, _ bool) (*loghttp.QueryResponse, error) {
+func (f *FileClient) QueryRange(queryStr string, limit int, start, end time.Time, direction logproto.Direction, step, interval time.Duration, _ string, _ bool) (*loghttp.QueryResponse, error) {
 	ctx := context.Background()
 
 	ctx = user.InjectOrgID(ctx, f.orgID)
@@ -145,6 +145,10 @@ func (f *FileClient) QueryRange(queryStr string, limit int, start, end time.Time
 	}, nil
 }
 
+func (f *FileClient) Export(_ string, _, _ int, _, _ time.Time, _ logproto.Direction, _ string, _ bool) (io.ReadCloser, error) {
+	return nil, fmt.Errorf("Export: %w", ErrNotSupported)
+}
+
 func (f *FileClient) ListLabelNames(_ bool, _, _ time.Time) (*loghttp.LabelResponse, error) {
 	return &loghttp.LabelResponse{
 		Status: loghttp.QueryStatusSuccess,
@@ -204,6 +208,10 @@ func (f *FileClient) GetVolumeRange(_ *volume.Query) (*loghttp.QueryResponse, er
 	return nil, ErrNotSupported
 }
 
+func (f *FileClient) Push(_ *logproto.PushRequest, _ bool) error {
+	return fmt.Errorf("Push: %w", ErrNotSupported)
+}
+
 type limiter struct {
 	n int
 }
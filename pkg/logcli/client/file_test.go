@@ -77,6 +77,7 @@ func TestFileClient_QueryRangeLogQueries(t *testing.T) {
 				c.direction,
 				c.step,
 				c.interval,
+				"",
 				true,
 			)
 
@@ -0,0 +1,646 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bmatcuk/doublestar"
+	"github.com/cespare/xxhash/v2"
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/zstd"
+	"github.com/prometheus/prometheus/model/labels"
+
+	"github.com/grafana/loki/v3/pkg/iter"
+	"github.com/grafana/loki/v3/pkg/logcli/util"
+	"github.com/grafana/loki/v3/pkg/loghttp"
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/logql"
+	logqllog "github.com/grafana/loki/v3/pkg/logql/log"
+	"github.com/grafana/loki/v3/pkg/logql/syntax"
+	"github.com/grafana/loki/v3/pkg/util/log"
+)
+
+const (
+	// FilenameLabel is the label holding the path of the file a line was read from.
+	FilenameLabel = "filename"
+
+	// DefaultLocalMaxOpenFiles is the default maximum number of files a query reads.
+	DefaultLocalMaxOpenFiles = 1000
+
+	// reverseReadSize is the size of the blocks files are read backward by.
+	reverseReadSize = 64 * 1024
+)
+
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+
+	templateLabelRegexp = regexp.MustCompile(`\{([a-zA-Z_][a-zA-Z0-9_]*)\}`)
+)
+
+// LocalConfig configures the files a LocalClient reads.
+type LocalConfig struct {
+	// Paths are the files, directories and glob patterns to read. Directories are read recursively.
+	Paths []string
+	// PathTemplate derives labels from the path of the files, e.g. /var/log/{app}/{pod}.log.
+	// When Paths is empty, the files matching the template are read.
+	PathTemplate string
+	// TimestampFormat is the format of the timestamps of the lines: a Go time layout, the name of one of
+	// the layouts of the time package (e.g. RFC3339) or one of Unix, UnixMs, UnixUs and UnixNs.
+	// Without format, lines are timestamped with the modification time of their file.
+	TimestampFormat string
+	// TimestampRegexp finds the timestamp in a line, in its first capture group if it has one.
+	TimestampRegexp string
+	// Location is used for the timestamps without time zone.
+	Location *time.Location
+	// MaxOpenFiles is the maximum number of files a query reads. The files selected by a query are merged by
+	// timestamp, so they are all open until the query ends. Defaults to DefaultLocalMaxOpenFiles.
+	MaxOpenFiles int
+}
+
+// LocalClient is a type of LogCLI client that runs LogQL queries on local files, optionally compressed
+// with gzip or zstd, instead of getting log lines from Loki servers.
+//
+// Files are opened once a query reads them, and closed at its end. Backward queries read plain files from
+// their end, compressed files are decompressed into a temporary file first.
+type LocalClient struct {
+	*FileClient
+	files []localFile
+}
+
+type localFile struct {
+	path   string
+	labels labels.Labels
+}
+
+// NewLocalClient returns a LocalClient for the files of the given config.
+func NewLocalClient(cfg LocalConfig) (*LocalClient, error) {
+	files, err := localFiles(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no file found")
+	}
+	ts, err := util.NewTimestampParser(cfg.TimestampFormat, cfg.TimestampRegexp, cfg.Location)
+	if err != nil {
+		return nil, err
+	}
+
+	maxOpenFiles := cfg.MaxOpenFiles
+	if maxOpenFiles <= 0 {
+		maxOpenFiles = DefaultLocalMaxOpenFiles
+	}
+
+	q := &localQuerier{files: files, timestamps: ts, maxOpenFiles: maxOpenFiles}
+	return &LocalClient{
+		FileClient: &FileClient{
+			orgID:  defaultOrgID,
+			engine: logql.NewEngine(logql.EngineOpts{}, q, &limiter{n: defaultMetricSeriesLimit}, log.Logger),
+		},
+		files: files,
+	}, nil
+}
+
+func localFiles(cfg LocalConfig) ([]localFile, error) {
+	var template *regexp.Regexp
+	paths := cfg.Paths
+	if cfg.PathTemplate != "" {
+		var err error
+		if template, err = pathTemplateRegexp(cfg.PathTemplate); err != nil {
+			return nil, err
+		}
+		if len(paths) == 0 {
+			paths = []string{templateLabelRegexp.ReplaceAllString(cfg.PathTemplate, "*")}
+		}
+	}
+
+	seen := map[string]struct{}{}
+	var files []localFile
+	add := func(path string) {
+		path = filepath.Clean(path)
+		if _, ok := seen[path]; ok {
+			return
+		}
+		seen[path] = struct{}{}
+
+		builder := labels.NewScratchBuilder(1)
+		builder.Add(FilenameLabel, path)
+		if template != nil {
+			matchPath := path
+			if filepath.IsAbs(cfg.PathTemplate) {
+				if abs, err := filepath.Abs(path); err == nil {
+					matchPath = abs
+				}
+			}
+			if match := template.FindStringSubmatch(matchPath); match != nil {
+				for i, name := range template.SubexpNames() {
+					if name != "" && name != FilenameLabel && match[i] != "" {
+						builder.Add(name, match[i])
+					}
+				}
+			}
+		}
+		builder.Sort()
+		files = append(files, localFile{path: path, labels: builder.Labels()})
+	}
+
+	for _, p := range paths {
+		matches, err := doublestar.Glob(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid path %s: %w", p, err)
+		}
+		for _, match := range matches {
+			info, err := os.Stat(match)
+			if err != nil {
+				return nil, err
+			}
+			if !info.IsDir() {
+				add(match)
+				continue
+			}
+			err = filepath.WalkDir(match, func(path string, d fs.DirEntry, err error) error {
+				if err != nil {
+					return err
+				}
+				if d.Type().IsRegular() {
+					add(path)
+				}
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	sort.Slice(files, func(i, j int) bool { return files[i].path < files[j].path })
+	return files, nil
+}
+
+// pathTemplateRegexp returns the regexp matching the paths of the given template, with a named capture group
+// per label of the template.
+func pathTemplateRegexp(template string) (*regexp.Regexp, error) {
+	var sb strings.Builder
+	sb.WriteString("^")
+	last := 0
+	for _, loc := range templateLabelRegexp.FindAllStringSubmatchIndex(template, -1) {
+		sb.WriteString(regexp.QuoteMeta(template[last:loc[0]]))
+		sb.WriteString("(?P<" + template[loc[2]:loc[3]] + `>[^/]*)`)
+		last = loc[1]
+	}
+	sb.WriteString(regexp.QuoteMeta(template[last:]))
+	sb.WriteString("$")
+
+	re, err := regexp.Compile(sb.String())
+	if err != nil {
+		return nil, fmt.Errorf("invalid path template %s: %w", template, err)
+	}
+	return re, nil
+}
+
+func (l *LocalClient) ListLabelNames(_ bool, _, _ time.Time) (*loghttp.LabelResponse, error) {
+	names := map[string]struct{}{}
+	for _, f := range l.files {
+		for _, lbl := range f.labels {
+			names[lbl.Name] = struct{}{}
+		}
+	}
+	return &loghttp.LabelResponse{
+		Status: loghttp.QueryStatusSuccess,
+		Data:   sortedKeys(names),
+	}, nil
+}
+
+func (l *LocalClient) ListLabelValues(name string, _ bool, _, _ time.Time) (*loghttp.LabelResponse, error) {
+	values := map[string]struct{}{}
+	for _, f := range l.files {
+		if v := f.labels.Get(name); v != "" {
+			values[v] = struct{}{}
+		}
+	}
+	return &loghttp.LabelResponse{
+		Status: loghttp.QueryStatusSuccess,
+		Data:   sortedKeys(values),
+	}, nil
+}
+
+func (l *LocalClient) Series(matchers []string, _, _ time.Time, _ bool) (*loghttp.SeriesResponse, error) {
+	groups := make([][]*labels.Matcher, 0, len(matchers))
+	for _, m := range matchers {
+		ms, err := syntax.ParseMatchers(m, true)
+		if err != nil {
+			return nil, err
+		}
+		groups = append(groups, ms)
+	}
+
+	series := []loghttp.LabelSet{}
+	for _, f := range l.files {
+		matches := len(groups) == 0
+		for _, ms := range groups {
+			if matchLabels(f.labels, ms) {
+				matches = true
+				break
+			}
+		}
+		if matches {
+			series = append(series, loghttp.LabelSet(f.labels.Map()))
+		}
+	}
+	return &loghttp.SeriesResponse{
+		Status: loghttp.QueryStatusSuccess,
+		Data:   series,
+	}, nil
+}
+
+func sortedKeys(m map[string]struct{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func matchLabels(lbs labels.Labels, matchers []*labels.Matcher) bool {
+	for _, m := range matchers {
+		if !m.Matches(lbs.Get(m.Name)) {
+			return false
+		}
+	}
+	return true
+}
+
+type localQuerier struct {
+	files        []localFile
+	timestamps   *util.TimestampParser
+	maxOpenFiles int
+}
+
+// selectFiles returns the files matching the selector of a query.
+func (q *localQuerier) selectFiles(matchers []*labels.Matcher) ([]localFile, error) {
+	var files []localFile
+	for _, f := range q.files {
+		if matchLabels(f.labels, matchers) {
+			files = append(files, f)
+		}
+	}
+	if len(files) > q.maxOpenFiles {
+		return nil, fmt.Errorf("the query selects %d files, more than the %d files that can be open at once: narrow down the stream selector or raise the maximum of open files", len(files), q.maxOpenFiles)
+	}
+	return files, nil
+}
+
+func (q *localQuerier) SelectLogs(_ context.Context, params logql.SelectLogParams) (iter.EntryIterator, error) {
+	expr, err := params.LogSelector()
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract selector for logs: %w", err)
+	}
+	pipeline, err := expr.Pipeline()
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract pipeline for logs: %w", err)
+	}
+
+	files, err := q.selectFiles(expr.Matchers())
+	if err != nil {
+		return nil, err
+	}
+
+	its := make([]iter.EntryIterator, 0, len(files))
+	for _, f := range files {
+		lines := newLocalLines(f, q.timestamps, params.Start, params.End)
+		// The order of the lines in a file is expected to be the order of their timestamps.
+		lines.backward = params.Direction == logproto.BACKWARD
+		its = append(its, &localEntryIterator{
+			localLines: lines,
+			pipeline:   pipeline.ForStream(f.labels),
+		})
+	}
+	return iter.NewSortEntryIterator(its, params.Direction), nil
+}
+
+func (q *localQuerier) SelectSamples(_ context.Context, params logql.SelectSampleParams) (iter.SampleIterator, error) {
+	expr, err := params.Expr()
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract expression for samples: %w", err)
+	}
+	selector, err := expr.Selector()
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract selector for samples: %w", err)
+	}
+	extractor, err := expr.Extractor()
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract extractor for samples: %w", err)
+	}
+
+	files, err := q.selectFiles(selector.Matchers())
+	if err != nil {
+		return nil, err
+	}
+
+	its := make([]iter.SampleIterator, 0, len(files))
+	for _, f := range files {
+		its = append(its, &localSampleIterator{
+			localLines: newLocalLines(f, q.timestamps, params.Start, params.End),
+			extractor:  extractor.ForStream(f.labels),
+		})
+	}
+	return iter.NewSortSampleIterator(its), nil
+}
+
+// localLines reads the lines of a file in the [from, through) time range.
+type localLines struct {
+	file          localFile
+	timestamps    *util.TimestampParser
+	from, through int64
+	backward      bool
+
+	f      *os.File
+	r      *bufio.Reader
+	closer func()
+	eof    bool
+	prev   int64
+
+	// The lines read backward, without timestamp yet, and the decompressed file they are read from.
+	rr        *reverseLineReader
+	tmp       *os.File
+	pending   []string
+	pendingTs int64
+	resolved  bool
+
+	line string
+	ts   int64
+	err  error
+}
+
+func newLocalLines(f localFile, timestamps *util.TimestampParser, from, through time.Time) *localLines {
+	return &localLines{
+		file:       f,
+		timestamps: timestamps,
+		from:       from.UnixNano(),
+		through:    through.UnixNano(),
+		closer:     func() {},
+	}
+}
+
+func (l *localLines) open() error {
+	f, err := os.Open(l.file.path)
+	if err != nil {
+		return err
+	}
+	l.f = f
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	// Lines without timestamp get the one of the line before, and the first ones the modification time of the file.
+	l.prev = info.ModTime().UnixNano()
+
+	br := bufio.NewReader(f)
+	magic, _ := br.Peek(len(zstdMagic))
+	var decompressed io.Reader
+	switch {
+	case bytes.HasPrefix(magic, gzipMagic):
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return fmt.Errorf("failed to read gzip file %s: %w", l.file.path, err)
+		}
+		decompressed, l.closer = gz, func() { gz.Close() }
+	case bytes.HasPrefix(magic, zstdMagic):
+		zr, err := zstd.NewReader(br)
+		if err != nil {
+			return fmt.Errorf("failed to read zstd file %s: %w", l.file.path, err)
+		}
+		decompressed, l.closer = zr, zr.Close
+	}
+
+	if l.backward {
+		return l.openBackward(decompressed, info.Size())
+	}
+	if decompressed != nil {
+		l.r = bufio.NewReader(decompressed)
+	} else {
+		l.r = br
+	}
+	return nil
+}
+
+// openBackward prepares reading the file from its end. Compressed files can't be read from their end, so
+// they are decompressed into a temporary file first.
+func (l *localLines) openBackward(decompressed io.Reader, size int64) error {
+	if decompressed == nil {
+		l.rr = newReverseLineReader(l.f, size)
+		return nil
+	}
+
+	tmp, err := os.CreateTemp("", "logcli-local-*")
+	if err != nil {
+		return fmt.Errorf("failed to decompress file %s: %w", l.file.path, err)
+	}
+	l.tmp = tmp
+	if size, err = io.Copy(tmp, decompressed); err != nil {
+		return fmt.Errorf("failed to decompress file %s: %w", l.file.path, err)
+	}
+	l.rr = newReverseLineReader(tmp, size)
+	return nil
+}
+
+func (l *localLines) next() bool {
+	if l.f == nil && l.err == nil {
+		l.err = l.open()
+	}
+	if l.backward {
+		return l.nextBackward()
+	}
+	for l.err == nil && !l.eof {
+		line, err := l.r.ReadString('\n')
+		if err == io.EOF {
+			l.eof = true
+			if line == "" {
+				break
+			}
+		} else if err != nil {
+			l.err = fmt.Errorf("failed to read file %s: %w", l.file.path, err)
+			break
+		}
+		line = strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+
+		ts, ok := l.timestamps.Timestamp(line)
+		if !ok {
+			ts = l.prev
+		}
+		l.prev = ts
+		if ts < l.from || ts >= l.through {
+			continue
+		}
+		l.line, l.ts = line, ts
+		return true
+	}
+	return false
+}
+
+// nextBackward reads the line before the previous one. Lines without timestamp get the one of the line before
+// them in the file, so they are kept until it is read.
+func (l *localLines) nextBackward() bool {
+	for l.err == nil {
+		if l.resolved {
+			for len(l.pending) > 0 {
+				line := l.pending[0]
+				l.pending = l.pending[1:]
+				if l.pendingTs < l.from || l.pendingTs >= l.through {
+					continue
+				}
+				l.line, l.ts = line, l.pendingTs
+				return true
+			}
+			l.pending, l.resolved = l.pending[:0], false
+		}
+
+		line, err := l.rr.readLine()
+		if err == io.EOF {
+			if len(l.pending) == 0 {
+				return false
+			}
+			// The first lines of the file get its modification time.
+			l.pendingTs, l.resolved = l.prev, true
+			continue
+		} else if err != nil {
+			l.err = fmt.Errorf("failed to read file %s: %w", l.file.path, err)
+			break
+		}
+		line = strings.TrimSuffix(line, "\r")
+
+		l.pending = append(l.pending, line)
+		if ts, ok := l.timestamps.Timestamp(line); ok {
+			l.pendingTs, l.resolved = ts, true
+		}
+	}
+	return false
+}
+
+func (l *localLines) Labels() string { return l.file.labels.String() }
+
+func (l *localLines) StreamHash() uint64 { return l.file.labels.Hash() }
+
+func (l *localLines) Error() error { return l.err }
+
+func (l *localLines) Close() error {
+	if l.f == nil {
+		return nil
+	}
+	l.closer()
+	if l.tmp != nil {
+		_ = l.tmp.Close()
+		_ = os.Remove(l.tmp.Name())
+	}
+	return l.f.Close()
+}
+
+// reverseLineReader reads the lines of a file from its end, a block at a time.
+type reverseLineReader struct {
+	r       io.ReaderAt
+	off     int64
+	buf     []byte
+	started bool
+	done    bool
+}
+
+func newReverseLineReader(r io.ReaderAt, size int64) *reverseLineReader {
+	return &reverseLineReader{r: r, off: size, done: size == 0}
+}
+
+// readLine returns the line before the one returned last, and io.EOF once the first line of the file
+// was returned.
+func (r *reverseLineReader) readLine() (string, error) {
+	for {
+		if i := bytes.LastIndexByte(r.buf, '\n'); i >= 0 {
+			line := string(r.buf[i+1:])
+			r.buf = r.buf[:i]
+			return line, nil
+		}
+		if r.off == 0 {
+			if r.done {
+				return "", io.EOF
+			}
+			line := string(r.buf)
+			r.buf, r.done = nil, true
+			return line, nil
+		}
+
+		n := int64(reverseReadSize)
+		if n > r.off {
+			n = r.off
+		}
+		block := make([]byte, n, n+int64(len(r.buf)))
+		if _, err := r.r.ReadAt(block, r.off-n); err != nil && err != io.EOF {
+			return "", err
+		}
+		r.off -= n
+		// Like when reading forward, the newline ending the file doesn't start an empty line.
+		if !r.started {
+			block, r.started = bytes.TrimSuffix(block, []byte{'\n'}), true
+		}
+		r.buf = append(block, r.buf...)
+	}
+}
+
+type localEntryIterator struct {
+	*localLines
+	pipeline logqllog.StreamPipeline
+
+	cur    logproto.Entry
+	labels string
+}
+
+func (it *localEntryIterator) Next() bool {
+	for it.next() {
+		line, lbs, ok := it.pipeline.ProcessString(it.ts, it.line)
+		if !ok {
+			continue
+		}
+		it.cur = logproto.Entry{Timestamp: time.Unix(0, it.ts), Line: line}
+		it.labels = lbs.String()
+		return true
+	}
+	return false
+}
+
+func (it *localEntryIterator) Entry() logproto.Entry { return it.cur }
+
+func (it *localEntryIterator) Labels() string { return it.labels }
+
+type localSampleIterator struct {
+	*localLines
+	extractor logqllog.StreamSampleExtractor
+
+	cur    logproto.Sample
+	labels string
+}
+
+func (it *localSampleIterator) Next() bool {
+	for it.next() {
+		value, lbs, ok := it.extractor.ProcessString(it.ts, it.line)
+		if !ok {
+			continue
+		}
+		it.cur = logproto.Sample{Timestamp: it.ts, Value: value, Hash: xxhash.Sum64String(it.line)}
+		it.labels = lbs.String()
+		return true
+	}
+	return false
+}
+
+func (it *localSampleIterator) Sample() logproto.Sample { return it.cur }
+
+func (it *localSampleIterator) Labels() string { return it.labels }
@@ -0,0 +1,224 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/loghttp"
+	"github.com/grafana/loki/v3/pkg/logproto"
+)
+
+func writeLocalFile(t *testing.T, path, compression string, lines ...string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+
+	content := []byte(strings.Join(lines, "\n") + "\n")
+	var buf bytes.Buffer
+	switch compression {
+	case "gzip":
+		w := gzip.NewWriter(&buf)
+		_, err := w.Write(content)
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+	case "zstd":
+		w, err := zstd.NewWriter(&buf)
+		require.NoError(t, err)
+		_, err = w.Write(content)
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+	default:
+		buf.Write(content)
+	}
+	require.NoError(t, os.WriteFile(path, buf.Bytes(), 0o644))
+}
+
+func newTestLocalClient(t *testing.T) (*LocalClient, string) {
+	dir := t.TempDir()
+	writeLocalFile(t, filepath.Join(dir, "api", "api-0.log"), "",
+		`2024-01-01T10:00:00Z level=info msg="started"`,
+		`2024-01-01T10:01:00Z level=error msg="request failed"`,
+		`  at handler.go:12`,
+		`2024-01-01T10:03:00Z level=info msg="done"`,
+	)
+	writeLocalFile(t, filepath.Join(dir, "api", "api-1.log.gz"), "gzip",
+		`2024-01-01T10:02:00Z level=error msg="timeout"`,
+	)
+	writeLocalFile(t, filepath.Join(dir, "db", "db-0.log.zst"), "zstd",
+		`2024-01-01T10:00:30Z level=info msg="ready"`,
+	)
+
+	c, err := NewLocalClient(LocalConfig{
+		Paths:           []string{dir},
+		PathTemplate:    filepath.Join(dir, "{app}", "{pod}.log{ext}"),
+		TimestampFormat: "RFC3339",
+		Location:        time.UTC,
+	})
+	require.NoError(t, err)
+	return c, dir
+}
+
+func TestLocalClient_QueryRange(t *testing.T) {
+	c, _ := newTestLocalClient(t)
+	start, end := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC), time.Date(2024, 1, 1, 11, 0, 0, 0, time.UTC)
+
+	t.Run("logs", func(t *testing.T) {
+		resp, err := c.QueryRange(`{app="api"} |= "level=error"`, 10, start, end, logproto.FORWARD, 0, 0, "", true)
+		require.NoError(t, err)
+		require.Equal(t, loghttp.ResultTypeStream, string(resp.Data.ResultType))
+
+		var lines []string
+		for _, s := range resp.Data.Result.(loghttp.Streams) {
+			assert.Equal(t, "api", s.Labels["app"])
+			for _, e := range s.Entries {
+				lines = append(lines, e.Line)
+			}
+		}
+		assert.ElementsMatch(t, []string{
+			`2024-01-01T10:01:00Z level=error msg="request failed"`,
+			`2024-01-01T10:02:00Z level=error msg="timeout"`,
+		}, lines)
+	})
+
+	t.Run("lines without timestamp", func(t *testing.T) {
+		resp, err := c.QueryRange(`{pod="api-0"} |= "handler.go"`, 10, start, end, logproto.BACKWARD, 0, 0, "", true)
+		require.NoError(t, err)
+		streams := resp.Data.Result.(loghttp.Streams)
+		require.Len(t, streams, 1)
+		require.Len(t, streams[0].Entries, 1)
+		assert.Equal(t, time.Date(2024, 1, 1, 10, 1, 0, 0, time.UTC), streams[0].Entries[0].Timestamp.UTC())
+	})
+
+	t.Run("backward", func(t *testing.T) {
+		resp, err := c.QueryRange(`{filename=~".+"}`, 10, start, end, logproto.BACKWARD, 0, 0, "", true)
+		require.NoError(t, err)
+
+		var entries []loghttp.Entry
+		for _, s := range resp.Data.Result.(loghttp.Streams) {
+			entries = append(entries, s.Entries...)
+		}
+		sort.SliceStable(entries, func(i, j int) bool { return entries[i].Timestamp.After(entries[j].Timestamp) })
+		var lines []string
+		for _, e := range entries {
+			lines = append(lines, e.Line)
+		}
+		assert.Equal(t, []string{
+			`2024-01-01T10:03:00Z level=info msg="done"`,
+			`2024-01-01T10:02:00Z level=error msg="timeout"`,
+			`  at handler.go:12`,
+			`2024-01-01T10:01:00Z level=error msg="request failed"`,
+			`2024-01-01T10:00:30Z level=info msg="ready"`,
+			`2024-01-01T10:00:00Z level=info msg="started"`,
+		}, lines)
+	})
+
+	t.Run("metrics", func(t *testing.T) {
+		resp, err := c.QueryRange(`sum by (app) (count_over_time({filename=~".+"} | logfmt | level="error" [1h]))`, 10, end, end, logproto.FORWARD, time.Minute, 0, "", true)
+		require.NoError(t, err)
+		require.Equal(t, loghttp.ResultTypeMatrix, string(resp.Data.ResultType))
+		matrix := resp.Data.Result.(loghttp.Matrix)
+		require.Len(t, matrix, 1)
+		assert.Equal(t, "api", string(matrix[0].Metric["app"]))
+		require.Len(t, matrix[0].Values, 1)
+		assert.Equal(t, float64(2), float64(matrix[0].Values[0].Value))
+	})
+}
+
+func TestLocalClient_MaxOpenFiles(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.log", "b.log", "c.log"} {
+		writeLocalFile(t, filepath.Join(dir, name), "", `2024-01-01T10:00:00Z msg="hello"`)
+	}
+	c, err := NewLocalClient(LocalConfig{
+		Paths:           []string{dir},
+		TimestampFormat: "RFC3339",
+		Location:        time.UTC,
+		MaxOpenFiles:    2,
+	})
+	require.NoError(t, err)
+	start, end := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC), time.Date(2024, 1, 1, 11, 0, 0, 0, time.UTC)
+
+	_, err = c.QueryRange(`{filename=~".+"}`, 10, start, end, logproto.FORWARD, 0, 0, "", true)
+	require.ErrorContains(t, err, "the query selects 3 files, more than the 2 files that can be open at once")
+
+	resp, err := c.QueryRange(`{filename=~".+/[ab].log"}`, 10, start, end, logproto.FORWARD, 0, 0, "", true)
+	require.NoError(t, err)
+	require.Len(t, resp.Data.Result.(loghttp.Streams), 2)
+}
+
+func TestReverseLineReader(t *testing.T) {
+	long := strings.Repeat("x", reverseReadSize+10)
+	var many []string
+	for i := 0; i < 10000; i++ {
+		many = append(many, fmt.Sprintf("line %d", i))
+	}
+
+	for name, content := range map[string]string{
+		"empty":                "",
+		"newline":              "\n",
+		"without last newline": "a\nb",
+		"empty lines":          "a\n\n\nb\n",
+		"across blocks":        strings.Join(many, "\n") + "\n",
+		"longer than a block":  "a\n" + long + "\nb\n" + long,
+	} {
+		t.Run(name, func(t *testing.T) {
+			// The lines read forward, reversed.
+			var expected []string
+			br := bufio.NewReader(strings.NewReader(content))
+			for {
+				line, err := br.ReadString('\n')
+				if line != "" {
+					expected = append([]string{strings.TrimSuffix(line, "\n")}, expected...)
+				}
+				if err != nil {
+					break
+				}
+			}
+
+			var lines []string
+			r := newReverseLineReader(strings.NewReader(content), int64(len(content)))
+			for {
+				line, err := r.readLine()
+				if err == io.EOF {
+					break
+				}
+				require.NoError(t, err)
+				lines = append(lines, line)
+			}
+			require.Equal(t, expected, lines)
+		})
+	}
+}
+
+func TestLocalClient_Labels(t *testing.T) {
+	c, dir := newTestLocalClient(t)
+
+	names, err := c.ListLabelNames(true, time.Time{}, time.Time{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"app", "ext", FilenameLabel, "pod"}, names.Data)
+
+	values, err := c.ListLabelValues("pod", true, time.Time{}, time.Time{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"api-0", "api-1", "db-0"}, values.Data)
+
+	series, err := c.Series([]string{`{app="db"}`}, time.Time{}, time.Time{}, true)
+	require.NoError(t, err)
+	assert.Equal(t, []loghttp.LabelSet{{
+		"app":         "db",
+		"ext":         ".zst",
+		"pod":         "db-0",
+		FilenameLabel: filepath.Join(dir, "db", "db-0.log.zst"),
+	}}, series.Data)
+}
@@ -37,6 +37,10 @@ type LogOutputOptions struct {
 	Timezone      *time.Location
 	NoLabels      bool
 	ColoredOutput bool
+	// Columns are the labels printed as columns by the tabular outputs.
+	Columns []string
+	// Wide makes the tabular outputs print metric results with a row per timestamp and a column per series.
+	Wide bool
 }
 
 // NewLogOutput creates a log output based on the input mode and options
@@ -61,6 +65,8 @@ func NewLogOutput(w io.Writer, mode string, options *LogOutputOptions) (LogOutpu
 			w:       w,
 			options: options,
 		}, nil
+	case FormatTable, FormatCSV, FormatTSV:
+		return newTabularOutput(w, mode, options), nil
 	default:
 		return nil, fmt.Errorf("unknown log output mode '%s'", mode)
 	}
@@ -8,7 +8,7 @@ import (
 )
 
 func TestNewLogOutput(t *testing.T) {
-	options := &LogOutputOptions{time.UTC, false, false}
+	options := &LogOutputOptions{Timezone: time.UTC}
 
 	out, err := NewLogOutput(nil, "default", options)
 	assert.NoError(t, err)
@@ -22,6 +22,12 @@ func TestNewLogOutput(t *testing.T) {
 	assert.NoError(t, err)
 	assert.IsType(t, &RawOutput{nil, options}, out)
 
+	for _, mode := range []string{"table", "csv", "tsv"} {
+		out, err = NewLogOutput(nil, mode, options)
+		assert.NoError(t, err)
+		assert.IsType(t, &TabularOutput{}, out)
+	}
+
 	out, err = NewLogOutput(nil, "unknown", options)
 	assert.Error(t, err)
 	assert.Nil(t, out)
@@ -0,0 +1,269 @@
+package output
+
+import (
+	"encoding/csv"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/loki/v3/pkg/loghttp"
+)
+
+// Tabular output formats
+const (
+	FormatTable = "table"
+	FormatCSV   = "csv"
+	FormatTSV   = "tsv"
+)
+
+// MetricOutput is implemented by the outputs printing metric results themselves, the others print them as JSON.
+type MetricOutput interface {
+	PrintMatrix(matrix loghttp.Matrix)
+	PrintVector(vector loghttp.Vector)
+	PrintScalar(scalar loghttp.Scalar)
+}
+
+// Flusher is implemented by the outputs buffering what they print, which must be flushed once a result is printed.
+type Flusher interface {
+	Flush()
+}
+
+// TabularOutput prints logs and metric results as rows with a header, aligned in a table or separated by commas
+// or tabs, suitable for spreadsheets and tools like awk.
+//
+// Log lines have a timestamp column, a column per label of Columns, or a single labels column without Columns, and
+// a line column. Metric results have a row per series and timestamp, with a column per label, or a row per
+// timestamp with a column per series in wide format.
+type TabularOutput struct {
+	w       io.Writer
+	options *LogOutputOptions
+	format  string
+
+	table         *tabwriter.Writer
+	csv           *csv.Writer
+	headerPrinted bool
+}
+
+func newTabularOutput(w io.Writer, format string, options *LogOutputOptions) *TabularOutput {
+	o := &TabularOutput{
+		w:       w,
+		options: options,
+		format:  format,
+	}
+	switch format {
+	case FormatTable:
+		o.table = tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	case FormatCSV:
+		o.csv = csv.NewWriter(w)
+	}
+	return o
+}
+
+// FormatAndPrintln prints a log entry as a row, after the header if it's the first one.
+func (o *TabularOutput) FormatAndPrintln(ts time.Time, lbls loghttp.LabelSet, _ int, line string) {
+	if !o.headerPrinted {
+		header := []string{"timestamp"}
+		switch {
+		case len(o.options.Columns) > 0:
+			header = append(header, o.options.Columns...)
+		case !o.options.NoLabels:
+			header = append(header, "labels")
+		}
+		o.printRow(append(header, "line"))
+		o.headerPrinted = true
+	}
+
+	row := []string{o.formatTime(ts)}
+	switch {
+	case len(o.options.Columns) > 0:
+		for _, name := range o.options.Columns {
+			row = append(row, lbls[name])
+		}
+	case !o.options.NoLabels:
+		row = append(row, lbls.String())
+	}
+	o.printRow(append(row, strings.TrimSuffix(line, "\n")))
+}
+
+// WithWriter returns a copy of the LogOutput with the writer set to the given writer
+func (o TabularOutput) WithWriter(w io.Writer) LogOutput {
+	return newTabularOutput(w, o.format, o.options)
+}
+
+// PrintMatrix prints the samples of the matrix, ordered by timestamp.
+func (o *TabularOutput) PrintMatrix(matrix loghttp.Matrix) {
+	if o.options.Wide {
+		series := make([]string, 0, len(matrix))
+		values := map[model.Time][]string{}
+		for i, s := range matrix {
+			series = append(series, s.Metric.String())
+			for _, v := range s.Values {
+				if _, ok := values[v.Timestamp]; !ok {
+					values[v.Timestamp] = make([]string, len(matrix))
+				}
+				values[v.Timestamp][i] = formatValue(float64(v.Value))
+			}
+		}
+		o.printRow(append([]string{"timestamp"}, series...))
+		for _, t := range sortedTimes(values) {
+			o.printRow(append([]string{o.formatTime(t.Time())}, values[t]...))
+		}
+		o.Flush()
+		return
+	}
+
+	sets := make([]loghttp.LabelSet, 0, len(matrix))
+	for _, s := range matrix {
+		sets = append(sets, metricLabels(s.Metric))
+	}
+	names := o.labelColumns(sets)
+	type sample struct {
+		t      time.Time
+		labels []string
+		value  string
+	}
+	var samples []sample
+	for i, s := range matrix {
+		labels := labelValues(sets[i], names)
+		for _, v := range s.Values {
+			samples = append(samples, sample{t: v.Timestamp.Time(), labels: labels, value: formatValue(float64(v.Value))})
+		}
+	}
+	// Samples of the same timestamp keep the order of their series.
+	sort.SliceStable(samples, func(i, j int) bool { return samples[i].t.Before(samples[j].t) })
+
+	o.printRow(append(append([]string{"timestamp"}, names...), "value"))
+	for _, s := range samples {
+		o.printRow(append(append([]string{o.formatTime(s.t)}, s.labels...), s.value))
+	}
+	o.Flush()
+}
+
+// PrintVector prints the samples of the vector.
+func (o *TabularOutput) PrintVector(vector loghttp.Vector) {
+	if o.options.Wide {
+		header, row := []string{"timestamp"}, []string{""}
+		for _, s := range vector {
+			header = append(header, s.Metric.String())
+			row = append(row, formatValue(float64(s.Value)))
+			row[0] = o.formatTime(s.Timestamp.Time())
+		}
+		o.printRow(header)
+		if len(vector) > 0 {
+			o.printRow(row)
+		}
+		o.Flush()
+		return
+	}
+
+	sets := make([]loghttp.LabelSet, 0, len(vector))
+	for _, s := range vector {
+		sets = append(sets, metricLabels(s.Metric))
+	}
+	names := o.labelColumns(sets)
+	o.printRow(append(append([]string{"timestamp"}, names...), "value"))
+	for i, s := range vector {
+		labels := labelValues(sets[i], names)
+		o.printRow(append(append([]string{o.formatTime(s.Timestamp.Time())}, labels...), formatValue(float64(s.Value))))
+	}
+	o.Flush()
+}
+
+// PrintScalar prints the scalar as a single row.
+func (o *TabularOutput) PrintScalar(scalar loghttp.Scalar) {
+	o.printRow([]string{"timestamp", "value"})
+	o.printRow([]string{o.formatTime(scalar.Timestamp.Time()), formatValue(float64(scalar.Value))})
+	o.Flush()
+}
+
+// Flush writes the buffered rows, the rows of a table are aligned with each other until flushed.
+func (o *TabularOutput) Flush() {
+	switch {
+	case o.table != nil:
+		_ = o.table.Flush()
+	case o.csv != nil:
+		o.csv.Flush()
+	}
+}
+
+func (o *TabularOutput) printRow(cells []string) {
+	switch o.format {
+	case FormatCSV:
+		_ = o.csv.Write(cells)
+	case FormatTSV:
+		_, _ = io.WriteString(o.w, joinEscaped(cells)+"\n")
+	case FormatTable:
+		_, _ = io.WriteString(o.table, joinEscaped(cells)+"\n")
+	}
+}
+
+func (o *TabularOutput) formatTime(t time.Time) string {
+	return t.In(o.options.Timezone).Format(time.RFC3339Nano)
+}
+
+// labelColumns returns the Columns option, or the sorted names of all the labels of the series.
+func (o *TabularOutput) labelColumns(series []loghttp.LabelSet) []string {
+	if len(o.options.Columns) > 0 {
+		return o.options.Columns
+	}
+	if o.options.NoLabels {
+		return nil
+	}
+	seen := map[string]struct{}{}
+	var names []string
+	for _, ls := range series {
+		for name := range ls {
+			if _, ok := seen[name]; !ok {
+				seen[name] = struct{}{}
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func metricLabels(m model.Metric) loghttp.LabelSet {
+	ls := make(loghttp.LabelSet, len(m))
+	for name, value := range m {
+		ls[string(name)] = string(value)
+	}
+	return ls
+}
+
+func labelValues(ls loghttp.LabelSet, names []string) []string {
+	values := make([]string, 0, len(names))
+	for _, name := range names {
+		values = append(values, ls[name])
+	}
+	return values
+}
+
+func sortedTimes(values map[model.Time][]string) []model.Time {
+	times := make([]model.Time, 0, len(values))
+	for t := range values {
+		times = append(times, t)
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+	return times
+}
+
+func formatValue(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+var cellEscaper = strings.NewReplacer(`\`, `\\`, "\t", `\t`, "\n", `\n`, "\r", `\r`)
+
+// joinEscaped joins the cells with tabs, escaping the tabs and new lines of the cells.
+func joinEscaped(cells []string) string {
+	escaped := make([]string, 0, len(cells))
+	for _, c := range cells {
+		escaped = append(escaped, cellEscaper.Replace(c))
+	}
+	return strings.Join(escaped, "\t")
+}
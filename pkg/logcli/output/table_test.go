@@ -0,0 +1,155 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/grafana/loki/v3/pkg/loghttp"
+)
+
+func TestTabularOutput_FormatAndPrintln(t *testing.T) {
+	t.Parallel()
+
+	timestamp, _ := time.Parse(time.RFC3339, "2006-01-02T15:04:05+07:00")
+	lbls := loghttp.LabelSet{"app": "api", "level": "error"}
+
+	tests := map[string]struct {
+		format   string
+		options  *LogOutputOptions
+		expected string
+	}{
+		"csv with labels": {
+			FormatCSV,
+			&LogOutputOptions{Timezone: time.UTC},
+			"timestamp,labels,line\n" +
+				`2006-01-02T08:04:05Z,"{app=""api"", level=""error""}","msg=""failed"", code=500"` + "\n",
+		},
+		"csv with columns": {
+			FormatCSV,
+			&LogOutputOptions{Timezone: time.UTC, Columns: []string{"level", "pod"}},
+			"timestamp,level,pod,line\n" +
+				`2006-01-02T08:04:05Z,error,,"msg=""failed"", code=500"` + "\n",
+		},
+		"tsv without labels": {
+			FormatTSV,
+			&LogOutputOptions{Timezone: time.UTC, NoLabels: true},
+			"timestamp\tline\n" +
+				"2006-01-02T08:04:05Z\tmsg=\"failed\", code=500\n",
+		},
+		"table with columns": {
+			FormatTable,
+			&LogOutputOptions{Timezone: time.UTC, Columns: []string{"app"}},
+			"timestamp             app  line\n" +
+				"2006-01-02T08:04:05Z  api  msg=\"failed\", code=500\n",
+		},
+	}
+
+	for testName, testData := range tests {
+		testData := testData
+
+		t.Run(testName, func(t *testing.T) {
+			t.Parallel()
+
+			writer := &bytes.Buffer{}
+			out, err := NewLogOutput(writer, testData.format, testData.options)
+			assert.NoError(t, err)
+			out.FormatAndPrintln(timestamp, lbls, 0, `msg="failed", code=500`)
+			out.(Flusher).Flush()
+
+			assert.Equal(t, testData.expected, writer.String())
+		})
+	}
+}
+
+func TestTabularOutput_EscapesTSV(t *testing.T) {
+	writer := &bytes.Buffer{}
+	out, _ := NewLogOutput(writer, FormatTSV, &LogOutputOptions{Timezone: time.UTC, NoLabels: true})
+	out.FormatAndPrintln(time.Unix(0, 0), nil, 0, "a\tb\\c\nd")
+
+	assert.Equal(t, "timestamp\tline\n1970-01-01T00:00:00Z\ta\\tb\\\\c\\nd\n", writer.String())
+}
+
+func TestTabularOutput_Metrics(t *testing.T) {
+	matrix := loghttp.Matrix{
+		{
+			Metric: model.Metric{"app": "api"},
+			Values: []model.SamplePair{{Timestamp: 0, Value: 1}, {Timestamp: 60000, Value: 2.5}},
+		},
+		{
+			Metric: model.Metric{"app": "db", "level": "error"},
+			Values: []model.SamplePair{{Timestamp: 60000, Value: 3}},
+		},
+	}
+	vector := loghttp.Vector{
+		{Metric: model.Metric{"app": "api"}, Timestamp: 60000, Value: 2.5},
+		{Metric: model.Metric{"app": "db"}, Timestamp: 60000, Value: 3},
+	}
+
+	for name, tc := range map[string]struct {
+		format  string
+		options *LogOutputOptions
+		print   func(MetricOutput)
+		expect  string
+	}{
+		"matrix csv": {
+			format:  FormatCSV,
+			options: &LogOutputOptions{Timezone: time.UTC},
+			print:   func(o MetricOutput) { o.PrintMatrix(matrix) },
+			expect: "timestamp,app,level,value\n" +
+				"1970-01-01T00:00:00Z,api,,1\n" +
+				"1970-01-01T00:01:00Z,api,,2.5\n" +
+				"1970-01-01T00:01:00Z,db,error,3\n",
+		},
+		"matrix wide csv": {
+			format:  FormatCSV,
+			options: &LogOutputOptions{Timezone: time.UTC, Wide: true},
+			print:   func(o MetricOutput) { o.PrintMatrix(matrix) },
+			expect: `timestamp,"{app=""api""}","{app=""db"", level=""error""}"` + "\n" +
+				"1970-01-01T00:00:00Z,1,\n" +
+				"1970-01-01T00:01:00Z,2.5,3\n",
+		},
+		"matrix table with columns": {
+			format:  FormatTable,
+			options: &LogOutputOptions{Timezone: time.UTC, Columns: []string{"level"}},
+			print:   func(o MetricOutput) { o.PrintMatrix(matrix) },
+			expect: "timestamp             level  value\n" +
+				"1970-01-01T00:00:00Z         1\n" +
+				"1970-01-01T00:01:00Z         2.5\n" +
+				"1970-01-01T00:01:00Z  error  3\n",
+		},
+		"vector tsv": {
+			format:  FormatTSV,
+			options: &LogOutputOptions{Timezone: time.UTC},
+			print:   func(o MetricOutput) { o.PrintVector(vector) },
+			expect: "timestamp\tapp\tvalue\n" +
+				"1970-01-01T00:01:00Z\tapi\t2.5\n" +
+				"1970-01-01T00:01:00Z\tdb\t3\n",
+		},
+		"vector wide tsv": {
+			format:  FormatTSV,
+			options: &LogOutputOptions{Timezone: time.UTC, Wide: true},
+			print:   func(o MetricOutput) { o.PrintVector(vector) },
+			expect: "timestamp\t{app=\"api\"}\t{app=\"db\"}\n" +
+				"1970-01-01T00:01:00Z\t2.5\t3\n",
+		},
+		"scalar csv": {
+			format:  FormatCSV,
+			options: &LogOutputOptions{Timezone: time.UTC},
+			print:   func(o MetricOutput) { o.PrintScalar(loghttp.Scalar{Timestamp: 60000, Value: 42}) },
+			expect:  "timestamp,value\n1970-01-01T00:01:00Z,42\n",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			writer := &bytes.Buffer{}
+			out, err := NewLogOutput(writer, tc.format, tc.options)
+			assert.NoError(t, err)
+			tc.print(out.(MetricOutput))
+
+			assert.Equal(t, tc.expect, writer.String())
+		})
+	}
+}
@@ -44,23 +44,43 @@ type streamEntryPair struct {
 func (r *QueryResultPrinter) PrintResult(value loghttp.ResultValue, out output.LogOutput, lastEntry []*loghttp.Entry) (int, []*loghttp.Entry) {
 	length := -1
 	var entry []*loghttp.Entry
+	metrics, printsMetrics := out.(output.MetricOutput)
 	switch value.Type() {
 	case logqlmodel.ValueTypeStreams:
 		length, entry = r.printStream(value.(loghttp.Streams), out, lastEntry)
 	case loghttp.ResultTypeScalar:
+		if printsMetrics {
+			metrics.PrintScalar(value.(loghttp.Scalar))
+			break
+		}
 		printScalar(value.(loghttp.Scalar))
 	case loghttp.ResultTypeMatrix:
+		if printsMetrics {
+			metrics.PrintMatrix(value.(loghttp.Matrix))
+			break
+		}
 		printMatrix(value.(loghttp.Matrix))
 	case loghttp.ResultTypeVector:
+		if printsMetrics {
+			metrics.PrintVector(value.(loghttp.Vector))
+			break
+		}
 		printVector(value.(loghttp.Vector))
 	default:
 		log.Fatalf("Unable to print unsupported type: %v", value.Type())
 	}
+	if f, ok := out.(output.Flusher); ok {
+		f.Flush()
+	}
 	return length, entry
 }
 
 func (r *QueryResultPrinter) printStream(streams loghttp.Streams, out output.LogOutput, lastEntry []*loghttp.Entry) (int, []*loghttp.Entry) {
 	common := commonLabels(streams)
+	// Every row of tabular outputs has all its labels, to be self-contained.
+	if _, ok := out.(*output.TabularOutput); ok {
+		common = nil
+	}
 
 	// Remove the labels we want to show from common
 	if len(r.ShowLabelsKey) > 0 {
@@ -165,6 +185,33 @@ func (r *QueryResultPrinter) printStream(streams loghttp.Streams, out output.Log
 	return printed, lel
 }
 
+// PrintExportedEntry prints an entry streamed by the export API. The entries are printed as they
+// are received, so the labels common to all the streams are not known and not removed.
+func (r *QueryResultPrinter) PrintExportedEntry(line loghttp.ExportLine, out output.LogOutput) error {
+	ts, err := line.Time()
+	if err != nil {
+		return err
+	}
+
+	ls := line.Stream
+	if len(r.ShowLabelsKey) > 0 {
+		ls = matchLabels(true, ls, r.ShowLabelsKey)
+	}
+	if len(r.IgnoreLabelsKey) > 0 {
+		ls = matchLabels(false, ls, r.IgnoreLabelsKey)
+	}
+
+	maxLabelsLen := r.FixedLabelsLen
+	if length := len(ls.String()); maxLabelsLen < length {
+		maxLabelsLen = length
+	}
+	out.FormatAndPrintln(ts, ls, maxLabelsLen, line.Line)
+	if f, ok := out.(output.Flusher); ok {
+		f.Flush()
+	}
+	return nil
+}
+
 func printMatrix(matrix loghttp.Matrix) {
 	// yes we are effectively unmarshalling and then immediately marshalling this object back to json.  we are doing this b/c
 	// it gives us more flexibility with regard to output types in the future.  initially we are supporting just formatted json but eventually
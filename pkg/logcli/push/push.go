@@ -0,0 +1,298 @@
+package push
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/grafana/jsonparser"
+	"github.com/prometheus/prometheus/model/labels"
+
+	"github.com/grafana/loki/v3/pkg/logcli/client"
+	"github.com/grafana/loki/v3/pkg/logcli/util"
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/logql/log/logfmt"
+	"github.com/grafana/loki/v3/pkg/logql/syntax"
+)
+
+// StdinPath is the path reading the lines from stdin.
+const StdinPath = "-"
+
+const progressInterval = time.Second
+
+// Push contains all the fields to push log lines to Loki.
+type Push struct {
+	// Labels are the labels of the pushed streams, e.g. {job="import"}.
+	Labels string
+	// Paths are the files to push, StdinPath for stdin. The lines of a file have a filename label.
+	Paths []string
+	// BatchSize is the maximum size in bytes of the lines of a push request.
+	BatchSize int
+	// TimestampFormat and TimestampRegexp configure the parsing of the timestamps of the lines, see
+	// util.NewTimestampParser. Without format, lines are timestamped with the time they are read at.
+	TimestampFormat string
+	TimestampRegexp string
+	Location        *time.Location
+	// Format is the format of the lines, json or logfmt, to extract the structured metadata from.
+	Format string
+	// StructuredMetadata maps the names of the structured metadata to the fields of the lines holding them.
+	StructuredMetadata map[string]string
+	Quiet              bool
+}
+
+// Stats are the statistics of a push.
+type Stats struct {
+	Lines       int
+	Bytes       int
+	Requests    int
+	FailedLines int
+}
+
+// DoPush pushes the lines and prints the progress to stderr.
+func (p *Push) DoPush(c client.Client) {
+	stats, err := p.Run(c, os.Stdin, os.Stderr)
+	if err != nil {
+		log.Fatalf("Error pushing lines: %s", err)
+	}
+	if stats.FailedLines > 0 {
+		log.Fatalf("%d of %d lines failed to be pushed", stats.FailedLines, stats.Lines)
+	}
+}
+
+// Run pushes the lines of the paths, reading stdin for StdinPath, and writes the progress to progress unless
+// quiet. Requests failing after the retries of the client are counted in the stats and the push goes on.
+func (p *Push) Run(c client.Client, stdin io.Reader, progress io.Writer) (Stats, error) {
+	lbs, err := syntax.ParseLabels(p.Labels)
+	if err != nil {
+		return Stats{}, fmt.Errorf("invalid labels %s: %w", p.Labels, err)
+	}
+	ts, err := util.NewTimestampParser(p.TimestampFormat, p.TimestampRegexp, p.Location)
+	if err != nil {
+		return Stats{}, err
+	}
+	fields, err := newFieldExtractor(p.Format, p.StructuredMetadata)
+	if err != nil {
+		return Stats{}, err
+	}
+	if p.Quiet {
+		progress = io.Discard
+	}
+
+	b := &batcher{
+		client:     c,
+		size:       p.BatchSize,
+		streams:    map[string]*logproto.Stream{},
+		progress:   progress,
+		start:      time.Now(),
+		lastReport: time.Now(),
+	}
+
+	paths := p.Paths
+	if len(paths) == 0 {
+		paths = []string{StdinPath}
+	}
+	for _, path := range paths {
+		streamLabels := lbs
+		r := stdin
+		if path != StdinPath {
+			builder := labels.NewBuilder(lbs)
+			builder.Set(client.FilenameLabel, path)
+			streamLabels = builder.Labels()
+
+			f, err := os.Open(path)
+			if err != nil {
+				return b.stats, err
+			}
+			r = f
+		}
+		err := b.read(r, streamLabels.String(), ts, fields)
+		if closer, ok := r.(io.Closer); ok && path != StdinPath {
+			closer.Close()
+		}
+		if err != nil {
+			return b.stats, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+	}
+	b.flush()
+	b.report(true)
+	return b.stats, nil
+}
+
+type batcher struct {
+	client   client.Client
+	size     int
+	streams  map[string]*logproto.Stream
+	bytes    int
+	lines    int
+	stats    Stats
+	progress io.Writer
+
+	start, lastReport time.Time
+}
+
+func (b *batcher) read(r io.Reader, lbs string, ts *util.TimestampParser, fields *fieldExtractor) error {
+	br := bufio.NewReader(r)
+	var prev time.Time
+	for {
+		line, err := br.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return err
+		}
+		if line == "" && err == io.EOF {
+			return nil
+		}
+		line = strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+
+		// Lines without timestamp get the one of the line before.
+		t := prev
+		if nanos, ok := ts.Timestamp(line); ok {
+			t = time.Unix(0, nanos)
+		} else if ts == nil || prev.IsZero() {
+			t = time.Now()
+		}
+		prev = t
+
+		b.add(lbs, logproto.Entry{
+			Timestamp:          t,
+			Line:               line,
+			StructuredMetadata: logproto.FromLabelsToLabelAdapters(fields.extract(line)),
+		})
+		if err == io.EOF {
+			return nil
+		}
+	}
+}
+
+func (b *batcher) add(lbs string, e logproto.Entry) {
+	stream, ok := b.streams[lbs]
+	if !ok {
+		stream = &logproto.Stream{Labels: lbs}
+		b.streams[lbs] = stream
+	}
+	stream.Entries = append(stream.Entries, e)
+
+	b.lines++
+	b.bytes += len(e.Line)
+	for _, m := range e.StructuredMetadata {
+		b.bytes += len(m.Name) + len(m.Value)
+	}
+	if b.bytes >= b.size {
+		b.flush()
+	}
+}
+
+func (b *batcher) flush() {
+	if b.lines == 0 {
+		return
+	}
+
+	req := &logproto.PushRequest{Streams: make([]logproto.Stream, 0, len(b.streams))}
+	for _, s := range b.streams {
+		req.Streams = append(req.Streams, *s)
+	}
+	sort.Slice(req.Streams, func(i, j int) bool { return req.Streams[i].Labels < req.Streams[j].Labels })
+
+	if err := b.client.Push(req, true); err != nil {
+		log.Printf("Error pushing %d lines: %s", b.lines, err)
+		b.stats.FailedLines += b.lines
+	}
+	b.stats.Lines += b.lines
+	b.stats.Bytes += b.bytes
+	b.stats.Requests++
+
+	b.streams = map[string]*logproto.Stream{}
+	b.lines, b.bytes = 0, 0
+	b.report(false)
+}
+
+func (b *batcher) report(final bool) {
+	now := time.Now()
+	if !final && now.Sub(b.lastReport) < progressInterval {
+		return
+	}
+	b.lastReport = now
+
+	elapsed := now.Sub(b.start).Seconds()
+	if elapsed == 0 {
+		elapsed = 1
+	}
+	end := "\r"
+	if final {
+		end = "\n"
+	}
+	fmt.Fprintf(b.progress, "pushed %d lines (%s) in %d requests, %.0f lines/s, %s/s, %d failed%s",
+		b.stats.Lines, humanize.Bytes(uint64(b.stats.Bytes)), b.stats.Requests,
+		float64(b.stats.Lines)/elapsed, humanize.Bytes(uint64(float64(b.stats.Bytes)/elapsed)), b.stats.FailedLines, end)
+}
+
+// fieldExtractor extracts the structured metadata of the lines from their fields.
+type fieldExtractor struct {
+	format string
+	// fields maps the names of the fields to the names of the structured metadata.
+	fields map[string]string
+	names  []string
+}
+
+func newFieldExtractor(format string, metadata map[string]string) (*fieldExtractor, error) {
+	if len(metadata) == 0 {
+		return nil, nil
+	}
+	if format != "json" && format != "logfmt" {
+		return nil, fmt.Errorf("invalid format %q for structured metadata, it must be json or logfmt", format)
+	}
+
+	e := &fieldExtractor{format: format, fields: make(map[string]string, len(metadata))}
+	for name, field := range metadata {
+		e.fields[field] = name
+		e.names = append(e.names, name)
+	}
+	sort.Strings(e.names)
+	return e, nil
+}
+
+// extract returns the structured metadata of the line, sorted by name.
+func (e *fieldExtractor) extract(line string) labels.Labels {
+	if e == nil {
+		return nil
+	}
+
+	values := make(map[string]string, len(e.fields))
+	switch e.format {
+	case "json":
+		for field, name := range e.fields {
+			// Nested fields are separated by dots, e.g. request.id.
+			value, dataType, _, err := jsonparser.Get([]byte(line), strings.Split(field, ".")...)
+			if err != nil {
+				continue
+			}
+			if dataType == jsonparser.String {
+				if s, err := jsonparser.ParseString(value); err == nil {
+					values[name] = s
+					continue
+				}
+			}
+			values[name] = string(value)
+		}
+	case "logfmt":
+		dec := logfmt.NewDecoder([]byte(line))
+		for !dec.EOL() && dec.ScanKeyval() {
+			if name, ok := e.fields[string(dec.Key())]; ok {
+				values[name] = string(dec.Value())
+			}
+		}
+	}
+
+	var metadata labels.Labels
+	for _, name := range e.names {
+		if v := values[name]; v != "" {
+			metadata = append(metadata, labels.Label{Name: name, Value: v})
+		}
+	}
+	return metadata
+}
@@ -0,0 +1,99 @@
+package push
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/logcli/client"
+	"github.com/grafana/loki/v3/pkg/logproto"
+)
+
+type fakeClient struct {
+	client.Client
+	requests []*logproto.PushRequest
+	err      error
+}
+
+func (c *fakeClient) Push(req *logproto.PushRequest, _ bool) error {
+	c.requests = append(c.requests, req)
+	return c.err
+}
+
+func (c *fakeClient) entries() map[string][]logproto.Entry {
+	entries := map[string][]logproto.Entry{}
+	for _, req := range c.requests {
+		for _, s := range req.Streams {
+			entries[s.Labels] = append(entries[s.Labels], s.Entries...)
+		}
+	}
+	return entries
+}
+
+func TestPush_Run(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	require.NoError(t, os.WriteFile(path, []byte(strings.Join([]string{
+		`{"ts":"2024-01-01T10:00:00Z","msg":"started","trace":{"id":"abc"}}`,
+		`{"ts":"2024-01-01T10:00:01Z","msg":"failed","trace":{"id":"def"}}`,
+		`not json`,
+	}, "\n")), 0o644))
+
+	c := &fakeClient{}
+	p := &Push{
+		Labels:             `{job="import"}`,
+		Paths:              []string{path, StdinPath},
+		BatchSize:          100,
+		TimestampFormat:    "RFC3339",
+		TimestampRegexp:    `"ts":"([^"]+)"`,
+		Location:           time.UTC,
+		Format:             "json",
+		StructuredMetadata: map[string]string{"trace_id": "trace.id"},
+	}
+	var progress bytes.Buffer
+	stats, err := p.Run(c, strings.NewReader("from stdin\n"), &progress)
+	require.NoError(t, err)
+	require.Equal(t, Stats{Lines: 4, Bytes: 171, Requests: 2}, stats)
+	require.Contains(t, progress.String(), "pushed 4 lines")
+
+	entries := c.entries()
+	fileEntries := entries[`{filename="`+path+`", job="import"}`]
+	require.Len(t, fileEntries, 3)
+	assert.Equal(t, time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC), fileEntries[0].Timestamp.UTC())
+	assert.Equal(t, "abc", fileEntries[0].StructuredMetadata[0].Value)
+	assert.Equal(t, "trace_id", fileEntries[1].StructuredMetadata[0].Name)
+	// The line without timestamp gets the timestamp of the line before.
+	assert.Equal(t, fileEntries[1].Timestamp, fileEntries[2].Timestamp)
+	assert.Empty(t, fileEntries[2].StructuredMetadata)
+
+	stdinEntries := entries[`{job="import"}`]
+	require.Len(t, stdinEntries, 1)
+	assert.Equal(t, "from stdin", stdinEntries[0].Line)
+}
+
+func TestPush_Run_Failures(t *testing.T) {
+	c := &fakeClient{err: client.ErrNotSupported}
+	p := &Push{Labels: `{job="import"}`, BatchSize: 10, Quiet: true}
+	stats, err := p.Run(c, strings.NewReader("line one\nline two\nline three"), nil)
+	require.NoError(t, err)
+	require.Equal(t, 3, stats.FailedLines)
+	require.Equal(t, 2, stats.Requests)
+}
+
+func TestFieldExtractor(t *testing.T) {
+	_, err := newFieldExtractor("xml", map[string]string{"a": "b"})
+	require.Error(t, err)
+
+	e, err := newFieldExtractor("logfmt", map[string]string{"trace_id": "traceID", "user": "user"})
+	require.NoError(t, err)
+	metadata := e.extract(`level=info traceID=abc user="jane doe" msg=hi`)
+	require.Equal(t, "trace_id", metadata[0].Name)
+	require.Equal(t, "abc", metadata[0].Value)
+	require.Equal(t, "user", metadata[1].Name)
+	require.Equal(t, "jane doe", metadata[1].Value)
+}
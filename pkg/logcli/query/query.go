@@ -2,6 +2,7 @@ package query
 
 import (
 	"context"
+	"encoding/json"
 	stdErrors "errors"
 	"flag"
 	"fmt"
@@ -80,19 +81,26 @@ type Query struct {
 	// If MergeParts is false, this parameter has no effect, part files will be kept.
 	// Otherwise, if this is true, the part files will not be deleted once they have been merged.
 	KeepParts bool
+
+	// If true, the entries are streamed with the export API instead of being queried in batches.
+	Export bool
 }
 
 // DoQuery executes the query and prints out the results
 func (q *Query) DoQuery(c client.Client, out output.LogOutput, statistics bool) {
+	if err := q.Run(c, out, statistics); err != nil {
+		log.Fatalf("Query failed: %+v", err)
+	}
+}
+
+// Run executes the query and prints out the results, returning the errors instead of exiting.
+func (q *Query) Run(c client.Client, out output.LogOutput, statistics bool) error {
 	if q.LocalConfig != "" {
 		orgID := c.GetOrgID()
 		if orgID == "" {
 			orgID = "fake"
 		}
-		if err := q.DoLocalQuery(out, statistics, orgID, q.FetchSchemaFromStorage); err != nil {
-			log.Fatalf("Query failed: %+v", err)
-		}
-		return
+		return q.DoLocalQuery(out, statistics, orgID, q.FetchSchemaFromStorage)
 	}
 
 	d := q.resultsDirection()
@@ -109,7 +117,7 @@ func (q *Query) DoQuery(c client.Client, out output.LogOutput, statistics bool)
 		// OverwriteCompleted is false, therefor, we should exit the function
 		// here because we have nothing to do.
 		if shouldSkip {
-			return
+			return nil
 		}
 	}
 
@@ -123,7 +131,7 @@ func (q *Query) DoQuery(c client.Client, out output.LogOutput, statistics bool)
 	if q.isInstant() {
 		resp, err = c.Query(q.QueryString, q.Limit, q.Start, d, q.Quiet)
 		if err != nil {
-			log.Fatalf("Query failed: %+v", err)
+			return err
 		}
 		if statistics {
 			result.PrintStats(resp.Data.Statistics)
@@ -140,6 +148,7 @@ func (q *Query) DoQuery(c client.Client, out output.LogOutput, statistics bool)
 		start := q.Start
 		end := q.End
 		var lastEntry []*loghttp.Entry
+		var cursor string
 		for total < q.Limit || unlimited {
 			bs := q.BatchSize
 			// We want to truncate the batch size if the remaining number
@@ -151,9 +160,9 @@ func (q *Query) DoQuery(c client.Client, out output.LogOutput, statistics bool)
 				// correct amount of new logs knowing there will be some overlapping logs returned.
 				bs = q.Limit - total + len(lastEntry)
 			}
-			resp, err = c.QueryRange(q.QueryString, bs, start, end, d, q.Step, q.Interval, q.Quiet)
+			resp, err = c.QueryRange(q.QueryString, bs, start, end, d, q.Step, q.Interval, cursor, q.Quiet)
 			if err != nil {
-				log.Fatalf("Query failed: %+v", err)
+				return err
 			}
 
 			if statistics {
@@ -173,12 +182,24 @@ func (q *Query) DoQuery(c client.Client, out output.LogOutput, statistics bool)
 			if resultLength == q.Limit {
 				break
 			}
+			// The cursor of the response resumes the next batch right after the entries of this one,
+			// so no entries are returned twice.
+			if resp.Data.Cursor != "" {
+				cursor = resp.Data.Cursor
+				lastEntry = nil
+				total += resultLength
+				continue
+			}
+			// No more cursor: the previous batch reached the end of the range.
+			if cursor != "" {
+				break
+			}
 			if len(lastEntry) >= q.BatchSize {
-				log.Fatalf("Invalid batch size %v, the next query will have %v overlapping entries "+
+				return fmt.Errorf("invalid batch size %v, the next query will have %v overlapping entries "+
 					"(there will always be 1 overlapping entry but Loki allows multiple entries to have "+
 					"the same timestamp, so when a batch ends in this scenario the next query will include "+
 					"all the overlapping entries again).  Please increase your batch size to at least %v to account "+
-					"for overlapping entryes\n", q.BatchSize, len(lastEntry), len(lastEntry)+1)
+					"for overlapping entryes", q.BatchSize, len(lastEntry), len(lastEntry)+1)
 			}
 
 			// Batching works by taking the timestamp of the last query and using it in the next query,
@@ -202,8 +223,77 @@ func (q *Query) DoQuery(c client.Client, out output.LogOutput, statistics bool)
 	}
 
 	if partFile != nil {
-		if err := partFile.Finalize(); err != nil {
-			log.Fatalln(err)
+		return partFile.Finalize()
+	}
+	return nil
+}
+
+// DoExport streams the entries of the query with the export API and prints them as they are
+// received. If the stream is interrupted, the export is resumed from the last cursor received.
+func (q *Query) DoExport(c client.Client, out output.LogOutput) {
+	result := print.NewQueryResultPrinter(q.ShowLabelsKey, q.IgnoreLabelsKey, q.Quiet, q.FixedLabelsLen, q.Forward)
+	d := q.resultsDirection()
+
+	var cursor string
+	exported := 0
+	for {
+		limit := 0
+		if q.Limit > 0 {
+			if exported >= q.Limit {
+				return
+			}
+			limit = q.Limit - exported
+		}
+		body, err := c.Export(q.QueryString, limit, q.BatchSize, q.Start, q.End, d, cursor, q.Quiet)
+		if err != nil {
+			log.Fatalf("Export failed: %+v", err)
+		}
+
+		done, resumeFrom, n, err := q.readExport(body, result, out)
+		body.Close()
+		exported += n
+		switch {
+		case done:
+			return
+		case err != nil && resumeFrom == cursor:
+			log.Fatalf("Export failed: %+v", err)
+		}
+		if !q.Quiet {
+			log.Printf("Export interrupted, resuming after %d entries: %v", exported, err)
+		}
+		// entries printed after the last cursor received are exported again.
+		cursor = resumeFrom
+	}
+}
+
+// readExport prints the entries of an export response. It returns whether the export is done,
+// the last cursor received and the number of entries printed up to it.
+func (q *Query) readExport(body io.Reader, result *print.QueryResultPrinter, out output.LogOutput) (bool, string, int, error) {
+	var cursor string
+	printed, pending := 0, 0
+	dec := json.NewDecoder(body)
+	for {
+		var line loghttp.ExportLine
+		if err := dec.Decode(&line); err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return false, cursor, printed, err
+		}
+		switch {
+		case line.Error != "":
+			log.Fatalf("Export failed: %s", line.Error)
+		case line.Done:
+			return true, cursor, printed + pending, nil
+		case line.Cursor != "":
+			cursor = line.Cursor
+			printed += pending
+			pending = 0
+		case line.IsEntry():
+			if err := result.PrintExportedEntry(line, out); err != nil {
+				return false, cursor, printed, err
+			}
+			pending++
 		}
 	}
 }
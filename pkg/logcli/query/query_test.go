@@ -5,6 +5,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -17,11 +18,13 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/grafana/loki/v3/pkg/iter"
 	"github.com/grafana/loki/v3/pkg/logcli/output"
 	"github.com/grafana/loki/v3/pkg/logcli/volume"
 	"github.com/grafana/loki/v3/pkg/loghttp"
 	"github.com/grafana/loki/v3/pkg/logproto"
 	"github.com/grafana/loki/v3/pkg/logql"
+	"github.com/grafana/loki/v3/pkg/logqlmodel"
 	"github.com/grafana/loki/v3/pkg/loki"
 	"github.com/grafana/loki/v3/pkg/storage"
 	"github.com/grafana/loki/v3/pkg/storage/chunk/client"
@@ -38,6 +41,7 @@ func Test_batch(t *testing.T) {
 		limit, batch  int
 		labelMatcher  string
 		forward       bool
+		cursors       bool
 		expectedCalls int
 		expected      []string
 	}{
@@ -370,10 +374,72 @@ func Test_batch(t *testing.T) {
 				"line10", "line9", "line8", "line7", "line6b", "line6a", "line6", "line5", "line4", "line3", "line2", "line1",
 			},
 		},
+		{
+			name: "forward with cursors",
+			streams: []logproto.Stream{
+				{
+					Labels: "{test=\"simple\"}",
+					Entries: []logproto.Entry{
+						{Timestamp: time.Unix(1, 0), Line: "line1"},
+						{Timestamp: time.Unix(2, 0), Line: "line2"},
+						{Timestamp: time.Unix(2, 0), Line: "line2a"},
+						{Timestamp: time.Unix(2, 0), Line: "line2b"},
+						{Timestamp: time.Unix(2, 0), Line: "line2c"},
+						{Timestamp: time.Unix(3, 0), Line: "line3"},
+						{Timestamp: time.Unix(4, 0), Line: "line4"},
+					},
+				},
+			},
+			start:        time.Unix(1, 0),
+			end:          time.Unix(10, 0),
+			limit:        6,
+			batch:        2,
+			labelMatcher: "{test=\"simple\"}",
+			forward:      true,
+			cursors:      true,
+			// The batches don't overlap, even when they end among entries sharing a timestamp.
+			// Call one:   line1 line2
+			// Call two:   line2a line2b
+			// Call three: line2c line3
+			expectedCalls: 3,
+			expected: []string{
+				"line1", "line2", "line2a", "line2b", "line2c", "line3",
+			},
+		},
+		{
+			name: "backward with cursors",
+			streams: []logproto.Stream{
+				{
+					Labels: "{test=\"simple\"}",
+					Entries: []logproto.Entry{
+						{Timestamp: time.Unix(1, 0), Line: "line1"},
+						{Timestamp: time.Unix(2, 0), Line: "line2"},
+						{Timestamp: time.Unix(2, 0), Line: "line2a"},
+						{Timestamp: time.Unix(2, 0), Line: "line2b"},
+						{Timestamp: time.Unix(3, 0), Line: "line3"},
+					},
+				},
+			},
+			start:        time.Unix(1, 0),
+			end:          time.Unix(10, 0),
+			limit:        0,
+			batch:        2,
+			labelMatcher: "{test=\"simple\"}",
+			forward:      false,
+			cursors:      true,
+			// Call one:   line3 line2b
+			// Call two:   line2a line2
+			// Call three: line1, which doesn't fill the batch so there is no cursor.
+			expectedCalls: 3,
+			expected: []string{
+				"line3", "line2b", "line2a", "line2", "line1",
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			tc := newTestQueryClient(tt.streams...)
+			tc.cursors = tt.cursors
 			writer := &bytes.Buffer{}
 			out := output.NewRaw(writer, nil)
 			q := Query{
@@ -408,6 +474,8 @@ func Test_batch(t *testing.T) {
 type testQueryClient struct {
 	engine          *logql.Engine
 	queryRangeCalls int
+	// cursors makes the client paginate log queries with cursors, like the query frontend.
+	cursors bool
 }
 
 func newTestQueryClient(testStreams ...logproto.Stream) *testQueryClient {
@@ -423,10 +491,24 @@ func (t *testQueryClient) Query(_ string, _ int, _ time.Time, _ logproto.Directi
 	panic("implement me")
 }
 
-func (t *testQueryClient) QueryRange(queryStr string, limit int, from, through time.Time, direction logproto.Direction, step, interval time.Duration, _ bool) (*loghttp.QueryResponse, error) {
+func (t *testQueryClient) QueryRange(queryStr string, limit int, from, through time.Time, direction logproto.Direction, step, interval time.Duration, cursor string, _ bool) (*loghttp.QueryResponse, error) {
 	ctx := user.InjectOrgID(context.Background(), "fake")
 
-	params, err := logql.NewLiteralParams(queryStr, from, through, step, interval, direction, uint32(limit), nil)
+	c := &loghttp.Cursor{}
+	if cursor != "" {
+		var err error
+		if c, err = loghttp.DecodeCursor(cursor); err != nil {
+			return nil, err
+		}
+		if ts := time.Unix(0, c.Timestamp); direction == logproto.FORWARD {
+			from = ts
+		} else {
+			through = ts.Add(time.Nanosecond)
+		}
+	}
+	queryLimit := uint32(limit + len(c.Seen))
+
+	params, err := logql.NewLiteralParams(queryStr, from, through, step, interval, direction, queryLimit, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -436,6 +518,11 @@ func (t *testQueryClient) QueryRange(queryStr string, limit int, from, through t
 		return nil, err
 	}
 
+	var next string
+	if streams, ok := v.Data.(logqlmodel.Streams); ok && t.cursors {
+		v.Data, next = t.page(streams, direction, c, queryLimit, uint32(limit))
+	}
+
 	value, err := marshal.NewResultValue(v.Data)
 	if err != nil {
 		return nil, err
@@ -447,12 +534,49 @@ func (t *testQueryClient) QueryRange(queryStr string, limit int, from, through t
 			ResultType: value.Type(),
 			Result:     value,
 			Statistics: v.Statistics,
+			Cursor:     next,
 		},
 	}
 	t.queryRangeCalls++
 	return q, nil
 }
 
+// page skips the entries returned before the cursor, and returns the cursor of the next page if the query was full.
+func (t *testQueryClient) page(streams logqlmodel.Streams, direction logproto.Direction, c *loghttp.Cursor, queryLimit, limit uint32) (logqlmodel.Streams, string) {
+	next := &loghttp.Cursor{Timestamp: c.Timestamp, Seen: append([]uint64(nil), c.Seen...)}
+	var (
+		result      logqlmodel.Streams
+		total, kept uint32
+		indexes     = map[string]int{}
+		it          = iter.NewStreamsIterator(streams, direction)
+	)
+	defer it.Close()
+	for it.Next() {
+		total++
+		e, lbls := it.Entry(), it.Labels()
+		if kept == limit || c.Returned(e.Timestamp.UnixNano(), lbls, e.Line) {
+			continue
+		}
+		i, ok := indexes[lbls]
+		if !ok {
+			i = len(result)
+			indexes[lbls] = i
+			result = append(result, logproto.Stream{Labels: lbls})
+		}
+		result[i].Entries = append(result[i].Entries, e)
+		next.Observe(e.Timestamp.UnixNano(), lbls, e.Line)
+		kept++
+	}
+	if total < queryLimit {
+		return result, ""
+	}
+	return result, next.Encode()
+}
+
+func (t *testQueryClient) Export(_ string, _, _ int, _, _ time.Time, _ logproto.Direction, _ string, _ bool) (io.ReadCloser, error) {
+	panic("not implemented")
+}
+
 func (t *testQueryClient) ListLabelNames(_ bool, _, _ time.Time) (*loghttp.LabelResponse, error) {
 	panic("implement me")
 }
@@ -485,6 +609,10 @@ func (t *testQueryClient) GetVolumeRange(_ *volume.Query) (*loghttp.QueryRespons
 	panic("not implemented")
 }
 
+func (t *testQueryClient) Push(_ *logproto.PushRequest, _ bool) error {
+	panic("not implemented")
+}
+
 var legacySchemaConfigContents = `schema_config:
   configs:
   - from: 2020-05-15
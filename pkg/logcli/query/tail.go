@@ -120,6 +120,9 @@ func (q *Query) TailQuery(delayFor time.Duration, c client.Client, out output.Lo
 			}
 
 		}
+		if f, ok := out.(output.Flusher); ok {
+			f.Flush()
+		}
 		if len(tailResponse.DroppedStreams) != 0 {
 			log.Println("Server dropped following entries due to slow client")
 			for _, d := range tailResponse.DroppedStreams {
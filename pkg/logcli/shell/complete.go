@@ -0,0 +1,187 @@
+package shell
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/grafana/loki/v3/pkg/logql/syntax"
+)
+
+var (
+	// stageKeywords are the keywords completed after a pipe.
+	stageKeywords = []string{
+		syntax.OpParserTypeJSON, syntax.OpParserTypeLogfmt, syntax.OpParserTypeRegexp, syntax.OpParserTypeUnpack,
+		syntax.OpParserTypePattern, syntax.OpFmtLine, syntax.OpFmtLabel, syntax.OpDecolorize, syntax.OpDrop,
+		syntax.OpKeep, syntax.OpDistinct, syntax.OpSample, syntax.OpLimitPerStream, syntax.OpUnwrap,
+	}
+
+	// functionKeywords are the keywords completed outside of stream selectors and pipelines.
+	functionKeywords = []string{
+		syntax.OpTypeSum, syntax.OpTypeAvg, syntax.OpTypeMax, syntax.OpTypeMin, syntax.OpTypeCount,
+		syntax.OpTypeStddev, syntax.OpTypeStdvar, syntax.OpTypeBottomK, syntax.OpTypeTopK, syntax.OpTypeSort,
+		syntax.OpTypeSortDesc,
+		syntax.OpRangeTypeCount, syntax.OpRangeTypeRate, syntax.OpRangeTypeRateCounter, syntax.OpRangeTypeBytes,
+		syntax.OpRangeTypeBytesRate, syntax.OpRangeTypeAvg, syntax.OpRangeTypeSum, syntax.OpRangeTypeMin,
+		syntax.OpRangeTypeMax, syntax.OpRangeTypeStdvar, syntax.OpRangeTypeStddev, syntax.OpRangeTypeQuantile,
+		syntax.OpRangeTypeFirst, syntax.OpRangeTypeLast, syntax.OpRangeTypeAbsent,
+		syntax.OpTypeVector, syntax.OpLabelReplace, syntax.OpTypeOr, syntax.OpTypeAnd, syntax.OpTypeUnless,
+		syntax.OpOffset, syntax.OpOn, syntax.OpIgnoring, syntax.OpGroupLeft, syntax.OpGroupRight,
+		"by", "without", "bool",
+	}
+)
+
+// completer completes the words of LogQL queries and shell commands.
+type completer struct {
+	labelNames  func() []string
+	labelValues func(name string) []string
+}
+
+// complete returns the candidates for the word ending at the end of prefix, and the index where the word starts.
+func (c *completer) complete(prefix string) ([]string, int) {
+	if start, ok := openQuote(prefix); ok {
+		// Only the values of label matchers are completed in strings.
+		name, ok := matcherLabel(prefix[:start])
+		if !ok || !inBraces(prefix[:start]) {
+			return nil, start + 1
+		}
+		return filterPrefix(c.labelValues(name), prefix[start+1:]), start + 1
+	}
+
+	start := len(prefix)
+	for start > 0 && isWordChar(prefix[start-1]) {
+		start--
+	}
+	word := prefix[start:]
+	before := strings.TrimRight(prefix[:start], " \t\n")
+
+	switch {
+	case strings.HasPrefix(prefix, ":"):
+		fields := strings.Fields(prefix)
+		if len(fields) == 1 && !strings.HasSuffix(prefix, " ") {
+			return filterPrefix(commandNames(), prefix), 0
+		}
+		if fields[0] == ":labels" && start > 0 {
+			return filterPrefix(c.labelNames(), word), start
+		}
+		return nil, start
+	case inBraces(before) || inGrouping(before):
+		return filterPrefix(c.labelNames(), word), start
+	case strings.HasSuffix(before, syntax.OpPipe):
+		return filterPrefix(stageKeywords, word), start
+	case word == "":
+		return nil, start
+	default:
+		return filterPrefix(functionKeywords, word), start
+	}
+}
+
+func isWordChar(b byte) bool {
+	return b == '_' || b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z' || b >= '0' && b <= '9'
+}
+
+// openQuote returns the index of the double quote of the string s ends in, if any.
+func openQuote(s string) (int, bool) {
+	start, in := -1, false
+	for i := 0; i < len(s); i++ {
+		switch {
+		case in && s[i] == '\\':
+			i++
+		case s[i] == '"':
+			in = !in
+			start = i
+		}
+	}
+	return start, in
+}
+
+// matcherLabel returns the name of the label of the matcher s ends with, e.g. `{app=`.
+func matcherLabel(s string) (string, bool) {
+	s = strings.TrimRight(s, " ")
+	for _, op := range []string{"!=", "=~", "!~", "="} {
+		if strings.HasSuffix(s, op) {
+			s = strings.TrimRight(strings.TrimSuffix(s, op), " ")
+			end := len(s)
+			for end > 0 && isWordChar(s[end-1]) {
+				end--
+			}
+			return s[end:], end < len(s)
+		}
+	}
+	return "", false
+}
+
+// inBraces returns true if s ends in a stream selector.
+func inBraces(s string) bool {
+	i, ok := unclosed(s, '{', '}')
+	return ok && i >= 0
+}
+
+// inGrouping returns true if s ends in the labels of a by or without clause.
+func inGrouping(s string) bool {
+	i, ok := unclosed(s, '(', ')')
+	if !ok {
+		return false
+	}
+	before := strings.TrimRight(s[:i], " ")
+	return strings.HasSuffix(before, "by") || strings.HasSuffix(before, "without")
+}
+
+// unclosed returns the index of the last open bracket of s which isn't closed, ignoring strings.
+func unclosed(s string, open, closing byte) (int, bool) {
+	var stack []int
+	in := false
+	for i := 0; i < len(s); i++ {
+		switch {
+		case in && s[i] == '\\':
+			i++
+		case s[i] == '"':
+			in = !in
+		case in:
+		case s[i] == open:
+			stack = append(stack, i)
+		case s[i] == closing && len(stack) > 0:
+			stack = stack[:len(stack)-1]
+		}
+	}
+	if len(stack) == 0 {
+		return -1, false
+	}
+	return stack[len(stack)-1], true
+}
+
+// incomplete returns true if the query has unclosed brackets or strings, and needs more lines.
+func incomplete(query string) bool {
+	if _, ok := openQuote(query); ok {
+		return true
+	}
+	for _, b := range []string{"{}", "()", "[]"} {
+		if _, ok := unclosed(query, b[0], b[1]); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func filterPrefix(candidates []string, prefix string) []string {
+	var res []string
+	for _, c := range candidates {
+		if strings.HasPrefix(c, prefix) {
+			res = append(res, c)
+		}
+	}
+	sort.Strings(res)
+	return res
+}
+
+func commonPrefix(candidates []string) string {
+	if len(candidates) == 0 {
+		return ""
+	}
+	prefix := candidates[0]
+	for _, c := range candidates[1:] {
+		for !strings.HasPrefix(c, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	return prefix
+}
@@ -0,0 +1,491 @@
+package shell
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"golang.org/x/term"
+
+	"github.com/grafana/loki/v3/pkg/logcli/client"
+	"github.com/grafana/loki/v3/pkg/logcli/output"
+	"github.com/grafana/loki/v3/pkg/logcli/query"
+	"github.com/grafana/loki/v3/pkg/loghttp"
+	"github.com/grafana/loki/v3/pkg/logql/syntax"
+)
+
+const (
+	prompt             = "logql> "
+	continuationPrompt = "  ...> "
+	maxHistory         = 1000
+)
+
+var commands = []struct {
+	name, args, help string
+}{
+	{":help", "", "Show this help."},
+	{":quit", "", "Quit the shell, like Ctrl-D."},
+	{":set", "", "Show the settings of the shell."},
+	{":since", "<duration>", "Query the given lookback window, ending when the query runs."},
+	{":from", "<time>", "Query from the given RFC3339 time."},
+	{":to", "<time>", "Query until the given RFC3339 time."},
+	{":limit", "<n>", "Set the maximum number of entries of log queries, 0 for no limit."},
+	{":direction", "forward|backward", "Set the direction of log queries."},
+	{":step", "<duration>", "Set the step of metric queries, 0 to let Loki choose."},
+	{":instant", "on|off", "Run instant queries, at the end of the range, instead of range queries."},
+	{":output", "<mode>", "Set the output mode of the log lines."},
+	{":stats", "on|off", "Show the statistics of the queries."},
+	{":org", "<tenant>", "Set the tenant of the queries."},
+	{":addr", "<url>", "Set the address of Loki."},
+	{":labels", "[<name>]", "List the label names, or the values of the given label."},
+	{":fmt", "[<query>]", "Validate and pretty-print the query, by default the last one."},
+	{":history", "", "Show the history."},
+}
+
+func commandNames() []string {
+	names := make([]string, 0, len(commands))
+	for _, c := range commands {
+		names = append(names, c.name)
+	}
+	return names
+}
+
+// Shell is an interactive LogQL shell. It keeps the connection, tenant, time range and output settings
+// between the queries it runs.
+type Shell struct {
+	Client client.Client
+	// Query holds the settings of the queries, like their limit, direction and time range.
+	Query query.Query
+	// Since is the lookback window of the queries, ending when they run. When zero, the queries use the
+	// start and end of Query.
+	Since         time.Duration
+	Instant       bool
+	OutputMode    string
+	OutputOptions output.LogOutputOptions
+	Statistics    bool
+	// HistoryFile is the file the history is loaded from and saved to, if not empty.
+	HistoryFile string
+
+	out       io.Writer
+	history   []string
+	lastQuery string
+	// labelValues caches the values of the labels, the names are cached with the empty name.
+	labelValues map[string][]string
+}
+
+// Run reads and runs queries and commands from in until it ends or the shell is quit.
+// When in is a terminal, lines can be edited, and completed with tab.
+func (s *Shell) Run(in io.Reader, out io.Writer) error {
+	s.out = out
+	s.labelValues = map[string][]string{}
+	s.loadHistory()
+
+	r, err := s.newLineReader(in, out)
+	if err != nil {
+		return err
+	}
+
+	var lines []string
+	for {
+		p := prompt
+		if len(lines) > 0 {
+			p = continuationPrompt
+		}
+		line, err := r.readLine(p)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		// Lines ending with a backslash, or with unclosed brackets or strings, are continued on the next line.
+		continued := strings.HasSuffix(line, `\`)
+		lines = append(lines, strings.TrimSuffix(line, `\`))
+		input := strings.TrimSpace(strings.Join(lines, "\n"))
+		if continued || (!strings.HasPrefix(input, ":") && incomplete(input)) {
+			continue
+		}
+		lines = nil
+		if input == "" {
+			continue
+		}
+
+		s.addHistory(input)
+		if quit := s.exec(input); quit {
+			return nil
+		}
+	}
+}
+
+// exec runs the input and returns true if the shell must be quit.
+func (s *Shell) exec(input string) bool {
+	if !strings.HasPrefix(input, ":") {
+		s.runQuery(input)
+		return false
+	}
+
+	fields := strings.Fields(input)
+	cmd, args := fields[0], fields[1:]
+	arg := strings.TrimSpace(strings.TrimPrefix(input, cmd))
+	var err error
+	switch cmd {
+	case ":help", ":h":
+		s.printHelp()
+	case ":quit", ":q", ":exit":
+		return true
+	case ":set":
+		s.printSettings()
+	case ":since":
+		var d model.Duration
+		if d, err = model.ParseDuration(arg); err == nil {
+			s.Since = time.Duration(d)
+		}
+	case ":from", ":to":
+		var t time.Time
+		if t, err = s.parseTime(arg); err == nil {
+			if s.Since > 0 {
+				now := time.Now()
+				s.Query.Start, s.Query.End = now.Add(-s.Since), now
+				s.Since = 0
+			}
+			if cmd == ":from" {
+				s.Query.Start = t
+			} else {
+				s.Query.End = t
+			}
+		}
+	case ":limit":
+		s.Query.Limit, err = strconv.Atoi(arg)
+	case ":direction":
+		switch arg {
+		case "forward":
+			s.Query.Forward = true
+		case "backward":
+			s.Query.Forward = false
+		default:
+			err = fmt.Errorf("invalid direction %q, it must be forward or backward", arg)
+		}
+	case ":step":
+		var d model.Duration
+		if d, err = model.ParseDuration(arg); err == nil {
+			s.Query.Step = time.Duration(d)
+		}
+	case ":instant":
+		s.Instant, err = parseSwitch(arg)
+	case ":stats":
+		s.Statistics, err = parseSwitch(arg)
+	case ":output":
+		if _, err = output.NewLogOutput(io.Discard, arg, &output.LogOutputOptions{}); err == nil {
+			s.OutputMode = arg
+		}
+	case ":org":
+		if c, ok := s.Client.(*client.DefaultClient); ok {
+			c.OrgID = arg
+		} else {
+			err = fmt.Errorf("the tenant can't be changed when not querying Loki")
+		}
+	case ":addr":
+		c, ok := s.Client.(*client.DefaultClient)
+		if !ok {
+			err = fmt.Errorf("the address can't be changed when not querying Loki")
+			break
+		}
+		var u *url.URL
+		if u, err = url.Parse(arg); err == nil {
+			c.Address = arg
+			if c.ProxyURL == "" {
+				c.TLSConfig.ServerName = strings.Split(u.Host, ":")[0]
+			}
+			s.labelValues = map[string][]string{}
+		}
+	case ":labels":
+		s.printLabels(args)
+	case ":fmt":
+		q := arg
+		if q == "" {
+			q = s.lastQuery
+		}
+		var expr syntax.Expr
+		if expr, err = syntax.ParseExpr(q); err == nil {
+			fmt.Fprintln(s.out, syntax.Prettify(expr))
+		}
+	case ":history":
+		for i, h := range s.history {
+			fmt.Fprintf(s.out, "%4d  %s\n", i+1, h)
+		}
+	default:
+		err = fmt.Errorf("unknown command %s, type :help for help", cmd)
+	}
+	if err != nil {
+		fmt.Fprintf(s.out, "error: %s\n", err)
+	}
+	return false
+}
+
+func (s *Shell) runQuery(input string) {
+	if _, err := syntax.ParseExpr(input); err != nil {
+		fmt.Fprintf(s.out, "invalid query: %s\n", err)
+		return
+	}
+	s.lastQuery = input
+
+	q := s.Query
+	q.QueryString = input
+	q.Start, q.End = s.timeRange()
+	if s.Instant {
+		q.SetInstant(q.End)
+		q.Step = 0
+	} else if _, remote := s.Client.(*client.DefaultClient); !remote && q.Step == 0 {
+		// Loki chooses the step of the queries it runs, other clients need one, chosen in the same way.
+		q.Step = time.Duration(math.Max(math.Floor(q.End.Sub(q.Start).Seconds()/250), 1)) * time.Second
+	}
+
+	options := s.OutputOptions
+	out, err := output.NewLogOutput(s.out, s.OutputMode, &options)
+	if err != nil {
+		fmt.Fprintf(s.out, "error: %s\n", err)
+		return
+	}
+	if err := q.Run(s.Client, out, s.Statistics); err != nil {
+		fmt.Fprintf(s.out, "query failed: %s\n", err)
+	}
+}
+
+func (s *Shell) timeRange() (time.Time, time.Time) {
+	if s.Since > 0 {
+		now := time.Now()
+		return now.Add(-s.Since), now
+	}
+	return s.Query.Start, s.Query.End
+}
+
+func (s *Shell) parseTime(v string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339Nano, v); err == nil {
+		return t, nil
+	}
+	loc := s.OutputOptions.Timezone
+	if loc == nil {
+		loc = time.Local
+	}
+	return time.ParseInLocation("2006-01-02T15:04:05.999999999", v, loc)
+}
+
+func parseSwitch(v string) (bool, error) {
+	switch v {
+	case "on":
+		return true, nil
+	case "off":
+		return false, nil
+	}
+	return false, fmt.Errorf("invalid value %q, it must be on or off", v)
+}
+
+func (s *Shell) printHelp() {
+	fmt.Fprintln(s.out, "Type a LogQL query to run it. Queries with unclosed brackets or strings, or lines ending with \\, continue on the next line.")
+	fmt.Fprintln(s.out, "Press tab to complete label names and values, pipeline stages and functions.")
+	fmt.Fprintln(s.out)
+	for _, c := range commands {
+		fmt.Fprintf(s.out, "  %-30s %s\n", c.name+" "+c.args, c.help)
+	}
+}
+
+func (s *Shell) printSettings() {
+	start, end := s.timeRange()
+	timeRange := fmt.Sprintf("%s to %s", start.Format(time.RFC3339), end.Format(time.RFC3339))
+	if s.Since > 0 {
+		timeRange = fmt.Sprintf("last %s", model.Duration(s.Since))
+	}
+	direction := "backward"
+	if s.Query.Forward {
+		direction = "forward"
+	}
+	if c, ok := s.Client.(*client.DefaultClient); ok {
+		fmt.Fprintf(s.out, "addr:      %s\n", c.Address)
+	}
+	fmt.Fprintf(s.out, "org:       %s\n", s.Client.GetOrgID())
+	fmt.Fprintf(s.out, "range:     %s\n", timeRange)
+	fmt.Fprintf(s.out, "limit:     %d\n", s.Query.Limit)
+	fmt.Fprintf(s.out, "direction: %s\n", direction)
+	fmt.Fprintf(s.out, "step:      %s\n", s.Query.Step)
+	fmt.Fprintf(s.out, "instant:   %t\n", s.Instant)
+	fmt.Fprintf(s.out, "output:    %s\n", s.OutputMode)
+	fmt.Fprintf(s.out, "stats:     %t\n", s.Statistics)
+}
+
+func (s *Shell) printLabels(args []string) {
+	var values []string
+	var err error
+	if len(args) == 0 {
+		values, err = s.fetchLabels("")
+	} else {
+		values, err = s.fetchLabels(args[0])
+	}
+	if err != nil {
+		fmt.Fprintf(s.out, "error: %s\n", err)
+		return
+	}
+	for _, v := range values {
+		fmt.Fprintln(s.out, v)
+	}
+}
+
+// fetchLabels returns the label names, or the values of the label of the given name, from the cache or Loki.
+func (s *Shell) fetchLabels(name string) ([]string, error) {
+	if values, ok := s.labelValues[name]; ok {
+		return values, nil
+	}
+	start, end := s.timeRange()
+	var resp *loghttp.LabelResponse
+	var err error
+	if name == "" {
+		resp, err = s.Client.ListLabelNames(true, start, end)
+	} else {
+		resp, err = s.Client.ListLabelValues(name, true, start, end)
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.labelValues[name] = resp.Data
+	return resp.Data, nil
+}
+
+func (s *Shell) completer() *completer {
+	fetch := func(name string) []string {
+		values, _ := s.fetchLabels(name)
+		return values
+	}
+	return &completer{
+		labelNames:  func() []string { return fetch("") },
+		labelValues: fetch,
+	}
+}
+
+func (s *Shell) addHistory(input string) {
+	if len(s.history) > 0 && s.history[len(s.history)-1] == input {
+		return
+	}
+	s.history = append(s.history, input)
+	if len(s.history) > maxHistory {
+		s.history = s.history[len(s.history)-maxHistory:]
+	}
+	if s.HistoryFile == "" {
+		return
+	}
+	f, err := os.OpenFile(s.HistoryFile, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	// Multi-line entries are saved on a single line.
+	fmt.Fprintln(f, strings.ReplaceAll(input, "\n", " "))
+}
+
+func (s *Shell) loadHistory() {
+	if s.HistoryFile == "" {
+		return
+	}
+	b, err := os.ReadFile(s.HistoryFile)
+	if err != nil {
+		return
+	}
+	for _, line := range strings.Split(string(b), "\n") {
+		if line != "" {
+			s.history = append(s.history, line)
+		}
+	}
+	if len(s.history) > maxHistory {
+		s.history = s.history[len(s.history)-maxHistory:]
+	}
+}
+
+type lineReader interface {
+	readLine(prompt string) (string, error)
+}
+
+func (s *Shell) newLineReader(in io.Reader, out io.Writer) (lineReader, error) {
+	f, ok := in.(*os.File)
+	if !ok || !term.IsTerminal(int(f.Fd())) {
+		return &scannerReader{scanner: bufio.NewScanner(in)}, nil
+	}
+
+	fmt.Fprintln(out, "LogQL shell, type :help for help.")
+	rw := &switchReadWriter{}
+	t := term.NewTerminal(rw, prompt)
+
+	// The terminal doesn't allow setting its history, so the saved one is replayed through it.
+	if len(s.history) > 0 {
+		rw.Reader, rw.Writer = strings.NewReader(strings.Join(s.history, "\r")+"\r"), io.Discard
+		for range s.history {
+			if _, err := t.ReadLine(); err != nil {
+				break
+			}
+		}
+	}
+	rw.Reader, rw.Writer = in, out
+
+	c := s.completer()
+	t.AutoCompleteCallback = func(line string, pos int, key rune) (string, int, bool) {
+		if key != '\t' {
+			return "", 0, false
+		}
+		candidates, start := c.complete(line[:pos])
+		word := line[start:pos]
+		completion := commonPrefix(candidates)
+		if len(candidates) > 1 && len(completion) <= len(word) {
+			fmt.Fprintf(t, "%s\n", strings.Join(candidates, "  "))
+		}
+		if len(completion) <= len(word) {
+			return line, pos, true
+		}
+		return line[:start] + completion + line[pos:], start + len(completion), true
+	}
+	return &terminalReader{fd: int(f.Fd()), t: t}, nil
+}
+
+// terminalReader reads lines from a terminal, which is in raw mode only while a line is edited so the output of
+// the queries is written as usual.
+type terminalReader struct {
+	fd int
+	t  *term.Terminal
+}
+
+func (r *terminalReader) readLine(prompt string) (string, error) {
+	state, err := term.MakeRaw(r.fd)
+	if err != nil {
+		return "", err
+	}
+	defer term.Restore(r.fd, state) //nolint:errcheck
+
+	if width, height, err := term.GetSize(r.fd); err == nil && width > 0 {
+		_ = r.t.SetSize(width, height)
+	}
+	r.t.SetPrompt(prompt)
+	return r.t.ReadLine()
+}
+
+type scannerReader struct {
+	scanner *bufio.Scanner
+}
+
+func (r *scannerReader) readLine(_ string) (string, error) {
+	if !r.scanner.Scan() {
+		if err := r.scanner.Err(); err != nil {
+			return "", err
+		}
+		return "", io.EOF
+	}
+	return r.scanner.Text(), nil
+}
+
+type switchReadWriter struct {
+	io.Reader
+	io.Writer
+}
@@ -0,0 +1,123 @@
+package shell
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/logcli/client"
+	"github.com/grafana/loki/v3/pkg/logcli/query"
+)
+
+func TestCompleter(t *testing.T) {
+	c := &completer{
+		labelNames: func() []string { return []string{"app", "cluster", "container"} },
+		labelValues: func(name string) []string {
+			if name == "app" {
+				return []string{"api", "db", "dashboard"}
+			}
+			return nil
+		},
+	}
+
+	for _, tc := range []struct {
+		prefix     string
+		candidates []string
+		start      int
+	}{
+		{prefix: `{c`, candidates: []string{"cluster", "container"}, start: 1},
+		{prefix: `{app="api", cl`, candidates: []string{"cluster"}, start: 12},
+		{prefix: `{app="d`, candidates: []string{"dashboard", "db"}, start: 6},
+		{prefix: `{app=~"a`, candidates: []string{"api"}, start: 7},
+		{prefix: `{app="api"} |= "d`, candidates: nil, start: 16},
+		{prefix: `{app="api"} | js`, candidates: []string{"json"}, start: 14},
+		{prefix: `{app="api"} | l`, candidates: []string{"label_format", "limit_per_stream", "line_format", "logfmt"}, start: 14},
+		{prefix: `count_o`, candidates: []string{"count_over_time"}, start: 0},
+		{prefix: `sum by (a`, candidates: []string{"app"}, start: 8},
+		{prefix: `sum(rate({app="api"}[5m])) without (`, candidates: []string{"app", "cluster", "container"}, start: 36},
+		{prefix: `:li`, candidates: []string{":limit"}, start: 0},
+		{prefix: `:labels a`, candidates: []string{"app"}, start: 8},
+		{prefix: `:limit 1`, candidates: nil, start: 7},
+	} {
+		t.Run(tc.prefix, func(t *testing.T) {
+			candidates, start := c.complete(tc.prefix)
+			assert.Equal(t, tc.candidates, candidates)
+			assert.Equal(t, tc.start, start)
+		})
+	}
+}
+
+func TestIncomplete(t *testing.T) {
+	assert.True(t, incomplete(`{app="api"`))
+	assert.True(t, incomplete(`{app="api`))
+	assert.True(t, incomplete(`sum(rate({app="api"}[5m]`))
+	assert.False(t, incomplete(`{app="api"} |= "{"`))
+	assert.False(t, incomplete(`sum(rate({app="api"}[5m]))`))
+}
+
+func TestShell_Run(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "api"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "api", "0.log"), []byte(strings.Join([]string{
+		`2024-01-01T10:00:00Z level=info msg="started"`,
+		`2024-01-01T10:01:00Z level=error msg="request failed"`,
+		`2024-01-01T10:02:00Z level=info msg="done"`,
+	}, "\n")+"\n"), 0o644))
+
+	c, err := client.NewLocalClient(client.LocalConfig{
+		Paths:           []string{dir},
+		PathTemplate:    filepath.Join(dir, "{app}", "{pod}.log"),
+		TimestampFormat: "RFC3339",
+		Location:        time.UTC,
+	})
+	require.NoError(t, err)
+
+	history := filepath.Join(dir, "history")
+	s := &Shell{
+		Client:      c,
+		Query:       query.Query{Limit: 30, BatchSize: 1000, Quiet: true, ParallelMaxWorkers: 1},
+		Since:       time.Hour,
+		OutputMode:  "raw",
+		HistoryFile: history,
+	}
+
+	in := strings.NewReader(strings.Join([]string{
+		`:from 2024-01-01T10:00:00Z`,
+		`:to 2024-01-01T11:00:00Z`,
+		`:direction forward`,
+		`{app="api"} \`,
+		`  |= "level=info"`,
+		`:labels app`,
+		`{app="api"} | logfmt | level="error" \`,
+		`  | line_format "{{.msg}}"`,
+		`:fmt`,
+		`{app=`,
+		`}`,
+		`:limit x`,
+		`:quit`,
+		`{app="api"}`,
+	}, "\n"))
+	var out bytes.Buffer
+	require.NoError(t, s.Run(in, &out))
+
+	assert.Equal(t, strings.Join([]string{
+		`2024-01-01T10:00:00Z level=info msg="started"`,
+		`2024-01-01T10:02:00Z level=info msg="done"`,
+		`api`,
+		`request failed`,
+		`{app="api"} | logfmt | level="error" | line_format "{{.msg}}"`,
+		`invalid query: parse error at line 2, col 1: syntax error: unexpected }, expecting STRING`,
+		`error: strconv.Atoi: parsing "x": invalid syntax`,
+	}, "\n")+"\n", out.String())
+
+	b, err := os.ReadFile(history)
+	require.NoError(t, err)
+	assert.Contains(t, string(b), `{app="api"} | logfmt | level="error"    | line_format "{{.msg}}"`+"\n")
+	assert.True(t, strings.HasSuffix(string(b), ":quit\n"))
+}
@@ -0,0 +1,132 @@
+package util
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var timestampLayouts = map[string]string{
+	"ANSIC":       time.ANSIC,
+	"UnixDate":    time.UnixDate,
+	"RubyDate":    time.RubyDate,
+	"RFC822":      time.RFC822,
+	"RFC822Z":     time.RFC822Z,
+	"RFC850":      time.RFC850,
+	"RFC1123":     time.RFC1123,
+	"RFC1123Z":    time.RFC1123Z,
+	"RFC3339":     time.RFC3339,
+	"RFC3339Nano": time.RFC3339Nano,
+	"Kitchen":     time.Kitchen,
+	"Stamp":       time.Stamp,
+	"StampMilli":  time.StampMilli,
+	"StampMicro":  time.StampMicro,
+	"StampNano":   time.StampNano,
+	"DateTime":    time.DateTime,
+}
+
+// TimestampParser finds and parses the timestamps of log lines. A nil parser doesn't find any timestamp.
+type TimestampParser struct {
+	re    *regexp.Regexp
+	parse func(string) (time.Time, error)
+}
+
+// NewTimestampParser returns a parser of the timestamps of the given format: a Go time layout, the name of one
+// of the layouts of the time package (e.g. RFC3339) or one of Unix, UnixMs, UnixUs and UnixNs. The timestamp is
+// the first match of expr in the lines, or of its first capture group if it has one, by default the first field
+// of the line. The parser is nil if the format is empty.
+func NewTimestampParser(format, expr string, loc *time.Location) (*TimestampParser, error) {
+	if format == "" {
+		return nil, nil
+	}
+	if expr == "" {
+		expr = `^\S+`
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timestamp regexp %s: %w", expr, err)
+	}
+	if loc == nil {
+		loc = time.Local
+	}
+
+	p := &TimestampParser{re: re}
+	switch format {
+	case "Unix":
+		p.parse = parseUnixSeconds
+	case "UnixMs":
+		p.parse = parseUnix(time.Millisecond)
+	case "UnixUs":
+		p.parse = parseUnix(time.Microsecond)
+	case "UnixNs":
+		p.parse = parseUnix(time.Nanosecond)
+	default:
+		layout := format
+		if l, ok := timestampLayouts[format]; ok {
+			layout = l
+		}
+		p.parse = func(s string) (time.Time, error) {
+			t, err := time.ParseInLocation(layout, s, loc)
+			if err != nil {
+				return t, err
+			}
+			// Layouts like Stamp don't have a year.
+			if t.Year() == 0 {
+				t = t.AddDate(time.Now().In(loc).Year(), 0, 0)
+			}
+			return t, nil
+		}
+	}
+	return p, nil
+}
+
+// Timestamp returns the timestamp of the line in nanoseconds, and false if it has none.
+func (p *TimestampParser) Timestamp(line string) (int64, bool) {
+	if p == nil {
+		return 0, false
+	}
+	match := p.re.FindStringSubmatch(line)
+	if match == nil {
+		return 0, false
+	}
+	s := match[0]
+	if len(match) > 1 {
+		s = match[1]
+	}
+	t, err := p.parse(s)
+	if err != nil {
+		return 0, false
+	}
+	return t.UnixNano(), true
+}
+
+func parseUnix(unit time.Duration) func(string) (time.Time, error) {
+	return func(s string) (time.Time, error) {
+		v, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return time.Unix(0, v*int64(unit)), nil
+	}
+}
+
+// parseUnixSeconds parses seconds with an optional fractional part, e.g. 1625995076.123.
+func parseUnixSeconds(s string) (time.Time, error) {
+	secs, frac, _ := strings.Cut(s, ".")
+	sec, err := strconv.ParseInt(secs, 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	var nsec int64
+	if frac != "" {
+		if len(frac) > 9 {
+			frac = frac[:9]
+		}
+		if nsec, err = strconv.ParseInt(frac+strings.Repeat("0", 9-len(frac)), 10, 64); err != nil {
+			return time.Time{}, err
+		}
+	}
+	return time.Unix(sec, nsec), nil
+}
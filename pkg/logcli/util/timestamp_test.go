@@ -0,0 +1,32 @@
+package util
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimestampParser(t *testing.T) {
+	for _, tc := range []struct {
+		format, regexp, line string
+		expected             time.Time
+	}{
+		{"RFC3339Nano", "", "2024-01-01T10:00:00.5Z msg=hello", time.Date(2024, 1, 1, 10, 0, 0, 5e8, time.UTC)},
+		{"Unix", "", "1704103200.25 msg=hello", time.Date(2024, 1, 1, 10, 0, 0, 25e7, time.UTC)},
+		{"UnixMs", `ts=(\d+)`, "msg=hello ts=1704103200001", time.Date(2024, 1, 1, 10, 0, 0, 1e6, time.UTC)},
+		{"2006/01/02 15:04:05", `^\S+ \S+`, "2024/01/01 10:00:00 hello", time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)},
+	} {
+		t.Run(tc.format, func(t *testing.T) {
+			p, err := NewTimestampParser(tc.format, tc.regexp, time.UTC)
+			require.NoError(t, err)
+			ts, ok := p.Timestamp(tc.line)
+			require.True(t, ok)
+			assert.Equal(t, tc.expected.UnixNano(), ts)
+
+			_, ok = p.Timestamp("not a timestamp")
+			assert.False(t, ok)
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package loghttp
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/cespare/xxhash/v2"
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultContextLines  = 10
+	defaultContextWindow = time.Hour
+)
+
+// ContextQuery defines a query for the context of a log entry.
+type ContextQuery struct {
+	// Query is the stream selector of the streams holding the entry.
+	Query     string
+	Timestamp time.Time
+	LineHash  uint64
+	Before    uint32
+	After     uint32
+	// Window is the duration searched for context on each side of the entry.
+	Window time.Duration
+	// Related are the names of the labels whose values the streams included in the context
+	// share with the stream of the entry. By default, only the stream of the entry is included.
+	Related []string
+}
+
+// ParseContextQuery parses a ContextQuery request from an http request.
+func ParseContextQuery(r *http.Request) (*ContextQuery, error) {
+	var err error
+	result := &ContextQuery{
+		Query:   query(r),
+		Related: r.Form["related"],
+	}
+
+	ts := r.Form.Get("ts")
+	if ts == "" {
+		return nil, errors.New("ts parameter is required")
+	}
+	result.Timestamp, err = parseTimestamp(ts, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+
+	hash := r.Form.Get("line_hash")
+	if hash == "" {
+		return nil, errors.New("line_hash parameter is required")
+	}
+	result.LineHash, err = strconv.ParseUint(hash, 16, 64)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid line_hash %q", hash)
+	}
+
+	before, err := parseInt(r.Form.Get("before"), defaultContextLines)
+	if err != nil {
+		return nil, err
+	}
+	after, err := parseInt(r.Form.Get("after"), defaultContextLines)
+	if err != nil {
+		return nil, err
+	}
+	if before < 0 || after < 0 {
+		return nil, errors.New("before and after must not be negative")
+	}
+	result.Before, result.After = uint32(before), uint32(after)
+
+	result.Window = defaultContextWindow
+	if w := r.Form.Get("window"); w != "" {
+		result.Window, err = parseSecondsOrDuration(w)
+		if err != nil {
+			return nil, err
+		}
+		if result.Window <= 0 {
+			return nil, errors.New("window must be a positive value")
+		}
+	}
+
+	return result, nil
+}
+
+// ContextLineHash returns the hash identifying a log line in a context query.
+func ContextLineHash(line string) uint64 {
+	return xxhash.Sum64String(line)
+}
+
+// FormatContextLineHash formats the hash of a log line as expected by the line_hash parameter.
+func FormatContextLineHash(line string) string {
+	return fmt.Sprintf("%016x", ContextLineHash(line))
+}
+
+// ContextEntry is a log entry returned by the context API.
+type ContextEntry struct {
+	Stream             LabelSet `json:"stream"`
+	Timestamp          string   `json:"ts"`
+	Line               string   `json:"line"`
+	StructuredMetadata LabelSet `json:"structured_metadata,omitempty"`
+}
+
+// ContextResponse is the response of the context API. The entries before and after the entry
+// are ordered by timestamp, and entries sharing a timestamp by stream.
+type ContextResponse struct {
+	Status string              `json:"status"`
+	Data   ContextResponseData `json:"data"`
+}
+
+// ContextResponseData is the data of the context API response.
+type ContextResponseData struct {
+	Entry  ContextEntry   `json:"entry"`
+	Before []ContextEntry `json:"before"`
+	After  []ContextEntry `json:"after"`
+}
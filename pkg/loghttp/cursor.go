@@ -0,0 +1,66 @@
+package loghttp
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"github.com/cespare/xxhash/v2"
+)
+
+// Cursor is the position of a paginated query or an export: the timestamp of the last returned
+// entries and the hashes of the ones returned with this timestamp, as several entries can share it.
+type Cursor struct {
+	Timestamp int64    `json:"ts"`
+	Seen      []uint64 `json:"seen,omitempty"`
+}
+
+// Observe moves the cursor to a returned entry.
+func (c *Cursor) Observe(ts int64, labels, line string) {
+	if ts != c.Timestamp {
+		c.Timestamp, c.Seen = ts, c.Seen[:0]
+	}
+	c.Seen = append(c.Seen, EntryHash(labels, line))
+}
+
+// Returned returns whether an entry has already been returned.
+func (c *Cursor) Returned(ts int64, labels, line string) bool {
+	if ts != c.Timestamp {
+		return false
+	}
+	h := EntryHash(labels, line)
+	for _, seen := range c.Seen {
+		if seen == h {
+			return true
+		}
+	}
+	return false
+}
+
+// Encode returns the opaque representation of the cursor.
+func (c *Cursor) Encode() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// DecodeCursor decodes a cursor encoded with Cursor.Encode.
+func DecodeCursor(s string) (*Cursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor: %w", err)
+	}
+	var c Cursor
+	if err := json.Unmarshal(b, &c); err != nil {
+		return nil, fmt.Errorf("invalid cursor: %w", err)
+	}
+	return &c, nil
+}
+
+// EntryHash identifies an entry among the ones sharing its timestamp.
+func EntryHash(labels, line string) uint64 {
+	h := xxhash.New()
+	_, _ = h.WriteString(labels)
+	_, _ = h.Write([]byte{0xff})
+	_, _ = h.WriteString(line)
+	return h.Sum64()
+}
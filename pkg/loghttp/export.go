@@ -0,0 +1,99 @@
+package loghttp
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/grafana/loki/v3/pkg/logproto"
+)
+
+const (
+	defaultExportPageSize  = 1000
+	defaultExportDirection = logproto.FORWARD
+)
+
+// ExportQuery defines a log export query.
+type ExportQuery struct {
+	Query     string
+	Start     time.Time
+	End       time.Time
+	Direction logproto.Direction
+	// Limit is the maximum number of entries to export, 0 meaning no limit.
+	Limit    uint32
+	PageSize uint32
+	Cursor   *Cursor
+}
+
+// ParseExportQuery parses an ExportQuery request from an http request.
+func ParseExportQuery(r *http.Request) (*ExportQuery, error) {
+	var err error
+	result := &ExportQuery{
+		Query: query(r),
+	}
+
+	result.Start, result.End, err = bounds(r)
+	if err != nil {
+		return nil, err
+	}
+	if result.End.Before(result.Start) {
+		return nil, errEndBeforeStart
+	}
+
+	result.Direction, err = parseDirection(r.Form.Get("direction"), defaultExportDirection)
+	if err != nil {
+		return nil, err
+	}
+
+	limit, err := parseInt(r.Form.Get("limit"), 0)
+	if err != nil {
+		return nil, err
+	}
+	if limit < 0 {
+		return nil, errors.New("limit must not be negative")
+	}
+	result.Limit = uint32(limit)
+
+	pageSize, err := parseInt(r.Form.Get("page_size"), defaultExportPageSize)
+	if err != nil {
+		return nil, err
+	}
+	if pageSize <= 0 {
+		return nil, errors.New("page_size must be a positive value")
+	}
+	result.PageSize = uint32(pageSize)
+
+	if c := r.Form.Get("cursor"); c != "" {
+		result.Cursor, err = DecodeCursor(c)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// ExportLine is a line of the newline delimited JSON response of the export API.
+// It holds either an entry, the cursor to resume the export after the entries written
+// so far, the end of the export or an error which interrupted it.
+type ExportLine struct {
+	Stream             LabelSet `json:"stream,omitempty"`
+	Timestamp          string   `json:"ts,omitempty"`
+	Line               string   `json:"line,omitempty"`
+	StructuredMetadata LabelSet `json:"structured_metadata,omitempty"`
+
+	Cursor string `json:"cursor,omitempty"`
+	Done   bool   `json:"done,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// IsEntry returns whether the line holds an entry.
+func (l ExportLine) IsEntry() bool {
+	return l.Timestamp != ""
+}
+
+// Time returns the timestamp of the entry held by the line.
+func (l ExportLine) Time() (time.Time, error) {
+	return parseTimestamp(l.Timestamp, time.Time{})
+}
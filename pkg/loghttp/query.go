@@ -226,6 +226,8 @@ type QueryResponseData struct {
 	ResultType ResultType   `json:"resultType"`
 	Result     ResultValue  `json:"result"`
 	Statistics stats.Result `json:"stats"`
+	// Cursor is the position of the next page of a log query, set when the limit was reached.
+	Cursor string `json:"cursor,omitempty"`
 }
 
 // Type implements the promql.Value interface
@@ -361,6 +363,8 @@ func (q *QueryResponseData) UnmarshalJSON(data []byte) error {
 			if err := json.Unmarshal(value, &q.Statistics); err != nil {
 				return err
 			}
+		case "cursor":
+			q.Cursor = string(value)
 		}
 		return nil
 	})
@@ -432,6 +436,8 @@ type RangeQuery struct {
 	Direction logproto.Direction
 	Limit     uint32
 	Shards    []string
+	// Cursor is the position of the page of a log query to return.
+	Cursor *Cursor
 }
 
 func NewRangeQueryWithDefaults() *RangeQuery {
@@ -488,6 +494,13 @@ func ParseRangeQuery(r *http.Request) (*RangeQuery, error) {
 
 	result.Shards = shards(r)
 
+	if c := r.Form.Get("cursor"); c != "" {
+		result.Cursor, err = DecodeCursor(c)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// For safety, limit the number of returned points per timeseries.
 	// This is sufficient for 60s resolution for a week or 1h resolution for a year.
 	if (result.End.Sub(result.Start) / result.Step) > 11000 {
@@ -134,12 +134,16 @@ type EngineOpts struct {
 	// only used for instant log queries.
 	MaxLookBackPeriod time.Duration `yaml:"max_look_back_period"`
 
+	// MaxDistinctKeys is the maximum number of keys the distinct stages of a query keep.
+	MaxDistinctKeys int `yaml:"max_distinct_keys"`
+
 	// LogExecutingQuery will control if we log the query when Exec is called.
 	LogExecutingQuery bool `yaml:"-"`
 }
 
 func (opts *EngineOpts) RegisterFlagsWithPrefix(prefix string, f *flag.FlagSet) {
 	f.DurationVar(&opts.MaxLookBackPeriod, prefix+".engine.max-lookback-period", 30*time.Second, "The maximum amount of time to look back for log lines. Used only for instant log queries.")
+	f.IntVar(&opts.MaxDistinctKeys, prefix+".engine.max-distinct-keys", 100000, "The maximum number of combinations of label values the distinct stages of a query keep in memory. Queries exceeding it fail. 0 for no limit.")
 	// Log executing query by default
 	opts.LogExecutingQuery = true
 }
@@ -166,7 +170,7 @@ func NewEngine(opts EngineOpts, q Querier, l Limits, logger log.Logger) *Engine
 	}
 	return &Engine{
 		logger:           logger,
-		evaluatorFactory: NewDefaultEvaluator(q, opts.MaxLookBackPeriod),
+		evaluatorFactory: NewDefaultEvaluator(q, opts.MaxLookBackPeriod, opts.MaxDistinctKeys),
 		limits:           l,
 		opts:             opts,
 	}
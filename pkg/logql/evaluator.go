@@ -15,6 +15,7 @@ import (
 
 	"github.com/grafana/loki/v3/pkg/iter"
 	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/logql/log"
 	"github.com/grafana/loki/v3/pkg/logql/syntax"
 	"github.com/grafana/loki/v3/pkg/logqlmodel"
 	"github.com/grafana/loki/v3/pkg/querier/plan"
@@ -191,28 +192,31 @@ func EvaluatorUnsupportedType(expr syntax.Expr, ev EvaluatorFactory) error {
 
 type DefaultEvaluator struct {
 	maxLookBackPeriod time.Duration
+	maxDistinctKeys   int
 	querier           Querier
 }
 
 // NewDefaultEvaluator constructs a DefaultEvaluator
-func NewDefaultEvaluator(querier Querier, maxLookBackPeriod time.Duration) *DefaultEvaluator {
+func NewDefaultEvaluator(querier Querier, maxLookBackPeriod time.Duration, maxDistinctKeys int) *DefaultEvaluator {
 	return &DefaultEvaluator{
 		querier:           querier,
 		maxLookBackPeriod: maxLookBackPeriod,
+		maxDistinctKeys:   maxDistinctKeys,
 	}
 }
 
 func (ev *DefaultEvaluator) NewIterator(ctx context.Context, expr syntax.LogSelectorExpr, q Params) (iter.EntryIterator, error) {
+	selector, stateful := syntax.SplitStatefulStages(expr)
 	params := SelectLogParams{
 		QueryRequest: &logproto.QueryRequest{
 			Start:     q.Start(),
 			End:       q.End(),
 			Limit:     q.Limit(),
 			Direction: q.Direction(),
-			Selector:  expr.String(),
+			Selector:  selector.String(),
 			Shards:    q.Shards(),
 			Plan: &plan.QueryPlan{
-				AST: expr,
+				AST: selector,
 			},
 		},
 	}
@@ -221,7 +225,60 @@ func (ev *DefaultEvaluator) NewIterator(ctx context.Context, expr syntax.LogSele
 		params.Start = params.Start.Add(-ev.maxLookBackPeriod)
 	}
 
-	return ev.querier.SelectLogs(ctx, params)
+	if len(stateful) == 0 {
+		return ev.querier.SelectLogs(ctx, params)
+	}
+
+	// The stateful stages drop lines once selected, so the limit can't be applied to the selected lines.
+	// The lines are selected lazily instead, and the selection stops once the stages kept limit lines.
+	params.Limit = 0
+	limiter := log.NewDistinctLimiter(ev.maxDistinctKeys)
+	pipeline, err := withDistinctLimiter(stateful, limiter).Pipeline()
+	if err != nil {
+		return nil, err
+	}
+	it, err := ev.querier.SelectLogs(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	return newStatefulEntryIterator(it, pipeline, limiter, q.Limit()), nil
+}
+
+// newStatefulSampleIterator selects the lines of a range aggregation holding stateful stages, and extracts
+// its samples from the merged lines.
+func (ev *DefaultEvaluator) newStatefulSampleIterator(ctx context.Context, expr *syntax.RangeAggregationExpr, q Params) (iter.SampleIterator, error) {
+	selector, stateful := syntax.SplitStatefulStages(expr.Left.Left)
+
+	remaining, err := syntax.Clone(expr)
+	if err != nil {
+		return nil, err
+	}
+	limiter := log.NewDistinctLimiter(ev.maxDistinctKeys)
+	remaining.Left.Left = &syntax.PipelineExpr{
+		Left:        &syntax.MatchersExpr{Mts: selector.Matchers()},
+		MultiStages: withDistinctLimiter(stateful, limiter),
+	}
+	extractor, err := remaining.Extractor()
+	if err != nil {
+		return nil, err
+	}
+
+	it, err := ev.querier.SelectLogs(ctx, SelectLogParams{
+		&logproto.QueryRequest{
+			Start:     q.Start().Add(-expr.Left.Interval).Add(-expr.Left.Offset),
+			End:       q.End().Add(-expr.Left.Offset),
+			Direction: logproto.FORWARD,
+			Selector:  selector.String(),
+			Shards:    q.Shards(),
+			Plan: &plan.QueryPlan{
+				AST: selector,
+			},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return newStatefulSampleIterator(it, extractor, limiter), nil
 }
 
 func (ev *DefaultEvaluator) NewStepEvaluator(
@@ -232,7 +289,7 @@ func (ev *DefaultEvaluator) NewStepEvaluator(
 ) (StepEvaluator, error) {
 	switch e := expr.(type) {
 	case *syntax.VectorAggregationExpr:
-		if rangExpr, ok := e.Left.(*syntax.RangeAggregationExpr); ok && e.Operation == syntax.OpTypeSum {
+		if rangExpr, ok := e.Left.(*syntax.RangeAggregationExpr); ok && e.Operation == syntax.OpTypeSum && !syntax.HasStatefulStage(rangExpr) {
 			// if range expression is wrapped with a vector expression
 			// we should send the vector expression for allowing reducing labels at the source.
 			nextEvFactory = SampleEvaluatorFunc(func(ctx context.Context, _ SampleEvaluatorFactory, _ syntax.SampleExpr, _ Params) (StepEvaluator, error) {
@@ -255,6 +312,13 @@ func (ev *DefaultEvaluator) NewStepEvaluator(
 		}
 		return newVectorAggEvaluator(ctx, nextEvFactory, e, q)
 	case *syntax.RangeAggregationExpr:
+		if syntax.HasStatefulStage(e) {
+			it, err := ev.newStatefulSampleIterator(ctx, e, q)
+			if err != nil {
+				return nil, err
+			}
+			return newRangeAggEvaluator(iter.NewPeekingSampleIterator(it), e, q, e.Left.Offset)
+		}
 		it, err := ev.querier.SelectSamples(ctx, SelectSampleParams{
 			&logproto.SampleQueryRequest{
 				Start:    q.Start().Add(-e.Left.Interval).Add(-e.Left.Offset),
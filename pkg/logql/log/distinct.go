@@ -0,0 +1,78 @@
+package log
+
+import (
+	"strings"
+
+	"github.com/grafana/loki/v3/pkg/logqlmodel"
+)
+
+// DistinctLimiter bounds the number of keys the Distinct stages of a query keep in memory.
+type DistinctLimiter struct {
+	max, keys int
+	err       error
+}
+
+// NewDistinctLimiter creates a new DistinctLimiter allowing up to max keys, 0 for no limit.
+func NewDistinctLimiter(max int) *DistinctLimiter {
+	return &DistinctLimiter{max: max}
+}
+
+// Err returns the error of the query once its Distinct stages kept more than the maximum number of keys.
+func (l *DistinctLimiter) Err() error {
+	return l.err
+}
+
+func (l *DistinctLimiter) add() bool {
+	if l.max > 0 && l.keys >= l.max {
+		l.err = logqlmodel.NewDistinctKeysLimitError(l.max)
+		return false
+	}
+	l.keys++
+	return true
+}
+
+// Distinct is a stage keeping the first line of each combination of values of its labels.
+// Lines missing any of the labels are kept. As it depends on the lines processed before,
+// a Distinct stage must process every line of a query, in the order of the query.
+type Distinct struct {
+	labels  []string
+	limiter *DistinctLimiter
+	seen    map[string]struct{}
+	buf     strings.Builder
+}
+
+// NewDistinct creates a new Distinct stage for the given label names. Once limiter is exceeded, lines
+// of new combinations of values are dropped and limiter holds the error of the query. A nil limiter
+// doesn't limit the number of combinations.
+func NewDistinct(labels []string, limiter *DistinctLimiter) *Distinct {
+	return &Distinct{
+		labels:  labels,
+		limiter: limiter,
+		seen:    map[string]struct{}{},
+	}
+}
+
+func (d *Distinct) Process(_ int64, line []byte, lbs *LabelsBuilder) ([]byte, bool) {
+	d.buf.Reset()
+	for _, name := range d.labels {
+		value, ok := lbs.Get(name)
+		if !ok {
+			return line, true
+		}
+		d.buf.WriteString(value)
+		d.buf.WriteByte(0xff)
+	}
+	key := d.buf.String()
+	if _, ok := d.seen[key]; ok {
+		return line, false
+	}
+	if d.limiter != nil && !d.limiter.add() {
+		return line, false
+	}
+	d.seen[key] = struct{}{}
+	return line, true
+}
+
+func (d *Distinct) RequiredLabelNames() []string {
+	return d.labels
+}
@@ -0,0 +1,61 @@
+package log
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/logqlmodel"
+)
+
+func Test_Distinct(t *testing.T) {
+	distinct := NewDistinct([]string{"user", "method"}, nil)
+
+	var kept []string
+	for _, lbs := range []labels.Labels{
+		labels.FromStrings("app", "foo", "user", "alice", "method", "GET"),
+		labels.FromStrings("app", "foo", "user", "alice", "method", "POST"),
+		labels.FromStrings("app", "bar", "user", "alice", "method", "GET"),
+		labels.FromStrings("app", "foo", "user", "bob", "method", "GET"),
+		labels.FromStrings("app", "foo", "user", "bob"),
+		labels.FromStrings("app", "foo", "user", "bob"),
+		labels.FromStrings("app", "foo", "user", "bob", "method", "POST"),
+	} {
+		b := NewBaseLabelsBuilder().ForLabels(lbs, lbs.Hash())
+		b.Reset()
+		if _, ok := distinct.Process(0, []byte("line"), b); ok {
+			kept = append(kept, lbs.String())
+		}
+	}
+	require.Equal(t, []string{
+		`{app="foo", method="GET", user="alice"}`,
+		`{app="foo", method="POST", user="alice"}`,
+		`{app="foo", method="GET", user="bob"}`,
+		`{app="foo", user="bob"}`,
+		`{app="foo", user="bob"}`,
+		`{app="foo", method="POST", user="bob"}`,
+	}, kept)
+	require.Equal(t, []string{"user", "method"}, distinct.RequiredLabelNames())
+}
+
+func Test_DistinctLimiter(t *testing.T) {
+	limiter := NewDistinctLimiter(2)
+	first, second := NewDistinct([]string{"user"}, limiter), NewDistinct([]string{"method"}, limiter)
+
+	process := func(d *Distinct, lbs labels.Labels) bool {
+		b := NewBaseLabelsBuilder().ForLabels(lbs, lbs.Hash())
+		b.Reset()
+		_, ok := d.Process(0, []byte("line"), b)
+		return ok
+	}
+	require.True(t, process(first, labels.FromStrings("user", "alice")))
+	require.False(t, process(first, labels.FromStrings("user", "alice", "method", "GET")))
+	require.True(t, process(second, labels.FromStrings("method", "GET")))
+	require.NoError(t, limiter.Err())
+
+	// The keys already seen are still deduplicated once the limit is reached, new keys fail the query.
+	require.False(t, process(second, labels.FromStrings("method", "GET")))
+	require.False(t, process(first, labels.FromStrings("user", "bob")))
+	require.ErrorIs(t, limiter.Err(), logqlmodel.ErrLimit)
+}
@@ -0,0 +1,28 @@
+package log
+
+// LimitPerStream is a stage keeping the first lines of each stream, the stream being the labels of the line
+// when it reaches the stage. As it depends on the lines processed before, a LimitPerStream stage must
+// process every line of a query, in the order of the query.
+type LimitPerStream struct {
+	limit  uint64
+	counts map[uint64]uint64
+}
+
+// NewLimitPerStream creates a new LimitPerStream stage keeping up to limit lines per stream.
+func NewLimitPerStream(limit uint64) *LimitPerStream {
+	return &LimitPerStream{
+		limit:  limit,
+		counts: map[uint64]uint64{},
+	}
+}
+
+func (l *LimitPerStream) Process(_ int64, line []byte, lbs *LabelsBuilder) ([]byte, bool) {
+	hash := lbs.LabelsResult().Hash()
+	if l.counts[hash] >= l.limit {
+		return line, false
+	}
+	l.counts[hash]++
+	return line, true
+}
+
+func (l *LimitPerStream) RequiredLabelNames() []string { return nil }
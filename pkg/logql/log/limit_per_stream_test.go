@@ -0,0 +1,52 @@
+package log
+
+import (
+	"testing"
+
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_LimitPerStream(t *testing.T) {
+	limit := NewLimitPerStream(2)
+	base := NewBaseLabelsBuilder()
+
+	kept := map[string]int{}
+	for _, lbs := range []labels.Labels{
+		labels.FromStrings("app", "foo"),
+		labels.FromStrings("app", "bar"),
+		labels.FromStrings("app", "foo"),
+		labels.FromStrings("app", "foo"),
+		labels.FromStrings("app", "bar"),
+		labels.FromStrings("app", "baz"),
+		labels.FromStrings("app", "bar"),
+	} {
+		b := base.ForLabels(lbs, lbs.Hash())
+		b.Reset()
+		if _, ok := limit.Process(0, []byte("line"), b); ok {
+			kept[lbs.String()]++
+		}
+	}
+	require.Equal(t, map[string]int{
+		`{app="foo"}`: 2,
+		`{app="bar"}`: 2,
+		`{app="baz"}`: 1,
+	}, kept)
+}
+
+func Test_LimitPerStream_ExtractedLabels(t *testing.T) {
+	limit := NewLimitPerStream(1)
+	lbs := labels.FromStrings("app", "foo")
+	base := NewBaseLabelsBuilder()
+
+	var kept []string
+	for _, level := range []string{"info", "error", "info"} {
+		b := base.ForLabels(lbs, lbs.Hash())
+		b.Reset()
+		b.Set(ParsedLabel, "level", level)
+		if _, ok := limit.Process(0, []byte("line"), b); ok {
+			kept = append(kept, level)
+		}
+	}
+	require.Equal(t, []string{"info", "error"}, kept)
+}
@@ -0,0 +1,32 @@
+package log
+
+import (
+	"encoding/binary"
+	"math"
+
+	"github.com/cespare/xxhash/v2"
+)
+
+// Sample is a stage keeping a ratio of the lines. The lines are kept depending on the hash of their
+// timestamp and content, so the same lines are kept whichever querier, shard or replica processes them.
+type Sample struct {
+	threshold uint64
+}
+
+// NewSample creates a new Sample stage keeping the given ratio of the lines, between 0 and 1 excluded.
+func NewSample(ratio float64) *Sample {
+	return &Sample{threshold: uint64(ratio * math.MaxUint64)}
+}
+
+func (s *Sample) Process(ts int64, line []byte, _ *LabelsBuilder) ([]byte, bool) {
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], uint64(ts))
+
+	var h xxhash.Digest
+	h.Reset()
+	_, _ = h.Write(buf[:])
+	_, _ = h.Write(line)
+	return line, h.Sum64() < s.threshold
+}
+
+func (s *Sample) RequiredLabelNames() []string { return nil }
@@ -0,0 +1,34 @@
+package log
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Sample(t *testing.T) {
+	sample := NewSample(0.1)
+
+	var kept []int64
+	for ts := int64(0); ts < 10000; ts++ {
+		if _, ok := sample.Process(ts, []byte(fmt.Sprintf("line %d", ts%100)), nil); ok {
+			kept = append(kept, ts)
+		}
+	}
+	require.InDelta(t, 1000, len(kept), 100)
+
+	// Another stage keeps the same lines.
+	sample = NewSample(0.1)
+	for _, ts := range kept {
+		_, ok := sample.Process(ts, []byte(fmt.Sprintf("line %d", ts%100)), nil)
+		require.True(t, ok)
+	}
+
+	// A larger ratio keeps the lines kept by a smaller one.
+	sample = NewSample(0.5)
+	for _, ts := range kept {
+		_, ok := sample.Process(ts, []byte(fmt.Sprintf("line %d", ts%100)), nil)
+		require.True(t, ok)
+	}
+}
@@ -561,7 +561,7 @@ func isSplittableByRange(expr syntax.SampleExpr) bool {
 		return ok && isSplittableByRange(e.Left)
 	case *syntax.RangeAggregationExpr:
 		_, ok := splittableRangeVectorOp[e.Operation]
-		return ok
+		return ok && !syntax.HasStatefulStage(e)
 	case *syntax.BinOpExpr:
 		_, literalLHS := e.SampleExpr.(*syntax.LiteralExpr)
 		_, literalRHS := e.RHS.(*syntax.LiteralExpr)
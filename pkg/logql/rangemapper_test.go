@@ -1850,6 +1850,12 @@ func Test_SplitRangeVectorMapping_Noop(t *testing.T) {
 			`bytes_over_time({app="foo"}[1m])`,
 		},
 
+		// should be noop if the range aggregation has a stateful stage, which needs every line of the range
+		{
+			`count_over_time({app="foo"} | distinct bar [3m])`,
+			`count_over_time({app="foo"} | distinct bar [3m])`,
+		},
+
 		// should be noop if inner range aggregation includes a stage for label extraction such as `| json` or `| logfmt`
 		// because otherwise the downstream queries would result in too many series
 		{
@@ -141,6 +141,11 @@ func (m ShardMapper) mapBinOpExpr(e *syntax.BinOpExpr, r *downstreamRecorder, to
 }
 
 func (m ShardMapper) mapLogSelectorExpr(expr syntax.LogSelectorExpr, r *downstreamRecorder) (syntax.LogSelectorExpr, uint64, error) {
+	if !expr.Shardable(true) {
+		// e.g. stateful stages keep lines depending on the lines of every shard.
+		return noOp(expr, m.shards.Resolver())
+	}
+
 	var head *ConcatLogSelectorExpr
 	shards, maxBytesPerShard, err := m.shards.Shards(expr)
 	if err != nil {
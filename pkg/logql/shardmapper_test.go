@@ -433,6 +433,20 @@ func TestMappingStrings(t *testing.T) {
 			in:  `count by (foo) (sum by (foo, bar) (rate({job="bar"}[1m])))`,
 			out: `countby(foo)(sumby(foo,bar)(downstream<sumby(foo,bar)(rate({job="bar"}[1m])),shard=0_of_2>++downstream<sumby(foo,bar)(rate({job="bar"}[1m])),shard=1_of_2>))`,
 		},
+		{
+			// sampling only depends on the line, so it's shardable
+			in:  `sum(count_over_time({a=~".+"} | sample 0.5 [1s]))`,
+			out: `sum(downstream<sum(count_over_time({a=~".+"}|sample0.5[1s])),shard=0_of_2>++downstream<sum(count_over_time({a=~".+"}|sample0.5[1s])),shard=1_of_2>)`,
+		},
+		{
+			// stateful stages need every line, so they aren't shardable
+			in:  `sum(count_over_time({a=~".+"} | logfmt | distinct user [1s]))`,
+			out: `sum(count_over_time({a=~".+"}|logfmt|distinctuser[1s]))`,
+		},
+		{
+			in:  `{foo="bar"} | json | limit_per_stream 5`,
+			out: `{foo="bar"}|json|limit_per_stream5`,
+		},
 	} {
 		t.Run(tc.in, func(t *testing.T) {
 			ast, err := syntax.ParseExpr(tc.in)
@@ -0,0 +1,226 @@
+package logql
+
+import (
+	"github.com/cespare/xxhash/v2"
+	"github.com/prometheus/prometheus/model/labels"
+
+	"github.com/grafana/loki/v3/pkg/iter"
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/logql/log"
+	"github.com/grafana/loki/v3/pkg/logql/syntax"
+)
+
+// Stateful stages, like distinct or limit_per_stream, keep lines depending on the lines they kept before.
+// Ingesters and stores only see a part of the lines of a query, so the stages of the pipeline from the first
+// stateful one are applied by the evaluator, on the merged lines and in the order of the query.
+
+// statefulStreamLabels returns the labels the remaining stages process the entries of a stream with. The
+// labels of the stream hold the structured metadata of the entry, which is given to the stages separately,
+// and the labels parsed by the stages before, which the remaining stages see as stream labels.
+func statefulStreamLabels(lbs string, e logproto.Entry) (labels.Labels, error) {
+	ls, err := syntax.ParseLabels(lbs)
+	if err != nil {
+		return nil, err
+	}
+	if len(e.StructuredMetadata) == 0 {
+		return ls, nil
+	}
+	builder := labels.NewBuilder(ls)
+	for _, l := range e.StructuredMetadata {
+		builder.Del(l.Name)
+	}
+	return builder.Labels(), nil
+}
+
+// withDistinctLimiter returns a copy of stages whose distinct stages are bounded by limiter, leaving the
+// stages of the query untouched.
+func withDistinctLimiter(stages syntax.MultiStageExpr, limiter *log.DistinctLimiter) syntax.MultiStageExpr {
+	limited := make(syntax.MultiStageExpr, len(stages))
+	for i, s := range stages {
+		if d, ok := s.(*syntax.DistinctExpr); ok {
+			s = &syntax.DistinctExpr{Labels: d.Labels, Limiter: limiter}
+		}
+		limited[i] = s
+	}
+	return limited
+}
+
+type statefulStream struct {
+	pipeline log.StreamPipeline
+	// parsed holds the names of the labels parsed by the stages before.
+	parsed map[string]struct{}
+}
+
+type statefulEntryIterator struct {
+	iter.EntryIterator
+	pipeline log.Pipeline
+	limiter  *log.DistinctLimiter
+	streams  map[string]*statefulStream
+
+	// limit is the maximum number of entries kept by the pipeline, 0 for no limit.
+	limit, kept uint32
+	closed      bool
+
+	cur    logproto.Entry
+	labels string
+	err    error
+}
+
+// newStatefulEntryIterator applies the pipeline to the entries of it, in their order. Once the pipeline kept
+// limit entries it is closed, so that no more entries are selected. It fails once limiter is exceeded.
+func newStatefulEntryIterator(it iter.EntryIterator, pipeline log.Pipeline, limiter *log.DistinctLimiter, limit uint32) iter.EntryIterator {
+	return &statefulEntryIterator{
+		EntryIterator: it,
+		pipeline:      pipeline,
+		limiter:       limiter,
+		streams:       map[string]*statefulStream{},
+		limit:         limit,
+	}
+}
+
+func (it *statefulEntryIterator) Next() bool {
+	if it.limit > 0 && it.kept >= it.limit {
+		if !it.closed {
+			it.closed = true
+			it.err = it.EntryIterator.Close()
+		}
+		return false
+	}
+	for it.err == nil && it.EntryIterator.Next() {
+		lbs, e := it.EntryIterator.Labels(), it.EntryIterator.Entry()
+		stream, ok := it.streams[lbs]
+		if !ok {
+			ls, err := statefulStreamLabels(lbs, e)
+			if err != nil {
+				it.err = err
+				return false
+			}
+			stream = &statefulStream{
+				pipeline: it.pipeline.ForStream(ls),
+				parsed:   make(map[string]struct{}, len(e.Parsed)),
+			}
+			for _, l := range e.Parsed {
+				stream.parsed[l.Name] = struct{}{}
+			}
+			it.streams[lbs] = stream
+		}
+
+		line, result, ok := stream.pipeline.ProcessString(e.Timestamp.UnixNano(), e.Line, logproto.FromLabelAdaptersToLabels(e.StructuredMetadata)...)
+		if err := it.limiter.Err(); err != nil {
+			it.err = err
+			return false
+		}
+		if !ok {
+			continue
+		}
+		// Keep the labels parsed by the stages before categorized as parsed labels.
+		parsed := result.Parsed()
+		if len(stream.parsed) > 0 {
+			parsed = append(labels.Labels{}, parsed...)
+			for _, l := range result.Stream() {
+				if _, ok := stream.parsed[l.Name]; ok {
+					parsed = append(parsed, l)
+				}
+			}
+		}
+		it.cur = logproto.Entry{
+			Timestamp:          e.Timestamp,
+			Line:               line,
+			StructuredMetadata: logproto.FromLabelsToLabelAdapters(result.StructuredMetadata()),
+			Parsed:             logproto.FromLabelsToLabelAdapters(parsed),
+		}
+		it.labels = result.String()
+		it.kept++
+		return true
+	}
+	return false
+}
+
+func (it *statefulEntryIterator) Entry() logproto.Entry { return it.cur }
+
+func (it *statefulEntryIterator) Labels() string { return it.labels }
+
+func (it *statefulEntryIterator) Error() error {
+	if it.err != nil {
+		return it.err
+	}
+	return it.EntryIterator.Error()
+}
+
+func (it *statefulEntryIterator) Close() error {
+	if it.closed {
+		return nil
+	}
+	it.closed = true
+	return it.EntryIterator.Close()
+}
+
+type statefulSampleIterator struct {
+	it        iter.EntryIterator
+	extractor log.SampleExtractor
+	limiter   *log.DistinctLimiter
+	streams   map[string]log.StreamSampleExtractor
+
+	cur    logproto.Sample
+	labels string
+	err    error
+}
+
+// newStatefulSampleIterator extracts samples from the entries of it, in their order. It fails once limiter
+// is exceeded.
+func newStatefulSampleIterator(it iter.EntryIterator, extractor log.SampleExtractor, limiter *log.DistinctLimiter) iter.SampleIterator {
+	return &statefulSampleIterator{
+		it:        it,
+		extractor: extractor,
+		limiter:   limiter,
+		streams:   map[string]log.StreamSampleExtractor{},
+	}
+}
+
+func (it *statefulSampleIterator) Next() bool {
+	for it.err == nil && it.it.Next() {
+		lbs, e := it.it.Labels(), it.it.Entry()
+		stream, ok := it.streams[lbs]
+		if !ok {
+			ls, err := statefulStreamLabels(lbs, e)
+			if err != nil {
+				it.err = err
+				return false
+			}
+			stream = it.extractor.ForStream(ls)
+			it.streams[lbs] = stream
+		}
+
+		value, result, ok := stream.ProcessString(e.Timestamp.UnixNano(), e.Line, logproto.FromLabelAdaptersToLabels(e.StructuredMetadata)...)
+		if err := it.limiter.Err(); err != nil {
+			it.err = err
+			return false
+		}
+		if !ok {
+			continue
+		}
+		it.cur = logproto.Sample{
+			Timestamp: e.Timestamp.UnixNano(),
+			Value:     value,
+			Hash:      xxhash.Sum64String(e.Line),
+		}
+		it.labels = result.String()
+		return true
+	}
+	return false
+}
+
+func (it *statefulSampleIterator) Sample() logproto.Sample { return it.cur }
+
+func (it *statefulSampleIterator) Labels() string { return it.labels }
+
+func (it *statefulSampleIterator) StreamHash() uint64 { return it.it.StreamHash() }
+
+func (it *statefulSampleIterator) Error() error {
+	if it.err != nil {
+		return it.err
+	}
+	return it.it.Error()
+}
+
+func (it *statefulSampleIterator) Close() error { return it.it.Close() }
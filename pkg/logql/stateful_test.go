@@ -0,0 +1,203 @@
+package logql
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/grafana/dskit/user"
+	"github.com/prometheus/prometheus/promql"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/iter"
+	"github.com/grafana/loki/v3/pkg/logproto"
+	logqllog "github.com/grafana/loki/v3/pkg/logql/log"
+	"github.com/grafana/loki/v3/pkg/logql/syntax"
+	"github.com/grafana/loki/v3/pkg/logqlmodel"
+)
+
+func statefulTestStreams() []logproto.Stream {
+	at := func(s int64) time.Time { return time.Unix(s, 0) }
+	return []logproto.Stream{
+		{Labels: `{app="a"}`, Entries: []logproto.Entry{
+			{Timestamp: at(1), Line: "user=alice n=1"},
+			{Timestamp: at(2), Line: "user=bob n=2"},
+			{Timestamp: at(4), Line: "user=alice n=3"},
+		}},
+		{Labels: `{app="b"}`, Entries: []logproto.Entry{
+			{Timestamp: at(3), Line: "user=bob n=4"},
+			{Timestamp: at(5), Line: "user=carol n=5"},
+		}},
+	}
+}
+
+func TestEngine_StatefulStages_LogQuery(t *testing.T) {
+	eng := NewEngine(EngineOpts{}, NewMockQuerier(0, statefulTestStreams()), NoLimits, log.NewNopLogger())
+
+	for _, tc := range []struct {
+		qs        string
+		direction logproto.Direction
+		limit     uint32
+		expected  map[string][]string
+	}{
+		{
+			qs:        `{app=~"a|b"} | logfmt user | distinct user`,
+			direction: logproto.FORWARD,
+			limit:     10,
+			expected: map[string][]string{
+				`{app="a", user="alice"}`: {"user=alice n=1"},
+				`{app="a", user="bob"}`:   {"user=bob n=2"},
+				`{app="b", user="carol"}`: {"user=carol n=5"},
+			},
+		},
+		{
+			qs:        `{app=~"a|b"} | logfmt user | distinct user`,
+			direction: logproto.BACKWARD,
+			limit:     10,
+			expected: map[string][]string{
+				`{app="a", user="alice"}`: {"user=alice n=3"},
+				`{app="b", user="bob"}`:   {"user=bob n=4"},
+				`{app="b", user="carol"}`: {"user=carol n=5"},
+			},
+		},
+		{
+			// The limit applies to the lines kept by the stateful stages.
+			qs:        `{app=~"a|b"} | logfmt user | distinct user`,
+			direction: logproto.FORWARD,
+			limit:     2,
+			expected: map[string][]string{
+				`{app="a", user="alice"}`: {"user=alice n=1"},
+				`{app="a", user="bob"}`:   {"user=bob n=2"},
+			},
+		},
+		{
+			qs:        `{app=~"a|b"} | limit_per_stream 1 |= "bob"`,
+			direction: logproto.FORWARD,
+			limit:     10,
+			expected: map[string][]string{
+				`{app="b"}`: {"user=bob n=4"},
+			},
+		},
+		{
+			qs:        `{app=~"a|b"} |= "bob" | limit_per_stream 1`,
+			direction: logproto.FORWARD,
+			limit:     10,
+			expected: map[string][]string{
+				`{app="a"}`: {"user=bob n=2"},
+				`{app="b"}`: {"user=bob n=4"},
+			},
+		},
+	} {
+		t.Run(tc.qs+" "+tc.direction.String(), func(t *testing.T) {
+			params, err := NewLiteralParams(tc.qs, time.Unix(0, 0), time.Unix(10, 0), 0, 0, tc.direction, tc.limit, nil)
+			require.NoError(t, err)
+			res, err := eng.Query(params).Exec(user.InjectOrgID(context.Background(), "fake"))
+			require.NoError(t, err)
+
+			actual := map[string][]string{}
+			for _, s := range res.Data.(logqlmodel.Streams) {
+				for _, e := range s.Entries {
+					actual[s.Labels] = append(actual[s.Labels], e.Line)
+				}
+			}
+			require.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestEngine_StatefulStages_MetricQuery(t *testing.T) {
+	eng := NewEngine(EngineOpts{}, NewMockQuerier(0, statefulTestStreams()), NoLimits, log.NewNopLogger())
+
+	for _, tc := range []struct {
+		qs       string
+		expected float64
+	}{
+		{qs: `sum(count_over_time({app=~"a|b"} | logfmt user [10s]))`, expected: 5},
+		{qs: `sum(count_over_time({app=~"a|b"} | logfmt user | distinct user [10s]))`, expected: 3},
+		{qs: `sum by (app) (count_over_time({app=~"a|b"} | logfmt user | distinct user [10s]))`, expected: 3},
+		{qs: `sum(count_over_time({app=~"a|b"} | logfmt user | limit_per_stream 1 [10s]))`, expected: 4},
+		{qs: `sum(sum_over_time({app=~"a|b"} | logfmt | distinct user | unwrap n [10s]))`, expected: 1 + 2 + 5},
+	} {
+		t.Run(tc.qs, func(t *testing.T) {
+			params, err := NewLiteralParams(tc.qs, time.Unix(10, 0), time.Unix(10, 0), 0, 0, logproto.FORWARD, 0, nil)
+			require.NoError(t, err)
+			res, err := eng.Query(params).Exec(user.InjectOrgID(context.Background(), "fake"))
+			require.NoError(t, err)
+
+			var actual float64
+			for _, s := range res.Data.(promql.Vector) {
+				actual += s.F
+			}
+			require.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+type countingEntryIterator struct {
+	iter.EntryIterator
+	next   int
+	closed bool
+}
+
+func (it *countingEntryIterator) Next() bool {
+	it.next++
+	return it.EntryIterator.Next()
+}
+
+func (it *countingEntryIterator) Close() error {
+	it.closed = true
+	return it.EntryIterator.Close()
+}
+
+func TestEngine_StatefulStages_MaxDistinctKeys(t *testing.T) {
+	for _, tc := range []struct {
+		qs          string
+		start       int64
+		maxKeys     int
+		expectedErr bool
+	}{
+		{qs: `{app=~"a|b"} | logfmt user | distinct user`, maxKeys: 3},
+		{qs: `{app=~"a|b"} | logfmt user | distinct user`, maxKeys: 2, expectedErr: true},
+		{qs: `{app=~"a|b"} | logfmt user | distinct user`, maxKeys: 0},
+		// The keys of all the distinct stages of the query count towards the limit.
+		{qs: `{app=~"a|b"} | logfmt user | distinct user | distinct app`, maxKeys: 4, expectedErr: true},
+		{qs: `sum(count_over_time({app=~"a|b"} | logfmt user | distinct user [10s]))`, start: 10, maxKeys: 3},
+		{qs: `sum(count_over_time({app=~"a|b"} | logfmt user | distinct user [10s]))`, start: 10, maxKeys: 2, expectedErr: true},
+	} {
+		t.Run(tc.qs, func(t *testing.T) {
+			eng := NewEngine(EngineOpts{MaxDistinctKeys: tc.maxKeys}, NewMockQuerier(0, statefulTestStreams()), NoLimits, log.NewNopLogger())
+			params, err := NewLiteralParams(tc.qs, time.Unix(tc.start, 0), time.Unix(10, 0), 0, 0, logproto.FORWARD, 10, nil)
+			require.NoError(t, err)
+			_, err = eng.Query(params).Exec(user.InjectOrgID(context.Background(), "fake"))
+			if !tc.expectedErr {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, logqlmodel.ErrLimit)
+			require.ErrorContains(t, err, "maximum of distinct keys")
+		})
+	}
+}
+
+func TestStatefulEntryIterator_Limit(t *testing.T) {
+	expr, err := syntax.ParseLogSelector(`{app=~"a|b"} | limit_per_stream 1`, true)
+	require.NoError(t, err)
+	_, stateful := syntax.SplitStatefulStages(expr)
+	pipeline, err := stateful.Pipeline()
+	require.NoError(t, err)
+
+	// The selected lines are consumed only until the stages kept limit lines.
+	inner := &countingEntryIterator{EntryIterator: iter.NewStreamsIterator(statefulTestStreams(), logproto.FORWARD)}
+	it := newStatefulEntryIterator(inner, pipeline, logqllog.NewDistinctLimiter(0), 2)
+
+	var lines []string
+	for it.Next() {
+		lines = append(lines, it.Entry().Line)
+	}
+	require.NoError(t, it.Error())
+	require.Equal(t, []string{"user=alice n=1", "user=bob n=4"}, lines)
+	require.Equal(t, 3, inner.next)
+	require.True(t, inner.closed)
+	require.NoError(t, it.Close())
+}
@@ -154,6 +154,20 @@ func (m MultiStageExpr) reorderStages() []StageExpr {
 			}
 			result = append(result, rest...)
 
+			filters = filters[:0]
+			rest = rest[:0]
+		case *DistinctExpr, *LimitPerStreamExpr:
+			// stateful stages keep lines depending on the lines they kept
+			// before, so any line filter originally after a stateful stage
+			// must still be after the same stage.
+
+			rest = append(rest, f)
+
+			if len(filters) > 0 {
+				result = append(result, combineFilters(filters))
+			}
+			result = append(result, rest...)
+
 			filters = filters[:0]
 			rest = rest[:0]
 		case *LabelParserExpr:
@@ -833,6 +847,97 @@ func (e *KeepLabelsExpr) Walk(f WalkFn) { f(e) }
 
 func (e *KeepLabelsExpr) Accept(v RootVisitor) { v.VisitKeepLabel(e) }
 
+type DistinctExpr struct {
+	Labels []string
+	// Limiter bounds the number of keys of the stage. It is set by the evaluator and isn't part of the query.
+	Limiter *log.DistinctLimiter
+	implicit
+}
+
+func newDistinctExpr(labels []string) *DistinctExpr {
+	return &DistinctExpr{Labels: labels}
+}
+
+func (*DistinctExpr) isStageExpr() {}
+
+// Shardable returns false: the first line of a combination of values can't be known from a single shard.
+func (e *DistinctExpr) Shardable(_ bool) bool { return false }
+
+func (e *DistinctExpr) Stage() (log.Stage, error) {
+	return log.NewDistinct(e.Labels, e.Limiter), nil
+}
+
+func (e *DistinctExpr) String() string {
+	return fmt.Sprintf("%s %s %s", OpPipe, OpDistinct, strings.Join(e.Labels, ","))
+}
+
+func (e *DistinctExpr) Walk(f WalkFn) { f(e) }
+
+func (e *DistinctExpr) Accept(v RootVisitor) { v.VisitDistinct(e) }
+
+type LimitPerStreamExpr struct {
+	Limit uint64
+	implicit
+}
+
+func mustNewLimitPerStreamExpr(limit string) *LimitPerStreamExpr {
+	n, err := strconv.ParseUint(limit, 10, 64)
+	if err != nil || n == 0 {
+		panic(logqlmodel.NewParseError(fmt.Sprintf("invalid %s limit: %s, it must be a positive integer", OpLimitPerStream, limit), 0, 0))
+	}
+	return &LimitPerStreamExpr{Limit: n}
+}
+
+func (*LimitPerStreamExpr) isStageExpr() {}
+
+// Shardable returns false: once labels are changed or dropped, the lines of a stream can come from several shards.
+func (e *LimitPerStreamExpr) Shardable(_ bool) bool { return false }
+
+func (e *LimitPerStreamExpr) Stage() (log.Stage, error) {
+	return log.NewLimitPerStream(e.Limit), nil
+}
+
+func (e *LimitPerStreamExpr) String() string {
+	return fmt.Sprintf("%s %s %d", OpPipe, OpLimitPerStream, e.Limit)
+}
+
+func (e *LimitPerStreamExpr) Walk(f WalkFn) { f(e) }
+
+func (e *LimitPerStreamExpr) Accept(v RootVisitor) { v.VisitLimitPerStream(e) }
+
+type SamplingExpr struct {
+	Ratio float64
+	implicit
+}
+
+func mustNewSamplingExpr(ratio string) *SamplingExpr {
+	n, err := strconv.ParseFloat(ratio, 64)
+	if err != nil || n <= 0 || n > 1 {
+		panic(logqlmodel.NewParseError(fmt.Sprintf("invalid %s ratio: %s, it must be greater than 0 and lower than or equal to 1", OpSample, ratio), 0, 0))
+	}
+	return &SamplingExpr{Ratio: n}
+}
+
+func (*SamplingExpr) isStageExpr() {}
+
+// Shardable returns true: whether a line is kept only depends on the line itself.
+func (e *SamplingExpr) Shardable(_ bool) bool { return true }
+
+func (e *SamplingExpr) Stage() (log.Stage, error) {
+	if e.Ratio >= 1 {
+		return log.NoopStage, nil
+	}
+	return log.NewSample(e.Ratio), nil
+}
+
+func (e *SamplingExpr) String() string {
+	return fmt.Sprintf("%s %s %s", OpPipe, OpSample, strconv.FormatFloat(e.Ratio, 'f', -1, 64))
+}
+
+func (e *SamplingExpr) Walk(f WalkFn) { f(e) }
+
+func (e *SamplingExpr) Accept(v RootVisitor) { v.VisitSampling(e) }
+
 func (*LineFmtExpr) isStageExpr() {}
 
 func (e *LineFmtExpr) Shardable(_ bool) bool { return true }
@@ -1235,6 +1340,12 @@ const (
 	// keep labels
 	OpKeep = "keep"
 
+	// stateful stages
+	OpDistinct       = "distinct"
+	OpLimitPerStream = "limit_per_stream"
+
+	OpSample = "sample"
+
 	// parser flags
 	OpStrict    = "--strict"
 	OpKeepEmpty = "--keep-empty"
@@ -2302,6 +2413,39 @@ func (e *VectorExpr) Matchers() []*labels.Matcher             { return nil }
 func (e *VectorExpr) MatcherGroups() ([]MatcherRange, error)  { return nil, e.err }
 func (e *VectorExpr) Extractor() (log.SampleExtractor, error) { return nil, nil }
 
+// HasStatefulStage returns true if the expression holds a stage keeping lines depending on the lines it
+// processed before, like distinct or limit_per_stream. The result of such a stage depends on every line of
+// the query, so the expression can't be evaluated on parts of the query time range and merged.
+func HasStatefulStage(e Expr) (stateful bool) {
+	e.Walk(func(e Expr) {
+		switch e.(type) {
+		case *DistinctExpr, *LimitPerStreamExpr:
+			stateful = true
+		}
+	})
+	return
+}
+
+// SplitStatefulStages splits the pipeline of e before its first stateful stage. It returns the log selector
+// holding the stages before it, which can be evaluated on any part of the lines, and the stages to apply
+// afterwards on every line of the query, in order. The stages are empty if e has no stateful stage.
+func SplitStatefulStages(e LogSelectorExpr) (LogSelectorExpr, MultiStageExpr) {
+	p, ok := e.(*PipelineExpr)
+	if !ok {
+		return e, nil
+	}
+	for i, s := range p.MultiStages {
+		switch s.(type) {
+		case *DistinctExpr, *LimitPerStreamExpr:
+			if i == 0 {
+				return p.Left, p.MultiStages
+			}
+			return newPipelineExpr(p.Left, p.MultiStages[:i]), p.MultiStages[i:]
+		}
+	}
+	return e, nil
+}
+
 func ReducesLabels(e Expr) (conflict bool) {
 	e.Walk(func(e Expr) {
 		switch expr := e.(type) {
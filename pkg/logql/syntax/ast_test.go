@@ -49,6 +49,7 @@ func Test_logSelectorExpr_String(t *testing.T) {
 		{`{foo="bar"} |= "baz" |~ "blip" != "flip" !~ "flap" | logfmt | b=ip("127.0.0.1") | level="error" | c=ip("::1")`, true}, // chain inside label filters.
 		{`{foo="bar"} |= "baz" |~ "blip" != "flip" !~ "flap" | regexp "(?P<foo>foo|bar)"`, true},
 		{`{foo="bar"} |= "baz" |~ "blip" != "flip" !~ "flap" | regexp "(?P<foo>foo|bar)" | ( ( foo<5.01 , bar>20ms ) or foo="bar" ) | line_format "blip{{.boop}}bap" | label_format foo=bar,bar="blip{{.blop}}"`, true},
+		{`{foo="bar"} | json | distinct user,method | sample 0.05 | limit_per_stream 10`, true},
 	}
 
 	for _, tt := range tests {
@@ -965,6 +966,67 @@ func TestFilterReodering(t *testing.T) {
 		require.Len(t, stages, 5)
 		require.Equal(t, `|= "06497595" | unpack != "message" | json | line_format "new log: {{.foo}}"`, MultiStageExpr(stages).String())
 	})
+
+	t.Run("stateful stages test", func(t *testing.T) {
+		logExpr := `{container_name="app"} |= "foo" | logfmt | distinct user |= "bar" | limit_per_stream 5 |= "baz"`
+		l, err := ParseExpr(logExpr)
+		require.NoError(t, err)
+
+		stages := l.(*PipelineExpr).MultiStages.reorderStages()
+		require.Len(t, stages, 6)
+		require.Equal(t, `|= "foo" | logfmt | distinct user |= "bar" | limit_per_stream 5 |= "baz"`, MultiStageExpr(stages).String())
+	})
+}
+
+func TestStatefulStages(t *testing.T) {
+	for _, tc := range []struct {
+		query     string
+		stateful  bool
+		shardable bool
+		selector  string
+		stages    string
+	}{
+		{
+			query:     `{app="foo"} | json | sample 0.1`,
+			shardable: true,
+			selector:  `{app="foo"} | json | sample 0.1`,
+		},
+		{
+			query:    `{app="foo"} | distinct user`,
+			stateful: true,
+			selector: `{app="foo"}`,
+			stages:   `| distinct user`,
+		},
+		{
+			query:    `{app="foo"} |= "bar" | json | limit_per_stream 10 | line_format "{{.user}}" | distinct user`,
+			stateful: true,
+			selector: `{app="foo"} |= "bar" | json`,
+			stages:   `| limit_per_stream 10 | line_format "{{.user}}" | distinct user`,
+		},
+		{
+			query:    `sum by (user) (count_over_time({app="foo"} | logfmt | distinct user [5m]))`,
+			stateful: true,
+			selector: `{app="foo"} | logfmt`,
+			stages:   `| distinct user`,
+		},
+	} {
+		t.Run(tc.query, func(t *testing.T) {
+			expr, err := ParseExpr(tc.query)
+			require.NoError(t, err)
+			require.Equal(t, tc.stateful, HasStatefulStage(expr))
+			require.Equal(t, tc.shardable, expr.Shardable(true))
+
+			var selector LogSelectorExpr
+			expr.Walk(func(e Expr) {
+				if s, ok := e.(LogSelectorExpr); ok && selector == nil {
+					selector = s
+				}
+			})
+			left, stages := SplitStatefulStages(selector)
+			require.Equal(t, tc.selector, left.String())
+			require.Equal(t, tc.stages, stages.String())
+		})
+	}
 }
 
 var result bool
@@ -144,6 +144,16 @@ func (v *cloneVisitor) VisitDecolorize(*DecolorizeExpr) {
 	v.cloned = &DecolorizeExpr{}
 }
 
+func (v *cloneVisitor) VisitDistinct(e *DistinctExpr) {
+	copied := &DistinctExpr{
+		Labels:  make([]string, len(e.Labels)),
+		Limiter: e.Limiter,
+	}
+	copy(copied.Labels, e.Labels)
+
+	v.cloned = copied
+}
+
 func (v *cloneVisitor) VisitDropLabels(e *DropLabelsExpr) {
 	copied := &DropLabelsExpr{
 		dropLabels: make([]log.DropLabel, len(e.dropLabels)),
@@ -258,6 +268,10 @@ func (v *cloneVisitor) VisitLabelParser(e *LabelParserExpr) {
 	}
 }
 
+func (v *cloneVisitor) VisitLimitPerStream(e *LimitPerStreamExpr) {
+	v.cloned = &LimitPerStreamExpr{Limit: e.Limit}
+}
+
 func (v *cloneVisitor) VisitLineFilter(e *LineFilterExpr) {
 	copied := &LineFilterExpr{
 		LineFilter: LineFilter{
@@ -300,3 +314,7 @@ func (v *cloneVisitor) VisitLogfmtParser(e *LogfmtParserExpr) {
 		KeepEmpty: e.KeepEmpty,
 	}
 }
+
+func (v *cloneVisitor) VisitSampling(e *SamplingExpr) {
+	v.cloned = &SamplingExpr{Ratio: e.Ratio}
+}
@@ -71,6 +71,9 @@ import (
   KeepLabel               log.KeepLabel
   KeepLabels              []log.KeepLabel
   KeepLabelsExpr          *KeepLabelsExpr
+  DistinctExpr            *DistinctExpr
+  LimitPerStreamExpr      *LimitPerStreamExpr
+  SamplingExpr            *SamplingExpr
 }
 
 %start root
@@ -119,6 +122,9 @@ import (
 %type <KeepLabelsExpr>        keepLabelsExpr
 %type <KeepLabels>            keepLabels
 %type <KeepLabel>             keepLabel
+%type <DistinctExpr>          distinctExpr
+%type <LimitPerStreamExpr>    limitPerStreamExpr
+%type <SamplingExpr>          samplingExpr
 %type <LabelFormatExpr>       labelFormatExpr
 %type <LabelFormat>           labelFormat
 %type <LabelsFormat>          labelsFormat
@@ -139,7 +145,7 @@ import (
                   BYTES_OVER_TIME BYTES_RATE BOOL JSON REGEXP LOGFMT PIPE LINE_FMT LABEL_FMT UNWRAP AVG_OVER_TIME SUM_OVER_TIME MIN_OVER_TIME
                   MAX_OVER_TIME STDVAR_OVER_TIME STDDEV_OVER_TIME QUANTILE_OVER_TIME BYTES_CONV DURATION_CONV DURATION_SECONDS_CONV
                   FIRST_OVER_TIME LAST_OVER_TIME ABSENT_OVER_TIME VECTOR LABEL_REPLACE UNPACK OFFSET PATTERN IP ON IGNORING GROUP_LEFT GROUP_RIGHT
-                  DECOLORIZE DROP KEEP
+                  DECOLORIZE DROP KEEP DISTINCT LIMIT_PER_STREAM SAMPLE
 
 // Operators are listed with increasing precedence.
 %left <binOp> OR
@@ -282,6 +288,9 @@ pipelineStage:
   | PIPE labelFormatExpr         { $$ = $2 }
   | PIPE dropLabelsExpr          { $$ = $2 }
   | PIPE keepLabelsExpr          { $$ = $2 }
+  | PIPE distinctExpr            { $$ = $2 }
+  | PIPE limitPerStreamExpr      { $$ = $2 }
+  | PIPE samplingExpr            { $$ = $2 }
   ;
 
 filterOp:
@@ -431,6 +440,12 @@ keepLabels:
 
 keepLabelsExpr: KEEP keepLabels { $$ = newKeepLabelsExpr($2) }
 
+distinctExpr: DISTINCT labels { $$ = newDistinctExpr($2) }
+
+limitPerStreamExpr: LIMIT_PER_STREAM NUMBER { $$ = mustNewLimitPerStreamExpr($2) }
+
+samplingExpr: SAMPLE NUMBER { $$ = mustNewSamplingExpr($2) }
+
 // Operator precedence only works if each of these is listed separately.
 binOpExpr:
          expr OR binOpModifier expr          { $$ = mustNewBinOpExpr("or", $3, $1, $4) }
@@ -64,15 +64,18 @@ type exprSymType struct {
 	JSONExpressionParser          *JSONExpressionParser
 	LogfmtExpressionParser        *LogfmtExpressionParser
 
-	UnwrapExpr     *UnwrapExpr
-	DecolorizeExpr *DecolorizeExpr
-	OffsetExpr     *OffsetExpr
-	DropLabel      log.DropLabel
-	DropLabels     []log.DropLabel
-	DropLabelsExpr *DropLabelsExpr
-	KeepLabel      log.KeepLabel
-	KeepLabels     []log.KeepLabel
-	KeepLabelsExpr *KeepLabelsExpr
+	UnwrapExpr         *UnwrapExpr
+	DecolorizeExpr     *DecolorizeExpr
+	OffsetExpr         *OffsetExpr
+	DropLabel          log.DropLabel
+	DropLabels         []log.DropLabel
+	DropLabelsExpr     *DropLabelsExpr
+	KeepLabel          log.KeepLabel
+	KeepLabels         []log.KeepLabel
+	KeepLabelsExpr     *KeepLabelsExpr
+	DistinctExpr       *DistinctExpr
+	LimitPerStreamExpr *LimitPerStreamExpr
+	SamplingExpr       *SamplingExpr
 }
 
 const BYTES = 57346
@@ -151,21 +154,24 @@ const GROUP_RIGHT = 57418
 const DECOLORIZE = 57419
 const DROP = 57420
 const KEEP = 57421
-const OR = 57422
-const AND = 57423
-const UNLESS = 57424
-const CMP_EQ = 57425
-const NEQ = 57426
-const LT = 57427
-const LTE = 57428
-const GT = 57429
-const GTE = 57430
-const ADD = 57431
-const SUB = 57432
-const MUL = 57433
-const DIV = 57434
-const MOD = 57435
-const POW = 57436
+const DISTINCT = 57422
+const LIMIT_PER_STREAM = 57423
+const SAMPLE = 57424
+const OR = 57425
+const AND = 57426
+const UNLESS = 57427
+const CMP_EQ = 57428
+const NEQ = 57429
+const LT = 57430
+const LTE = 57431
+const GT = 57432
+const GTE = 57433
+const ADD = 57434
+const SUB = 57435
+const MUL = 57436
+const DIV = 57437
+const MOD = 57438
+const POW = 57439
 
 var exprToknames = [...]string{
 	"$end",
@@ -247,6 +253,9 @@ var exprToknames = [...]string{
 	"DECOLORIZE",
 	"DROP",
 	"KEEP",
+	"DISTINCT",
+	"LIMIT_PER_STREAM",
+	"SAMPLE",
 	"OR",
 	"AND",
 	"UNLESS",
@@ -277,122 +286,125 @@ var exprExca = [...]int{
 
 const exprPrivate = 57344
 
-const exprLast = 608
+const exprLast = 622
 
 var exprAct = [...]int{
 
-	289, 228, 84, 4, 214, 64, 182, 126, 204, 189,
-	75, 200, 197, 63, 237, 5, 152, 187, 77, 2,
-	56, 80, 48, 49, 50, 57, 58, 61, 62, 59,
+	299, 238, 84, 4, 224, 64, 188, 129, 210, 195,
+	75, 206, 203, 63, 213, 5, 158, 193, 56, 293,
+	227, 80, 48, 49, 50, 57, 58, 61, 62, 59,
 	60, 51, 52, 53, 54, 55, 56, 49, 50, 57,
 	58, 61, 62, 59, 60, 51, 52, 53, 54, 55,
 	56, 57, 58, 61, 62, 59, 60, 51, 52, 53,
 	54, 55, 56, 51, 52, 53, 54, 55, 56, 109,
-	148, 150, 151, 115, 53, 54, 55, 56, 207, 150,
-	151, 283, 217, 139, 166, 167, 292, 156, 215, 295,
-	216, 72, 74, 161, 72, 74, 164, 165, 154, 69,
-	70, 71, 69, 70, 71, 297, 347, 140, 67, 294,
-	163, 366, 136, 366, 168, 169, 170, 171, 172, 173,
-	174, 175, 176, 177, 178, 179, 180, 181, 184, 230,
-	339, 292, 94, 130, 255, 136, 339, 194, 85, 86,
-	191, 149, 202, 206, 293, 136, 386, 369, 213, 208,
-	211, 212, 209, 210, 142, 219, 130, 363, 306, 141,
-	73, 184, 235, 73, 356, 381, 130, 239, 229, 374,
-	294, 231, 232, 142, 110, 240, 294, 122, 123, 121,
-	227, 131, 133, 297, 294, 72, 74, 185, 183, 316,
-	248, 249, 250, 69, 70, 71, 373, 298, 371, 124,
-	295, 125, 136, 359, 252, 72, 74, 132, 134, 135,
-	293, 349, 239, 69, 70, 71, 72, 74, 184, 224,
-	230, 183, 285, 130, 69, 70, 71, 346, 287, 290,
-	330, 296, 136, 299, 314, 109, 302, 115, 303, 304,
-	230, 291, 154, 288, 331, 300, 306, 72, 74, 243,
-	294, 230, 355, 130, 73, 69, 70, 71, 306, 310,
-	312, 315, 317, 318, 354, 233, 202, 206, 325, 320,
-	324, 292, 340, 83, 73, 85, 86, 185, 183, 136,
-	144, 266, 230, 221, 267, 73, 265, 262, 328, 220,
-	263, 332, 261, 334, 336, 184, 338, 109, 239, 306,
-	130, 337, 348, 333, 239, 353, 109, 224, 227, 350,
-	327, 306, 306, 72, 74, 239, 73, 308, 307, 239,
-	313, 69, 70, 71, 13, 224, 311, 143, 342, 343,
-	344, 379, 301, 155, 360, 361, 384, 241, 326, 109,
-	362, 238, 284, 153, 247, 246, 364, 365, 230, 264,
-	225, 245, 370, 13, 244, 260, 218, 160, 159, 158,
-	16, 90, 155, 89, 72, 74, 376, 82, 377, 378,
-	13, 380, 69, 70, 71, 352, 253, 305, 259, 6,
-	382, 258, 73, 21, 22, 23, 36, 45, 46, 37,
-	39, 40, 38, 41, 42, 43, 44, 24, 25, 66,
-	256, 242, 234, 226, 257, 254, 368, 26, 27, 28,
-	29, 30, 31, 32, 81, 146, 367, 33, 34, 35,
-	47, 19, 236, 281, 345, 335, 282, 79, 280, 322,
-	323, 145, 13, 73, 147, 278, 162, 88, 279, 87,
-	277, 6, 17, 18, 385, 21, 22, 23, 36, 45,
-	46, 37, 39, 40, 38, 41, 42, 43, 44, 24,
-	25, 275, 272, 127, 276, 273, 274, 271, 383, 26,
-	27, 28, 29, 30, 31, 32, 372, 358, 357, 33,
-	34, 35, 47, 19, 157, 269, 329, 375, 270, 190,
-	268, 3, 251, 190, 13, 319, 188, 321, 76, 309,
-	198, 128, 286, 6, 17, 18, 136, 21, 22, 23,
-	36, 45, 46, 37, 39, 40, 38, 41, 42, 43,
-	44, 24, 25, 223, 222, 221, 220, 130, 195, 193,
-	192, 26, 27, 28, 29, 30, 31, 32, 91, 351,
-	205, 33, 34, 35, 47, 19, 201, 190, 122, 123,
-	121, 81, 131, 133, 198, 113, 114, 196, 118, 203,
-	120, 199, 119, 117, 116, 186, 17, 18, 65, 137,
-	124, 129, 125, 138, 111, 112, 93, 92, 132, 134,
-	135, 11, 10, 9, 20, 12, 15, 8, 95, 96,
-	97, 98, 99, 100, 101, 102, 103, 104, 105, 106,
-	107, 108, 341, 14, 7, 78, 68, 1,
+	154, 156, 157, 115, 53, 54, 55, 56, 16, 217,
+	156, 157, 145, 172, 173, 170, 171, 162, 13, 302,
+	226, 307, 225, 167, 146, 304, 67, 6, 160, 375,
+	375, 21, 22, 23, 36, 45, 46, 37, 39, 40,
+	38, 41, 42, 43, 44, 24, 25, 77, 2, 302,
+	94, 83, 159, 85, 86, 26, 27, 28, 29, 30,
+	31, 32, 13, 349, 395, 33, 34, 35, 47, 19,
+	200, 161, 303, 197, 155, 208, 212, 85, 86, 350,
+	142, 390, 223, 218, 221, 222, 219, 220, 148, 147,
+	148, 229, 110, 17, 18, 383, 190, 382, 245, 269,
+	269, 133, 264, 304, 239, 365, 364, 241, 242, 269,
+	247, 249, 304, 234, 269, 363, 276, 305, 231, 277,
+	362, 275, 72, 74, 380, 349, 257, 258, 259, 368,
+	69, 70, 71, 359, 357, 352, 353, 354, 341, 169,
+	261, 340, 378, 174, 175, 176, 177, 178, 179, 180,
+	181, 182, 183, 184, 185, 186, 187, 240, 191, 189,
+	314, 272, 295, 230, 273, 304, 271, 252, 297, 300,
+	214, 306, 234, 309, 237, 109, 312, 115, 313, 72,
+	74, 301, 160, 298, 274, 310, 243, 69, 70, 71,
+	269, 308, 325, 214, 73, 142, 317, 311, 319, 321,
+	324, 326, 327, 142, 150, 208, 212, 334, 329, 333,
+	214, 190, 305, 303, 240, 323, 133, 72, 74, 190,
+	149, 372, 234, 142, 133, 69, 70, 71, 338, 270,
+	356, 342, 322, 344, 346, 214, 348, 109, 214, 190,
+	214, 347, 358, 343, 133, 142, 109, 235, 269, 360,
+	337, 73, 240, 304, 316, 13, 266, 320, 336, 388,
+	250, 263, 248, 237, 161, 294, 133, 256, 72, 74,
+	255, 254, 253, 228, 369, 370, 69, 70, 71, 109,
+	371, 191, 189, 166, 165, 164, 373, 374, 90, 73,
+	89, 82, 379, 393, 389, 152, 361, 262, 315, 246,
+	269, 268, 189, 240, 267, 385, 265, 386, 387, 13,
+	251, 151, 244, 377, 153, 236, 376, 355, 6, 391,
+	345, 216, 21, 22, 23, 36, 45, 46, 37, 39,
+	40, 38, 41, 42, 43, 44, 24, 25, 196, 215,
+	73, 260, 81, 163, 331, 332, 26, 27, 28, 29,
+	30, 31, 32, 13, 168, 79, 33, 34, 35, 47,
+	19, 196, 6, 394, 194, 88, 21, 22, 23, 36,
+	45, 46, 37, 39, 40, 38, 41, 42, 43, 44,
+	24, 25, 87, 392, 17, 18, 381, 367, 142, 384,
+	26, 27, 28, 29, 30, 31, 32, 366, 339, 328,
+	33, 34, 35, 47, 19, 72, 74, 318, 296, 133,
+	72, 74, 233, 69, 70, 71, 3, 232, 69, 70,
+	71, 231, 291, 76, 142, 292, 230, 290, 17, 18,
+	125, 126, 124, 201, 134, 136, 307, 199, 72, 74,
+	240, 72, 74, 198, 335, 133, 69, 70, 71, 69,
+	70, 71, 127, 288, 128, 211, 289, 207, 287, 196,
+	135, 137, 138, 139, 140, 141, 125, 126, 124, 91,
+	134, 136, 81, 240, 214, 285, 66, 73, 286, 204,
+	284, 282, 73, 130, 283, 131, 281, 113, 127, 279,
+	128, 114, 280, 302, 278, 202, 135, 137, 138, 139,
+	140, 141, 330, 118, 123, 204, 122, 121, 209, 120,
+	73, 205, 119, 73, 117, 116, 192, 65, 143, 95,
+	96, 97, 98, 99, 100, 101, 102, 103, 104, 105,
+	106, 107, 108, 132, 144, 111, 112, 93, 92, 11,
+	10, 9, 20, 12, 15, 8, 351, 14, 7, 78,
+	68, 1,
 }
 var exprPact = [...]int{
 
-	353, -1000, -58, -1000, -1000, 349, 353, -1000, -1000, -1000,
-	-1000, -1000, -1000, 409, 341, 247, -1000, 432, 430, 337,
-	335, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000,
+	71, -1000, -61, -1000, -1000, 496, 71, -1000, -1000, -1000,
+	-1000, -1000, -1000, 407, 335, 95, -1000, 445, 428, 334,
+	332, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000,
 	-1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000,
-	-1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, 86, 86,
-	86, 86, 86, 86, 86, 86, 86, 86, 86, 86,
-	86, 86, 86, 349, -1000, 76, 501, 3, 101, -1000,
-	-1000, -1000, -1000, -1000, -1000, 300, 253, -58, 413, -1000,
-	-1000, 57, 336, 477, 333, 332, 331, -1000, -1000, 353,
-	429, 353, 23, 9, -1000, 353, 353, 353, 353, 353,
-	353, 353, 353, 353, 353, 353, 353, 353, 353, -1000,
-	-1000, -1000, -1000, -1000, -1000, 197, -1000, -1000, -1000, -1000,
-	-1000, 488, 542, 524, -1000, 523, -1000, -1000, -1000, -1000,
-	227, 522, -1000, 549, 541, 535, 65, -1000, -1000, 82,
-	2, 330, -1000, -1000, -1000, -1000, -1000, 546, 520, 519,
-	518, 517, 323, 382, 298, 307, 238, 381, 415, 314,
-	310, 380, 222, -44, 328, 325, 319, 318, -32, -32,
-	-17, -17, -74, -74, -74, -74, -26, -26, -26, -26,
-	-26, -26, 197, 227, 227, 227, 484, 355, -1000, -1000,
-	392, 355, -1000, -1000, 107, -1000, 379, -1000, 391, 360,
-	-1000, 57, -1000, 357, -1000, 57, -1000, 283, 277, 481,
-	458, 457, 431, 419, -1000, 1, 316, 82, 496, -1000,
-	-1000, -1000, -1000, -1000, -1000, 110, 307, 201, 134, 190,
-	130, 170, 305, 110, 353, 212, 356, 291, -1000, -1000,
-	290, -1000, 493, -1000, 299, 293, 207, 162, 274, 197,
-	140, -1000, 355, 542, 489, -1000, 495, 424, 541, 535,
-	312, -1000, -1000, -1000, 284, -1000, -1000, -1000, -1000, -1000,
+	-1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, 74, 74,
+	74, 74, 74, 74, 74, 74, 74, 74, 74, 74,
+	74, 74, 74, 496, -1000, 465, 489, -1, 88, -1000,
+	-1000, -1000, -1000, -1000, -1000, 263, 247, -61, 363, -1000,
+	-1000, 57, 115, 406, 329, 328, 327, -1000, -1000, 71,
+	417, 71, 12, 8, -1000, 71, 71, 71, 71, 71,
+	71, 71, 71, 71, 71, 71, 71, 71, 71, -1000,
+	-1000, -1000, -1000, -1000, -1000, 268, -1000, -1000, -1000, -1000,
+	-1000, -1000, -1000, -1000, 426, 524, 507, -1000, 501, -1000,
+	-1000, -1000, -1000, 310, 497, -1000, 544, 522, 520, 539,
+	402, 384, 66, -1000, -1000, 86, -63, 317, -1000, -1000,
+	-1000, -1000, -1000, 537, 490, 485, 481, 476, 290, 364,
+	323, 308, 229, 361, 362, 305, 303, 359, 210, -47,
+	316, 315, 314, 311, -35, -35, -20, -20, -79, -79,
+	-79, -79, -29, -29, -29, -29, -29, -29, 268, 310,
+	310, 310, 403, 346, -1000, -1000, 318, 346, -1000, -1000,
+	145, -1000, 355, -1000, 313, 353, -1000, 57, -1000, 350,
+	-1000, 57, -1000, 349, -1000, -1000, -1000, 227, 182, 555,
+	547, 541, 519, 488, -1000, -64, 309, 86, 472, -1000,
+	-1000, -1000, -1000, -1000, -1000, 119, 308, 493, 132, 272,
+	453, 234, 240, 119, 71, 203, 347, 297, -1000, 239,
+	-1000, 471, -1000, 300, 275, 258, 235, 260, 268, 288,
+	-1000, 346, 524, 463, -1000, 570, 409, 522, 520, 509,
+	302, -1000, -1000, -1000, 294, -1000, -1000, -1000, -1000, -1000,
 	-1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000, -1000,
-	-1000, -1000, -1000, 82, 480, -1000, 203, -1000, 217, 232,
-	59, 232, 416, 16, 227, 16, 126, 267, 414, 200,
-	79, -1000, -1000, 184, -1000, 353, 534, -1000, -1000, 354,
-	278, -1000, 237, -1000, -1000, 225, -1000, 137, -1000, -1000,
-	-1000, -1000, -1000, -1000, -1000, -1000, 472, 471, -1000, 176,
-	-1000, 110, 59, 232, 59, -1000, -1000, 197, -1000, 16,
-	-1000, 131, -1000, -1000, -1000, 61, 406, 396, 120, 110,
-	171, -1000, 470, -1000, -1000, -1000, -1000, 169, 142, -1000,
-	-1000, 59, -1000, 482, 63, 59, 52, 16, 16, 321,
-	-1000, -1000, 350, -1000, -1000, 138, 59, -1000, -1000, 16,
-	462, -1000, -1000, 315, 438, 119, -1000,
+	-1000, -1000, -1000, 86, 462, -1000, 184, -1000, 181, 460,
+	45, 460, 381, 19, 310, 19, 123, 144, 377, 273,
+	177, -1000, -1000, 176, -1000, 71, -1000, -1000, 345, 163,
+	-1000, 158, -1000, -1000, 149, -1000, 148, -1000, -1000, -1000,
+	-1000, -1000, -1000, -1000, -1000, -1000, 461, 451, -1000, 172,
+	-1000, 119, 45, 460, 45, -1000, -1000, 268, -1000, 19,
+	-1000, 265, -1000, -1000, -1000, 49, 376, 373, 185, 119,
+	167, 450, -1000, -1000, -1000, -1000, 140, 138, -1000, -1000,
+	45, -1000, 454, 50, 45, 38, 19, 19, 319, -1000,
+	-1000, 343, -1000, -1000, 124, 45, -1000, -1000, 19, 447,
+	-1000, -1000, 342, 427, 107, -1000,
 }
 var exprPgo = [...]int{
 
-	0, 607, 18, 606, 2, 14, 491, 3, 16, 7,
-	605, 604, 603, 602, 15, 587, 586, 585, 584, 90,
-	583, 582, 581, 538, 577, 576, 575, 574, 13, 5,
-	573, 571, 569, 6, 568, 108, 4, 565, 564, 563,
-	562, 561, 11, 560, 559, 8, 558, 12, 557, 9,
-	17, 556, 555, 1, 501, 463, 0,
+	0, 621, 117, 620, 2, 14, 486, 3, 16, 7,
+	619, 618, 617, 616, 15, 615, 614, 613, 612, 90,
+	611, 610, 609, 539, 608, 607, 606, 605, 13, 5,
+	604, 603, 588, 6, 587, 96, 4, 586, 585, 584,
+	582, 581, 11, 579, 578, 8, 577, 576, 574, 573,
+	12, 565, 9, 17, 561, 557, 1, 555, 553, 0,
 }
 var exprR1 = [...]int{
 
@@ -400,25 +412,26 @@ var exprR1 = [...]int{
 	7, 6, 6, 6, 8, 8, 8, 8, 8, 8,
 	8, 8, 8, 8, 8, 8, 8, 8, 8, 8,
 	8, 8, 8, 8, 8, 8, 8, 8, 8, 8,
-	53, 53, 53, 13, 13, 13, 11, 11, 11, 11,
+	56, 56, 56, 13, 13, 13, 11, 11, 11, 11,
 	15, 15, 15, 15, 15, 15, 22, 3, 3, 3,
 	3, 3, 3, 14, 14, 14, 10, 10, 9, 9,
 	9, 9, 28, 28, 29, 29, 29, 29, 29, 29,
-	29, 29, 29, 29, 29, 19, 36, 36, 36, 35,
-	35, 35, 34, 34, 34, 37, 37, 27, 27, 26,
-	26, 26, 26, 52, 51, 51, 38, 39, 47, 47,
-	48, 48, 48, 46, 33, 33, 33, 33, 33, 33,
-	33, 33, 33, 49, 49, 50, 50, 55, 55, 54,
-	54, 32, 32, 32, 32, 32, 32, 32, 30, 30,
-	30, 30, 30, 30, 30, 31, 31, 31, 31, 31,
-	31, 31, 42, 42, 41, 41, 40, 45, 45, 44,
-	44, 43, 20, 20, 20, 20, 20, 20, 20, 20,
-	20, 20, 20, 20, 20, 20, 20, 24, 24, 25,
-	25, 25, 25, 23, 23, 23, 23, 23, 23, 23,
-	23, 21, 21, 21, 17, 18, 16, 16, 16, 16,
-	16, 16, 16, 16, 16, 16, 16, 12, 12, 12,
-	12, 12, 12, 12, 12, 12, 12, 12, 12, 12,
-	12, 12, 56, 5, 5, 4, 4, 4, 4,
+	29, 29, 29, 29, 29, 29, 29, 29, 19, 36,
+	36, 36, 35, 35, 35, 34, 34, 34, 37, 37,
+	27, 27, 26, 26, 26, 26, 55, 54, 54, 38,
+	39, 50, 50, 51, 51, 51, 49, 33, 33, 33,
+	33, 33, 33, 33, 33, 33, 52, 52, 53, 53,
+	58, 58, 57, 57, 32, 32, 32, 32, 32, 32,
+	32, 30, 30, 30, 30, 30, 30, 30, 31, 31,
+	31, 31, 31, 31, 31, 42, 42, 41, 41, 40,
+	45, 45, 44, 44, 43, 46, 47, 48, 20, 20,
+	20, 20, 20, 20, 20, 20, 20, 20, 20, 20,
+	20, 20, 20, 24, 24, 25, 25, 25, 25, 23,
+	23, 23, 23, 23, 23, 23, 23, 21, 21, 21,
+	17, 18, 16, 16, 16, 16, 16, 16, 16, 16,
+	16, 16, 16, 12, 12, 12, 12, 12, 12, 12,
+	12, 12, 12, 12, 12, 12, 12, 12, 59, 5,
+	5, 4, 4, 4, 4,
 }
 var exprR2 = [...]int{
 
@@ -430,105 +443,108 @@ var exprR2 = [...]int{
 	4, 5, 5, 6, 7, 7, 12, 1, 1, 1,
 	1, 1, 1, 3, 3, 2, 1, 3, 3, 3,
 	3, 3, 1, 2, 1, 2, 2, 2, 2, 2,
-	2, 2, 2, 2, 2, 1, 1, 4, 3, 2,
-	5, 4, 1, 3, 2, 1, 2, 1, 2, 1,
-	2, 1, 2, 2, 3, 2, 2, 1, 3, 3,
-	1, 3, 3, 2, 1, 1, 1, 1, 3, 2,
-	3, 3, 3, 3, 1, 1, 3, 6, 6, 1,
-	1, 3, 3, 3, 3, 3, 3, 3, 3, 3,
+	2, 2, 2, 2, 2, 2, 2, 2, 1, 1,
+	4, 3, 2, 5, 4, 1, 3, 2, 1, 2,
+	1, 2, 1, 2, 1, 2, 2, 3, 2, 2,
+	1, 3, 3, 1, 3, 3, 2, 1, 1, 1,
+	1, 3, 2, 3, 3, 3, 3, 1, 1, 3,
+	6, 6, 1, 1, 3, 3, 3, 3, 3, 3,
 	3, 3, 3, 3, 3, 3, 3, 3, 3, 3,
-	3, 3, 1, 1, 1, 3, 2, 1, 1, 1,
-	3, 2, 4, 4, 4, 4, 4, 4, 4, 4,
-	4, 4, 4, 4, 4, 4, 4, 0, 1, 5,
-	4, 5, 4, 1, 1, 2, 4, 5, 2, 4,
-	5, 1, 2, 2, 4, 1, 1, 1, 1, 1,
+	3, 3, 3, 3, 3, 1, 1, 1, 3, 2,
+	1, 1, 1, 3, 2, 2, 2, 2, 4, 4,
+	4, 4, 4, 4, 4, 4, 4, 4, 4, 4,
+	4, 4, 4, 0, 1, 5, 4, 5, 4, 1,
+	1, 2, 4, 5, 2, 4, 5, 1, 2, 2,
+	4, 1, 1, 1, 1, 1, 1, 1, 1, 1,
 	1, 1, 1, 1, 1, 1, 1, 1, 1, 1,
-	1, 1, 1, 1, 1, 1, 1, 1, 1, 1,
-	1, 1, 2, 1, 3, 4, 4, 3, 3,
+	1, 1, 1, 1, 1, 1, 1, 1, 2, 1,
+	3, 4, 4, 3, 3,
 }
 var exprChk = [...]int{
 
 	-1000, -1, -2, -6, -7, -14, 26, -11, -15, -20,
-	-21, -22, -17, 17, -12, -16, 7, 89, 90, 68,
+	-21, -22, -17, 17, -12, -16, 7, 92, 93, 68,
 	-18, 30, 31, 32, 44, 45, 54, 55, 56, 57,
 	58, 59, 60, 64, 65, 66, 33, 36, 39, 37,
-	38, 40, 41, 42, 43, 34, 35, 67, 80, 81,
-	82, 89, 90, 91, 92, 93, 94, 83, 84, 87,
-	88, 85, 86, -28, -29, -34, 50, -35, -3, 23,
-	24, 25, 15, 84, 16, -7, -6, -2, -10, 18,
+	38, 40, 41, 42, 43, 34, 35, 67, 83, 84,
+	85, 92, 93, 94, 95, 96, 97, 86, 87, 90,
+	91, 88, 89, -28, -29, -34, 50, -35, -3, 23,
+	24, 25, 15, 87, 16, -7, -6, -2, -10, 18,
 	-9, 5, 26, 26, -4, 28, 29, 7, 7, 26,
 	26, -23, -24, -25, 46, -23, -23, -23, -23, -23,
 	-23, -23, -23, -23, -23, -23, -23, -23, -23, -29,
-	-35, -27, -26, -52, -51, -33, -38, -39, -46, -40,
-	-43, 49, 47, 48, 69, 71, -9, -55, -54, -31,
-	26, 51, 77, 52, 78, 79, 5, -32, -30, 80,
-	6, -19, 72, 27, 27, 18, 2, 21, 13, 84,
-	14, 15, -8, 7, -14, 26, -7, 7, 26, 26,
-	26, -7, 7, -2, 73, 74, 75, 76, -2, -2,
-	-2, -2, -2, -2, -2, -2, -2, -2, -2, -2,
-	-2, -2, -33, 81, 21, 80, -37, -50, 8, -49,
-	5, -50, 6, 6, -33, 6, -48, -47, 5, -41,
-	-42, 5, -9, -44, -45, 5, -9, 13, 84, 87,
-	88, 85, 86, 83, -36, 6, -19, 80, 26, -9,
-	6, 6, 6, 6, 2, 27, 21, 10, -53, -28,
-	50, -14, -8, 27, 21, -7, 7, -5, 27, 5,
-	-5, 27, 21, 27, 26, 26, 26, 26, -33, -33,
-	-33, 8, -50, 21, 13, 27, 21, 13, 21, 21,
+	-35, -27, -26, -55, -54, -33, -38, -39, -49, -40,
+	-43, -46, -47, -48, 49, 47, 48, 69, 71, -9,
+	-58, -57, -31, 26, 51, 77, 52, 78, 79, 80,
+	81, 82, 5, -32, -30, 83, 6, -19, 72, 27,
+	27, 18, 2, 21, 13, 87, 14, 15, -8, 7,
+	-14, 26, -7, 7, 26, 26, 26, -7, 7, -2,
+	73, 74, 75, 76, -2, -2, -2, -2, -2, -2,
+	-2, -2, -2, -2, -2, -2, -2, -2, -33, 84,
+	21, 83, -37, -53, 8, -52, 5, -53, 6, 6,
+	-33, 6, -51, -50, 5, -41, -42, 5, -9, -44,
+	-45, 5, -9, -5, 5, 7, 7, 13, 87, 90,
+	91, 88, 89, 86, -36, 6, -19, 83, 26, -9,
+	6, 6, 6, 6, 2, 27, 21, 10, -56, -28,
+	50, -14, -8, 27, 21, -7, 7, -5, 27, -5,
+	27, 21, 27, 26, 26, 26, 26, -33, -33, -33,
+	8, -53, 21, 13, 27, 21, 13, 21, 21, 21,
 	72, 9, 4, 7, 72, 9, 4, 7, 9, 4,
 	7, 9, 4, 7, 9, 4, 7, 9, 4, 7,
-	9, 4, 7, 80, 26, -36, 6, -4, -8, -56,
-	-53, -28, 70, 10, 50, 10, -53, 53, 27, -53,
-	-28, 27, -4, -7, 27, 21, 21, 27, 27, 6,
-	-5, 27, -5, 27, 27, -5, 27, -5, -49, 6,
-	-47, 2, 5, 6, -42, -45, 26, 26, -36, 6,
-	27, 27, -53, -28, -53, 9, -56, -33, -56, 10,
-	5, -13, 61, 62, 63, 10, 27, 27, -53, 27,
-	-7, 5, 21, 27, 27, 27, 27, 6, 6, 27,
-	-4, -53, -56, 26, -56, -53, 50, 10, 10, 27,
-	-4, 27, 6, 27, 27, 5, -53, -56, -56, 10,
-	21, 27, -56, 6, 21, 6, 27,
+	9, 4, 7, 83, 26, -36, 6, -4, -8, -59,
+	-56, -28, 70, 10, 50, 10, -56, 53, 27, -56,
+	-28, 27, -4, -7, 27, 21, 27, 27, 6, -5,
+	27, -5, 27, 27, -5, 27, -5, -52, 6, -50,
+	2, 5, 6, -42, -45, 5, 26, 26, -36, 6,
+	27, 27, -56, -28, -56, 9, -59, -33, -59, 10,
+	5, -13, 61, 62, 63, 10, 27, 27, -56, 27,
+	-7, 21, 27, 27, 27, 27, 6, 6, 27, -4,
+	-56, -59, 26, -59, -56, 50, 10, 10, 27, -4,
+	27, 6, 27, 27, 5, -56, -59, -59, 10, 21,
+	27, -59, 6, 21, 6, 27,
 }
 var exprDef = [...]int{
 
 	0, -2, 1, 2, 3, 11, 0, 4, 5, 6,
-	7, 8, 9, 0, 0, 0, 191, 0, 0, 0,
-	0, 207, 208, 209, 210, 211, 212, 213, 214, 215,
-	216, 217, 218, 219, 220, 221, 196, 197, 198, 199,
-	200, 201, 202, 203, 204, 205, 206, 195, 177, 177,
-	177, 177, 177, 177, 177, 177, 177, 177, 177, 177,
-	177, 177, 177, 12, 72, 74, 0, 92, 0, 57,
+	7, 8, 9, 0, 0, 0, 197, 0, 0, 0,
+	0, 213, 214, 215, 216, 217, 218, 219, 220, 221,
+	222, 223, 224, 225, 226, 227, 202, 203, 204, 205,
+	206, 207, 208, 209, 210, 211, 212, 201, 183, 183,
+	183, 183, 183, 183, 183, 183, 183, 183, 183, 183,
+	183, 183, 183, 12, 72, 74, 0, 95, 0, 57,
 	58, 59, 60, 61, 62, 3, 2, 0, 0, 65,
-	66, 0, 0, 0, 0, 0, 0, 192, 193, 0,
-	0, 0, 183, 184, 178, 0, 0, 0, 0, 0,
+	66, 0, 0, 0, 0, 0, 0, 198, 199, 0,
+	0, 0, 189, 190, 184, 0, 0, 0, 0, 0,
 	0, 0, 0, 0, 0, 0, 0, 0, 0, 73,
-	94, 75, 76, 77, 78, 79, 80, 81, 82, 83,
-	84, 97, 99, 0, 101, 0, 114, 115, 116, 117,
-	0, 0, 107, 0, 0, 0, 0, 129, 130, 0,
-	89, 0, 85, 10, 13, 63, 64, 0, 0, 0,
-	0, 0, 0, 0, 0, 0, 3, 191, 0, 0,
-	0, 3, 0, 162, 0, 0, 185, 188, 163, 164,
-	165, 166, 167, 168, 169, 170, 171, 172, 173, 174,
-	175, 176, 119, 0, 0, 0, 98, 105, 95, 125,
-	124, 103, 100, 102, 0, 106, 113, 110, 0, 156,
-	154, 152, 153, 161, 159, 157, 158, 0, 0, 0,
-	0, 0, 0, 0, 93, 86, 0, 0, 0, 67,
+	97, 75, 76, 77, 78, 79, 80, 81, 82, 83,
+	84, 85, 86, 87, 100, 102, 0, 104, 0, 117,
+	118, 119, 120, 0, 0, 110, 0, 0, 0, 0,
+	0, 0, 0, 132, 133, 0, 92, 0, 88, 10,
+	13, 63, 64, 0, 0, 0, 0, 0, 0, 0,
+	0, 0, 3, 197, 0, 0, 0, 3, 0, 168,
+	0, 0, 191, 194, 169, 170, 171, 172, 173, 174,
+	175, 176, 177, 178, 179, 180, 181, 182, 122, 0,
+	0, 0, 101, 108, 98, 128, 127, 106, 103, 105,
+	0, 109, 116, 113, 0, 159, 157, 155, 156, 164,
+	162, 160, 161, 165, 229, 166, 167, 0, 0, 0,
+	0, 0, 0, 0, 96, 89, 0, 0, 0, 67,
 	68, 69, 70, 71, 39, 46, 0, 14, 0, 0,
-	0, 0, 0, 50, 0, 3, 191, 0, 227, 223,
-	0, 228, 0, 194, 0, 0, 0, 0, 120, 121,
-	122, 96, 104, 0, 0, 118, 0, 0, 0, 0,
-	0, 136, 143, 150, 0, 135, 142, 149, 131, 138,
-	145, 132, 139, 146, 133, 140, 147, 134, 141, 148,
-	137, 144, 151, 0, 0, 91, 0, 48, 0, 15,
+	0, 0, 0, 50, 0, 3, 197, 0, 233, 0,
+	234, 0, 200, 0, 0, 0, 0, 123, 124, 125,
+	99, 107, 0, 0, 121, 0, 0, 0, 0, 0,
+	0, 139, 146, 153, 0, 138, 145, 152, 134, 141,
+	148, 135, 142, 149, 136, 143, 150, 137, 144, 151,
+	140, 147, 154, 0, 0, 94, 0, 48, 0, 15,
 	18, 34, 0, 22, 0, 26, 0, 0, 0, 0,
-	0, 38, 52, 3, 51, 0, 0, 225, 226, 0,
-	0, 180, 0, 182, 186, 0, 189, 0, 126, 123,
-	111, 112, 108, 109, 155, 160, 0, 0, 88, 0,
-	90, 47, 19, 35, 36, 222, 23, 42, 27, 30,
+	0, 38, 52, 3, 51, 0, 231, 232, 0, 0,
+	186, 0, 188, 192, 0, 195, 0, 129, 126, 114,
+	115, 111, 112, 158, 163, 230, 0, 0, 91, 0,
+	93, 47, 19, 35, 36, 228, 23, 42, 27, 30,
 	40, 0, 43, 44, 45, 16, 0, 0, 0, 53,
-	3, 224, 0, 179, 181, 187, 190, 0, 0, 87,
-	49, 37, 31, 0, 17, 20, 0, 24, 28, 0,
-	54, 55, 0, 127, 128, 0, 21, 25, 29, 32,
-	0, 41, 33, 0, 0, 0, 56,
+	3, 0, 185, 187, 193, 196, 0, 0, 90, 49,
+	37, 31, 0, 17, 20, 0, 24, 28, 0, 54,
+	55, 0, 130, 131, 0, 21, 25, 29, 32, 0,
+	41, 33, 0, 0, 0, 56,
 }
 var exprTok1 = [...]int{
 
@@ -545,7 +561,7 @@ var exprTok2 = [...]int{
 	62, 63, 64, 65, 66, 67, 68, 69, 70, 71,
 	72, 73, 74, 75, 76, 77, 78, 79, 80, 81,
 	82, 83, 84, 85, 86, 87, 88, 89, 90, 91,
-	92, 93, 94,
+	92, 93, 94, 95, 96, 97,
 }
 var exprTok3 = [...]int{
 	0,
@@ -1301,728 +1317,758 @@ exprdefault:
 			exprVAL.PipelineStage = exprDollar[2].KeepLabelsExpr
 		}
 	case 85:
+		exprDollar = exprS[exprpt-2 : exprpt+1]
+		{
+			exprVAL.PipelineStage = exprDollar[2].DistinctExpr
+		}
+	case 86:
+		exprDollar = exprS[exprpt-2 : exprpt+1]
+		{
+			exprVAL.PipelineStage = exprDollar[2].LimitPerStreamExpr
+		}
+	case 87:
+		exprDollar = exprS[exprpt-2 : exprpt+1]
+		{
+			exprVAL.PipelineStage = exprDollar[2].SamplingExpr
+		}
+	case 88:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
 		{
 			exprVAL.FilterOp = OpFilterIP
 		}
-	case 86:
+	case 89:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
 		{
 			exprVAL.OrFilter = newLineFilterExpr(log.LineMatchEqual, "", exprDollar[1].str)
 		}
-	case 87:
+	case 90:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
 		{
 			exprVAL.OrFilter = newLineFilterExpr(log.LineMatchEqual, exprDollar[1].FilterOp, exprDollar[3].str)
 		}
-	case 88:
+	case 91:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
 		{
 			exprVAL.OrFilter = newOrLineFilter(newLineFilterExpr(log.LineMatchEqual, "", exprDollar[1].str), exprDollar[3].OrFilter)
 		}
-	case 89:
+	case 92:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
 		{
 			exprVAL.LineFilter = newLineFilterExpr(exprDollar[1].Filter, "", exprDollar[2].str)
 		}
-	case 90:
+	case 93:
 		exprDollar = exprS[exprpt-5 : exprpt+1]
 		{
 			exprVAL.LineFilter = newLineFilterExpr(exprDollar[1].Filter, exprDollar[2].FilterOp, exprDollar[4].str)
 		}
-	case 91:
+	case 94:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
 		{
 			exprVAL.LineFilter = newOrLineFilter(newLineFilterExpr(exprDollar[1].Filter, "", exprDollar[2].str), exprDollar[4].OrFilter)
 		}
-	case 92:
+	case 95:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
 		{
 			exprVAL.LineFilters = exprDollar[1].LineFilter
 		}
-	case 93:
+	case 96:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
 		{
 			exprVAL.LineFilters = newOrLineFilter(exprDollar[1].LineFilter, exprDollar[3].OrFilter)
 		}
-	case 94:
+	case 97:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
 		{
 			exprVAL.LineFilters = newNestedLineFilterExpr(exprDollar[1].LineFilters, exprDollar[2].LineFilter)
 		}
-	case 95:
+	case 98:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
 		{
 			exprVAL.ParserFlags = []string{exprDollar[1].str}
 		}
-	case 96:
+	case 99:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
 		{
 			exprVAL.ParserFlags = append(exprDollar[1].ParserFlags, exprDollar[2].str)
 		}
-	case 97:
+	case 100:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
 		{
 			exprVAL.LogfmtParser = newLogfmtParserExpr(nil)
 		}
-	case 98:
+	case 101:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
 		{
 			exprVAL.LogfmtParser = newLogfmtParserExpr(exprDollar[2].ParserFlags)
 		}
-	case 99:
+	case 102:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
 		{
 			exprVAL.LabelParser = newLabelParserExpr(OpParserTypeJSON, "")
 		}
-	case 100:
+	case 103:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
 		{
 			exprVAL.LabelParser = newLabelParserExpr(OpParserTypeRegexp, exprDollar[2].str)
 		}
-	case 101:
+	case 104:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
 		{
 			exprVAL.LabelParser = newLabelParserExpr(OpParserTypeUnpack, "")
 		}
-	case 102:
+	case 105:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
 		{
 			exprVAL.LabelParser = newLabelParserExpr(OpParserTypePattern, exprDollar[2].str)
 		}
-	case 103:
+	case 106:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
 		{
 			exprVAL.JSONExpressionParser = newJSONExpressionParser(exprDollar[2].LabelExtractionExpressionList)
 		}
-	case 104:
+	case 107:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
 		{
 			exprVAL.LogfmtExpressionParser = newLogfmtExpressionParser(exprDollar[3].LabelExtractionExpressionList, exprDollar[2].ParserFlags)
 		}
-	case 105:
+	case 108:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
 		{
 			exprVAL.LogfmtExpressionParser = newLogfmtExpressionParser(exprDollar[2].LabelExtractionExpressionList, nil)
 		}
-	case 106:
+	case 109:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
 		{
 			exprVAL.LineFormatExpr = newLineFmtExpr(exprDollar[2].str)
 		}
-	case 107:
+	case 110:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
 		{
 			exprVAL.DecolorizeExpr = newDecolorizeExpr()
 		}
-	case 108:
+	case 111:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
 		{
 			exprVAL.LabelFormat = log.NewRenameLabelFmt(exprDollar[1].str, exprDollar[3].str)
 		}
-	case 109:
+	case 112:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
 		{
 			exprVAL.LabelFormat = log.NewTemplateLabelFmt(exprDollar[1].str, exprDollar[3].str)
 		}
-	case 110:
+	case 113:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
 		{
 			exprVAL.LabelsFormat = []log.LabelFmt{exprDollar[1].LabelFormat}
 		}
-	case 111:
+	case 114:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
 		{
 			exprVAL.LabelsFormat = append(exprDollar[1].LabelsFormat, exprDollar[3].LabelFormat)
 		}
-	case 113:
+	case 116:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
 		{
 			exprVAL.LabelFormatExpr = newLabelFmtExpr(exprDollar[2].LabelsFormat)
 		}
-	case 114:
+	case 117:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
 		{
 			exprVAL.LabelFilter = log.NewStringLabelFilter(exprDollar[1].Matcher)
 		}
-	case 115:
+	case 118:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
 		{
 			exprVAL.LabelFilter = exprDollar[1].IPLabelFilter
 		}
-	case 116:
+	case 119:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
 		{
 			exprVAL.LabelFilter = exprDollar[1].UnitFilter
 		}
-	case 117:
+	case 120:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
 		{
 			exprVAL.LabelFilter = exprDollar[1].NumberFilter
 		}
-	case 118:
+	case 121:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
 		{
 			exprVAL.LabelFilter = exprDollar[2].LabelFilter
 		}
-	case 119:
+	case 122:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
 		{
 			exprVAL.LabelFilter = log.NewAndLabelFilter(exprDollar[1].LabelFilter, exprDollar[2].LabelFilter)
 		}
-	case 120:
+	case 123:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
 		{
 			exprVAL.LabelFilter = log.NewAndLabelFilter(exprDollar[1].LabelFilter, exprDollar[3].LabelFilter)
 		}
-	case 121:
+	case 124:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
 		{
 			exprVAL.LabelFilter = log.NewAndLabelFilter(exprDollar[1].LabelFilter, exprDollar[3].LabelFilter)
 		}
-	case 122:
+	case 125:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
 		{
 			exprVAL.LabelFilter = log.NewOrLabelFilter(exprDollar[1].LabelFilter, exprDollar[3].LabelFilter)
 		}
-	case 123:
+	case 126:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
 		{
 			exprVAL.LabelExtractionExpression = log.NewLabelExtractionExpr(exprDollar[1].str, exprDollar[3].str)
 		}
-	case 124:
+	case 127:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
 		{
 			exprVAL.LabelExtractionExpression = log.NewLabelExtractionExpr(exprDollar[1].str, exprDollar[1].str)
 		}
-	case 125:
+	case 128:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
 		{
 			exprVAL.LabelExtractionExpressionList = []log.LabelExtractionExpr{exprDollar[1].LabelExtractionExpression}
 		}
-	case 126:
+	case 129:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
 		{
 			exprVAL.LabelExtractionExpressionList = append(exprDollar[1].LabelExtractionExpressionList, exprDollar[3].LabelExtractionExpression)
 		}
-	case 127:
+	case 130:
 		exprDollar = exprS[exprpt-6 : exprpt+1]
 		{
 			exprVAL.IPLabelFilter = log.NewIPLabelFilter(exprDollar[5].str, exprDollar[1].str, log.LabelFilterEqual)
 		}
-	case 128:
+	case 131:
 		exprDollar = exprS[exprpt-6 : exprpt+1]
 		{
 			exprVAL.IPLabelFilter = log.NewIPLabelFilter(exprDollar[5].str, exprDollar[1].str, log.LabelFilterNotEqual)
 		}
-	case 129:
+	case 132:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
 		{
 			exprVAL.UnitFilter = exprDollar[1].DurationFilter
 		}
-	case 130:
+	case 133:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
 		{
 			exprVAL.UnitFilter = exprDollar[1].BytesFilter
 		}
-	case 131:
+	case 134:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
 		{
 			exprVAL.DurationFilter = log.NewDurationLabelFilter(log.LabelFilterGreaterThan, exprDollar[1].str, exprDollar[3].duration)
 		}
-	case 132:
+	case 135:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
 		{
 			exprVAL.DurationFilter = log.NewDurationLabelFilter(log.LabelFilterGreaterThanOrEqual, exprDollar[1].str, exprDollar[3].duration)
 		}
-	case 133:
+	case 136:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
 		{
 			exprVAL.DurationFilter = log.NewDurationLabelFilter(log.LabelFilterLesserThan, exprDollar[1].str, exprDollar[3].duration)
 		}
-	case 134:
+	case 137:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
 		{
 			exprVAL.DurationFilter = log.NewDurationLabelFilter(log.LabelFilterLesserThanOrEqual, exprDollar[1].str, exprDollar[3].duration)
 		}
-	case 135:
+	case 138:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
 		{
 			exprVAL.DurationFilter = log.NewDurationLabelFilter(log.LabelFilterNotEqual, exprDollar[1].str, exprDollar[3].duration)
 		}
-	case 136:
+	case 139:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
 		{
 			exprVAL.DurationFilter = log.NewDurationLabelFilter(log.LabelFilterEqual, exprDollar[1].str, exprDollar[3].duration)
 		}
-	case 137:
+	case 140:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
 		{
 			exprVAL.DurationFilter = log.NewDurationLabelFilter(log.LabelFilterEqual, exprDollar[1].str, exprDollar[3].duration)
 		}
-	case 138:
+	case 141:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
 		{
 			exprVAL.BytesFilter = log.NewBytesLabelFilter(log.LabelFilterGreaterThan, exprDollar[1].str, exprDollar[3].bytes)
 		}
-	case 139:
+	case 142:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
 		{
 			exprVAL.BytesFilter = log.NewBytesLabelFilter(log.LabelFilterGreaterThanOrEqual, exprDollar[1].str, exprDollar[3].bytes)
 		}
-	case 140:
+	case 143:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
 		{
 			exprVAL.BytesFilter = log.NewBytesLabelFilter(log.LabelFilterLesserThan, exprDollar[1].str, exprDollar[3].bytes)
 		}
-	case 141:
+	case 144:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
 		{
 			exprVAL.BytesFilter = log.NewBytesLabelFilter(log.LabelFilterLesserThanOrEqual, exprDollar[1].str, exprDollar[3].bytes)
 		}
-	case 142:
+	case 145:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
 		{
 			exprVAL.BytesFilter = log.NewBytesLabelFilter(log.LabelFilterNotEqual, exprDollar[1].str, exprDollar[3].bytes)
 		}
-	case 143:
+	case 146:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
 		{
 			exprVAL.BytesFilter = log.NewBytesLabelFilter(log.LabelFilterEqual, exprDollar[1].str, exprDollar[3].bytes)
 		}
-	case 144:
+	case 147:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
 		{
 			exprVAL.BytesFilter = log.NewBytesLabelFilter(log.LabelFilterEqual, exprDollar[1].str, exprDollar[3].bytes)
 		}
-	case 145:
+	case 148:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
 		{
 			exprVAL.NumberFilter = log.NewNumericLabelFilter(log.LabelFilterGreaterThan, exprDollar[1].str, mustNewFloat(exprDollar[3].str))
 		}
-	case 146:
+	case 149:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
 		{
 			exprVAL.NumberFilter = log.NewNumericLabelFilter(log.LabelFilterGreaterThanOrEqual, exprDollar[1].str, mustNewFloat(exprDollar[3].str))
 		}
-	case 147:
+	case 150:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
 		{
 			exprVAL.NumberFilter = log.NewNumericLabelFilter(log.LabelFilterLesserThan, exprDollar[1].str, mustNewFloat(exprDollar[3].str))
 		}
-	case 148:
+	case 151:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
 		{
 			exprVAL.NumberFilter = log.NewNumericLabelFilter(log.LabelFilterLesserThanOrEqual, exprDollar[1].str, mustNewFloat(exprDollar[3].str))
 		}
-	case 149:
+	case 152:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
 		{
 			exprVAL.NumberFilter = log.NewNumericLabelFilter(log.LabelFilterNotEqual, exprDollar[1].str, mustNewFloat(exprDollar[3].str))
 		}
-	case 150:
+	case 153:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
 		{
 			exprVAL.NumberFilter = log.NewNumericLabelFilter(log.LabelFilterEqual, exprDollar[1].str, mustNewFloat(exprDollar[3].str))
 		}
-	case 151:
+	case 154:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
 		{
 			exprVAL.NumberFilter = log.NewNumericLabelFilter(log.LabelFilterEqual, exprDollar[1].str, mustNewFloat(exprDollar[3].str))
 		}
-	case 152:
+	case 155:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
 		{
 			exprVAL.DropLabel = log.NewDropLabel(nil, exprDollar[1].str)
 		}
-	case 153:
+	case 156:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
 		{
 			exprVAL.DropLabel = log.NewDropLabel(exprDollar[1].Matcher, "")
 		}
-	case 154:
+	case 157:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
 		{
 			exprVAL.DropLabels = []log.DropLabel{exprDollar[1].DropLabel}
 		}
-	case 155:
+	case 158:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
 		{
 			exprVAL.DropLabels = append(exprDollar[1].DropLabels, exprDollar[3].DropLabel)
 		}
-	case 156:
+	case 159:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
 		{
 			exprVAL.DropLabelsExpr = newDropLabelsExpr(exprDollar[2].DropLabels)
 		}
-	case 157:
+	case 160:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
 		{
 			exprVAL.KeepLabel = log.NewKeepLabel(nil, exprDollar[1].str)
 		}
-	case 158:
+	case 161:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
 		{
 			exprVAL.KeepLabel = log.NewKeepLabel(exprDollar[1].Matcher, "")
 		}
-	case 159:
+	case 162:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
 		{
 			exprVAL.KeepLabels = []log.KeepLabel{exprDollar[1].KeepLabel}
 		}
-	case 160:
+	case 163:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
 		{
 			exprVAL.KeepLabels = append(exprDollar[1].KeepLabels, exprDollar[3].KeepLabel)
 		}
-	case 161:
+	case 164:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
 		{
 			exprVAL.KeepLabelsExpr = newKeepLabelsExpr(exprDollar[2].KeepLabels)
 		}
-	case 162:
+	case 165:
+		exprDollar = exprS[exprpt-2 : exprpt+1]
+		{
+			exprVAL.DistinctExpr = newDistinctExpr(exprDollar[2].Labels)
+		}
+	case 166:
+		exprDollar = exprS[exprpt-2 : exprpt+1]
+		{
+			exprVAL.LimitPerStreamExpr = mustNewLimitPerStreamExpr(exprDollar[2].str)
+		}
+	case 167:
+		exprDollar = exprS[exprpt-2 : exprpt+1]
+		{
+			exprVAL.SamplingExpr = mustNewSamplingExpr(exprDollar[2].str)
+		}
+	case 168:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
 		{
 			exprVAL.BinOpExpr = mustNewBinOpExpr("or", exprDollar[3].BinOpModifier, exprDollar[1].Expr, exprDollar[4].Expr)
 		}
-	case 163:
+	case 169:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
 		{
 			exprVAL.BinOpExpr = mustNewBinOpExpr("and", exprDollar[3].BinOpModifier, exprDollar[1].Expr, exprDollar[4].Expr)
 		}
-	case 164:
+	case 170:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
 		{
 			exprVAL.BinOpExpr = mustNewBinOpExpr("unless", exprDollar[3].BinOpModifier, exprDollar[1].Expr, exprDollar[4].Expr)
 		}
-	case 165:
+	case 171:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
 		{
 			exprVAL.BinOpExpr = mustNewBinOpExpr("+", exprDollar[3].BinOpModifier, exprDollar[1].Expr, exprDollar[4].Expr)
 		}
-	case 166:
+	case 172:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
 		{
 			exprVAL.BinOpExpr = mustNewBinOpExpr("-", exprDollar[3].BinOpModifier, exprDollar[1].Expr, exprDollar[4].Expr)
 		}
-	case 167:
+	case 173:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
 		{
 			exprVAL.BinOpExpr = mustNewBinOpExpr("*", exprDollar[3].BinOpModifier, exprDollar[1].Expr, exprDollar[4].Expr)
 		}
-	case 168:
+	case 174:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
 		{
 			exprVAL.BinOpExpr = mustNewBinOpExpr("/", exprDollar[3].BinOpModifier, exprDollar[1].Expr, exprDollar[4].Expr)
 		}
-	case 169:
+	case 175:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
 		{
 			exprVAL.BinOpExpr = mustNewBinOpExpr("%", exprDollar[3].BinOpModifier, exprDollar[1].Expr, exprDollar[4].Expr)
 		}
-	case 170:
+	case 176:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
 		{
 			exprVAL.BinOpExpr = mustNewBinOpExpr("^", exprDollar[3].BinOpModifier, exprDollar[1].Expr, exprDollar[4].Expr)
 		}
-	case 171:
+	case 177:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
 		{
 			exprVAL.BinOpExpr = mustNewBinOpExpr("==", exprDollar[3].BinOpModifier, exprDollar[1].Expr, exprDollar[4].Expr)
 		}
-	case 172:
+	case 178:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
 		{
 			exprVAL.BinOpExpr = mustNewBinOpExpr("!=", exprDollar[3].BinOpModifier, exprDollar[1].Expr, exprDollar[4].Expr)
 		}
-	case 173:
+	case 179:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
 		{
 			exprVAL.BinOpExpr = mustNewBinOpExpr(">", exprDollar[3].BinOpModifier, exprDollar[1].Expr, exprDollar[4].Expr)
 		}
-	case 174:
+	case 180:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
 		{
 			exprVAL.BinOpExpr = mustNewBinOpExpr(">=", exprDollar[3].BinOpModifier, exprDollar[1].Expr, exprDollar[4].Expr)
 		}
-	case 175:
+	case 181:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
 		{
 			exprVAL.BinOpExpr = mustNewBinOpExpr("<", exprDollar[3].BinOpModifier, exprDollar[1].Expr, exprDollar[4].Expr)
 		}
-	case 176:
+	case 182:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
 		{
 			exprVAL.BinOpExpr = mustNewBinOpExpr("<=", exprDollar[3].BinOpModifier, exprDollar[1].Expr, exprDollar[4].Expr)
 		}
-	case 177:
+	case 183:
 		exprDollar = exprS[exprpt-0 : exprpt+1]
 		{
 			exprVAL.BoolModifier = &BinOpOptions{VectorMatching: &VectorMatching{Card: CardOneToOne}}
 		}
-	case 178:
+	case 184:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
 		{
 			exprVAL.BoolModifier = &BinOpOptions{VectorMatching: &VectorMatching{Card: CardOneToOne}, ReturnBool: true}
 		}
-	case 179:
+	case 185:
 		exprDollar = exprS[exprpt-5 : exprpt+1]
 		{
 			exprVAL.OnOrIgnoringModifier = exprDollar[1].BoolModifier
 			exprVAL.OnOrIgnoringModifier.VectorMatching.On = true
 			exprVAL.OnOrIgnoringModifier.VectorMatching.MatchingLabels = exprDollar[4].Labels
 		}
-	case 180:
+	case 186:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
 		{
 			exprVAL.OnOrIgnoringModifier = exprDollar[1].BoolModifier
 			exprVAL.OnOrIgnoringModifier.VectorMatching.On = true
 		}
-	case 181:
+	case 187:
 		exprDollar = exprS[exprpt-5 : exprpt+1]
 		{
 			exprVAL.OnOrIgnoringModifier = exprDollar[1].BoolModifier
 			exprVAL.OnOrIgnoringModifier.VectorMatching.MatchingLabels = exprDollar[4].Labels
 		}
-	case 182:
+	case 188:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
 		{
 			exprVAL.OnOrIgnoringModifier = exprDollar[1].BoolModifier
 		}
-	case 183:
+	case 189:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
 		{
 			exprVAL.BinOpModifier = exprDollar[1].BoolModifier
 		}
-	case 184:
+	case 190:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
 		{
 			exprVAL.BinOpModifier = exprDollar[1].OnOrIgnoringModifier
 		}
-	case 185:
+	case 191:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
 		{
 			exprVAL.BinOpModifier = exprDollar[1].OnOrIgnoringModifier
 			exprVAL.BinOpModifier.VectorMatching.Card = CardManyToOne
 		}
-	case 186:
+	case 192:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
 		{
 			exprVAL.BinOpModifier = exprDollar[1].OnOrIgnoringModifier
 			exprVAL.BinOpModifier.VectorMatching.Card = CardManyToOne
 		}
-	case 187:
+	case 193:
 		exprDollar = exprS[exprpt-5 : exprpt+1]
 		{
 			exprVAL.BinOpModifier = exprDollar[1].OnOrIgnoringModifier
 			exprVAL.BinOpModifier.VectorMatching.Card = CardManyToOne
 			exprVAL.BinOpModifier.VectorMatching.Include = exprDollar[4].Labels
 		}
-	case 188:
+	case 194:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
 		{
 			exprVAL.BinOpModifier = exprDollar[1].OnOrIgnoringModifier
 			exprVAL.BinOpModifier.VectorMatching.Card = CardOneToMany
 		}
-	case 189:
+	case 195:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
 		{
 			exprVAL.BinOpModifier = exprDollar[1].OnOrIgnoringModifier
 			exprVAL.BinOpModifier.VectorMatching.Card = CardOneToMany
 		}
-	case 190:
+	case 196:
 		exprDollar = exprS[exprpt-5 : exprpt+1]
 		{
 			exprVAL.BinOpModifier = exprDollar[1].OnOrIgnoringModifier
 			exprVAL.BinOpModifier.VectorMatching.Card = CardOneToMany
 			exprVAL.BinOpModifier.VectorMatching.Include = exprDollar[4].Labels
 		}
-	case 191:
+	case 197:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
 		{
 			exprVAL.LiteralExpr = mustNewLiteralExpr(exprDollar[1].str, false)
 		}
-	case 192:
+	case 198:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
 		{
 			exprVAL.LiteralExpr = mustNewLiteralExpr(exprDollar[2].str, false)
 		}
-	case 193:
+	case 199:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
 		{
 			exprVAL.LiteralExpr = mustNewLiteralExpr(exprDollar[2].str, true)
 		}
-	case 194:
+	case 200:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
 		{
 			exprVAL.VectorExpr = NewVectorExpr(exprDollar[3].str)
 		}
-	case 195:
+	case 201:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
 		{
 			exprVAL.Vector = OpTypeVector
 		}
-	case 196:
+	case 202:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
 		{
 			exprVAL.VectorOp = OpTypeSum
 		}
-	case 197:
+	case 203:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
 		{
 			exprVAL.VectorOp = OpTypeAvg
 		}
-	case 198:
+	case 204:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
 		{
 			exprVAL.VectorOp = OpTypeCount
 		}
-	case 199:
+	case 205:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
 		{
 			exprVAL.VectorOp = OpTypeMax
 		}
-	case 200:
+	case 206:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
 		{
 			exprVAL.VectorOp = OpTypeMin
 		}
-	case 201:
+	case 207:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
 		{
 			exprVAL.VectorOp = OpTypeStddev
 		}
-	case 202:
+	case 208:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
 		{
 			exprVAL.VectorOp = OpTypeStdvar
 		}
-	case 203:
+	case 209:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
 		{
 			exprVAL.VectorOp = OpTypeBottomK
 		}
-	case 204:
+	case 210:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
 		{
 			exprVAL.VectorOp = OpTypeTopK
 		}
-	case 205:
+	case 211:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
 		{
 			exprVAL.VectorOp = OpTypeSort
 		}
-	case 206:
+	case 212:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
 		{
 			exprVAL.VectorOp = OpTypeSortDesc
 		}
-	case 207:
+	case 213:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
 		{
 			exprVAL.RangeOp = OpRangeTypeCount
 		}
-	case 208:
+	case 214:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
 		{
 			exprVAL.RangeOp = OpRangeTypeRate
 		}
-	case 209:
+	case 215:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
 		{
 			exprVAL.RangeOp = OpRangeTypeRateCounter
 		}
-	case 210:
+	case 216:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
 		{
 			exprVAL.RangeOp = OpRangeTypeBytes
 		}
-	case 211:
+	case 217:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
 		{
 			exprVAL.RangeOp = OpRangeTypeBytesRate
 		}
-	case 212:
+	case 218:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
 		{
 			exprVAL.RangeOp = OpRangeTypeAvg
 		}
-	case 213:
+	case 219:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
 		{
 			exprVAL.RangeOp = OpRangeTypeSum
 		}
-	case 214:
+	case 220:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
 		{
 			exprVAL.RangeOp = OpRangeTypeMin
 		}
-	case 215:
+	case 221:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
 		{
 			exprVAL.RangeOp = OpRangeTypeMax
 		}
-	case 216:
+	case 222:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
 		{
 			exprVAL.RangeOp = OpRangeTypeStdvar
 		}
-	case 217:
+	case 223:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
 		{
 			exprVAL.RangeOp = OpRangeTypeStddev
 		}
-	case 218:
+	case 224:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
 		{
 			exprVAL.RangeOp = OpRangeTypeQuantile
 		}
-	case 219:
+	case 225:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
 		{
 			exprVAL.RangeOp = OpRangeTypeFirst
 		}
-	case 220:
+	case 226:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
 		{
 			exprVAL.RangeOp = OpRangeTypeLast
 		}
-	case 221:
+	case 227:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
 		{
 			exprVAL.RangeOp = OpRangeTypeAbsent
 		}
-	case 222:
+	case 228:
 		exprDollar = exprS[exprpt-2 : exprpt+1]
 		{
 			exprVAL.OffsetExpr = newOffsetExpr(exprDollar[2].duration)
 		}
-	case 223:
+	case 229:
 		exprDollar = exprS[exprpt-1 : exprpt+1]
 		{
 			exprVAL.Labels = []string{exprDollar[1].str}
 		}
-	case 224:
+	case 230:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
 		{
 			exprVAL.Labels = append(exprDollar[1].Labels, exprDollar[3].str)
 		}
-	case 225:
+	case 231:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
 		{
 			exprVAL.Grouping = &Grouping{Without: false, Groups: exprDollar[3].Labels}
 		}
-	case 226:
+	case 232:
 		exprDollar = exprS[exprpt-4 : exprpt+1]
 		{
 			exprVAL.Grouping = &Grouping{Without: true, Groups: exprDollar[3].Labels}
 		}
-	case 227:
+	case 233:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
 		{
 			exprVAL.Grouping = &Grouping{Without: false, Groups: nil}
 		}
-	case 228:
+	case 234:
 		exprDollar = exprS[exprpt-3 : exprpt+1]
 		{
 			exprVAL.Grouping = &Grouping{Without: true, Groups: nil}
@@ -82,6 +82,13 @@ var tokens = map[string]int{
 	OpKeep: KEEP,
 }
 
+// stageTokens are tokens that are only keywords right after a pipe, so they can still be used as label names.
+var stageTokens = map[string]int{
+	OpDistinct:       DISTINCT,
+	OpLimitPerStream: LIMIT_PER_STREAM,
+	OpSample:         SAMPLE,
+}
+
 var parserFlags = map[string]struct{}{
 	OpStrict:    {},
 	OpKeepEmpty: {},
@@ -132,11 +139,18 @@ var functionTokens = map[string]int{
 
 type lexer struct {
 	Scanner
-	errs    []logqlmodel.ParseError
-	builder strings.Builder
+	errs      []logqlmodel.ParseError
+	builder   strings.Builder
+	lastToken int
 }
 
 func (l *lexer) Lex(lval *exprSymType) int {
+	tok := l.lex(lval)
+	l.lastToken = tok
+	return tok
+}
+
+func (l *lexer) lex(lval *exprSymType) int {
 	r := l.Scan()
 
 	switch r {
@@ -146,7 +160,7 @@ func (l *lexer) Lex(lval *exprSymType) int {
 		for next := l.Peek(); !(next == '\n' || next == scanner.EOF); next = l.Next() {
 		}
 
-		return l.Lex(lval)
+		return l.lex(lval)
 
 	case scanner.EOF:
 		return 0
@@ -238,6 +252,10 @@ func (l *lexer) Lex(lval *exprSymType) int {
 		return tok
 	}
 
+	if tok, ok := stageTokens[tokenTextLower]; ok && l.lastToken == PIPE {
+		return tok
+	}
+
 	if tok, ok := tokens[tokenNext]; ok {
 		l.Next()
 		return tok
@@ -47,6 +47,9 @@ func init() {
 	for str, tok := range tokens {
 		exprToknames[tok-exprPrivate+1] = str
 	}
+	for str, tok := range stageTokens {
+		exprToknames[tok-exprPrivate+1] = str
+	}
 }
 
 type parser struct {
@@ -58,6 +61,7 @@ type parser struct {
 
 func (p *parser) Parse() (Expr, error) {
 	p.lexer.errs = p.lexer.errs[:0]
+	p.lexer.lastToken = 0
 	p.lexer.Scanner.Error = func(_ *Scanner, msg string) {
 		p.lexer.Error(msg)
 	}
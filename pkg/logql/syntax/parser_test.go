@@ -3173,6 +3173,54 @@ var ParseTestCases = []struct {
 			},
 		},
 	},
+	{
+		in: `{app="foo"} | json | distinct user, method`,
+		exp: &PipelineExpr{
+			Left: newMatcherExpr([]*labels.Matcher{mustNewMatcher(labels.MatchEqual, "app", "foo")}),
+			MultiStages: MultiStageExpr{
+				newLabelParserExpr(OpParserTypeJSON, ""),
+				&DistinctExpr{Labels: []string{"user", "method"}},
+			},
+		},
+	},
+	{
+		in: `{sample="foo", distinct="bar"} | sample 0.05 | limit_per_stream 10`,
+		exp: &PipelineExpr{
+			Left: newMatcherExpr([]*labels.Matcher{
+				mustNewMatcher(labels.MatchEqual, "sample", "foo"),
+				mustNewMatcher(labels.MatchEqual, "distinct", "bar"),
+			}),
+			MultiStages: MultiStageExpr{
+				&SamplingExpr{Ratio: 0.05},
+				&LimitPerStreamExpr{Limit: 10},
+			},
+		},
+	},
+	{
+		in: `count_over_time({app="foo"} | logfmt | distinct user [5m])`,
+		exp: newRangeAggregationExpr(
+			newLogRange(&PipelineExpr{
+				Left: newMatcherExpr([]*labels.Matcher{mustNewMatcher(labels.MatchEqual, "app", "foo")}),
+				MultiStages: MultiStageExpr{
+					newLogfmtParserExpr(nil),
+					&DistinctExpr{Labels: []string{"user"}},
+				},
+			}, 5*time.Minute, nil, nil),
+			OpRangeTypeCount, nil, nil,
+		),
+	},
+	{
+		in:  `{app="foo"} | sample 2`,
+		err: logqlmodel.NewParseError("invalid sample ratio: 2, it must be greater than 0 and lower than or equal to 1", 0, 0),
+	},
+	{
+		in:  `{app="foo"} | limit_per_stream 0`,
+		err: logqlmodel.NewParseError("invalid limit_per_stream limit: 0, it must be a positive integer", 0, 0),
+	},
+	{
+		in:  `{app="foo"} | distinct`,
+		err: logqlmodel.NewParseError("syntax error: unexpected $end, expecting IDENTIFIER", 1, 23),
+	},
 }
 
 func TestParse(t *testing.T) {
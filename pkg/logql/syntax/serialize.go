@@ -307,16 +307,19 @@ func (v *JSONSerializer) VisitPipeline(e *PipelineExpr) {
 // Below are StageExpr visitors that we are skipping since a pipeline is
 // serialized as a string.
 func (*JSONSerializer) VisitDecolorize(*DecolorizeExpr)                     {}
+func (*JSONSerializer) VisitDistinct(*DistinctExpr)                         {}
 func (*JSONSerializer) VisitDropLabels(*DropLabelsExpr)                     {}
 func (*JSONSerializer) VisitJSONExpressionParser(*JSONExpressionParser)     {}
 func (*JSONSerializer) VisitKeepLabel(*KeepLabelsExpr)                      {}
 func (*JSONSerializer) VisitLabelFilter(*LabelFilterExpr)                   {}
 func (*JSONSerializer) VisitLabelFmt(*LabelFmtExpr)                         {}
 func (*JSONSerializer) VisitLabelParser(*LabelParserExpr)                   {}
+func (*JSONSerializer) VisitLimitPerStream(*LimitPerStreamExpr)             {}
 func (*JSONSerializer) VisitLineFilter(*LineFilterExpr)                     {}
 func (*JSONSerializer) VisitLineFmt(*LineFmtExpr)                           {}
 func (*JSONSerializer) VisitLogfmtExpressionParser(*LogfmtExpressionParser) {}
 func (*JSONSerializer) VisitLogfmtParser(*LogfmtParserExpr)                 {}
+func (*JSONSerializer) VisitSampling(*SamplingExpr)                         {}
 
 func encodeGrouping(s *jsoniter.Stream, g *Grouping) {
 	s.WriteObjectStart()
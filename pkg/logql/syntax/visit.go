@@ -30,16 +30,19 @@ type LogSelectorExprVisitor interface {
 
 type StageExprVisitor interface {
 	VisitDecolorize(*DecolorizeExpr)
+	VisitDistinct(*DistinctExpr)
 	VisitDropLabels(*DropLabelsExpr)
 	VisitJSONExpressionParser(*JSONExpressionParser)
 	VisitKeepLabel(*KeepLabelsExpr)
 	VisitLabelFilter(*LabelFilterExpr)
 	VisitLabelFmt(*LabelFmtExpr)
 	VisitLabelParser(*LabelParserExpr)
+	VisitLimitPerStream(*LimitPerStreamExpr)
 	VisitLineFilter(*LineFilterExpr)
 	VisitLineFmt(*LineFmtExpr)
 	VisitLogfmtExpressionParser(*LogfmtExpressionParser)
 	VisitLogfmtParser(*LogfmtParserExpr)
+	VisitSampling(*SamplingExpr)
 }
 
 var _ RootVisitor = &DepthFirstTraversal{}
@@ -47,6 +50,7 @@ var _ RootVisitor = &DepthFirstTraversal{}
 type DepthFirstTraversal struct {
 	VisitBinOpFn                  func(v RootVisitor, e *BinOpExpr)
 	VisitDecolorizeFn             func(v RootVisitor, e *DecolorizeExpr)
+	VisitDistinctFn               func(v RootVisitor, e *DistinctExpr)
 	VisitDropLabelsFn             func(v RootVisitor, e *DropLabelsExpr)
 	VisitJSONExpressionParserFn   func(v RootVisitor, e *JSONExpressionParser)
 	VisitKeepLabelFn              func(v RootVisitor, e *KeepLabelsExpr)
@@ -54,6 +58,7 @@ type DepthFirstTraversal struct {
 	VisitLabelFmtFn               func(v RootVisitor, e *LabelFmtExpr)
 	VisitLabelParserFn            func(v RootVisitor, e *LabelParserExpr)
 	VisitLabelReplaceFn           func(v RootVisitor, e *LabelReplaceExpr)
+	VisitLimitPerStreamFn         func(v RootVisitor, e *LimitPerStreamExpr)
 	VisitLineFilterFn             func(v RootVisitor, e *LineFilterExpr)
 	VisitLineFmtFn                func(v RootVisitor, e *LineFmtExpr)
 	VisitLiteralFn                func(v RootVisitor, e *LiteralExpr)
@@ -63,6 +68,7 @@ type DepthFirstTraversal struct {
 	VisitMatchersFn               func(v RootVisitor, e *MatchersExpr)
 	VisitPipelineFn               func(v RootVisitor, e *PipelineExpr)
 	VisitRangeAggregationFn       func(v RootVisitor, e *RangeAggregationExpr)
+	VisitSamplingFn               func(v RootVisitor, e *SamplingExpr)
 	VisitVectorFn                 func(v RootVisitor, e *VectorExpr)
 	VisitVectorAggregationFn      func(v RootVisitor, e *VectorAggregationExpr)
 }
@@ -90,6 +96,16 @@ func (v *DepthFirstTraversal) VisitDecolorize(e *DecolorizeExpr) {
 	}
 }
 
+// VisitDistinct implements RootVisitor.
+func (v *DepthFirstTraversal) VisitDistinct(e *DistinctExpr) {
+	if e == nil {
+		return
+	}
+	if v.VisitDistinctFn != nil {
+		v.VisitDistinctFn(v, e)
+	}
+}
+
 // VisitDropLabels implements RootVisitor.
 func (v *DepthFirstTraversal) VisitDropLabels(e *DropLabelsExpr) {
 	if e == nil {
@@ -160,6 +176,16 @@ func (v *DepthFirstTraversal) VisitLabelReplace(e *LabelReplaceExpr) {
 	}
 }
 
+// VisitLimitPerStream implements RootVisitor.
+func (v *DepthFirstTraversal) VisitLimitPerStream(e *LimitPerStreamExpr) {
+	if e == nil {
+		return
+	}
+	if v.VisitLimitPerStreamFn != nil {
+		v.VisitLimitPerStreamFn(v, e)
+	}
+}
+
 // VisitLineFilter implements RootVisitor.
 func (v *DepthFirstTraversal) VisitLineFilter(e *LineFilterExpr) {
 	if e == nil {
@@ -266,6 +292,16 @@ func (v *DepthFirstTraversal) VisitRangeAggregation(e *RangeAggregationExpr) {
 	}
 }
 
+// VisitSampling implements RootVisitor.
+func (v *DepthFirstTraversal) VisitSampling(e *SamplingExpr) {
+	if e == nil {
+		return
+	}
+	if v.VisitSamplingFn != nil {
+		v.VisitSamplingFn(v, e)
+	}
+}
+
 // VisitVector implements RootVisitor.
 func (v *DepthFirstTraversal) VisitVector(e *VectorExpr) {
 	if e == nil {
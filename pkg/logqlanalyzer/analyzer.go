@@ -2,6 +2,8 @@ package logqlanalyzer
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/pkg/errors"
@@ -15,30 +17,54 @@ import (
 type logQLAnalyzer struct {
 }
 
-func (a logQLAnalyzer) analyze(query string, logs []string) (*Result, error) {
-	expr, err := syntax.ParseLogSelector(query, true)
+func (a logQLAnalyzer) analyze(query string, logs []string, step time.Duration) (*Result, error) {
+	expr, err := syntax.ParseExpr(query)
 	if err != nil {
 		return nil, errors.Wrap(err, "invalid query")
 	}
+	switch expr := expr.(type) {
+	case syntax.LogSelectorExpr:
+		return a.analyzeLogQuery(expr, logs)
+	case syntax.SampleExpr:
+		return a.analyzeMetricQuery(query, expr, logs, step)
+	default:
+		return nil, fmt.Errorf("unsupported type of query")
+	}
+}
+
+func (a logQLAnalyzer) analyzeLogQuery(expr syntax.LogSelectorExpr, logs []string) (*Result, error) {
+	streamSelector, stages, analyzer, err := a.newPipelineAnalyzer(expr)
+	if err != nil {
+		return nil, err
+	}
+	// Log queries analyze the lines as they are, only metric queries need their timestamps.
+	now := time.Now().UnixNano()
+	entries := make([]entry, 0, len(logs))
+	for _, l := range logs {
+		entries = append(entries, entry{ts: now, line: l})
+	}
+	response := &Result{StreamSelector: streamSelector, Stages: stages, Results: make([]LineResult, 0, len(entries))}
+	for _, entry := range entries {
+		analysisRecords := analyzer.AnalyzeLine(entry.ts, entry.line)
+		response.Results = append(response.Results, mapAllToLineResult(entry, analysisRecords))
+	}
+	return response, nil
+}
+
+func (a logQLAnalyzer) newPipelineAnalyzer(expr syntax.LogSelectorExpr) (string, []string, PipelineAnalyzer, error) {
 	streamSelector, stages, err := a.extractExpressionParts(expr)
 	if err != nil {
-		return nil, errors.Wrap(err, "can not extract parts of expression")
+		return "", nil, nil, errors.Wrap(err, "can not extract parts of expression")
 	}
 	pipeline, err := expr.Pipeline()
 	if err != nil {
-		return nil, errors.Wrap(err, "can not create pipeline")
+		return "", nil, nil, errors.Wrap(err, "can not create pipeline")
 	}
 	streamLabels, err := parser.ParseMetric(streamSelector)
 	if err != nil {
-		return nil, errors.Wrap(err, "can not parse labels from stream selector")
+		return "", nil, nil, errors.Wrap(err, "can not parse labels from stream selector")
 	}
-	analyzer := NewPipelineAnalyzer(pipeline, streamLabels)
-	response := &Result{StreamSelector: streamSelector, Stages: stages, Results: make([]LineResult, 0, len(logs))}
-	for _, line := range logs {
-		analysisRecords := analyzer.AnalyzeLine(line)
-		response.Results = append(response.Results, mapAllToLineResult(line, analysisRecords))
-	}
-	return response, nil
+	return streamSelector, stages, NewPipelineAnalyzer(pipeline, streamLabels), nil
 }
 
 func (a logQLAnalyzer) extractExpressionParts(expr syntax.LogSelectorExpr) (string, []string, error) {
@@ -58,7 +84,7 @@ func (a logQLAnalyzer) extractExpressionParts(expr syntax.LogSelectorExpr) (stri
 
 }
 
-func mapAllToLineResult(originLine string, analysisRecords []StageAnalysisRecord) LineResult {
+func mapAllToLineResult(entry entry, analysisRecords []StageAnalysisRecord) LineResult {
 	stageRecords := make([]StageRecord, 0, len(analysisRecords))
 	for _, record := range analysisRecords {
 		if !record.Processed {
@@ -72,7 +98,7 @@ func mapAllToLineResult(originLine string, analysisRecords []StageAnalysisRecord
 			FilteredOut:  record.FilteredOut,
 		})
 	}
-	return LineResult{originLine, stageRecords}
+	return LineResult{OriginLine: entry.line, Timestamp: time.Unix(0, entry.ts).UTC(), StageRecords: stageRecords}
 }
 
 func mapAllToLabelsResponse(labels labels.Labels) []Label {
@@ -84,12 +110,12 @@ func mapAllToLabelsResponse(labels labels.Labels) []Label {
 }
 
 type PipelineAnalyzer interface {
-	AnalyzeLine(line string) []StageAnalysisRecord
+	AnalyzeLine(ts int64, line string) []StageAnalysisRecord
 }
 type noopPipelineAnalyzer struct {
 }
 
-func (n noopPipelineAnalyzer) AnalyzeLine(_ string) []StageAnalysisRecord {
+func (n noopPipelineAnalyzer) AnalyzeLine(_ int64, _ string) []StageAnalysisRecord {
 	return []StageAnalysisRecord{}
 }
 
@@ -107,7 +133,7 @@ func NewPipelineAnalyzer(origin log.Pipeline, streamLabels labels.Labels) Pipeli
 	return &noopPipelineAnalyzer{}
 }
 
-func (p streamPipelineAnalyzer) AnalyzeLine(line string) []StageAnalysisRecord {
+func (p streamPipelineAnalyzer) AnalyzeLine(ts int64, line string) []StageAnalysisRecord {
 	stages := p.origin.Stages()
 	stageRecorders := make([]log.Stage, 0, len(stages))
 	records := make([]StageAnalysisRecord, len(stages))
@@ -118,7 +144,7 @@ func (p streamPipelineAnalyzer) AnalyzeLine(line string) []StageAnalysisRecord {
 		})
 	}
 	stream := log.NewStreamPipeline(stageRecorders, p.origin.LabelsBuilder().ForLabels(p.streamLabels, p.streamLabels.Hash()))
-	_, _, _ = stream.ProcessString(time.Now().UnixMilli(), line)
+	_, _, _ = stream.ProcessString(ts, line)
 	return records
 }
 
@@ -157,3 +183,54 @@ type StageAnalysisRecord struct {
 	LabelsAfter  labels.Labels
 	FilteredOut  bool
 }
+
+// entry is an input log line with the timestamp it is analyzed at.
+type entry struct {
+	ts   int64
+	line string
+}
+
+// parseEntries splits the optional leading timestamp off every line. Lines without a timestamp are
+// analyzed at the given time.
+func parseEntries(logs []string, now time.Time) []entry {
+	entries := make([]entry, 0, len(logs))
+	for _, l := range logs {
+		ts, line, ok := parseTimestampedLine(l)
+		if !ok {
+			ts, line = now.UnixNano(), l
+		}
+		entries = append(entries, entry{ts: ts, line: line})
+	}
+	return entries
+}
+
+// parseTimestampedLine parses a line starting with a timestamp followed by a space or a tab. The timestamp
+// is either in RFC3339 format or a unix epoch in seconds, milliseconds, microseconds or nanoseconds.
+func parseTimestampedLine(l string) (int64, string, bool) {
+	i := strings.IndexAny(l, " \t")
+	if i <= 0 {
+		return 0, "", false
+	}
+	prefix, line := l[:i], l[i+1:]
+
+	if t, err := time.Parse(time.RFC3339Nano, prefix); err == nil {
+		return t.UnixNano(), line, true
+	}
+
+	epoch, err := strconv.ParseInt(prefix, 10, 64)
+	if err != nil {
+		return 0, "", false
+	}
+	switch len(prefix) {
+	case 10:
+		return time.Unix(epoch, 0).UnixNano(), line, true
+	case 13:
+		return time.UnixMilli(epoch).UnixNano(), line, true
+	case 16:
+		return time.UnixMicro(epoch).UnixNano(), line, true
+	case 19:
+		return epoch, line, true
+	default:
+		return 0, "", false
+	}
+}
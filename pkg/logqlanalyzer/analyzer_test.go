@@ -2,6 +2,7 @@ package logqlanalyzer
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -31,7 +32,7 @@ func Test_logQLAnalyzer_analyze_stages(t *testing.T) {
 	}
 	for name, data := range tests {
 		t.Run(name, func(t *testing.T) {
-			result, err := logQLAnalyzer{}.analyze(data.query, []string{})
+			result, err := logQLAnalyzer{}.analyze(data.query, []string{}, 0)
 			require.NoError(t, err)
 			require.Equal(t, data.expectedStreamSelector, result.StreamSelector)
 			require.Equal(t, data.expectedStages, result.Stages)
@@ -47,7 +48,7 @@ const (
 )
 
 func Test_logQLAnalyzer_analyze_expected_1_stage_record_for_each_log_line(t *testing.T) {
-	result, err := logQLAnalyzer{}.analyze("{job=\"analyze\"} | logfmt", []string{line1, line2})
+	result, err := logQLAnalyzer{}.analyze("{job=\"analyze\"} | logfmt", []string{line1, line2}, 0)
 
 	require.NoError(t, err)
 	require.Equal(t, 2, len(result.Results))
@@ -57,7 +58,7 @@ func Test_logQLAnalyzer_analyze_expected_1_stage_record_for_each_log_line(t *tes
 
 func Test_logQLAnalyzer_analyze_expected_all_stage_records_to_be_correct(t *testing.T) {
 	reformattedLine := "level=error message=A"
-	result, err := logQLAnalyzer{}.analyze("{job=\"analyze\"} | logfmt | line_format \"level={{.lvl}} message={{.msg | ToUpper}}\" |= \"info\"", []string{line1})
+	result, err := logQLAnalyzer{}.analyze("{job=\"analyze\"} | logfmt | line_format \"level={{.lvl}} message={{.msg | ToUpper}}\" |= \"info\"", []string{line1}, 0)
 	require.NoError(t, err)
 	require.Equal(t, 1, len(result.Results))
 	require.Equal(t, 3, len(result.Results[0].StageRecords), "expected records for two stages")
@@ -87,7 +88,7 @@ func Test_logQLAnalyzer_analyze_expected_all_stage_records_to_be_correct(t *test
 }
 
 func Test_logQLAnalyzer_analyze_expected_line_after_line_format_to_be_correct(t *testing.T) {
-	result, err := logQLAnalyzer{}.analyze("{job=\"analyze\"} | logfmt | line_format \"level={{.lvl}} message={{.msg | ToUpper}}\"", []string{line1, line2})
+	result, err := logQLAnalyzer{}.analyze("{job=\"analyze\"} | logfmt | line_format \"level={{.lvl}} message={{.msg | ToUpper}}\"", []string{line1, line2}, 0)
 
 	require.NoError(t, err)
 	require.Equal(t, 2, len(result.Results))
@@ -129,3 +130,128 @@ func Test_logQLAnalyzer_analyze_expected_line_after_line_format_to_be_correct(t
 		FilteredOut:  false,
 	}, result.Results[1].StageRecords[1], "line is expected to be reformatted on this stage")
 }
+
+func Test_logQLAnalyzer_analyze_log_query_keeps_leading_timestamps(t *testing.T) {
+	lines := []string{
+		"2024-01-01T00:00:00Z " + line1,
+		"1704067201000\t" + line2,
+	}
+	result, err := logQLAnalyzer{}.analyze("{job=\"analyze\"} | logfmt", lines, 0)
+
+	require.NoError(t, err)
+	require.Equal(t, 2, len(result.Results))
+	require.Equal(t, lines[0], result.Results[0].OriginLine)
+	require.Equal(t, lines[1], result.Results[1].OriginLine)
+}
+
+func Test_logQLAnalyzer_analyze_metric_query_timestamped_lines(t *testing.T) {
+	result, err := logQLAnalyzer{}.analyze("count_over_time({job=\"analyze\"} | logfmt [1m])", []string{
+		"2024-01-01T00:00:00Z " + line1,
+		"1704067201000\t" + line2,
+	}, 0)
+
+	require.NoError(t, err)
+	require.Equal(t, 2, len(result.Results))
+	require.Equal(t, line1, result.Results[0].OriginLine)
+	require.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), result.Results[0].Timestamp)
+	require.Equal(t, line2, result.Results[1].OriginLine)
+	require.Equal(t, time.Date(2024, 1, 1, 0, 0, 1, 0, time.UTC), result.Results[1].Timestamp)
+}
+
+func Test_logQLAnalyzer_analyze_metric_query_sample_records(t *testing.T) {
+	result, err := logQLAnalyzer{}.analyze("sum(sum_over_time({job=\"analyze\"} | logfmt | unwrap size [1m]))", []string{
+		"1704067200 lvl=info size=10",
+		"1704067210 lvl=info size=ten",
+	}, 0)
+
+	require.NoError(t, err)
+	require.Equal(t, "{job=\"analyze\"}", result.StreamSelector)
+	require.Equal(t, []string{"| logfmt"}, result.Stages)
+	require.Equal(t, 2, len(result.Results))
+
+	require.Equal(t, []SampleRecord{{
+		Expression: "sum_over_time({job=\"analyze\"} | logfmt | unwrap size[1m])",
+		Value:      10,
+		Labels:     []Label{{"job", "analyze"}, {"lvl", "info"}},
+	}}, result.Results[0].SampleRecords)
+
+	sample := result.Results[1].SampleRecords[0]
+	require.Equal(t, "SampleExtractionErr", sample.Error)
+	require.Contains(t, sample.ErrorDetails, "ten")
+	require.NotEmpty(t, result.SeriesError, "engine is expected to fail on the conversion error")
+	require.Empty(t, result.Series)
+}
+
+func Test_logQLAnalyzer_analyze_metric_query_series_per_step(t *testing.T) {
+	result, err := logQLAnalyzer{}.analyze("sum by (lvl) (count_over_time({job=\"analyze\"} | logfmt [30s]))", []string{
+		"1704067200 lvl=error msg=a",
+		"1704067210 lvl=info msg=b",
+		"1704067220 lvl=info msg=c",
+		"1704067260 lvl=error msg=d",
+	}, 30*time.Second)
+
+	require.NoError(t, err)
+	require.Equal(t, "30s", result.Step)
+	require.Empty(t, result.SeriesError)
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	require.Equal(t, []Series{
+		{
+			Labels: []Label{{"lvl", "error"}},
+			Points: []Point{
+				{Timestamp: start, Value: 1},
+				{Timestamp: start.Add(time.Minute), Value: 1},
+			},
+		},
+		{
+			Labels: []Label{{"lvl", "info"}},
+			Points: []Point{
+				{Timestamp: start.Add(30 * time.Second), Value: 2},
+			},
+		},
+	}, result.Series)
+}
+
+func Test_logQLAnalyzer_analyze_metric_query_default_step(t *testing.T) {
+	result, err := logQLAnalyzer{}.analyze("rate({job=\"analyze\"} [1m])", []string{
+		"1704067200 " + line1,
+		"1704074400 " + line2,
+	}, 0)
+
+	require.NoError(t, err)
+	require.Equal(t, "28s", result.Step)
+	require.Equal(t, 1, len(result.Series))
+}
+
+func Test_logQLAnalyzer_analyze_metric_query_same_stream_selectors(t *testing.T) {
+	result, err := logQLAnalyzer{}.analyze("count_over_time({job=\"analyze\", env=\"dev\"} |= \"error\" [1m]) / count_over_time({env=\"dev\", job=\"analyze\"} [1m])", []string{
+		"1704067200 lvl=error msg=a",
+		"1704067210 lvl=info msg=b",
+	}, 0)
+
+	require.NoError(t, err)
+	require.Len(t, result.Results[0].SampleRecords, 2)
+	require.False(t, result.Results[0].SampleRecords[0].FilteredOut)
+	require.True(t, result.Results[1].SampleRecords[0].FilteredOut)
+	require.False(t, result.Results[1].SampleRecords[1].FilteredOut)
+}
+
+func Test_logQLAnalyzer_analyze_metric_query_different_stream_selectors(t *testing.T) {
+	_, err := logQLAnalyzer{}.analyze("count_over_time({job=\"analyze\"} [1m]) / count_over_time({job=\"other\"} [1m])", []string{
+		"1704067200 lvl=error msg=a",
+	}, 0)
+
+	require.ErrorContains(t, err, "all range aggregations must use the same stream selector")
+}
+
+func Test_logQLAnalyzer_analyze_metric_query_too_many_steps(t *testing.T) {
+	lines := []string{
+		"1704067200 " + line1,
+		"1704153600 " + line2,
+	}
+	_, err := logQLAnalyzer{}.analyze("rate({job=\"analyze\"} [1m])", lines, time.Millisecond)
+	require.ErrorContains(t, err, "increase the step")
+
+	_, err = logQLAnalyzer{}.analyze("rate({job=\"analyze\"} [1m])", lines, 10*time.Second)
+	require.NoError(t, err)
+}
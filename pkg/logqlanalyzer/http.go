@@ -3,11 +3,14 @@ package logqlanalyzer
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"io"
 	"net/http"
+	"time"
 
 	"github.com/go-kit/log/level"
 	"github.com/gorilla/mux"
+	"github.com/prometheus/common/model"
 
 	util_log "github.com/grafana/loki/v3/pkg/util/log"
 )
@@ -42,7 +45,19 @@ func (s *LogQLAnalyzeHandler) ServeHTTP(w http.ResponseWriter, req *http.Request
 		writeError(req.Context(), w, err, http.StatusBadRequest, "unable unmarshal request body")
 		return
 	}
-	result, err := s.analyzer.analyze(requestBody.Query, requestBody.Logs)
+	var step time.Duration
+	if requestBody.Step != "" {
+		parsed, err := model.ParseDuration(requestBody.Step)
+		if err == nil && parsed <= 0 {
+			err = errors.New("step must be greater than 0")
+		}
+		if err != nil {
+			writeError(req.Context(), w, err, http.StatusBadRequest, "invalid step")
+			return
+		}
+		step = time.Duration(parsed)
+	}
+	result, err := s.analyzer.analyze(requestBody.Query, requestBody.Logs, step)
 	if err != nil {
 		writeError(req.Context(), w, err, http.StatusBadRequest, "unable to analyze query")
 		return
@@ -64,20 +79,28 @@ func writeError(ctx context.Context, w http.ResponseWriter, err error, statusCod
 	http.Error(w, err.Error(), statusCode)
 }
 
+// Request is the body of an analyze request. Logs can be prefixed with a timestamp followed by a space,
+// either in RFC3339 format or as a unix epoch. Step is only used by metric queries.
 type Request struct {
 	Query string   `json:"query"`
 	Logs  []string `json:"logs"`
+	Step  string   `json:"step,omitempty"`
 }
 
 type Result struct {
 	StreamSelector string       `json:"stream_selector"`
 	Stages         []string     `json:"stages"`
 	Results        []LineResult `json:"results"`
+	Step           string       `json:"step,omitempty"`
+	Series         []Series     `json:"series,omitempty"`
+	SeriesError    string       `json:"series_error,omitempty"`
 }
 
 type LineResult struct {
-	OriginLine   string        `json:"origin_line"`
-	StageRecords []StageRecord `json:"stage_records"`
+	OriginLine    string         `json:"origin_line"`
+	Timestamp     time.Time      `json:"timestamp"`
+	StageRecords  []StageRecord  `json:"stage_records"`
+	SampleRecords []SampleRecord `json:"sample_records,omitempty"`
 }
 
 type StageRecord struct {
@@ -92,3 +115,23 @@ type Label struct {
 	Name  string `json:"name"`
 	Value string `json:"value"`
 }
+
+// SampleRecord is the sample a range aggregation of a metric query extracted from a line.
+type SampleRecord struct {
+	Expression   string  `json:"expression"`
+	Value        float64 `json:"value"`
+	Labels       []Label `json:"labels"`
+	Error        string  `json:"error,omitempty"`
+	ErrorDetails string  `json:"error_details,omitempty"`
+	FilteredOut  bool    `json:"filtered_out"`
+}
+
+type Series struct {
+	Labels []Label `json:"labels"`
+	Points []Point `json:"points"`
+}
+
+type Point struct {
+	Timestamp time.Time `json:"timestamp"`
+	Value     float64   `json:"value"`
+}
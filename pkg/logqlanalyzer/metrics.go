@@ -0,0 +1,252 @@
+package logqlanalyzer
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cespare/xxhash/v2"
+	"github.com/grafana/dskit/user"
+	"github.com/pkg/errors"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/promql"
+	"github.com/prometheus/prometheus/promql/parser"
+
+	"github.com/grafana/loki/v3/pkg/iter"
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/logql"
+	"github.com/grafana/loki/v3/pkg/logql/log"
+	"github.com/grafana/loki/v3/pkg/logql/syntax"
+	"github.com/grafana/loki/v3/pkg/logqlmodel"
+)
+
+const (
+	// analyzerTenant is the tenant the metric queries are evaluated for.
+	analyzerTenant = "logql-analyzer"
+	// maxSteps is the maximum number of steps metric queries are evaluated at, the same as for range queries.
+	maxSteps = 11000
+)
+
+func (a logQLAnalyzer) analyzeMetricQuery(query string, expr syntax.SampleExpr, logs []string, step time.Duration) (*Result, error) {
+	var ranges []*syntax.RangeAggregationExpr
+	expr.Walk(func(e syntax.Expr) {
+		if r, ok := e.(*syntax.RangeAggregationExpr); ok {
+			ranges = append(ranges, r)
+		}
+	})
+	if len(ranges) == 0 {
+		return nil, fmt.Errorf("metric query without range aggregation is not supported")
+	}
+
+	// The lines are analyzed as a single stream, which can only be selected by one stream selector.
+	selector := streamSelectorKey(ranges[0].Left.Left)
+	for _, r := range ranges[1:] {
+		if streamSelectorKey(r.Left.Left) != selector {
+			return nil, fmt.Errorf("all range aggregations must use the same stream selector, got %s and %s",
+				syntax.MatchersString(ranges[0].Left.Left.Matchers()), syntax.MatchersString(r.Left.Left.Matchers()))
+		}
+	}
+
+	streamSelector, stages, analyzer, err := a.newPipelineAnalyzer(ranges[0].Left.Left)
+	if err != nil {
+		return nil, err
+	}
+	streamLabels, err := parser.ParseMetric(streamSelector)
+	if err != nil {
+		return nil, errors.Wrap(err, "can not parse labels from stream selector")
+	}
+
+	extractors := make([]log.StreamSampleExtractor, 0, len(ranges))
+	for _, r := range ranges {
+		extractor, err := r.Extractor()
+		if err != nil {
+			return nil, errors.Wrap(err, "can not create sample extractor")
+		}
+		extractors = append(extractors, extractor.ForStream(streamLabels))
+	}
+
+	entries := parseEntries(logs, time.Now())
+	response := &Result{StreamSelector: streamSelector, Stages: stages, Results: make([]LineResult, 0, len(entries))}
+	for _, entry := range entries {
+		lineResult := mapAllToLineResult(entry, analyzer.AnalyzeLine(entry.ts, entry.line))
+		lineResult.SampleRecords = make([]SampleRecord, 0, len(extractors))
+		for i, extractor := range extractors {
+			lineResult.SampleRecords = append(lineResult.SampleRecords, extractSample(ranges[i].String(), extractor, entry))
+		}
+		response.Results = append(response.Results, lineResult)
+	}
+
+	if len(entries) == 0 {
+		return response, nil
+	}
+
+	start, end := entriesTimeRange(entries)
+	if step <= 0 {
+		step = defaultStep(start, end)
+	}
+	if end.Sub(start)/step > maxSteps {
+		return nil, fmt.Errorf("the lines span %s, exceeding the maximum of %d steps of %s; increase the step", end.Sub(start), maxSteps, step)
+	}
+	response.Step = step.String()
+
+	series, err := evaluateMetricQuery(query, streamLabels, entries, start, end, step)
+	if err != nil {
+		// The lines still show which samples caused the error, e.g. conversion errors of unwrap.
+		response.SeriesError = err.Error()
+		return response, nil
+	}
+	response.Series = series
+	return response, nil
+}
+
+// streamSelectorKey returns the matchers of the stream selector of expr, independently of their order.
+func streamSelectorKey(expr syntax.LogSelectorExpr) string {
+	matchers := expr.Matchers()
+	keys := make([]string, 0, len(matchers))
+	for _, m := range matchers {
+		keys = append(keys, m.String())
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, ",")
+}
+
+func extractSample(expression string, extractor log.StreamSampleExtractor, entry entry) SampleRecord {
+	record := SampleRecord{Expression: expression}
+	value, lbs, ok := extractor.ProcessString(entry.ts, entry.line)
+	if !ok {
+		record.FilteredOut = true
+		return record
+	}
+	sampleLabels := lbs.Labels()
+	record.Value = value
+	record.Labels = mapAllToLabelsResponse(sampleLabels)
+	record.Error = sampleLabels.Get(logqlmodel.ErrorLabel)
+	record.ErrorDetails = sampleLabels.Get(logqlmodel.ErrorDetailsLabel)
+	return record
+}
+
+func entriesTimeRange(entries []entry) (time.Time, time.Time) {
+	start, end := entries[0].ts, entries[0].ts
+	for _, e := range entries[1:] {
+		if e.ts < start {
+			start = e.ts
+		}
+		if e.ts > end {
+			end = e.ts
+		}
+	}
+	return time.Unix(0, start), time.Unix(0, end)
+}
+
+// defaultStep returns the step Loki uses for range queries without a step parameter.
+func defaultStep(start, end time.Time) time.Duration {
+	return time.Duration(math.Max(math.Floor(end.Sub(start).Seconds()/250), 1)) * time.Second
+}
+
+// evaluateMetricQuery evaluates the query with the LogQL engine over the time range of the lines,
+// as if the lines were the only stream stored in Loki.
+func evaluateMetricQuery(query string, streamLabels labels.Labels, entries []entry, start, end time.Time, step time.Duration) ([]Series, error) {
+	params, err := logql.NewLiteralParams(query, start, end, step, 0, logproto.FORWARD, 0, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "can not create query parameters")
+	}
+
+	engine := logql.NewEngine(logql.EngineOpts{}, newLinesQuerier(streamLabels, entries), logql.NoLimits, nil)
+	ctx := user.InjectOrgID(context.Background(), analyzerTenant)
+	result, err := engine.Query(params).Exec(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var matrix promql.Matrix
+	switch data := result.Data.(type) {
+	case promql.Matrix:
+		matrix = data
+	case promql.Vector:
+		for _, s := range data {
+			matrix = append(matrix, promql.Series{Metric: s.Metric, Floats: []promql.FPoint{{T: s.T, F: s.F}}})
+		}
+	default:
+		return nil, fmt.Errorf("unexpected result type %s", result.Data.Type())
+	}
+
+	series := make([]Series, 0, len(matrix))
+	for _, s := range matrix {
+		points := make([]Point, 0, len(s.Floats))
+		for _, p := range s.Floats {
+			points = append(points, Point{Timestamp: time.UnixMilli(p.T).UTC(), Value: p.F})
+		}
+		series = append(series, Series{Labels: mapAllToLabelsResponse(s.Metric), Points: points})
+	}
+	return series, nil
+}
+
+// linesQuerier serves the analyzed lines as a single stream to the LogQL engine.
+type linesQuerier struct {
+	streamLabels labels.Labels
+	entries      []entry
+}
+
+func newLinesQuerier(streamLabels labels.Labels, entries []entry) linesQuerier {
+	sorted := make([]entry, len(entries))
+	copy(sorted, entries)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].ts < sorted[j].ts })
+	return linesQuerier{streamLabels: streamLabels, entries: sorted}
+}
+
+func (q linesQuerier) SelectLogs(_ context.Context, _ logql.SelectLogParams) (iter.EntryIterator, error) {
+	return iter.NoopIterator, nil
+}
+
+func (q linesQuerier) SelectSamples(_ context.Context, req logql.SelectSampleParams) (iter.SampleIterator, error) {
+	selector, err := req.LogSelector()
+	if err != nil {
+		return nil, err
+	}
+	for _, matcher := range selector.Matchers() {
+		if !matcher.Matches(q.streamLabels.Get(matcher.Name)) {
+			return iter.NoopIterator, nil
+		}
+	}
+
+	expr, err := req.Expr()
+	if err != nil {
+		return nil, err
+	}
+	extractor, err := expr.Extractor()
+	if err != nil {
+		return nil, err
+	}
+	streamExtractor := extractor.ForStream(q.streamLabels)
+
+	bySeries := map[string]*logproto.Series{}
+	var series []logproto.Series
+	for _, e := range q.entries {
+		value, lbs, ok := streamExtractor.ProcessString(e.ts, e.line)
+		if !ok {
+			continue
+		}
+		s, found := bySeries[lbs.String()]
+		if !found {
+			s = &logproto.Series{Labels: lbs.String(), StreamHash: streamExtractor.BaseLabels().Hash()}
+			bySeries[lbs.String()] = s
+		}
+		s.Samples = append(s.Samples, logproto.Sample{
+			Timestamp: e.ts,
+			Value:     value,
+			Hash:      xxhash.Sum64String(e.line),
+		})
+	}
+	for _, s := range bySeries {
+		series = append(series, *s)
+	}
+
+	return iter.NewTimeRangedSampleIterator(
+		iter.NewMultiSeriesIterator(series),
+		req.Start.UnixNano(),
+		req.End.UnixNano()+1,
+	), nil
+}
@@ -346,6 +346,8 @@ type Loki struct {
 	MemberlistKV              *memberlist.KVInitService
 	compactor                 *compactor.Compactor
 	QueryFrontEndMiddleware   queryrangebase.Middleware
+	queryBudget               *queryrange.QueryBudget
+	activeQueries             *queryrange.ActiveQueries
 	queryScheduler            *scheduler.Scheduler
 	querySchedulerRingManager *lokiring.RingManager
 	usageReport               *analytics.Reporter
@@ -891,8 +891,11 @@ func (t *Loki) initIngesterQuerier() (_ services.Service, err error) {
 	return services.NewIdleService(nil, nil), nil
 }
 
-// Placeholder limits type to pass to cortex frontend
-type disabledShuffleShardingLimits struct{}
+// Placeholder limits type to pass to cortex frontend.
+// Shuffle sharding is disabled, the remaining limits are taken from the overrides.
+type disabledShuffleShardingLimits struct {
+	scheduler.Limits
+}
 
 func (disabledShuffleShardingLimits) MaxQueriersPerUser(_ string) uint { return 0 }
 
@@ -914,6 +917,9 @@ func (i ingesterQueryOptions) QueryIngestersWithin() time.Duration {
 func (t *Loki) initQueryFrontendMiddleware() (_ services.Service, err error) {
 	level.Debug(util_log.Logger).Log("msg", "initializing query frontend tripperware")
 
+	t.queryBudget = queryrange.NewQueryBudget(t.Overrides, prometheus.DefaultRegisterer, t.Cfg.MetricsNamespace)
+	t.activeQueries = queryrange.NewActiveQueries(util_log.Logger, prometheus.DefaultRegisterer, t.Cfg.MetricsNamespace)
+
 	middleware, stopper, err := queryrange.NewMiddleware(
 		t.Cfg.QueryRange,
 		t.Cfg.Querier.Engine,
@@ -924,6 +930,8 @@ func (t *Loki) initQueryFrontendMiddleware() (_ services.Service, err error) {
 		t.cacheGenerationLoader, t.Cfg.CompactorConfig.RetentionEnabled,
 		prometheus.DefaultRegisterer,
 		t.Cfg.MetricsNamespace,
+		t.queryBudget,
+		t.activeQueries,
 	)
 	if err != nil {
 		return
@@ -999,7 +1007,7 @@ func (t *Loki) initQueryFrontend() (_ services.Service, err error) {
 	frontendTripper, frontendV1, frontendV2, err := frontend.InitFrontend(
 		combinedCfg,
 		scheduler.SafeReadRing(t.Cfg.QueryScheduler, t.querySchedulerRingManager),
-		disabledShuffleShardingLimits{},
+		disabledShuffleShardingLimits{t.Overrides},
 		t.Cfg.Server.GRPCListenPort,
 		util_log.Logger,
 		prometheus.DefaultRegisterer,
@@ -1031,7 +1039,7 @@ func (t *Loki) initQueryFrontend() (_ services.Service, err error) {
 
 	toMerge := []middleware.Interface{
 		httpreq.ExtractQueryTagsMiddleware(),
-		httpreq.PropagateHeadersMiddleware(httpreq.LokiActorPathHeader, httpreq.LokiEncodingFlagsHeader, httpreq.LokiDisablePipelineWrappersHeader),
+		httpreq.PropagateHeadersMiddleware(httpreq.LokiActorPathHeader, httpreq.LokiEncodingFlagsHeader, httpreq.LokiDisablePipelineWrappersHeader, httpreq.LokiQueryPriorityHeader),
 		serverutil.RecoveryHTTPMiddleware,
 		t.HTTPAuthMiddleware,
 		queryrange.StatsHTTPMiddleware,
@@ -1046,6 +1054,24 @@ func (t *Loki) initQueryFrontend() (_ services.Service, err error) {
 
 	frontendHandler = middleware.Merge(toMerge...).Wrap(frontendHandler)
 
+	// Exports stream their response as the pages of entries are queried, so they skip the frontend
+	// transport and the middlewares buffering or describing a JSON response.
+	exportMiddlewares := []middleware.Interface{
+		httpreq.ExtractQueryTagsMiddleware(),
+		httpreq.PropagateHeadersMiddleware(httpreq.LokiActorPathHeader, httpreq.LokiEncodingFlagsHeader, httpreq.LokiDisablePipelineWrappersHeader, httpreq.LokiQueryPriorityHeader),
+		serverutil.RecoveryHTTPMiddleware,
+		t.HTTPAuthMiddleware,
+	}
+	if t.Cfg.Querier.PerRequestLimitsEnabled {
+		exportMiddlewares = append(exportMiddlewares, querylimits.NewQueryLimitsMiddleware(log.With(util_log.Logger, "component", "query-limiter-middleware")))
+	}
+	exportHandler := queryrange.NewExportHandler(t.QueryFrontEndMiddleware.Wrap(frontendTripper), t.Overrides, t.activeQueries, util_log.Logger)
+	exportHandler = middleware.Merge(exportMiddlewares...).Wrap(gziphandler.GzipHandler(exportHandler))
+
+	// Context requests are composed of several log queries, so they skip the frontend transport too.
+	contextHandler := queryrange.NewContextHandler(t.QueryFrontEndMiddleware.Wrap(frontendTripper), t.Overrides, util_log.Logger)
+	contextHandler = middleware.Merge(exportMiddlewares...).Wrap(contextHandler)
+
 	var defaultHandler http.Handler
 	// If this process also acts as a Querier we don't do any proxying of tail requests
 	if t.Cfg.Frontend.TailProxyURL != "" && !t.isModuleActive(Querier) {
@@ -1081,6 +1107,8 @@ func (t *Loki) initQueryFrontend() (_ services.Service, err error) {
 	}
 	t.Server.HTTP.Path("/loki/api/v1/query_range").Methods("GET", "POST").Handler(frontendHandler)
 	t.Server.HTTP.Path("/loki/api/v1/query").Methods("GET", "POST").Handler(frontendHandler)
+	t.Server.HTTP.Path("/loki/api/v1/export").Methods("GET", "POST").Handler(exportHandler)
+	t.Server.HTTP.Path("/loki/api/v1/context").Methods("GET", "POST").Handler(contextHandler)
 	t.Server.HTTP.Path("/loki/api/v1/label").Methods("GET", "POST").Handler(frontendHandler)
 	t.Server.HTTP.Path("/loki/api/v1/labels").Methods("GET", "POST").Handler(frontendHandler)
 	t.Server.HTTP.Path("/loki/api/v1/label/{name}/values").Methods("GET", "POST").Handler(frontendHandler)
@@ -1096,6 +1124,9 @@ func (t *Loki) initQueryFrontend() (_ services.Service, err error) {
 	t.Server.HTTP.Path("/api/prom/label").Methods("GET", "POST").Handler(frontendHandler)
 	t.Server.HTTP.Path("/api/prom/label/{name}/values").Methods("GET", "POST").Handler(frontendHandler)
 	t.Server.HTTP.Path("/api/prom/series").Methods("GET", "POST").Handler(frontendHandler)
+	t.Server.HTTP.Path("/query-frontend/query_budget").Methods("GET").Handler(t.HTTPAuthMiddleware.Wrap(t.queryBudget))
+	t.Server.HTTP.Path("/query-frontend/active_queries").Methods("GET").Handler(t.HTTPAuthMiddleware.Wrap(t.activeQueries))
+	t.Server.HTTP.Path("/query-frontend/active_queries/cancel").Methods("POST").Handler(t.HTTPAuthMiddleware.Wrap(http.HandlerFunc(t.activeQueries.CancelHandler)))
 
 	// Only register tailing requests if this process does not act as a Querier
 	// If this process is also a Querier the Querier will register the tail endpoints.
@@ -1355,6 +1386,10 @@ func (t *Loki) initCompactor() (services.Service, error) {
 		t.InternalServer.HTTP.Path("/compactor/ring").Methods("GET", "POST").Handler(t.compactor)
 	}
 
+	if t.Cfg.CompactorConfig.Scrubber.Enabled {
+		t.Server.HTTP.Path("/compactor/scrub/report").Methods("GET").HandlerFunc(t.compactor.ScrubReportHandler)
+	}
+
 	if t.Cfg.CompactorConfig.RetentionEnabled {
 		t.Server.HTTP.Path("/loki/api/v1/delete").Methods("PUT", "POST").Handler(t.addCompactorMiddleware(t.compactor.DeleteRequestsHandler.AddDeleteRequestHandler))
 		t.Server.HTTP.Path("/loki/api/v1/delete").Methods("GET").Handler(t.addCompactorMiddleware(t.compactor.DeleteRequestsHandler.GetAllDeleteRequestsHandler))
@@ -1679,7 +1714,8 @@ func (t *Loki) createRulerQueryEngine(logger log.Logger) (eng *logql.Engine, err
 		return nil, fmt.Errorf("could not create querier: %w", err)
 	}
 
-	return logql.NewEngine(t.Cfg.Querier.Engine, q, t.Overrides, logger), nil
+	// Federated rule groups are evaluated against several tenants at once.
+	return logql.NewEngine(t.Cfg.Querier.Engine, querier.NewMultiTenantQuerier(q, logger), t.Overrides, logger), nil
 }
 
 func calculateMaxLookBack(pc config.PeriodConfig, maxLookBackConfig, minDuration time.Duration) (time.Duration, error) {
@@ -24,6 +24,7 @@ import (
 	"github.com/grafana/loki/v3/pkg/scheduler/limits"
 	"github.com/grafana/loki/v3/pkg/util"
 	lokigrpc "github.com/grafana/loki/v3/pkg/util/httpgrpc"
+	"github.com/grafana/loki/v3/pkg/util/httpreq"
 )
 
 var errTooManyRequest = httpgrpc.Errorf(http.StatusTooManyRequests, "too many outstanding requests")
@@ -46,6 +47,15 @@ type Limits interface {
 
 	// MaxQueryCapacity returns how much of the available query capacity can be used by this user.
 	MaxQueryCapacity(user string) float64
+
+	// HighPriorityQuerySources returns the query sources whose requests are enqueued with high priority.
+	HighPriorityQuerySources(user string) []string
+
+	// LowPriorityQuerySources returns the query sources whose requests are enqueued with low priority.
+	LowPriorityQuerySources(user string) []string
+
+	// MaxQueryPriority returns the highest priority a request can ask for with the X-Loki-Query-Priority header.
+	MaxQueryPriority(user string) string
 }
 
 // Frontend queues HTTP requests, dispatches them to backends, and handles retries
@@ -69,11 +79,12 @@ type Frontend struct {
 
 	// frontend metrics
 	numClients    prometheus.GaugeFunc
-	queueDuration prometheus.Histogram
+	queueDuration *prometheus.HistogramVec
 }
 
 type request struct {
 	enqueueTime time.Time
+	priority    queue.Priority
 	queueSpan   opentracing.Span
 	originalCtx context.Context
 
@@ -90,12 +101,12 @@ func New(cfg Config, frontendLimits Limits, log log.Logger, registerer prometheu
 		log:          log,
 		limits:       frontendLimits,
 		queueMetrics: queueMetrics,
-		queueDuration: promauto.With(registerer).NewHistogram(prometheus.HistogramOpts{
+		queueDuration: promauto.With(registerer).NewHistogramVec(prometheus.HistogramOpts{
 			Namespace: metricsNamespace,
 			Name:      "query_frontend_queue_duration_seconds",
 			Help:      "Time spend by requests queued.",
 			Buckets:   prometheus.DefBuckets,
-		}),
+		}, []string{"priority"}),
 	}
 
 	f.requestQueue = queue.NewRequestQueue(cfg.MaxOutstandingPerTenant, cfg.QuerierForgetDelay, limits.NewQueueLimits(frontendLimits), queueMetrics)
@@ -208,7 +219,7 @@ func (f *Frontend) Process(server frontendv1pb.Frontend_ProcessServer) error {
 
 		req := reqWrapper.(*request)
 
-		f.queueDuration.Observe(time.Since(req.enqueueTime).Seconds())
+		f.queueDuration.WithLabelValues(req.priority.String()).Observe(time.Since(req.enqueueTime).Seconds())
 		req.queueSpan.Finish()
 
 		/*
@@ -318,7 +329,14 @@ func (f *Frontend) queueRequest(ctx context.Context, req *request) error {
 	joinedTenantID := tenant.JoinTenantIDs(tenantIDs)
 	f.activeUsers.UpdateUserTimestamp(joinedTenantID, now)
 
-	err = f.requestQueue.Enqueue(joinedTenantID, nil, req, nil)
+	req.priority = queue.QueryPriority(
+		f.limits,
+		joinedTenantID,
+		httpreq.ExtractHTTPGRPCHeader(req.request, httpreq.LokiQueryPriorityHeader),
+		httpreq.ExtractHTTPGRPCHeader(req.request, string(httpreq.QueryTagsHTTPHeader)),
+	)
+
+	err = f.requestQueue.EnqueueWithPriority(joinedTenantID, nil, req.priority, req, nil)
 	if err == queue.ErrTooManyRequests {
 		return errTooManyRequest
 	}
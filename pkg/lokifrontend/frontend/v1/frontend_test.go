@@ -306,3 +306,15 @@ func (l mockLimits) MaxQueriersPerUser(_ string) uint {
 func (l mockLimits) MaxQueryCapacity(_ string) float64 {
 	return l.queryCapacity
 }
+
+func (l mockLimits) HighPriorityQuerySources(_ string) []string {
+	return nil
+}
+
+func (l mockLimits) LowPriorityQuerySources(_ string) []string {
+	return nil
+}
+
+func (l mockLimits) MaxQueryPriority(_ string) string {
+	return "normal"
+}
@@ -469,6 +469,11 @@ func (q *SingleTenantQuerier) Tail(ctx context.Context, req *logproto.TailReques
 		}
 	}
 
+	// Tailed lines are pushed by each ingester separately, stateful stages can't see all of them.
+	if syntax.HasStatefulStage(req.Plan.AST) {
+		return nil, httpgrpc.Errorf(http.StatusBadRequest, "stateful stages like distinct or limit_per_stream are not supported when tailing")
+	}
+
 	deletes, err := q.deletesForUser(ctx, req.Start, time.Now())
 	if err != nil {
 		level.Error(spanlogger.FromContext(ctx)).Log("msg", "failed loading deletes for user", "err", err)
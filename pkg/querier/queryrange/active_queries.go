@@ -0,0 +1,282 @@
+package queryrange
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/google/uuid"
+	"github.com/grafana/dskit/httpgrpc"
+	"github.com/grafana/dskit/tenant"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.uber.org/atomic"
+
+	"github.com/grafana/loki/v3/pkg/querier/queryrange/queryrangebase"
+	serverutil "github.com/grafana/loki/v3/pkg/util/server"
+)
+
+var errQueryCancelled = errors.New("the query was cancelled through the active queries API")
+
+// ActiveQueries tracks the log and metric queries in flight in the query frontend, so they can be listed and cancelled.
+// Cancelling a query cancels the context of all its sub-requests, which removes them from the scheduler queue and
+// stops the queriers working on them.
+type ActiveQueries struct {
+	logger log.Logger
+	now    func() time.Time
+
+	mtx     sync.Mutex
+	queries map[string]*activeQuery
+
+	cancelled *prometheus.CounterVec
+}
+
+type activeQuery struct {
+	id      string
+	tenants []string
+	typ     string
+	query   string
+	start   time.Time
+	cancel  context.CancelCauseFunc
+
+	outstanding atomic.Int64
+	completed   atomic.Int64
+	bytes       atomic.Uint64
+}
+
+// NewActiveQueries creates a new ActiveQueries.
+func NewActiveQueries(logger log.Logger, registerer prometheus.Registerer, metricsNamespace string) *ActiveQueries {
+	return &ActiveQueries{
+		logger:  logger,
+		now:     time.Now,
+		queries: map[string]*activeQuery{},
+		cancelled: promauto.With(registerer).NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "query_frontend_queries_cancelled_total",
+			Help:      "Total number of queries cancelled through the active queries API.",
+		}, []string{"tenant"}),
+	}
+}
+
+// start registers a query and returns its context, which is cancelled when the query is cancelled.
+func (a *ActiveQueries) start(ctx context.Context, tenants []string, typ, query string) (context.Context, *activeQuery) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	q := &activeQuery{
+		id:      uuid.NewString(),
+		tenants: tenants,
+		typ:     typ,
+		query:   query,
+		start:   a.now(),
+		cancel:  cancel,
+	}
+
+	a.mtx.Lock()
+	a.queries[q.id] = q
+	a.mtx.Unlock()
+
+	return context.WithValue(ctx, activeQueryKey{}, q), q
+}
+
+func (a *ActiveQueries) finish(q *activeQuery) {
+	a.mtx.Lock()
+	delete(a.queries, q.id)
+	a.mtx.Unlock()
+
+	q.cancel(nil)
+}
+
+// Cancel cancels the query with the given ID if it only queries the given tenants. It returns false if no such
+// query is in flight.
+func (a *ActiveQueries) Cancel(id string, tenantIDs []string) bool {
+	a.mtx.Lock()
+	q, ok := a.queries[id]
+	a.mtx.Unlock()
+	if !ok || !queriesOnly(q.tenants, tenantIDs) {
+		return false
+	}
+
+	level.Info(a.logger).Log("msg", "cancelling query", "id", id, "tenant", tenant.JoinTenantIDs(q.tenants), "query", q.query)
+	for _, id := range q.tenants {
+		a.cancelled.WithLabelValues(id).Inc()
+	}
+	q.cancel(errQueryCancelled)
+	return true
+}
+
+// ActiveQuery describes a query in flight.
+type ActiveQuery struct {
+	ID                  string   `json:"id"`
+	Tenants             []string `json:"tenants"`
+	Type                string   `json:"type"`
+	Query               string   `json:"query"`
+	Start               string   `json:"start"`
+	Duration            string   `json:"duration"`
+	OutstandingRequests int64    `json:"outstanding_requests"`
+	CompletedRequests   int64    `json:"completed_requests"`
+	BytesProcessed      uint64   `json:"bytes_processed"`
+}
+
+// List returns the queries in flight which only query the given tenants, the oldest first.
+func (a *ActiveQueries) List(tenantIDs []string) []ActiveQuery {
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+
+	now := a.now()
+	queries := make([]ActiveQuery, 0, len(a.queries))
+	for _, q := range a.queries {
+		if !queriesOnly(q.tenants, tenantIDs) {
+			continue
+		}
+		queries = append(queries, ActiveQuery{
+			ID:                  q.id,
+			Tenants:             q.tenants,
+			Type:                q.typ,
+			Query:               q.query,
+			Start:               q.start.UTC().Format(time.RFC3339Nano),
+			Duration:            now.Sub(q.start).String(),
+			OutstandingRequests: q.outstanding.Load(),
+			CompletedRequests:   q.completed.Load(),
+			BytesProcessed:      q.bytes.Load(),
+		})
+	}
+
+	sort.Slice(queries, func(i, j int) bool {
+		if queries[i].Start != queries[j].Start {
+			return queries[i].Start < queries[j].Start
+		}
+		return queries[i].ID < queries[j].ID
+	})
+	return queries
+}
+
+// queriesOnly returns true if all tenants of a query are among the given tenants. Queries spanning other tenants
+// are neither listed nor cancelled, since they expose and affect data of those tenants.
+func queriesOnly(queryTenants, tenantIDs []string) bool {
+	for _, id := range queryTenants {
+		if !slices.Contains(tenantIDs, id) {
+			return false
+		}
+	}
+	return true
+}
+
+// ServeHTTP lists the queries in flight of the tenants of the request.
+func (a *ActiveQueries) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	tenantIDs, err := tenant.TenantIDs(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(struct {
+		Queries []ActiveQuery `json:"queries"`
+	}{a.List(tenantIDs)}); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// CancelHandler cancels the query of the tenants of the request whose ID is given by the id parameter.
+func (a *ActiveQueries) CancelHandler(w http.ResponseWriter, r *http.Request) {
+	tenantIDs, err := tenant.TenantIDs(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	id := r.FormValue("id")
+	if id == "" {
+		http.Error(w, "id parameter is required", http.StatusBadRequest)
+		return
+	}
+	if !a.Cancel(id, tenantIDs) {
+		http.Error(w, "query not found", http.StatusNotFound)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type activeQueryKey struct{}
+
+func activeQueryFromContext(ctx context.Context) *activeQuery {
+	q, _ := ctx.Value(activeQueryKey{}).(*activeQuery)
+	return q
+}
+
+// NewActiveQueriesMiddleware creates a new Middleware that registers log and metric queries as active until they
+// complete.
+func NewActiveQueriesMiddleware(active *ActiveQueries) queryrangebase.Middleware {
+	return queryrangebase.MiddlewareFunc(func(next queryrangebase.Handler) queryrangebase.Handler {
+		if active == nil {
+			return next
+		}
+		return queryrangebase.HandlerFunc(func(ctx context.Context, r queryrangebase.Request) (queryrangebase.Response, error) {
+			var typ string
+			switch r.(type) {
+			case *LokiRequest:
+				typ = "range"
+			case *LokiInstantRequest:
+				typ = "instant"
+			default:
+				return next.Do(ctx, r)
+			}
+
+			// The request is part of a query already registered, such as a page of an export.
+			if activeQueryFromContext(ctx) != nil {
+				return next.Do(ctx, r)
+			}
+
+			tenantIDs, err := tenant.TenantIDs(ctx)
+			if err != nil {
+				return nil, httpgrpc.Errorf(http.StatusBadRequest, err.Error())
+			}
+
+			ctx, q := active.start(ctx, tenantIDs, typ, r.GetQuery())
+			defer active.finish(q)
+
+			resp, err := next.Do(ctx, r)
+			if err != nil && errors.Is(context.Cause(ctx), errQueryCancelled) {
+				return nil, httpgrpc.Errorf(serverutil.StatusClientClosedRequest, errQueryCancelled.Error())
+			}
+			return resp, err
+		})
+	})
+}
+
+// NewActiveQueriesDownstreamMiddleware creates a new Middleware that counts the sub-requests of the active queries
+// sent to the queriers, and the bytes they processed.
+func NewActiveQueriesDownstreamMiddleware(active *ActiveQueries) queryrangebase.Middleware {
+	return queryrangebase.MiddlewareFunc(func(next queryrangebase.Handler) queryrangebase.Handler {
+		if active == nil {
+			return next
+		}
+		return queryrangebase.HandlerFunc(func(ctx context.Context, r queryrangebase.Request) (queryrangebase.Response, error) {
+			q := activeQueryFromContext(ctx)
+			if q == nil {
+				return next.Do(ctx, r)
+			}
+			switch r.(type) {
+			case *LokiRequest, *LokiInstantRequest:
+			default:
+				return next.Do(ctx, r)
+			}
+
+			q.outstanding.Inc()
+			defer q.outstanding.Dec()
+
+			resp, err := next.Do(ctx, r)
+			q.completed.Inc()
+			if err == nil {
+				q.bytes.Add(bytesProcessed(resp))
+			}
+			return resp, err
+		})
+	})
+}
@@ -0,0 +1,134 @@
+package queryrange
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grafana/dskit/httpgrpc"
+	"github.com/grafana/dskit/user"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	base "github.com/grafana/loki/v3/pkg/querier/queryrange/queryrangebase"
+	"github.com/grafana/loki/v3/pkg/util/constants"
+	util_log "github.com/grafana/loki/v3/pkg/util/log"
+	serverutil "github.com/grafana/loki/v3/pkg/util/server"
+)
+
+func listActiveQueries(t *testing.T, active *ActiveQueries, orgID string) []ActiveQuery {
+	req := httptest.NewRequest(http.MethodGet, "/query-frontend/active_queries", nil)
+	rec := httptest.NewRecorder()
+	active.ServeHTTP(rec, req.WithContext(user.InjectOrgID(req.Context(), orgID)))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var resp struct {
+		Queries []ActiveQuery `json:"queries"`
+	}
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+	return resp.Queries
+}
+
+func cancelActiveQuery(active *ActiveQueries, orgID, id string) int {
+	req := httptest.NewRequest(http.MethodPost, "/query-frontend/active_queries/cancel?id="+id, nil)
+	rec := httptest.NewRecorder()
+	active.CancelHandler(rec, req.WithContext(user.InjectOrgID(req.Context(), orgID)))
+	return rec.Code
+}
+
+func TestActiveQueries(t *testing.T) {
+	active := NewActiveQueries(util_log.Logger, prometheus.NewRegistry(), constants.Loki)
+
+	// The first sub-request completes, the second one blocks until the query is cancelled.
+	started := make(chan struct{})
+	downstream := NewActiveQueriesDownstreamMiddleware(active).Wrap(base.HandlerFunc(func(ctx context.Context, r base.Request) (base.Response, error) {
+		if r.(*LokiRequest).Limit == 1 {
+			return scannedBytesHandler(42).Do(ctx, r)
+		}
+		close(started)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}))
+	handler := NewActiveQueriesMiddleware(active).Wrap(base.HandlerFunc(func(ctx context.Context, r base.Request) (base.Response, error) {
+		first := *r.(*LokiRequest)
+		first.Limit = 1
+		if _, err := downstream.Do(ctx, &first); err != nil {
+			return nil, err
+		}
+		return downstream.Do(ctx, r)
+	}))
+
+	errs := make(chan error, 1)
+	go func() {
+		_, err := handler.Do(user.InjectOrgID(context.Background(), "foo"), budgetTestRequest())
+		errs <- err
+	}()
+	<-started
+
+	queries := listActiveQueries(t, active, "foo")
+	require.Len(t, queries, 1)
+	q := queries[0]
+	require.Equal(t, []string{"foo"}, q.Tenants)
+	require.Equal(t, "range", q.Type)
+	require.Equal(t, budgetTestRequest().Query, q.Query)
+	require.Equal(t, int64(1), q.OutstandingRequests)
+	require.Equal(t, int64(1), q.CompletedRequests)
+	require.Equal(t, uint64(42), q.BytesProcessed)
+
+	require.Empty(t, listActiveQueries(t, active, "bar"))
+	require.Len(t, listActiveQueries(t, active, "bar|foo"), 1)
+
+	require.Equal(t, http.StatusNotFound, cancelActiveQuery(active, "foo", "unknown"))
+	require.Equal(t, http.StatusNotFound, cancelActiveQuery(active, "bar", q.ID))
+	require.Equal(t, http.StatusNoContent, cancelActiveQuery(active, "foo", q.ID))
+
+	err := <-errs
+	resp, ok := httpgrpc.HTTPResponseFromError(err)
+	require.True(t, ok)
+	require.Equal(t, int32(serverutil.StatusClientClosedRequest), resp.Code)
+	require.Equal(t, errQueryCancelled.Error(), string(resp.Body))
+
+	// The query is no longer listed once completed.
+	require.Empty(t, listActiveQueries(t, active, "foo"))
+	require.Equal(t, http.StatusNotFound, cancelActiveQuery(active, "foo", q.ID))
+}
+
+func TestActiveQueries_CrossTenantQueries(t *testing.T) {
+	active := NewActiveQueries(util_log.Logger, prometheus.NewRegistry(), constants.Loki)
+	ctx, q := active.start(context.Background(), []string{"bar", "foo"}, "range", `{app="foo"}`)
+	defer active.finish(q)
+
+	// A cross-tenant query is only visible to requests that include all of its tenants.
+	require.Empty(t, listActiveQueries(t, active, "foo"))
+	require.Equal(t, http.StatusNotFound, cancelActiveQuery(active, "foo", q.id))
+	require.NoError(t, ctx.Err())
+
+	require.Len(t, listActiveQueries(t, active, "foo|bar"), 1)
+	require.Equal(t, http.StatusNoContent, cancelActiveQuery(active, "foo|bar", q.id))
+	require.ErrorIs(t, context.Cause(ctx), errQueryCancelled)
+}
+
+func TestActiveQueries_RequiresTenant(t *testing.T) {
+	active := NewActiveQueries(util_log.Logger, prometheus.NewRegistry(), constants.Loki)
+
+	rec := httptest.NewRecorder()
+	active.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/query-frontend/active_queries", nil))
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = httptest.NewRecorder()
+	active.CancelHandler(rec, httptest.NewRequest(http.MethodPost, "/query-frontend/active_queries/cancel?id=foo", nil))
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestActiveQueries_IgnoresOtherRequests(t *testing.T) {
+	active := NewActiveQueries(util_log.Logger, prometheus.NewRegistry(), constants.Loki)
+	handler := NewActiveQueriesMiddleware(active).Wrap(base.HandlerFunc(func(ctx context.Context, _ base.Request) (base.Response, error) {
+		require.Nil(t, activeQueryFromContext(ctx))
+		return &LokiSeriesResponse{}, nil
+	}))
+
+	_, err := handler.Do(user.InjectOrgID(context.Background(), "foo"), &LokiSeriesRequest{Match: []string{`{app="foo"}`}})
+	require.NoError(t, err)
+}
@@ -367,6 +367,7 @@ func (Codec) DecodeRequest(_ context.Context, r *http.Request, _ []string) (quer
 			Plan: &plan.QueryPlan{
 				AST: parsed,
 			},
+			Cursor: encodeCursor(rangeQuery.Cursor),
 		}, nil
 	case InstantQueryOp:
 		req, err := loghttp.ParseInstantQuery(r)
@@ -582,6 +583,7 @@ func (Codec) DecodeHTTPGrpcRequest(ctx context.Context, r *httpgrpc.HTTPRequest)
 			Plan: &plan.QueryPlan{
 				AST: parsed,
 			},
+			Cursor: encodeCursor(req.Cursor),
 		}, ctx, nil
 	case InstantQueryOp:
 		req, err := loghttp.ParseInstantQuery(httpReq)
@@ -779,6 +781,11 @@ func (c Codec) EncodeRequest(ctx context.Context, r queryrangebase.Request) (*ht
 		header.Set(httpreq.LokiDisablePipelineWrappersHeader, disableWrappers)
 	}
 
+	// Add query priority
+	if priority := httpreq.ExtractHeader(ctx, httpreq.LokiQueryPriorityHeader); priority != "" {
+		header.Set(httpreq.LokiQueryPriorityHeader, priority)
+	}
+
 	// Add limits
 	if limits := querylimits.ExtractQueryLimitsContext(ctx); limits != nil {
 		err := querylimits.InjectQueryLimitsHeader(&header, limits)
@@ -1235,6 +1242,7 @@ func decodeResponseJSONFrom(buf []byte, req queryrangebase.Request, headers http
 				},
 				Headers:  httpResponseHeadersToPromResponseHeaders(headers),
 				Warnings: resp.Warnings,
+				Cursor:   resp.Data.Cursor,
 			}, nil
 		case loghttp.ResultTypeVector:
 			return &LokiPromResponse{
@@ -1372,7 +1380,7 @@ func encodeResponseJSONTo(version loghttp.Version, res queryrangebase.Response,
 				return err
 			}
 		} else {
-			if err := marshal.WriteQueryResponseJSON(logqlmodel.Streams(streams), response.Warnings, response.Statistics, w, encodeFlags); err != nil {
+			if err := marshal.WriteQueryResponseJSONWithCursor(logqlmodel.Streams(streams), response.Warnings, response.Statistics, response.Cursor, w, encodeFlags); err != nil {
 				return err
 			}
 		}
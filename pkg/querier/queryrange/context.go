@@ -0,0 +1,336 @@
+package queryrange
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/grafana/dskit/httpgrpc"
+	"github.com/grafana/dskit/tenant"
+	"github.com/prometheus/prometheus/model/labels"
+
+	"github.com/grafana/loki/v3/pkg/iter"
+	"github.com/grafana/loki/v3/pkg/loghttp"
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/logql/syntax"
+	"github.com/grafana/loki/v3/pkg/querier/plan"
+	"github.com/grafana/loki/v3/pkg/querier/queryrange/queryrangebase"
+	"github.com/grafana/loki/v3/pkg/util/httpreq"
+	serverutil "github.com/grafana/loki/v3/pkg/util/server"
+	"github.com/grafana/loki/v3/pkg/util/validation"
+)
+
+// defaultContextMaxEntries bounds the entries queried at once when the tenant has no max entries limit.
+const defaultContextMaxEntries = 5000
+
+// contextHandler returns the entries surrounding a log entry. The entry is located among the
+// entries sharing its timestamp, which are ordered by stream, and the entries before and after
+// it are queried through the query frontend middlewares.
+type contextHandler struct {
+	next   queryrangebase.Handler
+	limits Limits
+	logger log.Logger
+}
+
+// NewContextHandler returns the handler of the context API, querying the entries with the given handler.
+func NewContextHandler(next queryrangebase.Handler, limits Limits, logger log.Logger) http.Handler {
+	return &contextHandler{
+		next:   next,
+		limits: limits,
+		logger: logger,
+	}
+}
+
+func (h *contextHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		serverutil.WriteError(httpgrpc.Errorf(http.StatusBadRequest, err.Error()), w)
+		return
+	}
+	req, err := loghttp.ParseContextQuery(r)
+	if err != nil {
+		serverutil.WriteError(httpgrpc.Errorf(http.StatusBadRequest, err.Error()), w)
+		return
+	}
+	matchers, err := syntax.ParseMatchers(req.Query, true)
+	if err != nil {
+		serverutil.WriteError(httpgrpc.Errorf(http.StatusBadRequest, err.Error()), w)
+		return
+	}
+	tenantIDs, err := tenant.TenantIDs(r.Context())
+	if err != nil {
+		serverutil.WriteError(httpgrpc.Errorf(http.StatusBadRequest, err.Error()), w)
+		return
+	}
+
+	ctx := r.Context()
+	maxEntries := validation.SmallestPositiveIntPerTenant(tenantIDs, func(id string) int {
+		return h.limits.MaxEntriesLimitPerQuery(ctx, id)
+	})
+	if maxEntries <= 0 {
+		maxEntries = defaultContextMaxEntries
+	}
+	if req.Before > uint32(maxEntries) || req.After > uint32(maxEntries) {
+		serverutil.WriteError(httpgrpc.Errorf(http.StatusBadRequest, "max entries limit per query exceeded, limit > max_entries_limit (%d > %d)", max(req.Before, req.After), maxEntries), w)
+		return
+	}
+
+	// The stream labels are kept apart from the structured metadata of the entries.
+	ctx = httpreq.AddEncodingFlagsToContext(ctx, httpreq.NewEncodingFlags(httpreq.FlagCategorizeLabels))
+
+	c := &logContext{contextHandler: h, req: req, maxEntries: uint32(maxEntries)}
+	resp, err := c.run(ctx, matchers)
+	if err != nil {
+		serverutil.WriteError(err, w)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		serverutil.WriteError(err, w)
+	}
+}
+
+type logContext struct {
+	*contextHandler
+
+	req        *loghttp.ContextQuery
+	maxEntries uint32
+}
+
+func (c *logContext) run(ctx context.Context, matchers []*labels.Matcher) (*loghttp.ContextResponse, error) {
+	ts := c.req.Timestamp
+
+	// Locate the entry among the ones sharing its timestamp.
+	same, err := c.query(ctx, syntax.MatchersString(matchers), logproto.FORWARD, ts, ts.Add(time.Nanosecond), c.maxEntries)
+	if err != nil {
+		return nil, err
+	}
+	target := -1
+	for i, e := range same {
+		if loghttp.ContextLineHash(e.Line) == c.req.LineHash {
+			target = i
+			break
+		}
+	}
+	if target < 0 {
+		return nil, httpgrpc.Errorf(http.StatusNotFound, "no entry with the line hash %016x at the timestamp %d", c.req.LineHash, ts.UnixNano())
+	}
+	entry := same[target]
+
+	// Select the streams of the context: the stream of the entry, or the ones related to it.
+	lbls, err := syntax.ParseLabels(entry.labels)
+	if err != nil {
+		return nil, err
+	}
+	keep := func(e exportEntry) bool { return e.labels == entry.labels }
+	contextMatchers := make([]*labels.Matcher, 0, len(lbls))
+	if len(c.req.Related) == 0 {
+		for _, l := range lbls {
+			contextMatchers = append(contextMatchers, labels.MustNewMatcher(labels.MatchEqual, l.Name, l.Value))
+		}
+	} else {
+		for _, name := range c.req.Related {
+			value := lbls.Get(name)
+			if value == "" {
+				return nil, httpgrpc.Errorf(http.StatusBadRequest, "the stream of the entry has no label %q", name)
+			}
+			contextMatchers = append(contextMatchers, labels.MustNewMatcher(labels.MatchEqual, name, value))
+		}
+		keep = func(exportEntry) bool { return true }
+
+		// Other streams may hold entries sharing the timestamp of the entry.
+		same, err = c.query(ctx, syntax.MatchersString(contextMatchers), logproto.FORWARD, ts, ts.Add(time.Nanosecond), c.maxEntries)
+		if err != nil {
+			return nil, err
+		}
+	}
+	contextSelector := syntax.MatchersString(contextMatchers)
+
+	var sameBefore, sameAfter []exportEntry
+	found := false
+	for _, e := range same {
+		switch {
+		case !keep(e):
+		case !found && e.labels == entry.labels && e.Line == entry.Line:
+			found = true
+		case found:
+			sameAfter = append(sameAfter, e)
+		default:
+			sameBefore = append(sameBefore, e)
+		}
+	}
+
+	before := sameBefore
+	if want := int(c.req.Before) - len(sameBefore); want > 0 {
+		earlier, err := c.queryContext(ctx, contextSelector, keep, logproto.BACKWARD, ts.Add(-c.req.Window), ts, want)
+		if err != nil {
+			return nil, err
+		}
+		before = append(earlier, sameBefore...)
+	}
+	if len(before) > int(c.req.Before) {
+		before = before[len(before)-int(c.req.Before):]
+	}
+
+	after := sameAfter
+	if want := int(c.req.After) - len(sameAfter); want > 0 {
+		later, err := c.queryContext(ctx, contextSelector, keep, logproto.FORWARD, ts.Add(time.Nanosecond), ts.Add(c.req.Window), want)
+		if err != nil {
+			return nil, err
+		}
+		after = append(after, later...)
+	}
+	if len(after) > int(c.req.After) {
+		after = after[:c.req.After]
+	}
+
+	resp := &loghttp.ContextResponse{
+		Status: loghttp.QueryStatusSuccess,
+		Data: loghttp.ContextResponseData{
+			Entry:  contextEntry(lbls, entry),
+			Before: make([]loghttp.ContextEntry, 0, len(before)),
+			After:  make([]loghttp.ContextEntry, 0, len(after)),
+		},
+	}
+	for _, e := range before {
+		if resp.Data.Before, err = appendContextEntry(resp.Data.Before, e); err != nil {
+			return nil, err
+		}
+	}
+	for _, e := range after {
+		if resp.Data.After, err = appendContextEntry(resp.Data.After, e); err != nil {
+			return nil, err
+		}
+	}
+	return resp, nil
+}
+
+// queryContext returns up to want entries of the context streams closest to the entry, in forward order.
+// The selector can match streams other than the ones kept, in which case the limit is raised until
+// enough entries are kept or the range is exhausted.
+func (c *logContext) queryContext(ctx context.Context, selector string, keep func(exportEntry) bool, direction logproto.Direction, start, end time.Time, want int) ([]exportEntry, error) {
+	// One more entry than wanted, as the entries sharing the farthest timestamp are dropped.
+	limit := min(uint32(want)+1, c.maxEntries)
+	for {
+		entries, err := c.query(ctx, selector, direction, start, end, limit)
+		if err != nil {
+			return nil, err
+		}
+		if direction == logproto.BACKWARD {
+			forwardOrder(entries)
+		}
+
+		full := uint32(len(entries)) >= limit
+		if full && limit < c.maxEntries {
+			// The response may hold only some of the entries sharing the farthest timestamp.
+			entries = dropFarthest(entries, direction)
+		}
+
+		kept := make([]exportEntry, 0, len(entries))
+		for _, e := range entries {
+			if keep(e) {
+				kept = append(kept, e)
+			}
+		}
+		if len(kept) >= want || !full || limit >= c.maxEntries {
+			if direction == logproto.BACKWARD {
+				return kept[max(0, len(kept)-want):], nil
+			}
+			return kept[:min(want, len(kept))], nil
+		}
+		limit = min(2*limit, c.maxEntries)
+	}
+}
+
+// dropFarthest drops the entries, in forward order, sharing the timestamp farthest from the entry.
+func dropFarthest(entries []exportEntry, direction logproto.Direction) []exportEntry {
+	if len(entries) == 0 {
+		return entries
+	}
+	if direction == logproto.BACKWARD {
+		i := 0
+		for i < len(entries) && entries[i].Timestamp.Equal(entries[0].Timestamp) {
+			i++
+		}
+		return entries[i:]
+	}
+	i := len(entries)
+	for i > 0 && entries[i-1].Timestamp.Equal(entries[len(entries)-1].Timestamp) {
+		i--
+	}
+	return entries[:i]
+}
+
+// query returns the entries of the range in the order of the direction.
+func (c *logContext) query(ctx context.Context, selector string, direction logproto.Direction, start, end time.Time, limit uint32) ([]exportEntry, error) {
+	expr, err := syntax.ParseLogSelector(selector, true)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.next.Do(ctx, &LokiRequest{
+		Query:     selector,
+		Limit:     limit,
+		Direction: direction,
+		StartTs:   start.UTC(),
+		EndTs:     end.UTC(),
+		Path:      "/loki/api/v1/query_range",
+		Plan:      &plan.QueryPlan{AST: expr},
+	})
+	if err != nil {
+		return nil, err
+	}
+	lokiResp, ok := resp.(*LokiResponse)
+	if !ok {
+		return nil, fmt.Errorf("unexpected response type %T", resp)
+	}
+
+	it := iter.NewStreamsIterator(lokiResp.Data.Result, direction)
+	defer it.Close()
+	var entries []exportEntry
+	for it.Next() {
+		entries = append(entries, exportEntry{Entry: it.Entry(), labels: it.Labels()})
+	}
+	return entries, it.Error()
+}
+
+// forwardOrder reorders entries returned by a backward query: entries sharing a timestamp are ordered
+// by stream, and the entries of a stream sharing a timestamp keep their order within the stream.
+func forwardOrder(entries []exportEntry) {
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		if !entries[i].Timestamp.Equal(entries[j].Timestamp) {
+			return entries[i].Timestamp.Before(entries[j].Timestamp)
+		}
+		return entries[i].labels < entries[j].labels
+	})
+}
+
+func appendContextEntry(entries []loghttp.ContextEntry, e exportEntry) ([]loghttp.ContextEntry, error) {
+	lbls, err := syntax.ParseLabels(e.labels)
+	if err != nil {
+		return nil, err
+	}
+	return append(entries, contextEntry(lbls, e)), nil
+}
+
+func contextEntry(lbls labels.Labels, e exportEntry) loghttp.ContextEntry {
+	entry := loghttp.ContextEntry{
+		Stream:    lbls.Map(),
+		Timestamp: strconv.FormatInt(e.Timestamp.UnixNano(), 10),
+		Line:      e.Line,
+	}
+	if len(e.StructuredMetadata) > 0 {
+		entry.StructuredMetadata = make(loghttp.LabelSet, len(e.StructuredMetadata))
+		for _, l := range e.StructuredMetadata {
+			entry.StructuredMetadata[l.Name] = l.Value
+		}
+	}
+	return entry
+}
@@ -0,0 +1,115 @@
+package queryrange
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/grafana/dskit/user"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/loghttp"
+	"github.com/grafana/loki/v3/pkg/logproto"
+)
+
+func contextLines(entries []loghttp.ContextEntry) []string {
+	out := make([]string, 0, len(entries))
+	for _, e := range entries {
+		out = append(out, e.Line)
+	}
+	return out
+}
+
+func TestContextHandler(t *testing.T) {
+	base := time.Unix(0, 0).Add(time.Hour)
+	at := func(s int) time.Time { return base.Add(time.Duration(s) * time.Second) }
+	streams := []logproto.Stream{
+		{Labels: `{app="a", pod="p1"}`, Entries: []logproto.Entry{
+			{Timestamp: at(0), Line: "a0"},
+			{Timestamp: at(1), Line: "a1"},
+			{Timestamp: at(2), Line: "a2"},
+			{Timestamp: at(2), Line: "a3"},
+			{Timestamp: at(3), Line: "a4"},
+			{Timestamp: at(5), Line: "a5"},
+		}},
+		{Labels: `{app="a", container="c", pod="p1"}`, Entries: []logproto.Entry{
+			{Timestamp: at(2), Line: "c2"},
+			{Timestamp: at(3), Line: "c3"},
+		}},
+		{Labels: `{app="b", pod="p1"}`, Entries: []logproto.Entry{
+			{Timestamp: at(1), Line: "b1"},
+			{Timestamp: at(2), Line: "b2"},
+			{Timestamp: at(4), Line: "b4"},
+		}},
+		{Labels: `{app="b", pod="p2"}`, Entries: []logproto.Entry{
+			{Timestamp: at(2), Line: "other"},
+		}},
+	}
+
+	h := NewContextHandler(exportTestHandler(streams), fakeLimits{maxEntriesLimitPerQuery: 1000}, log.NewNopLogger())
+	doContext := func(kv ...string) (*loghttp.ContextResponse, *httptest.ResponseRecorder) {
+		params := url.Values{
+			"query": {`{app="a"}`},
+			"ts":    {strconv.FormatInt(at(2).UnixNano(), 10)},
+		}
+		for i := 0; i < len(kv); i += 2 {
+			params.Set(kv[i], kv[i+1])
+		}
+		req := httptest.NewRequest(http.MethodGet, "/loki/api/v1/context?"+params.Encode(), nil)
+		req = req.WithContext(user.InjectOrgID(req.Context(), "fake"))
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != http.StatusOK {
+			return nil, rec
+		}
+		var resp loghttp.ContextResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		return &resp, rec
+	}
+
+	t.Run("stream of the entry", func(t *testing.T) {
+		resp, rec := doContext("line_hash", loghttp.FormatContextLineHash("a3"), "before", "3", "after", "2")
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		require.Equal(t, "a3", resp.Data.Entry.Line)
+		require.Equal(t, loghttp.LabelSet{"app": "a", "pod": "p1"}, resp.Data.Entry.Stream)
+		require.Equal(t, strconv.FormatInt(at(2).UnixNano(), 10), resp.Data.Entry.Timestamp)
+		require.Equal(t, []string{"a0", "a1", "a2"}, contextLines(resp.Data.Before))
+		require.Equal(t, []string{"a4", "a5"}, contextLines(resp.Data.After))
+	})
+
+	t.Run("related streams", func(t *testing.T) {
+		resp, rec := doContext("line_hash", loghttp.FormatContextLineHash("a2"), "before", "2", "after", "3", "related", "pod")
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		// entries sharing a timestamp are ordered by stream.
+		require.Equal(t, []string{"b1", "c2"}, contextLines(resp.Data.Before))
+		require.Equal(t, []string{"a3", "b2", "c3"}, contextLines(resp.Data.After))
+		require.Equal(t, loghttp.LabelSet{"app": "a", "container": "c", "pod": "p1"}, resp.Data.Before[1].Stream)
+	})
+
+	t.Run("no context", func(t *testing.T) {
+		resp, rec := doContext("line_hash", loghttp.FormatContextLineHash("a0"), "ts", strconv.FormatInt(at(0).UnixNano(), 10), "before", "0", "window", "1.5")
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		require.Empty(t, resp.Data.Before)
+		require.Equal(t, []string{"a1"}, contextLines(resp.Data.After))
+	})
+
+	t.Run("entry not found", func(t *testing.T) {
+		_, rec := doContext("line_hash", loghttp.FormatContextLineHash("unknown"))
+		require.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("related label missing", func(t *testing.T) {
+		_, rec := doContext("line_hash", loghttp.FormatContextLineHash("a3"), "related", "namespace")
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("invalid selector", func(t *testing.T) {
+		_, rec := doContext("line_hash", loghttp.FormatContextLineHash("a3"), "query", `{app="a"} |= "a"`)
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
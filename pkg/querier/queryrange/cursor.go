@@ -0,0 +1,159 @@
+package queryrange
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/grafana/dskit/httpgrpc"
+	"github.com/grafana/dskit/tenant"
+
+	"github.com/grafana/loki/v3/pkg/iter"
+	"github.com/grafana/loki/v3/pkg/loghttp"
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/logql/syntax"
+	"github.com/grafana/loki/v3/pkg/querier/queryrange/queryrangebase"
+	"github.com/grafana/loki/v3/pkg/util/validation"
+)
+
+// NewCursorMiddleware creates a new Middleware paginating log queries with cursors. The response of a log
+// query reaching its limit holds the cursor of the next page: the timestamp of its last entries and the
+// hashes of the ones sharing this timestamp. A request holding a cursor returns the entries following it,
+// so pages neither overlap nor miss entries sharing a timestamp.
+func NewCursorMiddleware(limits Limits) queryrangebase.Middleware {
+	return queryrangebase.MiddlewareFunc(func(next queryrangebase.Handler) queryrangebase.Handler {
+		return queryrangebase.HandlerFunc(func(ctx context.Context, r queryrangebase.Request) (queryrangebase.Response, error) {
+			req, ok := r.(*LokiRequest)
+			if !ok || req.Limit == 0 || req.Plan == nil {
+				return next.Do(ctx, r)
+			}
+			if _, ok := req.Plan.AST.(syntax.LogSelectorExpr); !ok {
+				return next.Do(ctx, r)
+			}
+			return cursorPage(ctx, next, limits, req)
+		})
+	})
+}
+
+func cursorPage(ctx context.Context, next queryrangebase.Handler, limits Limits, req *LokiRequest) (queryrangebase.Response, error) {
+	cursor := &loghttp.Cursor{}
+	if req.Cursor != "" {
+		var err error
+		if cursor, err = loghttp.DecodeCursor(req.Cursor); err != nil {
+			return nil, httpgrpc.Errorf(http.StatusBadRequest, err.Error())
+		}
+	}
+
+	sub := *req
+	sub.Cursor = ""
+	if req.Cursor != "" {
+		// The entries sharing the timestamp of the cursor are queried again, and skipped.
+		ts := time.Unix(0, cursor.Timestamp).UTC()
+		if req.Direction == logproto.FORWARD {
+			sub.StartTs = maxTime(req.StartTs, ts)
+		} else {
+			sub.EndTs = minTime(req.EndTs, ts.Add(time.Nanosecond))
+		}
+		if !sub.StartTs.Before(sub.EndTs) {
+			return NewEmptyResponse(&sub)
+		}
+
+		tenantIDs, err := tenant.TenantIDs(ctx)
+		if err != nil {
+			return nil, httpgrpc.Errorf(http.StatusBadRequest, err.Error())
+		}
+		sub.Limit += uint32(len(cursor.Seen))
+		maxEntries := validation.SmallestPositiveIntPerTenant(tenantIDs, func(id string) int {
+			return limits.MaxEntriesLimitPerQuery(ctx, id)
+		})
+		if maxEntries > 0 {
+			// A limit above the max entries limit is rejected downstream.
+			sub.Limit = min(sub.Limit, max(req.Limit, uint32(maxEntries)))
+		}
+	}
+
+	resp, err := next.Do(ctx, &sub)
+	if err != nil {
+		return nil, err
+	}
+	lokiResp, ok := resp.(*LokiResponse)
+	if !ok {
+		return resp, nil
+	}
+
+	var total uint32
+	for _, s := range lokiResp.Data.Result {
+		total += uint32(len(s.Entries))
+	}
+	if total < sub.Limit {
+		// The range holds no more entries: there is no next page.
+		if req.Cursor != "" {
+			lokiResp.Data.Result = skipReturned(lokiResp.Data.Result, req.Direction, cursor, req.Limit, nil)
+		}
+		return lokiResp, nil
+	}
+
+	nextCursor := &loghttp.Cursor{Timestamp: cursor.Timestamp, Seen: append([]uint64(nil), cursor.Seen...)}
+	var kept int
+	lokiResp.Data.Result = skipReturned(lokiResp.Data.Result, req.Direction, cursor, req.Limit, func(e logproto.Entry, labels string) {
+		nextCursor.Observe(e.Timestamp.UnixNano(), labels, e.Line)
+		kept++
+	})
+	if kept == 0 {
+		return nil, httpgrpc.Errorf(http.StatusBadRequest, "more than %d entries share the timestamp %d, increase the limit", sub.Limit, cursor.Timestamp)
+	}
+	lokiResp.Cursor = nextCursor.Encode()
+	return lokiResp, nil
+}
+
+// skipReturned returns the streams holding, in the order of the direction, up to limit entries not returned
+// before the cursor. The kept entries are observed, if observe is given.
+func skipReturned(streams []logproto.Stream, direction logproto.Direction, cursor *loghttp.Cursor, limit uint32, observe func(logproto.Entry, string)) []logproto.Stream {
+	it := iter.NewStreamsIterator(streams, direction)
+	defer it.Close()
+
+	var (
+		result  []logproto.Stream
+		indexes = map[string]int{}
+		kept    uint32
+	)
+	for kept < limit && it.Next() {
+		e, labels := it.Entry(), it.Labels()
+		if cursor.Returned(e.Timestamp.UnixNano(), labels, e.Line) {
+			continue
+		}
+		i, ok := indexes[labels]
+		if !ok {
+			i = len(result)
+			indexes[labels] = i
+			result = append(result, logproto.Stream{Labels: labels, Hash: it.StreamHash()})
+		}
+		result[i].Entries = append(result[i].Entries, e)
+		kept++
+		if observe != nil {
+			observe(e, labels)
+		}
+	}
+	return result
+}
+
+func encodeCursor(c *loghttp.Cursor) string {
+	if c == nil {
+		return ""
+	}
+	return c.Encode()
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
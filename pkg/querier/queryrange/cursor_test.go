@@ -0,0 +1,160 @@
+package queryrange
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/grafana/dskit/httpgrpc"
+	"github.com/grafana/dskit/user"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/iter"
+	"github.com/grafana/loki/v3/pkg/loghttp"
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/logql/syntax"
+	"github.com/grafana/loki/v3/pkg/querier/plan"
+)
+
+func cursorTestStreams() []logproto.Stream {
+	at := func(s int) time.Time { return time.Unix(int64(s), 0) }
+	return []logproto.Stream{
+		{Labels: `{app="a"}`, Entries: []logproto.Entry{
+			{Timestamp: at(1), Line: "a1"},
+			{Timestamp: at(2), Line: "a2"},
+			{Timestamp: at(2), Line: "a2bis"},
+			{Timestamp: at(4), Line: "a4"},
+		}},
+		{Labels: `{app="b"}`, Entries: []logproto.Entry{
+			{Timestamp: at(2), Line: "b2"},
+			{Timestamp: at(3), Line: "b3"},
+			{Timestamp: at(5), Line: "b5"},
+		}},
+	}
+}
+
+func cursorTestRequest(direction logproto.Direction, limit uint32, cursor string) *LokiRequest {
+	expr, _ := syntax.ParseLogSelector(`{app=~"a|b"}`, true)
+	return &LokiRequest{
+		Query:     `{app=~"a|b"}`,
+		Limit:     limit,
+		Direction: direction,
+		StartTs:   time.Unix(0, 0),
+		EndTs:     time.Unix(10, 0),
+		Path:      "/loki/api/v1/query_range",
+		Plan:      &plan.QueryPlan{AST: expr},
+		Cursor:    cursor,
+	}
+}
+
+func responseLines(resp *LokiResponse) []string {
+	it := iter.NewStreamsIterator(resp.Data.Result, resp.Direction)
+	defer it.Close()
+	var lines []string
+	for it.Next() {
+		lines = append(lines, it.Entry().Line)
+	}
+	return lines
+}
+
+func TestCursorMiddleware(t *testing.T) {
+	ctx := user.InjectOrgID(context.Background(), "fake")
+	handler := NewCursorMiddleware(fakeLimits{maxEntriesLimitPerQuery: 1000}).Wrap(exportTestHandler(cursorTestStreams()))
+
+	for _, tc := range []struct {
+		direction logproto.Direction
+		expected  [][]string
+	}{
+		{
+			direction: logproto.FORWARD,
+			expected:  [][]string{{"a1", "a2"}, {"a2bis", "b2"}, {"b3", "a4"}, {"b5"}},
+		},
+		{
+			direction: logproto.BACKWARD,
+			expected:  [][]string{{"b5", "a4"}, {"b3", "a2"}, {"a2bis", "b2"}, {"a1"}},
+		},
+	} {
+		t.Run(tc.direction.String(), func(t *testing.T) {
+			var cursor string
+			for i, expected := range tc.expected {
+				resp, err := handler.Do(ctx, cursorTestRequest(tc.direction, 2, cursor))
+				require.NoError(t, err)
+				lokiResp := resp.(*LokiResponse)
+				require.Equal(t, expected, responseLines(lokiResp), "page %d", i)
+
+				cursor = lokiResp.Cursor
+				if i < len(tc.expected)-1 {
+					require.NotEmpty(t, cursor, "page %d", i)
+				}
+			}
+			// The last page doesn't reach the limit.
+			require.Empty(t, cursor)
+		})
+	}
+}
+
+func TestCursorMiddleware_Errors(t *testing.T) {
+	ctx := user.InjectOrgID(context.Background(), "fake")
+
+	t.Run("invalid cursor", func(t *testing.T) {
+		handler := NewCursorMiddleware(fakeLimits{}).Wrap(exportTestHandler(cursorTestStreams()))
+		_, err := handler.Do(ctx, cursorTestRequest(logproto.FORWARD, 2, "not a cursor"))
+		resp, ok := httpgrpc.HTTPResponseFromError(err)
+		require.True(t, ok)
+		require.Equal(t, int32(http.StatusBadRequest), resp.Code)
+	})
+
+	t.Run("too many entries sharing a timestamp", func(t *testing.T) {
+		handler := NewCursorMiddleware(fakeLimits{maxEntriesLimitPerQuery: 2}).Wrap(exportTestHandler(cursorTestStreams()))
+		c := &loghttp.Cursor{}
+		c.Observe(time.Unix(2, 0).UnixNano(), `{app="a"}`, "a2")
+		c.Observe(time.Unix(2, 0).UnixNano(), `{app="a"}`, "a2bis")
+		_, err := handler.Do(ctx, cursorTestRequest(logproto.FORWARD, 2, c.Encode()))
+		resp, ok := httpgrpc.HTTPResponseFromError(err)
+		require.True(t, ok)
+		require.Equal(t, int32(http.StatusBadRequest), resp.Code)
+	})
+}
+
+func TestCursorCodec(t *testing.T) {
+	c := &loghttp.Cursor{Timestamp: time.Unix(2, 0).UnixNano()}
+	c.Observe(c.Timestamp, `{app="a"}`, "a2")
+
+	params := url.Values{
+		"query":  {`{app="a"}`},
+		"start":  {"0"},
+		"end":    {strconv.FormatInt(time.Unix(10, 0).UnixNano(), 10)},
+		"cursor": {c.Encode()},
+	}
+	req, err := DefaultCodec.DecodeRequest(context.Background(), httptest.NewRequest(http.MethodGet, "/loki/api/v1/query_range?"+params.Encode(), nil), nil)
+	require.NoError(t, err)
+	require.Equal(t, c.Encode(), req.(*LokiRequest).Cursor)
+
+	params.Set("cursor", "not a cursor")
+	_, err = DefaultCodec.DecodeRequest(context.Background(), httptest.NewRequest(http.MethodGet, "/loki/api/v1/query_range?"+params.Encode(), nil), nil)
+	require.Error(t, err)
+
+	u := &url.URL{Path: "/loki/api/v1/query_range"}
+	httpResp, err := DefaultCodec.EncodeResponse(context.Background(), &http.Request{RequestURI: u.String(), URL: u}, &LokiResponse{
+		Status:    loghttp.QueryStatusSuccess,
+		Direction: logproto.FORWARD,
+		Version:   uint32(loghttp.VersionV1),
+		Data: LokiData{
+			ResultType: loghttp.ResultTypeStream,
+			Result:     cursorTestStreams()[:1],
+		},
+		Cursor: c.Encode(),
+	})
+	require.NoError(t, err)
+	body, err := io.ReadAll(httpResp.Body)
+	require.NoError(t, err)
+
+	var resp loghttp.QueryResponse
+	require.NoError(t, resp.UnmarshalJSON(body))
+	require.Equal(t, c.Encode(), resp.Data.Cursor)
+}
@@ -0,0 +1,307 @@
+package queryrange
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/grafana/dskit/httpgrpc"
+	"github.com/grafana/dskit/tenant"
+
+	"github.com/grafana/loki/v3/pkg/loghttp"
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/logql/syntax"
+	"github.com/grafana/loki/v3/pkg/querier/plan"
+	"github.com/grafana/loki/v3/pkg/querier/queryrange/queryrangebase"
+	serverutil "github.com/grafana/loki/v3/pkg/util/server"
+	"github.com/grafana/loki/v3/pkg/util/validation"
+)
+
+const exportContentType = "application/x-ndjson"
+
+// exportHandler streams the entries of a log query as newline delimited JSON. The range is
+// queried in pages of entries, in time order, through the query frontend middlewares, and each
+// page is written to the client as soon as it is received. A cursor is written after each page
+// so that an interrupted export can be resumed.
+type exportHandler struct {
+	next   queryrangebase.Handler
+	limits Limits
+	active *ActiveQueries
+	logger log.Logger
+}
+
+// NewExportHandler returns the handler of the export API, querying the pages with the given handler.
+// Exports are registered as a single query in the active queries, if given.
+func NewExportHandler(next queryrangebase.Handler, limits Limits, active *ActiveQueries, logger log.Logger) http.Handler {
+	return &exportHandler{
+		next:   next,
+		limits: limits,
+		active: active,
+		logger: logger,
+	}
+}
+
+func (h *exportHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		serverutil.WriteError(httpgrpc.Errorf(http.StatusBadRequest, err.Error()), w)
+		return
+	}
+	req, err := loghttp.ParseExportQuery(r)
+	if err != nil {
+		serverutil.WriteError(httpgrpc.Errorf(http.StatusBadRequest, err.Error()), w)
+		return
+	}
+	expr, err := syntax.ParseLogSelector(req.Query, true)
+	if err != nil {
+		serverutil.WriteError(httpgrpc.Errorf(http.StatusBadRequest, err.Error()), w)
+		return
+	}
+	tenantIDs, err := tenant.TenantIDs(r.Context())
+	if err != nil {
+		serverutil.WriteError(httpgrpc.Errorf(http.StatusBadRequest, err.Error()), w)
+		return
+	}
+
+	ctx := r.Context()
+	if h.active != nil {
+		var q *activeQuery
+		ctx, q = h.active.start(ctx, tenantIDs, "export", req.Query)
+		defer h.active.finish(q)
+	}
+
+	e := &export{
+		exportHandler: h,
+		req:           req,
+		expr:          expr,
+		w:             w,
+		from:          req.Start,
+		through:       req.End,
+		cursor:        &loghttp.Cursor{},
+		maxEntries: validation.SmallestPositiveIntPerTenant(tenantIDs, func(id string) int {
+			return h.limits.MaxEntriesLimitPerQuery(ctx, id)
+		}),
+		maxLength: validation.SmallestPositiveNonZeroDurationPerTenant(tenantIDs, func(id string) time.Duration {
+			return h.limits.MaxQueryLength(ctx, id)
+		}),
+		timeout: validation.MaxDurationPerTenant(tenantIDs, func(id string) time.Duration {
+			return h.limits.QueryTimeout(ctx, id)
+		}),
+	}
+	if req.Cursor != nil {
+		e.resume(req.Cursor)
+	}
+
+	if err := e.run(ctx); err != nil {
+		if errors.Is(context.Cause(ctx), errQueryCancelled) {
+			err = httpgrpc.Errorf(serverutil.StatusClientClosedRequest, errQueryCancelled.Error())
+		}
+		if !e.started {
+			serverutil.WriteError(err, w)
+			return
+		}
+		level.Warn(h.logger).Log("msg", "export interrupted", "query", req.Query, "err", err)
+		_, cerr := serverutil.ClientHTTPStatusAndError(err)
+		_ = e.write(loghttp.ExportLine{Error: cerr.Error()})
+	}
+}
+
+type export struct {
+	*exportHandler
+
+	req  *loghttp.ExportQuery
+	expr syntax.LogSelectorExpr
+	w    http.ResponseWriter
+
+	// the range remaining to export, end excluded.
+	from, through time.Time
+	cursor        *loghttp.Cursor
+	exported      uint32
+	started       bool
+
+	maxEntries int
+	maxLength  time.Duration
+	timeout    time.Duration
+}
+
+// resume restricts the range to the entries following the cursor.
+func (e *export) resume(cursor *loghttp.Cursor) {
+	ts := time.Unix(0, cursor.Timestamp)
+	if e.req.Direction == logproto.FORWARD {
+		if ts.After(e.from) {
+			e.from = ts
+		}
+	} else if end := ts.Add(time.Nanosecond); end.Before(e.through) {
+		e.through = end
+	}
+	e.cursor = cursor
+}
+
+func (e *export) run(ctx context.Context) error {
+	for e.from.Before(e.through) && (e.req.Limit == 0 || e.exported < e.req.Limit) {
+		if err := e.page(ctx); err != nil {
+			return err
+		}
+	}
+	return e.write(loghttp.ExportLine{Done: true})
+}
+
+// page exports the next page of entries, and moves the remaining range past them.
+func (e *export) page(ctx context.Context) error {
+	start, end := e.from, e.through
+	if e.maxLength > 0 {
+		if e.req.Direction == logproto.FORWARD && end.Sub(start) > e.maxLength {
+			end = start.Add(e.maxLength)
+		} else if e.req.Direction == logproto.BACKWARD && end.Sub(start) > e.maxLength {
+			start = end.Add(-e.maxLength)
+		}
+	}
+
+	limit := e.req.PageSize
+	if e.req.Limit > 0 {
+		limit = min(limit, e.req.Limit-e.exported)
+	}
+	// the entries already exported sharing the timestamp of the cursor are returned again and skipped.
+	limit += uint32(len(e.cursor.Seen))
+	if e.maxEntries > 0 {
+		limit = min(limit, uint32(e.maxEntries))
+	}
+
+	if e.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, e.timeout)
+		defer cancel()
+		_ = http.NewResponseController(e.w).SetWriteDeadline(time.Now().Add(e.timeout))
+	}
+
+	resp, err := e.next.Do(ctx, &LokiRequest{
+		Query:     e.req.Query,
+		Limit:     limit,
+		Direction: e.req.Direction,
+		StartTs:   start.UTC(),
+		EndTs:     end.UTC(),
+		Path:      "/loki/api/v1/query_range",
+		Plan:      &plan.QueryPlan{AST: e.expr},
+	})
+	if err != nil {
+		return err
+	}
+	lokiResp, ok := resp.(*LokiResponse)
+	if !ok {
+		return fmt.Errorf("unexpected response type %T", resp)
+	}
+
+	entries := sortedEntries(lokiResp.Data.Result, e.req.Direction)
+	written := 0
+	for _, entry := range entries {
+		ts := entry.Timestamp.UnixNano()
+		if e.cursor.Returned(ts, entry.labels, entry.Line) {
+			continue
+		}
+		if err := e.writeEntry(entry); err != nil {
+			return err
+		}
+		e.cursor.Observe(ts, entry.labels, entry.Line)
+		e.exported++
+		written++
+		if e.req.Limit > 0 && e.exported >= e.req.Limit {
+			break
+		}
+	}
+
+	switch {
+	case uint32(len(entries)) < limit:
+		// the window holds no more entries.
+		if e.req.Direction == logproto.FORWARD {
+			e.from = end
+			e.cursor = &loghttp.Cursor{Timestamp: end.UnixNano()}
+		} else {
+			e.through = start
+			e.cursor = &loghttp.Cursor{Timestamp: start.UnixNano() - 1}
+		}
+	case written == 0:
+		return httpgrpc.Errorf(http.StatusBadRequest, "more than %d entries share the timestamp %d, increase the page size", limit, e.cursor.Timestamp)
+	default:
+		// resume from the last entry, as the following ones may share its timestamp.
+		ts := time.Unix(0, e.cursor.Timestamp)
+		if e.req.Direction == logproto.FORWARD {
+			e.from = ts
+		} else {
+			e.through = ts.Add(time.Nanosecond)
+		}
+	}
+
+	return e.write(loghttp.ExportLine{Cursor: e.cursor.Encode()})
+}
+
+func (e *export) writeEntry(entry exportEntry) error {
+	lbls, err := syntax.ParseLabels(entry.labels)
+	if err != nil {
+		return err
+	}
+	line := loghttp.ExportLine{
+		Stream:    lbls.Map(),
+		Timestamp: strconv.FormatInt(entry.Timestamp.UnixNano(), 10),
+		Line:      entry.Line,
+	}
+	if len(entry.StructuredMetadata) > 0 {
+		line.StructuredMetadata = make(loghttp.LabelSet, len(entry.StructuredMetadata))
+		for _, l := range entry.StructuredMetadata {
+			line.StructuredMetadata[l.Name] = l.Value
+		}
+	}
+	return e.write(line)
+}
+
+func (e *export) write(line loghttp.ExportLine) error {
+	if !e.started {
+		e.w.Header().Set("Content-Type", exportContentType)
+		e.w.WriteHeader(http.StatusOK)
+		e.started = true
+	}
+	b, err := json.Marshal(line)
+	if err != nil {
+		return err
+	}
+	if _, err := e.w.Write(append(b, '\n')); err != nil {
+		return err
+	}
+	if line.IsEntry() {
+		return nil
+	}
+	if f, ok := e.w.(http.Flusher); ok {
+		f.Flush()
+	}
+	return nil
+}
+
+type exportEntry struct {
+	logproto.Entry
+	labels string
+}
+
+// sortedEntries returns the entries of the streams in the order of the direction.
+func sortedEntries(streams []logproto.Stream, direction logproto.Direction) []exportEntry {
+	var entries []exportEntry
+	for _, s := range streams {
+		for _, entry := range s.Entries {
+			entries = append(entries, exportEntry{Entry: entry, labels: s.Labels})
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		if !entries[i].Timestamp.Equal(entries[j].Timestamp) {
+			if direction == logproto.FORWARD {
+				return entries[i].Timestamp.Before(entries[j].Timestamp)
+			}
+			return entries[i].Timestamp.After(entries[j].Timestamp)
+		}
+		return entries[i].labels < entries[j].labels
+	})
+	return entries
+}
@@ -0,0 +1,176 @@
+package queryrange
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/grafana/dskit/user"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/loghttp"
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/logql/syntax"
+	"github.com/grafana/loki/v3/pkg/querier/queryrange/queryrangebase"
+)
+
+// exportTestHandler answers log queries from a fixed set of streams, returning the first entries
+// of the range matching the selector in the order of the query direction.
+func exportTestHandler(streams []logproto.Stream) queryrangebase.Handler {
+	return queryrangebase.HandlerFunc(func(_ context.Context, r queryrangebase.Request) (queryrangebase.Response, error) {
+		req := r.(*LokiRequest)
+		matchers := req.Plan.AST.(syntax.LogSelectorExpr).Matchers()
+		var entries []exportEntry
+		for _, e := range sortedEntries(streams, req.Direction) {
+			lbls, err := syntax.ParseLabels(e.labels)
+			if err != nil {
+				return nil, err
+			}
+			matches := true
+			for _, m := range matchers {
+				matches = matches && m.Matches(lbls.Get(m.Name))
+			}
+			if matches && !e.Timestamp.Before(req.StartTs) && e.Timestamp.Before(req.EndTs) && uint32(len(entries)) < req.Limit {
+				entries = append(entries, e)
+			}
+		}
+		byStream := map[string]*logproto.Stream{}
+		var result []logproto.Stream
+		for _, e := range entries {
+			if _, ok := byStream[e.labels]; !ok {
+				result = append(result, logproto.Stream{Labels: e.labels})
+				byStream[e.labels] = &result[len(result)-1]
+			}
+		}
+		for _, e := range entries {
+			for i := range result {
+				if result[i].Labels == e.labels {
+					result[i].Entries = append(result[i].Entries, e.Entry)
+				}
+			}
+		}
+		return &LokiResponse{Status: loghttp.QueryStatusSuccess, Direction: req.Direction, Data: LokiData{ResultType: loghttp.ResultTypeStream, Result: result}}, nil
+	})
+}
+
+func doExport(t *testing.T, h http.Handler, params url.Values) ([]loghttp.ExportLine, *httptest.ResponseRecorder) {
+	req := httptest.NewRequest(http.MethodGet, "/loki/api/v1/export?"+params.Encode(), nil)
+	req = req.WithContext(user.InjectOrgID(req.Context(), "fake"))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	var lines []loghttp.ExportLine
+	if rec.Code != http.StatusOK {
+		return nil, rec
+	}
+	scanner := bufio.NewScanner(rec.Body)
+	for scanner.Scan() {
+		var line loghttp.ExportLine
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &line))
+		lines = append(lines, line)
+	}
+	return lines, rec
+}
+
+func exportedLines(lines []loghttp.ExportLine) []string {
+	var out []string
+	for _, l := range lines {
+		if l.IsEntry() {
+			out = append(out, l.Line)
+		}
+	}
+	return out
+}
+
+func TestExportHandler(t *testing.T) {
+	base := time.Unix(0, 0).Add(time.Hour)
+	streams := []logproto.Stream{
+		{Labels: `{app="a"}`, Entries: []logproto.Entry{
+			{Timestamp: base, Line: "1"},
+			{Timestamp: base.Add(time.Second), Line: "3"},
+			{Timestamp: base.Add(time.Second), Line: "4"},
+			{Timestamp: base.Add(10 * time.Hour), Line: "7"},
+		}},
+		{Labels: `{app="b"}`, Entries: []logproto.Entry{
+			{Timestamp: base.Add(time.Millisecond), Line: "2"},
+			{Timestamp: base.Add(time.Second), Line: "5"},
+			{Timestamp: base.Add(2 * time.Second), Line: "6"},
+		}},
+	}
+	all := []string{"1", "2", "3", "4", "5", "6", "7"}
+	params := func(kv ...string) url.Values {
+		v := url.Values{
+			"query":     {`{app=~"a|b"}`},
+			"start":     {strconv.FormatInt(base.UnixNano(), 10)},
+			"end":       {strconv.FormatInt(base.Add(11*time.Hour).UnixNano(), 10)},
+			"page_size": {"2"},
+		}
+		for i := 0; i < len(kv); i += 2 {
+			v.Set(kv[i], kv[i+1])
+		}
+		return v
+	}
+
+	h := NewExportHandler(exportTestHandler(streams), fakeLimits{maxEntriesLimitPerQuery: 1000}, nil, log.NewNopLogger())
+
+	t.Run("forward", func(t *testing.T) {
+		lines, rec := doExport(t, h, params())
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, exportContentType, rec.Header().Get("Content-Type"))
+		require.Equal(t, all, exportedLines(lines))
+		require.True(t, lines[len(lines)-1].Done)
+		require.Equal(t, loghttp.LabelSet{"app": "b"}, lines[1].Stream)
+	})
+
+	t.Run("backward", func(t *testing.T) {
+		lines, _ := doExport(t, h, params("direction", "backward"))
+		// entries sharing a timestamp are ordered by stream.
+		require.Equal(t, []string{"7", "6", "3", "4", "5", "2", "1"}, exportedLines(lines))
+	})
+
+	t.Run("limit", func(t *testing.T) {
+		lines, _ := doExport(t, h, params("limit", "4"))
+		require.Equal(t, all[:4], exportedLines(lines))
+		require.True(t, lines[len(lines)-1].Done)
+	})
+
+	t.Run("resume from cursor", func(t *testing.T) {
+		lines, _ := doExport(t, h, params())
+		var cursor string
+		for _, l := range lines {
+			if l.Cursor != "" {
+				cursor = l.Cursor
+			}
+			if l.Line == "3" {
+				break
+			}
+		}
+		resumed, _ := doExport(t, h, params("cursor", cursor))
+		require.Equal(t, all[2:], exportedLines(resumed))
+	})
+
+	t.Run("entries sharing a timestamp over several pages", func(t *testing.T) {
+		lines, _ := doExport(t, h, params("page_size", "1"))
+		require.Equal(t, all, exportedLines(lines))
+	})
+
+	t.Run("more entries sharing a timestamp than the max entries limit", func(t *testing.T) {
+		h := NewExportHandler(exportTestHandler(streams), fakeLimits{maxEntriesLimitPerQuery: 2}, nil, log.NewNopLogger())
+		lines, rec := doExport(t, h, params("page_size", "1"))
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, all[:4], exportedLines(lines))
+		require.Contains(t, lines[len(lines)-1].Error, "increase the page size")
+	})
+
+	t.Run("invalid query", func(t *testing.T) {
+		_, rec := doExport(t, h, params("query", `count_over_time({app="a"}[1m])`))
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
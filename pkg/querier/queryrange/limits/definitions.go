@@ -33,6 +33,12 @@ type Limits interface {
 	RequiredNumberLabels(context.Context, string) int
 	MaxQueryBytesRead(context.Context, string) int
 	MaxQuerierBytesRead(context.Context, string) int
+	// QueryBytesBudget returns the number of bytes the queries of a tenant can scan within the query budget window.
+	QueryBytesBudget(string) int
+	// QueryBudgetWindow returns the sliding time window in which the query bytes budget is tracked.
+	QueryBudgetWindow(string) time.Duration
+	// QueryBudgetMaxWait returns the maximum time a query waits for budget to be freed up.
+	QueryBudgetMaxWait(string) time.Duration
 	MaxStatsCacheFreshness(context.Context, string) time.Duration
 	MaxMetadataCacheFreshness(context.Context, string) time.Duration
 	VolumeEnabled(string) bool
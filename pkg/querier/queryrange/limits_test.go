@@ -152,7 +152,7 @@ func Test_seriesLimiter(t *testing.T) {
 	l := WithSplitByLimits(fakeLimits{maxSeries: 1, maxQueryParallelism: 2}, time.Hour)
 	tpw, stopper, err := NewMiddleware(cfg, testEngineOpts, nil, util_log.Logger, l, config.SchemaConfig{
 		Configs: testSchemas,
-	}, nil, false, nil, constants.Loki)
+	}, nil, false, nil, constants.Loki, nil, nil)
 	if stopper != nil {
 		defer stopper.Stop()
 	}
@@ -325,7 +325,7 @@ func Test_MaxQueryLookBack(t *testing.T) {
 		maxQueryParallelism: 1,
 	}, config.SchemaConfig{
 		Configs: testSchemas,
-	}, nil, false, nil, constants.Loki)
+	}, nil, false, nil, constants.Loki, nil, nil)
 	if stopper != nil {
 		defer stopper.Stop()
 	}
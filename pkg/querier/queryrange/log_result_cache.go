@@ -2,6 +2,7 @@ package queryrange
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"net/http"
 	"time"
@@ -9,6 +10,8 @@ import (
 	"github.com/go-kit/log"
 	"github.com/go-kit/log/level"
 	"github.com/gogo/protobuf/proto"
+	"github.com/gogo/protobuf/types"
+	"github.com/grafana/dskit/flagext"
 	"github.com/grafana/dskit/httpgrpc"
 	"github.com/grafana/dskit/tenant"
 	"github.com/opentracing/opentracing-go"
@@ -22,14 +25,17 @@ import (
 	"github.com/grafana/loki/v3/pkg/logqlmodel/stats"
 	"github.com/grafana/loki/v3/pkg/querier/queryrange/queryrangebase"
 	"github.com/grafana/loki/v3/pkg/storage/chunk/cache"
+	"github.com/grafana/loki/v3/pkg/storage/chunk/cache/resultscache"
 	"github.com/grafana/loki/v3/pkg/util/constants"
+	"github.com/grafana/loki/v3/pkg/util/math"
 	"github.com/grafana/loki/v3/pkg/util/validation"
 )
 
 // LogResultCacheMetrics is the metrics wrapper used in log result cache.
 type LogResultCacheMetrics struct {
-	CacheHit  prometheus.Counter
-	CacheMiss prometheus.Counter
+	CacheHit      prometheus.Counter
+	CacheMiss     prometheus.Counter
+	CacheTooLarge prometheus.Counter
 }
 
 // NewLogResultCacheMetrics creates metrics to be used in log result cache.
@@ -43,16 +49,40 @@ func NewLogResultCacheMetrics(registerer prometheus.Registerer) *LogResultCacheM
 			Namespace: constants.Loki,
 			Name:      "query_frontend_log_result_cache_miss_total",
 		}),
+		CacheTooLarge: promauto.With(registerer).NewCounter(prometheus.CounterOpts{
+			Namespace: constants.Loki,
+			Name:      "query_frontend_log_result_cache_entry_too_large_total",
+			Help:      "Total number of log results not stored in the cache because they exceeded the maximum cache entry size.",
+		}),
 	}
 }
 
+// LogResultCacheConfig configures which log query results are kept by the log result cache.
+type LogResultCacheConfig struct {
+	CacheNonEmptyResults bool          `yaml:"cache_non_empty_results"`
+	MaxEntrySize         flagext.Bytes `yaml:"max_entry_size"`
+}
+
+// RegisterFlags registers flags.
+func (cfg *LogResultCacheConfig) RegisterFlags(f *flag.FlagSet) {
+	cfg.RegisterFlagsWithPrefix(f, "frontend.log-results-cache.")
+}
+
+// RegisterFlagsWithPrefix registers flags with the given prefix.
+func (cfg *LogResultCacheConfig) RegisterFlagsWithPrefix(f *flag.FlagSet, prefix string) {
+	f.BoolVar(&cfg.CacheNonEmptyResults, prefix+"cache-non-empty-results", false, "Cache log query results containing log lines. When disabled, only empty log query results are cached.")
+	cfg.MaxEntrySize = 1 << 20
+	f.Var(&cfg.MaxEntrySize, prefix+"max-entry-size", "Maximum size of a single log result cache entry. Results that are larger are not stored in the cache. 0 means unlimited. Only used when caching non-empty results.")
+}
+
 // NewLogResultCache creates a new log result cache middleware.
-// Currently it only caches empty filter queries, this is because those are usually easily and freely cacheable.
-// Log hits are difficult to handle because of the limit query parameter and the size of the response.
-// In the future it could be extended to cache non-empty query results.
+// By default it only caches empty filter queries, this is because those are usually easily and freely cacheable.
+// When cfg.CacheNonEmptyResults is set, log lines are cached as well. Results that were cut by the limit query
+// parameter are trimmed to the time range they fully cover before being stored, so that cached extents can serve
+// requests with any limit and direction.
 // see https://docs.google.com/document/d/1_mACOpxdWZ5K0cIedaja5gzMbv-m0lUVazqZd2O4mEU/edit
 func NewLogResultCache(logger log.Logger, limits Limits, cache cache.Cache, shouldCache queryrangebase.ShouldCacheFn,
-	transformer UserIDTransformer, metrics *LogResultCacheMetrics) queryrangebase.Middleware {
+	transformer UserIDTransformer, metrics *LogResultCacheMetrics, cfg LogResultCacheConfig) queryrangebase.Middleware {
 	if metrics == nil {
 		metrics = NewLogResultCacheMetrics(nil)
 	}
@@ -65,6 +95,7 @@ func NewLogResultCache(logger log.Logger, limits Limits, cache cache.Cache, shou
 			shouldCache: shouldCache,
 			transformer: transformer,
 			metrics:     metrics,
+			cfg:         cfg,
 		}
 	})
 }
@@ -75,6 +106,7 @@ type logResultCache struct {
 	cache       cache.Cache
 	shouldCache queryrangebase.ShouldCacheFn
 	transformer UserIDTransformer
+	cfg         LogResultCacheConfig
 
 	metrics *LogResultCacheMetrics
 	logger  log.Logger
@@ -123,6 +155,12 @@ func (l *logResultCache) Do(ctx context.Context, req queryrangebase.Request) (qu
 		}
 	}
 
+	if l.cfg.CacheNonEmptyResults {
+		// Entries are stored in a different format, so use a different key to avoid decoding entries written by the empty-only mode.
+		cacheKey := fmt.Sprintf("log_entries:%s:%s:%d:%d", tenant.JoinTenantIDs(transformedTenantIDs), req.GetQuery(), interval.Nanoseconds(), alignedStart.UnixNano()/(interval.Nanoseconds()))
+		return l.doWithEntries(ctx, cacheKey, lokiReq)
+	}
+
 	cacheKey := fmt.Sprintf("log:%s:%s:%d:%d", tenant.JoinTenantIDs(transformedTenantIDs), req.GetQuery(), interval.Nanoseconds(), alignedStart.UnixNano()/(interval.Nanoseconds()))
 
 	_, buff, _, err := l.cache.Fetch(ctx, []string{cache.HashKey(cacheKey)})
@@ -296,6 +334,321 @@ func (l *logResultCache) handleHit(ctx context.Context, cacheKey string, cachedR
 	return result, nil
 }
 
+// logExtent is a cached time range [start, end), in nanoseconds, together with all the log lines it contains.
+// Entries of the cached response are always kept in FORWARD order.
+type logExtent struct {
+	start, end int64
+	resp       *LokiResponse
+}
+
+func (l *logResultCache) doWithEntries(ctx context.Context, cacheKey string, req *LokiRequest) (queryrangebase.Response, error) {
+	cached, ok := l.getExtent(ctx, cacheKey)
+	if !ok {
+		l.metrics.CacheMiss.Inc()
+		level.Debug(l.logger).Log("msg", "cache miss", "key", cacheKey)
+		resp, err := l.nextLokiResponse(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		if extent, ok := trimLogExtent(req, resp); ok {
+			l.storeExtent(ctx, cacheKey, extent)
+		}
+		return resp, nil
+	}
+	l.metrics.CacheHit.Inc()
+
+	start, end := req.GetStartTs().UnixNano(), req.GetEndTs().UnixNano()
+
+	// if the query does not overlap the cached extent, do not try to fill the gap since it requires extending the queries beyond what is requested in the query.
+	// Instead, replace the cached extent if the new one covers a larger time range.
+	if end <= cached.start || start >= cached.end {
+		resp, err := l.nextLokiResponse(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		if extent, ok := trimLogExtent(req, resp); ok && extent.end-extent.start > cached.end-cached.start {
+			l.storeExtent(ctx, cacheKey, extent)
+		}
+		return resp, nil
+	}
+
+	cachedResp := &LokiResponse{
+		Status:    loghttp.QueryStatusSuccess,
+		Direction: req.Direction,
+		Limit:     req.Limit,
+		Version:   uint32(loghttp.GetVersion(req.Path)),
+		Data: LokiData{
+			ResultType: loghttp.ResultTypeStream,
+			Result:     filterStreams(cached.resp.Data.Result, math.Max64(start, cached.start), math.Min64(end, cached.end), logproto.FORWARD, req.Direction),
+		},
+	}
+
+	// we could be missing data at the start and the end.
+	var startRequest, endRequest *LokiRequest
+	if start < cached.start {
+		startRequest = req.WithStartEnd(req.GetStartTs(), time.Unix(0, cached.start)).(*LokiRequest)
+	}
+	if end > cached.end {
+		endRequest = req.WithStartEnd(time.Unix(0, cached.end), req.GetEndTs()).(*LokiRequest)
+	}
+
+	// The cached lines may already fill the limit, in which case the missing data past them in the direction
+	// of the query would be discarded anyway.
+	if countEntries(cachedResp) >= int(req.Limit) {
+		switch {
+		case req.Direction == logproto.FORWARD && startRequest == nil:
+			endRequest = nil
+		case req.Direction == logproto.BACKWARD && endRequest == nil:
+			startRequest = nil
+		}
+	}
+
+	var startResp, endResp *LokiResponse
+	g, gCtx := errgroup.WithContext(ctx)
+	if startRequest != nil {
+		g.Go(func() (err error) {
+			startResp, err = l.nextLokiResponse(gCtx, startRequest)
+			return err
+		})
+	}
+	if endRequest != nil {
+		g.Go(func() (err error) {
+			endResp, err = l.nextLokiResponse(gCtx, endRequest)
+			return err
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	// responses are merged in the order of the query direction so the limit is applied to the right lines.
+	ordered := []*LokiResponse{startResp, cachedResp, endResp}
+	if req.Direction == logproto.BACKWARD {
+		ordered = []*LokiResponse{endResp, cachedResp, startResp}
+	}
+	responses := make([]queryrangebase.Response, 0, len(ordered))
+	for _, resp := range ordered {
+		if resp == nil {
+			continue
+		}
+		if resp.Status != loghttp.QueryStatusSuccess {
+			return resp, nil
+		}
+		responses = append(responses, resp)
+	}
+	result := mergeLokiResponse(responses...)
+
+	// extend the cached extent with the fetched data when it is adjacent to it.
+	updated := cached
+	if startResp != nil {
+		if extent, ok := trimLogExtent(startRequest, startResp); ok && extent.end == updated.start {
+			updated = mergeLogExtents(extent, updated)
+		}
+	}
+	if endResp != nil {
+		if extent, ok := trimLogExtent(endRequest, endResp); ok && extent.start == updated.end {
+			updated = mergeLogExtents(updated, extent)
+		}
+	}
+	if updated.start != cached.start || updated.end != cached.end {
+		l.storeExtent(ctx, cacheKey, updated)
+	}
+
+	return result, nil
+}
+
+func (l *logResultCache) nextLokiResponse(ctx context.Context, req *LokiRequest) (*LokiResponse, error) {
+	resp, err := l.next.Do(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	lokiRes, ok := resp.(*LokiResponse)
+	if !ok {
+		return nil, fmt.Errorf("unexpected response type %T", resp)
+	}
+	return lokiRes, nil
+}
+
+func (l *logResultCache) getExtent(ctx context.Context, cacheKey string) (logExtent, bool) {
+	_, buff, _, err := l.cache.Fetch(ctx, []string{cache.HashKey(cacheKey)})
+	if err != nil {
+		level.Warn(l.logger).Log("msg", "error fetching cache", "err", err, "cacheKey", cacheKey)
+		return logExtent{}, false
+	}
+	// we expect only one key to be found or missing.
+	if len(buff) != 1 {
+		if len(buff) > 1 {
+			level.Warn(l.logger).Log("msg", "unexpected length of cache return values", "buff", len(buff))
+		}
+		return logExtent{}, false
+	}
+
+	var cached resultscache.CachedResponse
+	if err := proto.Unmarshal(buff[0], &cached); err != nil {
+		level.Warn(l.logger).Log("msg", "error unmarshalling cached response", "err", err)
+		return logExtent{}, false
+	}
+	// the cache key is hashed, so check that the entry was written for this key.
+	if cached.Key != cacheKey || len(cached.Extents) != 1 {
+		return logExtent{}, false
+	}
+
+	var resp LokiResponse
+	if err := types.UnmarshalAny(cached.Extents[0].Response, &resp); err != nil {
+		level.Warn(l.logger).Log("msg", "error unmarshalling cached log response", "err", err)
+		return logExtent{}, false
+	}
+	return logExtent{start: cached.Extents[0].Start, end: cached.Extents[0].End, resp: &resp}, true
+}
+
+func (l *logResultCache) storeExtent(ctx context.Context, cacheKey string, extent logExtent) {
+	anyResp, err := types.MarshalAny(extent.resp)
+	if err != nil {
+		level.Warn(l.logger).Log("msg", "error marshalling response", "err", err)
+		return
+	}
+	data, err := proto.Marshal(&resultscache.CachedResponse{
+		Key: cacheKey,
+		Extents: []resultscache.Extent{{
+			Start:    extent.start,
+			End:      extent.end,
+			Response: anyResp,
+		}},
+	})
+	if err != nil {
+		level.Warn(l.logger).Log("msg", "error marshalling cached response", "err", err)
+		return
+	}
+	if l.cfg.MaxEntrySize > 0 && len(data) > int(l.cfg.MaxEntrySize) {
+		l.metrics.CacheTooLarge.Inc()
+		level.Debug(l.logger).Log("msg", "log result too large to be cached", "key", cacheKey, "size", len(data))
+		return
+	}
+	if err := l.cache.Store(ctx, []string{cache.HashKey(cacheKey)}, [][]byte{data}); err != nil {
+		level.Warn(l.logger).Log("msg", "error storing cache", "err", err)
+	}
+}
+
+// trimLogExtent returns the part of a response that can be cached.
+// When the response reached the limit, only the time range before the last returned timestamp (in the direction of the query)
+// is known to be complete, since other lines sharing that timestamp may have been cut.
+func trimLogExtent(req *LokiRequest, resp *LokiResponse) (logExtent, bool) {
+	if resp.Status != loghttp.QueryStatusSuccess {
+		return logExtent{}, false
+	}
+
+	start, end := req.GetStartTs().UnixNano(), req.GetEndTs().UnixNano()
+	if countEntries(resp) >= int(req.Limit) {
+		from, through := entriesBounds(resp)
+		if req.Direction == logproto.FORWARD {
+			end = through
+		} else {
+			start = from + 1
+		}
+	}
+	if start >= end {
+		return logExtent{}, false
+	}
+
+	return logExtent{
+		start: start,
+		end:   end,
+		resp: &LokiResponse{
+			Status:  loghttp.QueryStatusSuccess,
+			Version: resp.Version,
+			Data: LokiData{
+				ResultType: loghttp.ResultTypeStream,
+				Result:     filterStreams(resp.Data.Result, start, end, req.Direction, logproto.FORWARD),
+			},
+		},
+	}, true
+}
+
+// mergeLogExtents merges two adjacent extents, a being before b.
+func mergeLogExtents(a, b logExtent) logExtent {
+	merged := &LokiResponse{
+		Status:  loghttp.QueryStatusSuccess,
+		Version: a.resp.Version,
+		Data: LokiData{
+			ResultType: loghttp.ResultTypeStream,
+		},
+	}
+
+	streams := make(map[string]int, len(a.resp.Data.Result))
+	for _, stream := range a.resp.Data.Result {
+		streams[stream.Labels] = len(merged.Data.Result)
+		merged.Data.Result = append(merged.Data.Result, logproto.Stream{
+			Labels:  stream.Labels,
+			Hash:    stream.Hash,
+			Entries: append([]logproto.Entry{}, stream.Entries...),
+		})
+	}
+	for _, stream := range b.resp.Data.Result {
+		i, ok := streams[stream.Labels]
+		if !ok {
+			merged.Data.Result = append(merged.Data.Result, stream)
+			continue
+		}
+		merged.Data.Result[i].Entries = append(merged.Data.Result[i].Entries, stream.Entries...)
+	}
+
+	return logExtent{start: a.start, end: b.end, resp: merged}
+}
+
+// filterStreams returns the entries of the streams within [start, end).
+// Entries are expected in the from direction and returned in the to direction. Streams without entries are dropped.
+func filterStreams(streams []logproto.Stream, start, end int64, from, to logproto.Direction) []logproto.Stream {
+	result := make([]logproto.Stream, 0, len(streams))
+	for _, stream := range streams {
+		entries := make([]logproto.Entry, 0, len(stream.Entries))
+		for _, entry := range stream.Entries {
+			if ts := entry.Timestamp.UnixNano(); ts >= start && ts < end {
+				entries = append(entries, entry)
+			}
+		}
+		if len(entries) == 0 {
+			continue
+		}
+		if from != to {
+			for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+				entries[i], entries[j] = entries[j], entries[i]
+			}
+		}
+		result = append(result, logproto.Stream{
+			Labels:  stream.Labels,
+			Hash:    stream.Hash,
+			Entries: entries,
+		})
+	}
+	return result
+}
+
+func countEntries(resp *LokiResponse) int {
+	var n int
+	for _, stream := range resp.Data.Result {
+		n += len(stream.Entries)
+	}
+	return n
+}
+
+// entriesBounds returns the smallest and largest entry timestamps of a non-empty response in nanoseconds.
+func entriesBounds(resp *LokiResponse) (from, through int64) {
+	first := true
+	for _, stream := range resp.Data.Result {
+		for _, entry := range stream.Entries {
+			ts := entry.Timestamp.UnixNano()
+			if first || ts < from {
+				from = ts
+			}
+			if first || ts > through {
+				through = ts
+			}
+			first = false
+		}
+	}
+	return from, through
+}
+
 // extractLokiResponse extracts response with interval [start, end)
 func extractLokiResponse(start, end time.Time, r *LokiResponse) *LokiResponse {
 	extractedResp := LokiResponse{
@@ -3,6 +3,7 @@ package queryrange
 import (
 	"context"
 	"fmt"
+	"slices"
 	"testing"
 	"time"
 
@@ -38,6 +39,7 @@ func Test_LogResultCacheSameRange(t *testing.T) {
 			nil,
 			nil,
 			nil,
+			LogResultCacheConfig{},
 		)
 	)
 
@@ -80,6 +82,7 @@ func Test_LogResultCacheSameRangeNonEmpty(t *testing.T) {
 			nil,
 			nil,
 			nil,
+			LogResultCacheConfig{},
 		)
 	)
 
@@ -128,6 +131,7 @@ func Test_LogResultCacheSmallerRange(t *testing.T) {
 			nil,
 			nil,
 			nil,
+			LogResultCacheConfig{},
 		)
 	)
 
@@ -178,6 +182,7 @@ func Test_LogResultCacheDifferentRange(t *testing.T) {
 			nil,
 			nil,
 			nil,
+			LogResultCacheConfig{},
 		)
 	)
 
@@ -254,6 +259,7 @@ func Test_LogResultCacheDifferentRangeNonEmpty(t *testing.T) {
 			nil,
 			nil,
 			nil,
+			LogResultCacheConfig{},
 		)
 	)
 
@@ -341,6 +347,7 @@ func Test_LogResultCacheDifferentRangeNonEmptyAndEmpty(t *testing.T) {
 			nil,
 			nil,
 			nil,
+			LogResultCacheConfig{},
 		)
 	)
 
@@ -451,6 +458,7 @@ func Test_LogResultNonOverlappingCache(t *testing.T) {
 			nil,
 			nil,
 			metrics,
+			LogResultCacheConfig{},
 		)
 	)
 
@@ -592,6 +600,7 @@ func Test_LogResultCacheDifferentLimit(t *testing.T) {
 			nil,
 			nil,
 			nil,
+			LogResultCacheConfig{},
 		)
 	)
 
@@ -628,6 +637,224 @@ func Test_LogResultCacheDifferentLimit(t *testing.T) {
 	fake.AssertExpectations(t)
 }
 
+func Test_LogResultCacheNonEmptyResults(t *testing.T) {
+	var (
+		ctx = user.InjectOrgID(context.Background(), "foo")
+		lrc = NewLogResultCache(
+			log.NewNopLogger(),
+			fakeLimits{
+				splitDuration: map[string]time.Duration{"foo": time.Minute},
+			},
+			cache.NewMockCache(),
+			nil,
+			nil,
+			nil,
+			LogResultCacheConfig{CacheNonEmptyResults: true},
+		)
+	)
+
+	req := &LokiRequest{
+		StartTs:   time.Unix(60, 0),
+		EndTs:     time.Unix(120, 0),
+		Limit:     entriesLimit,
+		Direction: logproto.FORWARD,
+	}
+	subReq := &LokiRequest{
+		StartTs:   time.Unix(70, 0),
+		EndTs:     time.Unix(80, 0),
+		Limit:     entriesLimit,
+		Direction: logproto.BACKWARD,
+	}
+
+	fake := newFakeResponse([]mockResponse{
+		{
+			RequestResponse: queryrangebase.RequestResponse{
+				Request:  req,
+				Response: nonEmptyResponse(req, time.Unix(61, 0), time.Unix(90, 0), lblFooBar),
+			},
+		},
+	})
+
+	h := lrc.Wrap(fake)
+
+	resp, err := h.Do(ctx, req)
+	require.NoError(t, err)
+	require.Equal(t, nonEmptyResponse(req, time.Unix(61, 0), time.Unix(90, 0), lblFooBar), resp)
+
+	// the same request is served from the cache.
+	resp, err = h.Do(ctx, req)
+	require.NoError(t, err)
+	require.Equal(t, nonEmptyResponse(req, time.Unix(61, 0), time.Unix(90, 0), lblFooBar).Data.Result, resp.(*LokiResponse).Data.Result)
+
+	// a smaller request in the other direction is served from the cache as well.
+	resp, err = h.Do(ctx, subReq)
+	require.NoError(t, err)
+	expected := nonEmptyResponse(subReq, time.Unix(70, 0), time.Unix(79, 0), lblFooBar).Data.Result
+	slices.Reverse(expected[0].Entries)
+	require.Equal(t, expected, resp.(*LokiResponse).Data.Result)
+
+	fake.AssertExpectations(t)
+}
+
+func Test_LogResultCacheNonEmptyResultsLimit(t *testing.T) {
+	var (
+		ctx = user.InjectOrgID(context.Background(), "foo")
+		lrc = NewLogResultCache(
+			log.NewNopLogger(),
+			fakeLimits{
+				splitDuration: map[string]time.Duration{"foo": time.Minute},
+			},
+			cache.NewMockCache(),
+			nil,
+			nil,
+			nil,
+			LogResultCacheConfig{CacheNonEmptyResults: true},
+		)
+	)
+
+	// the response reaches the limit, so only [60s, 65s) is known to be complete and cached.
+	req1 := &LokiRequest{
+		StartTs:   time.Unix(60, 0),
+		EndTs:     time.Unix(120, 0),
+		Limit:     5,
+		Direction: logproto.FORWARD,
+	}
+	// a lower limit in the same direction is fully served from the cache.
+	req2 := &LokiRequest{
+		StartTs:   time.Unix(60, 0),
+		EndTs:     time.Unix(120, 0),
+		Limit:     3,
+		Direction: logproto.FORWARD,
+	}
+	// a backward request only needs to fetch what is after the cached extent.
+	req3 := &LokiRequest{
+		StartTs:   time.Unix(60, 0),
+		EndTs:     time.Unix(120, 0),
+		Limit:     3,
+		Direction: logproto.BACKWARD,
+	}
+	req3End := &LokiRequest{
+		StartTs:   time.Unix(65, 0),
+		EndTs:     time.Unix(120, 0),
+		Limit:     3,
+		Direction: logproto.BACKWARD,
+	}
+	// a forward request with a higher limit fetches the remaining data and extends the cached extent.
+	req4 := &LokiRequest{
+		StartTs:   time.Unix(60, 0),
+		EndTs:     time.Unix(120, 0),
+		Limit:     entriesLimit,
+		Direction: logproto.FORWARD,
+	}
+	req4End := &LokiRequest{
+		StartTs:   time.Unix(65, 0),
+		EndTs:     time.Unix(120, 0),
+		Limit:     entriesLimit,
+		Direction: logproto.FORWARD,
+	}
+
+	req3EndResp := nonEmptyResponse(req3End, time.Unix(117, 0), time.Unix(119, 0), lblFooBar)
+	slices.Reverse(req3EndResp.Data.Result[0].Entries)
+
+	fake := newFakeResponse([]mockResponse{
+		{
+			RequestResponse: queryrangebase.RequestResponse{
+				Request:  req1,
+				Response: nonEmptyResponse(req1, time.Unix(61, 0), time.Unix(65, 0), lblFooBar),
+			},
+		},
+		{
+			RequestResponse: queryrangebase.RequestResponse{
+				Request:  req3End,
+				Response: req3EndResp,
+			},
+		},
+		{
+			RequestResponse: queryrangebase.RequestResponse{
+				Request:  req4End,
+				Response: nonEmptyResponse(req4End, time.Unix(65, 0), time.Unix(119, 0), lblFooBar),
+			},
+		},
+	})
+
+	h := lrc.Wrap(fake)
+
+	resp, err := h.Do(ctx, req1)
+	require.NoError(t, err)
+	require.Equal(t, nonEmptyResponse(req1, time.Unix(61, 0), time.Unix(65, 0), lblFooBar), resp)
+
+	resp, err = h.Do(ctx, req2)
+	require.NoError(t, err)
+	require.Equal(t, nonEmptyResponse(req2, time.Unix(61, 0), time.Unix(63, 0), lblFooBar).Data.Result, resp.(*LokiResponse).Data.Result)
+
+	resp, err = h.Do(ctx, req3)
+	require.NoError(t, err)
+	require.Equal(t, req3EndResp.Data.Result, resp.(*LokiResponse).Data.Result)
+
+	resp, err = h.Do(ctx, req4)
+	require.NoError(t, err)
+	require.Equal(t, nonEmptyResponse(req4, time.Unix(61, 0), time.Unix(119, 0), lblFooBar).Data.Result, resp.(*LokiResponse).Data.Result)
+
+	// the extended extent now serves the whole range.
+	resp, err = h.Do(ctx, req4)
+	require.NoError(t, err)
+	require.Equal(t, nonEmptyResponse(req4, time.Unix(61, 0), time.Unix(119, 0), lblFooBar).Data.Result, resp.(*LokiResponse).Data.Result)
+
+	fake.AssertExpectations(t)
+}
+
+func Test_LogResultCacheMaxEntrySize(t *testing.T) {
+	metrics := NewLogResultCacheMetrics(prometheus.NewPedanticRegistry())
+	var (
+		ctx = user.InjectOrgID(context.Background(), "foo")
+		lrc = NewLogResultCache(
+			log.NewNopLogger(),
+			fakeLimits{
+				splitDuration: map[string]time.Duration{"foo": time.Minute},
+			},
+			cache.NewMockCache(),
+			nil,
+			nil,
+			metrics,
+			LogResultCacheConfig{CacheNonEmptyResults: true, MaxEntrySize: 64},
+		)
+	)
+
+	req := &LokiRequest{
+		StartTs:   time.Unix(60, 0),
+		EndTs:     time.Unix(120, 0),
+		Limit:     entriesLimit,
+		Direction: logproto.FORWARD,
+	}
+
+	fake := newFakeResponse([]mockResponse{
+		{
+			RequestResponse: queryrangebase.RequestResponse{
+				Request:  req,
+				Response: nonEmptyResponse(req, time.Unix(61, 0), time.Unix(90, 0), lblFooBar),
+			},
+		},
+		{
+			RequestResponse: queryrangebase.RequestResponse{
+				Request:  req,
+				Response: nonEmptyResponse(req, time.Unix(61, 0), time.Unix(90, 0), lblFooBar),
+			},
+		},
+	})
+
+	h := lrc.Wrap(fake)
+
+	for i := 0; i < 2; i++ {
+		resp, err := h.Do(ctx, req)
+		require.NoError(t, err)
+		require.Equal(t, nonEmptyResponse(req, time.Unix(61, 0), time.Unix(90, 0), lblFooBar), resp)
+	}
+
+	require.Equal(t, float64(2), testutil.ToFloat64(metrics.CacheMiss))
+	require.Equal(t, float64(2), testutil.ToFloat64(metrics.CacheTooLarge))
+	fake.AssertExpectations(t)
+}
+
 func TestExtractLokiResponse(t *testing.T) {
 	for _, tc := range []struct {
 		name           string
@@ -308,6 +308,11 @@ func (Codec) QueryRequestUnwrap(ctx context.Context, req *QueryRequest) (queryra
 		ctx = httpreq.InjectHeader(ctx, httpreq.LokiDisablePipelineWrappersHeader, disableWrappers)
 	}
 
+	// Add query priority
+	if priority, ok := req.Metadata[httpreq.LokiQueryPriorityHeader]; ok {
+		ctx = httpreq.InjectHeader(ctx, httpreq.LokiQueryPriorityHeader, priority)
+	}
+
 	// Add limits
 	if encodedLimits, ok := req.Metadata[querylimits.HTTPHeaderQueryLimitsKey]; ok {
 		limits, err := querylimits.UnmarshalQueryLimits([]byte(encodedLimits))
@@ -425,6 +430,12 @@ func (Codec) QueryRequestWrap(ctx context.Context, r queryrangebase.Request) (*Q
 		result.Metadata[httpreq.LokiDisablePipelineWrappersHeader] = disableWrappers
 	}
 
+	// Keep query priority
+	priority := httpreq.ExtractHeader(ctx, httpreq.LokiQueryPriorityHeader)
+	if priority != "" {
+		result.Metadata[httpreq.LokiQueryPriorityHeader] = priority
+	}
+
 	// Add limits
 	limits := querylimits.ExtractQueryLimitsContext(ctx)
 	if limits != nil {
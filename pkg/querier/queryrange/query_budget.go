@@ -0,0 +1,402 @@
+package queryrange
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/grafana/dskit/httpgrpc"
+	"github.com/grafana/dskit/tenant"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/grafana/loki/v3/pkg/logql"
+	"github.com/grafana/loki/v3/pkg/logqlmodel/stats"
+	"github.com/grafana/loki/v3/pkg/querier/queryrange/queryrangebase"
+	"github.com/grafana/loki/v3/pkg/storage/config"
+	"github.com/grafana/loki/v3/pkg/storage/types"
+	"github.com/grafana/loki/v3/pkg/util/spanlogger"
+)
+
+const (
+	limErrQueryBudgetExceededTmpl   = "tenant %s exhausted its query budget (consumed: %s, budget: %s per %s); try again later or reduce the amount of data scanned by queries"
+	limErrQueryLargerThanBudgetTmpl = "the query of tenant %s would scan %s, more than its query budget (budget: %s per %s); reduce the amount of data scanned by the query"
+)
+
+// BudgetLimits are the per-tenant limits of the query budget.
+type BudgetLimits interface {
+	QueryBytesBudget(string) int
+	QueryBudgetWindow(string) time.Duration
+	QueryBudgetMaxWait(string) time.Duration
+}
+
+// QueryBudget tracks the bytes scanned by the queries of each tenant within a sliding time window.
+// Queries of a tenant that exhausted its budget are delayed until enough of the budget is freed up, or rejected.
+type QueryBudget struct {
+	limits BudgetLimits
+	now    func() time.Time
+
+	mtx     sync.Mutex
+	tenants map[string]*tenantBudget
+
+	consumedBytes *prometheus.CounterVec
+	throttled     *prometheus.CounterVec
+}
+
+type tenantBudget struct {
+	// charges are ordered by the time the queries were admitted.
+	charges   []*budgetCharge
+	consumed  uint64
+	throttled uint64
+}
+
+type budgetCharge struct {
+	ts    time.Time
+	bytes uint64
+}
+
+// NewQueryBudget creates a new QueryBudget.
+func NewQueryBudget(limits BudgetLimits, registerer prometheus.Registerer, metricsNamespace string) *QueryBudget {
+	return &QueryBudget{
+		limits:  limits,
+		now:     time.Now,
+		tenants: map[string]*tenantBudget{},
+		consumedBytes: promauto.With(registerer).NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "query_frontend_query_budget_consumed_bytes_total",
+			Help:      "Total number of bytes charged to the query budget of a tenant.",
+		}, []string{"tenant"}),
+		throttled: promauto.With(registerer).NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "query_frontend_query_budget_throttled_total",
+			Help:      "Total number of queries throttled because the tenant exhausted its query budget, by outcome.",
+		}, []string{"tenant", "outcome"}),
+	}
+}
+
+// admit waits until the bytes the query is expected to scan fit into the budget of all tenants, and reserves them.
+// Checking and reserving the budget happen at once, so that concurrent queries can not overrun it. It returns an error
+// if the budget of a tenant is not freed up within its max wait time, or if the query does not fit into it at all.
+func (b *QueryBudget) admit(ctx context.Context, tenantIDs []string, bytes uint64) (map[string]*budgetCharge, error) {
+	for _, id := range tenantIDs {
+		if budget := b.limits.QueryBytesBudget(id); budget > 0 && bytes > uint64(budget) {
+			b.throttled.WithLabelValues(id, "rejected").Inc()
+			return nil, httpgrpc.Errorf(http.StatusTooManyRequests, limErrQueryLargerThanBudgetTmpl,
+				id, humanize.IBytes(bytes), humanize.IBytes(uint64(budget)), b.limits.QueryBudgetWindow(id))
+		}
+	}
+
+	var (
+		start   = b.now()
+		delayed = map[string]struct{}{}
+	)
+	for {
+		charges, exhausted, retryAfter, consumed := b.reserve(tenantIDs, bytes)
+		if exhausted == "" {
+			for id := range delayed {
+				b.throttled.WithLabelValues(id, "delayed").Inc()
+			}
+			return charges, nil
+		}
+
+		if _, ok := delayed[exhausted]; !ok {
+			b.mtx.Lock()
+			b.tenant(exhausted).throttled++
+			b.mtx.Unlock()
+		}
+
+		budget := b.limits.QueryBytesBudget(exhausted)
+		window := b.limits.QueryBudgetWindow(exhausted)
+		remaining := b.limits.QueryBudgetMaxWait(exhausted) - b.now().Sub(start)
+		if retryAfter > remaining {
+			b.throttled.WithLabelValues(exhausted, "rejected").Inc()
+			return nil, httpgrpc.Errorf(http.StatusTooManyRequests, limErrQueryBudgetExceededTmpl,
+				exhausted, humanize.IBytes(consumed), humanize.IBytes(uint64(budget)), window)
+		}
+		delayed[exhausted] = struct{}{}
+
+		timer := time.NewTimer(retryAfter)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// reserve charges the bytes to every tenant with a budget if they fit into the budget of all of them. Otherwise it
+// charges nothing and returns the first tenant whose budget is exhausted, along with the time until enough of its
+// budget is freed up and the bytes it consumed within the window.
+func (b *QueryBudget) reserve(tenantIDs []string, bytes uint64) (map[string]*budgetCharge, string, time.Duration, uint64) {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	now := b.now()
+	for _, id := range tenantIDs {
+		budget := b.limits.QueryBytesBudget(id)
+		if budget <= 0 {
+			continue
+		}
+		window := b.limits.QueryBudgetWindow(id)
+		t := b.tenant(id)
+		t.expire(now.Add(-window))
+		if fitsBudget(t.consumed, bytes, uint64(budget)) {
+			continue
+		}
+
+		// Find the charge whose expiry makes the query fit into the budget of the tenant.
+		freed := uint64(0)
+		for _, c := range t.charges {
+			freed += c.bytes
+			if fitsBudget(t.consumed-freed, bytes, uint64(budget)) {
+				return nil, id, c.ts.Add(window).Sub(now), t.consumed
+			}
+		}
+		return nil, id, window, t.consumed
+	}
+
+	charges := make(map[string]*budgetCharge, len(tenantIDs))
+	for _, id := range tenantIDs {
+		if b.limits.QueryBytesBudget(id) <= 0 {
+			continue
+		}
+		c := &budgetCharge{ts: now, bytes: bytes}
+		t := b.tenant(id)
+		t.charges = append(t.charges, c)
+		t.consumed += bytes
+		charges[id] = c
+	}
+	return charges, "", 0, 0
+}
+
+// fitsBudget returns true if a query expected to scan bytes can run when consumed bytes of the budget are used up.
+// Queries without an estimate run as long as the budget is not exhausted.
+func fitsBudget(consumed, bytes, budget uint64) bool {
+	return consumed < budget && consumed+bytes <= budget
+}
+
+// settle replaces the estimated bytes of the charges with the bytes the query actually scanned.
+func (b *QueryBudget) settle(charges map[string]*budgetCharge, bytes uint64) {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	for id, c := range charges {
+		b.consumedBytes.WithLabelValues(id).Add(float64(bytes))
+
+		t, ok := b.tenants[id]
+		if !ok || !t.contains(c) {
+			// The charge already expired.
+			continue
+		}
+		t.consumed = t.consumed - c.bytes + bytes
+		c.bytes = bytes
+	}
+}
+
+func (b *QueryBudget) tenant(id string) *tenantBudget {
+	t, ok := b.tenants[id]
+	if !ok {
+		t = &tenantBudget{}
+		b.tenants[id] = t
+	}
+	return t
+}
+
+// expire removes all charges made before the given time.
+func (t *tenantBudget) expire(before time.Time) {
+	i := 0
+	for ; i < len(t.charges) && t.charges[i].ts.Before(before); i++ {
+		t.consumed -= t.charges[i].bytes
+	}
+	t.charges = t.charges[i:]
+}
+
+func (t *tenantBudget) contains(c *budgetCharge) bool {
+	for _, other := range t.charges {
+		if other == c {
+			return true
+		}
+	}
+	return false
+}
+
+// TenantBudgetUsage is the consumption of the query budget of a tenant.
+type TenantBudgetUsage struct {
+	Tenant        string  `json:"tenant"`
+	ConsumedBytes uint64  `json:"consumed_bytes"`
+	BudgetBytes   uint64  `json:"budget_bytes"`
+	Window        string  `json:"window"`
+	Utilization   float64 `json:"utilization"`
+	Queries       int     `json:"queries"`
+	Throttled     uint64  `json:"throttled"`
+}
+
+// Usage returns the current consumption of the query budget of all tenants that ran queries within their window.
+func (b *QueryBudget) Usage() []TenantBudgetUsage {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	now := b.now()
+	usage := make([]TenantBudgetUsage, 0, len(b.tenants))
+	for id, t := range b.tenants {
+		budget := b.limits.QueryBytesBudget(id)
+		window := b.limits.QueryBudgetWindow(id)
+		t.expire(now.Add(-window))
+		if budget <= 0 || len(t.charges) == 0 {
+			delete(b.tenants, id)
+			continue
+		}
+
+		usage = append(usage, TenantBudgetUsage{
+			Tenant:        id,
+			ConsumedBytes: t.consumed,
+			BudgetBytes:   uint64(budget),
+			Window:        window.String(),
+			Utilization:   float64(t.consumed) / float64(budget),
+			Queries:       len(t.charges),
+			Throttled:     t.throttled,
+		})
+	}
+
+	sort.Slice(usage, func(i, j int) bool {
+		return usage[i].Tenant < usage[j].Tenant
+	})
+	return usage
+}
+
+// ServeHTTP reports the query budget consumption of the tenants of the request.
+func (b *QueryBudget) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	tenantIDs, err := tenant.TenantIDs(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	usage := b.Usage()
+	filtered := usage[:0]
+	for _, u := range usage {
+		if slices.Contains(tenantIDs, u.Tenant) {
+			filtered = append(filtered, u)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(struct {
+		Tenants []TenantBudgetUsage `json:"tenants"`
+	}{filtered}); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+type queryBudgetMiddleware struct {
+	budget    *QueryBudget
+	estimator *querySizeLimiter
+	next      queryrangebase.Handler
+}
+
+// NewQueryBudgetMiddleware creates a new Middleware that admits log and metric queries according to the query budget of
+// their tenants. The bytes a query scans are estimated from index stats when TSDB is used, and corrected with the
+// statistics of the response.
+func NewQueryBudgetMiddleware(
+	budget *QueryBudget,
+	cfg []config.PeriodConfig,
+	engineOpts logql.EngineOpts,
+	logger log.Logger,
+	statsHandler queryrangebase.Handler,
+) queryrangebase.Middleware {
+	return queryrangebase.MiddlewareFunc(func(next queryrangebase.Handler) queryrangebase.Handler {
+		if budget == nil {
+			return next
+		}
+		return &queryBudgetMiddleware{
+			budget:    budget,
+			estimator: newQuerySizeLimiter(next, cfg, engineOpts, logger, nil, "", statsHandler),
+			next:      next,
+		}
+	})
+}
+
+func (q *queryBudgetMiddleware) Do(ctx context.Context, r queryrangebase.Request) (queryrangebase.Response, error) {
+	switch r.(type) {
+	case *LokiRequest, *LokiInstantRequest:
+	default:
+		return q.next.Do(ctx, r)
+	}
+
+	tenantIDs, err := tenant.TenantIDs(ctx)
+	if err != nil {
+		return nil, httpgrpc.Errorf(http.StatusBadRequest, err.Error())
+	}
+
+	enabled := false
+	for _, id := range tenantIDs {
+		if q.budget.limits.QueryBytesBudget(id) > 0 {
+			enabled = true
+			break
+		}
+	}
+	if !enabled {
+		return q.next.Do(ctx, r)
+	}
+
+	log := spanlogger.FromContext(ctx)
+	defer log.Finish()
+
+	start := time.Now()
+	charges, err := q.budget.admit(ctx, tenantIDs, q.estimate(ctx, r))
+	if err != nil {
+		level.Warn(log).Log("msg", "query budget exhausted", "status", "rejected", "err", err)
+		return nil, err
+	}
+	if waited := time.Since(start); waited > time.Millisecond {
+		level.Debug(log).Log("msg", "query delayed by query budget", "waited", waited)
+	}
+
+	resp, err := q.next.Do(ctx, r)
+	if err != nil {
+		// Keep the estimate, the query may have scanned data before failing.
+		return resp, err
+	}
+
+	q.budget.settle(charges, bytesProcessed(resp))
+	return resp, nil
+}
+
+// estimate returns the bytes the query is expected to scan according to index stats.
+// It returns 0 if the estimate is not available, in which case only the actual bytes are charged.
+func (q *queryBudgetMiddleware) estimate(ctx context.Context, r queryrangebase.Request) uint64 {
+	schemaCfg, err := q.estimator.getSchemaCfg(r)
+	if err != nil || schemaCfg.IndexType != types.TSDBType {
+		return 0
+	}
+
+	bytes, err := q.estimator.getBytesReadForRequest(ctx, r)
+	if err != nil {
+		level.Warn(q.estimator.logger).Log("msg", "failed to estimate bytes read for query budget", "err", err)
+		return 0
+	}
+	return bytes
+}
+
+// bytesProcessed returns the bytes processed by a query according to the statistics of its response.
+func bytesProcessed(resp queryrangebase.Response) uint64 {
+	var s *stats.Result
+	switch r := resp.(type) {
+	case *LokiResponse:
+		s = &r.Statistics
+	case *LokiPromResponse:
+		s = &r.Statistics
+	default:
+		return 0
+	}
+	return uint64(s.Querier.Store.Chunk.DecompressedBytes + s.Querier.Store.Chunk.HeadChunkBytes +
+		s.Ingester.Store.Chunk.DecompressedBytes + s.Ingester.Store.Chunk.HeadChunkBytes)
+}
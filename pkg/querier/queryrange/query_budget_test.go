@@ -0,0 +1,245 @@
+package queryrange
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/grafana/dskit/httpgrpc"
+	"github.com/grafana/dskit/user"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/logql/syntax"
+	"github.com/grafana/loki/v3/pkg/logqlmodel/stats"
+	"github.com/grafana/loki/v3/pkg/querier/plan"
+	base "github.com/grafana/loki/v3/pkg/querier/queryrange/queryrangebase"
+	"github.com/grafana/loki/v3/pkg/util/constants"
+	util_log "github.com/grafana/loki/v3/pkg/util/log"
+)
+
+func budgetTestRequest() *LokiRequest {
+	query := `{app="foo"} |= "foo"`
+	return &LokiRequest{
+		Query:     query,
+		Limit:     1000,
+		StartTs:   testTime.Add(-time.Hour),
+		EndTs:     testTime,
+		Direction: logproto.FORWARD,
+		Path:      "/loki/api/v1/query_range",
+		Plan: &plan.QueryPlan{
+			AST: syntax.MustParseExpr(query),
+		},
+	}
+}
+
+// scannedBytesHandler returns log responses whose statistics report the given number of processed bytes.
+func scannedBytesHandler(bytes int64) base.Handler {
+	return base.HandlerFunc(func(_ context.Context, _ base.Request) (base.Response, error) {
+		return &LokiResponse{
+			Status:    "success",
+			Direction: logproto.FORWARD,
+			Statistics: stats.Result{
+				Querier: stats.Querier{Store: stats.Store{Chunk: stats.Chunk{DecompressedBytes: bytes}}},
+			},
+		}, nil
+	})
+}
+
+func TestQueryBudget_RejectsOnceExhausted(t *testing.T) {
+	limits := fakeLimits{queryBytesBudget: 100, queryBudgetWindow: time.Hour}
+	budget := NewQueryBudget(limits, prometheus.NewRegistry(), constants.Loki)
+	now := testTime
+	budget.now = func() time.Time { return now }
+
+	handler := NewQueryBudgetMiddleware(budget, testSchemas, testEngineOpts, util_log.Logger, nil).Wrap(scannedBytesHandler(60))
+	ctx := user.InjectOrgID(context.Background(), "foo")
+
+	// The budget is exhausted only once the consumption reaches it.
+	_, err := handler.Do(ctx, budgetTestRequest())
+	require.NoError(t, err)
+	_, err = handler.Do(ctx, budgetTestRequest())
+	require.NoError(t, err)
+
+	_, err = handler.Do(ctx, budgetTestRequest())
+	require.Error(t, err)
+	resp, ok := httpgrpc.HTTPResponseFromError(err)
+	require.True(t, ok)
+	require.Equal(t, int32(http.StatusTooManyRequests), resp.Code)
+
+	// Other tenants are not affected.
+	_, err = handler.Do(user.InjectOrgID(context.Background(), "bar"), budgetTestRequest())
+	require.NoError(t, err)
+
+	// The budget is freed up once the charges leave the window.
+	now = now.Add(time.Hour + time.Second)
+	_, err = handler.Do(ctx, budgetTestRequest())
+	require.NoError(t, err)
+}
+
+func TestQueryBudget_DelaysUntilBudgetIsFreed(t *testing.T) {
+	limits := fakeLimits{queryBytesBudget: 100, queryBudgetWindow: 100 * time.Millisecond, queryBudgetMaxWait: 5 * time.Second}
+	budget := NewQueryBudget(limits, prometheus.NewRegistry(), constants.Loki)
+
+	handler := NewQueryBudgetMiddleware(budget, testSchemas, testEngineOpts, util_log.Logger, nil).Wrap(scannedBytesHandler(100))
+	ctx := user.InjectOrgID(context.Background(), "foo")
+
+	_, err := handler.Do(ctx, budgetTestRequest())
+	require.NoError(t, err)
+
+	start := time.Now()
+	_, err = handler.Do(ctx, budgetTestRequest())
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+
+	usage := budget.Usage()
+	require.Len(t, usage, 1)
+	require.Equal(t, uint64(1), usage[0].Throttled)
+}
+
+func TestQueryBudget_EstimatesFromIndexStats(t *testing.T) {
+	limits := fakeLimits{queryBytesBudget: 100, queryBudgetWindow: time.Hour}
+	budget := NewQueryBudget(limits, prometheus.NewRegistry(), constants.Loki)
+	budget.now = func() time.Time { return testTime }
+
+	statsHits, statsHandler := indexStatsResult(logproto.IndexStatsResponse{Bytes: 80})
+	ctx := user.InjectOrgID(context.Background(), "foo")
+
+	// The estimate is charged while the query runs and replaced by the actual bytes once it completes.
+	next := base.HandlerFunc(func(ctx context.Context, r base.Request) (base.Response, error) {
+		usage := budget.Usage()
+		require.Len(t, usage, 1)
+		require.Equal(t, uint64(80), usage[0].ConsumedBytes)
+		return scannedBytesHandler(30).Do(ctx, r)
+	})
+	_, err := NewQueryBudgetMiddleware(budget, testSchemasTSDB, testEngineOpts, util_log.Logger, statsHandler).Wrap(next).Do(ctx, budgetTestRequest())
+	require.NoError(t, err)
+	require.Equal(t, 1, *statsHits)
+
+	require.Equal(t, []TenantBudgetUsage{{
+		Tenant:        "foo",
+		ConsumedBytes: 30,
+		BudgetBytes:   100,
+		Window:        "1h0m0s",
+		Utilization:   0.3,
+		Queries:       1,
+	}}, budget.Usage())
+}
+
+func TestQueryBudget_ReservesEstimateOnAdmission(t *testing.T) {
+	limits := fakeLimits{queryBytesBudget: 100, queryBudgetWindow: time.Hour}
+	budget := NewQueryBudget(limits, prometheus.NewRegistry(), constants.Loki)
+	budget.now = func() time.Time { return testTime }
+
+	_, statsHandler := indexStatsResult(logproto.IndexStatsResponse{Bytes: 60})
+	ctx := user.InjectOrgID(context.Background(), "foo")
+
+	running, release := make(chan struct{}), make(chan struct{})
+	next := base.HandlerFunc(func(ctx context.Context, r base.Request) (base.Response, error) {
+		close(running)
+		<-release
+		return scannedBytesHandler(60).Do(ctx, r)
+	})
+	handler := NewQueryBudgetMiddleware(budget, testSchemasTSDB, testEngineOpts, util_log.Logger, statsHandler).Wrap(next)
+
+	errs := make(chan error, 1)
+	go func() {
+		_, err := handler.Do(ctx, budgetTestRequest())
+		errs <- err
+	}()
+	<-running
+
+	// The estimate of the running query is reserved, so a concurrent query does not fit into the budget anymore.
+	_, err := handler.Do(ctx, budgetTestRequest())
+	resp, ok := httpgrpc.HTTPResponseFromError(err)
+	require.True(t, ok)
+	require.Equal(t, int32(http.StatusTooManyRequests), resp.Code)
+
+	close(release)
+	require.NoError(t, <-errs)
+	require.Equal(t, uint64(60), budget.Usage()[0].ConsumedBytes)
+}
+
+func TestQueryBudget_RejectsQueriesLargerThanBudget(t *testing.T) {
+	limits := fakeLimits{queryBytesBudget: 100, queryBudgetWindow: time.Hour, queryBudgetMaxWait: time.Hour}
+	budget := NewQueryBudget(limits, prometheus.NewRegistry(), constants.Loki)
+
+	_, statsHandler := indexStatsResult(logproto.IndexStatsResponse{Bytes: 200})
+	ctx := user.InjectOrgID(context.Background(), "foo")
+
+	// Waiting for the budget to be freed up does not help, the query is rejected right away.
+	_, err := NewQueryBudgetMiddleware(budget, testSchemasTSDB, testEngineOpts, util_log.Logger, statsHandler).Wrap(scannedBytesHandler(200)).Do(ctx, budgetTestRequest())
+	resp, ok := httpgrpc.HTTPResponseFromError(err)
+	require.True(t, ok)
+	require.Equal(t, int32(http.StatusTooManyRequests), resp.Code)
+	require.Contains(t, string(resp.Body), "more than its query budget")
+	require.Empty(t, budget.Usage())
+}
+
+func TestQueryBudget_PassThrough(t *testing.T) {
+	var calls int
+	next := base.HandlerFunc(func(_ context.Context, _ base.Request) (base.Response, error) {
+		calls++
+		return &LokiSeriesResponse{Status: "success"}, nil
+	})
+	ctx := user.InjectOrgID(context.Background(), "foo")
+
+	// No budget configured for the tenant.
+	budget := NewQueryBudget(fakeLimits{}, prometheus.NewRegistry(), constants.Loki)
+	_, err := NewQueryBudgetMiddleware(budget, testSchemas, testEngineOpts, util_log.Logger, nil).Wrap(next).Do(ctx, budgetTestRequest())
+	require.NoError(t, err)
+	require.Empty(t, budget.Usage())
+
+	// Series requests are not subject to the budget.
+	budget = NewQueryBudget(fakeLimits{queryBytesBudget: 10, queryBudgetWindow: time.Hour}, prometheus.NewRegistry(), constants.Loki)
+	budget.reserve([]string{"foo"}, 10)
+	_, err = NewQueryBudgetMiddleware(budget, testSchemas, testEngineOpts, util_log.Logger, nil).Wrap(next).Do(ctx, &LokiSeriesRequest{})
+	require.NoError(t, err)
+
+	// No budget tracker.
+	_, err = NewQueryBudgetMiddleware(nil, testSchemas, testEngineOpts, util_log.Logger, nil).Wrap(next).Do(ctx, budgetTestRequest())
+	require.NoError(t, err)
+	require.Equal(t, 3, calls)
+}
+
+func TestQueryBudget_ServeHTTP(t *testing.T) {
+	budget := NewQueryBudget(fakeLimits{queryBytesBudget: 100, queryBudgetWindow: time.Hour}, prometheus.NewRegistry(), constants.Loki)
+	budget.reserve([]string{"foo", "bar"}, 25)
+
+	for _, tc := range []struct {
+		orgID    string
+		expected []string
+	}{
+		{orgID: "foo", expected: []string{"foo"}},
+		{orgID: "bar|foo", expected: []string{"bar", "foo"}},
+		{orgID: "baz"},
+	} {
+		t.Run(tc.orgID, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/query-frontend/query_budget", nil)
+			w := httptest.NewRecorder()
+			budget.ServeHTTP(w, req.WithContext(user.InjectOrgID(req.Context(), tc.orgID)))
+			require.Equal(t, http.StatusOK, w.Code)
+
+			var resp struct {
+				Tenants []TenantBudgetUsage `json:"tenants"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+
+			var tenants []string
+			for _, u := range resp.Tenants {
+				require.Equal(t, uint64(25), u.ConsumedBytes)
+				tenants = append(tenants, u.Tenant)
+			}
+			require.Equal(t, tc.expected, tenants)
+		})
+	}
+
+	// Requests without a tenant are rejected.
+	w := httptest.NewRecorder()
+	budget.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/query-frontend/query_budget", nil))
+	require.Equal(t, http.StatusBadRequest, w.Code)
+}
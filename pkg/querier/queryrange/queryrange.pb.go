@@ -52,6 +52,8 @@ type LokiRequest struct {
 	Path      string                                                 `protobuf:"bytes,7,opt,name=path,proto3" json:"path,omitempty"`
 	Shards    []string                                               `protobuf:"bytes,8,rep,name=shards,proto3" json:"shards"`
 	Plan      *github_com_grafana_loki_v3_pkg_querier_plan.QueryPlan `protobuf:"bytes,10,opt,name=plan,proto3,customtype=github.com/grafana/loki/v3/pkg/querier/plan.QueryPlan" json:"plan,omitempty"`
+	// The position of the page to return, from the cursor of the previous page.
+	Cursor string `protobuf:"bytes,11,opt,name=cursor,proto3" json:"cursor,omitempty"`
 }
 
 func (m *LokiRequest) Reset()      { *m = LokiRequest{} }
@@ -149,6 +151,13 @@ func (m *LokiRequest) GetShards() []string {
 	return nil
 }
 
+func (m *LokiRequest) GetCursor() string {
+	if m != nil {
+		return m.Cursor
+	}
+	return ""
+}
+
 type LokiInstantRequest struct {
 	Query     string                                                 `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
 	Limit     uint32                                                 `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
@@ -287,6 +296,8 @@ type LokiResponse struct {
 	Statistics stats.Result                                                                                            `protobuf:"bytes,8,opt,name=statistics,proto3" json:"statistics"`
 	Headers    []github_com_grafana_loki_v3_pkg_querier_queryrange_queryrangebase_definitions.PrometheusResponseHeader `protobuf:"bytes,9,rep,name=Headers,proto3,customtype=github.com/grafana/loki/v3/pkg/querier/queryrange/queryrangebase/definitions.PrometheusResponseHeader" json:"-"`
 	Warnings   []string                                                                                                `protobuf:"bytes,10,rep,name=warnings,proto3" json:"warnings,omitempty"`
+	// The position of the next page, set when the limit of a log query was reached.
+	Cursor string `protobuf:"bytes,11,opt,name=cursor,proto3" json:"cursor,omitempty"`
 }
 
 func (m *LokiResponse) Reset()      { *m = LokiResponse{} }
@@ -384,6 +395,13 @@ func (m *LokiResponse) GetWarnings() []string {
 	return nil
 }
 
+func (m *LokiResponse) GetCursor() string {
+	if m != nil {
+		return m.Cursor
+	}
+	return ""
+}
+
 type LokiSeriesRequest struct {
 	Match   []string  `protobuf:"bytes,1,rep,name=match,proto3" json:"match,omitempty"`
 	StartTs time.Time `protobuf:"bytes,2,opt,name=startTs,proto3,stdtime" json:"startTs"`
@@ -1451,122 +1469,123 @@ func init() {
 }
 
 var fileDescriptor_51b9d53b40d11902 = []byte{
-	// 1836 bytes of a gzipped FileDescriptorProto
+	// 1854 bytes of a gzipped FileDescriptorProto
 	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xec, 0x59, 0xcd, 0x6f, 0x1c, 0x49,
 	0x15, 0x9f, 0x9e, 0x4f, 0xcf, 0xf3, 0xc7, 0x9a, 0x8a, 0x71, 0x1a, 0xef, 0xee, 0xf4, 0x30, 0x12,
 	0xbb, 0x06, 0xc1, 0x0c, 0x19, 0xef, 0x86, 0x5d, 0x13, 0xa2, 0x4d, 0xe3, 0x44, 0x0e, 0x64, 0x21,
-	0xdb, 0xb6, 0x38, 0x70, 0x41, 0x65, 0x4f, 0x65, 0xdc, 0x78, 0xa6, 0xbb, 0xd3, 0x5d, 0xe3, 0xc4,
-	0x12, 0x42, 0xfb, 0x0f, 0xac, 0xd8, 0xbf, 0x02, 0x71, 0x43, 0x48, 0x9c, 0x38, 0x71, 0x0c, 0x48,
-	0x48, 0x39, 0xae, 0x46, 0xa2, 0x21, 0x0e, 0x42, 0xc8, 0xa7, 0x48, 0x5c, 0x39, 0xa0, 0xfa, 0xe8,
-	0x9e, 0xaa, 0xe9, 0x36, 0x99, 0x09, 0xe2, 0x60, 0xe0, 0xe2, 0xa9, 0xae, 0x7a, 0xbf, 0xea, 0x57,
-	0xbf, 0xf7, 0x7e, 0xaf, 0xab, 0xca, 0xf0, 0x76, 0x70, 0xdc, 0xef, 0x3c, 0x1c, 0x91, 0xd0, 0x25,
-	0x21, 0xff, 0x3d, 0x0d, 0xb1, 0xd7, 0x27, 0x4a, 0xb3, 0x1d, 0x84, 0x3e, 0xf5, 0x11, 0x4c, 0x7a,
-	0x36, 0xba, 0x7d, 0x97, 0x1e, 0x8d, 0x0e, 0xda, 0x87, 0xfe, 0xb0, 0xd3, 0xf7, 0xfb, 0x7e, 0xa7,
-	0xef, 0xfb, 0xfd, 0x01, 0xc1, 0x81, 0x1b, 0xc9, 0x66, 0x27, 0x0c, 0x0e, 0x3b, 0x11, 0xc5, 0x74,
-	0x14, 0x09, 0xfc, 0xc6, 0x1a, 0x33, 0xe4, 0x4d, 0x0e, 0x91, 0xbd, 0x96, 0x34, 0xe7, 0x4f, 0x07,
-	0xa3, 0x07, 0x1d, 0xea, 0x0e, 0x49, 0x44, 0xf1, 0x30, 0x48, 0x0c, 0x98, 0x7f, 0x03, 0xbf, 0x2f,
-	0x90, 0xae, 0xd7, 0x23, 0x8f, 0xfb, 0x98, 0x92, 0x47, 0xf8, 0x54, 0x1a, 0xbc, 0xae, 0x19, 0x24,
-	0x0d, 0x39, 0xb8, 0xa1, 0x0d, 0x06, 0x98, 0x52, 0x12, 0x7a, 0x72, 0xec, 0x0b, 0xda, 0x58, 0x74,
-	0x4c, 0xe8, 0xe1, 0x91, 0x1c, 0x6a, 0xca, 0xa1, 0x87, 0x83, 0xa1, 0xdf, 0x23, 0x03, 0xbe, 0x90,
-	0x48, 0xfc, 0x95, 0x16, 0x57, 0x98, 0x45, 0x30, 0x8a, 0x8e, 0xf8, 0x1f, 0xd9, 0xf9, 0xed, 0x97,
-	0x72, 0x79, 0x80, 0x23, 0xd2, 0xe9, 0x91, 0x07, 0xae, 0xe7, 0x52, 0xd7, 0xf7, 0x22, 0xb5, 0x2d,
-	0x27, 0xb9, 0x3e, 0xdb, 0x24, 0xd3, 0xf1, 0x69, 0xfd, 0xaa, 0x04, 0x8b, 0xf7, 0xfc, 0x63, 0xd7,
-	0x21, 0x0f, 0x47, 0x24, 0xa2, 0x68, 0x0d, 0x2a, 0xdc, 0xc6, 0x34, 0x9a, 0xc6, 0x66, 0xdd, 0x11,
-	0x0f, 0xac, 0x77, 0xe0, 0x0e, 0x5d, 0x6a, 0x16, 0x9b, 0xc6, 0xe6, 0xb2, 0x23, 0x1e, 0x10, 0x82,
-	0x72, 0x44, 0x49, 0x60, 0x96, 0x9a, 0xc6, 0x66, 0xc9, 0xe1, 0x6d, 0xb4, 0x01, 0x0b, 0xae, 0x47,
-	0x49, 0x78, 0x82, 0x07, 0x66, 0x9d, 0xf7, 0xa7, 0xcf, 0xe8, 0x26, 0xd4, 0x22, 0x8a, 0x43, 0xba,
-	0x1f, 0x99, 0xe5, 0xa6, 0xb1, 0xb9, 0xd8, 0xdd, 0x68, 0x8b, 0x38, 0xb6, 0x93, 0x38, 0xb6, 0xf7,
-	0x93, 0x38, 0xda, 0x0b, 0x4f, 0x62, 0xab, 0xf0, 0xe9, 0x9f, 0x2c, 0xc3, 0x49, 0x40, 0x68, 0x1b,
-	0x2a, 0xc4, 0xeb, 0xed, 0x47, 0x66, 0x65, 0x0e, 0xb4, 0x80, 0xa0, 0x6b, 0x50, 0xef, 0xb9, 0x21,
-	0x39, 0x64, 0x9c, 0x99, 0xd5, 0xa6, 0xb1, 0xb9, 0xd2, 0xbd, 0xd2, 0x4e, 0xc3, 0xbe, 0x93, 0x0c,
-	0x39, 0x13, 0x2b, 0xb6, 0xbc, 0x00, 0xd3, 0x23, 0xb3, 0xc6, 0x99, 0xe0, 0x6d, 0xd4, 0x82, 0x6a,
-	0x74, 0x84, 0xc3, 0x5e, 0x64, 0x2e, 0x34, 0x4b, 0x9b, 0x75, 0x1b, 0xce, 0x63, 0x4b, 0xf6, 0x38,
-	0xf2, 0x17, 0xfd, 0x08, 0xca, 0xc1, 0x00, 0x7b, 0x26, 0x70, 0x2f, 0x57, 0xdb, 0x0a, 0xe7, 0xf7,
-	0x07, 0xd8, 0xb3, 0xdf, 0x1f, 0xc7, 0xd6, 0xbb, 0xaa, 0x14, 0x42, 0xfc, 0x00, 0x7b, 0xb8, 0x33,
-	0xf0, 0x8f, 0xdd, 0xce, 0xc9, 0x56, 0x47, 0x8d, 0x24, 0x9b, 0xa8, 0xfd, 0x11, 0x9b, 0x80, 0x41,
-	0x1d, 0x3e, 0x71, 0xeb, 0xf7, 0x45, 0x40, 0x2c, 0x66, 0x77, 0xbd, 0x88, 0x62, 0x8f, 0xbe, 0x4a,
-	0xe8, 0x6e, 0x40, 0x95, 0x49, 0x66, 0x3f, 0xe2, 0xc1, 0x9b, 0x95, 0x4b, 0x89, 0xd1, 0xc9, 0x2c,
-	0xcf, 0x45, 0x66, 0x25, 0x97, 0xcc, 0xea, 0x4b, 0xc9, 0xac, 0xfd, 0xa7, 0xc8, 0x34, 0xa1, 0xcc,
-	0x9e, 0xd0, 0x2a, 0x94, 0x42, 0xfc, 0x88, 0x73, 0xb7, 0xe4, 0xb0, 0x66, 0xeb, 0xac, 0x0c, 0x4b,
-	0x42, 0x1a, 0x51, 0xe0, 0x7b, 0x11, 0x61, 0xfe, 0xee, 0xf1, 0xda, 0x24, 0x18, 0x96, 0xfe, 0xf2,
-	0x1e, 0x47, 0x8e, 0xa0, 0x0f, 0xa0, 0xbc, 0x83, 0x29, 0xe6, 0x6c, 0x2f, 0x76, 0xd7, 0x54, 0x7f,
-	0xd9, 0x5c, 0x6c, 0xcc, 0x5e, 0x67, 0x84, 0x9e, 0xc7, 0xd6, 0x4a, 0x0f, 0x53, 0xfc, 0x55, 0x7f,
-	0xe8, 0x52, 0x32, 0x0c, 0xe8, 0xa9, 0xc3, 0x91, 0xe8, 0x5d, 0xa8, 0xdf, 0x0e, 0x43, 0x3f, 0xdc,
-	0x3f, 0x0d, 0x08, 0x8f, 0x4e, 0xdd, 0xbe, 0x7a, 0x1e, 0x5b, 0x57, 0x48, 0xd2, 0xa9, 0x20, 0x26,
-	0x96, 0xe8, 0xcb, 0x50, 0xe1, 0x0f, 0x3c, 0x1e, 0x75, 0xfb, 0xca, 0x79, 0x6c, 0xbd, 0xc6, 0x21,
-	0x8a, 0xb9, 0xb0, 0xd0, 0xc3, 0x57, 0x99, 0x29, 0x7c, 0x69, 0x16, 0x55, 0xd5, 0x2c, 0x32, 0xa1,
-	0x76, 0x42, 0xc2, 0x88, 0x4d, 0x53, 0xe3, 0xfd, 0xc9, 0x23, 0xba, 0x05, 0xc0, 0x88, 0x71, 0x23,
-	0xea, 0x1e, 0x32, 0xad, 0x30, 0x32, 0x96, 0xdb, 0xa2, 0x14, 0x3a, 0x24, 0x1a, 0x0d, 0xa8, 0x8d,
-	0x24, 0x0b, 0x8a, 0xa1, 0xa3, 0xb4, 0xd1, 0x2f, 0x0d, 0xa8, 0xed, 0x12, 0xdc, 0x23, 0x61, 0x64,
-	0xd6, 0x9b, 0xa5, 0xcd, 0xc5, 0xee, 0x97, 0xda, 0x6a, 0xdd, 0xbb, 0x1f, 0xfa, 0x43, 0x42, 0x8f,
-	0xc8, 0x28, 0x4a, 0x02, 0x24, 0xac, 0x6d, 0x6f, 0x1c, 0x5b, 0x64, 0xc6, 0x94, 0x98, 0xa9, 0xdc,
-	0x5e, 0xf8, 0xaa, 0xf3, 0xd8, 0x32, 0xbe, 0xe6, 0x24, 0x5e, 0xa2, 0x2e, 0x2c, 0x3c, 0xc2, 0xa1,
-	0xe7, 0x7a, 0xfd, 0xc8, 0x04, 0x9e, 0xd1, 0xeb, 0xe7, 0xb1, 0x85, 0x92, 0x3e, 0x25, 0x10, 0xa9,
-	0x5d, 0xeb, 0x8f, 0x06, 0x7c, 0x8e, 0x25, 0xc6, 0x1e, 0xf3, 0x27, 0x52, 0xa4, 0x3c, 0xc4, 0xf4,
-	0xf0, 0xc8, 0x34, 0xd8, 0x34, 0x8e, 0x78, 0x50, 0xeb, 0x67, 0xf1, 0xdf, 0xaa, 0x9f, 0xa5, 0xf9,
-	0xeb, 0x67, 0xa2, 0xdf, 0x72, 0xae, 0x7e, 0x2b, 0x17, 0xe9, 0xb7, 0xf5, 0xb3, 0x92, 0xa8, 0x55,
-	0xc9, 0xfa, 0xe6, 0x90, 0xd2, 0x9d, 0x54, 0x4a, 0x25, 0xee, 0x6d, 0x9a, 0xa1, 0x62, 0xae, 0xbb,
-	0x3d, 0xe2, 0x51, 0xf7, 0x81, 0x4b, 0xc2, 0x97, 0x08, 0x4a, 0xc9, 0xd2, 0x92, 0x9e, 0xa5, 0x6a,
-	0x8a, 0x95, 0x2f, 0x45, 0x8a, 0xe9, 0xba, 0xaa, 0xbc, 0x82, 0xae, 0x5a, 0x7f, 0x2f, 0xc2, 0x3a,
-	0x8b, 0xc8, 0x3d, 0x7c, 0x40, 0x06, 0xdf, 0xc3, 0xc3, 0x39, 0xa3, 0xf2, 0x96, 0x12, 0x95, 0xba,
-	0x8d, 0xfe, 0xcf, 0xfa, 0x6c, 0xac, 0xff, 0xdc, 0x80, 0x85, 0xe4, 0x03, 0x80, 0xda, 0x00, 0x02,
-	0xc6, 0x6b, 0xbc, 0xe0, 0x7a, 0x85, 0x81, 0xc3, 0xb4, 0xd7, 0x51, 0x2c, 0xd0, 0x8f, 0xa1, 0x2a,
-	0x9e, 0xa4, 0x16, 0xae, 0x2a, 0x5a, 0xa0, 0x21, 0xc1, 0xc3, 0x5b, 0x3d, 0x1c, 0x50, 0x12, 0xda,
-	0xef, 0x33, 0x2f, 0xc6, 0xb1, 0xf5, 0xf6, 0x45, 0x2c, 0x25, 0xfb, 0x4f, 0x89, 0x63, 0xf1, 0x15,
-	0xef, 0x74, 0xe4, 0x1b, 0x5a, 0x9f, 0x18, 0xb0, 0xca, 0x1c, 0x65, 0xd4, 0xa4, 0x89, 0xb1, 0x03,
-	0x0b, 0xa1, 0x6c, 0x73, 0x77, 0x17, 0xbb, 0xad, 0xb6, 0x4e, 0x6b, 0x0e, 0x95, 0x76, 0xf9, 0x49,
-	0x6c, 0x19, 0x4e, 0x8a, 0x44, 0x5b, 0x1a, 0x8d, 0xc5, 0x3c, 0x1a, 0x19, 0xa4, 0xa0, 0x11, 0xf7,
-	0xdb, 0x22, 0xa0, 0xbb, 0x6c, 0xff, 0xce, 0xf2, 0x6f, 0x92, 0xaa, 0x8f, 0x33, 0x1e, 0xbd, 0x31,
-	0x21, 0x25, 0x6b, 0x6f, 0xdf, 0x1c, 0xc7, 0xd6, 0xf6, 0x4b, 0x72, 0xe7, 0x5f, 0xe0, 0x95, 0x55,
-	0xa8, 0xe9, 0x5b, 0xbc, 0x0c, 0xe9, 0xdb, 0xfa, 0x75, 0x11, 0x56, 0x7e, 0xe0, 0x0f, 0x46, 0x43,
-	0x92, 0xd2, 0x17, 0x64, 0xe8, 0x33, 0x27, 0xf4, 0xe9, 0xb6, 0xf6, 0xf6, 0x38, 0xb6, 0xae, 0xcf,
-	0x4a, 0x9d, 0x8e, 0xbd, 0xd4, 0xb4, 0xfd, 0xb5, 0x08, 0x6b, 0xfb, 0x7e, 0xf0, 0xdd, 0x3d, 0x7e,
-	0xc6, 0x53, 0xca, 0xe4, 0x51, 0x86, 0xbc, 0xb5, 0x09, 0x79, 0x0c, 0xf1, 0x21, 0xa6, 0xa1, 0xfb,
-	0xd8, 0xbe, 0x3e, 0x8e, 0xad, 0xee, 0xac, 0xc4, 0x4d, 0x70, 0x97, 0x99, 0x34, 0x6d, 0x0f, 0x54,
-	0x9a, 0x71, 0x0f, 0xf4, 0x8f, 0x22, 0xac, 0x7f, 0x34, 0xc2, 0x1e, 0x75, 0x07, 0x44, 0x90, 0x9d,
-	0x52, 0xfd, 0x93, 0x0c, 0xd5, 0x8d, 0x09, 0xd5, 0x3a, 0x46, 0x92, 0xfe, 0xc1, 0x38, 0xb6, 0x6e,
-	0xcc, 0x4a, 0x7a, 0xde, 0x0c, 0xff, 0x73, 0xf4, 0xff, 0xa6, 0x08, 0x2b, 0x7b, 0x62, 0xd7, 0x96,
-	0x2c, 0xfc, 0x24, 0x87, 0x76, 0xf5, 0x12, 0x25, 0x38, 0x68, 0xeb, 0x88, 0xf9, 0x8a, 0x84, 0x8e,
-	0xbd, 0xd4, 0x45, 0xe2, 0x0f, 0x45, 0x58, 0xdf, 0x21, 0x94, 0x1c, 0x52, 0xd2, 0xbb, 0xe3, 0x92,
-	0x81, 0x42, 0xe2, 0xc7, 0x46, 0x86, 0xc5, 0xa6, 0x72, 0xcc, 0xca, 0x05, 0xd9, 0xf6, 0x38, 0xb6,
-	0x6e, 0xce, 0xca, 0x63, 0xfe, 0x1c, 0x97, 0x9a, 0xcf, 0xdf, 0x15, 0xe1, 0xf3, 0xe2, 0x88, 0x2e,
-	0x6e, 0xdd, 0x26, 0x74, 0xfe, 0x34, 0xc3, 0xa6, 0xa5, 0x96, 0x82, 0x1c, 0x88, 0x7d, 0x6b, 0x1c,
-	0x5b, 0xdf, 0x9a, 0xbd, 0x16, 0xe4, 0x4c, 0xf1, 0x5f, 0x93, 0x9b, 0x7c, 0xb7, 0x3f, 0x6f, 0x6e,
-	0xea, 0xa0, 0x57, 0xcb, 0x4d, 0x7d, 0x8e, 0x4b, 0xcd, 0xe7, 0x5f, 0xaa, 0xb0, 0xcc, 0xb3, 0x24,
-	0xa5, 0xf1, 0x2b, 0x20, 0x8f, 0x47, 0x92, 0x43, 0x94, 0x1c, 0xa9, 0xc3, 0xe0, 0xb0, 0xbd, 0x27,
-	0x0f, 0x4e, 0xc2, 0x02, 0xbd, 0x07, 0xd5, 0x88, 0x1f, 0x5c, 0xe5, 0xce, 0xb7, 0x31, 0x7d, 0x37,
-	0xa4, 0x1f, 0x91, 0x77, 0x0b, 0x8e, 0xb4, 0x47, 0x37, 0xa0, 0x3a, 0xe0, 0x2c, 0xca, 0x83, 0x7b,
-	0x6b, 0x1a, 0x99, 0x3d, 0xca, 0x31, 0xb4, 0xc0, 0xa0, 0xeb, 0x50, 0xe1, 0x5b, 0x6c, 0x79, 0xe7,
-	0xaa, 0xbd, 0x36, 0xbb, 0xd1, 0xdd, 0x2d, 0x38, 0xc2, 0x1c, 0x75, 0xa1, 0x1c, 0x84, 0xfe, 0x50,
-	0x1e, 0x77, 0xde, 0x98, 0x7e, 0xa7, 0x7a, 0x3e, 0xd8, 0x2d, 0x38, 0xdc, 0x16, 0xbd, 0x03, 0xb5,
-	0x88, 0x1f, 0x2c, 0x22, 0x7e, 0x51, 0xc4, 0x76, 0x95, 0x53, 0x30, 0x05, 0x92, 0x98, 0xa2, 0x77,
-	0xa0, 0x7a, 0xc2, 0xb7, 0x8d, 0xf2, 0x96, 0x6f, 0x43, 0x05, 0xe9, 0x1b, 0x4a, 0xb6, 0x2e, 0x61,
-	0x8b, 0xee, 0xc0, 0x12, 0xf5, 0x83, 0xe3, 0x64, 0x77, 0x26, 0x2f, 0x99, 0x9a, 0x2a, 0x36, 0x6f,
-	0xf7, 0xb6, 0x5b, 0x70, 0x34, 0x1c, 0xba, 0x0f, 0xab, 0x0f, 0xb5, 0x6d, 0x00, 0x89, 0xf8, 0xcd,
-	0xf5, 0x14, 0xcf, 0xf9, 0x1b, 0x94, 0xdd, 0x82, 0x93, 0x41, 0xa3, 0x1d, 0x58, 0x89, 0xb4, 0x2f,
-	0x9c, 0xbc, 0x0a, 0xd6, 0xd6, 0xa5, 0x7f, 0x03, 0x77, 0x0b, 0xce, 0x14, 0x06, 0xdd, 0x83, 0x95,
-	0x9e, 0x56, 0xdf, 0xcd, 0xc5, 0xac, 0x57, 0xf9, 0x5f, 0x00, 0x36, 0x9b, 0x8e, 0x45, 0xdf, 0x87,
-	0xd5, 0x60, 0xaa, 0xb6, 0x99, 0x4b, 0x7c, 0xbe, 0x2f, 0xea, 0xab, 0xcc, 0x29, 0x82, 0x6c, 0x91,
-	0xd3, 0x60, 0xd5, 0x3d, 0x21, 0x71, 0x73, 0xf9, 0x62, 0xf7, 0xf4, 0x22, 0xa0, 0xba, 0x27, 0x46,
-	0x6c, 0x98, 0x94, 0xa3, 0xd6, 0x27, 0x55, 0x58, 0x92, 0x32, 0x13, 0xb7, 0x61, 0xdf, 0x48, 0x95,
-	0x23, 0x54, 0xf6, 0xe6, 0x45, 0xca, 0xe1, 0xe6, 0x8a, 0x70, 0xbe, 0x9e, 0x0a, 0x47, 0x48, 0x6e,
-	0x7d, 0x52, 0xe2, 0xf8, 0x7b, 0x15, 0x84, 0x14, 0xcb, 0x56, 0x22, 0x16, 0xa1, 0xb4, 0xd7, 0xf3,
-	0xcf, 0x94, 0x09, 0x4a, 0x2a, 0x65, 0x1b, 0x6a, 0xae, 0xb8, 0x8a, 0xcf, 0xd3, 0x58, 0xf6, 0xa6,
-	0x9e, 0xe5, 0xbe, 0x04, 0xa0, 0xad, 0x89, 0x62, 0x84, 0xd0, 0xae, 0x66, 0x15, 0x93, 0x82, 0x12,
-	0xc1, 0x5c, 0x4b, 0x05, 0x53, 0x95, 0x98, 0xcc, 0xf9, 0x2b, 0x5d, 0x98, 0x54, 0xcb, 0x6d, 0x58,
-	0x4e, 0xf2, 0x8b, 0x0f, 0x49, 0xb9, 0xbc, 0x79, 0xd1, 0xb6, 0x2e, 0xc1, 0xeb, 0x28, 0x74, 0x37,
-	0x93, 0x94, 0xf5, 0xe9, 0x4f, 0xf1, 0x74, 0x4a, 0x26, 0x33, 0x4d, 0x67, 0xe4, 0x77, 0xe0, 0xb5,
-	0x49, 0x52, 0x09, 0x9f, 0x20, 0xbb, 0xc3, 0xd7, 0xd2, 0x31, 0x99, 0x6a, 0x1a, 0xa8, 0xba, 0x25,
-	0x93, 0x71, 0xf1, 0x22, 0xb7, 0x92, 0x54, 0xcc, 0xb8, 0x25, 0x06, 0xd0, 0x2e, 0x2c, 0x0c, 0x09,
-	0xc5, 0x3d, 0x4c, 0xb1, 0x59, 0xe3, 0x9f, 0xa5, 0xb7, 0x32, 0x02, 0x91, 0xe8, 0xf6, 0x87, 0xd2,
-	0xf0, 0xb6, 0x47, 0xc3, 0x53, 0x79, 0x77, 0x91, 0xa2, 0x37, 0xbe, 0x09, 0xcb, 0x9a, 0x01, 0x5a,
-	0x85, 0xd2, 0x31, 0x49, 0xfe, 0x3d, 0xc3, 0x9a, 0x68, 0x0d, 0x2a, 0x27, 0x78, 0x30, 0x22, 0x3c,
-	0x3f, 0xeb, 0x8e, 0x78, 0xd8, 0x2e, 0xbe, 0x67, 0xd8, 0x75, 0xa8, 0x85, 0xe2, 0x2d, 0x76, 0xff,
-	0xe9, 0xb3, 0x46, 0xe1, 0xb3, 0x67, 0x8d, 0xc2, 0x8b, 0x67, 0x0d, 0xe3, 0xe3, 0xb3, 0x86, 0xf1,
-	0x8b, 0xb3, 0x86, 0xf1, 0xe4, 0xac, 0x61, 0x3c, 0x3d, 0x6b, 0x18, 0x7f, 0x3e, 0x6b, 0x18, 0x7f,
-	0x3b, 0x6b, 0x14, 0x5e, 0x9c, 0x35, 0x8c, 0x4f, 0x9f, 0x37, 0x0a, 0x4f, 0x9f, 0x37, 0x0a, 0x9f,
-	0x3d, 0x6f, 0x14, 0x7e, 0x78, 0x6d, 0xee, 0x2f, 0xe4, 0x41, 0x95, 0x33, 0xb5, 0xf5, 0xcf, 0x00,
-	0x00, 0x00, 0xff, 0xff, 0x96, 0xad, 0x71, 0x3d, 0xdd, 0x1d, 0x00, 0x00,
+	0xdb, 0xb6, 0x38, 0x70, 0x41, 0xe5, 0x99, 0xca, 0xb8, 0xf1, 0x4c, 0x77, 0xa7, 0xab, 0xc6, 0x89,
+	0x25, 0x84, 0xf6, 0x1f, 0x58, 0xb1, 0x7f, 0x05, 0xe2, 0xc6, 0x85, 0x13, 0x27, 0x8e, 0x59, 0x24,
+	0xa4, 0x1c, 0x57, 0x23, 0x31, 0x10, 0x07, 0x21, 0xe4, 0xd3, 0x4a, 0xdc, 0x10, 0x07, 0x54, 0x1f,
+	0xdd, 0x53, 0x35, 0x3d, 0x26, 0xe3, 0x20, 0x0e, 0x86, 0xbd, 0x78, 0xea, 0xe3, 0xfd, 0xaa, 0x5f,
+	0xff, 0xde, 0xfb, 0xbd, 0xae, 0x2a, 0xc3, 0x9b, 0xd1, 0x51, 0xaf, 0xf5, 0x70, 0x48, 0x62, 0x9f,
+	0xc4, 0xe2, 0xf7, 0x24, 0xc6, 0x41, 0x8f, 0x68, 0xcd, 0x66, 0x14, 0x87, 0x2c, 0x44, 0x30, 0x19,
+	0xd9, 0x68, 0xf7, 0x7c, 0x76, 0x38, 0x3c, 0x68, 0x76, 0xc2, 0x41, 0xab, 0x17, 0xf6, 0xc2, 0x56,
+	0x2f, 0x0c, 0x7b, 0x7d, 0x82, 0x23, 0x9f, 0xaa, 0x66, 0x2b, 0x8e, 0x3a, 0x2d, 0xca, 0x30, 0x1b,
+	0x52, 0x89, 0xdf, 0x58, 0xe3, 0x86, 0xa2, 0x29, 0x20, 0x6a, 0xd4, 0x51, 0xe6, 0xa2, 0x77, 0x30,
+	0x7c, 0xd0, 0x62, 0xfe, 0x80, 0x50, 0x86, 0x07, 0x51, 0x62, 0xc0, 0xfd, 0xeb, 0x87, 0x3d, 0x89,
+	0xf4, 0x83, 0x2e, 0x79, 0xdc, 0xc3, 0x8c, 0x3c, 0xc2, 0x27, 0xca, 0xe0, 0x55, 0xc3, 0x20, 0x69,
+	0xa8, 0xc9, 0x0d, 0x63, 0x32, 0xc2, 0x8c, 0x91, 0x38, 0x50, 0x73, 0x5f, 0x32, 0xe6, 0xe8, 0x11,
+	0x61, 0x9d, 0x43, 0x35, 0x55, 0x57, 0x53, 0x0f, 0xfb, 0x83, 0xb0, 0x4b, 0xfa, 0xe2, 0x45, 0xa8,
+	0xfc, 0xab, 0x2c, 0xae, 0x70, 0x8b, 0x68, 0x48, 0x0f, 0xc5, 0x1f, 0x35, 0xf8, 0xdd, 0x17, 0x72,
+	0x79, 0x80, 0x29, 0x69, 0x75, 0xc9, 0x03, 0x3f, 0xf0, 0x99, 0x1f, 0x06, 0x54, 0x6f, 0xab, 0x45,
+	0xae, 0xcf, 0xb7, 0xc8, 0x74, 0x7c, 0x1a, 0x9f, 0x14, 0x60, 0xf1, 0x5e, 0x78, 0xe4, 0x7b, 0xe4,
+	0xe1, 0x90, 0x50, 0x86, 0xd6, 0xa0, 0x24, 0x6c, 0x6c, 0xab, 0x6e, 0x6d, 0x56, 0x3d, 0xd9, 0xe1,
+	0xa3, 0x7d, 0x7f, 0xe0, 0x33, 0x3b, 0x5f, 0xb7, 0x36, 0x97, 0x3d, 0xd9, 0x41, 0x08, 0x8a, 0x94,
+	0x91, 0xc8, 0x2e, 0xd4, 0xad, 0xcd, 0x82, 0x27, 0xda, 0x68, 0x03, 0x16, 0xfc, 0x80, 0x91, 0xf8,
+	0x18, 0xf7, 0xed, 0xaa, 0x18, 0x4f, 0xfb, 0xe8, 0x26, 0x54, 0x28, 0xc3, 0x31, 0xdb, 0xa7, 0x76,
+	0xb1, 0x6e, 0x6d, 0x2e, 0xb6, 0x37, 0x9a, 0x32, 0x8e, 0xcd, 0x24, 0x8e, 0xcd, 0xfd, 0x24, 0x8e,
+	0xee, 0xc2, 0x93, 0xb1, 0x93, 0xfb, 0xf8, 0x4f, 0x8e, 0xe5, 0x25, 0x20, 0xb4, 0x0d, 0x25, 0x12,
+	0x74, 0xf7, 0xa9, 0x5d, 0xba, 0x00, 0x5a, 0x42, 0xd0, 0x35, 0xa8, 0x76, 0xfd, 0x98, 0x74, 0x38,
+	0x67, 0x76, 0xb9, 0x6e, 0x6d, 0xae, 0xb4, 0xaf, 0x34, 0xd3, 0xb0, 0xef, 0x24, 0x53, 0xde, 0xc4,
+	0x8a, 0xbf, 0x5e, 0x84, 0xd9, 0xa1, 0x5d, 0x11, 0x4c, 0x88, 0x36, 0x6a, 0x40, 0x99, 0x1e, 0xe2,
+	0xb8, 0x4b, 0xed, 0x85, 0x7a, 0x61, 0xb3, 0xea, 0xc2, 0xd9, 0xd8, 0x51, 0x23, 0x9e, 0xfa, 0x45,
+	0x3f, 0x81, 0x62, 0xd4, 0xc7, 0x81, 0x0d, 0xc2, 0xcb, 0xd5, 0xa6, 0xc6, 0xf9, 0xfd, 0x3e, 0x0e,
+	0xdc, 0x77, 0x47, 0x63, 0xe7, 0x6d, 0x5d, 0x0a, 0x31, 0x7e, 0x80, 0x03, 0xdc, 0xea, 0x87, 0x47,
+	0x7e, 0xeb, 0x78, 0xab, 0xa5, 0x47, 0x92, 0x2f, 0xd4, 0xfc, 0x80, 0x2f, 0xc0, 0xa1, 0x9e, 0x58,
+	0x18, 0xad, 0x43, 0xb9, 0x33, 0x8c, 0x69, 0x18, 0xdb, 0x8b, 0xc2, 0x35, 0xd5, 0x6b, 0xfc, 0x3e,
+	0x0f, 0x88, 0xc7, 0xf2, 0x6e, 0x40, 0x19, 0x0e, 0xd8, 0xcb, 0x84, 0xf4, 0x06, 0x94, 0xb9, 0x94,
+	0xf6, 0xa9, 0x08, 0xea, 0xbc, 0x1c, 0x2b, 0x8c, 0x49, 0x72, 0xf1, 0x42, 0x24, 0x97, 0x66, 0x92,
+	0x5c, 0x7e, 0x21, 0xc9, 0x95, 0xff, 0x12, 0xc9, 0x0d, 0x1b, 0x8a, 0xbc, 0x87, 0x56, 0xa1, 0x10,
+	0xe3, 0x47, 0x82, 0xbb, 0x25, 0x8f, 0x37, 0x1b, 0xff, 0x28, 0xc2, 0x92, 0x94, 0x0c, 0x8d, 0xc2,
+	0x80, 0x12, 0xee, 0xef, 0x9e, 0xa8, 0x59, 0x92, 0x61, 0xe5, 0xaf, 0x18, 0xf1, 0xd4, 0x0c, 0x7a,
+	0x0f, 0x8a, 0x3b, 0x98, 0x61, 0xc1, 0xf6, 0x62, 0x7b, 0x4d, 0xf7, 0x97, 0xaf, 0xc5, 0xe7, 0xdc,
+	0x75, 0x4e, 0xe8, 0xd9, 0xd8, 0x59, 0xe9, 0x62, 0x86, 0xbf, 0x1e, 0x0e, 0x7c, 0x46, 0x06, 0x11,
+	0x3b, 0xf1, 0x04, 0x12, 0xbd, 0x0d, 0xd5, 0xdb, 0x71, 0x1c, 0xc6, 0xfb, 0x27, 0x11, 0x11, 0xd1,
+	0xa9, 0xba, 0x57, 0xcf, 0xc6, 0xce, 0x15, 0x92, 0x0c, 0x6a, 0x88, 0x89, 0x25, 0xfa, 0x2a, 0x94,
+	0x44, 0x47, 0xc4, 0xa3, 0xea, 0x5e, 0x39, 0x1b, 0x3b, 0xaf, 0x08, 0x88, 0x66, 0x2e, 0x2d, 0xcc,
+	0xf0, 0x95, 0xe6, 0x0a, 0x5f, 0x9a, 0x45, 0x65, 0x3d, 0x8b, 0x6c, 0xa8, 0x1c, 0x93, 0x98, 0xf2,
+	0x65, 0x2a, 0x62, 0x3c, 0xe9, 0xa2, 0x5b, 0x00, 0x9c, 0x18, 0x9f, 0x32, 0xbf, 0xc3, 0x35, 0xc4,
+	0xc9, 0x58, 0x6e, 0xca, 0x12, 0xe9, 0x11, 0x3a, 0xec, 0x33, 0x17, 0x29, 0x16, 0x34, 0x43, 0x4f,
+	0x6b, 0xa3, 0x5f, 0x5b, 0x50, 0xd9, 0x25, 0xb8, 0x4b, 0x62, 0x6a, 0x57, 0xeb, 0x85, 0xcd, 0xc5,
+	0xf6, 0x57, 0x9a, 0x7a, 0x3d, 0xbc, 0x1f, 0x87, 0x03, 0xc2, 0x0e, 0xc9, 0x90, 0x26, 0x01, 0x92,
+	0xd6, 0x6e, 0x30, 0x1a, 0x3b, 0x64, 0xce, 0x94, 0x98, 0xab, 0x0c, 0x9f, 0xfb, 0xa8, 0xb3, 0xb1,
+	0x63, 0x7d, 0xc3, 0x4b, 0xbc, 0x44, 0x6d, 0x58, 0x78, 0x84, 0xe3, 0xc0, 0x0f, 0x7a, 0xd4, 0x06,
+	0x91, 0xd1, 0xeb, 0x67, 0x63, 0x07, 0x25, 0x63, 0x5a, 0x20, 0x52, 0xbb, 0x73, 0x35, 0xfe, 0x47,
+	0x0b, 0xbe, 0xc0, 0x13, 0x66, 0x8f, 0xfb, 0x49, 0x35, 0x89, 0x0f, 0x30, 0xeb, 0x1c, 0xda, 0x16,
+	0x5f, 0xde, 0x93, 0x1d, 0xbd, 0xde, 0xe6, 0xff, 0xa3, 0x7a, 0x5b, 0xb8, 0x78, 0xbd, 0x4d, 0x74,
+	0x5d, 0x9c, 0xa9, 0xeb, 0xd2, 0x79, 0xba, 0x6e, 0xfc, 0xa2, 0x20, 0x6b, 0x58, 0xf2, 0x7e, 0x17,
+	0x90, 0xd8, 0x9d, 0x54, 0x62, 0x05, 0xe1, 0x6d, 0x9a, 0xb9, 0x72, 0xad, 0xbb, 0x5d, 0x12, 0x30,
+	0xff, 0x81, 0x4f, 0xe2, 0x17, 0x08, 0x4d, 0xcb, 0xde, 0x82, 0x99, 0xbd, 0x7a, 0xea, 0x15, 0x2f,
+	0x45, 0xea, 0x99, 0x7a, 0x2b, 0xbd, 0x84, 0xde, 0x1a, 0x7f, 0xcf, 0xc3, 0x3a, 0x8f, 0xc8, 0x3d,
+	0x7c, 0x40, 0xfa, 0x3f, 0xc0, 0x83, 0x0b, 0x46, 0xe5, 0x0d, 0x2d, 0x2a, 0x55, 0x17, 0x7d, 0xce,
+	0xfa, 0x7c, 0xac, 0xff, 0xd2, 0x82, 0x85, 0xe4, 0xc3, 0x80, 0x9a, 0x00, 0x12, 0x26, 0x6a, 0xbf,
+	0xe4, 0x7a, 0x85, 0x83, 0xe3, 0x74, 0xd4, 0xd3, 0x2c, 0xd0, 0x4f, 0xa1, 0x2c, 0x7b, 0x4a, 0x0b,
+	0x57, 0x35, 0x2d, 0xb0, 0x98, 0xe0, 0xc1, 0xad, 0x2e, 0x8e, 0x18, 0x89, 0xdd, 0x77, 0xb9, 0x17,
+	0xa3, 0xb1, 0xf3, 0xe6, 0x79, 0x2c, 0x25, 0xfb, 0x55, 0x85, 0xe3, 0xf1, 0x95, 0xcf, 0xf4, 0xd4,
+	0x13, 0x1a, 0x1f, 0x59, 0xb0, 0xca, 0x1d, 0xe5, 0xd4, 0xa4, 0x89, 0xb1, 0x03, 0x0b, 0xb1, 0x6a,
+	0x0b, 0x77, 0x17, 0xdb, 0x8d, 0xa6, 0x49, 0xeb, 0x0c, 0x2a, 0xdd, 0xe2, 0x93, 0xb1, 0x63, 0x79,
+	0x29, 0x12, 0x6d, 0x19, 0x34, 0xe6, 0x67, 0xd1, 0xc8, 0x21, 0x39, 0x83, 0xb8, 0xdf, 0xe5, 0x01,
+	0xdd, 0xe5, 0xfb, 0x7d, 0x9e, 0x7f, 0x93, 0x54, 0x7d, 0x9c, 0xf1, 0xe8, 0xb5, 0x09, 0x29, 0x59,
+	0x7b, 0xf7, 0xe6, 0x68, 0xec, 0x6c, 0xbf, 0x20, 0x77, 0xfe, 0x0d, 0x5e, 0x7b, 0x0b, 0x3d, 0x7d,
+	0xf3, 0x97, 0x21, 0x7d, 0x1b, 0xbf, 0xc9, 0xc3, 0xca, 0x8f, 0xc2, 0xfe, 0x70, 0x40, 0x52, 0xfa,
+	0xa2, 0x0c, 0x7d, 0xf6, 0x84, 0x3e, 0xd3, 0xd6, 0xdd, 0x1e, 0x8d, 0x9d, 0xeb, 0xf3, 0x52, 0x67,
+	0x62, 0x2f, 0x35, 0x6d, 0x7f, 0xcd, 0xc3, 0xda, 0x7e, 0x18, 0x7d, 0x7f, 0x4f, 0x9c, 0x09, 0xb5,
+	0x32, 0x79, 0x98, 0x21, 0x6f, 0x6d, 0x42, 0x1e, 0x47, 0xbc, 0x8f, 0x59, 0xec, 0x3f, 0x76, 0xaf,
+	0x8f, 0xc6, 0x4e, 0x7b, 0x5e, 0xe2, 0x26, 0xb8, 0xcb, 0x4c, 0x9a, 0xb1, 0x37, 0x2a, 0xcc, 0xb7,
+	0x37, 0x6a, 0xfc, 0x33, 0x0f, 0xeb, 0x1f, 0x0c, 0x71, 0xc0, 0xfc, 0x3e, 0x91, 0x64, 0xa7, 0x54,
+	0xff, 0x2c, 0x43, 0x75, 0x6d, 0x42, 0xb5, 0x89, 0x51, 0xa4, 0xbf, 0x37, 0x1a, 0x3b, 0x37, 0xe6,
+	0x25, 0x7d, 0xd6, 0x0a, 0xff, 0x77, 0xf4, 0xff, 0x36, 0x0f, 0x2b, 0x7b, 0x72, 0xd7, 0x96, 0xbc,
+	0xf8, 0xf1, 0x0c, 0xda, 0xf5, 0x4b, 0x97, 0xe8, 0xa0, 0x69, 0x22, 0x2e, 0x56, 0x24, 0x4c, 0xec,
+	0xa5, 0x2e, 0x12, 0x7f, 0xc8, 0xc3, 0xfa, 0x0e, 0x61, 0xa4, 0xc3, 0x48, 0xf7, 0x8e, 0x4f, 0xfa,
+	0x1a, 0x89, 0x1f, 0x5a, 0x19, 0x16, 0xeb, 0xda, 0xf1, 0x6b, 0x26, 0xc8, 0x75, 0x47, 0x63, 0xe7,
+	0xe6, 0xbc, 0x3c, 0xce, 0x5e, 0xe3, 0x52, 0xf3, 0xf9, 0x49, 0x1e, 0xbe, 0x28, 0x8f, 0xee, 0xf2,
+	0x96, 0x6e, 0x42, 0xe7, 0xcf, 0x33, 0x6c, 0x3a, 0x7a, 0x29, 0x98, 0x01, 0x71, 0x6f, 0x8d, 0xc6,
+	0xce, 0x77, 0xe6, 0xaf, 0x05, 0x33, 0x96, 0xf8, 0x9f, 0xc9, 0x4d, 0xb1, 0xdb, 0xbf, 0x68, 0x6e,
+	0x9a, 0xa0, 0x97, 0xcb, 0x4d, 0x73, 0x8d, 0x4b, 0xcd, 0xe7, 0x5f, 0xca, 0xb0, 0x2c, 0xb2, 0x24,
+	0xa5, 0xf1, 0x6b, 0xa0, 0x8e, 0x47, 0x8a, 0x43, 0x94, 0x1c, 0xa9, 0xe3, 0xa8, 0xd3, 0xdc, 0x53,
+	0x07, 0x27, 0x69, 0x81, 0xde, 0x81, 0x32, 0x15, 0x07, 0x57, 0xb5, 0xf3, 0xad, 0x4d, 0xdf, 0x19,
+	0x99, 0x47, 0xe4, 0xdd, 0x9c, 0xa7, 0xec, 0xd1, 0x0d, 0x28, 0xf7, 0x05, 0x8b, 0xea, 0xe0, 0xde,
+	0x98, 0x46, 0x66, 0x8f, 0x72, 0x1c, 0x2d, 0x31, 0xe8, 0x3a, 0x94, 0xc4, 0x16, 0x5b, 0xdd, 0xd1,
+	0x1a, 0x8f, 0xcd, 0x6e, 0x74, 0x77, 0x73, 0x9e, 0x34, 0x47, 0x6d, 0x28, 0x46, 0x71, 0x38, 0x50,
+	0xc7, 0x9d, 0xd7, 0xa6, 0x9f, 0xa9, 0x9f, 0x0f, 0x76, 0x73, 0x9e, 0xb0, 0x45, 0x6f, 0x41, 0x85,
+	0x8a, 0x83, 0x05, 0x15, 0x17, 0x48, 0x7c, 0x57, 0x39, 0x05, 0xd3, 0x20, 0x89, 0x29, 0x7a, 0x0b,
+	0xca, 0xc7, 0x62, 0xdb, 0xa8, 0x6e, 0xff, 0x36, 0x74, 0x90, 0xb9, 0xa1, 0xe4, 0xef, 0x25, 0x6d,
+	0xd1, 0x1d, 0x58, 0x62, 0x61, 0x74, 0x94, 0xec, 0xce, 0xd4, 0xe5, 0x53, 0x5d, 0xc7, 0xce, 0xda,
+	0xbd, 0xed, 0xe6, 0x3c, 0x03, 0x87, 0xee, 0xc3, 0xea, 0x43, 0x63, 0x1b, 0x40, 0xa8, 0xb8, 0xe9,
+	0x9e, 0xe2, 0x79, 0xf6, 0x06, 0x65, 0x37, 0xe7, 0x65, 0xd0, 0x68, 0x07, 0x56, 0xa8, 0xf1, 0x85,
+	0x53, 0x57, 0xc7, 0xc6, 0x7b, 0x99, 0xdf, 0xc0, 0xdd, 0x9c, 0x37, 0x85, 0x41, 0xf7, 0x60, 0xa5,
+	0x6b, 0xd4, 0x77, 0x71, 0x73, 0x34, 0xe5, 0xd5, 0xec, 0x2f, 0x00, 0x5f, 0xcd, 0xc4, 0xa2, 0x1f,
+	0xc2, 0x6a, 0x34, 0x55, 0xdb, 0xec, 0x25, 0xb1, 0xde, 0x97, 0xcd, 0xb7, 0x9c, 0x51, 0x04, 0xf9,
+	0x4b, 0x4e, 0x83, 0x75, 0xf7, 0xa4, 0xc4, 0xed, 0xe5, 0xf3, 0xdd, 0x33, 0x8b, 0x80, 0xee, 0x9e,
+	0x9c, 0x71, 0x61, 0x52, 0x8e, 0x1a, 0x1f, 0x95, 0x61, 0x49, 0xc9, 0x4c, 0xde, 0x86, 0x7d, 0x2b,
+	0x55, 0x8e, 0x54, 0xd9, 0xeb, 0xe7, 0x29, 0x47, 0x98, 0x6b, 0xc2, 0xf9, 0x66, 0x2a, 0x1c, 0x29,
+	0xb9, 0xf5, 0x49, 0x89, 0x13, 0xcf, 0xd5, 0x10, 0x4a, 0x2c, 0x5b, 0x89, 0x58, 0xa4, 0xd2, 0x5e,
+	0x9d, 0x7d, 0xa6, 0x4c, 0x50, 0x4a, 0x29, 0xdb, 0x50, 0xf1, 0xe5, 0x15, 0xfd, 0x2c, 0x8d, 0x65,
+	0x6f, 0xf0, 0x79, 0xee, 0x2b, 0x00, 0xda, 0x9a, 0x28, 0x46, 0x0a, 0xed, 0x6a, 0x56, 0x31, 0x29,
+	0x28, 0x11, 0xcc, 0xb5, 0x54, 0x30, 0x65, 0x85, 0xc9, 0x9c, 0xbf, 0xd2, 0x17, 0x53, 0x6a, 0xb9,
+	0x0d, 0xcb, 0x49, 0x7e, 0x89, 0x29, 0x25, 0x97, 0xd7, 0xcf, 0xdb, 0xd6, 0x25, 0x78, 0x13, 0x85,
+	0xee, 0x66, 0x92, 0xb2, 0x3a, 0xfd, 0x29, 0x9e, 0x4e, 0xc9, 0x64, 0xa5, 0xe9, 0x8c, 0xfc, 0x1e,
+	0xbc, 0x32, 0x49, 0x2a, 0xe9, 0x13, 0x64, 0x77, 0xf8, 0x46, 0x3a, 0x26, 0x4b, 0x4d, 0x03, 0x75,
+	0xb7, 0x54, 0x32, 0x2e, 0x9e, 0xe7, 0x56, 0x92, 0x8a, 0x19, 0xb7, 0xe4, 0x04, 0xda, 0x85, 0x85,
+	0x01, 0x61, 0xb8, 0x8b, 0x19, 0xb6, 0x2b, 0xe2, 0xb3, 0xf4, 0x46, 0x46, 0x20, 0x0a, 0xdd, 0x7c,
+	0x5f, 0x19, 0xde, 0x0e, 0x58, 0x7c, 0xa2, 0xee, 0x2e, 0x52, 0xf4, 0xc6, 0xb7, 0x61, 0xd9, 0x30,
+	0x40, 0xab, 0x50, 0x38, 0x22, 0xc9, 0xbf, 0x6d, 0x78, 0x13, 0xad, 0x41, 0xe9, 0x18, 0xf7, 0x87,
+	0x44, 0xe4, 0x67, 0xd5, 0x93, 0x9d, 0xed, 0xfc, 0x3b, 0x96, 0x5b, 0x85, 0x4a, 0x2c, 0x9f, 0xe2,
+	0xf6, 0x9e, 0x3e, 0xab, 0xe5, 0x3e, 0x7d, 0x56, 0xcb, 0x7d, 0xf6, 0xac, 0x66, 0x7d, 0x78, 0x5a,
+	0xb3, 0x7e, 0x75, 0x5a, 0xb3, 0x9e, 0x9c, 0xd6, 0xac, 0xa7, 0xa7, 0x35, 0xeb, 0xcf, 0xa7, 0x35,
+	0xeb, 0x6f, 0xa7, 0xb5, 0xdc, 0x67, 0xa7, 0x35, 0xeb, 0xe3, 0xe7, 0xb5, 0xdc, 0xd3, 0xe7, 0xb5,
+	0xdc, 0xa7, 0xcf, 0x6b, 0xb9, 0x1f, 0x5f, 0xbb, 0xf0, 0x17, 0xf2, 0xa0, 0x2c, 0x98, 0xda, 0xfa,
+	0x57, 0x00, 0x00, 0x00, 0xff, 0xff, 0x5c, 0x9a, 0x0c, 0x1e, 0x0d, 0x1e, 0x00, 0x00,
 }
 
 func (this *LokiRequest) Equal(that interface{}) bool {
@@ -1627,6 +1646,9 @@ func (this *LokiRequest) Equal(that interface{}) bool {
 	} else if !this.Plan.Equal(*that1.Plan) {
 		return false
 	}
+	if this.Cursor != that1.Cursor {
+		return false
+	}
 	return true
 }
 func (this *LokiInstantRequest) Equal(that interface{}) bool {
@@ -1763,6 +1785,9 @@ func (this *LokiResponse) Equal(that interface{}) bool {
 			return false
 		}
 	}
+	if this.Cursor != that1.Cursor {
+		return false
+	}
 	return true
 }
 func (this *LokiSeriesRequest) Equal(that interface{}) bool {
@@ -2869,7 +2894,7 @@ func (this *LokiRequest) GoString() string {
 	if this == nil {
 		return "nil"
 	}
-	s := make([]string, 0, 14)
+	s := make([]string, 0, 15)
 	s = append(s, "&queryrange.LokiRequest{")
 	s = append(s, "Query: "+fmt.Sprintf("%#v", this.Query)+",\n")
 	s = append(s, "Limit: "+fmt.Sprintf("%#v", this.Limit)+",\n")
@@ -2881,6 +2906,7 @@ func (this *LokiRequest) GoString() string {
 	s = append(s, "Path: "+fmt.Sprintf("%#v", this.Path)+",\n")
 	s = append(s, "Shards: "+fmt.Sprintf("%#v", this.Shards)+",\n")
 	s = append(s, "Plan: "+fmt.Sprintf("%#v", this.Plan)+",\n")
+	s = append(s, "Cursor: "+fmt.Sprintf("%#v", this.Cursor)+",\n")
 	s = append(s, "}")
 	return strings.Join(s, "")
 }
@@ -2914,7 +2940,7 @@ func (this *LokiResponse) GoString() string {
 	if this == nil {
 		return "nil"
 	}
-	s := make([]string, 0, 14)
+	s := make([]string, 0, 15)
 	s = append(s, "&queryrange.LokiResponse{")
 	s = append(s, "Status: "+fmt.Sprintf("%#v", this.Status)+",\n")
 	s = append(s, "Data: "+strings.Replace(this.Data.GoString(), `&`, ``, 1)+",\n")
@@ -2926,6 +2952,7 @@ func (this *LokiResponse) GoString() string {
 	s = append(s, "Statistics: "+strings.Replace(this.Statistics.GoString(), `&`, ``, 1)+",\n")
 	s = append(s, "Headers: "+fmt.Sprintf("%#v", this.Headers)+",\n")
 	s = append(s, "Warnings: "+fmt.Sprintf("%#v", this.Warnings)+",\n")
+	s = append(s, "Cursor: "+fmt.Sprintf("%#v", this.Cursor)+",\n")
 	s = append(s, "}")
 	return strings.Join(s, "")
 }
@@ -3335,6 +3362,13 @@ func (m *LokiRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if len(m.Cursor) > 0 {
+		i -= len(m.Cursor)
+		copy(dAtA[i:], m.Cursor)
+		i = encodeVarintQueryrange(dAtA, i, uint64(len(m.Cursor)))
+		i--
+		dAtA[i] = 0x5a
+	}
 	if m.Plan != nil {
 		{
 			size := m.Plan.Size()
@@ -3535,6 +3569,13 @@ func (m *LokiResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if len(m.Cursor) > 0 {
+		i -= len(m.Cursor)
+		copy(dAtA[i:], m.Cursor)
+		i = encodeVarintQueryrange(dAtA, i, uint64(len(m.Cursor)))
+		i--
+		dAtA[i] = 0x5a
+	}
 	if len(m.Warnings) > 0 {
 		for iNdEx := len(m.Warnings) - 1; iNdEx >= 0; iNdEx-- {
 			i -= len(m.Warnings[iNdEx])
@@ -4907,6 +4948,10 @@ func (m *LokiRequest) Size() (n int) {
 		l = m.Plan.Size()
 		n += 1 + l + sovQueryrange(uint64(l))
 	}
+	l = len(m.Cursor)
+	if l > 0 {
+		n += 1 + l + sovQueryrange(uint64(l))
+	}
 	return n
 }
 
@@ -5001,6 +5046,10 @@ func (m *LokiResponse) Size() (n int) {
 			n += 1 + l + sovQueryrange(uint64(l))
 		}
 	}
+	l = len(m.Cursor)
+	if l > 0 {
+		n += 1 + l + sovQueryrange(uint64(l))
+	}
 	return n
 }
 
@@ -5613,6 +5662,7 @@ func (this *LokiRequest) String() string {
 		`Shards:` + fmt.Sprintf("%v", this.Shards) + `,`,
 		`Interval:` + fmt.Sprintf("%v", this.Interval) + `,`,
 		`Plan:` + fmt.Sprintf("%v", this.Plan) + `,`,
+		`Cursor:` + fmt.Sprintf("%v", this.Cursor) + `,`,
 		`}`,
 	}, "")
 	return s
@@ -5658,6 +5708,7 @@ func (this *LokiResponse) String() string {
 		`Statistics:` + strings.Replace(strings.Replace(fmt.Sprintf("%v", this.Statistics), "Result", "stats.Result", 1), `&`, ``, 1) + `,`,
 		`Headers:` + fmt.Sprintf("%v", this.Headers) + `,`,
 		`Warnings:` + fmt.Sprintf("%v", this.Warnings) + `,`,
+		`Cursor:` + fmt.Sprintf("%v", this.Cursor) + `,`,
 		`}`,
 	}, "")
 	return s
@@ -6384,6 +6435,38 @@ func (m *LokiRequest) Unmarshal(dAtA []byte) error {
 				return err
 			}
 			iNdEx = postIndex
+		case 11:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Cursor", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQueryrange
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthQueryrange
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthQueryrange
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Cursor = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipQueryrange(dAtA[iNdEx:])
@@ -7065,6 +7148,38 @@ func (m *LokiResponse) Unmarshal(dAtA []byte) error {
 			}
 			m.Warnings = append(m.Warnings, string(dAtA[iNdEx:postIndex]))
 			iNdEx = postIndex
+		case 11:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Cursor", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowQueryrange
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthQueryrange
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthQueryrange
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Cursor = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipQueryrange(dAtA[iNdEx:])
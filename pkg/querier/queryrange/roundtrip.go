@@ -57,6 +57,7 @@ type Config struct {
 	SeriesCacheConfig            SeriesCacheConfig        `yaml:"series_results_cache" doc:"description=If series_results_cache is not configured and cache_series_results is true, the config for the results cache is used."`
 	CacheLabelResults            bool                     `yaml:"cache_label_results"`
 	LabelsCacheConfig            LabelsCacheConfig        `yaml:"label_results_cache" doc:"description=If label_results_cache is not configured and cache_label_results is true, the config for the results cache is used."`
+	LogResultCacheConfig         LogResultCacheConfig     `yaml:"log_results_cache" doc:"description=Controls which log query results are cached when cache_results is true."`
 }
 
 // RegisterFlags adds the flags required to configure this flag set.
@@ -73,6 +74,7 @@ func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
 	cfg.SeriesCacheConfig.RegisterFlags(f)
 	f.BoolVar(&cfg.CacheLabelResults, "querier.cache-label-results", true, "Cache label query results.")
 	cfg.LabelsCacheConfig.RegisterFlags(f)
+	cfg.LogResultCacheConfig.RegisterFlags(f)
 }
 
 // Validate validates the config.
@@ -134,6 +136,8 @@ func NewMiddleware(
 	retentionEnabled bool,
 	registerer prometheus.Registerer,
 	metricsNamespace string,
+	budget *QueryBudget,
+	active *ActiveQueries,
 ) (base.Middleware, Stopper, error) {
 	metrics := NewMetrics(registerer, metricsNamespace)
 
@@ -236,6 +240,8 @@ func NewMiddleware(
 	}
 
 	return base.MiddlewareFunc(func(next base.Handler) base.Handler {
+		next = NewActiveQueriesDownstreamMiddleware(active).Wrap(next)
+
 		var (
 			metricRT         = metricsTripperware.Wrap(next)
 			limitedRT        = limitedTripperware.Wrap(next)
@@ -249,7 +255,12 @@ func NewMiddleware(
 			detectedLabelsRT = next // TODO(shantanu): add middlewares
 		)
 
-		return newRoundTripper(log, next, limitedRT, logFilterRT, metricRT, seriesRT, labelsRT, instantRT, statsRT, seriesVolumeRT, detectedFieldsRT, detectedLabelsRT, limits)
+		rt := newRoundTripper(log, next, limitedRT, logFilterRT, metricRT, seriesRT, labelsRT, instantRT, statsRT, seriesVolumeRT, detectedFieldsRT, detectedLabelsRT, limits)
+		return base.MergeMiddlewares(
+			NewActiveQueriesMiddleware(active),
+			NewQueryBudgetMiddleware(budget, schema.Configs, engineOpts, log, statsRT),
+			NewCursorMiddleware(limits),
+		).Wrap(rt)
 	}), StopperWrapper{resultsCache, statsCache, volumeCache}, nil
 }
 
@@ -468,10 +479,11 @@ func NewLogFilterTripperware(cfg Config, engineOpts logql.EngineOpts, log log.Lo
 				limits,
 				c,
 				func(_ context.Context, r base.Request) bool {
-					return !r.GetCachingOptions().Disabled
+					return !r.GetCachingOptions().Disabled && !hasStatefulStage(r)
 				},
 				cfg.Transformer,
 				metrics.LogResultCacheMetrics,
+				cfg.LogResultCacheConfig,
 			)
 			queryRangeMiddleware = append(
 				queryRangeMiddleware,
@@ -707,7 +719,7 @@ func NewMetricTripperware(cfg Config, engineOpts logql.EngineOpts, log log.Logge
 			extractor,
 			cacheGenNumLoader,
 			func(_ context.Context, r base.Request) bool {
-				return !r.GetCachingOptions().Disabled
+				return !r.GetCachingOptions().Disabled && !hasStatefulStage(r)
 			},
 			func(ctx context.Context, tenantIDs []string, r base.Request) int {
 				return MinWeightedParallelism(
@@ -829,7 +841,7 @@ func NewInstantMetricTripperware(
 			c,
 			cacheGenNumLoader,
 			func(_ context.Context, r base.Request) bool {
-				return !r.GetCachingOptions().Disabled
+				return !r.GetCachingOptions().Disabled && !hasStatefulStage(r)
 			},
 			func(ctx context.Context, tenantIDs []string, r base.Request) int {
 				return MinWeightedParallelism(
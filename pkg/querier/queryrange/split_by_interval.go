@@ -189,8 +189,8 @@ func (h *splitByInterval) Do(ctx context.Context, r queryrangebase.Request) (que
 		interval = validation.SmallestPositiveNonZeroDurationPerTenant(tenantIDs, h.limits.QuerySplitDuration)
 	}
 
-	// skip split by if unset
-	if interval == 0 {
+	// skip split by if unset, or if the query has stateful stages which need all the lines of the query at once.
+	if interval == 0 || hasStatefulStage(r) {
 		return h.next.Do(ctx, r)
 	}
 
@@ -276,3 +276,20 @@ func maxRangeVectorAndOffsetDuration(expr syntax.Expr) (time.Duration, time.Dura
 	})
 	return maxRVDuration, maxOffset, nil
 }
+
+// hasStatefulStage returns true if the query of r has stages, like distinct, keeping lines depending on the
+// lines kept before. Those queries can't be split nor cached by parts.
+func hasStatefulStage(r queryrangebase.Request) bool {
+	var expr syntax.Expr
+	switch req := r.(type) {
+	case *LokiRequest:
+		if req.Plan != nil {
+			expr = req.Plan.AST
+		}
+	case *LokiInstantRequest:
+		if req.Plan != nil {
+			expr = req.Plan.AST
+		}
+	}
+	return expr != nil && syntax.HasStatefulStage(expr)
+}
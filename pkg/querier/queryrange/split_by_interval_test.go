@@ -1537,6 +1537,38 @@ func Test_splitByInterval_Do(t *testing.T) {
 				},
 			},
 		},
+		{
+			"stateful stages aren't split",
+			&LokiRequest{
+				StartTs:   time.Unix(0, 0),
+				EndTs:     time.Unix(0, (4 * time.Hour).Nanoseconds()),
+				Query:     `{foo="bar"} | distinct level`,
+				Limit:     1000,
+				Step:      1,
+				Direction: logproto.FORWARD,
+				Path:      "/api/prom/query_range",
+				Plan: &plan.QueryPlan{
+					AST: syntax.MustParseExpr(`{foo="bar"} | distinct level`),
+				},
+			},
+			&LokiResponse{
+				Status:    loghttp.QueryStatusSuccess,
+				Direction: logproto.FORWARD,
+				Limit:     1000,
+				Version:   1,
+				Data: LokiData{
+					ResultType: loghttp.ResultTypeStream,
+					Result: []logproto.Stream{
+						{
+							Labels: `{foo="bar", level="debug"}`,
+							Entries: []logproto.Entry{
+								{Timestamp: time.Unix(0, 0), Line: fmt.Sprintf("%d", 0)},
+							},
+						},
+					},
+				},
+			},
+		},
 	}
 
 	for _, tt := range tests {
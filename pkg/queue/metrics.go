@@ -6,9 +6,10 @@ import (
 )
 
 type Metrics struct {
-	queueLength       *prometheus.GaugeVec   // Per tenant
-	discardedRequests *prometheus.CounterVec // Per tenant
-	enqueueCount      *prometheus.CounterVec // Per tenant and level
+	queueLength         *prometheus.GaugeVec   // Per tenant
+	priorityQueueLength *prometheus.GaugeVec   // Per priority
+	discardedRequests   *prometheus.CounterVec // Per tenant
+	enqueueCount        *prometheus.CounterVec // Per tenant and level
 }
 
 func NewMetrics(registerer prometheus.Registerer, metricsNamespace, subsystem string) *Metrics {
@@ -19,6 +20,12 @@ func NewMetrics(registerer prometheus.Registerer, metricsNamespace, subsystem st
 			Name:      "queue_length",
 			Help:      "Number of queries in the queue.",
 		}, []string{"user"}),
+		priorityQueueLength: promauto.With(registerer).NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Subsystem: subsystem,
+			Name:      "priority_queue_length",
+			Help:      "Number of queries in the queue per priority class.",
+		}, []string{"priority"}),
 		discardedRequests: promauto.With(registerer).NewCounterVec(prometheus.CounterOpts{
 			Namespace: metricsNamespace,
 			Subsystem: subsystem,
@@ -0,0 +1,129 @@
+package queue
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/grafana/dskit/tenant"
+
+	"github.com/grafana/loki/v3/pkg/util/httpreq"
+)
+
+// Priority is the priority class of a request in the queue.
+// Within a tenant queue, requests of a higher priority class are dequeued before requests of a lower class.
+// Fairness between tenants is not affected by priorities.
+type Priority int
+
+const (
+	PriorityLow Priority = iota - 1
+	PriorityNormal
+	PriorityHigh
+)
+
+// priorities holds all priority classes, from the highest to the lowest.
+var priorities = []Priority{PriorityHigh, PriorityNormal, PriorityLow}
+
+// maxPriorityStreak is the maximum number of requests that are dequeued in a row from a higher priority class
+// while requests of a lower class are waiting. Once reached, a request of a lower class is dequeued.
+// This prevents lower priority classes from being starved.
+const maxPriorityStreak = 10
+
+// ParsePriority parses the name of a priority class.
+func ParsePriority(s string) (Priority, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "high":
+		return PriorityHigh, nil
+	case "normal", "":
+		return PriorityNormal, nil
+	case "low":
+		return PriorityLow, nil
+	default:
+		return PriorityNormal, fmt.Errorf("invalid priority %q, must be one of high, normal or low", s)
+	}
+}
+
+func (p Priority) String() string {
+	switch p {
+	case PriorityHigh:
+		return "high"
+	case PriorityLow:
+		return "low"
+	default:
+		return "normal"
+	}
+}
+
+// PriorityLimits are the per-tenant limits used to determine the priority class of a request.
+type PriorityLimits interface {
+	// HighPriorityQuerySources returns the query sources whose requests are enqueued with high priority.
+	HighPriorityQuerySources(user string) []string
+	// LowPriorityQuerySources returns the query sources whose requests are enqueued with low priority.
+	LowPriorityQuerySources(user string) []string
+	// MaxQueryPriority returns the highest priority a request can ask for explicitly.
+	MaxQueryPriority(user string) string
+}
+
+// QueryPriority returns the priority class a request of the given tenant is enqueued with.
+// A valid explicit priority, e.g. from the X-Loki-Query-Priority header, takes precedence, lowered to the max query
+// priority of the tenant. Otherwise the source query tag is matched against the high and low priority query sources
+// of the tenant. For multi-tenant requests the lowest priority of all tenants is used.
+func QueryPriority(limits PriorityLimits, tenantID string, priority string, queryTags string) Priority {
+	tenantIDs, err := tenant.TenantIDsFromOrgID(tenantID)
+	if err != nil {
+		return PriorityNormal
+	}
+
+	if priority != "" {
+		if p, err := ParsePriority(priority); err == nil {
+			if maxPriority := maxQueryPriority(limits, tenantIDs); p > maxPriority {
+				return maxPriority
+			}
+			return p
+		}
+	}
+
+	source := httpreq.QueryTagValue(queryTags, "source")
+	if limits == nil || source == "" {
+		return PriorityNormal
+	}
+
+	result := PriorityHigh
+	for _, id := range tenantIDs {
+		p := PriorityNormal
+		switch {
+		case containsFold(limits.HighPriorityQuerySources(id), source):
+			p = PriorityHigh
+		case containsFold(limits.LowPriorityQuerySources(id), source):
+			p = PriorityLow
+		}
+		if p < result {
+			result = p
+		}
+	}
+	return result
+}
+
+// maxQueryPriority returns the highest priority a request of all the given tenants can ask for explicitly.
+func maxQueryPriority(limits PriorityLimits, tenantIDs []string) Priority {
+	if limits == nil {
+		return PriorityNormal
+	}
+	result := PriorityHigh
+	for _, id := range tenantIDs {
+		// An invalid limit is rejected when the limits are loaded.
+		p, _ := ParsePriority(limits.MaxQueryPriority(id))
+		if p < result {
+			result = p
+		}
+	}
+	return result
+}
+
+func containsFold(values []string, s string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
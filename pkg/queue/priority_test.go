@@ -0,0 +1,194 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/util/constants"
+)
+
+func TestParsePriority(t *testing.T) {
+	for _, tc := range []struct {
+		in       string
+		expected Priority
+		err      bool
+	}{
+		{in: "", expected: PriorityNormal},
+		{in: "normal", expected: PriorityNormal},
+		{in: "High", expected: PriorityHigh},
+		{in: " low ", expected: PriorityLow},
+		{in: "urgent", expected: PriorityNormal, err: true},
+	} {
+		t.Run(tc.in, func(t *testing.T) {
+			p, err := ParsePriority(tc.in)
+			if tc.err {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, tc.expected, p)
+		})
+	}
+}
+
+func TestQueue_DequeueByPriority(t *testing.T) {
+	metrics := NewMetrics(nil, constants.Loki, "query_scheduler")
+	queue := NewRequestQueue(100, 0, noQueueLimits, metrics)
+	queue.RegisterConsumerConnection("querier")
+
+	for _, p := range []Priority{PriorityLow, PriorityNormal, PriorityHigh} {
+		for i := 0; i < 2; i++ {
+			require.NoError(t, queue.EnqueueWithPriority("tenant", nil, p, fmt.Sprintf("%s-%d", p, i), nil))
+		}
+	}
+	require.Equal(t, float64(2), testutil.ToFloat64(metrics.priorityQueueLength.WithLabelValues("high")))
+
+	var dequeued []Request
+	idx := StartIndex
+	for i := 0; i < 6; i++ {
+		req, newIdx, err := queue.Dequeue(context.Background(), idx, "querier")
+		require.NoError(t, err)
+		dequeued = append(dequeued, req)
+		idx = newIdx.ReuseLastIndex()
+	}
+
+	require.Equal(t, []Request{"high-0", "high-1", "normal-0", "normal-1", "low-0", "low-1"}, dequeued)
+	require.Equal(t, float64(0), testutil.ToFloat64(metrics.priorityQueueLength.WithLabelValues("high")))
+	require.Equal(t, float64(0), testutil.ToFloat64(metrics.priorityQueueLength.WithLabelValues("low")))
+}
+
+func TestQueue_PriorityStarvation(t *testing.T) {
+	queue := NewRequestQueue(100, 0, noQueueLimits, NewMetrics(nil, constants.Loki, "query_scheduler"))
+	queue.RegisterConsumerConnection("querier")
+
+	require.NoError(t, queue.EnqueueWithPriority("tenant", nil, PriorityLow, "low", nil))
+	require.NoError(t, queue.Enqueue("tenant", []string{"actor"}, "normal", nil))
+	for i := 0; i < 3*maxPriorityStreak; i++ {
+		require.NoError(t, queue.EnqueueWithPriority("tenant", nil, PriorityHigh, "high", nil))
+	}
+
+	positions := map[Request]int{}
+	idx := StartIndex
+	for i := 0; i < 3*maxPriorityStreak+2; i++ {
+		req, newIdx, err := queue.Dequeue(context.Background(), idx, "querier")
+		require.NoError(t, err)
+		positions[req] = i
+		idx = newIdx.ReuseLastIndex()
+	}
+
+	// lower priority classes are served in turns once the streak limit of higher priority requests is reached.
+	require.Equal(t, maxPriorityStreak, positions["normal"])
+	require.Equal(t, 2*maxPriorityStreak+1, positions["low"])
+}
+
+func TestQueryPriority(t *testing.T) {
+	limits := &mockPriorityLimits{
+		high:        []string{"ruler"},
+		low:         []string{"batch"},
+		maxPriority: map[string]string{"a": "high", "b": "normal"},
+	}
+
+	for name, tt := range map[string]struct {
+		limits   PriorityLimits
+		tenantID string
+		priority string
+		tags     string
+		expected Priority
+	}{
+		"nil limits": {
+			tags:     "source=ruler",
+			expected: PriorityNormal,
+		},
+		"explicit priority": {
+			limits:   limits,
+			tenantID: "a",
+			priority: "low",
+			tags:     "source=ruler",
+			expected: PriorityLow,
+		},
+		"explicit priority lowered to the max query priority": {
+			limits:   limits,
+			tenantID: "b",
+			priority: "high",
+			expected: PriorityNormal,
+		},
+		"explicit priority lowered to the max query priority of all tenants": {
+			limits:   limits,
+			tenantID: "a|b",
+			priority: "high",
+			expected: PriorityNormal,
+		},
+		"explicit priority within the max query priority": {
+			limits:   limits,
+			tenantID: "a",
+			priority: "high",
+			expected: PriorityHigh,
+		},
+		"explicit priority without max query priority": {
+			limits:   limits,
+			tenantID: "c",
+			priority: "high",
+			expected: PriorityNormal,
+		},
+		"explicit priority with nil limits": {
+			tenantID: "a",
+			priority: "high",
+			expected: PriorityNormal,
+		},
+		"invalid explicit priority falls back to sources": {
+			limits:   limits,
+			tenantID: "a",
+			priority: "urgent",
+			tags:     "Source=Ruler",
+			expected: PriorityHigh,
+		},
+		"high priority source": {
+			limits:   limits,
+			tenantID: "a",
+			tags:     "source=ruler,feature=beta",
+			expected: PriorityHigh,
+		},
+		"low priority source": {
+			limits:   limits,
+			tenantID: "a|b",
+			tags:     "source=batch",
+			expected: PriorityLow,
+		},
+		"unknown source": {
+			limits:   limits,
+			tenantID: "a",
+			tags:     "source=grafana",
+			expected: PriorityNormal,
+		},
+		"no source": {
+			limits:   limits,
+			tenantID: "a",
+			expected: PriorityNormal,
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, tt.expected, QueryPriority(tt.limits, tt.tenantID, tt.priority, tt.tags))
+		})
+	}
+}
+
+type mockPriorityLimits struct {
+	high, low   []string
+	maxPriority map[string]string
+}
+
+func (l *mockPriorityLimits) HighPriorityQuerySources(_ string) []string {
+	return l.high
+}
+
+func (l *mockPriorityLimits) LowPriorityQuerySources(_ string) []string {
+	return l.low
+}
+
+func (l *mockPriorityLimits) MaxQueryPriority(user string) string {
+	return l.maxPriority[user]
+}
@@ -83,9 +83,15 @@ func NewRequestQueue(maxOutstandingPerTenant int, forgetDelay time.Duration, lim
 	return q
 }
 
-// Enqueue puts the request into the queue.
+// Enqueue puts the request into the queue with normal priority.
 // If request is successfully enqueued, successFn is called with the lock held, before any querier can receive the request.
 func (q *RequestQueue) Enqueue(tenant string, path []string, req Request, successFn func()) error {
+	return q.EnqueueWithPriority(tenant, path, PriorityNormal, req, successFn)
+}
+
+// EnqueueWithPriority puts the request into the queue of the given priority class.
+// If request is successfully enqueued, successFn is called with the lock held, before any querier can receive the request.
+func (q *RequestQueue) EnqueueWithPriority(tenant string, path []string, priority Priority, req Request, successFn func()) error {
 	q.mtx.Lock()
 	defer q.mtx.Unlock()
 
@@ -93,7 +99,7 @@ func (q *RequestQueue) Enqueue(tenant string, path []string, req Request, succes
 		return ErrStopped
 	}
 
-	queue, err := q.queues.getOrAddQueue(tenant, path)
+	queue, err := q.queues.getOrAddQueue(tenant, path, priority)
 	if err != nil {
 		return fmt.Errorf("no queue found: %w", err)
 	}
@@ -115,6 +121,7 @@ func (q *RequestQueue) Enqueue(tenant string, path []string, req Request, succes
 	select {
 	case queue.Chan() <- req:
 		q.metrics.queueLength.WithLabelValues(tenant).Inc()
+		q.metrics.priorityQueueLength.WithLabelValues(priority.String()).Inc()
 		q.metrics.enqueueCount.WithLabelValues(tenant, fmt.Sprint(len(path))).Inc()
 		q.cond.Broadcast()
 		// Call this function while holding a lock. This guarantees that no querier can fetch the request before function returns.
@@ -228,7 +235,7 @@ FindQueue:
 		return nil, last, queue.Name(), false, ErrQueueWasRemoved
 	}
 	// Pick next request from the queue.
-	request := queue.Dequeue()
+	request, priority := dequeueWithPriority(queue)
 	isTenantQueueEmpty := queue.Len() == 0
 	if isTenantQueueEmpty {
 		q.queues.deleteQueue(tenant)
@@ -236,6 +243,7 @@ FindQueue:
 
 	q.queues.perUserQueueLen.Dec(tenant)
 	q.metrics.queueLength.WithLabelValues(tenant).Dec()
+	q.metrics.priorityQueueLength.WithLabelValues(priority.String()).Dec()
 
 	// Tell close() we've processed a request.
 	q.cond.Broadcast()
@@ -243,6 +251,13 @@ FindQueue:
 	return request, last, queue.Name(), isTenantQueueEmpty, nil
 }
 
+func dequeueWithPriority(queue Queue) (Request, Priority) {
+	if tq, ok := queue.(*tenantQueue); ok {
+		return tq.dequeueWithPriority()
+	}
+	return queue.Dequeue(), PriorityNormal
+}
+
 func (q *RequestQueue) forgetDisconnectedConsumers(_ context.Context) error {
 	q.mtx.Lock()
 	defer q.mtx.Unlock()
@@ -92,8 +92,17 @@ type Mapable interface {
 }
 
 type tenantQueue struct {
+	// queue of requests with normal priority
 	*TreeQueue
 
+	// queues of requests with any other priority, created on demand.
+	prioritized map[Priority]*TreeQueue
+
+	// number of requests dequeued in a row from a higher priority class while requests of a lower class were waiting.
+	streak int
+	// number of times a lower priority class was served because the streak limit was reached.
+	rescued int
+
 	// If not nil, only these consumers can handle user requests. If nil, all consumers can.
 	// We set this to nil if number of available consumers <= MaxConsumers.
 	consumers map[string]struct{}
@@ -103,6 +112,81 @@ type tenantQueue struct {
 	seed int64
 }
 
+func (q *tenantQueue) getOrAddPriorityQueue(priority Priority, size int) *TreeQueue {
+	if q.prioritized == nil {
+		q.prioritized = make(map[Priority]*TreeQueue, len(priorities)-1)
+	}
+	pq, ok := q.prioritized[priority]
+	if !ok {
+		pq = newTreeQueue(size, q.name)
+		q.prioritized[priority] = pq
+	}
+	return pq
+}
+
+func (q *tenantQueue) queueFor(priority Priority) *TreeQueue {
+	if priority == PriorityNormal {
+		return q.TreeQueue
+	}
+	return q.prioritized[priority]
+}
+
+// Dequeue implements Queue.
+// It takes the next request from the highest priority class that has pending requests.
+// To avoid starving lower priority classes, after maxPriorityStreak requests were dequeued in a row ahead of them,
+// the next request is taken from one of the lower classes, in a round-robin fashion.
+func (q *tenantQueue) Dequeue() Request {
+	req, _ := q.dequeueWithPriority()
+	return req
+}
+
+func (q *tenantQueue) dequeueWithPriority() (Request, Priority) {
+	// fast path when there are only normal priority requests.
+	if len(q.prioritized) == 0 {
+		q.streak = 0
+		return q.TreeQueue.Dequeue(), PriorityNormal
+	}
+
+	pending := make([]Priority, 0, len(priorities))
+	for _, p := range priorities {
+		if pq := q.queueFor(p); pq != nil && pq.Len() > 0 {
+			pending = append(pending, p)
+		}
+	}
+	if len(pending) == 0 {
+		return nil, PriorityNormal
+	}
+
+	priority := pending[0]
+	switch {
+	case len(pending) == 1:
+		q.streak = 0
+	case q.streak >= maxPriorityStreak:
+		lower := pending[1:]
+		priority = lower[q.rescued%len(lower)]
+		q.rescued++
+		q.streak = 0
+	default:
+		q.streak++
+	}
+
+	req := q.queueFor(priority).Dequeue()
+	if priority != PriorityNormal && q.prioritized[priority].Len() == 0 {
+		delete(q.prioritized, priority)
+	}
+	return req, priority
+}
+
+// Len implements Queue
+// It returns the number of requests of all priority classes.
+func (q *tenantQueue) Len() int {
+	count := q.TreeQueue.Len()
+	for _, pq := range q.prioritized {
+		count += pq.Len()
+	}
+	return count
+}
+
 func newTenantQueues(maxUserQueueSize int, forgetDelay time.Duration, limits Limits) *tenantQueues {
 	mm := &Mapping[*tenantQueue]{}
 	mm.Init(64)
@@ -126,7 +210,7 @@ func (q *tenantQueues) deleteQueue(tenant string) {
 }
 
 // Returns existing or new queue for a tenant.
-func (q *tenantQueues) getOrAddQueue(tenantID string, path []string) (Queue, error) {
+func (q *tenantQueues) getOrAddQueue(tenantID string, path []string, priority Priority) (Queue, error) {
 	// Empty tenant is not allowed, as that would break our tenants list ("" is used for free spot).
 	if tenantID == "" {
 		return nil, fmt.Errorf("empty tenant is not allowed")
@@ -158,6 +242,9 @@ func (q *tenantQueues) getOrAddQueue(tenantID string, path []string) (Queue, err
 		uq.consumers = shuffleConsumersForTenants(uq.seed, consumersToSelect, q.sortedConsumers, nil)
 	}
 
+	if priority != PriorityNormal {
+		return uq.getOrAddPriorityQueue(priority, q.maxUserQueueSize).add(path), nil
+	}
 	if len(path) == 0 {
 		return uq, nil
 	}
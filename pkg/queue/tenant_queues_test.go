@@ -200,7 +200,7 @@ func TestQueuesConsistency(t *testing.T) {
 			for i := 0; i < 10000; i++ {
 				switch r.Int() % 6 {
 				case 0:
-					q, err := uq.getOrAddQueue(generateTenant(r), generateActor(r))
+					q, err := uq.getOrAddQueue(generateTenant(r), generateActor(r), PriorityNormal)
 					assert.NoError(t, err)
 					assert.NotNil(t, q)
 				case 1:
@@ -409,11 +409,11 @@ func generateConsumer(r *rand.Rand) string {
 
 func getOrAdd(t *testing.T, uq *tenantQueues, tenant string) Queue {
 	actor := []string{}
-	q, err := uq.getOrAddQueue(tenant, actor)
+	q, err := uq.getOrAddQueue(tenant, actor, PriorityNormal)
 	assert.NoError(t, err)
 	assert.NotNil(t, q)
 	assert.NoError(t, isConsistent(uq))
-	q2, err := uq.getOrAddQueue(tenant, actor)
+	q2, err := uq.getOrAddQueue(tenant, actor, PriorityNormal)
 	assert.NoError(t, err)
 	assert.Equal(t, q, q2)
 	return q
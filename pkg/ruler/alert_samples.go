@@ -0,0 +1,288 @@
+package ruler
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/grafana/dskit/tenant"
+	"github.com/grafana/dskit/user"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/rules"
+
+	"github.com/grafana/loki/v3/pkg/logql/syntax"
+	"github.com/grafana/loki/v3/pkg/logqlmodel"
+	ruler "github.com/grafana/loki/v3/pkg/ruler/base"
+)
+
+const (
+	// AlertSamplesAnnotation opts an alerting rule into attaching sample log lines to its
+	// firing alerts. Its value is the number of log lines to attach.
+	AlertSamplesAnnotation = "__alert_samples__"
+	// LogSamplesAnnotation is the annotation holding the sample log lines of a firing alert.
+	LogSamplesAnnotation = "log_samples"
+
+	// cached samples of alerts which were not sent for this long are dropped.
+	alertSamplesStaleAfter = time.Hour
+)
+
+// LogSampler is implemented by evaluators which can run log queries.
+type LogSampler interface {
+	// SampleLogs returns the most recent log lines matching the given log query between start and end.
+	SampleLogs(ctx context.Context, qs string, start, end time.Time, limit int) (logqlmodel.Streams, error)
+}
+
+type AlertSamplesConfig struct {
+	MaxLines             int           `yaml:"max_lines"`
+	MaxBytes             int           `yaml:"max_bytes"`
+	QueryTimeout         time.Duration `yaml:"query_timeout"`
+	MaxConcurrentQueries int           `yaml:"max_concurrent_queries"`
+}
+
+func (c *AlertSamplesConfig) RegisterFlags(f *flag.FlagSet) {
+	f.IntVar(&c.MaxLines, "ruler.alert-samples.max-lines", 20, "Maximum number of sample log lines attached to a firing alert of a rule with the '"+AlertSamplesAnnotation+"' annotation. Set 0 to disable attaching sample log lines.")
+	f.IntVar(&c.MaxBytes, "ruler.alert-samples.max-bytes", 4096, "Maximum size in bytes of the sample log lines attached to a firing alert. Lines exceeding the size are dropped, starting with the oldest one.")
+	f.DurationVar(&c.QueryTimeout, "ruler.alert-samples.query-timeout", 10*time.Second, "Timeout of the log query fetching the sample log lines of a firing alert.")
+	f.IntVar(&c.MaxConcurrentQueries, "ruler.alert-samples.max-concurrent-queries", 4, "Maximum number of log queries fetching sample log lines run at once. Alerts starting to fire while the limit is reached are sent without sample log lines until a query slot is available.")
+}
+
+// alertSampler attaches sample log lines to the firing alerts of the rules opting into it.
+// The log lines are queried once, in the background when an alert starts firing, so that
+// sending the notifications never waits for the query. They are attached to every
+// notification sent for the alert once the query completes, until the alert resolves.
+type alertSampler struct {
+	cfg     AlertSamplesConfig
+	sampler LogSampler
+	logger  log.Logger
+
+	mtx   sync.Mutex
+	cache map[uint64]*alertSamples
+
+	// queries bounds the number of log queries run at once.
+	queries chan struct{}
+	wg      sync.WaitGroup
+}
+
+type alertSamples struct {
+	activeAt time.Time
+	lastSent time.Time
+	samples  string
+}
+
+func newAlertSampler(cfg AlertSamplesConfig, sampler LogSampler, logger log.Logger) *alertSampler {
+	return &alertSampler{
+		cfg:     cfg,
+		sampler: sampler,
+		logger:  logger,
+		cache:   map[uint64]*alertSamples{},
+		queries: make(chan struct{}, max(cfg.MaxConcurrentQueries, 1)),
+	}
+}
+
+// NotifyFunc wraps next, attaching sample log lines to the alerts being sent.
+func (s *alertSampler) NotifyFunc(next rules.NotifyFunc) rules.NotifyFunc {
+	return func(ctx context.Context, expr string, alerts ...*rules.Alert) {
+		now := time.Now()
+		for _, alert := range alerts {
+			n := alert.Annotations.Get(AlertSamplesAnnotation)
+			if n == "" {
+				continue
+			}
+			// the annotation only configures the rule and is not sent along with the alert
+			b := labels.NewBuilder(alert.Annotations).Del(AlertSamplesAnnotation)
+
+			if samples := s.samples(ctx, expr, alert, n, now); samples != "" {
+				b.Set(LogSamplesAnnotation, samples)
+			}
+			alert.Annotations = b.Labels()
+		}
+		s.pruneStale(now)
+
+		next(ctx, expr, alerts...)
+	}
+}
+
+func (s *alertSampler) samples(ctx context.Context, expr string, alert *rules.Alert, n string, now time.Time) string {
+	key := alert.Labels.Hash()
+	if !alert.ResolvedAt.IsZero() {
+		s.mtx.Lock()
+		defer s.mtx.Unlock()
+		if cached, ok := s.cache[key]; ok && cached.activeAt.Equal(alert.ActiveAt) {
+			delete(s.cache, key)
+			return cached.samples
+		}
+		return ""
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	cached, ok := s.cache[key]
+	if ok && cached.activeAt.Equal(alert.ActiveAt) {
+		cached.lastSent = now
+		return cached.samples
+	}
+
+	limit, err := strconv.Atoi(n)
+	if err != nil || limit <= 0 || s.cfg.MaxLines <= 0 {
+		return ""
+	}
+	limit = min(limit, s.cfg.MaxLines)
+
+	select {
+	case s.queries <- struct{}{}:
+	default:
+		// too many queries running, the samples are queried on a later notification
+		return ""
+	}
+
+	// The samples are empty until the query completes. The alert is copied, since the rule
+	// keeps updating it while the query runs.
+	cached = &alertSamples{activeAt: alert.ActiveAt, lastSent: now}
+	s.cache[key] = cached
+	a := *alert
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer func() { <-s.queries }()
+
+		samples, err := s.query(context.WithoutCancel(ctx), expr, &a, limit)
+
+		s.mtx.Lock()
+		defer s.mtx.Unlock()
+		if err != nil {
+			level.Warn(s.logger).Log("msg", "failed to query sample log lines for alert", "alert", a.Labels.Get(labels.AlertName), "err", err)
+			// query again on the next notification
+			if s.cache[key] == cached {
+				delete(s.cache, key)
+			}
+			return
+		}
+		cached.samples = samples
+	}()
+	return ""
+}
+
+func (s *alertSampler) query(ctx context.Context, expr string, alert *rules.Alert, limit int) (string, error) {
+	qs, interval, err := alertSamplesQuery(expr, alert.Labels)
+	if err != nil {
+		return "", err
+	}
+
+	if sourceTenants := ruler.ExtractSourceTenants(ctx); len(sourceTenants) > 0 {
+		ctx = user.InjectOrgID(ctx, tenant.JoinTenantIDs(sourceTenants))
+	}
+	ctx, cancel := context.WithTimeout(ctx, s.cfg.QueryTimeout)
+	defer cancel()
+
+	// the log lines leading to the alert firing, from the first evaluation the alert became pending
+	end := alert.FiredAt
+	if end.IsZero() {
+		end = time.Now()
+	}
+	streams, err := s.sampler.SampleLogs(ctx, qs, alert.ActiveAt.Add(-interval), end, limit)
+	if err != nil {
+		return "", err
+	}
+	return formatAlertSamples(streams, limit, s.cfg.MaxBytes), nil
+}
+
+// pruneStale drops the samples of alerts which were neither resolved nor sent for a while,
+// for instance because their rule was deleted.
+func (s *alertSampler) pruneStale(now time.Time) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	for key, cached := range s.cache {
+		if now.Sub(cached.lastSent) > alertSamplesStaleAfter {
+			delete(s.cache, key)
+		}
+	}
+}
+
+// alertSamplesQuery returns the log query selecting the log lines of an alert, along with
+// the range of the log range aggregation. The log selector of the alerting rule expression
+// is narrowed down to the labels the expression groups by, using the values of the alert.
+func alertSamplesQuery(expr string, alertLabels labels.Labels) (string, time.Duration, error) {
+	parsed, err := syntax.ParseSampleExpr(expr)
+	if err != nil {
+		return "", 0, err
+	}
+
+	var (
+		logRange *syntax.LogRange
+		grouping *syntax.Grouping
+	)
+	parsed.Walk(func(e syntax.Expr) {
+		switch e := e.(type) {
+		case *syntax.LogRange:
+			if logRange == nil {
+				logRange = e
+			}
+		case *syntax.VectorAggregationExpr:
+			if grouping == nil && e.Grouping != nil && !e.Grouping.Without {
+				grouping = e.Grouping
+			}
+		case *syntax.RangeAggregationExpr:
+			if grouping == nil && e.Grouping != nil && !e.Grouping.Without {
+				grouping = e.Grouping
+			}
+		}
+	})
+	if logRange == nil {
+		return "", 0, fmt.Errorf("no log selector in expression %s", expr)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(logRange.Left.String())
+	if grouping != nil {
+		for _, name := range grouping.Groups {
+			if value := alertLabels.Get(name); value != "" {
+				sb.WriteString(" | ")
+				sb.WriteString(name)
+				sb.WriteString("=")
+				sb.WriteString(strconv.Quote(value))
+			}
+		}
+	}
+	return sb.String(), logRange.Interval, nil
+}
+
+// formatAlertSamples formats the most recent log lines of the streams, one per line, prefixed
+// with their timestamp. Lines are dropped, starting with the oldest one, to fit in maxBytes.
+func formatAlertSamples(streams logqlmodel.Streams, limit, maxBytes int) string {
+	type sample struct {
+		ts   time.Time
+		line string
+	}
+	var samples []sample
+	for _, stream := range streams {
+		for _, e := range stream.Entries {
+			samples = append(samples, sample{ts: e.Timestamp, line: e.Line})
+		}
+	}
+	// most recent first
+	slices.SortFunc(samples, func(a, b sample) int { return b.ts.Compare(a.ts) })
+	if len(samples) > limit {
+		samples = samples[:limit]
+	}
+
+	lines := make([]string, 0, len(samples))
+	size := 0
+	for _, smp := range samples {
+		line := smp.ts.UTC().Format(time.RFC3339Nano) + " " + smp.line
+		if size+len(line)+1 > maxBytes {
+			break
+		}
+		size += len(line) + 1
+		lines = append(lines, line)
+	}
+	// oldest first, as read in a log viewer
+	slices.Reverse(lines)
+	return strings.Join(lines, "\n")
+}
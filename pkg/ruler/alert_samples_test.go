@@ -0,0 +1,214 @@
+package ruler
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grafana/dskit/user"
+	"github.com/prometheus/prometheus/model/labels"
+	"github.com/prometheus/prometheus/model/rulefmt"
+	"github.com/prometheus/prometheus/rules"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/grafana/loki/v3/pkg/logproto"
+	"github.com/grafana/loki/v3/pkg/logqlmodel"
+	rulerbase "github.com/grafana/loki/v3/pkg/ruler/base"
+	"github.com/grafana/loki/v3/pkg/util/log"
+)
+
+func TestAlertSamplesQuery(t *testing.T) {
+	for _, tc := range []struct {
+		desc     string
+		expr     string
+		labels   labels.Labels
+		query    string
+		interval time.Duration
+	}{
+		{
+			desc:     "no grouping",
+			expr:     `count_over_time({app="foo"} |= "error" [5m]) > 10`,
+			labels:   labels.FromStrings("alertname", "errors", "app", "foo"),
+			query:    `{app="foo"} |= "error"`,
+			interval: 5 * time.Minute,
+		},
+		{
+			desc:     "vector aggregation grouping",
+			expr:     `sum by (job, level) (rate({app="foo"} | logfmt [1m])) > 1`,
+			labels:   labels.FromStrings("alertname", "errors", "job", "foo/bar", "level", "error"),
+			query:    `{app="foo"} | logfmt | job="foo/bar" | level="error"`,
+			interval: time.Minute,
+		},
+		{
+			desc:     "grouping label missing from the alert",
+			expr:     `sum by (pod) (rate({app="foo"}[1m])) / sum(rate({app="foo"}[10m])) > 0.5`,
+			labels:   labels.FromStrings("alertname", "errors"),
+			query:    `{app="foo"}`,
+			interval: time.Minute,
+		},
+		{
+			desc:     "without grouping",
+			expr:     `sum without (pod) (count_over_time({app="foo"}[2m])) > 1`,
+			labels:   labels.FromStrings("alertname", "errors", "job", "foo"),
+			query:    `{app="foo"}`,
+			interval: 2 * time.Minute,
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			query, interval, err := alertSamplesQuery(tc.expr, tc.labels)
+			require.NoError(t, err)
+			require.Equal(t, tc.query, query)
+			require.Equal(t, tc.interval, interval)
+		})
+	}
+}
+
+func TestFormatAlertSamples(t *testing.T) {
+	ts := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	streams := logqlmodel.Streams{
+		{Labels: `{app="a"}`, Entries: []logproto.Entry{{Timestamp: ts, Line: "first"}, {Timestamp: ts.Add(2 * time.Second), Line: "third"}}},
+		{Labels: `{app="b"}`, Entries: []logproto.Entry{{Timestamp: ts.Add(time.Second), Line: "second"}, {Timestamp: ts.Add(3 * time.Second), Line: "fourth"}}},
+	}
+
+	require.Equal(t, "2024-05-01T10:00:01Z second\n2024-05-01T10:00:02Z third\n2024-05-01T10:00:03Z fourth", formatAlertSamples(streams, 3, 1024))
+	// the oldest lines are dropped to fit in the size limit
+	require.Equal(t, "2024-05-01T10:00:02Z third\n2024-05-01T10:00:03Z fourth", formatAlertSamples(streams, 3, 60))
+}
+
+type fakeLogSampler struct {
+	mtx     sync.Mutex
+	queries []string
+	orgIDs  []string
+	streams logqlmodel.Streams
+	// block, when set, holds the queries until it is closed.
+	block chan struct{}
+}
+
+func (f *fakeLogSampler) SampleLogs(ctx context.Context, qs string, _, _ time.Time, _ int) (logqlmodel.Streams, error) {
+	if f.block != nil {
+		<-f.block
+	}
+	orgID, err := user.ExtractOrgID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	f.queries = append(f.queries, qs)
+	f.orgIDs = append(f.orgIDs, orgID)
+	return f.streams, nil
+}
+
+func TestAlertSampler_NotifyFunc(t *testing.T) {
+	ts := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	sampler := &fakeLogSampler{
+		streams: logqlmodel.Streams{{Labels: `{app="foo"}`, Entries: []logproto.Entry{{Timestamp: ts, Line: "level=error msg=boom"}}}},
+	}
+	s := newAlertSampler(AlertSamplesConfig{MaxLines: 5, MaxBytes: 1024, QueryTimeout: time.Second, MaxConcurrentQueries: 1}, sampler, log.Logger)
+
+	var sent []*rules.Alert
+	notify := s.NotifyFunc(func(_ context.Context, _ string, alerts ...*rules.Alert) {
+		sent = append(sent, alerts...)
+	})
+
+	newAlert := func(annotations labels.Labels) *rules.Alert {
+		return &rules.Alert{
+			Labels:      labels.FromStrings("alertname", "errors", "job", "foo"),
+			Annotations: annotations,
+			ActiveAt:    ts.Add(-time.Minute),
+			FiredAt:     ts,
+		}
+	}
+	ctx := user.InjectOrgID(context.Background(), "user")
+	expr := `sum by (job) (count_over_time({app="foo"}[1m])) > 0`
+
+	// rules without the annotation are left untouched
+	notify(ctx, expr, newAlert(labels.FromStrings("summary", "errors")))
+	require.Equal(t, labels.FromStrings("summary", "errors"), sent[0].Annotations)
+	require.Empty(t, sampler.queries)
+
+	// the samples are queried once, in the background, and attached to every notification
+	// sent once the query completed
+	notify(ctx, expr, newAlert(labels.FromStrings("summary", "errors", AlertSamplesAnnotation, "3")))
+	require.Equal(t, labels.FromStrings("summary", "errors"), sent[1].Annotations)
+	s.wg.Wait()
+	notify(ctx, expr, newAlert(labels.FromStrings("summary", "errors", AlertSamplesAnnotation, "3")))
+	notify(ctx, expr, newAlert(labels.FromStrings("summary", "errors", AlertSamplesAnnotation, "3")))
+	expected := labels.FromStrings("summary", "errors", LogSamplesAnnotation, "2024-05-01T10:00:00Z level=error msg=boom")
+	require.Equal(t, expected, sent[2].Annotations)
+	require.Equal(t, expected, sent[3].Annotations)
+	require.Equal(t, []string{`{app="foo"} | job="foo"`}, sampler.queries)
+
+	// resolving the alert drops the cached samples
+	resolved := newAlert(labels.FromStrings("summary", "errors", AlertSamplesAnnotation, "3"))
+	resolved.ResolvedAt = ts.Add(time.Minute)
+	notify(ctx, expr, resolved)
+	require.Equal(t, expected, sent[4].Annotations)
+	require.Empty(t, s.cache)
+
+	// federated rule groups sample the logs of their source tenants
+	notify(rulerbase.InjectSourceTenants(ctx, []string{"team-a", "team-b"}), expr, newAlert(labels.FromStrings(AlertSamplesAnnotation, "3")))
+	s.wg.Wait()
+	require.Equal(t, []string{"user", "team-a|team-b"}, sampler.orgIDs)
+}
+
+func TestAlertSampler_NotifyDoesNotWaitForQueries(t *testing.T) {
+	ts := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	sampler := &fakeLogSampler{
+		streams: logqlmodel.Streams{{Labels: `{app="foo"}`, Entries: []logproto.Entry{{Timestamp: ts, Line: "level=error msg=boom"}}}},
+		block:   make(chan struct{}),
+	}
+	s := newAlertSampler(AlertSamplesConfig{MaxLines: 5, MaxBytes: 1024, QueryTimeout: time.Second, MaxConcurrentQueries: 1}, sampler, log.Logger)
+
+	var sent []*rules.Alert
+	notify := s.NotifyFunc(func(_ context.Context, _ string, alerts ...*rules.Alert) {
+		sent = append(sent, alerts...)
+	})
+	newAlert := func(job string) *rules.Alert {
+		return &rules.Alert{
+			Labels:      labels.FromStrings("alertname", "errors", "job", job),
+			Annotations: labels.FromStrings(AlertSamplesAnnotation, "3"),
+			ActiveAt:    ts.Add(-time.Minute),
+			FiredAt:     ts,
+		}
+	}
+	ctx := user.InjectOrgID(context.Background(), "user")
+	expr := `sum by (job) (count_over_time({app="foo"}[1m])) > 0`
+
+	// the alerts are sent while the query of the first one is blocked, the second one
+	// isn't queried since the only query slot is taken
+	notify(ctx, expr, newAlert("foo"), newAlert("bar"))
+	require.Len(t, sent, 2)
+	require.Empty(t, sent[0].Annotations)
+	require.Empty(t, sent[1].Annotations)
+
+	close(sampler.block)
+	s.wg.Wait()
+	require.Equal(t, []string{`{app="foo"} | job="foo"`}, sampler.queries)
+
+	// the second alert is queried on its next notification
+	notify(ctx, expr, newAlert("foo"), newAlert("bar"))
+	s.wg.Wait()
+	require.Equal(t, labels.FromStrings(LogSamplesAnnotation, "2024-05-01T10:00:00Z level=error msg=boom"), sent[2].Annotations)
+	require.Empty(t, sent[3].Annotations)
+	require.Equal(t, []string{`{app="foo"} | job="foo"`, `{app="foo"} | job="bar"`}, sampler.queries)
+}
+
+func TestValidateAlertSamplesAnnotation(t *testing.T) {
+	rule := func(value string) rulefmt.RuleGroup {
+		return rulefmt.RuleGroup{
+			Name: "test",
+			Rules: []rulefmt.RuleNode{{
+				Alert:       yaml.Node{Value: "errors"},
+				Expr:        yaml.Node{Value: `sum(rate({app="foo"}[5m])) > 0`},
+				Annotations: map[string]string{AlertSamplesAnnotation: value},
+			}},
+		}
+	}
+
+	require.Empty(t, ValidateGroups(rule("10")))
+	require.Len(t, ValidateGroups(rule("0")), 1)
+	require.Len(t, ValidateGroups(rule("{{ $value }}")), 1)
+}
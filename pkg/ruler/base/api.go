@@ -18,7 +18,6 @@ import (
 	"github.com/pkg/errors"
 	v1 "github.com/prometheus/client_golang/api/prometheus/v1"
 	"github.com/prometheus/prometheus/model/labels"
-	"github.com/prometheus/prometheus/model/rulefmt"
 	"gopkg.in/yaml.v3"
 
 	"github.com/grafana/dskit/tenant"
@@ -524,7 +523,7 @@ func (a *API) ListRules(w http.ResponseWriter, req *http.Request) {
 		rgs = fitlerRuleGroups(rgs, pr.Labels)
 	}
 
-	formatted := rgs.Formatted()
+	formatted := rgs.FormattedWithSourceTenants()
 	marshalAndSend(formatted, w, logger)
 }
 
@@ -546,7 +545,7 @@ func (a *API) GetRuleGroup(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	formatted := rulespb.FromProto(rg)
+	formatted := rulespb.FromProtoWithSourceTenants(rg)
 	marshalAndSend(formatted, w, logger)
 }
 
@@ -569,7 +568,7 @@ func (a *API) CreateRuleGroup(w http.ResponseWriter, req *http.Request) {
 
 	level.Debug(logger).Log("msg", "attempting to unmarshal rulegroup", "group", string(payload))
 
-	rg := rulefmt.RuleGroup{}
+	rg := rulespb.RuleGroup{}
 	err = yaml.Unmarshal(payload, &rg)
 	if err != nil {
 		level.Error(logger).Log("msg", "unable to unmarshal rule group payload", "err", err.Error())
@@ -577,7 +576,7 @@ func (a *API) CreateRuleGroup(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	errs := a.ruler.manager.ValidateRuleGroup(rg)
+	errs := a.ruler.manager.ValidateRuleGroup(rg.RuleGroup)
 	if len(errs) > 0 {
 		e := []string{}
 		for _, err := range errs {
@@ -595,6 +594,12 @@ func (a *API) CreateRuleGroup(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	if err := a.ruler.AssertSourceTenants(pr.UserID, rg.SourceTenants); err != nil {
+		level.Error(logger).Log("msg", "source tenants validation failure", "err", err.Error())
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	rgs, err := a.store.ListRuleGroupsForUserAndNamespace(req.Context(), pr.UserID, "")
 	if err != nil {
 		level.Error(logger).Log("msg", "unable to fetch current rule groups for validation", "err", err.Error(), "user", pr.UserID)
@@ -608,7 +613,8 @@ func (a *API) CreateRuleGroup(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	rgProto := rulespb.ToProto(pr.UserID, pr.Namespace, rg)
+	rgProto := rulespb.ToProto(pr.UserID, pr.Namespace, rg.RuleGroup)
+	rgProto.SourceTenants = rg.SourceTenants
 
 	level.Debug(logger).Log("msg", "attempting to store rulegroup", "group", rgProto.String())
 	err = a.store.SetRuleGroup(req.Context(), pr.UserID, pr.Namespace, rgProto)
@@ -1004,6 +1004,87 @@ rules:
 	}
 }
 
+func TestRuler_SourceTenants(t *testing.T) {
+	cfg := defaultRulerConfig(t, newMockRuleStore(make(map[string]rulespb.RuleGroupList)))
+
+	r := newTestRuler(t, cfg)
+	defer services.StopAndAwaitTerminated(context.Background(), r) //nolint:errcheck
+
+	r.limits = ruleLimits{sourceTenants: []string{"team-a", "team-b"}}
+
+	a := NewAPI(r, r.store, log.NewNopLogger())
+
+	router := mux.NewRouter()
+	router.Path("/api/v1/rules/{namespace}").Methods("POST").HandlerFunc(a.CreateRuleGroup)
+	router.Path("/api/v1/rules/{namespace}/{groupName}").Methods("GET").HandlerFunc(a.GetRuleGroup)
+
+	for _, tt := range []struct {
+		name   string
+		input  string
+		output string
+		status int
+	}{
+		{
+			name:   "when a source tenant is not allowed",
+			status: 400,
+			input: `
+name: federated
+source_tenants: [team-a, team-c]
+rules:
+- record: up_rule
+  expr: up{}
+`,
+			output: "source tenant \"team-c\" is not allowed for federated rule groups\n",
+		},
+		{
+			name:   "when a source tenant is invalid",
+			status: 400,
+			input: `
+name: federated
+source_tenants: [team/a]
+rules:
+- record: up_rule
+  expr: up{}
+`,
+			output: "invalid source tenant \"team/a\": tenant ID 'team/a' contains unsupported character '/'\n",
+		},
+		{
+			name:   "when all source tenants are allowed",
+			status: 202,
+			input: `
+name: federated
+source_tenants: [team-a, team-b]
+rules:
+- record: up_rule
+  expr: up{}
+`,
+			output: "{\"status\":\"success\",\"data\":null,\"errorType\":\"\",\"error\":\"\"}",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			req := requestFor(t, http.MethodPost, "https://localhost:8080/api/v1/rules/namespace", strings.NewReader(tt.input), "user1")
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+			require.Equal(t, tt.status, w.Code)
+			require.Equal(t, tt.output, w.Body.String())
+		})
+	}
+
+	req := requestFor(t, http.MethodGet, "https://localhost:8080/api/v1/rules/namespace/federated", nil, "user1")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, 200, w.Code)
+	require.Equal(t, `name: federated
+rules:
+    - record: up_rule
+      expr: up{}
+source_tenants:
+    - team-a
+    - team-b
+`, w.Body.String())
+}
+
 func requestFor(t *testing.T, method string, url string, body io.Reader, userID string) *http.Request {
 	t.Helper()
 
@@ -0,0 +1,84 @@
+package base
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/grafana/dskit/tenant"
+	promRules "github.com/prometheus/prometheus/rules"
+
+	"github.com/grafana/loki/v3/pkg/ruler/rulespb"
+)
+
+type sourceTenantsContextKey int
+
+const sourceTenantsKey sourceTenantsContextKey = 0
+
+// InjectSourceTenants returns a derived context holding the source tenants of the
+// federated rule group being evaluated.
+func InjectSourceTenants(ctx context.Context, tenants []string) context.Context {
+	return context.WithValue(ctx, sourceTenantsKey, tenants)
+}
+
+// ExtractSourceTenants returns the source tenants of the federated rule group being
+// evaluated, or nil if the rules are evaluated against the tenant owning them.
+func ExtractSourceTenants(ctx context.Context) []string {
+	tenants, _ := ctx.Value(sourceTenantsKey).([]string)
+	return tenants
+}
+
+// ValidateSourceTenants checks the source tenants of a federated rule group are valid
+// tenant IDs and all part of the allowed tenants, where "*" allows any tenant.
+func ValidateSourceTenants(sourceTenants, allowed []string) error {
+	for _, t := range sourceTenants {
+		if err := tenant.ValidTenantID(t); err != nil {
+			return fmt.Errorf("invalid source tenant %q: %w", t, err)
+		}
+		if !slices.Contains(allowed, "*") && !slices.Contains(allowed, t) {
+			return fmt.Errorf(errSourceTenantNotAllowed, t)
+		}
+	}
+	return nil
+}
+
+// federatedGroups holds the source tenants of the federated rule groups of a tenant,
+// keyed by the Prometheus group key of the mapped rule group.
+type federatedGroups map[string][]string
+
+func (r *DefaultMultiTenantManager) setFederatedGroups(user string, groups rulespb.RuleGroupList) {
+	federated := federatedGroups{}
+	for _, g := range groups {
+		if len(g.GetSourceTenants()) == 0 {
+			continue
+		}
+		federated[promRules.GroupKey(r.mapper.ruleFile(user, g.GetNamespace()), g.GetName())] = g.GetSourceTenants()
+	}
+
+	r.federatedGroupsMtx.Lock()
+	defer r.federatedGroupsMtx.Unlock()
+	if len(federated) == 0 {
+		delete(r.federatedGroups, user)
+		return
+	}
+	r.federatedGroups[user] = federated
+}
+
+func (r *DefaultMultiTenantManager) sourceTenants(user string, g *promRules.Group) []string {
+	r.federatedGroupsMtx.RLock()
+	defer r.federatedGroupsMtx.RUnlock()
+	return r.federatedGroups[user][promRules.GroupKey(g.File(), g.Name())]
+}
+
+// evalIterationFunc returns the function evaluating the rule groups of the given user.
+// The source tenants of federated rule groups are looked up on every evaluation, so that
+// changing them does not require the rule files to be updated.
+func (r *DefaultMultiTenantManager) evalIterationFunc(user string) promRules.GroupEvalIterationFunc {
+	return func(ctx context.Context, g *promRules.Group, evalTimestamp time.Time) {
+		if tenants := r.sourceTenants(user, g); len(tenants) > 0 {
+			ctx = InjectSourceTenants(ctx, tenants)
+		}
+		promRules.DefaultEvalIterationFunc(ctx, g, evalTimestamp)
+	}
+}
@@ -94,6 +94,48 @@ func TestSyncRuleGroups(t *testing.T) {
 	})
 }
 
+func TestSyncRuleGroupsWithSourceTenants(t *testing.T) {
+	dir := t.TempDir()
+
+	m, err := NewDefaultMultiTenantManager(Config{RulePath: dir}, factory, nil, log.NewNopLogger(), ruleLimits{}, constants.Loki)
+	require.NoError(t, err)
+	defer m.Stop()
+
+	const user = "testUser"
+
+	group := &rulespb.RuleGroupDesc{
+		Name:          "group1",
+		Namespace:     "ns/federated",
+		Interval:      1 * time.Minute,
+		User:          user,
+		SourceTenants: []string{"team-a", "team-b"},
+	}
+	m.SyncRuleGroups(context.Background(), map[string]rulespb.RuleGroupList{user: {group}})
+
+	g := promRules.NewGroup(promRules.GroupOptions{
+		Name: "group1",
+		File: m.mapper.ruleFile(user, "ns/federated"),
+		Opts: &promRules.ManagerOptions{},
+	})
+	require.Equal(t, []string{"team-a", "team-b"}, m.sourceTenants(user, g))
+
+	// Changing the source tenants is picked up without the rule files being updated.
+	group = &rulespb.RuleGroupDesc{
+		Name:      "group1",
+		Namespace: "ns/federated",
+		Interval:  1 * time.Minute,
+		User:      user,
+	}
+	m.SyncRuleGroups(context.Background(), map[string]rulespb.RuleGroupList{user: {group}})
+	require.Nil(t, m.sourceTenants(user, g))
+}
+
+func TestSourceTenantsContext(t *testing.T) {
+	ctx := context.Background()
+	require.Nil(t, ExtractSourceTenants(ctx))
+	require.Equal(t, []string{"team-a"}, ExtractSourceTenants(InjectSourceTenants(ctx, []string{"team-a"})))
+}
+
 func getManager(m *DefaultMultiTenantManager, user string) RulesManager {
 	m.userManagerMtx.Lock()
 	defer m.userManagerMtx.Unlock()
@@ -75,6 +75,11 @@ func (m *mapper) users() ([]string, error) {
 	return result, err
 }
 
+// ruleFile returns the path of the file the rule groups of the given namespace are mapped to.
+func (m *mapper) ruleFile(user, namespace string) string {
+	return filepath.Join(m.Path, user, url.PathEscape(namespace))
+}
+
 func (m *mapper) MapRules(user string, ruleConfigs map[string][]rulefmt.RuleGroup) (bool, []string, error) {
 	anyUpdated := false
 	filenames := []string{}
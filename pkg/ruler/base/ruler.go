@@ -62,6 +62,7 @@ const (
 	// Limit errors
 	errMaxRuleGroupsPerUserLimitExceeded        = "per-user rule groups limit (limit: %d actual: %d) exceeded"
 	errMaxRulesPerRuleGroupPerUserLimitExceeded = "per-user rules per rule group limit (limit: %d actual: %d) exceeded"
+	errSourceTenantNotAllowed                   = "source tenant %q is not allowed for federated rule groups"
 
 	// errors
 	errListAllUser = "unable to list the ruler users"
@@ -1082,6 +1083,12 @@ func (r *Ruler) AssertMaxRulesPerRuleGroup(userID string, rules int) error {
 	return fmt.Errorf(errMaxRulesPerRuleGroupPerUserLimitExceeded, limit, rules)
 }
 
+// AssertSourceTenants checks the given tenant is allowed to evaluate rules against
+// the source tenants of a federated rule group and returns an error if not.
+func (r *Ruler) AssertSourceTenants(userID string, sourceTenants []string) error {
+	return ValidateSourceTenants(sourceTenants, r.limits.RulerAllowedSourceTenants(userID))
+}
+
 func (r *Ruler) DeleteTenantConfiguration(w http.ResponseWriter, req *http.Request) {
 	logger := util_log.WithContext(req.Context(), r.logger)
 
@@ -1130,7 +1137,7 @@ func (r *Ruler) ListAllRules(w http.ResponseWriter, req *http.Request) {
 		if err := r.store.LoadRuleGroups(ctx, userRules); err != nil {
 			return errors.Wrapf(err, "failed to load ruler config for user %s", userID)
 		}
-		data := map[string]map[string][]rulefmt.RuleGroup{userID: userRules[userID].Formatted()}
+		data := map[string]map[string][]rulespb.RuleGroup{userID: userRules[userID].FormattedWithSourceTenants()}
 
 		select {
 		case iter <- data:
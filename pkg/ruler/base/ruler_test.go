@@ -88,6 +88,7 @@ type ruleLimits struct {
 	maxRulesPerRuleGroup int
 	maxRuleGroups        int
 	alertManagerConfig   map[string]*config.AlertManagerConfig
+	sourceTenants        []string
 }
 
 func (r ruleLimits) RulerTenantShardSize(_ string) int {
@@ -106,6 +107,10 @@ func (r ruleLimits) RulerAlertManagerConfig(tenantID string) *config.AlertManage
 	return r.alertManagerConfig[tenantID]
 }
 
+func (r ruleLimits) RulerAllowedSourceTenants(_ string) []string {
+	return r.sourceTenants
+}
+
 func testQueryableFunc(q storage.Querier) storage.QueryableFunc {
 	if q != nil {
 		return func(mint, maxt int64) (storage.Querier, error) {
@@ -3,11 +3,13 @@ package ruler
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/go-kit/log"
 	"github.com/go-kit/log/level"
+	"github.com/grafana/dskit/tenant"
 	"github.com/grafana/dskit/user"
 	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus"
@@ -58,7 +60,7 @@ type RulesLimits interface {
 
 // queryFunc returns a new query function using the rules.EngineQueryFunc function
 // and passing an altered timestamp.
-func queryFunc(evaluator Evaluator, checker readyChecker, userID string, logger log.Logger) rules.QueryFunc {
+func queryFunc(evaluator Evaluator, checker readyChecker, limits RulesLimits, userID string, logger log.Logger) rules.QueryFunc {
 	return func(ctx context.Context, qs string, t time.Time) (promql.Vector, error) {
 		hash := util.HashedQuery(qs)
 		detail := rules.FromOriginContext(ctx)
@@ -72,6 +74,17 @@ func queryFunc(evaluator Evaluator, checker readyChecker, userID string, logger
 			return nil, errNotReady
 		}
 
+		// rules of federated rule groups are evaluated against their source tenants;
+		// the allowed source tenants are checked again as the limits may have changed
+		// since the rule group was stored
+		if sourceTenants := ruler.ExtractSourceTenants(ctx); len(sourceTenants) > 0 {
+			if err := ruler.ValidateSourceTenants(sourceTenants, limits.RulerAllowedSourceTenants(userID)); err != nil {
+				level.Error(detailLog).Log("msg", "rule evaluation failed", "err", err)
+				return nil, fmt.Errorf("rule evaluation failed: %w", err)
+			}
+			ctx = user.InjectOrgID(ctx, tenant.JoinTenantIDs(sourceTenants))
+		}
+
 		res, err := evaluator.Eval(ctx, qs, t)
 
 		if err != nil {
@@ -144,20 +157,25 @@ func MultiTenantRuleManager(cfg Config, evaluator Evaluator, overrides RulesLimi
 		registry.configureTenantStorage(userID)
 
 		logger = log.With(logger, "user", userID)
-		queryFn := queryFunc(evaluator, registry, userID, logger)
+		queryFn := queryFunc(evaluator, registry, overrides, userID, logger)
 		memStore := NewMemStore(userID, queryFn, newMemstoreMetrics(reg), 5*time.Minute, log.With(logger, "subcomponent", "MemStore"))
 
 		// GroupLoader builds a cache of the rules as they're loaded by the
 		// manager.This is used to back the memstore
 		groupLoader := NewCachingGroupLoader(GroupLoader{})
 
+		notifyFunc := ruler.SendAlerts(notifier, cfg.ExternalURL.URL.String(), cfg.DatasourceUID)
+		if sampler, ok := evaluator.(LogSampler); ok {
+			notifyFunc = newAlertSampler(cfg.AlertSamples, sampler, log.With(logger, "subcomponent", "AlertSampler")).NotifyFunc(notifyFunc)
+		}
+
 		mgr := rules.NewManager(&rules.ManagerOptions{
 			Appendable:               registry,
 			Queryable:                memStore,
 			QueryFunc:                queryFn,
 			Context:                  user.InjectOrgID(ctx, userID),
 			ExternalURL:              cfg.ExternalURL.URL,
-			NotifyFunc:               ruler.SendAlerts(notifier, cfg.ExternalURL.URL.String(), cfg.DatasourceUID),
+			NotifyFunc:               notifyFunc,
 			Logger:                   logger,
 			Registerer:               reg,
 			OutageTolerance:          cfg.OutageTolerance,
@@ -278,10 +296,15 @@ func validateRuleNode(r *rulefmt.RuleNode, groupName string) error {
 		}
 	}
 
-	for k := range r.Annotations {
+	for k, v := range r.Annotations {
 		if !model.LabelName(k).IsValid() {
 			return errors.Errorf("invalid annotation name: %s", k)
 		}
+		if k == AlertSamplesAnnotation {
+			if n, err := strconv.Atoi(v); err != nil || n <= 0 {
+				return errors.Errorf("invalid annotation %s: %q must be a positive number of log lines", k, v)
+			}
+		}
 	}
 
 	for _, err := range testTemplateParsing(r) {
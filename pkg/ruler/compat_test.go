@@ -5,8 +5,10 @@ import (
 	"testing"
 	"time"
 
+	"github.com/grafana/dskit/user"
 	"github.com/prometheus/prometheus/config"
 	"github.com/prometheus/prometheus/model/rulefmt"
+	"github.com/prometheus/prometheus/promql"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
@@ -14,6 +16,7 @@ import (
 
 	"github.com/grafana/loki/v3/pkg/iter"
 	"github.com/grafana/loki/v3/pkg/logql"
+	"github.com/grafana/loki/v3/pkg/logqlmodel"
 	rulerbase "github.com/grafana/loki/v3/pkg/ruler/base"
 	"github.com/grafana/loki/v3/pkg/util/log"
 	"github.com/grafana/loki/v3/pkg/validation"
@@ -109,12 +112,47 @@ func TestNonMetricQuery(t *testing.T) {
 	eval, err := NewLocalEvaluator(engine, log)
 	require.NoError(t, err)
 
-	queryFunc := queryFunc(eval, fakeChecker{}, "fake", log)
+	queryFunc := queryFunc(eval, fakeChecker{}, overrides, "fake", log)
 
 	_, err = queryFunc(context.TODO(), `{job="nginx"}`, time.Now())
 	require.Error(t, err, "rule result is not a vector or scalar")
 }
 
+func TestFederatedQuery(t *testing.T) {
+	overrides, err := validation.NewOverrides(validation.Limits{}, fakeLimits{limits: map[string]*validation.Limits{
+		"fake": {RulerAllowedSourceTenants: []string{"team-a", "team-b"}},
+	}})
+	require.NoError(t, err)
+
+	eval := &orgIDEvaluator{}
+	queryFunc := queryFunc(eval, fakeChecker{}, overrides, "fake", log.Logger)
+	ctx := user.InjectOrgID(context.Background(), "fake")
+
+	_, err = queryFunc(ctx, `sum(rate({job="nginx"}[1m]))`, time.Now())
+	require.NoError(t, err)
+	require.Equal(t, "fake", eval.orgID)
+
+	_, err = queryFunc(rulerbase.InjectSourceTenants(ctx, []string{"team-a", "team-b"}), `sum(rate({job="nginx"}[1m]))`, time.Now())
+	require.NoError(t, err)
+	require.Equal(t, "team-a|team-b", eval.orgID)
+
+	_, err = queryFunc(rulerbase.InjectSourceTenants(ctx, []string{"team-c"}), `sum(rate({job="nginx"}[1m]))`, time.Now())
+	require.ErrorContains(t, err, `source tenant "team-c" is not allowed`)
+}
+
+type orgIDEvaluator struct {
+	orgID string
+}
+
+func (e *orgIDEvaluator) Eval(ctx context.Context, _ string, _ time.Time) (*logqlmodel.Result, error) {
+	orgID, err := user.ExtractOrgID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	e.orgID = orgID
+	return &logqlmodel.Result{Data: promql.Vector{}}, nil
+}
+
 type FakeQuerier struct{}
 
 func (q *FakeQuerier) SelectLogs(context.Context, logql.SelectLogParams) (iter.EntryIterator, error) {
@@ -24,6 +24,8 @@ type Config struct {
 	RemoteWrite RemoteWriteConfig `yaml:"remote_write,omitempty" doc:"description=Remote-write configuration to send rule samples to a Prometheus remote-write endpoint."`
 
 	Evaluation EvaluationConfig `yaml:"evaluation,omitempty" doc:"description=Configuration for rule evaluation."`
+
+	AlertSamples AlertSamplesConfig `yaml:"alert_samples,omitempty" doc:"description=Configuration for the sample log lines attached to the firing alerts of rules with the '__alert_samples__' annotation."`
 }
 
 func (c *Config) RegisterFlags(f *flag.FlagSet) {
@@ -32,6 +34,7 @@ func (c *Config) RegisterFlags(f *flag.FlagSet) {
 	c.WAL.RegisterFlags(f)
 	c.WALCleaner.RegisterFlags(f)
 	c.Evaluation.RegisterFlags(f)
+	c.AlertSamples.RegisterFlags(f)
 }
 
 // Validate overrides the embedded cortex variant which expects a cortex limits struct. Instead, copy the relevant bits over.
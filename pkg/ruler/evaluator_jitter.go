@@ -2,6 +2,7 @@ package ruler
 
 import (
 	"context"
+	"errors"
 	"hash"
 	"math"
 	"sync"
@@ -55,6 +56,15 @@ func (e *EvaluatorWithJitter) Eval(ctx context.Context, qs string, now time.Time
 	return e.inner.Eval(ctx, qs, now)
 }
 
+// SampleLogs implements LogSampler if the wrapped evaluator does. No jitter is applied.
+func (e *EvaluatorWithJitter) SampleLogs(ctx context.Context, qs string, start, end time.Time, limit int) (logqlmodel.Streams, error) {
+	sampler, ok := e.inner.(LogSampler)
+	if !ok {
+		return nil, errors.New("evaluator does not support sampling logs")
+	}
+	return sampler.SampleLogs(ctx, qs, start, end, limit)
+}
+
 func (e *EvaluatorWithJitter) calculateJitter(qs string, logger log.Logger) time.Duration {
 	var h uint32
 
@@ -50,3 +50,31 @@ func (l *LocalEvaluator) Eval(ctx context.Context, qs string, now time.Time) (*l
 
 	return &res, nil
 }
+
+// SampleLogs implements LogSampler.
+func (l *LocalEvaluator) SampleLogs(ctx context.Context, qs string, start, end time.Time, limit int) (logqlmodel.Streams, error) {
+	params, err := logql.NewLiteralParams(
+		qs,
+		start,
+		end,
+		0,
+		0,
+		logproto.BACKWARD,
+		uint32(limit),
+		nil,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := l.engine.Query(params).Exec(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	streams, ok := res.Data.(logqlmodel.Streams)
+	if !ok {
+		return nil, fmt.Errorf("unexpected result type: %s", res.Data.Type())
+	}
+	return streams, nil
+}
@@ -48,9 +48,10 @@ const (
 	keepAlive        = time.Second * 10
 	keepAliveTimeout = time.Second * 5
 
-	serviceConfig     = `{"loadBalancingPolicy": "round_robin"}`
-	queryEndpointPath = "/loki/api/v1/query"
-	mimeTypeFormPost  = "application/x-www-form-urlencoded"
+	serviceConfig          = `{"loadBalancingPolicy": "round_robin"}`
+	queryEndpointPath      = "/loki/api/v1/query"
+	queryRangeEndpointPath = "/loki/api/v1/query_range"
+	mimeTypeFormPost       = "application/x-www-form-urlencoded"
 
 	EvalModeRemote = "remote"
 )
@@ -219,24 +220,10 @@ func (r *RemoteEvaluator) query(ctx context.Context, orgID, query string, ts tim
 	if !ts.IsZero() {
 		args.Set("time", ts.Format(time.RFC3339Nano))
 	}
-	body := []byte(args.Encode())
 	hash := util.HashedQuery(query)
 
-	req := httpgrpc.HTTPRequest{
-		Method: http.MethodPost,
-		Url:    queryEndpointPath,
-		Body:   body,
-		Headers: []*httpgrpc.Header{
-			{Key: textproto.CanonicalMIMEHeaderKey("User-Agent"), Values: []string{userAgent}},
-			{Key: textproto.CanonicalMIMEHeaderKey("Content-Type"), Values: []string{mimeTypeFormPost}},
-			{Key: textproto.CanonicalMIMEHeaderKey("Content-Length"), Values: []string{strconv.Itoa(len(body))}},
-			{Key: textproto.CanonicalMIMEHeaderKey(string(httpreq.QueryTagsHTTPHeader)), Values: []string{"source=ruler"}},
-			{Key: textproto.CanonicalMIMEHeaderKey(user.OrgIDHeaderName), Values: []string{orgID}},
-		},
-	}
-
 	start := time.Now()
-	resp, err := r.client.Handle(ctx, &req)
+	resp, err := r.client.Handle(ctx, newQueryRequest(queryEndpointPath, orgID, args))
 
 	instrument.ObserveWithExemplar(ctx, r.metrics.reqDurationSecs.WithLabelValues(orgID), time.Since(start).Seconds())
 
@@ -281,6 +268,60 @@ func (r *RemoteEvaluator) query(ctx context.Context, orgID, query string, ts tim
 	return r.decodeResponse(ctx, resp, orgID)
 }
 
+// SampleLogs implements LogSampler by running a log query against the query-frontend.
+func (r *RemoteEvaluator) SampleLogs(ctx context.Context, qs string, start, end time.Time, limit int) (logqlmodel.Streams, error) {
+	orgID, err := user.ExtractOrgID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve tenant ID from context: %w", err)
+	}
+
+	args := make(url.Values)
+	args.Set("query", qs)
+	args.Set("start", start.Format(time.RFC3339Nano))
+	args.Set("end", end.Format(time.RFC3339Nano))
+	args.Set("limit", strconv.Itoa(limit))
+	args.Set("direction", "backward")
+
+	resp, err := r.client.Handle(ctx, newQueryRequest(queryRangeEndpointPath, orgID, args))
+	if err != nil {
+		return nil, err
+	}
+	if resp.Code/100 != 2 {
+		return nil, fmt.Errorf("unsuccessful/unexpected response - status code %d", resp.Code)
+	}
+
+	maxSize := r.overrides.RulerRemoteEvaluationMaxResponseSize(orgID)
+	if maxSize > 0 && int64(len(resp.Body)) >= maxSize {
+		return nil, fmt.Errorf("%d bytes exceeds response size limit of %d (defined by ruler_remote_evaluation_max_response_size)", len(resp.Body), maxSize)
+	}
+
+	var decoded loghttp.QueryResponse
+	if err := json.NewDecoder(bytes.NewReader(resp.Body)).Decode(&decoded); err != nil {
+		return nil, fmt.Errorf("unexpected body encoding, not valid JSON: %w", err)
+	}
+	streams, ok := decoded.Data.Result.(loghttp.Streams)
+	if decoded.Status != loghttp.QueryStatusSuccess || !ok {
+		return nil, fmt.Errorf("unexpected query response: status %q, result type %q", decoded.Status, decoded.Data.ResultType)
+	}
+	return streams.ToProto(), nil
+}
+
+func newQueryRequest(path, orgID string, args url.Values) *httpgrpc.HTTPRequest {
+	body := []byte(args.Encode())
+	return &httpgrpc.HTTPRequest{
+		Method: http.MethodPost,
+		Url:    path,
+		Body:   body,
+		Headers: []*httpgrpc.Header{
+			{Key: textproto.CanonicalMIMEHeaderKey("User-Agent"), Values: []string{userAgent}},
+			{Key: textproto.CanonicalMIMEHeaderKey("Content-Type"), Values: []string{mimeTypeFormPost}},
+			{Key: textproto.CanonicalMIMEHeaderKey("Content-Length"), Values: []string{strconv.Itoa(len(body))}},
+			{Key: textproto.CanonicalMIMEHeaderKey(string(httpreq.QueryTagsHTTPHeader)), Values: []string{"source=ruler"}},
+			{Key: textproto.CanonicalMIMEHeaderKey(user.OrgIDHeaderName), Values: []string{orgID}},
+		},
+	}
+}
+
 func (r *RemoteEvaluator) decodeResponse(ctx context.Context, resp *httpgrpc.HTTPResponse, orgID string) (*logqlmodel.Result, error) {
 	fullBody := resp.Body
 	// created a limited reader to avoid logging the entire response body should it be very large
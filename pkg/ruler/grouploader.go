@@ -12,6 +12,7 @@ import (
 	"gopkg.in/yaml.v3"
 
 	"github.com/grafana/loki/v3/pkg/logql/syntax"
+	"github.com/grafana/loki/v3/pkg/ruler/rulespb"
 )
 
 type GroupLoader struct{}
@@ -26,6 +27,21 @@ func (GroupLoader) Parse(query string) (parser.Expr, error) {
 }
 
 func (g GroupLoader) Load(identifier string) (*rulefmt.RuleGroups, []error) {
+	groups, errs := g.LoadFederated(identifier)
+	if groups == nil {
+		return nil, errs
+	}
+
+	rgs := &rulefmt.RuleGroups{Groups: make([]rulefmt.RuleGroup, 0, len(groups))}
+	for _, group := range groups {
+		rgs.Groups = append(rgs.Groups, group.RuleGroup)
+	}
+	return rgs, errs
+}
+
+// LoadFederated loads the rule groups of a rule file along with the source tenants of
+// federated rule groups.
+func (g GroupLoader) LoadFederated(identifier string) ([]rulespb.RuleGroup, []error) {
 	b, err := os.ReadFile(identifier)
 	if err != nil {
 		return nil, []error{errors.Wrap(err, identifier)}
@@ -37,10 +53,12 @@ func (g GroupLoader) Load(identifier string) (*rulefmt.RuleGroups, []error) {
 	return rgs, errs
 }
 
-func (GroupLoader) parseRules(content []byte) (*rulefmt.RuleGroups, []error) {
+func (GroupLoader) parseRules(content []byte) ([]rulespb.RuleGroup, []error) {
 	var (
-		groups rulefmt.RuleGroups
-		errs   []error
+		groups struct {
+			Groups []rulespb.RuleGroup `yaml:"groups"`
+		}
+		errs []error
 	)
 
 	decoder := yaml.NewDecoder(bytes.NewReader(content))
@@ -54,7 +72,11 @@ func (GroupLoader) parseRules(content []byte) (*rulefmt.RuleGroups, []error) {
 		return nil, errs
 	}
 
-	return &groups, ValidateGroups(groups.Groups...)
+	formatted := make([]rulefmt.RuleGroup, 0, len(groups.Groups))
+	for _, group := range groups.Groups {
+		formatted = append(formatted, group.RuleGroup)
+	}
+	return groups.Groups, ValidateGroups(formatted...)
 }
 
 type CachingGroupLoader struct {
@@ -3,6 +3,7 @@ package ruler
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -268,6 +269,34 @@ groups:
 	}
 }
 
+func Test_GroupLoader_LoadFederated(t *testing.T) {
+	var loader GroupLoader
+	f := filepath.Join(t.TempDir(), "rules")
+	require.NoError(t, os.WriteFile(f, []byte(`
+groups:
+  - name: federated
+    source_tenants: [team-a, team-b]
+    rules:
+      - record: nginx:requests:rate1m
+        expr: sum(rate({job="nginx"}[1m]))
+  - name: local
+    rules:
+      - record: app:requests:rate1m
+        expr: sum(rate({job="app"}[1m]))
+`), 0o644))
+
+	groups, errs := loader.LoadFederated(f)
+	require.Nil(t, errs)
+	require.Len(t, groups, 2)
+	require.Equal(t, []string{"team-a", "team-b"}, groups[0].SourceTenants)
+	require.Nil(t, groups[1].SourceTenants)
+
+	rgs, errs := loader.Load(f)
+	require.Nil(t, errs)
+	require.Len(t, rgs.Groups, 2)
+	require.Equal(t, "federated", rgs.Groups[0].Name)
+}
+
 func TestCachingGroupLoader(t *testing.T) {
 	t.Run("it caches rules as they are loaded from the underlying loader", func(t *testing.T) {
 		l := newFakeGroupLoader()
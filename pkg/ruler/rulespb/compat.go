@@ -11,6 +11,14 @@ import (
 	"github.com/grafana/loki/v3/pkg/logproto" //lint:ignore faillint allowed to import other protobuf
 )
 
+// RuleGroup is a rule group as read from rule files and the ruler API. Next to the
+// Prometheus rule group fields it holds the tenants the rules of a federated rule
+// group are evaluated against.
+type RuleGroup struct {
+	rulefmt.RuleGroup `yaml:",inline"`
+	SourceTenants     []string `yaml:"source_tenants,omitempty"`
+}
+
 // ToProto transforms a formatted prometheus rulegroup to a rule group protobuf
 func ToProto(user string, namespace string, rl rulefmt.RuleGroup) *RuleGroupDesc {
 	rg := RuleGroupDesc{
@@ -75,3 +83,12 @@ func FromProto(rg *RuleGroupDesc) rulefmt.RuleGroup {
 
 	return formattedRuleGroup
 }
+
+// FromProtoWithSourceTenants generates a RuleGroup which, unlike FromProto, keeps
+// the source tenants of the rule group.
+func FromProtoWithSourceTenants(rg *RuleGroupDesc) RuleGroup {
+	return RuleGroup{
+		RuleGroup:     FromProto(rg),
+		SourceTenants: rg.GetSourceTenants(),
+	}
+}
@@ -45,6 +45,8 @@ type RuleGroupDesc struct {
 	// to the Prometheus Manager.
 	Options []*types.Any `protobuf:"bytes,9,rep,name=options,proto3" json:"options,omitempty"`
 	Limit   int64        `protobuf:"varint,10,opt,name=limit,proto3" json:"limit,omitempty"`
+	// The tenants whose data the rules of a federated rule group are evaluated against.
+	SourceTenants []string `protobuf:"bytes,11,rep,name=source_tenants,json=sourceTenants,proto3" json:"source_tenants,omitempty"`
 }
 
 func (m *RuleGroupDesc) Reset()      { *m = RuleGroupDesc{} }
@@ -128,6 +130,13 @@ func (m *RuleGroupDesc) GetLimit() int64 {
 	return 0
 }
 
+func (m *RuleGroupDesc) GetSourceTenants() []string {
+	if m != nil {
+		return m.SourceTenants
+	}
+	return nil
+}
+
 // RuleDesc is a proto representation of a Prometheus Rule
 type RuleDesc struct {
 	Expr        string                                                 `protobuf:"bytes,1,opt,name=expr,proto3" json:"expr,omitempty"`
@@ -206,39 +215,41 @@ func init() {
 func init() { proto.RegisterFile("pkg/ruler/rulespb/rules.proto", fileDescriptor_dd3ef3757f506fba) }
 
 var fileDescriptor_dd3ef3757f506fba = []byte{
-	// 503 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xb4, 0x53, 0x31, 0x6f, 0xd3, 0x40,
-	0x18, 0xf5, 0x25, 0x8e, 0x6b, 0x5f, 0x54, 0x11, 0x9d, 0x22, 0xe4, 0x14, 0xb8, 0x44, 0x95, 0x90,
-	0x32, 0x20, 0x5b, 0x6a, 0x61, 0x43, 0x42, 0x8d, 0x2a, 0x21, 0x45, 0x1d, 0x90, 0x47, 0xb6, 0xb3,
-	0x73, 0x39, 0xac, 0x5e, 0x7c, 0xd6, 0xd9, 0xae, 0xc8, 0xc6, 0x4f, 0x60, 0x41, 0xe2, 0x27, 0xf0,
-	0x53, 0x3a, 0x66, 0xac, 0x18, 0x0a, 0x71, 0x16, 0xc6, 0x2e, 0xec, 0xe8, 0xee, 0xec, 0x52, 0x60,
-	0x80, 0x85, 0xc5, 0xf7, 0xbd, 0x7b, 0xf7, 0xdd, 0x7b, 0xdf, 0xb3, 0x0d, 0x1f, 0xe5, 0xe7, 0x2c,
-	0x94, 0x15, 0xa7, 0x52, 0x3f, 0x8b, 0x3c, 0x36, 0x6b, 0x90, 0x4b, 0x51, 0x0a, 0xd4, 0xd3, 0xe0,
-	0x60, 0xc8, 0x04, 0x13, 0x7a, 0x27, 0x54, 0x95, 0x21, 0x0f, 0x46, 0x4c, 0x08, 0xc6, 0x69, 0xa8,
-	0x51, 0x5c, 0x2d, 0x43, 0x92, 0xad, 0x1b, 0x0a, 0xff, 0x4e, 0x2d, 0x2a, 0x49, 0xca, 0x54, 0x64,
-	0x0d, 0xff, 0x40, 0xc9, 0x72, 0xc1, 0xcc, 0x9d, 0x6d, 0x61, 0xc8, 0xc3, 0x0f, 0x1d, 0xb8, 0x1f,
-	0x55, 0x9c, 0xbe, 0x94, 0xa2, 0xca, 0x4f, 0x69, 0x91, 0x20, 0x04, 0xed, 0x8c, 0xac, 0xa8, 0x0f,
-	0x26, 0x60, 0xea, 0x45, 0xba, 0x46, 0x0f, 0xa1, 0xa7, 0xd6, 0x22, 0x27, 0x09, 0xf5, 0x3b, 0x9a,
-	0xf8, 0xb9, 0x81, 0x5e, 0x40, 0x37, 0xcd, 0x4a, 0x2a, 0x2f, 0x08, 0xf7, 0xbb, 0x13, 0x30, 0xed,
-	0x1f, 0x8d, 0x02, 0xe3, 0x29, 0x68, 0x3d, 0x05, 0xa7, 0x8d, 0xa7, 0x99, 0x7b, 0x79, 0x3d, 0xb6,
-	0x3e, 0x7e, 0x19, 0x83, 0xe8, 0xb6, 0x09, 0x3d, 0x86, 0x66, 0x76, 0xdf, 0x9e, 0x74, 0xa7, 0xfd,
-	0xa3, 0x7b, 0x81, 0x89, 0x45, 0xf9, 0x52, 0x96, 0x22, 0xc3, 0x2a, 0x67, 0x55, 0x41, 0xa5, 0xef,
-	0x18, 0x67, 0xaa, 0x46, 0x01, 0xdc, 0x13, 0xb9, 0xba, 0xb8, 0xf0, 0x3d, 0xdd, 0x3c, 0xfc, 0x43,
-	0xfa, 0x24, 0x5b, 0x47, 0xed, 0x21, 0x34, 0x84, 0x3d, 0x9e, 0xae, 0xd2, 0xd2, 0x87, 0x13, 0x30,
-	0xed, 0x46, 0x06, 0xcc, 0x6d, 0xb7, 0x37, 0x70, 0xe6, 0xb6, 0xbb, 0x37, 0x70, 0xe7, 0xb6, 0xeb,
-	0x0e, 0xbc, 0xc3, 0xef, 0x1d, 0xe8, 0xb6, 0xfa, 0x4a, 0x98, 0xbe, 0xcd, 0x65, 0x1b, 0x89, 0xaa,
-	0xd1, 0x7d, 0xe8, 0x48, 0x9a, 0x08, 0xb9, 0x68, 0xf2, 0x68, 0x90, 0x12, 0x20, 0x9c, 0xca, 0x52,
-	0x27, 0xe1, 0x45, 0x06, 0xa0, 0x67, 0xb0, 0xbb, 0x14, 0xd2, 0xb7, 0xff, 0x3d, 0x1d, 0x75, 0x1e,
-	0x09, 0xe8, 0x70, 0x12, 0x53, 0x5e, 0xf8, 0x3d, 0x3d, 0xdc, 0x28, 0xb8, 0x7d, 0x7d, 0x67, 0x94,
-	0x91, 0x64, 0x7d, 0xa6, 0xd8, 0x57, 0x24, 0x95, 0xb3, 0xe7, 0xaa, 0xf3, 0xf3, 0xf5, 0xf8, 0x29,
-	0x4b, 0xcb, 0x37, 0x55, 0x1c, 0x24, 0x62, 0x15, 0x32, 0x49, 0x96, 0x24, 0x23, 0x21, 0x17, 0xe7,
-	0x69, 0x78, 0x71, 0x1c, 0xde, 0xfd, 0x10, 0x02, 0xdd, 0x7a, 0xb2, 0x20, 0x79, 0x49, 0x65, 0xd4,
-	0xc8, 0xa0, 0x35, 0xec, 0x93, 0x2c, 0x13, 0x25, 0x31, 0x91, 0x3a, 0xff, 0x57, 0xf5, 0xae, 0x96,
-	0x4e, 0x7f, 0x7f, 0x16, 0x6f, 0xb6, 0xd8, 0xba, 0xda, 0x62, 0xeb, 0x66, 0x8b, 0xc1, 0xbb, 0x1a,
-	0x83, 0x4f, 0x35, 0x06, 0x97, 0x35, 0x06, 0x9b, 0x1a, 0x83, 0xaf, 0x35, 0x06, 0xdf, 0x6a, 0x6c,
-	0xdd, 0xd4, 0x18, 0xbc, 0xdf, 0x61, 0x6b, 0xb3, 0xc3, 0xd6, 0xd5, 0x0e, 0x5b, 0xaf, 0x9f, 0xfc,
-	0x45, 0xfe, 0x97, 0x9f, 0x2e, 0x76, 0xb4, 0x95, 0xe3, 0x1f, 0x01, 0x00, 0x00, 0xff, 0xff, 0xab,
-	0x30, 0xeb, 0xc9, 0x90, 0x03, 0x00, 0x00,
+	// 531 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xb4, 0x93, 0x31, 0x6f, 0xd3, 0x40,
+	0x14, 0xc7, 0x7d, 0x8d, 0xe3, 0xda, 0x17, 0x05, 0xa2, 0x53, 0x84, 0x9c, 0x02, 0x97, 0xa8, 0x52,
+	0xa5, 0x0c, 0xc8, 0x96, 0x5a, 0xd8, 0x90, 0x50, 0xa3, 0x4a, 0x48, 0x51, 0x07, 0x64, 0x31, 0xb1,
+	0xa0, 0xb3, 0x73, 0x31, 0x56, 0x9d, 0x3b, 0xeb, 0x7c, 0xae, 0xc8, 0xc6, 0x47, 0x60, 0xe4, 0x23,
+	0xf0, 0x21, 0xf8, 0x00, 0x1d, 0x33, 0x56, 0x0c, 0x85, 0x38, 0x0b, 0x63, 0x17, 0x76, 0x74, 0x77,
+	0x4e, 0x29, 0x30, 0xc0, 0xc2, 0xe2, 0x7b, 0xef, 0xfd, 0xef, 0xdd, 0xfb, 0xf9, 0x6f, 0x1f, 0x7c,
+	0x58, 0x9c, 0xa5, 0xa1, 0xa8, 0x72, 0x2a, 0xf4, 0xb3, 0x2c, 0x62, 0xb3, 0x06, 0x85, 0xe0, 0x92,
+	0xa3, 0xb6, 0x4e, 0xf6, 0xfa, 0x29, 0x4f, 0xb9, 0xae, 0x84, 0x2a, 0x32, 0xe2, 0xde, 0x20, 0xe5,
+	0x3c, 0xcd, 0x69, 0xa8, 0xb3, 0xb8, 0x9a, 0x87, 0x84, 0x2d, 0x1b, 0x09, 0xff, 0x2e, 0xcd, 0x2a,
+	0x41, 0x64, 0xc6, 0x59, 0xa3, 0xdf, 0x57, 0x63, 0x73, 0x9e, 0x9a, 0x33, 0xb7, 0x81, 0x11, 0xf7,
+	0x3f, 0xed, 0xc0, 0x6e, 0x54, 0xe5, 0xf4, 0xb9, 0xe0, 0x55, 0x71, 0x42, 0xcb, 0x04, 0x21, 0x68,
+	0x33, 0xb2, 0xa0, 0x3e, 0x18, 0x81, 0xb1, 0x17, 0xe9, 0x18, 0x3d, 0x80, 0x9e, 0x5a, 0xcb, 0x82,
+	0x24, 0xd4, 0xdf, 0xd1, 0xc2, 0xcf, 0x02, 0x7a, 0x06, 0xdd, 0x8c, 0x49, 0x2a, 0xce, 0x49, 0xee,
+	0xb7, 0x46, 0x60, 0xdc, 0x39, 0x1c, 0x04, 0x86, 0x29, 0xd8, 0x32, 0x05, 0x27, 0x0d, 0xd3, 0xc4,
+	0xbd, 0xb8, 0x1a, 0x5a, 0x1f, 0xbe, 0x0c, 0x41, 0x74, 0xd3, 0x84, 0x0e, 0xa0, 0x79, 0x77, 0xdf,
+	0x1e, 0xb5, 0xc6, 0x9d, 0xc3, 0xbb, 0x81, 0xb1, 0x45, 0x71, 0x29, 0xa4, 0xc8, 0xa8, 0x8a, 0xac,
+	0x2a, 0xa9, 0xf0, 0x1d, 0x43, 0xa6, 0x62, 0x14, 0xc0, 0x5d, 0x5e, 0xa8, 0x83, 0x4b, 0xdf, 0xd3,
+	0xcd, 0xfd, 0x3f, 0x46, 0x1f, 0xb3, 0x65, 0xb4, 0xdd, 0x84, 0xfa, 0xb0, 0x9d, 0x67, 0x8b, 0x4c,
+	0xfa, 0x70, 0x04, 0xc6, 0xad, 0xc8, 0x24, 0xe8, 0x00, 0xde, 0x29, 0x79, 0x25, 0x12, 0xfa, 0x5a,
+	0x52, 0x46, 0x98, 0x2c, 0xfd, 0xce, 0xa8, 0x35, 0xf6, 0xa2, 0xae, 0xa9, 0xbe, 0x34, 0xc5, 0xa9,
+	0xed, 0xb6, 0x7b, 0xce, 0xd4, 0x76, 0x77, 0x7b, 0xee, 0xd4, 0x76, 0xdd, 0x9e, 0xb7, 0xff, 0x7d,
+	0x07, 0xba, 0x5b, 0x4c, 0xc5, 0x47, 0xdf, 0x16, 0x62, 0xeb, 0x9c, 0x8a, 0xd1, 0x3d, 0xe8, 0x08,
+	0x9a, 0x70, 0x31, 0x6b, 0x6c, 0x6b, 0x32, 0xc5, 0x41, 0x72, 0x2a, 0xa4, 0x36, 0xcc, 0x8b, 0x4c,
+	0x82, 0x9e, 0xc0, 0xd6, 0x9c, 0x0b, 0xdf, 0xfe, 0x77, 0x13, 0xd5, 0x7e, 0xc4, 0xa1, 0x93, 0x93,
+	0x98, 0xe6, 0xa5, 0xdf, 0xd6, 0x1e, 0x0c, 0x82, 0x9b, 0xaf, 0x7c, 0x4a, 0x53, 0x92, 0x2c, 0x4f,
+	0x95, 0xfa, 0x82, 0x64, 0x62, 0xf2, 0x54, 0x75, 0x7e, 0xbe, 0x1a, 0x3e, 0x4e, 0x33, 0xf9, 0xa6,
+	0x8a, 0x83, 0x84, 0x2f, 0xc2, 0x54, 0x90, 0x39, 0x61, 0x24, 0xcc, 0xf9, 0x59, 0x16, 0x9e, 0x1f,
+	0x85, 0xb7, 0xff, 0x97, 0x40, 0xb7, 0x1e, 0xcf, 0x48, 0x21, 0xa9, 0x88, 0x9a, 0x31, 0x68, 0x09,
+	0x3b, 0x84, 0x31, 0x2e, 0x89, 0x71, 0xde, 0xf9, 0xbf, 0x53, 0x6f, 0xcf, 0xd2, 0xee, 0x77, 0x27,
+	0xf1, 0x6a, 0x8d, 0xad, 0xcb, 0x35, 0xb6, 0xae, 0xd7, 0x18, 0xbc, 0xab, 0x31, 0xf8, 0x58, 0x63,
+	0x70, 0x51, 0x63, 0xb0, 0xaa, 0x31, 0xf8, 0x5a, 0x63, 0xf0, 0xad, 0xc6, 0xd6, 0x75, 0x8d, 0xc1,
+	0xfb, 0x0d, 0xb6, 0x56, 0x1b, 0x6c, 0x5d, 0x6e, 0xb0, 0xf5, 0xea, 0xd1, 0x5f, 0xc6, 0xff, 0x72,
+	0x37, 0x63, 0x47, 0xa3, 0x1c, 0xfd, 0x08, 0x00, 0x00, 0xff, 0xff, 0xce, 0xf5, 0x95, 0xcf, 0xb7,
+	0x03, 0x00, 0x00,
 }
 
 func (this *RuleGroupDesc) Equal(that interface{}) bool {
@@ -291,6 +302,14 @@ func (this *RuleGroupDesc) Equal(that interface{}) bool {
 	if this.Limit != that1.Limit {
 		return false
 	}
+	if len(this.SourceTenants) != len(that1.SourceTenants) {
+		return false
+	}
+	for i := range this.SourceTenants {
+		if this.SourceTenants[i] != that1.SourceTenants[i] {
+			return false
+		}
+	}
 	return true
 }
 func (this *RuleDesc) Equal(that interface{}) bool {
@@ -346,7 +365,7 @@ func (this *RuleGroupDesc) GoString() string {
 	if this == nil {
 		return "nil"
 	}
-	s := make([]string, 0, 11)
+	s := make([]string, 0, 12)
 	s = append(s, "&rulespb.RuleGroupDesc{")
 	s = append(s, "Name: "+fmt.Sprintf("%#v", this.Name)+",\n")
 	s = append(s, "Namespace: "+fmt.Sprintf("%#v", this.Namespace)+",\n")
@@ -359,6 +378,7 @@ func (this *RuleGroupDesc) GoString() string {
 		s = append(s, "Options: "+fmt.Sprintf("%#v", this.Options)+",\n")
 	}
 	s = append(s, "Limit: "+fmt.Sprintf("%#v", this.Limit)+",\n")
+	s = append(s, "SourceTenants: "+fmt.Sprintf("%#v", this.SourceTenants)+",\n")
 	s = append(s, "}")
 	return strings.Join(s, "")
 }
@@ -405,6 +425,15 @@ func (m *RuleGroupDesc) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if len(m.SourceTenants) > 0 {
+		for iNdEx := len(m.SourceTenants) - 1; iNdEx >= 0; iNdEx-- {
+			i -= len(m.SourceTenants[iNdEx])
+			copy(dAtA[i:], m.SourceTenants[iNdEx])
+			i = encodeVarintRules(dAtA, i, uint64(len(m.SourceTenants[iNdEx])))
+			i--
+			dAtA[i] = 0x5a
+		}
+	}
 	if m.Limit != 0 {
 		i = encodeVarintRules(dAtA, i, uint64(m.Limit))
 		i--
@@ -596,6 +625,12 @@ func (m *RuleGroupDesc) Size() (n int) {
 	if m.Limit != 0 {
 		n += 1 + sovRules(uint64(m.Limit))
 	}
+	if len(m.SourceTenants) > 0 {
+		for _, s := range m.SourceTenants {
+			l = len(s)
+			n += 1 + l + sovRules(uint64(l))
+		}
+	}
 	return n
 }
 
@@ -662,6 +697,7 @@ func (this *RuleGroupDesc) String() string {
 		`User:` + fmt.Sprintf("%v", this.User) + `,`,
 		`Options:` + repeatedStringForOptions + `,`,
 		`Limit:` + fmt.Sprintf("%v", this.Limit) + `,`,
+		`SourceTenants:` + fmt.Sprintf("%v", this.SourceTenants) + `,`,
 		`}`,
 	}, "")
 	return s
@@ -934,6 +970,38 @@ func (m *RuleGroupDesc) Unmarshal(dAtA []byte) error {
 					break
 				}
 			}
+		case 11:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field SourceTenants", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRules
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthRules
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthRules
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.SourceTenants = append(m.SourceTenants, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipRules(dAtA[iNdEx:])
@@ -33,6 +33,12 @@ type Client struct {
 	loader promRules.GroupLoader
 }
 
+// FederatedGroupLoader is implemented by group loaders which also load the source
+// tenants of federated rule groups.
+type FederatedGroupLoader interface {
+	LoadFederated(identifier string) ([]rulespb.RuleGroup, []error)
+}
+
 func NewLocalRulesClient(cfg Config, loader promRules.GroupLoader) (*Client, error) {
 	if cfg.Directory == "" {
 		return nil, errors.New("directory required for local rules config")
@@ -177,6 +183,23 @@ func (l *Client) loadAllRulesGroupsForUser(ctx context.Context, userID string) (
 func (l *Client) loadAllRulesGroupsForUserAndNamespace(_ context.Context, userID string, namespace string) (rulespb.RuleGroupList, error) {
 	filename := filepath.Join(l.cfg.Directory, userID, namespace)
 
+	if loader, ok := l.loader.(FederatedGroupLoader); ok {
+		rulegroups, allErrors := loader.LoadFederated(filename)
+		if len(allErrors) > 0 {
+			return nil, errors.Wrapf(allErrors[0], "error parsing %s", filename)
+		}
+
+		var list rulespb.RuleGroupList
+
+		for _, group := range rulegroups {
+			desc := rulespb.ToProto(userID, namespace, group.RuleGroup)
+			desc.SourceTenants = group.SourceTenants
+			list = append(list, desc)
+		}
+
+		return list, nil
+	}
+
 	rulegroups, allErrors := l.loader.Load(filename)
 	if len(allErrors) > 0 {
 		return nil, errors.Wrapf(allErrors[0], "error parsing %s", filename)
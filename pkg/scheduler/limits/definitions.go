@@ -11,6 +11,15 @@ type Limits interface {
 
 	// MaxQueryCapacity returns how much of the available query capacity can be used by this user.
 	MaxQueryCapacity(user string) float64
+
+	// HighPriorityQuerySources returns the query sources whose requests are enqueued with high priority.
+	HighPriorityQuerySources(user string) []string
+
+	// LowPriorityQuerySources returns the query sources whose requests are enqueued with low priority.
+	LowPriorityQuerySources(user string) []string
+
+	// MaxQueryPriority returns the highest priority a request can ask for with the X-Loki-Query-Priority header.
+	MaxQueryPriority(user string) string
 }
 
 func NewQueueLimits(limits Limits) *QueueLimits {
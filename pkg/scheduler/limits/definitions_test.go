@@ -65,8 +65,10 @@ func TestQueueLimitsMaxConsumers(t *testing.T) {
 }
 
 type mockLimits struct {
-	maxQueriers      uint
-	maxQueryCapacity float64
+	maxQueriers         uint
+	maxQueryCapacity    float64
+	highPrioritySources []string
+	lowPrioritySources  []string
 }
 
 func (l mockLimits) MaxQueriersPerUser(_ string) uint {
@@ -76,3 +78,15 @@ func (l mockLimits) MaxQueriersPerUser(_ string) uint {
 func (l mockLimits) MaxQueryCapacity(_ string) float64 {
 	return l.maxQueryCapacity
 }
+
+func (l mockLimits) HighPriorityQuerySources(_ string) []string {
+	return l.highPrioritySources
+}
+
+func (l mockLimits) LowPriorityQuerySources(_ string) []string {
+	return l.lowPrioritySources
+}
+
+func (l mockLimits) MaxQueryPriority(_ string) string {
+	return "normal"
+}
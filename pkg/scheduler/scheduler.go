@@ -76,7 +76,7 @@ type Scheduler struct {
 	// scheduler metrics.
 	connectedQuerierClients  prometheus.GaugeFunc
 	connectedFrontendClients prometheus.GaugeFunc
-	queueDuration            prometheus.Histogram
+	queueDuration            *prometheus.HistogramVec
 	schedulerRunning         prometheus.Gauge
 	inflightRequests         prometheus.Summary
 
@@ -162,12 +162,12 @@ func NewScheduler(cfg Config, schedulerLimits Limits, log log.Logger, ringManage
 		requestQueue:       queue.NewRequestQueue(cfg.MaxOutstandingPerTenant, cfg.QuerierForgetDelay, limits.NewQueueLimits(schedulerLimits), queueMetrics),
 	}
 
-	s.queueDuration = promauto.With(registerer).NewHistogram(prometheus.HistogramOpts{
+	s.queueDuration = promauto.With(registerer).NewHistogramVec(prometheus.HistogramOpts{
 		Namespace: metricsNamespace,
 		Name:      "query_scheduler_queue_duration_seconds",
 		Help:      "Time spend by requests in queue before getting picked up by a querier.",
 		Buckets:   prometheus.DefBuckets,
-	})
+	}, []string{"priority"})
 	s.connectedQuerierClients = promauto.With(registerer).NewGaugeFunc(prometheus.GaugeOpts{
 		Namespace: metricsNamespace,
 		Name:      "query_scheduler_connected_querier_clients",
@@ -224,6 +224,7 @@ type schedulerRequest struct {
 	request         *httpgrpc.HTTPRequest
 	queryRequest    *queryrange.QueryRequest
 	statsEnabled    bool
+	priority        queue.Priority
 
 	queueTime time.Time
 
@@ -393,8 +394,15 @@ func (s *Scheduler) enqueueRequest(frontendContext context.Context, frontendAddr
 		}
 	}
 
+	req.priority = queue.QueryPriority(
+		s.limits,
+		req.tenantID,
+		requestHeader(msg, lokihttpreq.LokiQueryPriorityHeader),
+		requestHeader(msg, string(lokihttpreq.QueryTagsHTTPHeader)),
+	)
+
 	s.activeUsers.UpdateUserTimestamp(req.tenantID, now)
-	return s.requestQueue.Enqueue(req.tenantID, queuePath, req, func() {
+	return s.requestQueue.EnqueueWithPriority(req.tenantID, queuePath, req.priority, req, func() {
 		shouldCancel = false
 
 		s.pendingRequestsMu.Lock()
@@ -403,6 +411,14 @@ func (s *Scheduler) enqueueRequest(frontendContext context.Context, frontendAddr
 	})
 }
 
+// requestHeader returns the value of a header of the request, regardless of whether it is HTTP or protobuf encoded.
+func requestHeader(msg *schedulerpb.FrontendToScheduler, name string) string {
+	if req := msg.GetQueryRequest(); req != nil {
+		return req.Metadata[name]
+	}
+	return lokihttpreq.ExtractHTTPGRPCHeader(msg.GetHttpRequest(), name)
+}
+
 // This method doesn't do removal from the queue.
 func (s *Scheduler) cancelRequestAndRemoveFromPending(frontendAddr string, queryID uint64) {
 	s.pendingRequestsMu.Lock()
@@ -446,7 +462,7 @@ func (s *Scheduler) QuerierLoop(querier schedulerpb.SchedulerForQuerier_QuerierL
 		r := req.(*schedulerRequest)
 
 		reqQueueTime := time.Since(r.queueTime)
-		s.queueDuration.Observe(reqQueueTime.Seconds())
+		s.queueDuration.WithLabelValues(r.priority.String()).Observe(reqQueueTime.Seconds())
 		r.queueSpan.Finish()
 
 		// Add HTTP header to the request containing the query queue time
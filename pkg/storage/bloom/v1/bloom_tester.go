@@ -1,9 +1,11 @@
 package v1
 
 import (
+	"strings"
 	"unicode/utf8"
 
 	"github.com/grafana/regexp"
+	"github.com/prometheus/prometheus/model/labels"
 
 	"github.com/grafana/loki/v3/pkg/logql/log"
 	"github.com/grafana/loki/v3/pkg/logql/log/pattern"
@@ -110,6 +112,109 @@ func simpleFilterToBloomTest(b NGramBuilder, filter syntax.LineFilter) BloomTest
 	}
 }
 
+// ExtractTestableLabelFilters extracts all label filters from an expression that
+// can be tested against the key/value tokens of a bloom filter.
+// Only label filters before the first stage that might add or modify labels, such
+// as parsers or label_format, are extracted, since labels are only tokenized from
+// stream labels and structured metadata, not from the content of the log line.
+// Label filters that cannot be tested at all, e.g. regex or numeric filters, are skipped.
+// E.g. for {app="fake"} | trace_id="abc" | json | level="error"
+// this function will return only the label filter for trace_id.
+func ExtractTestableLabelFilters(expr syntax.Expr) []log.LabelFilterer {
+	if expr == nil {
+		return nil
+	}
+
+	var filters []log.LabelFilterer
+	visitor := &syntax.DepthFirstTraversal{
+		VisitPipelineFn: func(_ syntax.RootVisitor, e *syntax.PipelineExpr) {
+			for _, stage := range e.MultiStages {
+				switch s := stage.(type) {
+				case *syntax.LabelFilterExpr:
+					if _, matchAll := labelFilterToBloomTest(s.LabelFilterer).(matchAllTest); !matchAll {
+						filters = append(filters, s.LabelFilterer)
+					}
+				case *syntax.LineFilterExpr, *syntax.LineFmtExpr, *syntax.DecolorizeExpr:
+					// These stages do not modify labels.
+				default:
+					return
+				}
+			}
+		},
+	}
+	expr.Accept(visitor)
+	return filters
+}
+
+// HasTestableFilters returns whether the expression contains any line or label
+// filters that can be tested against a bloom filter.
+func HasTestableFilters(expr syntax.Expr) bool {
+	return len(ExtractTestableLineFilters(expr)) > 0 || len(ExtractTestableLabelFilters(expr)) > 0
+}
+
+// LabelFiltersToBloomTest converts a list of label filters to a BloomTest.
+// Only equality matchers on non-empty values can be tested, all other filters match all blooms.
+// Note that the blooms must contain key/value tokens, see Schema.HasKeyValueTokens.
+func LabelFiltersToBloomTest(filters ...log.LabelFilterer) BloomTest {
+	tests := make(BloomTests, 0, len(filters))
+	for _, f := range filters {
+		tests = append(tests, labelFilterToBloomTest(f))
+	}
+	return tests
+}
+
+func labelFilterToBloomTest(f log.LabelFilterer) BloomTest {
+	switch filter := f.(type) {
+	case *log.BinaryLabelFilter:
+		left, right := labelFilterToBloomTest(filter.Left), labelFilterToBloomTest(filter.Right)
+		_, leftMatchAll := left.(matchAllTest)
+		_, rightMatchAll := right.(matchAllTest)
+		switch {
+		case filter.And && leftMatchAll:
+			return right
+		case filter.And && rightMatchAll:
+			return left
+		case filter.And:
+			return BloomTests{left, right}
+		case leftMatchAll || rightMatchAll:
+			return MatchAll
+		default:
+			return newOrTest(left, right)
+		}
+	case *log.StringLabelFilter:
+		return matcherToBloomTest(filter.Matcher)
+	case *log.LineFilterLabelFilter:
+		return matcherToBloomTest(filter.Matcher)
+	default:
+		return MatchAll
+	}
+}
+
+func matcherToBloomTest(m *labels.Matcher) BloomTest {
+	// An empty value also matches a missing label, which cannot be tested with a bloom filter.
+	// Internal labels, such as __error__, and labels with the _extracted suffix, which are renamed from structured
+	// metadata that conflicts with stream labels, are not tokenized under their name.
+	if m == nil || m.Type != labels.MatchEqual || m.Value == "" ||
+		strings.HasPrefix(m.Name, "__") || strings.HasSuffix(m.Name, "_extracted") {
+		return MatchAll
+	}
+	return keyValueTest{token: KeyValueToken(nil, m.Name, m.Value)}
+}
+
+type keyValueTest struct {
+	token []byte
+}
+
+// Matches implements the BloomTest interface
+func (k keyValueTest) Matches(bloom filter.Checker) bool {
+	return bloom.Test(k.token)
+}
+
+// MatchesWithPrefixBuf implements the BloomTest interface
+func (k keyValueTest) MatchesWithPrefixBuf(bloom filter.Checker, buf []byte, prefixLen int) bool {
+	return bloom.Test(append(buf[:prefixLen], k.token...))
+}
+
 type bloomCheckerWrapper struct {
 	bloom filter.Checker
 }
@@ -117,3 +117,121 @@ func TestBloomQueryingLogic(t *testing.T) {
 		})
 	}
 }
+
+func TestLabelFilterBloomQueryingLogic(t *testing.T) {
+	// the bloom contains the key/value tokens of the stream labels and structured metadata
+	bloom := fakeBloom{
+		string(KeyValueToken(nil, "app", "fake")),
+		string(KeyValueToken(nil, "trace_id", "abc")),
+		string(KeyValueToken(nil, "user", "bob")),
+	}
+
+	for _, tc := range []struct {
+		desc     string
+		query    string
+		testable bool
+		match    bool
+	}{
+		{
+			desc:     "structured metadata match",
+			query:    `{app="fake"} | trace_id="abc"`,
+			testable: true,
+			match:    true,
+		},
+		{
+			desc:     "structured metadata nomatch",
+			query:    `{app="fake"} | trace_id="def"`,
+			testable: true,
+			match:    false,
+		},
+		{
+			desc:     "stream label match",
+			query:    `{app=~"fa.*"} | app="fake"`,
+			testable: true,
+			match:    true,
+		},
+		{
+			desc:     "and nomatch",
+			query:    `{app="fake"} | trace_id="abc" and user="alice"`,
+			testable: true,
+			match:    false,
+		},
+		{
+			desc:     "or match",
+			query:    `{app="fake"} | trace_id="def" or user="bob"`,
+			testable: true,
+			match:    true,
+		},
+		{
+			desc:     "or with untestable filter always matches",
+			query:    `{app="fake"} | trace_id="def" or user=~"ali.*"`,
+			testable: false,
+			match:    true,
+		},
+		{
+			desc:     "multiple filters",
+			query:    `{app="fake"} |= "foo" | trace_id="abc" | user="alice"`,
+			testable: true,
+			match:    false,
+		},
+		{
+			desc:     "negated filter always matches",
+			query:    `{app="fake"} | trace_id!="abc"`,
+			testable: false,
+			match:    true,
+		},
+		{
+			desc:     "empty value always matches",
+			query:    `{app="fake"} | trace_id=""`,
+			testable: false,
+			match:    true,
+		},
+		{
+			desc:     "numeric filter always matches",
+			query:    `{app="fake"} | status > 400`,
+			testable: false,
+			match:    true,
+		},
+		{
+			desc:     "internal label always matches",
+			query:    `{app="fake"} | __error__="JSONParserErr"`,
+			testable: false,
+			match:    true,
+		},
+		{
+			desc:     "filter after parser always matches",
+			query:    `{app="fake"} | json | trace_id="def"`,
+			testable: false,
+			match:    true,
+		},
+		{
+			desc:     "filter after label_format always matches",
+			query:    `{app="fake"} | label_format trace_id="{{.user}}" | trace_id="def"`,
+			testable: false,
+			match:    true,
+		},
+		{
+			desc:     "filter after line_format",
+			query:    `{app="fake"} | line_format "{{.user}}" | trace_id="def"`,
+			testable: true,
+			match:    false,
+		},
+		{
+			desc:     "metric query",
+			query:    `count_over_time({app="fake"} | trace_id="def" [5m])`,
+			testable: true,
+			match:    false,
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			expr, err := syntax.ParseExpr(tc.query)
+			require.NoError(t, err)
+			filters := ExtractTestableLabelFilters(expr)
+			require.Equal(t, tc.testable, len(filters) > 0)
+			require.Equal(t, tc.testable, HasTestableFilters(expr))
+
+			bloomTests := LabelFiltersToBloomTest(filters...)
+			require.Equal(t, tc.match, bloomTests.Matches(bloom))
+		})
+	}
+}
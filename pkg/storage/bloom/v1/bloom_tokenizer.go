@@ -7,6 +7,7 @@ import (
 
 	"github.com/go-kit/log/level"
 	"github.com/pkg/errors"
+	"github.com/prometheus/prometheus/model/labels"
 
 	"github.com/grafana/dskit/multierror"
 
@@ -21,6 +22,8 @@ BloomTokenizer is a utility that converts either Loki chunks or individual lines
 These tokens are n-grams, representing adjacent letters, that are used to populate a bloom filter.
 https://en.wikipedia.org/wiki/Bloom_filter
 Bloom filters are utilized for faster lookups of log lines.
+In addition, the stream labels and the structured metadata of the log lines are added as key/value tokens,
+so that label filters on them can be tested as well.
 */
 type BloomTokenizer struct {
 	metrics *Metrics
@@ -85,7 +88,9 @@ func prefixedToken(ngram int, chk ChunkRef, buf []byte) ([]byte, int) {
 // ChunkRefWithIter is a wrapper around a ChunkRef and an EntryIterator.
 type ChunkRefWithIter struct {
 	Ref ChunkRef
-	Itr iter.EntryIterator
+	// Labels are the labels of the stream the chunk belongs to.
+	Labels labels.Labels
+	Itr    iter.EntryIterator
 }
 
 // Populate adds the tokens from the given chunks to the given seriesWithBloom.
@@ -114,16 +119,30 @@ func (bt *BloomTokenizer) Populate(swb *SeriesWithBloom, chks Iterator[ChunkRefW
 			chunkCollisionInserts  int
 			chk                    = chks.At()
 			itr                    = chk.Itr
+			kvInserts              insertCounts
 		)
 		tokenBuf, prefixLn = prefixedToken(bt.lineTokenizer.N(), chk.Ref, tokenBuf)
 
+		// The stream labels are tokenized as well, since label filters before any parser stage
+		// can match on both stream labels and structured metadata.
+		for _, l := range chk.Labels {
+			tokenBuf = bt.addKeyValue(swb.Bloom, tokenBuf, prefixLn, l.Name, l.Value, &kvInserts)
+		}
+
 		// Iterate over lines in the chunk
 		for itr.Next() && itr.Error() == nil {
 			// TODO(owen-d): rather than iterate over the line twice, once for prefixed tokenizer & once for
 			// raw tokenizer, we could iterate once and just return (prefix, token) pairs from the tokenizer.
 			// Double points for them being different-ln references to the same data.
-			line := itr.Entry().Line
+			entry := itr.Entry()
+			line := entry.Line
 			sourceBytes += len(line)
+
+			for _, l := range entry.StructuredMetadata {
+				sourceBytes += len(l.Name) + len(l.Value)
+				tokenBuf = bt.addKeyValue(swb.Bloom, tokenBuf, prefixLn, l.Name, l.Value, &kvInserts)
+			}
+
 			chunkTokenizer := NewPrefixedTokenIter(tokenBuf, prefixLn, bt.lineTokenizer.Tokens(line))
 			for chunkTokenizer.Next() {
 				tok := chunkTokenizer.At()
@@ -194,6 +213,10 @@ func (bt *BloomTokenizer) Populate(swb *SeriesWithBloom, chks Iterator[ChunkRefW
 		bt.metrics.insertsTotal.WithLabelValues(tokenTypeChunkPrefixed, collisionTypeFalse).Add(float64(chunkSuccessfulInserts))
 		bt.metrics.insertsTotal.WithLabelValues(tokenTypeChunkPrefixed, collisionTypeCache).Add(float64(chunkCachedInserts))
 		bt.metrics.insertsTotal.WithLabelValues(tokenTypeChunkPrefixed, collisionTypeTrue).Add(float64(chunkCollisionInserts))
+		bt.metrics.tokensTotal.Add(float64(kvInserts.tokens))
+		bt.metrics.insertsTotal.WithLabelValues(tokenTypeKeyValue, collisionTypeFalse).Add(float64(kvInserts.successful))
+		bt.metrics.insertsTotal.WithLabelValues(tokenTypeKeyValue, collisionTypeCache).Add(float64(kvInserts.cached))
+		bt.metrics.insertsTotal.WithLabelValues(tokenTypeKeyValue, collisionTypeTrue).Add(float64(kvInserts.collision))
 	}
 
 	if err := chks.Err(); err != nil {
@@ -213,6 +236,36 @@ func (bt *BloomTokenizer) Populate(swb *SeriesWithBloom, chks Iterator[ChunkRefW
 	return sourceBytes, nil
 }
 
+type insertCounts struct {
+	tokens, successful, cached, collision int
+}
+
+// addKeyValue adds both the raw and the chunk prefixed token of a key/value pair to the bloom.
+// It returns the token buffer, which may have been grown to fit the token.
+func (bt *BloomTokenizer) addKeyValue(bloom *Bloom, tokenBuf []byte, prefixLn int, name, value string, counts *insertCounts) []byte {
+	tokenBuf = KeyValueToken(tokenBuf[:prefixLn], name, value)
+	for _, tok := range [][]byte{tokenBuf[prefixLn:], tokenBuf} {
+		counts.tokens++
+		str := string(tok)
+		if _, found := bt.cache[str]; found {
+			counts.cached++
+			continue
+		}
+		bt.cache[str] = nil
+
+		if bloom.ScalableBloomFilter.TestAndAdd(tok) {
+			counts.collision++
+		} else {
+			counts.successful++
+		}
+
+		if len(bt.cache) >= cacheSize {
+			clearCache(bt.cache)
+		}
+	}
+	return tokenBuf
+}
+
 // n ≈ −m ln(1 − p).
 func estimatedCount(m uint, p float64) uint {
 	return uint(-float64(m) * math.Log(1-p))
@@ -135,6 +135,44 @@ func TestTokenizerPopulate(t *testing.T) {
 	}
 }
 
+func TestTokenizerPopulateKeyValues(t *testing.T) {
+	t.Parallel()
+	bt := NewBloomTokenizer(DefaultNGramLength, DefaultNGramSkip, metrics)
+
+	memChunk := chunkenc.NewMemChunk(chunkenc.ChunkFormatV4, chunkenc.EncSnappy, chunkenc.ChunkHeadFormatFor(chunkenc.ChunkFormatV4), 256000, 1500000)
+	_ = memChunk.Append(&push.Entry{
+		Timestamp:          time.Unix(0, 1),
+		Line:               "this is a log line",
+		StructuredMetadata: push.LabelsAdapter{{Name: "trace_id", Value: "3c0e3dcd33e7"}},
+	})
+	itr, err := memChunk.Iterator(
+		context.Background(),
+		time.Unix(0, 0),
+		time.Unix(0, math.MaxInt64),
+		logproto.FORWARD,
+		log.NewNoopPipeline().ForStream(nil),
+	)
+	require.Nil(t, err)
+
+	ref := ChunkRef{From: 1, Through: 2, Checksum: 3}
+	lbs := labels.FromStrings("app", "fake")
+	swb := SeriesWithBloom{
+		Bloom:  &Bloom{ScalableBloomFilter: *filter.NewScalableBloomFilter(1024, 0.01, 0.8)},
+		Series: &Series{Fingerprint: model.Fingerprint(lbs.Hash())},
+	}
+
+	_, err = bt.Populate(&swb, NewSliceIter([]ChunkRefWithIter{{Ref: ref, Labels: lbs, Itr: itr}}))
+	require.NoError(t, err)
+
+	prefix, prefixLn := prefixedToken(DefaultNGramLength, ref, nil)
+	for _, kv := range [][2]string{{"app", "fake"}, {"trace_id", "3c0e3dcd33e7"}} {
+		tok := KeyValueToken(nil, kv[0], kv[1])
+		require.True(t, swb.Bloom.Test(tok))
+		require.True(t, swb.Bloom.Test(KeyValueToken(prefix[:prefixLn], kv[0], kv[1])))
+	}
+	require.False(t, swb.Bloom.Test(KeyValueToken(nil, "trace_id", "0000000000")))
+}
+
 func BenchmarkPopulateSeriesWithBloom(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		var testLine = lorem + lorem + lorem
@@ -72,7 +72,7 @@ type BlockBuilder struct {
 
 func NewBlockOptions(enc chunkenc.Encoding, NGramLength, NGramSkip, MaxBlockSizeBytes uint64) BlockOptions {
 	opts := NewBlockOptionsFromSchema(Schema{
-		version:     byte(1),
+		version:     DefaultSchemaVersion,
 		encoding:    enc,
 		nGramLength: NGramLength,
 		nGramSkip:   NGramSkip,
@@ -30,6 +30,7 @@ const (
 
 	tokenTypeRaw           = "raw"
 	tokenTypeChunkPrefixed = "chunk_prefixed"
+	tokenTypeKeyValue      = "key_value"
 	collisionTypeFalse     = "false"
 	collisionTypeTrue      = "true"
 	collisionTypeCache     = "cache"
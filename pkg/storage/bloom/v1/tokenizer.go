@@ -18,6 +18,14 @@ func reassemble(buf []rune, ln, pos int, result []byte) []byte {
 	return result
 }
 
+// KeyValueToken appends the token of a key/value pair, such as a structured metadata or stream label, to buf.
+// Unlike log lines, key/value pairs are not split into n-grams, so only exact matches of the value can be tested.
+func KeyValueToken(buf []byte, name, value string) []byte {
+	buf = append(buf, name...)
+	buf = append(buf, '=')
+	return append(buf, value...)
+}
+
 // Iterable variants (more performant, less space)
 type NGramTokenizer struct {
 	n, skip int
@@ -0,0 +1,441 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"hash/crc32"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/grafana/loki/v3/pkg/logqlmodel/stats"
+	"github.com/grafana/loki/v3/pkg/util/constants"
+)
+
+const (
+	diskCacheTmpDir    = "tmp"
+	diskCacheMagic     = uint32(0x4c4b4443) // "LKDC"
+	diskCacheHeaderLen = 4 + 4              // magic + key length
+
+	corruptedReason = "corrupted"
+)
+
+var diskCacheCastagnoliTable = crc32.MakeTable(crc32.Castagnoli)
+
+// DiskCacheConfig represents the local disk cache config.
+type DiskCacheConfig struct {
+	Enabled   bool          `yaml:"enabled,omitempty"`
+	Directory string        `yaml:"directory"`
+	MaxSizeMB int64         `yaml:"max_size_mb"`
+	TTL       time.Duration `yaml:"ttl"`
+
+	// PurgeInterval tell how often should we remove keys that are expired.
+	// by default it takes `defaultPurgeInterval`
+	PurgeInterval time.Duration `yaml:"-"`
+}
+
+func (cfg *DiskCacheConfig) RegisterFlagsWithPrefix(prefix, description string, f *flag.FlagSet) {
+	f.BoolVar(&cfg.Enabled, prefix+"enabled", false, description+"Whether the local disk cache is enabled. When the embedded cache is enabled as well, the disk cache is used as a second tier behind it.")
+	f.StringVar(&cfg.Directory, prefix+"directory", "", description+"Directory where the cache entries are stored. Entries stored in the directory are kept across restarts.")
+	f.Int64Var(&cfg.MaxSizeMB, prefix+"max-size-mb", 10000, description+"Maximum size of the cache on disk in MB. The least recently used entries are evicted when it is exceeded.")
+	f.DurationVar(&cfg.TTL, prefix+"ttl", 24*time.Hour, description+"The time to live for items in the cache before they get purged. 0 disables expiration.")
+}
+
+func (cfg *DiskCacheConfig) IsEnabled() bool {
+	return cfg.Enabled
+}
+
+func (cfg *DiskCacheConfig) Validate() error {
+	if !cfg.Enabled {
+		return nil
+	}
+	if cfg.Directory == "" {
+		return errors.New("disk cache directory must be set")
+	}
+	if cfg.MaxSizeMB <= 0 {
+		return errors.New("disk cache max size must be > 0")
+	}
+	return nil
+}
+
+type diskCacheEntry struct {
+	file    string
+	size    uint64
+	updated time.Time
+}
+
+// DiskCache is a size bounded cache storing its entries as files in a local directory, so
+// that they survive restarts. It evicts the least recently used entries when it is full.
+//
+// Each entry is written to a temporary file which is renamed in place once complete, and
+// holds its key and a checksum of its content. Entries torn by a crash, or whose file
+// holds another key, are discarded when read.
+type DiskCache struct {
+	cacheType stats.CacheType
+	logger    log.Logger
+
+	dir          string
+	ttl          time.Duration
+	maxSizeBytes uint64
+
+	lock          sync.Mutex
+	currSizeBytes uint64
+	entries       map[string]*list.Element
+	lru           *list.List
+
+	done     chan struct{}
+	stopOnce sync.Once
+
+	entriesAddedNew prometheus.Counter
+	entriesEvicted  *prometheus.CounterVec
+	entriesCurrent  prometheus.Gauge
+	sizeBytes       prometheus.Gauge
+}
+
+// NewDiskCache returns a new DiskCache, loading the entries already stored in its directory.
+func NewDiskCache(name string, cfg DiskCacheConfig, reg prometheus.Registerer, logger log.Logger, cacheType stats.CacheType) (*DiskCache, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	// several caches may share the configured directory.
+	dir := filepath.Join(cfg.Directory, strings.Trim(strings.ReplaceAll(name, ".", "-"), "-"))
+	if err := os.MkdirAll(filepath.Join(dir, diskCacheTmpDir), 0o750); err != nil {
+		return nil, err
+	}
+
+	if cfg.PurgeInterval == 0 {
+		cfg.PurgeInterval = defaultPurgeInterval
+	}
+
+	c := &DiskCache{
+		cacheType:    cacheType,
+		logger:       log.With(logger, "cache", name),
+		dir:          dir,
+		ttl:          cfg.TTL,
+		maxSizeBytes: uint64(cfg.MaxSizeMB * 1e6),
+		entries:      map[string]*list.Element{},
+		lru:          list.New(),
+		done:         make(chan struct{}),
+
+		entriesAddedNew: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Namespace:   constants.Loki,
+			Subsystem:   "diskcache",
+			Name:        "added_new_total",
+			Help:        "The total number of new entries added to the cache",
+			ConstLabels: prometheus.Labels{"cache": name},
+		}),
+		entriesEvicted: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Namespace:   constants.Loki,
+			Subsystem:   "diskcache",
+			Name:        "evicted_total",
+			Help:        "The total number of evicted entries",
+			ConstLabels: prometheus.Labels{"cache": name},
+		}, []string{"reason"}),
+		entriesCurrent: promauto.With(reg).NewGauge(prometheus.GaugeOpts{
+			Namespace:   constants.Loki,
+			Subsystem:   "diskcache",
+			Name:        "entries",
+			Help:        "Current number of entries in the cache",
+			ConstLabels: prometheus.Labels{"cache": name},
+		}),
+		sizeBytes: promauto.With(reg).NewGauge(prometheus.GaugeOpts{
+			Namespace:   constants.Loki,
+			Subsystem:   "diskcache",
+			Name:        "size_bytes",
+			Help:        "The current size of the cache on disk in bytes",
+			ConstLabels: prometheus.Labels{"cache": name},
+		}),
+	}
+
+	if err := c.load(); err != nil {
+		return nil, fmt.Errorf("loading disk cache entries: %w", err)
+	}
+
+	if c.ttl > 0 {
+		go c.runPruneJob(cfg.PurgeInterval)
+	}
+	return c, nil
+}
+
+// load indexes the entries stored in the directory, ordered by their modification time
+// as an approximation of their last use, and removes the leftovers of interrupted writes.
+func (c *DiskCache) load() error {
+	if err := os.RemoveAll(filepath.Join(c.dir, diskCacheTmpDir)); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Join(c.dir, diskCacheTmpDir), 0o750); err != nil {
+		return err
+	}
+
+	var entries []*diskCacheEntry
+	err := filepath.WalkDir(c.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == diskCacheTmpDir {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		entries = append(entries, &diskCacheEntry{file: d.Name(), size: uint64(info.Size()), updated: info.ModTime()})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].updated.Before(entries[j].updated) })
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for _, entry := range entries {
+		c.entries[entry.file] = c.lru.PushFront(entry)
+		c.currSizeBytes += entry.size
+		c.entriesCurrent.Inc()
+	}
+	c.evict(0)
+	c.sizeBytes.Set(float64(c.currSizeBytes))
+
+	level.Info(c.logger).Log("msg", "loaded disk cache entries", "entries", len(c.entries), "bytes", c.currSizeBytes)
+	return nil
+}
+
+func (c *DiskCache) runPruneJob(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-ticker.C:
+			c.pruneExpiredItems()
+		}
+	}
+}
+
+// pruneExpiredItems prunes items in the cache that exceeded their ttl
+func (c *DiskCache) pruneExpiredItems() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for file, element := range c.entries {
+		if time.Since(element.Value.(*diskCacheEntry).updated) > c.ttl {
+			c.remove(file, element, expiredReason)
+		}
+	}
+	c.sizeBytes.Set(float64(c.currSizeBytes))
+}
+
+// Store implements Cache.
+func (c *DiskCache) Store(_ context.Context, keys []string, bufs [][]byte) error {
+	var lastErr error
+	for i := range keys {
+		if err := c.put(keys[i], bufs[i]); err != nil {
+			level.Warn(c.logger).Log("msg", "failed to store entry in disk cache", "err", err)
+			lastErr = err
+		}
+	}
+	return lastErr
+}
+
+func (c *DiskCache) put(key string, buf []byte) error {
+	file := diskCacheFileName(key)
+	size := uint64(diskCacheHeaderLen + len(key) + len(buf) + crc32.Size)
+	if size > c.maxSizeBytes {
+		c.entriesEvicted.WithLabelValues(tooBigReason).Inc()
+		return nil
+	}
+
+	// write the entry to a temporary file first, so that readers never see a partial entry.
+	tmp, err := os.CreateTemp(filepath.Join(c.dir, diskCacheTmpDir), file)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(encodeDiskCacheEntry(key, buf)); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(c.path(file)), 0o750); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), c.path(file)); err != nil {
+		return err
+	}
+
+	element, replaced := c.entries[file]
+	if replaced {
+		entry := element.Value.(*diskCacheEntry)
+		c.currSizeBytes -= entry.size
+		entry.size, entry.updated = size, time.Now()
+		c.lru.MoveToFront(element)
+	} else {
+		c.entries[file] = c.lru.PushFront(&diskCacheEntry{file: file, size: size, updated: time.Now()})
+		c.entriesAddedNew.Inc()
+		c.entriesCurrent.Inc()
+	}
+	c.currSizeBytes += size
+
+	c.evict(0)
+	c.sizeBytes.Set(float64(c.currSizeBytes))
+	return nil
+}
+
+// evict removes the least recently used entries until the cache has room for the given size.
+// It must be called with the lock held.
+func (c *DiskCache) evict(size uint64) {
+	for c.currSizeBytes+size > c.maxSizeBytes {
+		element := c.lru.Back()
+		if element == nil {
+			return
+		}
+		c.remove(element.Value.(*diskCacheEntry).file, element, fullReason)
+	}
+}
+
+// remove removes an entry and its file. It must be called with the lock held.
+func (c *DiskCache) remove(file string, element *list.Element, reason string) {
+	entry := c.lru.Remove(element).(*diskCacheEntry)
+	delete(c.entries, file)
+	c.currSizeBytes -= entry.size
+	c.entriesCurrent.Dec()
+	c.entriesEvicted.WithLabelValues(reason).Inc()
+
+	if err := os.Remove(c.path(file)); err != nil && !os.IsNotExist(err) {
+		level.Warn(c.logger).Log("msg", "failed to remove disk cache entry", "file", file, "err", err)
+	}
+}
+
+// Fetch implements Cache.
+func (c *DiskCache) Fetch(_ context.Context, keys []string) (found []string, bufs [][]byte, missing []string, err error) {
+	found, bufs, missing = make([]string, 0, len(keys)), make([][]byte, 0, len(keys)), make([]string, 0, len(keys))
+	for _, key := range keys {
+		buf, ok := c.get(key)
+		if !ok {
+			missing = append(missing, key)
+			continue
+		}
+		found = append(found, key)
+		bufs = append(bufs, buf)
+	}
+	return found, bufs, missing, nil
+}
+
+func (c *DiskCache) get(key string) ([]byte, bool) {
+	file := diskCacheFileName(key)
+
+	c.lock.Lock()
+	element, ok := c.entries[file]
+	if !ok {
+		c.lock.Unlock()
+		return nil, false
+	}
+	if c.ttl > 0 && time.Since(element.Value.(*diskCacheEntry).updated) > c.ttl {
+		c.remove(file, element, expiredReason)
+		c.sizeBytes.Set(float64(c.currSizeBytes))
+		c.lock.Unlock()
+		return nil, false
+	}
+	c.lru.MoveToFront(element)
+	c.lock.Unlock()
+
+	data, err := os.ReadFile(c.path(file))
+	if err == nil {
+		var storedKey string
+		var buf []byte
+		if storedKey, buf, err = decodeDiskCacheEntry(data); err == nil && storedKey == key {
+			return buf, true
+		}
+	}
+
+	// the entry was removed concurrently, torn by a crash or is a hash collision.
+	if err != nil && !os.IsNotExist(err) {
+		level.Warn(c.logger).Log("msg", "discarding corrupted disk cache entry", "file", file, "err", err)
+		c.lock.Lock()
+		if element, ok := c.entries[file]; ok {
+			c.remove(file, element, corruptedReason)
+			c.sizeBytes.Set(float64(c.currSizeBytes))
+		}
+		c.lock.Unlock()
+	}
+	return nil, false
+}
+
+// Stop implements Cache. The entries are kept on disk to be loaded on the next start.
+func (c *DiskCache) Stop() {
+	c.stopOnce.Do(func() {
+		close(c.done)
+	})
+}
+
+func (c *DiskCache) GetCacheType() stats.CacheType {
+	return c.cacheType
+}
+
+// path returns the path of the file of an entry, spread in sub directories to keep them small.
+func (c *DiskCache) path(file string) string {
+	return filepath.Join(c.dir, file[:2], file)
+}
+
+func diskCacheFileName(key string) string {
+	h := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(h[:])
+}
+
+// encodeDiskCacheEntry encodes an entry as: magic | key length | key | value | crc32 of all the previous bytes.
+func encodeDiskCacheEntry(key string, buf []byte) []byte {
+	b := make([]byte, diskCacheHeaderLen, diskCacheHeaderLen+len(key)+len(buf)+crc32.Size)
+	binary.BigEndian.PutUint32(b[0:4], diskCacheMagic)
+	binary.BigEndian.PutUint32(b[4:8], uint32(len(key)))
+	b = append(b, key...)
+	b = append(b, buf...)
+	return binary.BigEndian.AppendUint32(b, crc32.Checksum(b, diskCacheCastagnoliTable))
+}
+
+func decodeDiskCacheEntry(b []byte) (string, []byte, error) {
+	if len(b) < diskCacheHeaderLen+crc32.Size {
+		return "", nil, errors.New("entry too short")
+	}
+	if binary.BigEndian.Uint32(b[0:4]) != diskCacheMagic {
+		return "", nil, errors.New("invalid magic number")
+	}
+	content, checksum := b[:len(b)-crc32.Size], binary.BigEndian.Uint32(b[len(b)-crc32.Size:])
+	if crc32.Checksum(content, diskCacheCastagnoliTable) != checksum {
+		return "", nil, errors.New("checksum mismatch")
+	}
+	keyLen := int(binary.BigEndian.Uint32(b[4:8]))
+	if diskCacheHeaderLen+keyLen > len(content) {
+		return "", nil, errors.New("invalid key length")
+	}
+	return string(content[diskCacheHeaderLen : diskCacheHeaderLen+keyLen]), content[diskCacheHeaderLen+keyLen:], nil
+}
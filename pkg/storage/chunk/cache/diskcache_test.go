@@ -0,0 +1,143 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestDiskCache(t *testing.T, cfg DiskCacheConfig) *DiskCache {
+	c, err := NewDiskCache("test", cfg, prometheus.NewRegistry(), log.NewNopLogger(), "test")
+	require.NoError(t, err)
+	t.Cleanup(c.Stop)
+	return c
+}
+
+func TestDiskCache(t *testing.T) {
+	ctx := context.Background()
+	cfg := DiskCacheConfig{Enabled: true, Directory: t.TempDir(), MaxSizeMB: 1}
+	c := newTestDiskCache(t, cfg)
+
+	require.NoError(t, c.Store(ctx, []string{"foo", "bar"}, [][]byte{[]byte("foo-value"), []byte("bar-value")}))
+	found, bufs, missing, err := c.Fetch(ctx, []string{"foo", "bar", "baz"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"foo", "bar"}, found)
+	require.Equal(t, [][]byte{[]byte("foo-value"), []byte("bar-value")}, bufs)
+	require.Equal(t, []string{"baz"}, missing)
+
+	// replacing an entry does not count as a new one.
+	require.NoError(t, c.Store(ctx, []string{"foo"}, [][]byte{[]byte("new-value")}))
+	_, bufs, _, err = c.Fetch(ctx, []string{"foo"})
+	require.NoError(t, err)
+	require.Equal(t, [][]byte{[]byte("new-value")}, bufs)
+	require.Equal(t, float64(2), testutil.ToFloat64(c.entriesAddedNew))
+	require.Equal(t, float64(2), testutil.ToFloat64(c.entriesCurrent))
+	require.Equal(t, float64(c.currSizeBytes), testutil.ToFloat64(c.sizeBytes))
+
+	// the entries are loaded back after a restart.
+	c.Stop()
+	c = newTestDiskCache(t, cfg)
+	require.Equal(t, float64(2), testutil.ToFloat64(c.entriesCurrent))
+	found, bufs, missing, err = c.Fetch(ctx, []string{"foo", "bar"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"foo", "bar"}, found)
+	require.Equal(t, [][]byte{[]byte("new-value"), []byte("bar-value")}, bufs)
+	require.Empty(t, missing)
+}
+
+func TestDiskCacheEviction(t *testing.T) {
+	ctx := context.Background()
+	c := newTestDiskCache(t, DiskCacheConfig{Enabled: true, Directory: t.TempDir(), MaxSizeMB: 1})
+
+	// 10 entries of ~0.1MB fill up the cache.
+	value := make([]byte, 1e5-100)
+	for i := 0; i < 10; i++ {
+		require.NoError(t, c.Store(ctx, []string{fmt.Sprintf("key-%d", i)}, [][]byte{value}))
+	}
+	require.Equal(t, float64(10), testutil.ToFloat64(c.entriesCurrent))
+
+	// key-0 is the least recently used one once key-1 has been read.
+	_, _, missing, err := c.Fetch(ctx, []string{"key-1"})
+	require.NoError(t, err)
+	require.Empty(t, missing)
+	require.NoError(t, c.Store(ctx, []string{"key-10"}, [][]byte{value}))
+
+	_, _, missing, err = c.Fetch(ctx, []string{"key-0", "key-1", "key-10"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"key-0"}, missing)
+	require.Equal(t, float64(1), testutil.ToFloat64(c.entriesEvicted.WithLabelValues(fullReason)))
+	require.LessOrEqual(t, c.currSizeBytes, c.maxSizeBytes)
+
+	// entries bigger than the cache are not stored.
+	require.NoError(t, c.Store(ctx, []string{"too-big"}, [][]byte{make([]byte, 2e6)}))
+	require.Equal(t, float64(1), testutil.ToFloat64(c.entriesEvicted.WithLabelValues(tooBigReason)))
+}
+
+func TestDiskCacheExpiry(t *testing.T) {
+	ctx := context.Background()
+	c := newTestDiskCache(t, DiskCacheConfig{Enabled: true, Directory: t.TempDir(), MaxSizeMB: 1, TTL: time.Millisecond, PurgeInterval: time.Hour})
+
+	require.NoError(t, c.Store(ctx, []string{"foo", "bar"}, [][]byte{[]byte("foo"), []byte("bar")}))
+	time.Sleep(5 * time.Millisecond)
+
+	_, _, missing, err := c.Fetch(ctx, []string{"foo"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"foo"}, missing)
+
+	c.pruneExpiredItems()
+	require.Equal(t, float64(2), testutil.ToFloat64(c.entriesEvicted.WithLabelValues(expiredReason)))
+	require.Equal(t, float64(0), testutil.ToFloat64(c.entriesCurrent))
+	require.Equal(t, float64(0), testutil.ToFloat64(c.sizeBytes))
+}
+
+func TestDiskCacheCorruption(t *testing.T) {
+	ctx := context.Background()
+	cfg := DiskCacheConfig{Enabled: true, Directory: t.TempDir(), MaxSizeMB: 1}
+	c := newTestDiskCache(t, cfg)
+	require.NoError(t, c.Store(ctx, []string{"foo", "bar"}, [][]byte{[]byte("foo-value"), []byte("bar-value")}))
+	c.Stop()
+
+	// simulate a torn write and the leftover of an interrupted one.
+	path := c.path(diskCacheFileName("foo"))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, data[:len(data)-2], 0o640))
+	leftover := filepath.Join(c.dir, diskCacheTmpDir, "leftover")
+	require.NoError(t, os.WriteFile(leftover, []byte("partial"), 0o640))
+
+	c = newTestDiskCache(t, cfg)
+	require.NoFileExists(t, leftover)
+
+	found, _, missing, err := c.Fetch(ctx, []string{"foo", "bar"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"bar"}, found)
+	require.Equal(t, []string{"foo"}, missing)
+	require.Equal(t, float64(1), testutil.ToFloat64(c.entriesEvicted.WithLabelValues(corruptedReason)))
+	require.NoFileExists(t, path)
+}
+
+func TestNew_DiskCacheWithoutTTLNeverExpires(t *testing.T) {
+	ctx := context.Background()
+	c, err := New(Config{
+		DefaultValidity: time.Millisecond,
+		DiskCache:       DiskCacheConfig{Enabled: true, Directory: t.TempDir(), MaxSizeMB: 1},
+	}, prometheus.NewRegistry(), log.NewNopLogger(), "test", "loki")
+	require.NoError(t, err)
+	t.Cleanup(c.Stop)
+
+	// the default validity of the other caches does not apply to the disk cache.
+	require.NoError(t, c.Store(ctx, []string{"foo"}, [][]byte{[]byte("foo")}))
+	time.Sleep(5 * time.Millisecond)
+
+	found, _, _, err := c.Fetch(ctx, []string{"foo"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"foo"}, found)
+}
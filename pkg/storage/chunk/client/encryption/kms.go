@@ -0,0 +1,187 @@
+package encryption
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	KMSFile = "file"
+
+	keySize = 32 // AES-256
+
+	fileKMSTenantsDir = "tenants"
+	fileKMSSharedKey  = "shared.key"
+	fileKMSKeyExt     = ".key"
+)
+
+// ErrKeyNotFound is returned when the key of a tenant does not exist, for instance
+// because it has been deleted to crypto-shred the data of the tenant.
+var ErrKeyNotFound = errors.New("encryption key not found")
+
+// KMS wraps the data keys used to encrypt objects with a per-tenant key encryption key.
+// The empty tenant designates the key used for objects shared by several tenants.
+type KMS interface {
+	WrapKey(ctx context.Context, tenant string, dataKey []byte) ([]byte, error)
+	// UnwrapKey returns ErrKeyNotFound if the key of the tenant does not exist anymore.
+	UnwrapKey(ctx context.Context, tenant string, wrappedKey []byte) ([]byte, error)
+}
+
+// NewKMS makes a new KMS based on the configuration.
+func NewKMS(cfg Config) (KMS, error) {
+	switch cfg.KMS {
+	case KMSFile:
+		return NewFileKMS(cfg.File, cfg.KeyCacheTTL)
+	default:
+		return nil, fmt.Errorf("unsupported KMS %q", cfg.KMS)
+	}
+}
+
+// FileKMS is a KMS storing the key encryption key of each tenant in a local file, which
+// is created the first time a data key of the tenant is wrapped. Deleting the file of a
+// tenant makes its data unreadable once the loaded key expired.
+type FileKMS struct {
+	dir string
+	ttl time.Duration
+
+	mtx     sync.Mutex
+	keys    map[string]*fileKMSKey
+	nowFunc func() time.Time
+}
+
+type fileKMSKey struct {
+	aead   cipher.AEAD
+	loaded time.Time
+}
+
+// NewFileKMS makes a new FileKMS storing its keys in the configured directory. Loaded keys
+// are read again from their file after ttl.
+func NewFileKMS(cfg FileKMSConfig, ttl time.Duration) (*FileKMS, error) {
+	if err := os.MkdirAll(filepath.Join(cfg.Directory, fileKMSTenantsDir), 0o700); err != nil {
+		return nil, err
+	}
+	return &FileKMS{dir: cfg.Directory, ttl: ttl, keys: map[string]*fileKMSKey{}, nowFunc: time.Now}, nil
+}
+
+// WrapKey implements KMS.
+func (k *FileKMS) WrapKey(_ context.Context, tenant string, dataKey []byte) ([]byte, error) {
+	aead, err := k.key(tenant, true)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, dataKey, []byte(tenant)), nil
+}
+
+// UnwrapKey implements KMS.
+func (k *FileKMS) UnwrapKey(_ context.Context, tenant string, wrappedKey []byte) ([]byte, error) {
+	aead, err := k.key(tenant, false)
+	if err != nil {
+		return nil, err
+	}
+	if len(wrappedKey) < aead.NonceSize() {
+		return nil, errors.New("wrapped key too short")
+	}
+	nonce, ciphertext := wrappedKey[:aead.NonceSize()], wrappedKey[aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, []byte(tenant))
+}
+
+func (k *FileKMS) key(tenant string, create bool) (cipher.AEAD, error) {
+	k.mtx.Lock()
+	defer k.mtx.Unlock()
+
+	// keys deleted while loaded are forgotten once expired.
+	now := k.nowFunc()
+	if key, ok := k.keys[tenant]; ok && now.Before(key.loaded.Add(k.ttl)) {
+		return key.aead, nil
+	}
+	delete(k.keys, tenant)
+
+	path, err := k.path(tenant)
+	if err != nil {
+		return nil, err
+	}
+	key, err := readKeyFile(path)
+	if os.IsNotExist(err) && create {
+		key, err = createKeyFile(path)
+	}
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("%w for tenant %q", ErrKeyNotFound, tenant)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	k.keys[tenant] = &fileKMSKey{aead: aead, loaded: now}
+	return aead, nil
+}
+
+func (k *FileKMS) path(tenant string) (string, error) {
+	if tenant == "" {
+		return filepath.Join(k.dir, fileKMSSharedKey), nil
+	}
+	if tenant == "." || tenant == ".." || strings.ContainsAny(tenant, `/\`) {
+		return "", fmt.Errorf("invalid tenant %q", tenant)
+	}
+	return filepath.Join(k.dir, fileKMSTenantsDir, tenant+fileKMSKeyExt), nil
+}
+
+func readKeyFile(path string) ([]byte, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	key, err := hex.DecodeString(strings.TrimSpace(string(b)))
+	if err != nil || len(key) != keySize {
+		return nil, fmt.Errorf("invalid key file %s: expected %d hex encoded bytes", path, keySize)
+	}
+	return key, nil
+}
+
+// createKeyFile creates a new random key, unless another process created it concurrently.
+func createKeyFile(path string) ([]byte, error) {
+	key := make([]byte, keySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+
+	tmp := fmt.Sprintf("%s.%x.tmp", path, key[:4])
+	if err := os.WriteFile(tmp, []byte(hex.EncodeToString(key)), 0o600); err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp)
+
+	// linking fails if the key already exists, in which case the existing one is used.
+	if err := os.Link(tmp, path); err != nil {
+		if os.IsExist(err) {
+			return readKeyFile(path)
+		}
+		return nil, err
+	}
+	return key, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,327 @@
+package encryption
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/golang-lru/v2/simplelru"
+
+	"github.com/grafana/loki/v3/pkg/storage/chunk/client"
+)
+
+// magic identifies encrypted objects. Objects without it are returned as they are, so that
+// encryption can be enabled on a store holding unencrypted objects.
+var magic = []byte{'L', 'K', 'E', 0x01}
+
+// Config configures the client-side envelope encryption of objects.
+type Config struct {
+	Enabled               bool          `yaml:"enabled"`
+	KMS                   string        `yaml:"kms"`
+	File                  FileKMSConfig `yaml:"file"`
+	DataKeyRotationPeriod time.Duration `yaml:"data_key_rotation_period"`
+	KeyCacheSize          int           `yaml:"key_cache_size"`
+	KeyCacheTTL           time.Duration `yaml:"key_cache_ttl"`
+}
+
+// FileKMSConfig configures the file based KMS.
+type FileKMSConfig struct {
+	Directory string `yaml:"directory"`
+}
+
+// RegisterFlagsWithPrefix registers flags with prefix.
+func (cfg *Config) RegisterFlagsWithPrefix(prefix string, f *flag.FlagSet) {
+	f.BoolVar(&cfg.Enabled, prefix+"enabled", false, "Encrypt the objects written to object storage with per-tenant keys. Objects written before encryption was enabled remain readable.")
+	f.StringVar(&cfg.KMS, prefix+"kms", KMSFile, "The KMS holding the key encryption keys of the tenants. Supported values are: file.")
+	f.StringVar(&cfg.File.Directory, prefix+"file.directory", "", "Directory where the file KMS stores the key of each tenant. Deleting the key of a tenant makes its data unreadable. The directory must be shared by all the components accessing the object storage.")
+	f.DurationVar(&cfg.DataKeyRotationPeriod, prefix+"data-key-rotation-period", time.Hour, "How long a data key is used to encrypt the objects of a tenant before a new one is generated.")
+	f.IntVar(&cfg.KeyCacheSize, prefix+"key-cache-size", 10000, "Maximum number of unwrapped data keys kept in memory.")
+	f.DurationVar(&cfg.KeyCacheTTL, prefix+"key-cache-ttl", 5*time.Minute, "How long the keys of the tenants and the unwrapped data keys are kept in memory. Objects of a tenant whose key is deleted remain readable, and are still written with the deleted key, up to this duration. Data keys are rotated at least this often.")
+}
+
+func (cfg *Config) Validate() error {
+	if !cfg.Enabled {
+		return nil
+	}
+	switch cfg.KMS {
+	case KMSFile:
+		if cfg.File.Directory == "" {
+			return errors.New("the file KMS directory must be set")
+		}
+	default:
+		return fmt.Errorf("unsupported KMS %q", cfg.KMS)
+	}
+	if cfg.DataKeyRotationPeriod <= 0 {
+		return errors.New("the data key rotation period must be > 0")
+	}
+	if cfg.KeyCacheSize <= 0 {
+		return errors.New("the key cache size must be > 0")
+	}
+	return nil
+}
+
+type dataKey struct {
+	key     []byte
+	wrapped []byte
+	expires time.Time
+}
+
+// ObjectClient encrypts the objects of a downstream client with AES-256-GCM, using data
+// keys wrapped by the KMS key of the tenant owning the object. The wrapped data key is
+// stored alongside the encrypted object.
+type ObjectClient struct {
+	downstream client.ObjectClient
+	kms        KMS
+	cfg        Config
+
+	mtx        sync.Mutex
+	writeKeys  map[string]*dataKey
+	readKeys   *simplelru.LRU[string, *dataKey]
+	nowFunc    func() time.Time
+	tenantFunc func(objectKey string) string
+}
+
+// NewObjectClient makes a new ObjectClient encrypting the objects of the downstream client.
+func NewObjectClient(downstream client.ObjectClient, kms KMS, cfg Config) (*ObjectClient, error) {
+	readKeys, err := simplelru.NewLRU[string, *dataKey](cfg.KeyCacheSize, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &ObjectClient{
+		downstream: downstream,
+		kms:        kms,
+		cfg:        cfg,
+		writeKeys:  map[string]*dataKey{},
+		readKeys:   readKeys,
+		nowFunc:    time.Now,
+		tenantFunc: TenantFromKey,
+	}, nil
+}
+
+func (c *ObjectClient) PutObject(ctx context.Context, objectKey string, object io.ReadSeeker) error {
+	plaintext, err := io.ReadAll(object)
+	if err != nil {
+		return err
+	}
+	encrypted, err := c.encrypt(ctx, c.tenantFunc(objectKey), plaintext)
+	if err != nil {
+		return fmt.Errorf("encrypting object %s: %w", objectKey, err)
+	}
+	return c.downstream.PutObject(ctx, objectKey, bytes.NewReader(encrypted))
+}
+
+func (c *ObjectClient) GetObject(ctx context.Context, objectKey string) (io.ReadCloser, int64, error) {
+	rc, _, err := c.downstream.GetObject(ctx, objectKey)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rc.Close()
+
+	b, err := io.ReadAll(rc)
+	if err != nil {
+		return nil, 0, err
+	}
+	if !bytes.HasPrefix(b, magic) {
+		return io.NopCloser(bytes.NewReader(b)), int64(len(b)), nil
+	}
+
+	plaintext, err := c.decrypt(ctx, b)
+	if err != nil {
+		return nil, 0, fmt.Errorf("decrypting object %s: %w", objectKey, err)
+	}
+	return io.NopCloser(bytes.NewReader(plaintext)), int64(len(plaintext)), nil
+}
+
+func (c *ObjectClient) ObjectExists(ctx context.Context, objectKey string) (bool, error) {
+	return c.downstream.ObjectExists(ctx, objectKey)
+}
+
+func (c *ObjectClient) List(ctx context.Context, prefix, delimiter string) ([]client.StorageObject, []client.StorageCommonPrefix, error) {
+	return c.downstream.List(ctx, prefix, delimiter)
+}
+
+func (c *ObjectClient) DeleteObject(ctx context.Context, objectKey string) error {
+	return c.downstream.DeleteObject(ctx, objectKey)
+}
+
+func (c *ObjectClient) IsObjectNotFoundErr(err error) bool {
+	return c.downstream.IsObjectNotFoundErr(err)
+}
+
+func (c *ObjectClient) IsRetryableErr(err error) bool {
+	return c.downstream.IsRetryableErr(err)
+}
+
+func (c *ObjectClient) Stop() {
+	c.downstream.Stop()
+}
+
+func (c *ObjectClient) GetDownstream() client.ObjectClient {
+	return c.downstream
+}
+
+// encrypt encodes the object as:
+// magic | uvarint len(tenant) | tenant | uvarint len(wrapped key) | wrapped key | nonce | ciphertext.
+// The header is authenticated along with the ciphertext.
+func (c *ObjectClient) encrypt(ctx context.Context, tenant string, plaintext []byte) ([]byte, error) {
+	key, err := c.writeKey(ctx, tenant)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(key.key)
+	if err != nil {
+		return nil, err
+	}
+
+	header := append([]byte{}, magic...)
+	header = binary.AppendUvarint(header, uint64(len(tenant)))
+	header = append(header, tenant...)
+	header = binary.AppendUvarint(header, uint64(len(key.wrapped)))
+	header = append(header, key.wrapped...)
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	out := make([]byte, 0, len(header)+len(nonce)+len(plaintext)+aead.Overhead())
+	out = append(append(out, header...), nonce...)
+	return aead.Seal(out, nonce, plaintext, header), nil
+}
+
+func (c *ObjectClient) decrypt(ctx context.Context, b []byte) ([]byte, error) {
+	r := bytes.NewReader(b[len(magic):])
+	tenant, err := readBytes(r)
+	if err != nil {
+		return nil, err
+	}
+	wrapped, err := readBytes(r)
+	if err != nil {
+		return nil, err
+	}
+	header := b[:len(b)-r.Len()]
+
+	key, err := c.readKey(ctx, string(tenant), wrapped)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	rest := b[len(header):]
+	if len(rest) < aead.NonceSize() {
+		return nil, errors.New("encrypted object too short")
+	}
+	return aead.Open(nil, rest[:aead.NonceSize()], rest[aead.NonceSize():], header)
+}
+
+// writeKey returns the current data key of the tenant, generating a new one once the
+// previous one is older than the rotation period or the key cache TTL. Otherwise objects
+// would still be written with the key of a tenant whose key was deleted.
+func (c *ObjectClient) writeKey(ctx context.Context, tenant string) (*dataKey, error) {
+	c.mtx.Lock()
+	key, ok := c.writeKeys[tenant]
+	c.mtx.Unlock()
+	if ok && c.nowFunc().Before(key.expires) {
+		return key, nil
+	}
+
+	plain := make([]byte, keySize)
+	if _, err := rand.Read(plain); err != nil {
+		return nil, err
+	}
+	wrapped, err := c.kms.WrapKey(ctx, tenant, plain)
+	if err != nil {
+		return nil, err
+	}
+	validity := c.cfg.DataKeyRotationPeriod
+	if c.cfg.KeyCacheTTL < validity {
+		validity = c.cfg.KeyCacheTTL
+	}
+	key = &dataKey{key: plain, wrapped: wrapped, expires: c.nowFunc().Add(validity)}
+
+	c.mtx.Lock()
+	c.writeKeys[tenant] = key
+	c.mtx.Unlock()
+	return key, nil
+}
+
+func (c *ObjectClient) readKey(ctx context.Context, tenant string, wrapped []byte) ([]byte, error) {
+	cacheKey := tenant + "/" + string(wrapped)
+
+	c.mtx.Lock()
+	key, ok := c.readKeys.Get(cacheKey)
+	c.mtx.Unlock()
+	if ok && c.nowFunc().Before(key.expires) {
+		return key.key, nil
+	}
+
+	plain, err := c.kms.UnwrapKey(ctx, tenant, wrapped)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mtx.Lock()
+	c.readKeys.Add(cacheKey, &dataKey{key: plain, expires: c.nowFunc().Add(c.cfg.KeyCacheTTL)})
+	c.mtx.Unlock()
+	return plain, nil
+}
+
+func readBytes(r *bytes.Reader) ([]byte, error) {
+	n, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+	if n > uint64(r.Len()) {
+		return nil, errors.New("invalid encryption header")
+	}
+	b := make([]byte, n)
+	_, err = io.ReadFull(r, b)
+	return b, err
+}
+
+var tableNameRegexp = regexp.MustCompile(`^.+_\d+$`)
+
+// TenantFromKey returns the tenant owning an object, or an empty string for objects shared by
+// several tenants. It recognises the following layouts:
+//   - chunks: <tenant>/<fingerprint>/<from>:<through>:<checksum> or <tenant>/<fingerprint>:<from>:<through>:<checksum>,
+//     with their last part base64 encoded when stored on the filesystem.
+//   - per-tenant index files and blooms: <prefix>/<table>/<tenant>/<file...>
+func TenantFromKey(key string) string {
+	parts := strings.Split(key, "/")
+	last := parts[len(parts)-1]
+	if decoded, err := base64.StdEncoding.DecodeString(last); err == nil && strings.Contains(string(decoded), ":") {
+		// legacy filesystem chunk keys are entirely encoded.
+		if len(parts) == 1 {
+			return TenantFromKey(string(decoded))
+		}
+		last = string(decoded)
+	}
+
+	switch {
+	case strings.Count(last, ":") == 2 && len(parts) >= 3:
+		return parts[len(parts)-3]
+	case strings.Count(last, ":") == 3 && len(parts) >= 2:
+		return parts[len(parts)-2]
+	}
+
+	for i, part := range parts {
+		if tableNameRegexp.MatchString(part) {
+			if i+2 < len(parts) {
+				return parts[i+1]
+			}
+			break
+		}
+	}
+	return ""
+}
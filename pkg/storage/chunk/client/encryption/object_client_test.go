@@ -0,0 +1,172 @@
+package encryption
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/loki/v3/pkg/storage/chunk/client"
+	"github.com/grafana/loki/v3/pkg/storage/chunk/client/local"
+)
+
+func newTestClients(t *testing.T) (*ObjectClient, client.ObjectClient, Config) {
+	downstream, err := local.NewFSObjectClient(local.FSConfig{Directory: t.TempDir()})
+	require.NoError(t, err)
+
+	cfg := Config{
+		Enabled:               true,
+		KMS:                   KMSFile,
+		File:                  FileKMSConfig{Directory: t.TempDir()},
+		DataKeyRotationPeriod: time.Hour,
+		KeyCacheSize:          10,
+		KeyCacheTTL:           time.Minute,
+	}
+	require.NoError(t, cfg.Validate())
+	kms, err := NewKMS(cfg)
+	require.NoError(t, err)
+	c, err := NewObjectClient(downstream, kms, cfg)
+	require.NoError(t, err)
+	return c, downstream, cfg
+}
+
+func readObject(t *testing.T, c client.ObjectClient, key string) []byte {
+	rc, size, err := c.GetObject(context.Background(), key)
+	require.NoError(t, err)
+	defer rc.Close()
+	b, err := io.ReadAll(rc)
+	require.NoError(t, err)
+	require.Equal(t, int64(len(b)), size)
+	return b
+}
+
+func TestObjectClient(t *testing.T) {
+	ctx := context.Background()
+	c, downstream, cfg := newTestClients(t)
+
+	const key = "index/index_19700/tenant-a/1700000000-compactor.tsdb.gz"
+	content := []byte("some index content")
+	require.NoError(t, c.PutObject(ctx, key, bytes.NewReader(content)))
+
+	// the object is only readable through the encrypting client.
+	require.NotContains(t, string(readObject(t, downstream, key)), string(content))
+	require.Equal(t, content, readObject(t, c, key))
+
+	// objects written before encryption was enabled are still readable.
+	require.NoError(t, downstream.PutObject(ctx, "plain", bytes.NewReader([]byte("plain content"))))
+	require.Equal(t, []byte("plain content"), readObject(t, c, "plain"))
+
+	// tampering with the object is detected.
+	encrypted := readObject(t, downstream, key)
+	encrypted[len(encrypted)-1] ^= 0xff
+	require.NoError(t, downstream.PutObject(ctx, key, bytes.NewReader(encrypted)))
+	_, _, err := c.GetObject(ctx, key)
+	require.Error(t, err)
+
+	// deleting the key of the tenant makes its objects unreadable, but not the ones of other tenants.
+	require.NoError(t, c.PutObject(ctx, key, bytes.NewReader(content)))
+	require.NoError(t, c.PutObject(ctx, "tenant-b/fp/1:2:3", bytes.NewReader(content)))
+	require.NoError(t, os.Remove(filepath.Join(cfg.File.Directory, fileKMSTenantsDir, "tenant-a"+fileKMSKeyExt)))
+
+	kms, err := NewKMS(cfg)
+	require.NoError(t, err)
+	restarted, err := NewObjectClient(downstream, kms, cfg)
+	require.NoError(t, err)
+	_, _, err = restarted.GetObject(ctx, key)
+	require.ErrorIs(t, err, ErrKeyNotFound)
+	require.Equal(t, content, readObject(t, restarted, "tenant-b/fp/1:2:3"))
+}
+
+func TestObjectClient_DataKeyRotation(t *testing.T) {
+	ctx := context.Background()
+	c, _, _ := newTestClients(t)
+	now := time.Unix(0, 0)
+	c.nowFunc = func() time.Time { return now }
+
+	first, err := c.writeKey(ctx, "tenant")
+	require.NoError(t, err)
+	again, err := c.writeKey(ctx, "tenant")
+	require.NoError(t, err)
+	require.Equal(t, first, again)
+
+	now = now.Add(2 * time.Hour)
+	rotated, err := c.writeKey(ctx, "tenant")
+	require.NoError(t, err)
+	require.NotEqual(t, first.key, rotated.key)
+
+	// objects encrypted with the previous key remain readable.
+	encrypted, err := c.encrypt(ctx, "tenant", []byte("content"))
+	require.NoError(t, err)
+	plaintext, err := c.decrypt(ctx, encrypted)
+	require.NoError(t, err)
+	require.Equal(t, []byte("content"), plaintext)
+}
+
+func TestTenantFromKey(t *testing.T) {
+	for _, tc := range []struct {
+		key, tenant string
+	}{
+		{"tenant/8a9fc7a8b9c0d1e2/18f1b2c3:18f1b2d4:a1b2c3d4", "tenant"},
+		{"tenant/8a9fc7a8b9c0d1e2:18f1b2c3:18f1b2d4:a1b2c3d4", "tenant"},
+		{"tenant/8a9fc7a8b9c0d1e2/MThmMWIyYzM6MThmMWIyZDQ6YTFiMmMzZDQ=", "tenant"},
+		{"dGVuYW50LzhhOWZjN2E4YjljMGQxZTI6MThmMWIyYzM6MThmMWIyZDQ6YTFiMmMzZDQ=", "tenant"},
+		{"index/index_19700/tenant/1700000000-compactor-1-2-abc.tsdb.gz", "tenant"},
+		{"index/index_19700/ingester-1-1700000000.tsdb.gz", ""},
+		{"bloom/index_19700/tenant/metas/0-ffff-abc.json", "tenant"},
+		{"deletion/delete_requests/delete_requests.gz", ""},
+	} {
+		require.Equal(t, tc.tenant, TenantFromKey(tc.key), tc.key)
+	}
+}
+
+func TestObjectClient_DeletedKeyExpires(t *testing.T) {
+	ctx := context.Background()
+	downstream, err := local.NewFSObjectClient(local.FSConfig{Directory: t.TempDir()})
+	require.NoError(t, err)
+	cfg := Config{
+		Enabled:               true,
+		KMS:                   KMSFile,
+		File:                  FileKMSConfig{Directory: t.TempDir()},
+		DataKeyRotationPeriod: time.Hour,
+		KeyCacheSize:          10,
+		KeyCacheTTL:           time.Minute,
+	}
+	kms, err := NewFileKMS(cfg.File, cfg.KeyCacheTTL)
+	require.NoError(t, err)
+	c, err := NewObjectClient(downstream, kms, cfg)
+	require.NoError(t, err)
+
+	now := time.Unix(0, 0)
+	kms.nowFunc = func() time.Time { return now }
+	c.nowFunc = func() time.Time { return now }
+
+	const key = "tenant-a/fp/1:2:3"
+	content := []byte("some chunk content")
+	require.NoError(t, c.PutObject(ctx, key, bytes.NewReader(content)))
+	require.Equal(t, content, readObject(t, c, key))
+
+	// the deleted key remains usable until the TTL expired.
+	require.NoError(t, os.Remove(filepath.Join(cfg.File.Directory, fileKMSTenantsDir, "tenant-a"+fileKMSKeyExt)))
+	now = now.Add(30 * time.Second)
+	require.Equal(t, content, readObject(t, c, key))
+
+	now = now.Add(time.Minute)
+	_, _, err = c.GetObject(ctx, key)
+	require.ErrorIs(t, err, ErrKeyNotFound)
+	_, err = kms.UnwrapKey(ctx, "tenant-a", []byte("wrapped"))
+	require.ErrorIs(t, err, ErrKeyNotFound)
+
+	// new objects are written with a new key of the tenant.
+	wk, err := c.writeKey(ctx, "tenant-a")
+	require.NoError(t, err)
+	_, err = os.Stat(filepath.Join(cfg.File.Directory, fileKMSTenantsDir, "tenant-a"+fileKMSKeyExt))
+	require.NoError(t, err)
+	unwrapped, err := kms.UnwrapKey(ctx, "tenant-a", wk.wrapped)
+	require.NoError(t, err)
+	require.Equal(t, wk.key, unwrapped)
+}
@@ -22,6 +22,7 @@ import (
 	"github.com/grafana/loki/v3/pkg/storage/chunk/client/baidubce"
 	"github.com/grafana/loki/v3/pkg/storage/chunk/client/cassandra"
 	"github.com/grafana/loki/v3/pkg/storage/chunk/client/congestion"
+	"github.com/grafana/loki/v3/pkg/storage/chunk/client/encryption"
 	"github.com/grafana/loki/v3/pkg/storage/chunk/client/gcp"
 	"github.com/grafana/loki/v3/pkg/storage/chunk/client/grpc"
 	"github.com/grafana/loki/v3/pkg/storage/chunk/client/hedging"
@@ -289,6 +290,7 @@ type Config struct {
 	IndexCacheValidity     time.Duration             `yaml:"index_cache_validity"`
 	CongestionControl      congestion.Config         `yaml:"congestion_control,omitempty"`
 	ObjectPrefix           string                    `yaml:"object_prefix" doc:"description=Experimental. Sets a constant prefix for all keys inserted into object storage. Example: loki/"`
+	Encryption             encryption.Config         `yaml:"encryption" category:"experimental" doc:"description=Experimental: Configures the client-side envelope encryption of the objects written to object storage."`
 
 	IndexQueriesCacheConfig  cache.Config `yaml:"index_queries_cache_config"`
 	DisableBroadIndexQueries bool         `yaml:"disable_broad_index_queries"`
@@ -320,6 +322,7 @@ func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
 	cfg.GrpcConfig.RegisterFlags(f)
 	cfg.Hedging.RegisterFlagsWithPrefix("store.", f)
 	cfg.CongestionControl.RegisterFlagsWithPrefix("store.", f)
+	cfg.Encryption.RegisterFlagsWithPrefix("store.encryption.", f)
 
 	cfg.IndexQueriesCacheConfig.RegisterFlagsWithPrefix("store.index-cache-read.", "", f)
 	f.DurationVar(&cfg.IndexCacheValidity, "store.index-cache-validity", 5*time.Minute, "Cache validity for active index entries. Should be no higher than -ingester.max-chunk-idle.")
@@ -358,6 +361,9 @@ func (cfg *Config) Validate() error {
 	if err := cfg.BloomShipperConfig.Validate(); err != nil {
 		return errors.Wrap(err, "invalid bloom shipper config")
 	}
+	if err := cfg.Encryption.Validate(); err != nil {
+		return errors.Wrap(err, "invalid encryption config")
+	}
 
 	return cfg.NamedStores.Validate()
 }
@@ -597,18 +603,26 @@ func (c *ClientMetrics) Unregister() {
 }
 
 // NewObjectClient makes a new StorageClient with the prefix in the front.
+// Objects are encrypted when encryption is enabled.
 func NewObjectClient(name string, cfg Config, clientMetrics ClientMetrics) (client.ObjectClient, error) {
 	actual, err := internalNewObjectClient(name, cfg, clientMetrics)
 	if err != nil {
 		return nil, err
 	}
 
-	if cfg.ObjectPrefix == "" {
-		return actual, nil
-	} else {
+	if cfg.ObjectPrefix != "" {
 		prefix := strings.Trim(cfg.ObjectPrefix, "/") + "/"
-		return client.NewPrefixedObjectClient(actual, prefix), nil
+		actual = client.NewPrefixedObjectClient(actual, prefix)
+	}
+
+	if !cfg.Encryption.Enabled {
+		return actual, nil
+	}
+	kms, err := encryption.NewKMS(cfg.Encryption)
+	if err != nil {
+		return nil, err
 	}
+	return encryption.NewObjectClient(actual, kms, cfg.Encryption)
 }
 
 // internalNewObjectClient makes the underlying StorageClient of the desired types.
@@ -12,11 +12,13 @@ import (
 	"time"
 
 	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
 	"github.com/grafana/dskit/concurrency"
 	"github.com/pkg/errors"
 	"github.com/prometheus/common/model"
 
 	v1 "github.com/grafana/loki/v3/pkg/storage/bloom/v1"
+	"github.com/grafana/loki/v3/pkg/storage/chunk/cache"
 	"github.com/grafana/loki/v3/pkg/storage/chunk/client"
 	"github.com/grafana/loki/v3/pkg/storage/chunk/client/util"
 	"github.com/grafana/loki/v3/pkg/storage/config"
@@ -504,3 +506,47 @@ func (c *cachedListOpObjectClient) Stop() {
 	c.cache = nil
 	c.ObjectClient.Stop()
 }
+
+// cachedBlocksObjectClient serves the block archives from a cache, usually a local disk cache.
+// The keys of the block archives contain their checksum, so cached archives never get stale.
+type cachedBlocksObjectClient struct {
+	client.ObjectClient
+	cache  cache.Cache
+	logger log.Logger
+}
+
+func newCachedBlocksObjectClient(oc client.ObjectClient, c cache.Cache, logger log.Logger) *cachedBlocksObjectClient {
+	return &cachedBlocksObjectClient{
+		ObjectClient: oc,
+		cache:        c,
+		logger:       logger,
+	}
+}
+
+func (c *cachedBlocksObjectClient) GetObject(ctx context.Context, key string) (io.ReadCloser, int64, error) {
+	if !strings.HasSuffix(key, extTarGz) {
+		return c.ObjectClient.GetObject(ctx, key)
+	}
+
+	found, bufs, _, err := c.cache.Fetch(ctx, []string{key})
+	if err != nil {
+		level.Warn(c.logger).Log("msg", "failed to fetch block archive from cache", "key", key, "err", err)
+	} else if len(found) == 1 {
+		return io.NopCloser(bytes.NewReader(bufs[0])), int64(len(bufs[0])), nil
+	}
+
+	rc, _, err := c.ObjectClient.GetObject(ctx, key)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rc.Close()
+
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		return nil, 0, err
+	}
+	if err := c.cache.Store(ctx, []string{key}, [][]byte{data}); err != nil {
+		level.Warn(c.logger).Log("msg", "failed to store block archive in cache", "key", key, "err", err)
+	}
+	return io.NopCloser(bytes.NewReader(data)), int64(len(data)), nil
+}
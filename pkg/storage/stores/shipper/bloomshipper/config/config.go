@@ -50,6 +50,10 @@ type BlocksCacheConfig struct {
 	HardLimit flagext.Bytes `yaml:"hard_limit"`
 	TTL       time.Duration `yaml:"ttl"`
 
+	// DiskCache keeps the downloaded block archives, so that blocks evicted from the
+	// working directory are extracted again without downloading them.
+	DiskCache cache.DiskCacheConfig `yaml:"disk_cache"`
+
 	// PurgeInterval tell how often should we remove keys that are expired.
 	// by default it takes `defaultPurgeInterval`
 	PurgeInterval time.Duration `yaml:"-"`
@@ -65,6 +69,7 @@ func (cfg *BlocksCacheConfig) RegisterFlagsWithPrefixAndDefaults(prefix, descrip
 	_ = cfg.HardLimit.Set("64GiB")
 	f.Var(&cfg.HardLimit, prefix+"hard-limit", description+"Hard limit of the cache in bytes. Exceeding this limit will block execution until soft limit is deceeded.")
 	f.DurationVar(&cfg.TTL, prefix+"ttl", defaultTTL, description+"The time to live for items in the cache before they get purged.")
+	cfg.DiskCache.RegisterFlagsWithPrefix(prefix+"disk-cache.", description+"Disk cache for the downloaded block archives. ", f)
 }
 
 func (cfg *BlocksCacheConfig) Validate() error {
@@ -77,5 +82,5 @@ func (cfg *BlocksCacheConfig) Validate() error {
 	if cfg.SoftLimit > cfg.HardLimit {
 		return errors.New("blocks cache soft_limit must not be greater than hard_limit")
 	}
-	return nil
+	return cfg.DiskCache.Validate()
 }
@@ -14,6 +14,7 @@ import (
 	"github.com/prometheus/common/model"
 	"golang.org/x/exp/slices"
 
+	"github.com/grafana/loki/v3/pkg/logqlmodel/stats"
 	"github.com/grafana/loki/v3/pkg/storage"
 	v1 "github.com/grafana/loki/v3/pkg/storage/bloom/v1"
 	"github.com/grafana/loki/v3/pkg/storage/chunk/cache"
@@ -243,6 +244,9 @@ type BloomStore struct {
 	metrics       *storeMetrics
 	bloomMetrics  *v1.Metrics
 
+	// blocksDiskCache holds the block archives of all periods, nil when disabled.
+	blocksDiskCache cache.Cache
+
 	logger             log.Logger
 	defaultKeyResolver // TODO(owen-d): impl schema aware resolvers
 }
@@ -289,6 +293,15 @@ func NewBloomStore(
 		}
 	}
 
+	if diskCfg := storageConfig.BloomShipperConfig.BlocksCache.DiskCache; diskCfg.IsEnabled() {
+		cacheName := "bloom-blocks-disk-cache"
+		diskCache, err := cache.NewDiskCache(cacheName, diskCfg, reg, logger, stats.BloomBlocksCache)
+		if err != nil {
+			return nil, errors.Wrap(err, "creating disk cache for bloom blocks")
+		}
+		store.blocksDiskCache = cache.Instrument(cacheName, diskCache, reg)
+	}
+
 	for _, periodicConfig := range periodicConfigs {
 		objectClient, err := storage.NewObjectClient(periodicConfig.ObjectType, storageConfig, clientMetrics)
 		if err != nil {
@@ -298,6 +311,9 @@ func NewBloomStore(
 		if storageConfig.BloomShipperConfig.CacheListOps {
 			objectClient = newCachedListOpObjectClient(objectClient, 5*time.Minute, 10*time.Second)
 		}
+		if store.blocksDiskCache != nil {
+			objectClient = newCachedBlocksObjectClient(objectClient, store.blocksDiskCache, logger)
+		}
 		bloomClient, err := NewBloomClient(cfg, objectClient, logger)
 		if err != nil {
 			return nil, errors.Wrapf(err, "creating bloom client for period %s", periodicConfig.From)
@@ -514,6 +530,9 @@ func (b *BloomStore) Stop() {
 	for _, s := range b.stores {
 		s.Stop()
 	}
+	if b.blocksDiskCache != nil {
+		b.blocksDiskCache.Stop()
+	}
 }
 
 func (b *BloomStore) getStore(ts model.Time) *bloomStoreEntry {
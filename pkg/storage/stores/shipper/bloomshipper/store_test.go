@@ -25,14 +25,14 @@ import (
 	"github.com/grafana/loki/v3/pkg/storage/types"
 )
 
-func newMockBloomStore(t *testing.T) (*BloomStore, string, error) {
+func newMockBloomStore(t *testing.T, opts ...func(*storage.Config)) (*BloomStore, string, error) {
 	dir := t.TempDir()
 	workDir := filepath.Join(dir, "bloomshipper")
 	storeDir := filepath.Join(dir, "fs-storage")
-	return newMockBloomStoreWithWorkDir(t, workDir, storeDir)
+	return newMockBloomStoreWithWorkDir(t, workDir, storeDir, opts...)
 }
 
-func newMockBloomStoreWithWorkDir(t *testing.T, workDir, storeDir string) (*BloomStore, string, error) {
+func newMockBloomStoreWithWorkDir(t *testing.T, workDir, storeDir string, opts ...func(*storage.Config)) (*BloomStore, string, error) {
 	periodicConfigs := []storageconfig.PeriodConfig{
 		{
 			ObjectType: types.StorageTypeFileSystem,
@@ -69,6 +69,9 @@ func newMockBloomStoreWithWorkDir(t *testing.T, workDir, storeDir string) (*Bloo
 			},
 		},
 	}
+	for _, opt := range opts {
+		opt(&storageConfig)
+	}
 
 	reg := prometheus.NewPedanticRegistry()
 	metrics := storage.NewClientMetrics()
@@ -278,6 +281,38 @@ func TestBloomStore_FetchBlocks(t *testing.T) {
 	)
 }
 
+func TestBloomStore_FetchBlocksFromDiskCache(t *testing.T) {
+	diskCacheDir := t.TempDir()
+	store, _, err := newMockBloomStore(t, func(cfg *storage.Config) {
+		cfg.BloomShipperConfig.BlocksCache.DiskCache = cache.DiskCacheConfig{
+			Enabled:   true,
+			Directory: diskCacheDir,
+			MaxSizeMB: 10,
+		}
+	})
+	require.NoError(t, err)
+
+	b1, err := createBlockInStorage(t, store, "tenant", parseTime("2024-01-20 00:00"), 0x00000000, 0x0000ffff)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	c, err := store.Client(b1.StartTimestamp)
+	require.NoError(t, err)
+
+	dir, err := c.GetBlock(ctx, b1.BlockRef)
+	require.NoError(t, err)
+	require.NoError(t, os.RemoveAll(dir.Path))
+
+	// the archive is extracted from the disk cache once removed from the storage.
+	require.NoError(t, store.storeDo(b1.StartTimestamp, func(s *bloomStoreEntry) error {
+		return s.objectClient.DeleteObject(ctx, s.Block(b1.BlockRef).Addr())
+	}))
+	dir, err = c.GetBlock(ctx, b1.BlockRef)
+	require.NoError(t, err)
+	ok, _ := isBlockDir(dir.Path, log.NewNopLogger())
+	require.True(t, ok)
+}
+
 func TestBloomStore_TenantFilesForInterval(t *testing.T) {
 	ctx := context.Background()
 	var keyResolver defaultKeyResolver
@@ -3,8 +3,10 @@ package httpreq
 import (
 	"context"
 	"net/http"
+	"net/textproto"
 	"strings"
 
+	"github.com/grafana/dskit/httpgrpc"
 	"github.com/grafana/dskit/middleware"
 )
 
@@ -14,6 +16,9 @@ var (
 	// LokiActorPathHeader is the name of the header e.g. used to enqueue requests in hierarchical queues.
 	LokiActorPathHeader               = "X-Loki-Actor-Path"
 	LokiDisablePipelineWrappersHeader = "X-Loki-Disable-Pipeline-Wrappers"
+	// LokiQueryPriorityHeader is the name of the header used to set the priority class (high, normal or low) of a query
+	// in the query-frontend / query-scheduler queue.
+	LokiQueryPriorityHeader = "X-Loki-Query-Priority"
 
 	// LokiActorPathDelimiter is the delimiter used to serialise the hierarchy of the actor.
 	LokiActorPathDelimiter = "|"
@@ -55,3 +60,14 @@ func InjectActorPath(ctx context.Context, value string) context.Context {
 func InjectHeader(ctx context.Context, key, value string) context.Context {
 	return context.WithValue(ctx, headerContextKey(key), value)
 }
+
+// ExtractHTTPGRPCHeader returns the first value of the header with the given name from an httpgrpc request.
+func ExtractHTTPGRPCHeader(req *httpgrpc.HTTPRequest, name string) string {
+	name = textproto.CanonicalMIMEHeaderKey(name)
+	for _, h := range req.GetHeaders() {
+		if textproto.CanonicalMIMEHeaderKey(h.Key) == name && len(h.Values) > 0 {
+			return h.Values[0]
+		}
+	}
+	return ""
+}
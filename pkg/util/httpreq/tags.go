@@ -4,6 +4,7 @@ import (
 	"context"
 	"net/http"
 	"regexp"
+	"strings"
 	"time"
 
 	"github.com/grafana/dskit/middleware"
@@ -40,6 +41,18 @@ func ExtractQueryTagsFromHTTP(req *http.Request) string {
 	return safeQueryTags.ReplaceAllString(tags, "_")
 }
 
+// QueryTagValue returns the value of key in the given query tags, e.g. `ruler` for key `source` and tags `Source=ruler,Feature=beta`.
+// Keys are compared case-insensitively. An empty string is returned if the key is not present.
+func QueryTagValue(tags, key string) string {
+	for _, tag := range strings.Split(tags, ",") {
+		k, v, ok := strings.Cut(tag, "=")
+		if ok && strings.EqualFold(strings.TrimSpace(k), key) {
+			return strings.TrimSpace(v)
+		}
+	}
+	return ""
+}
+
 func InjectQueryTags(ctx context.Context, tags string) context.Context {
 	tags = safeQueryTags.ReplaceAllString(tags, "_")
 	return context.WithValue(ctx, QueryTagsHTTPHeader, tags)
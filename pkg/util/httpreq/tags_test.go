@@ -96,3 +96,11 @@ func TestQueryMetrics(t *testing.T) {
 		})
 	}
 }
+
+func TestQueryTagValue(t *testing.T) {
+	require.Equal(t, "ruler", QueryTagValue(`source=ruler`, "source"))
+	require.Equal(t, "logvolhist", QueryTagValue(`Source=logvolhist,Feature=beta`, "source"))
+	require.Equal(t, "beta", QueryTagValue(`Source=logvolhist, Feature=beta`, "feature"))
+	require.Equal(t, "", QueryTagValue(`Feature=beta`, "source"))
+	require.Equal(t, "", QueryTagValue(``, "source"))
+}
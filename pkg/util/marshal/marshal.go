@@ -53,9 +53,15 @@ func WriteResponseJSON(r *http.Request, v any, w http.ResponseWriter) error {
 // WriteQueryResponseJSON marshals the promql.Value to v1 loghttp JSON and then
 // writes it to the provided io.Writer.
 func WriteQueryResponseJSON(data parser.Value, warnings []string, statistics stats.Result, w io.Writer, encodeFlags httpreq.EncodingFlags) error {
+	return WriteQueryResponseJSONWithCursor(data, warnings, statistics, "", w, encodeFlags)
+}
+
+// WriteQueryResponseJSONWithCursor is like WriteQueryResponseJSON, and also writes
+// the cursor of the next page of a log query when it is not empty.
+func WriteQueryResponseJSONWithCursor(data parser.Value, warnings []string, statistics stats.Result, cursor string, w io.Writer, encodeFlags httpreq.EncodingFlags) error {
 	s := jsoniter.ConfigFastest.BorrowStream(w)
 	defer jsoniter.ConfigFastest.ReturnStream(s)
-	err := EncodeResult(data, warnings, statistics, s, encodeFlags)
+	err := EncodeResult(data, warnings, statistics, cursor, s, encodeFlags)
 	if err != nil {
 		return fmt.Errorf("could not write JSON response: %w", err)
 	}
@@ -176,7 +176,7 @@ func NewMetric(l labels.Labels) model.Metric {
 	return ret
 }
 
-func EncodeResult(data parser.Value, warnings []string, statistics stats.Result, s *jsoniter.Stream, encodeFlags httpreq.EncodingFlags) error {
+func EncodeResult(data parser.Value, warnings []string, statistics stats.Result, cursor string, s *jsoniter.Stream, encodeFlags httpreq.EncodingFlags) error {
 	s.WriteObjectStart()
 	s.WriteObjectField("status")
 	s.WriteString("success")
@@ -197,7 +197,7 @@ func EncodeResult(data parser.Value, warnings []string, statistics stats.Result,
 
 	s.WriteMore()
 	s.WriteObjectField("data")
-	err := encodeData(data, statistics, s, encodeFlags)
+	err := encodeData(data, statistics, cursor, s, encodeFlags)
 	if err != nil {
 		return err
 	}
@@ -276,7 +276,7 @@ func encodeEncodingFlags(s *jsoniter.Stream, flags httpreq.EncodingFlags) error
 	return nil
 }
 
-func encodeData(data parser.Value, statistics stats.Result, s *jsoniter.Stream, encodeFlags httpreq.EncodingFlags) error {
+func encodeData(data parser.Value, statistics stats.Result, cursor string, s *jsoniter.Stream, encodeFlags httpreq.EncodingFlags) error {
 	s.WriteObjectStart()
 
 	s.WriteObjectField("resultType")
@@ -301,6 +301,12 @@ func encodeData(data parser.Value, statistics stats.Result, s *jsoniter.Stream,
 	s.WriteObjectField("stats")
 	s.WriteVal(statistics)
 
+	if cursor != "" {
+		s.WriteMore()
+		s.WriteObjectField("cursor")
+		s.WriteString(cursor)
+	}
+
 	s.WriteObjectEnd()
 	s.Flush()
 	return nil
@@ -25,6 +25,7 @@ import (
 	"github.com/grafana/loki/v3/pkg/loghttp/push"
 	"github.com/grafana/loki/v3/pkg/logql"
 	"github.com/grafana/loki/v3/pkg/logql/syntax"
+	"github.com/grafana/loki/v3/pkg/queue"
 	ruler_config "github.com/grafana/loki/v3/pkg/ruler/config"
 	"github.com/grafana/loki/v3/pkg/ruler/util"
 	"github.com/grafana/loki/v3/pkg/storage/stores/shipper/indexshipper/tsdb/sharding"
@@ -109,6 +110,9 @@ type Limits struct {
 	MaxStatsCacheFreshness     model.Duration   `yaml:"max_stats_cache_freshness" json:"max_stats_cache_freshness"`
 	MaxQueriersPerTenant       uint             `yaml:"max_queriers_per_tenant" json:"max_queriers_per_tenant"`
 	MaxQueryCapacity           float64          `yaml:"max_query_capacity" json:"max_query_capacity"`
+	HighPriorityQuerySources   []string         `yaml:"high_priority_query_sources" json:"high_priority_query_sources"`
+	LowPriorityQuerySources    []string         `yaml:"low_priority_query_sources" json:"low_priority_query_sources"`
+	MaxQueryPriority           string           `yaml:"max_query_priority" json:"max_query_priority"`
 	QueryReadyIndexNumDays     int              `yaml:"query_ready_index_num_days" json:"query_ready_index_num_days"`
 	QueryTimeout               model.Duration   `yaml:"query_timeout" json:"query_timeout"`
 
@@ -122,6 +126,9 @@ type Limits struct {
 	MinShardingLookback              model.Duration   `yaml:"min_sharding_lookback" json:"min_sharding_lookback"`
 	MaxQueryBytesRead                flagext.ByteSize `yaml:"max_query_bytes_read" json:"max_query_bytes_read"`
 	MaxQuerierBytesRead              flagext.ByteSize `yaml:"max_querier_bytes_read" json:"max_querier_bytes_read"`
+	QueryBytesBudget                 flagext.ByteSize `yaml:"query_bytes_budget" json:"query_bytes_budget"`
+	QueryBudgetWindow                model.Duration   `yaml:"query_budget_window" json:"query_budget_window"`
+	QueryBudgetMaxWait               model.Duration   `yaml:"query_budget_max_wait" json:"query_budget_max_wait"`
 	VolumeEnabled                    bool             `yaml:"volume_enabled" json:"volume_enabled" doc:"description=Enable log-volume endpoints."`
 	VolumeMaxSeries                  int              `yaml:"volume_max_series" json:"volume_max_series" doc:"description=The maximum number of aggregated series in a log-volume response"`
 
@@ -130,6 +137,7 @@ type Limits struct {
 	RulerMaxRuleGroupsPerTenant int                              `yaml:"ruler_max_rule_groups_per_tenant" json:"ruler_max_rule_groups_per_tenant"`
 	RulerAlertManagerConfig     *ruler_config.AlertManagerConfig `yaml:"ruler_alertmanager_config" json:"ruler_alertmanager_config" doc:"hidden"`
 	RulerTenantShardSize        int                              `yaml:"ruler_tenant_shard_size" json:"ruler_tenant_shard_size"`
+	RulerAllowedSourceTenants   []string                         `yaml:"ruler_allowed_source_tenants" json:"ruler_allowed_source_tenants"`
 
 	// TODO(dannyk): add HTTP client overrides (basic auth / tls config, etc)
 	// Ruler remote-write limits.
@@ -311,6 +319,14 @@ func (l *Limits) RegisterFlags(f *flag.FlagSet) {
 	_ = l.MaxQuerierBytesRead.Set("150GB")
 	f.Var(&l.MaxQuerierBytesRead, "frontend.max-querier-bytes-read", "Max number of bytes a query can fetch after splitting and sharding. Enforced in log and metric queries only when TSDB is used. The default value of 0 disables this limit.")
 
+	f.Var(&l.QueryBytesBudget, "frontend.query-bytes-budget", "Number of bytes the queries of a tenant can scan within the query budget window. Once the budget is exhausted, further log and metric queries are delayed or rejected until enough of the budget is freed up. The bytes a query is estimated to scan from index stats are reserved when it is admitted, and corrected with the actual bytes processed once it completes. Queries estimated to scan more than the budget are rejected. The default value of 0 disables the budget.")
+
+	_ = l.QueryBudgetWindow.Set("1h")
+	f.Var(&l.QueryBudgetWindow, "frontend.query-budget-window", "Sliding time window in which the query bytes budget is tracked.")
+
+	_ = l.QueryBudgetMaxWait.Set("0s")
+	f.Var(&l.QueryBudgetMaxWait, "frontend.query-budget-max-wait", "Maximum time a query waits in the query-frontend for budget to be freed up once the tenant has exhausted its query bytes budget. The default value of 0 rejects such queries immediately.")
+
 	_ = l.MaxCacheFreshness.Set("10m")
 	f.Var(&l.MaxCacheFreshness, "frontend.max-cache-freshness", "Most recent allowed cacheable result per-tenant, to prevent caching very recent results that might still be in flux.")
 
@@ -322,11 +338,15 @@ func (l *Limits) RegisterFlags(f *flag.FlagSet) {
 
 	f.UintVar(&l.MaxQueriersPerTenant, "frontend.max-queriers-per-tenant", 0, "Maximum number of queriers that can handle requests for a single tenant. If set to 0 or value higher than number of available queriers, *all* queriers will handle requests for the tenant. Each frontend (or query-scheduler, if used) will select the same set of queriers for the same tenant (given that all queriers are connected to all frontends / query-schedulers). This option only works with queriers connecting to the query-frontend / query-scheduler, not when using downstream URL.")
 	f.Float64Var(&l.MaxQueryCapacity, "frontend.max-query-capacity", 0, "How much of the available query capacity (\"querier\" components in distributed mode, \"read\" components in SSD mode) can be used by a single tenant. Allowed values are 0.0 to 1.0. For example, setting this to 0.5 would allow a tenant to use half of the available queriers for processing the query workload. If set to 0, query capacity is determined by frontend.max-queriers-per-tenant. When both frontend.max-queriers-per-tenant and frontend.max-query-capacity are configured, smaller value of the resulting querier replica count is considered: min(frontend.max-queriers-per-tenant, ceil(querier_replicas * frontend.max-query-capacity)). *All* queriers will handle requests for the tenant if neither limits are applied. This option only works with queriers connecting to the query-frontend / query-scheduler, not when using downstream URL. Use this feature in a multi-tenant setup where you need to limit query capacity for certain tenants.")
+	f.Var((*dskit_flagext.StringSlice)(&l.HighPriorityQuerySources), "frontend.high-priority-query-sources", "Query sources, taken from the 'source' key of the X-Query-Tags header, whose requests are enqueued with high priority in the query-frontend / query-scheduler queue. Within a tenant queue, higher priority requests are dequeued first. For example, 'ruler' prioritises rule evaluations. The X-Loki-Query-Priority header overrides this setting.")
+	f.Var((*dskit_flagext.StringSlice)(&l.LowPriorityQuerySources), "frontend.low-priority-query-sources", "Query sources, taken from the 'source' key of the X-Query-Tags header, whose requests are enqueued with low priority in the query-frontend / query-scheduler queue. The X-Loki-Query-Priority header overrides this setting.")
+	f.StringVar(&l.MaxQueryPriority, "frontend.max-query-priority", "normal", "The highest priority (high, normal or low) a request can ask for with the X-Loki-Query-Priority header. Higher priorities are lowered to it. It doesn't apply to the priority of the high and low priority query sources.")
 	f.IntVar(&l.QueryReadyIndexNumDays, "store.query-ready-index-num-days", 0, "Number of days of index to be kept always downloaded for queries. Applies only to per user index in boltdb-shipper index store. 0 to disable.")
 
 	f.IntVar(&l.RulerMaxRulesPerRuleGroup, "ruler.max-rules-per-rule-group", 0, "Maximum number of rules per rule group per-tenant. 0 to disable.")
 	f.IntVar(&l.RulerMaxRuleGroupsPerTenant, "ruler.max-rule-groups-per-tenant", 0, "Maximum number of rule groups per-tenant. 0 to disable.")
 	f.IntVar(&l.RulerTenantShardSize, "ruler.tenant-shard-size", 0, "The default tenant's shard size when shuffle-sharding is enabled in the ruler. When this setting is specified in the per-tenant overrides, a value of 0 disables shuffle sharding for the tenant.")
+	f.Var((*dskit_flagext.StringSlice)(&l.RulerAllowedSourceTenants), "ruler.allowed-source-tenants", "Tenants whose data the federated rule groups of this tenant can query, listed in the 'source_tenants' field of a rule group. Federated rule groups are evaluated across all their source tenants, with the tenant of each series in the '__tenant_id__' label. Use '*' to allow any tenant. Empty list disables federated rule groups for the tenant.")
 
 	f.StringVar(&l.PerTenantOverrideConfig, "limits.per-user-override-config", "", "Feature renamed to 'runtime configuration', flag deprecated in favor of -runtime-config.file (runtime_config.file in YAML).")
 	_ = l.RetentionPeriod.Set("0s")
@@ -455,6 +475,10 @@ func (l *Limits) Validate() error {
 		l.MaxQueryCapacity = 1
 	}
 
+	if _, err := queue.ParsePriority(l.MaxQueryPriority); err != nil {
+		return errors.Wrap(err, "invalid max query priority")
+	}
+
 	if err := l.OTLPConfig.Validate(); err != nil {
 		return err
 	}
@@ -610,6 +634,21 @@ func (o *Overrides) MaxQueryCapacity(userID string) float64 {
 	return o.getOverridesForUser(userID).MaxQueryCapacity
 }
 
+// HighPriorityQuerySources returns the query sources whose requests are enqueued with high priority for this user.
+func (o *Overrides) HighPriorityQuerySources(userID string) []string {
+	return o.getOverridesForUser(userID).HighPriorityQuerySources
+}
+
+// LowPriorityQuerySources returns the query sources whose requests are enqueued with low priority for this user.
+func (o *Overrides) LowPriorityQuerySources(userID string) []string {
+	return o.getOverridesForUser(userID).LowPriorityQuerySources
+}
+
+// MaxQueryPriority returns the highest priority a request of this user can ask for with the X-Loki-Query-Priority header.
+func (o *Overrides) MaxQueryPriority(userID string) string {
+	return o.getOverridesForUser(userID).MaxQueryPriority
+}
+
 // QueryReadyIndexNumDays returns the number of days for which we have to be query ready for a user.
 func (o *Overrides) QueryReadyIndexNumDays(userID string) int {
 	return o.getOverridesForUser(userID).QueryReadyIndexNumDays
@@ -693,6 +732,21 @@ func (o *Overrides) MaxQuerierBytesRead(_ context.Context, userID string) int {
 	return o.getOverridesForUser(userID).MaxQuerierBytesRead.Val()
 }
 
+// QueryBytesBudget returns the number of bytes the queries of a user can scan within the query budget window.
+func (o *Overrides) QueryBytesBudget(userID string) int {
+	return o.getOverridesForUser(userID).QueryBytesBudget.Val()
+}
+
+// QueryBudgetWindow returns the sliding time window in which the query bytes budget of a user is tracked.
+func (o *Overrides) QueryBudgetWindow(userID string) time.Duration {
+	return time.Duration(o.getOverridesForUser(userID).QueryBudgetWindow)
+}
+
+// QueryBudgetMaxWait returns the maximum time a query of a user waits for budget to be freed up.
+func (o *Overrides) QueryBudgetMaxWait(userID string) time.Duration {
+	return time.Duration(o.getOverridesForUser(userID).QueryBudgetMaxWait)
+}
+
 // MaxConcurrentTailRequests returns the limit to number of concurrent tail requests.
 func (o *Overrides) MaxConcurrentTailRequests(_ context.Context, userID string) int {
 	return o.getOverridesForUser(userID).MaxConcurrentTailRequests
@@ -749,6 +803,11 @@ func (o *Overrides) RulerMaxRuleGroupsPerTenant(userID string) int {
 	return o.getOverridesForUser(userID).RulerMaxRuleGroupsPerTenant
 }
 
+// RulerAllowedSourceTenants returns the tenants the federated rule groups of a given user can query.
+func (o *Overrides) RulerAllowedSourceTenants(userID string) []string {
+	return o.getOverridesForUser(userID).RulerAllowedSourceTenants
+}
+
 // RulerAlertManagerConfig returns the alertmanager configurations to use for a given user.
 func (o *Overrides) RulerAlertManagerConfig(userID string) *ruler_config.AlertManagerConfig {
 	return o.getOverridesForUser(userID).RulerAlertManagerConfig
@@ -214,8 +214,11 @@ ruler_remote_write_headers:
   foo: "bar"
 `,
 			exp: Limits{
-				RulerRemoteWriteHeaders: OverwriteMarshalingStringMap{map[string]string{"foo": "bar"}},
-				DiscoverServiceName:     []string{},
+				RulerRemoteWriteHeaders:   OverwriteMarshalingStringMap{map[string]string{"foo": "bar"}},
+				DiscoverServiceName:       []string{},
+				HighPriorityQuerySources:  []string{},
+				LowPriorityQuerySources:   []string{},
+				RulerAllowedSourceTenants: []string{},
 
 				// Rest from new defaults
 				StreamRetention: []StreamRetention{
@@ -233,7 +236,10 @@ ruler_remote_write_headers:
 ruler_remote_write_headers:
 `,
 			exp: Limits{
-				DiscoverServiceName: []string{},
+				DiscoverServiceName:       []string{},
+				HighPriorityQuerySources:  []string{},
+				LowPriorityQuerySources:   []string{},
+				RulerAllowedSourceTenants: []string{},
 
 				// Rest from new defaults
 				StreamRetention: []StreamRetention{
@@ -253,7 +259,10 @@ retention_stream:
     selector: '{foo="bar"}'
 `,
 			exp: Limits{
-				DiscoverServiceName: []string{},
+				DiscoverServiceName:       []string{},
+				HighPriorityQuerySources:  []string{},
+				LowPriorityQuerySources:   []string{},
+				RulerAllowedSourceTenants: []string{},
 				StreamRetention: []StreamRetention{
 					{
 						Period:   model.Duration(24 * time.Hour),
@@ -272,8 +281,11 @@ retention_stream:
 reject_old_samples: true
 `,
 			exp: Limits{
-				RejectOldSamples:    true,
-				DiscoverServiceName: []string{},
+				RejectOldSamples:          true,
+				DiscoverServiceName:       []string{},
+				HighPriorityQuerySources:  []string{},
+				LowPriorityQuerySources:   []string{},
+				RulerAllowedSourceTenants: []string{},
 
 				// Rest from new defaults
 				RulerRemoteWriteHeaders: OverwriteMarshalingStringMap{map[string]string{"a": "b"}},
@@ -292,8 +304,11 @@ reject_old_samples: true
 query_timeout: 5m
 `,
 			exp: Limits{
-				DiscoverServiceName: []string{},
-				QueryTimeout:        model.Duration(5 * time.Minute),
+				DiscoverServiceName:       []string{},
+				HighPriorityQuerySources:  []string{},
+				LowPriorityQuerySources:   []string{},
+				RulerAllowedSourceTenants: []string{},
+				QueryTimeout:              model.Duration(5 * time.Minute),
 
 				// Rest from new defaults.
 				RulerRemoteWriteHeaders: OverwriteMarshalingStringMap{map[string]string{"a": "b"}},